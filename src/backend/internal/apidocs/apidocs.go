@@ -0,0 +1,71 @@
+// Package apidocs main.go のルートとハンドラーから生成した OpenAPI 3 の仕様
+//
+// 仕様は gen で生成してこのパッケージに埋め込む。ルートやハンドラーを変更したら go generate ./internal/apidocs で作り直す。
+// go test ./internal/apidocs（または go run ./internal/apidocs/gen -check）で、仕様がソースと食い違っていないか確認する。
+package apidocs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate go run ./gen -root ../..
+
+//go:embed openapi.json
+var spec []byte
+
+// Spec OpenAPI 3 の仕様（JSON）
+func Spec() []byte {
+	return spec
+}
+
+// Drift 登録しているルートと仕様の食い違い（起動時に警告するために使う。空なら一致している）
+func Drift(routes gin.RoutesInfo) []string {
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return []string{fmt.Sprintf("仕様を読み込めません: %v", err)}
+	}
+	documented := map[string]bool{}
+	for path, operations := range doc.Paths {
+		for method := range operations {
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	var drift []string
+	for _, r := range routes {
+		// バージョンなしの /api は v1 の別名のため、仕様には /api/v1 のみ載せる
+		if !strings.HasPrefix(r.Path, "/api/v1/") {
+			continue
+		}
+		key := r.Method + " " + openAPIPath(r.Path)
+		if documented[key] {
+			delete(documented, key)
+			continue
+		}
+		drift = append(drift, "仕様にないルート: "+key)
+	}
+	for key := range documented {
+		drift = append(drift, "登録されていないルート: "+key)
+	}
+	sort.Strings(drift)
+	return drift
+}
+
+// openAPIPath gin のパス（/tasks/:id）を OpenAPI の形式（/tasks/{id}）にする
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "{" + s[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,37 @@
+package apidocs_test
+
+import (
+	"bytes"
+	"testing"
+
+	"task-calendar-backend/internal/apidocs"
+	"task-calendar-backend/internal/apidocs/generator"
+)
+
+// backend ディレクトリ（テストはパッケージのディレクトリで実行される）
+const root = "../.."
+
+// main.go で登録しているルートがすべて仕様に載っていて、仕様にしかないルートもない
+func TestSpecMatchesRoutes(t *testing.T) {
+	routes, err := generator.Routes(root)
+	if err != nil {
+		t.Fatalf("ルートを読み取れません: %v", err)
+	}
+	if len(routes) == 0 {
+		t.Fatal("main.go からルートを読み取れませんでした")
+	}
+	for _, drift := range apidocs.Drift(routes) {
+		t.Error(drift)
+	}
+}
+
+// 埋め込んだ仕様がソースから生成したものと一致する（go run ./internal/apidocs/gen -check と同じ確認）
+func TestSpecIsUpToDate(t *testing.T) {
+	data, err := generator.Generate(root)
+	if err != nil {
+		t.Fatalf("仕様を生成できません: %v", err)
+	}
+	if !bytes.Equal(apidocs.Spec(), data) {
+		t.Error("openapi.json が最新ではありません。go generate ./internal/apidocs を実行してください")
+	}
+}
@@ -0,0 +1,48 @@
+// gen main.go のルート定義とハンドラーのソースから OpenAPI 3 の仕様（internal/apidocs/openapi.json）を生成する
+//
+// 使い方（backend ディレクトリで実行）:
+//
+//	go generate ./internal/apidocs        # openapi.json を作り直す
+//	go run ./internal/apidocs/gen -check  # openapi.json がソースと食い違っていれば終了コード1で終わる（CI向け）
+//
+// 生成の処理は internal/apidocs/generator にある（go test ./internal/apidocs でも食い違いを確認する）。
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"task-calendar-backend/internal/apidocs/generator"
+)
+
+func main() {
+	root := flag.String("root", ".", "backend ディレクトリ")
+	out := flag.String("out", "internal/apidocs/openapi.json", "出力するファイル（root からの相対パス）")
+	check := flag.Bool("check", false, "生成せず、既存のファイルが最新か確認する")
+	flag.Parse()
+
+	data, err := generator.Generate(*root)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	path := filepath.Join(*root, *out)
+	if *check {
+		current, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !bytes.Equal(current, data) {
+			fmt.Fprintf(os.Stderr, "%s が最新ではありません。go generate ./internal/apidocs を実行してください\n", *out)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,863 @@
+// Package generator main.go のルート定義とハンドラーのソースから OpenAPI 3 の仕様（internal/apidocs/openapi.json）を生成する
+//
+// ルートは main.go の Group・GET/POST/PUT/PATCH/DELETE の呼び出しから、概要はハンドラーのメソッドのコメントの1行目から、
+// リクエストの本文は ShouldBindJSON に渡す変数の型から、クエリパラメータは c.Query などの呼び出しから求める。
+// 実行するコマンドは internal/apidocs/gen にある。
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var httpMethods = map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true}
+
+// 仕様に含めるのは現在のバージョンのAPIのみ（バージョンなしの /api は v1 の別名）
+const apiPrefix = "/api/v1"
+
+// Generate root（backend ディレクトリ）のソースから仕様（openapi.json の内容）を生成する
+func Generate(root string) ([]byte, error) {
+	spec, err := newGenerator(root).generate()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Routes root の main.go で登録しているルート（仕様との食い違いを確認するために使う）
+func Routes(root string) (gin.RoutesInfo, error) {
+	routes, err := newGenerator(root).loadRoutes()
+	if err != nil {
+		return nil, err
+	}
+	info := make(gin.RoutesInfo, 0, len(routes))
+	for _, r := range routes {
+		info = append(info, gin.RouteInfo{Method: r.method, Path: r.path, Handler: r.handler + "." + r.function})
+	}
+	return info, nil
+}
+
+// route main.go で登録しているルート
+type route struct {
+	method   string
+	path     string // gin の形式（/tasks/:id）
+	handler  string // ハンドラーの型（TaskHandler）
+	function string // メソッド（GetTask）
+	secured  bool   // 認証が必要か
+}
+
+type generator struct {
+	root  string
+	fset  *token.FileSet
+	pkgs  map[string]map[string]*ast.TypeSpec // パッケージ名 → 型名 → 定義
+	enums map[string][]string                 // "models.TaskStatus" → 定数の値
+	// ハンドラーのメソッド（"TaskHandler.GetTask"）と、パッケージレベルの関数
+	methods map[string]*ast.FuncDecl
+	funcs   map[string]*ast.FuncDecl
+
+	schemas map[string]interface{}
+}
+
+func newGenerator(root string) *generator {
+	return &generator{
+		root:    root,
+		fset:    token.NewFileSet(),
+		pkgs:    map[string]map[string]*ast.TypeSpec{},
+		enums:   map[string][]string{},
+		methods: map[string]*ast.FuncDecl{},
+		funcs:   map[string]*ast.FuncDecl{},
+		schemas: map[string]interface{}{},
+	}
+}
+
+func (g *generator) generate() (map[string]interface{}, error) {
+	for _, pkg := range []string{"handlers", "services", "models"} {
+		if err := g.loadPackage(pkg); err != nil {
+			return nil, err
+		}
+	}
+	routes, err := g.loadRoutes()
+	if err != nil {
+		return nil, err
+	}
+
+	paths := map[string]map[string]interface{}{}
+	for _, r := range routes {
+		if !strings.HasPrefix(r.path, apiPrefix+"/") {
+			continue
+		}
+		path, params := openAPIPath(r.path)
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(r.method)] = g.operation(r, params)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "TaskCalendar API",
+			"version": "1.0.0",
+			"description": "/api/v1 の仕様。/api/v2 は同じルートで、一覧の応答のみ data（要素の配列）と " +
+				"meta（requestId・count・nextCursor・hasMore、includeTotal=true の場合は total）の形で返す。",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": g.schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}, nil
+}
+
+// loadPackage internal/<pkg> の型定義・定数・関数を読み込む
+func (g *generator) loadPackage(pkg string) error {
+	dir := filepath.Join(g.root, "internal", pkg)
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+	types := map[string]*ast.TypeSpec{}
+	g.pkgs[pkg] = types
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(g.fset, file, nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				g.loadGenDecl(pkg, d, types)
+			case *ast.FuncDecl:
+				if pkg != "handlers" {
+					continue
+				}
+				if d.Recv == nil {
+					g.funcs[d.Name.Name] = d
+				} else if name := receiverName(d.Recv); name != "" {
+					g.methods[name+"."+d.Name.Name] = d
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (g *generator) loadGenDecl(pkg string, d *ast.GenDecl, types map[string]*ast.TypeSpec) {
+	switch d.Tok {
+	case token.TYPE:
+		for _, spec := range d.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if ts.Doc == nil && len(d.Specs) == 1 {
+				ts.Doc = d.Doc
+			}
+			types[ts.Name.Name] = ts
+		}
+	case token.CONST:
+		// 型を明示した文字列の定数を列挙値として扱う
+		var typeName string
+		for _, spec := range d.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if ident, ok := vs.Type.(*ast.Ident); ok {
+				typeName = ident.Name
+			} else if vs.Type != nil {
+				typeName = ""
+			}
+			if typeName == "" || len(vs.Values) != 1 {
+				continue
+			}
+			if lit, ok := vs.Values[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				value, _ := strconv.Unquote(lit.Value)
+				key := pkg + "." + typeName
+				g.enums[key] = append(g.enums[key], value)
+			}
+		}
+	}
+}
+
+// loadRoutes main.go の main 関数からルートを読み取る
+func (g *generator) loadRoutes() ([]route, error) {
+	f, err := parser.ParseFile(g.fset, filepath.Join(g.root, "main.go"), nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	w := &routeWalker{
+		groups:     map[string]string{},
+		secured:    map[string]bool{},
+		handlers:   map[string]string{},
+		registrars: map[string]*ast.FuncLit{},
+	}
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+			ast.Inspect(fn.Body, w.visit)
+		}
+	}
+	return w.routes, nil
+}
+
+// routeWalker ルーターとグループの変数を追いながらルートの登録を集める
+type routeWalker struct {
+	groups   map[string]string // 変数名 → パスのプレフィックス
+	secured  map[string]bool   // 認証のミドルウェアを使うグループ
+	handlers map[string]string // 変数名 → ハンドラーの型
+	// グループを受け取ってルートを登録する関数（APIのバージョンごとの registerV1 など）。呼び出したときに本体をたどる
+	registrars map[string]*ast.FuncLit
+	routes     []route
+}
+
+func (w *routeWalker) visit(n ast.Node) bool {
+	switch node := n.(type) {
+	case *ast.AssignStmt:
+		if len(node.Lhs) != 1 || len(node.Rhs) != 1 {
+			return true
+		}
+		name, ok := node.Lhs[0].(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if lit, ok := node.Rhs[0].(*ast.FuncLit); ok && len(lit.Type.Params.List) == 1 && len(lit.Type.Params.List[0].Names) == 1 {
+			w.registrars[name.Name] = lit
+			return false
+		}
+		call, ok := node.Rhs[0].(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if fun, ok := call.Fun.(*ast.SelectorExpr); ok {
+			pkg, _ := fun.X.(*ast.Ident)
+			switch {
+			case pkg != nil && pkg.Name == "gin" && (fun.Sel.Name == "Default" || fun.Sel.Name == "New"):
+				w.groups[name.Name] = ""
+			case pkg != nil && pkg.Name == "handlers" && strings.HasPrefix(fun.Sel.Name, "New"):
+				w.handlers[name.Name] = strings.TrimPrefix(fun.Sel.Name, "New")
+			default:
+				if prefix, secured, ok := w.group(call); ok {
+					w.groups[name.Name] = prefix
+					w.secured[name.Name] = secured
+				}
+			}
+		}
+	case *ast.CallExpr:
+		if ident, ok := node.Fun.(*ast.Ident); ok && w.registrars[ident.Name] != nil && len(node.Args) == 1 {
+			w.register(w.registrars[ident.Name], node.Args[0])
+			return false
+		}
+		fun, ok := node.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		group, ok := fun.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		prefix, known := w.groups[group.Name]
+		if !known {
+			return true
+		}
+		if fun.Sel.Name == "Use" {
+			if usesAuth(node.Args) {
+				w.secured[group.Name] = true
+			}
+			return true
+		}
+		if !httpMethods[fun.Sel.Name] || len(node.Args) < 2 {
+			return true
+		}
+		path, ok := stringLit(node.Args[0])
+		if !ok {
+			return true
+		}
+		r := route{method: fun.Sel.Name, path: joinPath(prefix, path), secured: w.secured[group.Name] || usesAuth(node.Args[1:])}
+		if sel, ok := node.Args[len(node.Args)-1].(*ast.SelectorExpr); ok {
+			if recv, ok := sel.X.(*ast.Ident); ok {
+				r.handler = w.handlers[recv.Name]
+				r.function = sel.Sel.Name
+			}
+		}
+		w.routes = append(w.routes, r)
+	}
+	return true
+}
+
+// group グループの変数、または x.Group("/prefix") の呼び出しが表すパスのプレフィックスと、認証が必要か
+func (w *routeWalker) group(expr ast.Expr) (string, bool, bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		prefix, known := w.groups[e.Name]
+		return prefix, w.secured[e.Name], known
+	case *ast.CallExpr:
+		fun, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok || fun.Sel.Name != "Group" || len(e.Args) == 0 {
+			return "", false, false
+		}
+		parent, secured, known := w.group(fun.X)
+		prefix, ok := stringLit(e.Args[0])
+		if !known || !ok {
+			return "", false, false
+		}
+		return joinPath(parent, prefix), secured || usesAuth(e.Args[1:]), true
+	}
+	return "", false, false
+}
+
+// register ルートを登録する関数の本体を、引数のグループを渡したものとしてたどる
+func (w *routeWalker) register(lit *ast.FuncLit, arg ast.Expr) {
+	prefix, secured, ok := w.group(arg)
+	if !ok {
+		return
+	}
+	param := lit.Type.Params.List[0].Names[0].Name
+	savedPrefix, hadPrefix := w.groups[param]
+	savedSecured := w.secured[param]
+	w.groups[param] = prefix
+	w.secured[param] = secured
+	ast.Inspect(lit.Body, w.visit)
+	if hadPrefix {
+		w.groups[param] = savedPrefix
+		w.secured[param] = savedSecured
+	} else {
+		delete(w.groups, param)
+		delete(w.secured, param)
+	}
+}
+
+// usesAuth ミドルウェアに middleware.AuthMiddleware が含まれるか
+func usesAuth(args []ast.Expr) bool {
+	for _, arg := range args {
+		if call, ok := arg.(*ast.CallExpr); ok {
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "AuthMiddleware" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// operation ルートの説明・パラメータ・リクエストの本文・応答
+func (g *generator) operation(r route, pathParams []string) map[string]interface{} {
+	op := map[string]interface{}{}
+	if tag := routeTag(r.path); tag != "" {
+		op["tags"] = []string{tag}
+	}
+	if r.secured {
+		op["security"] = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	var params []map[string]interface{}
+	for _, name := range pathParams {
+		params = append(params, map[string]interface{}{
+			"name": name, "in": "path", "required": true, "schema": map[string]string{"type": "string"},
+		})
+	}
+
+	status := "200"
+	fn := g.methods[r.handler+"."+r.function]
+	if fn != nil {
+		summary, description := docText(fn.Doc, r.function)
+		if summary != "" {
+			op["summary"] = summary
+		}
+		if description != "" {
+			op["description"] = description
+		}
+		for _, name := range g.queryParams(fn.Body, nil, 0) {
+			params = append(params, map[string]interface{}{
+				"name": name, "in": "query", "schema": map[string]string{"type": "string"},
+			})
+		}
+		if schema := g.requestSchema(fn); schema != nil {
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}},
+			}
+		}
+		status = successStatus(fn.Body)
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	op["responses"] = map[string]interface{}{
+		status:    map[string]string{"description": http204Description(status)},
+		"default": map[string]interface{}{"description": "エラー（error にメッセージ）"},
+	}
+	return op
+}
+
+func http204Description(status string) string {
+	if status == "204" {
+		return "成功（本文なし）"
+	}
+	return "成功"
+}
+
+// queryParams ハンドラー（と、そこから呼ぶパッケージ内の関数）で読むクエリパラメータ
+func (g *generator) queryParams(body *ast.BlockStmt, args map[string]string, depth int) []string {
+	if body == nil || depth > 3 {
+		return nil
+	}
+	seen := map[string]bool{}
+	var names []string
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	resolve := func(expr ast.Expr) string {
+		if s, ok := stringLit(expr); ok {
+			return s
+		}
+		if ident, ok := expr.(*ast.Ident); ok {
+			return args[ident.Name]
+		}
+		return ""
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		switch fun := call.Fun.(type) {
+		case *ast.SelectorExpr:
+			switch fun.Sel.Name {
+			case "Query", "DefaultQuery", "GetQuery", "QueryArray":
+				if len(call.Args) > 0 {
+					add(resolve(call.Args[0]))
+				}
+			}
+		case *ast.Ident:
+			decl := g.funcs[fun.Name]
+			if decl == nil {
+				return true
+			}
+			// 呼び出し先の引数名に、呼び出し元で渡した文字列を対応づける
+			mapping := map[string]string{}
+			i := 0
+			for _, field := range decl.Type.Params.List {
+				for _, name := range field.Names {
+					if i < len(call.Args) {
+						mapping[name.Name] = resolve(call.Args[i])
+					}
+					i++
+				}
+			}
+			for _, name := range g.queryParams(decl.Body, mapping, depth+1) {
+				add(name)
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// requestSchema ShouldBindJSON に渡す変数の型のスキーマ
+func (g *generator) requestSchema(fn *ast.FuncDecl) interface{} {
+	bound := ""
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		// c.ShouldBindJSON(&req)、または本文を読み込むパッケージ内の関数（bindMergePatch(c, &req) など）
+		binds := false
+		switch fun := call.Fun.(type) {
+		case *ast.SelectorExpr:
+			binds = fun.Sel.Name == "ShouldBindJSON"
+		case *ast.Ident:
+			binds = g.funcs[fun.Name] != nil && bindsJSON(g.funcs[fun.Name].Body)
+		}
+		if !binds {
+			return true
+		}
+		for _, arg := range call.Args {
+			if u, ok := arg.(*ast.UnaryExpr); ok && u.Op == token.AND {
+				if ident, ok := u.X.(*ast.Ident); ok {
+					bound = ident.Name
+				}
+			}
+		}
+		return true
+	})
+	if bound == "" {
+		return nil
+	}
+	var schema interface{}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		vs, ok := n.(*ast.ValueSpec)
+		if !ok || vs.Type == nil {
+			return true
+		}
+		for _, name := range vs.Names {
+			if name.Name == bound {
+				schema = g.typeSchema("handlers", vs.Type)
+			}
+		}
+		return true
+	})
+	return schema
+}
+
+// bindsJSON 関数が本文をJSONとして読み込むか
+func bindsJSON(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "ShouldBindJSON" {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// typeSchema Goの型をJSONスキーマにする（パッケージ内の構造体は components に登録して参照する）
+func (g *generator) typeSchema(pkg string, expr ast.Expr) interface{} {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if schema := basicSchema(t.Name); schema != nil {
+			return schema
+		}
+		return g.namedSchema(pkg, t.Name)
+	case *ast.SelectorExpr:
+		x, _ := t.X.(*ast.Ident)
+		if x == nil {
+			return map[string]interface{}{}
+		}
+		switch x.Name + "." + t.Sel.Name {
+		case "time.Time":
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		case "time.Duration":
+			return map[string]interface{}{"type": "integer"}
+		}
+		if _, ok := g.pkgs[x.Name]; ok {
+			return g.namedSchema(x.Name, t.Sel.Name)
+		}
+		return map[string]interface{}{}
+	case *ast.StarExpr:
+		schema := g.typeSchema(pkg, t.X)
+		if m, ok := schema.(map[string]interface{}); ok {
+			if _, isRef := m["$ref"]; !isRef {
+				nullable := map[string]interface{}{"nullable": true}
+				for k, v := range m {
+					nullable[k] = v
+				}
+				return nullable
+			}
+		}
+		return schema
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.typeSchema(pkg, t.Elt)}
+	case *ast.MapType:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.typeSchema(pkg, t.Value)}
+	case *ast.StructType:
+		return g.structSchema(pkg, t)
+	}
+	return map[string]interface{}{}
+}
+
+func basicSchema(name string) map[string]interface{} {
+	switch name {
+	case "string":
+		return map[string]interface{}{"type": "string"}
+	case "bool":
+		return map[string]interface{}{"type": "boolean"}
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return map[string]interface{}{"type": "integer"}
+	case "float32", "float64":
+		return map[string]interface{}{"type": "number"}
+	case "any":
+		return map[string]interface{}{}
+	}
+	return nil
+}
+
+// namedSchema パッケージの型（構造体は参照、文字列などの型は列挙値つきのスキーマ）
+func (g *generator) namedSchema(pkg, name string) interface{} {
+	ts := g.pkgs[pkg][name]
+	if ts == nil {
+		return map[string]interface{}{}
+	}
+	if _, ok := ts.Type.(*ast.StructType); !ok {
+		schema, _ := g.typeSchema(pkg, ts.Type).(map[string]interface{})
+		if values := g.enums[pkg+"."+name]; len(values) > 0 && schema["type"] == "string" {
+			schema["enum"] = values
+		}
+		return schema
+	}
+	key := pkg + "." + name
+	ref := map[string]interface{}{"$ref": "#/components/schemas/" + key}
+	if _, done := g.schemas[key]; done {
+		return ref
+	}
+	// 再帰する型のため、先に登録しておく
+	g.schemas[key] = map[string]interface{}{}
+	schema := g.structSchema(pkg, ts.Type.(*ast.StructType))
+	if summary, _ := docText(ts.Doc, name); summary != "" {
+		schema["description"] = summary
+	}
+	g.schemas[key] = schema
+	return ref
+}
+
+// structSchema 構造体のJSONでの形（json タグの名前、binding タグの検証ルールを必須・制約として扱う）
+func (g *generator) structSchema(pkg string, st *ast.StructType) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	for _, field := range st.Fields.List {
+		tag := ""
+		if field.Tag != nil {
+			tag, _ = strconv.Unquote(field.Tag.Value)
+		}
+		jsonTag := reflect.StructTag(tag).Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		if len(field.Names) == 0 {
+			// 埋め込んだ構造体のフィールドは同じ階層に並ぶ
+			if embedded := g.embeddedStruct(pkg, field.Type); embedded != nil {
+				for k, v := range embedded["properties"].(map[string]interface{}) {
+					properties[k] = v
+				}
+				if req, ok := embedded["required"].([]string); ok {
+					required = append(required, req...)
+				}
+			}
+			continue
+		}
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			jsonName := strings.Split(jsonTag, ",")[0]
+			if jsonName == "" {
+				jsonName = name.Name
+			}
+			schema := g.typeSchema(pkg, field.Type)
+			if field.Comment != nil {
+				if m, ok := schema.(map[string]interface{}); ok {
+					if _, isRef := m["$ref"]; !isRef {
+						m = copySchema(m)
+						m["description"] = strings.TrimSpace(field.Comment.Text())
+						schema = m
+					}
+				}
+			}
+			schema, isRequired := applyBinding(schema, reflect.StructTag(tag).Get("binding"))
+			properties[jsonName] = schema
+			if isRequired {
+				required = append(required, jsonName)
+			}
+		}
+	}
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// applyBinding binding タグの検証ルール（max・oneof など）をスキーマの制約にする
+func applyBinding(schema interface{}, binding string) (interface{}, bool) {
+	m, ok := schema.(map[string]interface{})
+	if _, isRef := m["$ref"]; !ok || isRef || binding == "" {
+		return schema, strings.Contains(","+binding+",", ",required,")
+	}
+	m = copySchema(m)
+	required := false
+	for _, rule := range strings.Split(binding, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "dive" {
+			// 以降は配列の要素のルール
+			break
+		}
+		switch name {
+		case "required":
+			required = true
+		case "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			key := map[string]map[string]string{
+				"string":  {"min": "minLength", "max": "maxLength"},
+				"array":   {"min": "minItems", "max": "maxItems"},
+				"integer": {"min": "minimum", "max": "maximum"},
+				"number":  {"min": "minimum", "max": "maximum"},
+			}[fmt.Sprint(m["type"])][name]
+			if key != "" {
+				m[key] = n
+			}
+		case "notblank":
+			m["minLength"] = 1
+		case "oneof":
+			m["enum"] = strings.Fields(param)
+		case "email":
+			m["format"] = "email"
+		case "url", "http_url":
+			m["format"] = "uri"
+		case "timezone":
+			m["format"] = "timezone"
+		case "color":
+			m["pattern"] = "^#?([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$"
+		case "datetime":
+			if param == "15:04" {
+				m["pattern"] = "^[0-2][0-9]:[0-5][0-9]$"
+			}
+		case "unique":
+			m["uniqueItems"] = true
+		}
+	}
+	return m, required
+}
+
+func (g *generator) embeddedStruct(pkg string, expr ast.Expr) map[string]interface{} {
+	name := ""
+	switch t := expr.(type) {
+	case *ast.Ident:
+		name = t.Name
+	case *ast.SelectorExpr:
+		if x, ok := t.X.(*ast.Ident); ok {
+			pkg, name = x.Name, t.Sel.Name
+		}
+	case *ast.StarExpr:
+		return g.embeddedStruct(pkg, t.X)
+	}
+	ts := g.pkgs[pkg][name]
+	if ts == nil {
+		return nil
+	}
+	st, ok := ts.Type.(*ast.StructType)
+	if !ok {
+		return nil
+	}
+	return g.structSchema(pkg, st)
+}
+
+func copySchema(m map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(m)+1)
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+// successStatus ハンドラーが成功時に返すステータス（c.JSON・c.Status に渡す2xxのうち最初のもの）
+func successStatus(body *ast.BlockStmt) string {
+	status := ""
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || status != "" || len(call.Args) == 0 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || (sel.Sel.Name != "JSON" && sel.Sel.Name != "Status") {
+			return true
+		}
+		if code, ok := call.Args[0].(*ast.SelectorExpr); ok {
+			switch code.Sel.Name {
+			case "StatusOK":
+				status = "200"
+			case "StatusCreated":
+				status = "201"
+			case "StatusAccepted":
+				status = "202"
+			case "StatusNoContent":
+				status = "204"
+			}
+		}
+		return true
+	})
+	if status == "" {
+		return "200"
+	}
+	return status
+}
+
+// docText doc コメントの1行目（先頭の識別子を除く）と、2行目以降
+func docText(doc *ast.CommentGroup, name string) (string, string) {
+	if doc == nil {
+		return "", ""
+	}
+	text := strings.TrimSpace(doc.Text())
+	summary, description, _ := strings.Cut(text, "\n")
+	summary = strings.TrimSpace(strings.TrimPrefix(summary, name))
+	return summary, strings.TrimSpace(description)
+}
+
+// openAPIPath gin のパス（/tasks/:id）を OpenAPI の形式（/tasks/{id}）にし、パスパラメータを返す
+func openAPIPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			name := s[1:]
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// routeTag パスの /api の次の区切り（tasks・events など）
+func routeTag(path string) string {
+	rest := strings.TrimPrefix(path, apiPrefix+"/")
+	tag, _, _ := strings.Cut(rest, "/")
+	if strings.HasPrefix(tag, ":") {
+		return ""
+	}
+	return tag
+}
+
+func joinPath(prefix, path string) string {
+	joined := strings.TrimRight(prefix, "/") + "/" + strings.TrimLeft(path, "/")
+	if joined != "/" {
+		joined = strings.TrimRight(joined, "/")
+	}
+	return joined
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+func receiverName(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+	t := recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	if ident, ok := t.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+// Package apierror APIのエラー応答の形式（code・message・details・requestId）
+package apierror
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Code エラーの種類（クライアントが分岐に使う。message は表示用で、言語や文言が変わることがある）
+type Code string
+
+const (
+	CodeValidation           Code = "VALIDATION_FAILED"
+	CodeUnauthorized         Code = "UNAUTHORIZED"
+	CodeForbidden            Code = "FORBIDDEN"
+	CodeNotFound             Code = "NOT_FOUND"
+	CodeConflict             Code = "CONFLICT"
+	CodeScheduleConflict     Code = "SCHEDULE_CONFLICT" // 参加者・会議室の予定と重複する
+	CodeVersionConflict      Code = "VERSION_CONFLICT"  // 他のユーザーが先に更新した
+	CodePreconditionFailed   Code = "PRECONDITION_FAILED"
+	CodePayloadTooLarge      Code = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMediaType Code = "UNSUPPORTED_MEDIA_TYPE"
+	CodeUnprocessable        Code = "UNPROCESSABLE"
+	CodeRateLimited          Code = "RATE_LIMITED"
+	CodeResyncRequired       Code = "RESYNC_REQUIRED" // 差分同期の since が古い（全件を取り直す）
+	CodeInternal             Code = "INTERNAL"
+)
+
+// Detail エラーの詳細（入力の検証エラーでは項目ごと）
+type Detail struct {
+	Field   string `json:"field,omitempty"`
+	Reason  string `json:"reason"`
+	Message string `json:"message,omitempty"`
+}
+
+// Response エラー応答の本文
+type Response struct {
+	Code      Code     `json:"code"`
+	Message   string   `json:"message"`
+	Details   []Detail `json:"details"`
+	RequestID string   `json:"requestId"`
+	// Conflicts 重複する予定（SCHEDULE_CONFLICT）
+	Conflicts interface{} `json:"conflicts,omitempty"`
+	// Current サーバーの最新の状態（VERSION_CONFLICT）
+	Current interface{} `json:"current,omitempty"`
+}
+
+// New エラー応答を作る（requestId は RequestID ミドルウェアが設定した値）
+//
+// message は日本語で渡し、Accept-Language で選んだ言語にする（選んだ言語は Content-Language で返す）。
+// details のメッセージは呼び出し側で i18n.FromContext の言語にしておく。
+func New(c *gin.Context, code Code, message string, details ...Detail) *Response {
+	if details == nil {
+		details = []Detail{}
+	}
+	lang := i18n.FromContext(c)
+	c.Header("Content-Language", string(lang))
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	return &Response{Code: code, Message: Translate(lang, code, message), Details: details, RequestID: c.GetString("requestID")}
+}
+
+// Respond ステータスに対応する code でエラー応答を返す
+func Respond(c *gin.Context, status int, message string, details ...Detail) {
+	c.JSON(status, New(c, CodeForStatus(status), message, details...))
+}
+
+// Abort エラー応答を返し、以降のハンドラーを実行しない（ミドルウェア向け）
+func Abort(c *gin.Context, status int, message string) {
+	c.AbortWithStatusJSON(status, New(c, CodeForStatus(status), message))
+}
+
+// CodeForStatus HTTPステータスに対応する既定の code
+func CodeForStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeValidation
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case http.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	}
+	return CodeInternal
+}
@@ -0,0 +1,73 @@
+package apierror
+
+import "task-calendar-backend/internal/i18n"
+
+// codeMessages code ごとの既定のメッセージ（個別のメッセージの翻訳がない場合に使う）
+var codeMessages = map[Code]map[i18n.Lang]string{
+	CodeValidation:           {i18n.Japanese: "入力内容に誤りがあります", i18n.English: "The request contains invalid input."},
+	CodeUnauthorized:         {i18n.Japanese: "認証に失敗しました", i18n.English: "Authentication failed."},
+	CodeForbidden:            {i18n.Japanese: "この操作を行う権限がありません", i18n.English: "You do not have permission to perform this operation."},
+	CodeNotFound:             {i18n.Japanese: "リソースが見つかりません", i18n.English: "The resource was not found."},
+	CodeConflict:             {i18n.Japanese: "他の操作と競合しました", i18n.English: "The request conflicts with the current state of the resource."},
+	CodeScheduleConflict:     {i18n.Japanese: "他の予定と重複しています", i18n.English: "The schedule conflicts with other events."},
+	CodeVersionConflict:      {i18n.Japanese: "他のユーザーが先に更新しました", i18n.English: "Another user updated this resource first. Fetch the latest version and try again."},
+	CodePreconditionFailed:   {i18n.Japanese: "リソースが他の操作で更新されています", i18n.English: "The resource has been modified by another operation."},
+	CodePayloadTooLarge:      {i18n.Japanese: "リクエストの本文が大きすぎます", i18n.English: "The request body is too large."},
+	CodeUnsupportedMediaType: {i18n.Japanese: "Content-Typeに対応していません", i18n.English: "The Content-Type is not supported."},
+	CodeUnprocessable:        {i18n.Japanese: "リクエストを処理できません", i18n.English: "The request could not be processed."},
+	CodeRateLimited:          {i18n.Japanese: "リクエストが多すぎます", i18n.English: "Too many requests. Please try again later."},
+	CodeResyncRequired:       {i18n.Japanese: "全件の取り直しが必要です", i18n.English: "A full resync is required."},
+	CodeInternal:             {i18n.Japanese: "サーバーでエラーが発生しました", i18n.English: "An internal server error occurred."},
+}
+
+// messageTranslations 日本語のメッセージの翻訳（決まった文言のメッセージのみ。値を埋め込んだメッセージは code の既定のメッセージにする）
+var messageTranslations = map[string]map[i18n.Lang]string{
+	"入力内容に誤りがあります":                                       {i18n.English: "The request contains invalid input."},
+	"入力内容が正しくありません":                                      {i18n.English: "The request contains invalid input."},
+	"本文のJSONが不正です":                                       {i18n.English: "The request body is not valid JSON."},
+	"本文を指定してください":                                        {i18n.English: "The request body is required."},
+	"本文はJSONのオブジェクトで指定してください":                            {i18n.English: "The request body must be a JSON object."},
+	"リクエストの本文を読み込めません":                                   {i18n.English: "The request body could not be read."},
+	"Content-Typeはapplication/merge-patch+jsonで指定してください": {i18n.English: "The Content-Type must be application/merge-patch+json."},
+	"ファイルサイズが上限を超えています":                                  {i18n.English: "The file exceeds the maximum size."},
+	"このファイル形式はアップロードできません":                               {i18n.English: "This file type cannot be uploaded."},
+	"multipart/form-dataの形式が不正です":                        {i18n.English: "The multipart/form-data body is malformed."},
+	"fileが必要です":                                          {i18n.English: "file is required."},
+	"sinceが古すぎます。sinceを指定せずに全件を取り直してください":                {i18n.English: "since is too old. Omit since and fetch everything again."},
+	"sinceが不正です":                                         {i18n.English: "since is invalid."},
+	"codeは必須です":                                          {i18n.English: "code is required."},
+	"tzが不正です":                                            {i18n.English: "tz is invalid."},
+	"expandが不正です":                                        {i18n.English: "expand is invalid."},
+	"dryRunが不正です":                                        {i18n.English: "dryRun is invalid."},
+	"minCapacityが不正です":                                   {i18n.English: "minCapacity is invalid."},
+	"limitは正の整数で指定してください":                                {i18n.English: "limit must be a positive integer."},
+	"認証に失敗しました":                                          {i18n.English: "Authentication failed."},
+	"ユーザーが見つかりません":                                       {i18n.English: "The user was not found."},
+	"管理者権限が必要です":                                         {i18n.English: "Administrator privileges are required."},
+	"リソースが見つかりません":                                       {i18n.English: "The resource was not found."},
+	"この操作を行う権限がありません":                                    {i18n.English: "You do not have permission to perform this operation."},
+	"リソースが他の操作で更新されています":                                 {i18n.English: "The resource has been modified by another operation."},
+	"他の予定と重複しています":                                       {i18n.English: "The schedule conflicts with other events."},
+	"他のユーザーが先に更新しました。最新の内容を取得してください":                     {i18n.English: "Another user updated this resource first. Fetch the latest version and try again."},
+	"リクエストが多すぎます。しばらくしてから再度お試しください":                      {i18n.English: "Too many requests. Please try again later."},
+	"指定されたAPIは存在しません":                                    {i18n.English: "The requested API does not exist."},
+	"メール受信は無効です":                                         {i18n.English: "Inbound email is disabled."},
+	"Slack連携は無効です":                                       {i18n.English: "The Slack integration is disabled."},
+}
+
+// Translate メッセージを lang の言語にする
+//
+// 日本語はそのまま返す。決まった文言のメッセージは翻訳し、翻訳のないメッセージは code の既定のメッセージにする
+// （クライアントは code で分岐するため、メッセージが変わっても動作は変わらない）。
+func Translate(lang i18n.Lang, code Code, message string) string {
+	if lang == i18n.Japanese {
+		return message
+	}
+	if translated, ok := messageTranslations[message][lang]; ok {
+		return translated
+	}
+	if translated, ok := codeMessages[code][lang]; ok {
+		return translated
+	}
+	return message
+}
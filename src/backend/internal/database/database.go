@@ -0,0 +1,107 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// 接続先の種類（gorm.Dialector.Name() の値）
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// Options 接続プールとレプリカの設定（0の項目は database/sql の既定値のまま）
+type Options struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// 読み取り専用のクエリ（Read）を振り分けるレプリカ（PostgreSQLのみ）
+	ReplicaURLs []string
+}
+
+// Connect データベースに接続する
+//
+// DATABASE_URL が「sqlite:」で始まる場合はSQLite（ローカルの開発・テスト用。例: sqlite:task_calendar.db、sqlite::memory:）、
+// それ以外はPostgreSQLに接続する。本番環境はPostgreSQLを使う。接続プールの設定はレプリカにも同じものを使う。
+func Connect(databaseURL string, opts Options) (*gorm.DB, error) {
+	if path, ok := strings.CutPrefix(databaseURL, "sqlite:"); ok {
+		if len(opts.ReplicaURLs) > 0 {
+			return nil, errors.New("SQLiteではレプリカを使えません")
+		}
+		return open(sqlite.Open(sqliteDSN(path)), opts)
+	}
+	db, err := open(postgres.Open(databaseURL), opts)
+	if err != nil {
+		return nil, err
+	}
+	pools := make([]gorm.ConnPool, 0, len(opts.ReplicaURLs))
+	for i, replicaURL := range opts.ReplicaURLs {
+		replica, err := open(postgres.Open(replicaURL), opts)
+		if err != nil {
+			return nil, fmt.Errorf("レプリカ（%d番目）に接続できません: %w", i+1, err)
+		}
+		pools = append(pools, replica.ConnPool)
+	}
+	registerReplicas(db, pools)
+	return db, nil
+}
+
+// open 接続し、接続プールを設定する
+func open(dialector gorm.Dialector, opts Options) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	if opts.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(opts.MaxOpenConns)
+	}
+	if opts.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(opts.MaxIdleConns)
+	}
+	if opts.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	}
+	if opts.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+	}
+	return db, nil
+}
+
+// sqliteDSN 外部キーの制約を有効にし、ロック中は待つようにしたSQLiteの接続文字列
+//
+// :memory: は接続ごとに別のデータベースになるため、接続プールの接続で共有するようにする。
+func sqliteDSN(path string) string {
+	if path == ":memory:" {
+		path = "file::memory:?cache=shared"
+	}
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return path + separator + "_foreign_keys=on&_busy_timeout=5000"
+}
+
+// IsSQLite SQLiteに接続しているか（PostgreSQL固有のSQLを使う処理の切り替えに使う）
+func IsSQLite(db *gorm.DB) bool {
+	return db.Dialector.Name() == DialectSQLite
+}
+
+// Greatest 引数のうち最も大きい値を返すSQLの関数名（PostgreSQLは GREATEST、SQLiteは複数の引数を取る MAX）
+func Greatest(db *gorm.DB) string {
+	if IsSQLite(db) {
+		return "MAX"
+	}
+	return "GREATEST"
+}
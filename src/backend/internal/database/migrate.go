@@ -0,0 +1,336 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// マイグレーション
+//
+// migrations/ の「<バージョン>_<名前>.up.sql」で適用し、「.down.sql」で取り消す。適用したバージョンは schema_migrations に記録する。
+// AutoMigrate と違い、列の名前の変更やデータの移行も書ける。スキーマを変える場合は、次のバージョンの up・down を追加する。
+// SQLiteで書き方が異なる場合は「.up.sqlite.sql」「.down.sqlite.sql」を置くと、SQLiteではそちらを使う。
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)(\.sqlite)?\.sql$`)
+
+// マイグレーションを同時に実行しないためのアドバイザリーロックのキー
+const migrationLockKey = 72401
+
+// アドバイザリーロックを取得・解放するSQL（PostgreSQL）
+var (
+	migrationLockSQL   = "SELECT pg_advisory_lock(?)"
+	migrationUnlockSQL = "SELECT pg_advisory_unlock(?)"
+)
+
+// ErrPendingMigrations 適用していないマイグレーションがある
+var ErrPendingMigrations = errors.New("適用していないマイグレーションがあります")
+
+// Migration バージョンごとのスキーマの変更
+type Migration struct {
+	Version int64
+	Name    string
+	up      string
+	down    string
+	// SQLite用（空の場合は up・down を使う）
+	sqliteUp   string
+	sqliteDown string
+}
+
+// upSQL 接続先に合わせた適用のSQL
+func (m Migration) upSQL(db *gorm.DB) string {
+	if IsSQLite(db) && m.sqliteUp != "" {
+		return m.sqliteUp
+	}
+	return m.up
+}
+
+// downSQL 接続先に合わせた取り消しのSQL
+func (m Migration) downSQL(db *gorm.DB) string {
+	if IsSQLite(db) && m.sqliteDown != "" {
+		return m.sqliteDown
+	}
+	return m.down
+}
+
+// MigrationStatus マイグレーションの適用の状況
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"appliedAt"` // 適用していない場合は nil
+}
+
+// schemaMigration 適用したマイグレーションの記録
+type schemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrations 組み込まれたマイグレーション（バージョンの順）
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("マイグレーションのファイル名が不正です: %s", entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		data, err := migrationFiles.ReadFile("migrations/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("マイグレーションのバージョン %d が重複しています", version)
+		}
+		switch {
+		case match[3] == "up" && match[4] == "":
+			m.up = string(data)
+		case match[3] == "up":
+			m.sqliteUp = string(data)
+		case match[4] == "":
+			m.down = string(data)
+		default:
+			m.sqliteDown = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("マイグレーション %d_%s には up と down の両方が必要です", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// MigrateUp 適用していないマイグレーションをバージョンの順に適用する（適用したものを返す）
+//
+// マイグレーションごとにトランザクションで実行し、失敗した場合はそのマイグレーションの変更を戻して止まる。
+func MigrateUp(db *gorm.DB) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	return migrateUp(db, migrations)
+}
+
+// migrateUp migrations のうち適用していないものを適用する
+func migrateUp(db *gorm.DB, migrations []Migration) ([]Migration, error) {
+	var applied []Migration
+	err := withMigrationLock(db, func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range migrations {
+			if _, ok := done[m.Version]; ok {
+				continue
+			}
+			if err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(m.upSQL(tx)).Error; err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+			}); err != nil {
+				return fmt.Errorf("マイグレーション %d_%s の適用に失敗しました: %w", m.Version, m.Name, err)
+			}
+			applied = append(applied, m)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// MigrateDown 適用したマイグレーションを新しいものから steps 件取り消す（取り消したものを返す）
+func MigrateDown(db *gorm.DB, steps int) ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	var reverted []Migration
+	err = withMigrationLock(db, func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for i := len(migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			m := migrations[i]
+			if _, ok := done[m.Version]; !ok {
+				continue
+			}
+			if err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(m.downSQL(tx)).Error; err != nil {
+					return err
+				}
+				return tx.Delete(&schemaMigration{}, "version = ?", m.Version).Error
+			}); err != nil {
+				return fmt.Errorf("マイグレーション %d_%s の取り消しに失敗しました: %w", m.Version, m.Name, err)
+			}
+			reverted = append(reverted, m)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// MigrationStatuses 組み込まれたマイグレーションと、データベースにだけ記録されている（新しいバージョンのサーバーが適用した）ものの状況
+func MigrationStatuses(db *gorm.DB) ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	var records []schemaMigration
+	if db.Migrator().HasTable(&schemaMigration{}) {
+		if err := db.Order("version").Find(&records).Error; err != nil {
+			return nil, err
+		}
+	}
+	byVersion := make(map[int64]schemaMigration, len(records))
+	for _, record := range records {
+		byVersion[record.Version] = record
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if record, ok := byVersion[m.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+			delete(byVersion, m.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range records {
+		if _, ok := byVersion[record.Version]; ok {
+			appliedAt := record.AppliedAt
+			statuses = append(statuses, MigrationStatus{Version: record.Version, Name: record.Name, AppliedAt: &appliedAt})
+		}
+	}
+	return statuses, nil
+}
+
+// CheckSchema 組み込まれたマイグレーションがすべて適用されているか（起動時の確認。未適用があれば ErrPendingMigrations）
+//
+// データベースにだけ記録されているバージョンは、新しいサーバーへの入れ替え中とみなしてエラーにしない。
+func CheckSchema(db *gorm.DB) error {
+	statuses, err := MigrationStatuses(db)
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, fmt.Sprintf("%d_%s", status.Version, status.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %s", ErrPendingMigrations, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// SchemaVersion 適用した最新のマイグレーションのバージョン（バックアップを作成したスキーマの記録と、復元できるかの確認に使う）
+func SchemaVersion(db *gorm.DB) (int64, error) {
+	var version int64
+	if err := db.Model(&schemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error; err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// withMigrationLock 1つの接続でアドバイザリーロックを取って fn を実行する（複数のサーバーが同時に起動した場合に備える）
+//
+// SQLiteはローカルの開発・テスト用で複数のサーバーから使わないため、ロックを取らない。
+func withMigrationLock(db *gorm.DB, fn func(conn *gorm.DB) error) error {
+	if IsSQLite(db) {
+		if err := ensureMigrationTable(db); err != nil {
+			return err
+		}
+		return fn(db)
+	}
+	return db.Connection(func(conn *gorm.DB) error {
+		return lockedMigration(conn, fn)
+	})
+}
+
+// lockedMigration conn でアドバイザリーロックを取って fn を実行し、fn が失敗した場合もロックを解放する
+//
+// ロックは接続に残るため、解放できなかった場合は fn が成功していてもエラーにする。
+func lockedMigration(conn *gorm.DB, fn func(conn *gorm.DB) error) (err error) {
+	if err := conn.Exec(migrationLockSQL, migrationLockKey).Error; err != nil {
+		return fmt.Errorf("マイグレーションのロックを取得できません: %w", err)
+	}
+	defer func() {
+		if unlockErr := conn.Exec(migrationUnlockSQL, migrationLockKey).Error; unlockErr != nil {
+			unlockErr = fmt.Errorf("マイグレーションのロックを解放できません: %w", unlockErr)
+			if err == nil {
+				err = unlockErr
+			} else {
+				log.Println(unlockErr)
+			}
+		}
+	}()
+	if err := ensureMigrationTable(conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// ensureMigrationTable schema_migrations を作成する
+//
+// AutoMigrate で作成した既存のデータベース（記録がなくテーブルがある）は、初期のスキーマ（バージョン1）を適用済みとして記録する。
+func ensureMigrationTable(conn *gorm.DB) error {
+	if conn.Migrator().HasTable(&schemaMigration{}) {
+		return nil
+	}
+	existing := conn.Migrator().HasTable("users")
+	return conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Migrator().CreateTable(&schemaMigration{}); err != nil {
+			return err
+		}
+		if !existing {
+			return nil
+		}
+		log.Println("AutoMigrateで作成したデータベースのため、初期のスキーマを適用済みとして記録します")
+		return tx.Create(&schemaMigration{Version: 1, Name: "initial_schema", AppliedAt: time.Now()}).Error
+	})
+}
+
+func appliedVersions(db *gorm.DB) (map[int64]struct{}, error) {
+	var versions []int64
+	if err := db.Model(&schemaMigration{}).Pluck("version", &versions).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[int64]struct{}, len(versions))
+	for _, version := range versions {
+		done[version] = struct{}{}
+	}
+	return done, nil
+}
@@ -0,0 +1,206 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// openTestDB 空のSQLiteのデータベース（外部キーの制約を有効にして開く）
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := Connect("sqlite:"+filepath.Join(t.TempDir(), "test.db"), Options{})
+	if err != nil {
+		t.Fatalf("接続に失敗しました: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// 空のデータベースにすべて適用し、すべて取り消し、もう一度適用できる（テーブルの作成・削除の順が外部キーに合っている）
+func TestMigrateUpDownUp(t *testing.T) {
+	db := openTestDB(t)
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := MigrateUp(db)
+	if err != nil {
+		t.Fatalf("適用に失敗しました: %v", err)
+	}
+	if len(applied) != len(migrations) {
+		t.Fatalf("適用したマイグレーションが %d 件です（%d 件のはず）", len(applied), len(migrations))
+	}
+	if err := CheckSchema(db); err != nil {
+		t.Fatalf("適用後のスキーマの確認に失敗しました: %v", err)
+	}
+
+	reverted, err := MigrateDown(db, len(migrations))
+	if err != nil {
+		t.Fatalf("取り消しに失敗しました: %v", err)
+	}
+	if len(reverted) != len(migrations) {
+		t.Fatalf("取り消したマイグレーションが %d 件です（%d 件のはず）", len(reverted), len(migrations))
+	}
+	for _, table := range []string{"users", "events", "team_chat_channels", "chat_event_posts", "calendar_subscriptions"} {
+		if db.Migrator().HasTable(table) {
+			t.Errorf("取り消した後もテーブル %s が残っています", table)
+		}
+	}
+
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatalf("取り消した後の再適用に失敗しました: %v", err)
+	}
+	if err := CheckSchema(db); err != nil {
+		t.Fatalf("再適用後のスキーマの確認に失敗しました: %v", err)
+	}
+}
+
+// チャットのチャンネルを登録でき、投稿の記録はチャンネルを参照する（チャンネルを削除すると記録も削除される）
+func TestMigrateChatChannelForeignKeys(t *testing.T) {
+	db := openTestDB(t)
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+
+	statements := []string{
+		`INSERT INTO users (id, email, username, password, first_name, last_name) VALUES ('u1', 'u1@example.com', 'u1', 'x', 'U', '1')`,
+		`INSERT INTO teams (id, name, creator_id) VALUES ('t1', 'Team', 'u1')`,
+		`INSERT INTO tasks (id, title, team_id, creator_id) VALUES ('k1', 'Task', 't1', 'u1')`,
+		`INSERT INTO team_chat_channels (id, provider, channel_id, team_id, creator_id) VALUES ('c1', 'SLACK', 'C0123456789', 't1', 'u1')`,
+		`INSERT INTO chat_task_posts (id, due_date, channel_id, task_id) VALUES ('p1', CURRENT_TIMESTAMP, 'c1', 'k1')`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+	if err := db.Exec(`INSERT INTO chat_task_posts (id, due_date, channel_id, task_id) VALUES ('p2', CURRENT_TIMESTAMP, 'missing', 'k1')`).Error; err == nil {
+		t.Error("存在しないチャンネルへの投稿の記録を作成できました")
+	}
+
+	if err := db.Exec(`DELETE FROM team_chat_channels WHERE id = 'c1'`).Error; err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err := db.Table("chat_task_posts").Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("チャンネルを削除した後も投稿の記録が %d 件残っています", count)
+	}
+}
+
+// 途中のマイグレーションが失敗した場合、そのマイグレーションの変更は戻り、以降は適用せずに止まる（直した後に続きから適用できる）
+func TestMigrateUpStopsAtFailedMigration(t *testing.T) {
+	db := openTestDB(t)
+	migrations := []Migration{
+		{Version: 1, Name: "first", up: `CREATE TABLE first (id TEXT)`, down: `DROP TABLE first`},
+		{Version: 2, Name: "broken", up: `CREATE TABLE second (id TEXT); INSERT INTO missing (id) VALUES ('x')`, down: `DROP TABLE second`},
+		{Version: 3, Name: "third", up: `CREATE TABLE third (id TEXT)`, down: `DROP TABLE third`},
+	}
+
+	applied, err := migrateUp(db, migrations)
+	if err == nil || !strings.Contains(err.Error(), "2_broken") {
+		t.Fatalf("エラーが %v です（2_broken の適用の失敗のはず）", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 1 {
+		t.Errorf("適用したマイグレーションが %v です（1のみのはず）", applied)
+	}
+	if db.Migrator().HasTable("second") || db.Migrator().HasTable("third") {
+		t.Error("失敗したマイグレーションの変更、または以降のマイグレーションが残っています")
+	}
+	if version, err := SchemaVersion(db); err != nil || version != 1 {
+		t.Errorf("記録したバージョンが %d です（1のはず）: %v", version, err)
+	}
+
+	migrations[1].up = `CREATE TABLE second (id TEXT)`
+	applied, err = migrateUp(db, migrations)
+	if err != nil {
+		t.Fatalf("直した後の適用に失敗しました: %v", err)
+	}
+	if len(applied) != 2 || applied[0].Version != 2 || applied[1].Version != 3 {
+		t.Errorf("直した後に適用したマイグレーションが %v です（2と3のはず）", applied)
+	}
+}
+
+// useLockSQL ロックの取得・解放のSQLを、migration_lock_calls に記録するものに置き換える（SQLiteにはアドバイザリーロックがない）
+func useLockSQL(t *testing.T, db *gorm.DB, lock, unlock string) {
+	t.Helper()
+	if err := db.Exec(`CREATE TABLE IF NOT EXISTS migration_lock_calls (seq INTEGER PRIMARY KEY AUTOINCREMENT, action TEXT, lock_key INTEGER)`).Error; err != nil {
+		t.Fatal(err)
+	}
+	savedLock, savedUnlock := migrationLockSQL, migrationUnlockSQL
+	migrationLockSQL, migrationUnlockSQL = lock, unlock
+	t.Cleanup(func() { migrationLockSQL, migrationUnlockSQL = savedLock, savedUnlock })
+}
+
+func lockCalls(t *testing.T, db *gorm.DB) string {
+	t.Helper()
+	var calls []string
+	if err := db.Table("migration_lock_calls").Order("seq").Pluck("action", &calls).Error; err != nil {
+		t.Fatal(err)
+	}
+	return strings.Join(calls, ",")
+}
+
+const (
+	recordLockSQL   = `INSERT INTO migration_lock_calls (action, lock_key) VALUES ('lock', ?)`
+	recordUnlockSQL = `INSERT INTO migration_lock_calls (action, lock_key) VALUES ('unlock', ?)`
+)
+
+func TestLockedMigration(t *testing.T) {
+	errMigration := errors.New("マイグレーションの失敗")
+	tests := []struct {
+		name      string
+		lock      string
+		unlock    string
+		fnErr     error
+		wantCalls string
+		wantRun   bool
+		wantErr   bool
+	}{
+		{name: "成功", lock: recordLockSQL, unlock: recordUnlockSQL, wantCalls: "lock,unlock", wantRun: true},
+		{name: "マイグレーションが失敗してもロックを解放する", lock: recordLockSQL, unlock: recordUnlockSQL, fnErr: errMigration,
+			wantCalls: "lock,unlock", wantRun: true, wantErr: true},
+		{name: "ロックを取得できない場合は実行しない", lock: `SELECT missing_lock_function(?)`, unlock: recordUnlockSQL,
+			wantCalls: "", wantErr: true},
+		{name: "ロックを解放できない場合はエラー", lock: recordLockSQL, unlock: `SELECT missing_unlock_function(?)`,
+			wantCalls: "lock", wantRun: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+			useLockSQL(t, db, tt.lock, tt.unlock)
+
+			ran := false
+			err := lockedMigration(db, func(conn *gorm.DB) error {
+				ran = true
+				if !conn.Migrator().HasTable(&schemaMigration{}) {
+					t.Error("ロックを取った後に schema_migrations が作成されていません")
+				}
+				return tt.fnErr
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("エラーが %v です", err)
+			}
+			if tt.fnErr != nil && !errors.Is(err, tt.fnErr) {
+				t.Errorf("マイグレーションのエラーが返されませんでした: %v", err)
+			}
+			if ran != tt.wantRun {
+				t.Errorf("マイグレーションを実行したかが %t です（%t のはず）", ran, tt.wantRun)
+			}
+			if got := lockCalls(t, db); got != tt.wantCalls {
+				t.Errorf("ロックの操作が %q です（%q のはず）", got, tt.wantCalls)
+			}
+		})
+	}
+}
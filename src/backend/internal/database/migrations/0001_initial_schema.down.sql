@@ -0,0 +1,59 @@
+-- 初期のスキーマをすべて削除する
+DROP TABLE IF EXISTS "sync_tombstones" CASCADE;
+DROP TABLE IF EXISTS "api_usages" CASCADE;
+DROP TABLE IF EXISTS "automation_overdue_runs" CASCADE;
+DROP TABLE IF EXISTS "automation_rules" CASCADE;
+DROP TABLE IF EXISTS "jobs" CASCADE;
+DROP TABLE IF EXISTS "change_watermarks" CASCADE;
+DROP TABLE IF EXISTS "idempotency_keys" CASCADE;
+DROP TABLE IF EXISTS "device_tokens" CASCADE;
+DROP TABLE IF EXISTS "webhook_delivery_attempts" CASCADE;
+DROP TABLE IF EXISTS "webhook_deliveries" CASCADE;
+DROP TABLE IF EXISTS "webhook_endpoints" CASCADE;
+DROP TABLE IF EXISTS "team_notification_preferences" CASCADE;
+DROP TABLE IF EXISTS "notification_preferences" CASCADE;
+DROP TABLE IF EXISTS "weekly_digest_settings" CASCADE;
+DROP TABLE IF EXISTS "chat_task_posts" CASCADE;
+DROP TABLE IF EXISTS "chat_event_posts" CASCADE;
+DROP TABLE IF EXISTS "team_chat_channels" CASCADE;
+DROP TABLE IF EXISTS "password_reset_tokens" CASCADE;
+DROP TABLE IF EXISTS "notifications" CASCADE;
+DROP TABLE IF EXISTS "materialized_occurrences" CASCADE;
+DROP TABLE IF EXISTS "event_poll_votes" CASCADE;
+DROP TABLE IF EXISTS "event_poll_invitees" CASCADE;
+DROP TABLE IF EXISTS "event_poll_options" CASCADE;
+DROP TABLE IF EXISTS "event_polls" CASCADE;
+DROP TABLE IF EXISTS "shared_calendar_links" CASCADE;
+DROP TABLE IF EXISTS "daily_agenda_settings" CASCADE;
+DROP TABLE IF EXISTS "event_check_ins" CASCADE;
+DROP TABLE IF EXISTS "working_hours" CASCADE;
+DROP TABLE IF EXISTS "reminder_deliveries" CASCADE;
+DROP TABLE IF EXISTS "event_reminders" CASCADE;
+DROP TABLE IF EXISTS "event_attendees" CASCADE;
+DROP TABLE IF EXISTS "cal_dav_resources" CASCADE;
+DROP TABLE IF EXISTS "event_exceptions" CASCADE;
+DROP TABLE IF EXISTS "event_reply_tokens" CASCADE;
+DROP TABLE IF EXISTS "email_reply_tokens" CASCADE;
+DROP TABLE IF EXISTS "user_warnings" CASCADE;
+DROP TABLE IF EXISTS "comment_reports" CASCADE;
+DROP TABLE IF EXISTS "task_watchers" CASCADE;
+DROP TABLE IF EXISTS "comment_mentions" CASCADE;
+DROP TABLE IF EXISTS "attachments" CASCADE;
+DROP TABLE IF EXISTS "comment_reactions" CASCADE;
+DROP TABLE IF EXISTS "comment_revisions" CASCADE;
+DROP TABLE IF EXISTS "task_activities" CASCADE;
+DROP TABLE IF EXISTS "task_occurrences" CASCADE;
+DROP TABLE IF EXISTS "checklist_items" CASCADE;
+DROP TABLE IF EXISTS "comments" CASCADE;
+DROP TABLE IF EXISTS "event_labels" CASCADE;
+DROP TABLE IF EXISTS "events" CASCADE;
+DROP TABLE IF EXISTS "calendar_connections" CASCADE;
+DROP TABLE IF EXISTS "calendar_subscriptions" CASCADE;
+DROP TABLE IF EXISTS "event_categories" CASCADE;
+DROP TABLE IF EXISTS "rooms" CASCADE;
+DROP TABLE IF EXISTS "task_labels" CASCADE;
+DROP TABLE IF EXISTS "labels" CASCADE;
+DROP TABLE IF EXISTS "tasks" CASCADE;
+DROP TABLE IF EXISTS "team_members" CASCADE;
+DROP TABLE IF EXISTS "teams" CASCADE;
+DROP TABLE IF EXISTS "users" CASCADE;
@@ -0,0 +1,59 @@
+-- 初期のスキーマをすべて削除する（SQLiteは DROP TABLE の CASCADE がないため、参照する側から順に削除する）
+DROP TABLE IF EXISTS "sync_tombstones";
+DROP TABLE IF EXISTS "api_usages";
+DROP TABLE IF EXISTS "automation_overdue_runs";
+DROP TABLE IF EXISTS "automation_rules";
+DROP TABLE IF EXISTS "jobs";
+DROP TABLE IF EXISTS "change_watermarks";
+DROP TABLE IF EXISTS "idempotency_keys";
+DROP TABLE IF EXISTS "device_tokens";
+DROP TABLE IF EXISTS "webhook_delivery_attempts";
+DROP TABLE IF EXISTS "webhook_deliveries";
+DROP TABLE IF EXISTS "webhook_endpoints";
+DROP TABLE IF EXISTS "team_notification_preferences";
+DROP TABLE IF EXISTS "notification_preferences";
+DROP TABLE IF EXISTS "weekly_digest_settings";
+DROP TABLE IF EXISTS "chat_task_posts";
+DROP TABLE IF EXISTS "chat_event_posts";
+DROP TABLE IF EXISTS "team_chat_channels";
+DROP TABLE IF EXISTS "password_reset_tokens";
+DROP TABLE IF EXISTS "notifications";
+DROP TABLE IF EXISTS "materialized_occurrences";
+DROP TABLE IF EXISTS "event_poll_votes";
+DROP TABLE IF EXISTS "event_poll_invitees";
+DROP TABLE IF EXISTS "event_poll_options";
+DROP TABLE IF EXISTS "event_polls";
+DROP TABLE IF EXISTS "shared_calendar_links";
+DROP TABLE IF EXISTS "daily_agenda_settings";
+DROP TABLE IF EXISTS "event_check_ins";
+DROP TABLE IF EXISTS "working_hours";
+DROP TABLE IF EXISTS "reminder_deliveries";
+DROP TABLE IF EXISTS "event_reminders";
+DROP TABLE IF EXISTS "event_attendees";
+DROP TABLE IF EXISTS "cal_dav_resources";
+DROP TABLE IF EXISTS "event_exceptions";
+DROP TABLE IF EXISTS "event_reply_tokens";
+DROP TABLE IF EXISTS "email_reply_tokens";
+DROP TABLE IF EXISTS "user_warnings";
+DROP TABLE IF EXISTS "comment_reports";
+DROP TABLE IF EXISTS "task_watchers";
+DROP TABLE IF EXISTS "comment_mentions";
+DROP TABLE IF EXISTS "attachments";
+DROP TABLE IF EXISTS "comment_reactions";
+DROP TABLE IF EXISTS "comment_revisions";
+DROP TABLE IF EXISTS "task_activities";
+DROP TABLE IF EXISTS "task_occurrences";
+DROP TABLE IF EXISTS "checklist_items";
+DROP TABLE IF EXISTS "comments";
+DROP TABLE IF EXISTS "event_labels";
+DROP TABLE IF EXISTS "events";
+DROP TABLE IF EXISTS "calendar_connections";
+DROP TABLE IF EXISTS "calendar_subscriptions";
+DROP TABLE IF EXISTS "event_categories";
+DROP TABLE IF EXISTS "rooms";
+DROP TABLE IF EXISTS "task_labels";
+DROP TABLE IF EXISTS "labels";
+DROP TABLE IF EXISTS "tasks";
+DROP TABLE IF EXISTS "team_members";
+DROP TABLE IF EXISTS "teams";
+DROP TABLE IF EXISTS "users";
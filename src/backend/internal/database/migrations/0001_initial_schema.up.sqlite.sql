@@ -0,0 +1,829 @@
+-- 初期のスキーマ（SQLite用。PostgreSQL用の 0001_initial_schema.up.sql と同じテーブルを、SQLiteの型で作成する）
+
+CREATE TABLE "users" (
+    "id" varchar(25),
+    "email" text NOT NULL UNIQUE,
+    "username" text NOT NULL UNIQUE,
+    "password" text NOT NULL,
+    "first_name" text NOT NULL,
+    "last_name" text NOT NULL,
+    "avatar" text,
+    "avatar_key" varchar(255),
+    "role" text DEFAULT 'MEMBER',
+    "time_zone" varchar(64),
+    "holiday_country" varchar(2),
+    "created_at" datetime,
+    "updated_at" datetime,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE "teams" (
+    "id" varchar(25),
+    "name" text NOT NULL,
+    "description" text,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "creator_id" varchar(25) NOT NULL,
+    "block_event_conflicts" boolean DEFAULT false,
+    "conference_provider" text DEFAULT '',
+    "holiday_country" varchar(2),
+    "mention_broadcast_policy" varchar(16) DEFAULT 'ADMINS',
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_created_teams" FOREIGN KEY ("creator_id") REFERENCES "users"("id")
+);
+
+CREATE TABLE "team_members" (
+    "id" varchar(25),
+    "user_id" varchar(25) NOT NULL,
+    "team_id" varchar(25) NOT NULL,
+    "role" text DEFAULT 'MEMBER',
+    "status" text DEFAULT 'ACTIVE',
+    "joined_at" datetime,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_teams_members" FOREIGN KEY ("team_id") REFERENCES "teams"("id"),
+    CONSTRAINT "fk_users_team_memberships" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+
+CREATE TABLE "tasks" (
+    "id" varchar(25),
+    "title" text NOT NULL,
+    "description" text,
+    "status" text DEFAULT 'TODO',
+    "priority" text DEFAULT 'MEDIUM',
+    "due_date" datetime,
+    "is_recurring" boolean DEFAULT false,
+    "recurrence" text,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "version" bigint NOT NULL DEFAULT 1,
+    "team_id" varchar(25) NOT NULL,
+    "creator_id" varchar(25) NOT NULL,
+    "assignee_id" varchar(25),
+    "duplicate_of_id" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_teams_tasks" FOREIGN KEY ("team_id") REFERENCES "teams"("id"),
+    CONSTRAINT "fk_users_assigned_tasks" FOREIGN KEY ("assignee_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_users_created_tasks" FOREIGN KEY ("creator_id") REFERENCES "users"("id")
+);
+
+CREATE TABLE "labels" (
+    "id" varchar(25),
+    "name" text NOT NULL,
+    "color" varchar(7),
+    "created_at" datetime,
+    "updated_at" datetime,
+    "team_id" text NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_labels_team_name" ON "labels" ("name","team_id");
+
+CREATE TABLE "task_labels" (
+    "task_id" varchar(25),
+    "label_id" varchar(25),
+    PRIMARY KEY ("task_id","label_id"),
+    CONSTRAINT "fk_task_labels_task" FOREIGN KEY ("task_id") REFERENCES "tasks"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_task_labels_label" FOREIGN KEY ("label_id") REFERENCES "labels"("id") ON DELETE CASCADE
+);
+
+CREATE TABLE "rooms" (
+    "id" varchar(25),
+    "name" text NOT NULL,
+    "description" text,
+    "location" text,
+    "capacity" bigint DEFAULT 0,
+    "time_zone" varchar(64),
+    "created_at" datetime,
+    "updated_at" datetime,
+    "team_id" text NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_rooms_team_id" ON "rooms" ("team_id");
+
+CREATE TABLE "event_categories" (
+    "id" varchar(25),
+    "name" text NOT NULL,
+    "color" varchar(7) NOT NULL,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "team_id" text NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_event_categories_team_name" ON "event_categories" ("name","team_id");
+
+CREATE TABLE "calendar_subscriptions" (
+    "id" varchar(25),
+    "name" text NOT NULL,
+    "url" text NOT NULL,
+    "e_tag" text,
+    "last_modified" text,
+    "last_fetched_at" datetime,
+    "last_error" text,
+    "event_count" bigint DEFAULT 0,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "user_id" text NOT NULL,
+    "team_id" text,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE "calendar_connections" (
+    "id" varchar(25),
+    "provider" text NOT NULL,
+    "account_id" text NOT NULL,
+    "account_email" text,
+    "access_token" text,
+    "refresh_token" text,
+    "token_expires_at" datetime,
+    "sync_token" text,
+    "last_full_sync_at" datetime,
+    "last_synced_at" datetime,
+    "last_error" text,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "user_id" text NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_calendar_connection" ON "calendar_connections" ("provider","account_id","user_id");
+
+CREATE TABLE "events" (
+    "id" varchar(25),
+    "title" text NOT NULL,
+    "description" text,
+    "start_date" datetime NOT NULL,
+    "end_date" datetime NOT NULL,
+    "all_day" boolean DEFAULT false,
+    "time_zone" varchar(64),
+    "is_recurring" boolean DEFAULT false,
+    "recurrence" text,
+    "type" text DEFAULT 'MEETING',
+    "location" text,
+    "room_id" varchar(25),
+    "buffer_before_minutes" bigint DEFAULT 0,
+    "buffer_after_minutes" bigint DEFAULT 0,
+    "capacity" bigint,
+    "conference_provider" text DEFAULT '',
+    "conference_url" text,
+    "conference_id" text,
+    "conference_start" datetime,
+    "conference_end" datetime,
+    "color" varchar(7),
+    "category_id" varchar(25),
+    "visibility" varchar(16) DEFAULT 'TEAM',
+    "status" varchar(16) DEFAULT 'CONFIRMED',
+    "cancelled_at" datetime,
+    "cancellation_reason" text,
+    "sequence" bigint DEFAULT 0,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "version" bigint NOT NULL DEFAULT 1,
+    "team_id" varchar(25),
+    "creator_id" varchar(25) NOT NULL,
+    "ical_uid" text,
+    "subscription_id" varchar(25),
+    "connection_id" varchar(25),
+    "external_id" text,
+    "occurrences_from" datetime,
+    "occurrences_until" datetime,
+    "occurrences_version" varchar(64),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_events" FOREIGN KEY ("creator_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_calendar_subscriptions_events" FOREIGN KEY ("subscription_id") REFERENCES "calendar_subscriptions"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_calendar_connections_events" FOREIGN KEY ("connection_id") REFERENCES "calendar_connections"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_teams_events" FOREIGN KEY ("team_id") REFERENCES "teams"("id"),
+    CONSTRAINT "fk_events_room" FOREIGN KEY ("room_id") REFERENCES "rooms"("id") ON DELETE SET NULL,
+    CONSTRAINT "fk_events_category" FOREIGN KEY ("category_id") REFERENCES "event_categories"("id") ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS "idx_events_subscription_id" ON "events" ("subscription_id");
+CREATE INDEX IF NOT EXISTS "idx_events_i_cal_uid" ON "events" ("ical_uid");
+CREATE INDEX IF NOT EXISTS "idx_events_status" ON "events" ("status");
+CREATE INDEX IF NOT EXISTS "idx_events_category_id" ON "events" ("category_id");
+CREATE INDEX IF NOT EXISTS "idx_events_room_id" ON "events" ("room_id");
+CREATE INDEX IF NOT EXISTS "idx_events_external_id" ON "events" ("external_id");
+CREATE INDEX IF NOT EXISTS "idx_events_connection_id" ON "events" ("connection_id");
+
+CREATE TABLE "event_labels" (
+    "event_id" varchar(25),
+    "label_id" varchar(25),
+    PRIMARY KEY ("event_id","label_id"),
+    CONSTRAINT "fk_event_labels_event" FOREIGN KEY ("event_id") REFERENCES "events"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_event_labels_label" FOREIGN KEY ("label_id") REFERENCES "labels"("id") ON DELETE CASCADE
+);
+
+CREATE TABLE "comments" (
+    "id" varchar(25),
+    "content" text NOT NULL,
+    "content_html" text,
+    "is_edited" boolean DEFAULT false,
+    "edited_at" datetime,
+    "is_pinned" boolean DEFAULT false,
+    "pinned_at" datetime,
+    "pinned_by_id" text,
+    "is_hidden" boolean DEFAULT false,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "task_id" varchar(25) NOT NULL,
+    "author_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_tasks_comments" FOREIGN KEY ("task_id") REFERENCES "tasks"("id"),
+    CONSTRAINT "fk_tasks_pinned_comments" FOREIGN KEY ("task_id") REFERENCES "tasks"("id"),
+    CONSTRAINT "fk_users_comments" FOREIGN KEY ("author_id") REFERENCES "users"("id")
+);
+
+CREATE TABLE "checklist_items" (
+    "id" varchar(25),
+    "content" text NOT NULL,
+    "position" bigint DEFAULT 0,
+    "is_completed" boolean DEFAULT false,
+    "completed_at" datetime,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "task_id" varchar(25) NOT NULL,
+    "completed_by_id" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_tasks_checklist_items" FOREIGN KEY ("task_id") REFERENCES "tasks"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_checklist_items_task_id" ON "checklist_items" ("task_id");
+
+CREATE TABLE "task_occurrences" (
+    "id" varchar(25),
+    "due_date" datetime NOT NULL,
+    "status" text,
+    "total_items" bigint,
+    "completed_items" bigint,
+    "reset_at" datetime,
+    "task_id" text NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_task_occurrences_task_id" ON "task_occurrences" ("task_id");
+
+CREATE TABLE "task_activities" (
+    "id" varchar(25),
+    "action" text NOT NULL,
+    "details" text,
+    "created_at" datetime,
+    "task_id" text NOT NULL,
+    "actor_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_task_activities_actor" FOREIGN KEY ("actor_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_task_activities_task_id" ON "task_activities" ("task_id");
+
+CREATE TABLE "comment_revisions" (
+    "id" varchar(25),
+    "content" text NOT NULL,
+    "created_at" datetime,
+    "comment_id" varchar(25) NOT NULL,
+    "edited_by_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_comment_revisions_edited_by" FOREIGN KEY ("edited_by_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_comments_revisions" FOREIGN KEY ("comment_id") REFERENCES "comments"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_comment_revisions_comment_id" ON "comment_revisions" ("comment_id");
+
+CREATE TABLE "comment_reactions" (
+    "id" varchar(25),
+    "emoji" text NOT NULL,
+    "created_at" datetime,
+    "comment_id" text NOT NULL,
+    "user_id" text NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_comment_reaction" ON "comment_reactions" ("emoji","comment_id","user_id");
+
+CREATE TABLE "attachments" (
+    "id" varchar(25),
+    "file_name" text NOT NULL,
+    "content_type" text,
+    "size" bigint,
+    "storage_key" text NOT NULL,
+    "created_at" datetime,
+    "task_id" text NOT NULL,
+    "comment_id" varchar(25),
+    "uploader_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_attachments_uploader" FOREIGN KEY ("uploader_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_comments_attachments" FOREIGN KEY ("comment_id") REFERENCES "comments"("id") ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS "idx_attachments_comment_id" ON "attachments" ("comment_id");
+CREATE INDEX IF NOT EXISTS "idx_attachments_task_id" ON "attachments" ("task_id");
+
+CREATE TABLE "comment_mentions" (
+    "id" varchar(25),
+    "created_at" datetime,
+    "comment_id" varchar(25) NOT NULL,
+    "user_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_comment_mentions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_comments_mentions" FOREIGN KEY ("comment_id") REFERENCES "comments"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_comment_mention" ON "comment_mentions" ("comment_id","user_id");
+
+CREATE TABLE "task_watchers" (
+    "id" varchar(25),
+    "created_at" datetime,
+    "task_id" varchar(25) NOT NULL,
+    "user_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_task_watchers_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_tasks_watchers" FOREIGN KEY ("task_id") REFERENCES "tasks"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_task_watcher" ON "task_watchers" ("task_id","user_id");
+
+CREATE TABLE "comment_reports" (
+    "id" varchar(25),
+    "reason" text NOT NULL,
+    "content" text,
+    "status" text DEFAULT 'OPEN',
+    "action" text,
+    "note" text,
+    "created_at" datetime,
+    "resolved_at" datetime,
+    "task_id" text NOT NULL,
+    "comment_id" text NOT NULL,
+    "author_id" varchar(25) NOT NULL,
+    "reporter_id" varchar(25) NOT NULL,
+    "resolved_by_id" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_comment_reports_reporter" FOREIGN KEY ("reporter_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_comment_reports_author" FOREIGN KEY ("author_id") REFERENCES "users"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_comment_reporter" ON "comment_reports" ("comment_id","reporter_id");
+CREATE INDEX IF NOT EXISTS "idx_comment_reports_status" ON "comment_reports" ("status");
+
+CREATE TABLE "user_warnings" (
+    "id" varchar(25),
+    "reason" text,
+    "created_at" datetime,
+    "user_id" text NOT NULL,
+    "issued_by_id" text NOT NULL,
+    "report_id" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_user_warnings_user_id" ON "user_warnings" ("user_id");
+
+CREATE TABLE "email_reply_tokens" (
+    "token" varchar(64),
+    "created_at" datetime,
+    "expires_at" datetime NOT NULL,
+    "user_id" text NOT NULL,
+    "task_id" text NOT NULL,
+    PRIMARY KEY ("token")
+);
+CREATE INDEX IF NOT EXISTS "idx_email_reply_tokens_expires_at" ON "email_reply_tokens" ("expires_at");
+
+CREATE TABLE "event_reply_tokens" (
+    "token" varchar(64),
+    "created_at" datetime,
+    "expires_at" datetime NOT NULL,
+    "user_id" text NOT NULL,
+    "event_id" text NOT NULL,
+    PRIMARY KEY ("token")
+);
+CREATE INDEX IF NOT EXISTS "idx_event_reply_tokens_event_id" ON "event_reply_tokens" ("event_id");
+CREATE INDEX IF NOT EXISTS "idx_event_reply_tokens_expires_at" ON "event_reply_tokens" ("expires_at");
+
+CREATE TABLE "event_exceptions" (
+    "id" varchar(25),
+    "recurrence_id" datetime NOT NULL,
+    "is_cancelled" boolean DEFAULT false,
+    "title" text,
+    "description" text,
+    "start_date" datetime,
+    "end_date" datetime,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "event_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_events_exceptions" FOREIGN KEY ("event_id") REFERENCES "events"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_event_exception" ON "event_exceptions" ("recurrence_id","event_id");
+
+CREATE TABLE "cal_dav_resources" (
+    "id" varchar(25),
+    "calendar" text NOT NULL,
+    "name" text NOT NULL,
+    "created_at" datetime,
+    "event_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_cal_dav_resources_event" FOREIGN KEY ("event_id") REFERENCES "events"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_cal_dav_resources_event_id" ON "cal_dav_resources" ("event_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_caldav_resource" ON "cal_dav_resources" ("calendar","name");
+
+CREATE TABLE "event_attendees" (
+    "id" varchar(25),
+    "status" text DEFAULT 'PENDING',
+    "comment" text,
+    "responded_at" datetime,
+    "waitlisted_at" datetime,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "event_id" varchar(25) NOT NULL,
+    "user_id" varchar(25) NOT NULL,
+    "invited_by_id" text NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_event_attendees_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_events_attendees" FOREIGN KEY ("event_id") REFERENCES "events"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_event_attendee" ON "event_attendees" ("event_id","user_id");
+
+CREATE TABLE "event_reminders" (
+    "id" varchar(25),
+    "minutes_before" bigint NOT NULL,
+    "created_at" datetime,
+    "event_id" varchar(25) NOT NULL,
+    "user_id" text NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_events_reminders" FOREIGN KEY ("event_id") REFERENCES "events"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_event_reminder" ON "event_reminders" ("minutes_before","event_id","user_id");
+
+CREATE TABLE "reminder_deliveries" (
+    "id" varchar(25),
+    "recurrence_id" datetime NOT NULL,
+    "fire_at" datetime NOT NULL,
+    "sent_at" datetime,
+    "reminder_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_event_reminders_deliveries" FOREIGN KEY ("reminder_id") REFERENCES "event_reminders"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_reminder_delivery" ON "reminder_deliveries" ("recurrence_id","reminder_id");
+
+CREATE TABLE "working_hours" (
+    "id" varchar(25),
+    "weekday" bigint NOT NULL,
+    "start_minute" bigint NOT NULL,
+    "end_minute" bigint NOT NULL,
+    "user_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_working_hours" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_working_hours_user_id" ON "working_hours" ("user_id");
+
+CREATE TABLE "event_check_ins" (
+    "id" varchar(25),
+    "recurrence_id" datetime NOT NULL,
+    "checked_in_at" datetime NOT NULL,
+    "event_id" varchar(25) NOT NULL,
+    "user_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_event_check_ins_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_events_check_ins" FOREIGN KEY ("event_id") REFERENCES "events"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_event_check_in" ON "event_check_ins" ("recurrence_id","event_id","user_id");
+
+CREATE TABLE "daily_agenda_settings" (
+    "user_id" varchar(25),
+    "enabled" boolean NOT NULL,
+    "send_minute" bigint NOT NULL,
+    "last_sent_on" varchar(10),
+    "updated_at" datetime,
+    PRIMARY KEY ("user_id"),
+    CONSTRAINT "fk_daily_agenda_settings_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+
+CREATE TABLE "shared_calendar_links" (
+    "id" varchar(25),
+    "token" varchar(64) NOT NULL,
+    "name" text,
+    "expires_at" datetime,
+    "last_accessed_at" datetime,
+    "created_at" datetime,
+    "creator_id" text NOT NULL,
+    "team_id" varchar(25),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_shared_calendar_links_team" FOREIGN KEY ("team_id") REFERENCES "teams"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_shared_calendar_links_token" ON "shared_calendar_links" ("token");
+CREATE INDEX IF NOT EXISTS "idx_shared_calendar_links_team_id" ON "shared_calendar_links" ("team_id");
+CREATE INDEX IF NOT EXISTS "idx_shared_calendar_links_creator_id" ON "shared_calendar_links" ("creator_id");
+
+CREATE TABLE "event_polls" (
+    "id" varchar(25),
+    "title" text NOT NULL,
+    "description" text,
+    "location" text,
+    "time_zone" varchar(64),
+    "status" varchar(16) DEFAULT 'OPEN',
+    "created_at" datetime,
+    "updated_at" datetime,
+    "creator_id" varchar(25) NOT NULL,
+    "team_id" text,
+    "option_id" text,
+    "event_id" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_event_polls_creator" FOREIGN KEY ("creator_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_event_polls_creator_id" ON "event_polls" ("creator_id");
+CREATE INDEX IF NOT EXISTS "idx_event_polls_status" ON "event_polls" ("status");
+
+CREATE TABLE "event_poll_options" (
+    "id" varchar(25),
+    "start_date" datetime NOT NULL,
+    "end_date" datetime NOT NULL,
+    "poll_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_event_polls_options" FOREIGN KEY ("poll_id") REFERENCES "event_polls"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_event_poll_options_poll_id" ON "event_poll_options" ("poll_id");
+
+CREATE TABLE "event_poll_invitees" (
+    "id" varchar(25),
+    "created_at" datetime,
+    "poll_id" varchar(25) NOT NULL,
+    "user_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_event_poll_invitees_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_event_polls_invitees" FOREIGN KEY ("poll_id") REFERENCES "event_polls"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_event_poll_invitee" ON "event_poll_invitees" ("poll_id","user_id");
+
+CREATE TABLE "event_poll_votes" (
+    "id" varchar(25),
+    "response" varchar(16) NOT NULL,
+    "updated_at" datetime,
+    "poll_id" text NOT NULL,
+    "option_id" varchar(25) NOT NULL,
+    "user_id" text NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_event_poll_options_votes" FOREIGN KEY ("option_id") REFERENCES "event_poll_options"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_event_poll_vote" ON "event_poll_votes" ("option_id","user_id");
+CREATE INDEX IF NOT EXISTS "idx_event_poll_votes_poll_id" ON "event_poll_votes" ("poll_id");
+
+CREATE TABLE "materialized_occurrences" (
+    "event_id" varchar(25),
+    "recurrence_id" datetime,
+    "start_date" datetime NOT NULL,
+    "end_date" datetime NOT NULL,
+    "is_override" boolean NOT NULL DEFAULT false,
+    "title" text,
+    "description" text,
+    PRIMARY KEY ("event_id","recurrence_id"),
+    CONSTRAINT "fk_events_occurrences" FOREIGN KEY ("event_id") REFERENCES "events"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_materialized_occurrence_range" ON "materialized_occurrences" ("event_id","start_date");
+
+CREATE TABLE "notifications" (
+    "id" varchar(25),
+    "type" varchar(64) NOT NULL,
+    "title" text NOT NULL,
+    "body" text,
+    "entity_type" varchar(32),
+    "entity_id" varchar(25),
+    "read_at" datetime,
+    "created_at" datetime,
+    "user_id" text NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_notifications_user_created" ON "notifications" ("user_id","created_at");
+
+CREATE TABLE "password_reset_tokens" (
+    "id" varchar(25),
+    "token_hash" varchar(64) NOT NULL,
+    "expires_at" datetime NOT NULL,
+    "used_at" datetime,
+    "created_at" datetime,
+    "user_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_password_reset_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_password_reset_tokens_user_id" ON "password_reset_tokens" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_password_reset_tokens_token_hash" ON "password_reset_tokens" ("token_hash");
+
+CREATE TABLE "team_chat_channels" (
+    "id" varchar(25),
+    "provider" varchar(16) NOT NULL,
+    "channel_id" text NOT NULL,
+    "channel_name" text,
+    "webhook_url" text,
+    "notify_task_created" boolean,
+    "notify_task_status" boolean,
+    "notify_task_due_soon" boolean NOT NULL DEFAULT false,
+    "notify_upcoming_events" boolean,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "team_id" varchar(25) NOT NULL,
+    "creator_id" text NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_team_chat_channels_team" FOREIGN KEY ("team_id") REFERENCES "teams"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_team_chat_channels_channel_id" ON "team_chat_channels" ("channel_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_team_chat_channel" ON "team_chat_channels" ("provider","team_id");
+
+CREATE TABLE "chat_event_posts" (
+    "id" varchar(25),
+    "recurrence_id" datetime NOT NULL,
+    "posted_at" datetime,
+    "channel_id" varchar(25) NOT NULL,
+    "event_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_chat_event_posts_channel" FOREIGN KEY ("channel_id") REFERENCES "team_chat_channels"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_chat_event_posts_event" FOREIGN KEY ("event_id") REFERENCES "events"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_chat_event_post" ON "chat_event_posts" ("recurrence_id","channel_id","event_id");
+
+CREATE TABLE "chat_task_posts" (
+    "id" varchar(25),
+    "due_date" datetime NOT NULL,
+    "posted_at" datetime,
+    "channel_id" varchar(25) NOT NULL,
+    "task_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_chat_task_posts_channel" FOREIGN KEY ("channel_id") REFERENCES "team_chat_channels"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_chat_task_posts_task" FOREIGN KEY ("task_id") REFERENCES "tasks"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_chat_task_post" ON "chat_task_posts" ("due_date","channel_id","task_id");
+
+CREATE TABLE "weekly_digest_settings" (
+    "user_id" varchar(25),
+    "opted_out" boolean NOT NULL DEFAULT false,
+    "last_sent_week" varchar(8),
+    "updated_at" datetime,
+    PRIMARY KEY ("user_id"),
+    CONSTRAINT "fk_weekly_digest_settings_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+
+CREATE TABLE "notification_preferences" (
+    "id" varchar(25),
+    "type" varchar(64) NOT NULL,
+    "channel" varchar(16) NOT NULL,
+    "enabled" boolean NOT NULL,
+    "updated_at" datetime,
+    "user_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_notification_preferences_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_notification_preference" ON "notification_preferences" ("type","channel","user_id");
+
+CREATE TABLE "team_notification_preferences" (
+    "id" varchar(25),
+    "type" varchar(64) NOT NULL,
+    "channel" varchar(16) NOT NULL,
+    "enabled" boolean NOT NULL,
+    "updated_at" datetime,
+    "team_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_team_notification_preferences_team" FOREIGN KEY ("team_id") REFERENCES "teams"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_team_notification_preference" ON "team_notification_preferences" ("type","channel","team_id");
+
+CREATE TABLE "webhook_endpoints" (
+    "id" varchar(25),
+    "url" text NOT NULL,
+    "secret" text NOT NULL,
+    "event_types" text,
+    "active" boolean NOT NULL DEFAULT true,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "team_id" varchar(25),
+    "creator_id" text NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_webhook_endpoints_team" FOREIGN KEY ("team_id") REFERENCES "teams"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_webhook_endpoints_creator_id" ON "webhook_endpoints" ("creator_id");
+CREATE INDEX IF NOT EXISTS "idx_webhook_endpoints_team_id" ON "webhook_endpoints" ("team_id");
+
+CREATE TABLE "webhook_deliveries" (
+    "id" varchar(25),
+    "event_type" varchar(64) NOT NULL,
+    "payload" text NOT NULL,
+    "status" varchar(16) NOT NULL,
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "next_attempt_at" datetime,
+    "last_status_code" bigint,
+    "last_error" text,
+    "delivered_at" datetime,
+    "created_at" datetime,
+    "endpoint_id" varchar(25) NOT NULL,
+    "replay_of_id" varchar(25),
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_webhook_deliveries_endpoint" FOREIGN KEY ("endpoint_id") REFERENCES "webhook_endpoints"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_webhook_delivery_endpoint" ON "webhook_deliveries" ("endpoint_id","created_at");
+CREATE INDEX IF NOT EXISTS "idx_webhook_delivery_due" ON "webhook_deliveries" ("status","next_attempt_at");
+
+CREATE TABLE "webhook_delivery_attempts" (
+    "id" varchar(25),
+    "status_code" bigint,
+    "latency_ms" bigint,
+    "error" text,
+    "response_body" text,
+    "created_at" datetime,
+    "delivery_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_webhook_deliveries_history" FOREIGN KEY ("delivery_id") REFERENCES "webhook_deliveries"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_webhook_delivery_attempts_delivery_id" ON "webhook_delivery_attempts" ("delivery_id");
+
+CREATE TABLE "device_tokens" (
+    "id" varchar(25),
+    "platform" varchar(16) NOT NULL,
+    "token" text NOT NULL,
+    "device_name" text,
+    "last_seen_at" datetime,
+    "created_at" datetime,
+    "user_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_device_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_device_tokens_user_id" ON "device_tokens" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_device_tokens_token" ON "device_tokens" ("token");
+
+CREATE TABLE "idempotency_keys" (
+    "id" varchar(25),
+    "user_id" text NOT NULL,
+    "key" varchar(255) NOT NULL,
+    "fingerprint" varchar(64) NOT NULL,
+    "status_code" bigint,
+    "content_type" text,
+    "response" blob,
+    "created_at" datetime,
+    "completed_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_idempotency_keys_created_at" ON "idempotency_keys" ("created_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_idempotency_user_key" ON "idempotency_keys" ("user_id","key");
+
+CREATE TABLE "change_watermarks" (
+    "scope" varchar(96),
+    "changed_at" datetime NOT NULL,
+    PRIMARY KEY ("scope")
+);
+
+CREATE TABLE "jobs" (
+    "id" varchar(25),
+    "type" varchar(32) NOT NULL,
+    "status" varchar(16) NOT NULL,
+    "progress" bigint NOT NULL DEFAULT 0,
+    "params" text NOT NULL,
+    "input_key" text,
+    "result" text,
+    "result_key" text,
+    "result_name" text,
+    "result_content_type" text,
+    "error" text,
+    "created_at" datetime,
+    "started_at" datetime,
+    "completed_at" datetime,
+    "user_id" text NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_jobs_user_id" ON "jobs" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_jobs_status" ON "jobs" ("status");
+
+CREATE TABLE "automation_rules" (
+    "id" varchar(25),
+    "name" text NOT NULL,
+    "enabled" boolean NOT NULL DEFAULT true,
+    "trigger" varchar(32) NOT NULL,
+    "conditions" text,
+    "actions" text,
+    "last_triggered_at" datetime,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "team_id" varchar(25) NOT NULL,
+    "creator_id" text NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_automation_rules_team" FOREIGN KEY ("team_id") REFERENCES "teams"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_automation_rules_team_trigger" ON "automation_rules" ("team_id","trigger");
+
+CREATE TABLE "automation_overdue_runs" (
+    "id" varchar(25),
+    "due_date" datetime NOT NULL,
+    "created_at" datetime,
+    "rule_id" varchar(25) NOT NULL,
+    "task_id" varchar(25) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_automation_overdue_runs_rule" FOREIGN KEY ("rule_id") REFERENCES "automation_rules"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_automation_overdue_runs_task" FOREIGN KEY ("task_id") REFERENCES "tasks"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_automation_overdue_run" ON "automation_overdue_runs" ("due_date","rule_id","task_id");
+
+CREATE TABLE "api_usages" (
+    "hour_start" datetime,
+    "client_key" varchar(80),
+    "route" varchar(255),
+    "user_id" text,
+    "requests" bigint NOT NULL DEFAULT 0,
+    "client_errors" bigint NOT NULL DEFAULT 0,
+    "server_errors" bigint NOT NULL DEFAULT 0,
+    "total_latency_ms" bigint NOT NULL DEFAULT 0,
+    "max_latency_ms" bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY ("hour_start","client_key","route")
+);
+CREATE INDEX IF NOT EXISTS "idx_api_usages_user_id" ON "api_usages" ("user_id");
+
+CREATE TABLE "sync_tombstones" (
+    "id" varchar(25),
+    "entity_type" varchar(16) NOT NULL,
+    "entity_id" varchar(25) NOT NULL,
+    "team_id" text,
+    "user_id" text,
+    "deleted_at" datetime NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_sync_tombstones_team_id" ON "sync_tombstones" ("team_id");
+CREATE INDEX IF NOT EXISTS "idx_sync_tombstones_deleted_at" ON "sync_tombstones" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_sync_tombstones_user_id" ON "sync_tombstones" ("user_id");
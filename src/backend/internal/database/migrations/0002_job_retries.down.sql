@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "idx_jobs_run_at";
+ALTER TABLE "jobs" DROP COLUMN "run_at";
+ALTER TABLE "jobs" DROP COLUMN "max_attempts";
+ALTER TABLE "jobs" DROP COLUMN "attempts";
@@ -0,0 +1,8 @@
+-- 非同期ジョブの再実行（実行した回数・最大の回数・次に実行する日時）
+ALTER TABLE "jobs" ADD COLUMN "attempts" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "jobs" ADD COLUMN "max_attempts" bigint NOT NULL DEFAULT 1;
+ALTER TABLE "jobs" ADD COLUMN "run_at" timestamptz;
+UPDATE "jobs" SET "run_at" = "created_at";
+UPDATE "jobs" SET "attempts" = 1 WHERE "status" <> 'PENDING';
+ALTER TABLE "jobs" ALTER COLUMN "run_at" SET NOT NULL;
+CREATE INDEX IF NOT EXISTS "idx_jobs_run_at" ON "jobs" ("run_at");
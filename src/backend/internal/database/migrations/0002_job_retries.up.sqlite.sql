@@ -0,0 +1,7 @@
+-- 非同期ジョブの再実行（SQLite用。ALTER COLUMN がないため、run_at は既定値をつけて追加してから作成日時で埋める）
+ALTER TABLE "jobs" ADD COLUMN "attempts" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "jobs" ADD COLUMN "max_attempts" bigint NOT NULL DEFAULT 1;
+ALTER TABLE "jobs" ADD COLUMN "run_at" datetime NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+UPDATE "jobs" SET "run_at" = "created_at";
+UPDATE "jobs" SET "attempts" = 1 WHERE "status" <> 'PENDING';
+CREATE INDEX IF NOT EXISTS "idx_jobs_run_at" ON "jobs" ("run_at");
@@ -0,0 +1,13 @@
+-- 定期実行のジョブごとの実行の記録
+CREATE TABLE "cron_job_states" (
+    "name" varchar(128),
+    "schedule" varchar(64) NOT NULL,
+    "last_started_at" timestamptz,
+    "last_finished_at" timestamptz,
+    "last_succeeded_at" timestamptz,
+    "last_error" text,
+    "last_duration_ms" bigint,
+    "next_run_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("name")
+);
@@ -0,0 +1,13 @@
+-- 定期実行のジョブごとの実行の記録（SQLite用）
+CREATE TABLE "cron_job_states" (
+    "name" varchar(128),
+    "schedule" varchar(64) NOT NULL,
+    "last_started_at" datetime,
+    "last_finished_at" datetime,
+    "last_succeeded_at" datetime,
+    "last_error" text,
+    "last_duration_ms" bigint,
+    "next_run_at" datetime,
+    "updated_at" datetime,
+    PRIMARY KEY ("name")
+);
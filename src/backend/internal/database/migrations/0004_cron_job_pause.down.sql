@@ -0,0 +1 @@
+ALTER TABLE "cron_job_states" DROP COLUMN "paused";
@@ -0,0 +1,2 @@
+-- 定期実行のジョブの一時停止（管理者が再デプロイせずに止める）
+ALTER TABLE "cron_job_states" ADD COLUMN "paused" boolean NOT NULL DEFAULT false;
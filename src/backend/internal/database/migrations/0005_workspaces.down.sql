@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS "idx_events_workspace_id";
+ALTER TABLE "events" DROP COLUMN "workspace_id";
+DROP INDEX IF EXISTS "idx_tasks_workspace_id";
+ALTER TABLE "tasks" DROP COLUMN "workspace_id";
+DROP INDEX IF EXISTS "idx_teams_workspace_id";
+ALTER TABLE "teams" DROP COLUMN "workspace_id";
+DROP INDEX IF EXISTS "idx_users_workspace_id";
+ALTER TABLE "users" DROP COLUMN "workspace_id";
+DROP TABLE IF EXISTS "workspaces";
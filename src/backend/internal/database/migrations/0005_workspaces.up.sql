@@ -0,0 +1,24 @@
+-- ワークスペース（テナント）。ユーザー・チーム・タスク・イベントが所属し、それ以外のデータはこれらを通じて所属する
+-- 既存のデータは既定のワークスペース（default）に入れる
+CREATE TABLE "workspaces" (
+    "id" varchar(25),
+    "name" text NOT NULL,
+    "slug" varchar(64) NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_workspaces_slug" ON "workspaces" ("slug");
+INSERT INTO "workspaces" ("id", "name", "slug", "created_at", "updated_at") VALUES ('default', 'Default', 'default', now(), now());
+ALTER TABLE "users" ADD COLUMN "workspace_id" varchar(25) NOT NULL DEFAULT 'default';
+ALTER TABLE "users" ADD CONSTRAINT "fk_workspaces_users" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id");
+CREATE INDEX IF NOT EXISTS "idx_users_workspace_id" ON "users" ("workspace_id");
+ALTER TABLE "teams" ADD COLUMN "workspace_id" varchar(25) NOT NULL DEFAULT 'default';
+ALTER TABLE "teams" ADD CONSTRAINT "fk_workspaces_teams" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id");
+CREATE INDEX IF NOT EXISTS "idx_teams_workspace_id" ON "teams" ("workspace_id");
+ALTER TABLE "tasks" ADD COLUMN "workspace_id" varchar(25) NOT NULL DEFAULT 'default';
+ALTER TABLE "tasks" ADD CONSTRAINT "fk_workspaces_tasks" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id");
+CREATE INDEX IF NOT EXISTS "idx_tasks_workspace_id" ON "tasks" ("workspace_id");
+ALTER TABLE "events" ADD COLUMN "workspace_id" varchar(25) NOT NULL DEFAULT 'default';
+ALTER TABLE "events" ADD CONSTRAINT "fk_workspaces_events" FOREIGN KEY ("workspace_id") REFERENCES "workspaces"("id");
+CREATE INDEX IF NOT EXISTS "idx_events_workspace_id" ON "events" ("workspace_id");
@@ -0,0 +1,19 @@
+-- ワークスペース（SQLite用。ADD COLUMN では外部キーを追加できないため、列と索引のみ追加する）
+CREATE TABLE "workspaces" (
+    "id" varchar(25),
+    "name" text NOT NULL,
+    "slug" varchar(64) NOT NULL,
+    "created_at" datetime,
+    "updated_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_workspaces_slug" ON "workspaces" ("slug");
+INSERT INTO "workspaces" ("id", "name", "slug", "created_at", "updated_at") VALUES ('default', 'Default', 'default', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+ALTER TABLE "users" ADD COLUMN "workspace_id" varchar(25) NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS "idx_users_workspace_id" ON "users" ("workspace_id");
+ALTER TABLE "teams" ADD COLUMN "workspace_id" varchar(25) NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS "idx_teams_workspace_id" ON "teams" ("workspace_id");
+ALTER TABLE "tasks" ADD COLUMN "workspace_id" varchar(25) NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS "idx_tasks_workspace_id" ON "tasks" ("workspace_id");
+ALTER TABLE "events" ADD COLUMN "workspace_id" varchar(25) NOT NULL DEFAULT 'default';
+CREATE INDEX IF NOT EXISTS "idx_events_workspace_id" ON "events" ("workspace_id");
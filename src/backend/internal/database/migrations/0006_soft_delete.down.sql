@@ -0,0 +1,7 @@
+-- 論理削除した行は削除されていないものとして残る（戻す前に完全な削除を実行しておく）
+DROP INDEX IF EXISTS "idx_comments_deleted_at";
+ALTER TABLE "comments" DROP COLUMN "deleted_at";
+DROP INDEX IF EXISTS "idx_events_deleted_at";
+ALTER TABLE "events" DROP COLUMN "deleted_at";
+DROP INDEX IF EXISTS "idx_tasks_deleted_at";
+ALTER TABLE "tasks" DROP COLUMN "deleted_at";
@@ -0,0 +1,7 @@
+-- 論理削除（削除したタスク・イベント・コメントを保存期間のあいだ残し、復元できるようにする）
+ALTER TABLE "tasks" ADD COLUMN "deleted_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_tasks_deleted_at" ON "tasks" ("deleted_at");
+ALTER TABLE "events" ADD COLUMN "deleted_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_events_deleted_at" ON "events" ("deleted_at");
+ALTER TABLE "comments" ADD COLUMN "deleted_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_comments_deleted_at" ON "comments" ("deleted_at");
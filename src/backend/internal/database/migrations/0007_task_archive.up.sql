@@ -0,0 +1,3 @@
+-- タスクのアーカイブ（完了から一定期間が過ぎたタスクを、保存期間のルールでアーカイブする）
+ALTER TABLE "tasks" ADD COLUMN "archived_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_tasks_archived_at" ON "tasks" ("archived_at");
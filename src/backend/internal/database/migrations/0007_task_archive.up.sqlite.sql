@@ -0,0 +1,3 @@
+-- タスクのアーカイブ（SQLite用）
+ALTER TABLE "tasks" ADD COLUMN "archived_at" datetime;
+CREATE INDEX IF NOT EXISTS "idx_tasks_archived_at" ON "tasks" ("archived_at");
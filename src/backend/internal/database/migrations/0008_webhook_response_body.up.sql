@@ -0,0 +1,2 @@
+-- Webhookの配信の試行に、宛先の応答の本文を記録しない（利用者に内部のサービスの応答を見せないため、記録済みの本文も削除する）
+ALTER TABLE "webhook_delivery_attempts" DROP COLUMN "response_body";
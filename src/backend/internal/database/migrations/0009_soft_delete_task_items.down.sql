@@ -0,0 +1,5 @@
+-- 論理削除した行は削除されていないものとして残る（戻す前に完全な削除を実行しておく）
+DROP INDEX IF EXISTS "idx_checklist_items_deleted_at";
+ALTER TABLE "checklist_items" DROP COLUMN "deleted_at";
+DROP INDEX IF EXISTS "idx_attachments_deleted_at";
+ALTER TABLE "attachments" DROP COLUMN "deleted_at";
@@ -0,0 +1,5 @@
+-- 論理削除（削除した添付ファイル・チェックリスト項目を保存期間のあいだ残し、復元できるようにする）
+ALTER TABLE "attachments" ADD COLUMN "deleted_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_attachments_deleted_at" ON "attachments" ("deleted_at");
+ALTER TABLE "checklist_items" ADD COLUMN "deleted_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_checklist_items_deleted_at" ON "checklist_items" ("deleted_at");
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// replicaSet 読み取り専用のクエリを振り分けるレプリカの接続（順に使う）
+type replicaSet struct {
+	pools []gorm.ConnPool
+	next  atomic.Uint64
+}
+
+// replicas プライマリの接続プール（*gorm.DB の ConnPool。Session・トランザクションで作った *gorm.DB も同じものを持つ）ごとのレプリカ
+var replicas sync.Map
+
+func registerReplicas(db *gorm.DB, pools []gorm.ConnPool) {
+	if len(pools) > 0 {
+		replicas.Store(db.ConnPool, &replicaSet{pools: pools})
+	}
+}
+
+// Read 読み取り専用のクエリ（一覧・集計）をレプリカで実行する *gorm.DB（レプリカがない場合は db をそのまま返す）
+//
+// レプリカは書き込みの反映が遅れることがあるため、書き込んだ直後に読み直す処理や、書き込みの前提の確認には使わない。
+// トランザクション中は書き込んだ内容を読めるよう、プライマリを使う。接続だけを差し替えるため、登録したコールバックはそのまま動く。
+func Read(db *gorm.DB) *gorm.DB {
+	value, ok := replicas.Load(db.ConnPool)
+	if !ok {
+		return db
+	}
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); inTx {
+		return db
+	}
+	set := value.(*replicaSet)
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	// Context を指定すると Statement が複製されるため、db の接続は変わらない
+	tx := db.Session(&gorm.Session{Context: ctx})
+	tx.Statement.ConnPool = set.pools[set.next.Add(1)%uint64(len(set.pools))]
+	return tx
+}
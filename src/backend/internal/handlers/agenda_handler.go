@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AgendaHandler struct {
+	agendaService *services.AgendaService
+	watermarks    *services.ChangeWatermarkService
+}
+
+func NewAgendaHandler(agendaService *services.AgendaService, watermarks *services.ChangeWatermarkService) *AgendaHandler {
+	return &AgendaHandler{agendaService: agendaService, watermarks: watermarks}
+}
+
+// GetAgenda 期間内のイベントとタスクの期限をまとめたアジェンダ（絞り込み条件は parseOccurrenceFilter）
+//
+// If-Modified-Since 以降に自分・所属するチームのイベントやタスクが変更されていなければ304を返す。
+func (h *AgendaHandler) GetAgenda(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	loc, err := parseLocation(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	from, to, err := parseTimeRange(c, loc)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	changedAt, err := h.watermarks.CalendarChangedAt(userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	if notModified(c, changedAt) {
+		return
+	}
+	items, err := h.agendaService.GetAgenda(userID, from, to, parseOccurrenceFilter(c))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, services.LocalizeAgenda(items, loc))
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/apidocs"
+
+	"github.com/gin-gonic/gin"
+)
+
+// swaggerUIPage Swagger UI（CDNのswagger-ui-dist）で /api/docs/openapi.json を表示するページ
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="ja">
+<head>
+  <meta charset="utf-8">
+  <title>TaskCalendar API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/api/docs/openapi.json", dom_id: "#swagger-ui", persistAuthorization: true });
+  </script>
+</body>
+</html>
+`
+
+// APIDocsHandler OpenAPIの仕様とSwagger UI
+type APIDocsHandler struct{}
+
+func NewAPIDocsHandler() *APIDocsHandler {
+	return &APIDocsHandler{}
+}
+
+// GetUI Swagger UI
+func (h *APIDocsHandler) GetUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
+
+// GetSpec OpenAPI 3 の仕様（JSON）
+func (h *APIDocsHandler) GetSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", apidocs.Spec())
+}
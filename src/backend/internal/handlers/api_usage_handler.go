@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIUsageHandler struct {
+	usageService *services.APIUsageService
+}
+
+func NewAPIUsageHandler(usageService *services.APIUsageService) *APIUsageHandler {
+	return &APIUsageHandler{usageService: usageService}
+}
+
+// GetUsage APIの利用状況（管理者。from・to は省略すると直近24時間、groupBy=client|route、userId で絞り込み、limit は既定50）
+//
+// 利用者・ルートごとのリクエスト数、4xx・5xxの件数とエラー率、平均・最大の応答時間をリクエスト数の多い順に返す。
+func (h *APIUsageHandler) GetUsage(c *gin.Context) {
+	to := time.Now()
+	from := to.Add(-24 * time.Hour)
+	if c.Query("from") != "" || c.Query("to") != "" {
+		var err error
+		if from, to, err = parseTimeRange(c, time.UTC); err != nil {
+			respondError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	query := services.APIUsageQuery{
+		From:    from,
+		To:      to,
+		GroupBy: services.APIUsageGroupBy(c.Query("groupBy")),
+		UserID:  c.Query("userId"),
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			respondError(c, http.StatusBadRequest, "limitは正の整数で指定してください")
+			return
+		}
+		query.Limit = limit
+	}
+
+	summaries, err := h.usageService.SummarizeUsage(query)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, summaries)
+}
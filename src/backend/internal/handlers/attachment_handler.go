@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+	"task-calendar-backend/internal/upload"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttachmentHandler struct {
+	attachmentService *services.AttachmentService
+}
+
+func NewAttachmentHandler(attachmentService *services.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{attachmentService: attachmentService}
+}
+
+// UploadCommentAttachment コメントへのファイル添付（multipart/form-data の file。ファイルはメモリにためずにストレージへ書き込む）
+func (h *AttachmentHandler) UploadCommentAttachment(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	file, err := upload.FromRequest(c.Writer, c.Request, "file", upload.Limits{MaxSize: h.attachmentService.MaxUploadSize()})
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	attachment, err := h.attachmentService.UploadCommentAttachment(c.Param("id"), c.Param("commentId"), userID, file)
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// DownloadAttachment 添付ファイルのダウンロード
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	attachment, rc, err := h.attachmentService.OpenAttachment(c.Param("id"), c.Param("attachmentId"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	defer rc.Close()
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", attachment.FileName))
+	c.Header("Content-Type", attachment.ContentType)
+	c.Header("Content-Length", fmt.Sprint(attachment.Size))
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, rc)
+}
+
+// DeleteAttachment 添付ファイル削除
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.attachmentService.DeleteAttachment(c.Param("id"), c.Param("attachmentId"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "添付ファイルを削除しました"})
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttendanceHandler struct {
+	attendanceService *services.AttendanceService
+}
+
+func NewAttendanceHandler(attendanceService *services.AttendanceService) *AttendanceHandler {
+	return &AttendanceHandler{attendanceService: attendanceService}
+}
+
+// CheckIn イベントへの出席チェックイン
+func (h *AttendanceHandler) CheckIn(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.CheckInInput
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+
+	checkIn, err := h.attendanceService.CheckIn(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, checkIn)
+}
+
+// GetAttendance イベント・シリーズの出席集計（from / to は任意）
+func (h *AttendanceHandler) GetAttendance(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	loc, err := parseLocation(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	from, err := parseOptionalTimeParam(c, "from", loc)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	to, err := parseOptionalTimeParam(c, "to", loc)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	summary, err := h.attendanceService.GetAttendance(c.Param("id"), userID, from, to)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
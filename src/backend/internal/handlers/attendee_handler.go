@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type inviteAttendeesRequest struct {
+	UserIDs []string `json:"userIds" binding:"required,min=1"`
+}
+
+type AttendeeHandler struct {
+	attendeeService *services.AttendeeService
+}
+
+func NewAttendeeHandler(attendeeService *services.AttendeeService) *AttendeeHandler {
+	return &AttendeeHandler{attendeeService: attendeeService}
+}
+
+// GetAttendees イベントの参加者一覧取得
+func (h *AttendeeHandler) GetAttendees(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	sort, err := parseSort(c, services.AttendeeSortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	include, err := parseInclude(c, services.AttendeeIncludes)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	attendees, err := h.attendeeService.ListAttendees(c.Param("id"), userID, sort, include)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, attendees)
+}
+
+// InviteAttendees イベントへの招待
+func (h *AttendeeHandler) InviteAttendees(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req inviteAttendeesRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.attendeeService.InviteAttendees(c.Param("id"), userID, req.UserIDs)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// RemoveAttendee 参加者の削除（本人の場合は辞退）
+func (h *AttendeeHandler) RemoveAttendee(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.attendeeService.RemoveAttendee(c.Param("id"), c.Param("userId"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "参加者を削除しました"})
+}
+
+// RespondRSVP 招待への出欠回答
+func (h *AttendeeHandler) RespondRSVP(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.RSVPInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	attendee, err := h.attendeeService.RespondToInvitation(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, attendee)
+}
+
+// SetCapacity イベントの定員の設定（超えた出席の回答はキャンセル待ちになる）
+func (h *AttendeeHandler) SetCapacity(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.SetCapacityInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	event, err := h.attendeeService.SetCapacity(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+// TransferOwnership イベントの作成者（主催者）の変更
+func (h *AttendeeHandler) TransferOwnership(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.TransferOwnershipInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	event, err := h.attendeeService.TransferOwnership(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+// CancelEvent イベントのキャンセル（削除せずにキャンセル済みとして残し、参加者に通知する）
+func (h *AttendeeHandler) CancelEvent(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.CancelEventInput
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+
+	event, err := h.attendeeService.CancelEvent(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
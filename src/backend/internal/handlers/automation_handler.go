@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AutomationHandler struct {
+	automationService *services.AutomationService
+}
+
+func NewAutomationHandler(automationService *services.AutomationService) *AutomationHandler {
+	return &AutomationHandler{automationService: automationService}
+}
+
+// GetRules チームの自動化のルール一覧取得
+func (h *AutomationHandler) GetRules(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	sort, err := parseSort(c, services.AutomationRuleSortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	rules, err := h.automationService.ListRules(c.Param("id"), userID, sort)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, rules)
+}
+
+// CreateRule 自動化のルールの登録（trigger のときに conditions をすべて満たせば actions を行う）
+func (h *AutomationHandler) CreateRule(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.AutomationRuleInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	rule, err := h.automationService.CreateRule(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateRule 自動化のルールの更新
+func (h *AutomationHandler) UpdateRule(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.AutomationRuleInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	rule, err := h.automationService.UpdateRule(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule 自動化のルールの削除
+func (h *AutomationHandler) DeleteRule(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.automationService.DeleteRule(c.Param("id"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "自動化のルールを削除しました"})
+}
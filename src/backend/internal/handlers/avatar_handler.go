@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+	"task-calendar-backend/internal/upload"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AvatarHandler struct {
+	avatarService *services.AvatarService
+}
+
+func NewAvatarHandler(avatarService *services.AvatarService) *AvatarHandler {
+	return &AvatarHandler{avatarService: avatarService}
+}
+
+// UploadAvatar 自分のアバター画像のアップロード（multipart/form-data の file。PNG・JPEG・GIF・WebPのみ。応答は更新後のユーザー）
+func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	file, err := upload.FromRequest(c.Writer, c.Request, "file", h.avatarService.Limits())
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+	user, err := h.avatarService.SetAvatar(userID, file)
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteAvatar 自分のアバターの削除（応答は更新後のユーザー）
+func (h *AvatarHandler) DeleteAvatar(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	user, err := h.avatarService.DeleteAvatar(userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// GetAvatar ユーザーのアバター画像（img 要素から読み込めるよう認証不要。URLは画像ごとに変わるため長くキャッシュさせる）
+func (h *AvatarHandler) GetAvatar(c *gin.Context) {
+	rc, contentType, err := h.avatarService.OpenAvatar(c.Param("id"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	defer rc.Close()
+
+	c.Header("Content-Type", contentType)
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, rc)
+}
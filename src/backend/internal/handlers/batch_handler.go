@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// batchForwardHeaders バッチのリクエストから個々のリクエストに引き継ぐヘッダー
+var batchForwardHeaders = []string{"Authorization", "Accept-Language", "User-Agent"}
+
+// batchItemHeaders 個々のリクエストで指定できるヘッダー
+var batchItemHeaders = map[string]bool{"If-Match": true, "Idempotency-Key": true}
+
+type batchRequest struct {
+	// Requests 実行するリクエスト（1回のバッチで20件まで）
+	Requests []batchItem `json:"requests" binding:"required,min=1,max=20,dive"`
+	// StopOnError 失敗したリクエストがあれば、以降のリクエストを実行しない（実行済みのリクエストは取り消さない）
+	StopOnError bool `json:"stopOnError"`
+}
+
+// batchItem バッチで実行するリクエスト（path はバージョンのパスからの相対パス。/tasks など）
+type batchItem struct {
+	Method  string            `json:"method" binding:"required"`
+	Path    string            `json:"path" binding:"required"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+// batchResult 個々のリクエストの結果（実行しなかったリクエストは status が 424）
+type batchResult struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body,omitempty"`
+}
+
+// BatchHandler 複数のリクエストを1回の呼び出しで実行する（モバイルアプリの同期向け）
+type BatchHandler struct {
+	engine *gin.Engine
+}
+
+func NewBatchHandler(engine *gin.Engine) *BatchHandler {
+	return &BatchHandler{engine: engine}
+}
+
+// ExecuteBatch リクエストを順に実行し、それぞれのステータスと応答を返す
+func (h *BatchHandler) ExecuteBatch(c *gin.Context) {
+	var req batchRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	// /api/v1/batch なら /api/v1 を基準にする
+	prefix := strings.TrimSuffix(c.FullPath(), "/batch")
+	requests := make([]*http.Request, len(req.Requests))
+	for i, item := range req.Requests {
+		sub, err := h.newRequest(c, prefix, item)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, fmt.Sprintf("requests[%d]: %v", i, err))
+			return
+		}
+		requests[i] = sub
+	}
+
+	results := make([]batchResult, len(requests))
+	failed := false
+	for i, sub := range requests {
+		if failed && req.StopOnError {
+			results[i] = batchResult{Status: http.StatusFailedDependency}
+			continue
+		}
+		results[i] = h.execute(sub)
+		if results[i].Status >= http.StatusBadRequest {
+			failed = true
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// newRequest バッチの1件を、元のリクエストの認証情報を引き継いだリクエストにする
+func (h *BatchHandler) newRequest(c *gin.Context, prefix string, item batchItem) (*http.Request, error) {
+	method := strings.ToUpper(item.Method)
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return nil, fmt.Errorf("methodはGET・POST・PUT・PATCH・DELETEのいずれかで指定してください")
+	}
+	if !strings.HasPrefix(item.Path, "/") {
+		return nil, fmt.Errorf("pathは/から始まるパスで指定してください")
+	}
+	if item.Path == "/batch" || strings.HasPrefix(item.Path, "/batch?") {
+		return nil, fmt.Errorf("バッチの中でバッチは実行できません")
+	}
+
+	var body *bytes.Reader
+	if len(item.Body) > 0 && string(item.Body) != "null" {
+		body = bytes.NewReader(item.Body)
+	} else {
+		body = bytes.NewReader(nil)
+	}
+	sub, err := http.NewRequestWithContext(c.Request.Context(), method, prefix+item.Path, body)
+	if err != nil {
+		return nil, fmt.Errorf("pathが不正です")
+	}
+	for _, name := range batchForwardHeaders {
+		if value := c.GetHeader(name); value != "" {
+			sub.Header.Set(name, value)
+		}
+	}
+	for name, value := range item.Headers {
+		name = http.CanonicalHeaderKey(name)
+		if !batchItemHeaders[name] {
+			return nil, fmt.Errorf("%sヘッダーは指定できません", name)
+		}
+		sub.Header.Set(name, value)
+	}
+	if body.Len() > 0 {
+		sub.Header.Set("Content-Type", "application/json")
+	}
+	sub.RemoteAddr = c.Request.RemoteAddr
+	return sub, nil
+}
+
+// execute リクエストをルーターで実行し、結果を受け取る
+func (h *BatchHandler) execute(req *http.Request) batchResult {
+	rec := &batchRecorder{header: http.Header{}, status: http.StatusOK}
+	h.engine.ServeHTTP(rec, req)
+
+	result := batchResult{Status: rec.status}
+	for _, name := range []string{"ETag", "Location"} {
+		if value := rec.header.Get(name); value != "" {
+			if result.Headers == nil {
+				result.Headers = map[string]string{}
+			}
+			result.Headers[name] = value
+		}
+	}
+	if rec.body.Len() > 0 {
+		if strings.HasPrefix(rec.header.Get("Content-Type"), "application/json") {
+			result.Body = json.RawMessage(rec.body.Bytes())
+		} else {
+			result.Body = rec.body.String()
+		}
+	}
+	return result
+}
+
+// batchRecorder バッチで実行したリクエストの応答を受け取る
+type batchRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *batchRecorder) Header() http.Header {
+	return w.header
+}
+
+func (w *batchRecorder) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *batchRecorder) WriteHeader(status int) {
+	w.status = status
+}
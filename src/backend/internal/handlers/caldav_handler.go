@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	davRoot          = "/caldav"
+	davPrincipalPath = davRoot + "/principal/"
+	davHomePath      = davRoot + "/calendars/"
+)
+
+// CalDAVMethods CalDAVのルートで受け付けるHTTPメソッド
+var CalDAVMethods = []string{
+	http.MethodOptions, "PROPFIND", "REPORT",
+	http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete,
+}
+
+type CalDAVHandler struct {
+	caldavService *services.CalDAVService
+}
+
+func NewCalDAVHandler(caldavService *services.CalDAVService) *CalDAVHandler {
+	return &CalDAVHandler{caldavService: caldavService}
+}
+
+// WellKnown /.well-known/caldav からCalDAVのルートへ転送
+func (h *CalDAVHandler) WellKnown(c *gin.Context) {
+	c.Redirect(http.StatusMovedPermanently, davRoot+"/")
+}
+
+// ServeDAV CalDAVリクエストをパスとメソッドで振り分ける
+//
+// /caldav/                          ルート
+// /caldav/principal/                ログインユーザーのプリンシパル
+// /caldav/calendars/                カレンダーホーム
+// /caldav/calendars/{calendar}/     カレンダー（personal または team-{チームID}）
+// /caldav/calendars/{calendar}/{name}.ics  イベント
+func (h *CalDAVHandler) ServeDAV(c *gin.Context) {
+	c.Header("DAV", "1, 3, calendar-access")
+	if c.Request.Method == http.MethodOptions {
+		c.Header("Allow", strings.Join(CalDAVMethods, ", "))
+		c.Status(http.StatusOK)
+		return
+	}
+
+	segments := strings.FieldsFunc(c.Param("path"), func(r rune) bool { return r == '/' })
+	isCollection := len(segments) < 3
+	switch {
+	case len(segments) == 0, len(segments) == 1 && segments[0] == "principal":
+		if c.Request.Method != "PROPFIND" {
+			c.Status(http.StatusMethodNotAllowed)
+			return
+		}
+		h.propfindPrincipal(c, len(segments) == 1)
+		return
+	case segments[0] != "calendars" || len(segments) > 3:
+		c.Status(http.StatusNotFound)
+		return
+	case len(segments) == 1:
+		if c.Request.Method != "PROPFIND" {
+			c.Status(http.StatusMethodNotAllowed)
+			return
+		}
+		h.propfindHome(c)
+		return
+	}
+
+	userID := c.GetString("userID")
+	cal, err := h.caldavService.Calendar(userID, segments[1])
+	if err != nil {
+		c.Status(serviceErrorStatus(err))
+		return
+	}
+
+	switch method := c.Request.Method; {
+	case isCollection && method == "PROPFIND":
+		h.propfindCalendar(c, cal)
+	case isCollection && method == "REPORT":
+		h.report(c, cal)
+	case isCollection:
+		c.Status(http.StatusMethodNotAllowed)
+	case method == "PROPFIND":
+		h.propfindObject(c, cal, segments[2])
+	case method == http.MethodGet, method == http.MethodHead:
+		h.getObject(c, cal, segments[2])
+	case method == http.MethodPut:
+		h.putObject(c, cal, segments[2])
+	case method == http.MethodDelete:
+		h.deleteObject(c, cal, segments[2])
+	default:
+		c.Status(http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *CalDAVHandler) propfindPrincipal(c *gin.Context, isPrincipal bool) {
+	href := davRoot + "/"
+	resourceType := "<d:collection/>"
+	if isPrincipal {
+		href = davPrincipalPath
+		resourceType = "<d:principal/>"
+	}
+	writeMultistatus(c, []davResponse{{href: href, props: []string{
+		"<d:resourcetype>" + resourceType + "</d:resourcetype>",
+		hrefProp("d:current-user-principal", davPrincipalPath),
+		hrefProp("d:principal-URL", davPrincipalPath),
+		hrefProp("c:calendar-home-set", davHomePath),
+	}}})
+}
+
+func (h *CalDAVHandler) propfindHome(c *gin.Context) {
+	responses := []davResponse{{href: davHomePath, props: []string{
+		"<d:resourcetype><d:collection/></d:resourcetype>",
+		hrefProp("d:current-user-principal", davPrincipalPath),
+	}}}
+
+	if c.GetHeader("Depth") != "0" {
+		calendars, err := h.caldavService.Calendars(c.GetString("userID"))
+		if err != nil {
+			c.Status(serviceErrorStatus(err))
+			return
+		}
+		for i := range calendars {
+			responses = append(responses, calendarResponse(&calendars[i]))
+		}
+	}
+	writeMultistatus(c, responses)
+}
+
+func (h *CalDAVHandler) propfindCalendar(c *gin.Context, cal *services.CalDAVCalendar) {
+	responses := []davResponse{calendarResponse(cal)}
+
+	if c.GetHeader("Depth") != "0" {
+		objects, err := h.caldavService.Objects(c.GetString("userID"), cal)
+		if err != nil {
+			c.Status(serviceErrorStatus(err))
+			return
+		}
+		for i := range objects {
+			responses = append(responses, objectResponse(cal, &objects[i], false))
+		}
+	}
+	writeMultistatus(c, responses)
+}
+
+func (h *CalDAVHandler) propfindObject(c *gin.Context, cal *services.CalDAVCalendar, name string) {
+	object, err := h.caldavService.Object(c.GetString("userID"), cal, name)
+	if err != nil {
+		c.Status(serviceErrorStatus(err))
+		return
+	}
+	writeMultistatus(c, []davResponse{objectResponse(cal, object, false)})
+}
+
+// calendarReport REPORTリクエスト本文（calendar-multiget / calendar-query）
+type calendarReport struct {
+	XMLName xml.Name
+	Hrefs   []string `xml:"DAV: href"`
+}
+
+// report calendar-multiget は指定されたリソース、calendar-query はカレンダー内の全リソースを返す
+//
+// calendar-queryのフィルター（time-rangeなど）は適用せず、クライアント側での絞り込みに任せる。
+func (h *CalDAVHandler) report(c *gin.Context, cal *services.CalDAVCalendar) {
+	userID := c.GetString("userID")
+
+	var req calendarReport
+	if err := xml.NewDecoder(io.LimitReader(c.Request.Body, 1<<20)).Decode(&req); err != nil {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	var responses []davResponse
+	switch req.XMLName.Local {
+	case "calendar-multiget":
+		for _, href := range req.Hrefs {
+			name := path.Base(href)
+			if unescaped, err := url.PathUnescape(name); err == nil {
+				name = unescaped
+			}
+			object, err := h.caldavService.Object(userID, cal, name)
+			if err != nil {
+				responses = append(responses, davResponse{href: href, status: serviceErrorStatus(err)})
+				continue
+			}
+			responses = append(responses, objectResponse(cal, object, true))
+		}
+	case "calendar-query":
+		objects, err := h.caldavService.Objects(userID, cal)
+		if err != nil {
+			c.Status(serviceErrorStatus(err))
+			return
+		}
+		for i := range objects {
+			responses = append(responses, objectResponse(cal, &objects[i], true))
+		}
+	default:
+		c.Status(http.StatusForbidden)
+		return
+	}
+	writeMultistatus(c, responses)
+}
+
+func (h *CalDAVHandler) getObject(c *gin.Context, cal *services.CalDAVCalendar, name string) {
+	object, err := h.caldavService.Object(c.GetString("userID"), cal, name)
+	if err != nil {
+		c.Status(serviceErrorStatus(err))
+		return
+	}
+	c.Header("ETag", object.ETag)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", object.Data)
+}
+
+func (h *CalDAVHandler) putObject(c *gin.Context, cal *services.CalDAVCalendar, name string) {
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	object, created, err := h.caldavService.PutObject(c.GetString("userID"), cal, name, body, davPrecondition(c))
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.Status(http.StatusRequestEntityTooLarge)
+			return
+		}
+		c.String(serviceErrorStatus(err), err.Error())
+		return
+	}
+
+	c.Header("ETag", object.ETag)
+	if created {
+		c.Status(http.StatusCreated)
+	} else {
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func (h *CalDAVHandler) deleteObject(c *gin.Context, cal *services.CalDAVCalendar, name string) {
+	if err := h.caldavService.DeleteObject(c.GetString("userID"), cal, name, davPrecondition(c)); err != nil {
+		c.Status(serviceErrorStatus(err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func davPrecondition(c *gin.Context) services.CalDAVPrecondition {
+	return services.CalDAVPrecondition{
+		IfMatch:     strings.TrimPrefix(c.GetHeader("If-Match"), "W/"),
+		IfNoneMatch: c.GetHeader("If-None-Match") == "*",
+	}
+}
+
+// davResponse multistatus内の1リソース分（statusが0以外の場合はプロパティなしのエラー応答）
+type davResponse struct {
+	href   string
+	props  []string
+	status int
+}
+
+func calendarResponse(cal *services.CalDAVCalendar) davResponse {
+	return davResponse{href: davHomePath + url.PathEscape(cal.Name) + "/", props: []string{
+		"<d:resourcetype><d:collection/><c:calendar/></d:resourcetype>",
+		textProp("d:displayname", cal.DisplayName),
+		textProp("cs:getctag", cal.CTag),
+		`<c:supported-calendar-component-set><c:comp name="VEVENT"/></c:supported-calendar-component-set>`,
+		"<d:current-user-privilege-set><d:privilege><d:read/></d:privilege><d:privilege><d:write/></d:privilege></d:current-user-privilege-set>",
+		hrefProp("d:current-user-principal", davPrincipalPath),
+	}}
+}
+
+func objectResponse(cal *services.CalDAVCalendar, object *services.CalDAVObject, withData bool) davResponse {
+	props := []string{
+		"<d:resourcetype/>",
+		textProp("d:getetag", object.ETag),
+		textProp("d:getcontenttype", "text/calendar; charset=utf-8; component=VEVENT"),
+	}
+	if withData {
+		props = append(props, textProp("c:calendar-data", string(object.Data)))
+	}
+	return davResponse{href: davHomePath + url.PathEscape(cal.Name) + "/" + url.PathEscape(object.Name), props: props}
+}
+
+func writeMultistatus(c *gin.Context, responses []davResponse) {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" xmlns:cs="http://calendarserver.org/ns/">`)
+	for _, r := range responses {
+		b.WriteString("<d:response>")
+		b.WriteString(textProp("d:href", r.href))
+		if r.status != 0 {
+			b.WriteString(textProp("d:status", davStatusLine(r.status)))
+		} else {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, p := range r.props {
+				b.WriteString(p)
+			}
+			b.WriteString("</d:prop>")
+			b.WriteString(textProp("d:status", davStatusLine(http.StatusOK)))
+			b.WriteString("</d:propstat>")
+		}
+		b.WriteString("</d:response>")
+	}
+	b.WriteString("</d:multistatus>")
+	c.Data(http.StatusMultiStatus, "application/xml; charset=utf-8", b.Bytes())
+}
+
+func davStatusLine(status int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", status, http.StatusText(status))
+}
+
+func textProp(name, value string) string {
+	var escaped bytes.Buffer
+	_ = xml.EscapeText(&escaped, []byte(value))
+	return "<" + name + ">" + escaped.String() + "</" + name + ">"
+}
+
+func hrefProp(name, href string) string {
+	return "<" + name + ">" + textProp("d:href", href) + "</" + name + ">"
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarSyncHandler struct {
+	calendarSyncService *services.CalendarSyncService
+}
+
+func NewCalendarSyncHandler(calendarSyncService *services.CalendarSyncService) *CalendarSyncHandler {
+	return &CalendarSyncHandler{calendarSyncService: calendarSyncService}
+}
+
+// Authorize 外部カレンダー連携の認可URL取得
+func (h *CalendarSyncHandler) Authorize(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	authURL, err := h.calendarSyncService.AuthorizeURL(userID, providerParam(c))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"url": authURL})
+}
+
+// Callback 認可後のリダイレクト先（stateでユーザーを識別するため認証不要）
+func (h *CalendarSyncHandler) Callback(c *gin.Context) {
+	if errCode := c.Query("error"); errCode != "" {
+		respondError(c, http.StatusBadRequest, "連携が拒否されました: "+errCode)
+		return
+	}
+	code := c.Query("code")
+	if code == "" {
+		respondError(c, http.StatusBadRequest, "codeは必須です")
+		return
+	}
+
+	connection, err := h.calendarSyncService.CompleteAuthorization(c.Request.Context(), providerParam(c), code, c.Query("state"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "カレンダーを連携しました", "connection": connection})
+}
+
+// GetConnections カレンダー連携一覧取得
+func (h *CalendarSyncHandler) GetConnections(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	sort, err := parseSort(c, services.ConnectionSortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	connections, err := h.calendarSyncService.ListConnections(userID, sort)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, connections)
+}
+
+// SyncConnection カレンダー連携の即時同期
+func (h *CalendarSyncHandler) SyncConnection(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	connection, err := h.calendarSyncService.SyncConnection(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, connection)
+}
+
+// DeleteConnection カレンダー連携解除
+func (h *CalendarSyncHandler) DeleteConnection(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.calendarSyncService.DeleteConnection(c.Param("id"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "カレンダー連携を解除しました"})
+}
+
+func providerParam(c *gin.Context) models.CalendarProvider {
+	return models.CalendarProvider(strings.ToUpper(c.Param("provider")))
+}
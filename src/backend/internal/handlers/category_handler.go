@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	categoryService *services.CategoryService
+}
+
+func NewCategoryHandler(categoryService *services.CategoryService) *CategoryHandler {
+	return &CategoryHandler{categoryService: categoryService}
+}
+
+// GetCategories チームのイベントカテゴリー一覧取得
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	sort, err := parseSort(c, services.CategorySortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	categories, err := h.categoryService.ListCategories(c.Param("id"), userID, sort)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, categories)
+}
+
+// CreateCategory イベントカテゴリーの登録
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.EventCategoryInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, category)
+}
+
+// UpdateCategory イベントカテゴリーの更新
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.EventCategoryInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	category, err := h.categoryService.UpdateCategory(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory イベントカテゴリーの削除
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.categoryService.DeleteCategory(c.Param("id"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "カテゴリーを削除しました"})
+}
+
+// SetEventAppearance イベントの表示色・カテゴリーの設定
+func (h *CategoryHandler) SetEventAppearance(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.EventAppearanceInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	event, err := h.categoryService.SetEventAppearance(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
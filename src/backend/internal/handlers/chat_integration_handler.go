@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChatIntegrationHandler struct {
+	chatIntegrationService *services.ChatIntegrationService
+}
+
+func NewChatIntegrationHandler(chatIntegrationService *services.ChatIntegrationService) *ChatIntegrationHandler {
+	return &ChatIntegrationHandler{chatIntegrationService: chatIntegrationService}
+}
+
+// GetTeamChannels チームに設定したチャットのチャンネル一覧
+func (h *ChatIntegrationHandler) GetTeamChannels(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	channels, err := h.chatIntegrationService.ListTeamChannels(c.Param("id"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, channels)
+}
+
+// SetTeamChannel チームの通知を投稿するチャンネルを設定（:provider は slack / teams / discord）
+func (h *ChatIntegrationHandler) SetTeamChannel(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var input services.TeamChatChannelInput
+	if !bindJSON(c, &input) {
+		return
+	}
+
+	channel, err := h.chatIntegrationService.SetTeamChannel(c.Param("id"), userID, chatProviderParam(c), input)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, channel)
+}
+
+// DeleteTeamChannel チームのチャンネル設定を削除
+func (h *ChatIntegrationHandler) DeleteTeamChannel(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.chatIntegrationService.DeleteTeamChannel(c.Param("id"), userID, chatProviderParam(c)); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "チャット連携を削除しました"})
+}
+
+func chatProviderParam(c *gin.Context) models.ChatProvider {
+	return models.ChatProvider(strings.ToUpper(c.Param("provider")))
+}
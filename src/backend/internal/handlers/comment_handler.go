@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentHandler struct {
+	commentService *services.CommentService
+}
+
+func NewCommentHandler(commentService *services.CommentService) *CommentHandler {
+	return &CommentHandler{commentService: commentService}
+}
+
+type commentRequest struct {
+	Content string `json:"content" binding:"required,notblank,max=10000"`
+}
+
+type addReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required,max=32"`
+}
+
+// GetComments コメント一覧取得（cursor, limit, sort=createdAt|-createdAt, include=author,attachments,mentions。order=newest|oldest も使える）
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	pageOpts, err := parsePageOptions(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if pageOpts.Sort, err = parseSort(c, services.CursorSortFields); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	include, err := parseInclude(c, services.CommentIncludes)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := h.commentService.ListComments(c.Param("id"), userID, services.CommentListOptions{
+		PageOptions: pageOpts,
+		Order:       c.Query("order"),
+		Include:     include,
+	})
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondListPage(c, page, page.Comments, listPage{NextCursor: page.NextCursor, Total: page.Total})
+}
+
+// CreateComment コメント投稿
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req commentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	comment, err := h.commentService.CreateComment(c.Param("id"), userID, req.Content)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+// UpdateComment コメント編集
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req commentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	comment, err := h.commentService.UpdateComment(c.Param("id"), c.Param("commentId"), userID, req.Content)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment コメント削除
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.commentService.DeleteComment(c.Param("id"), c.Param("commentId"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "コメントを削除しました"})
+}
+
+// GetCommentHistory コメント編集履歴取得
+func (h *CommentHandler) GetCommentHistory(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	revisions, err := h.commentService.GetCommentHistory(c.Param("id"), c.Param("commentId"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, revisions)
+}
+
+// AddReaction コメントへのリアクション追加
+func (h *CommentHandler) AddReaction(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req addReactionRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	comment, err := h.commentService.AddReaction(c.Param("id"), c.Param("commentId"), userID, req.Emoji)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+// RemoveReaction コメントへのリアクション削除
+func (h *CommentHandler) RemoveReaction(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	comment, err := h.commentService.RemoveReaction(c.Param("id"), c.Param("commentId"), userID, c.Param("emoji"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+// PinComment コメントの固定
+func (h *CommentHandler) PinComment(c *gin.Context) {
+	h.setPinned(c, true)
+}
+
+// UnpinComment コメントの固定解除
+func (h *CommentHandler) UnpinComment(c *gin.Context) {
+	h.setPinned(c, false)
+}
+
+func (h *CommentHandler) setPinned(c *gin.Context, pinned bool) {
+	userID := c.GetString("userID")
+
+	task, err := h.commentService.PinComment(c.Param("id"), c.Param("commentId"), userID, pinned)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 変更からこの時間が経つまでは Last-Modified を返さない
+//
+// 変更日時はコミット前に記録されるため、実行中のトランザクションの変更を見落とした応答に日時をつけないようにする。
+const lastModifiedSettle = 5 * time.Second
+
+// notModified 最後の変更日時を Last-Modified に設定し、If-Modified-Since 以降に変更がなければ304を返す（返した場合は true）
+//
+// Last-Modified は秒単位のため、変更日時を切り上げた秒にする。同じ秒のうちに後から変更があっても、
+// その秒が過ぎるまでは日時を返さないので、古い日時のまま304を返すことはない。If-None-Match がある場合はそちらを優先する。
+func notModified(c *gin.Context, changedAt time.Time) bool {
+	if changedAt.IsZero() {
+		return false
+	}
+	lastModified := changedAt.UTC().Truncate(time.Second).Add(time.Second)
+	if time.Now().Before(lastModified.Add(lastModifiedSettle)) {
+		return false
+	}
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+
+	if c.GetHeader("If-None-Match") != "" {
+		return false
+	}
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil || lastModified.After(since) {
+		return false
+	}
+	c.Status(http.StatusNotModified)
+	return true
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConferenceHandler struct {
+	conferenceService *services.ConferenceService
+}
+
+func NewConferenceHandler(conferenceService *services.ConferenceService) *ConferenceHandler {
+	return &ConferenceHandler{conferenceService: conferenceService}
+}
+
+// AddConference イベントにビデオ会議リンクを作成
+func (h *ConferenceHandler) AddConference(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req struct {
+		Provider models.ConferenceProvider `json:"provider" binding:"required,oneof=ZOOM GOOGLE_MEET NONE"`
+	}
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	event, err := h.conferenceService.AddConference(c.Request.Context(), c.Param("id"), userID, req.Provider)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, event)
+}
+
+// RemoveConference イベントのビデオ会議リンクを削除
+func (h *ConferenceHandler) RemoveConference(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.conferenceService.RemoveConference(c.Request.Context(), c.Param("id"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "ビデオ会議リンクを削除しました"})
+}
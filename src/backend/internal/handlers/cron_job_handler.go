@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CronJobHandler struct {
+	cronStateService *services.CronStateService
+}
+
+func NewCronJobHandler(cronStateService *services.CronStateService) *CronJobHandler {
+	return &CronJobHandler{cronStateService: cronStateService}
+}
+
+// GetCronJobs 定期実行のジョブの一覧（管理者。前回の実行の結果・エラー、次に実行する予定の日時、一時停止中か）
+func (h *CronJobHandler) GetCronJobs(c *gin.Context) {
+	jobs, err := h.cronStateService.ListJobs()
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, jobs)
+}
+
+// RunCronJob 定期実行のジョブを今すぐ実行する（管理者。202。結果は一覧で確認する）
+func (h *CronJobHandler) RunCronJob(c *gin.Context) {
+	job, err := h.cronStateService.TriggerJob(c.Param("name"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, job)
+}
+
+// PauseCronJob 定期実行のジョブを一時停止する（管理者。再開するまで予定の日時を迎えても実行しない）
+func (h *CronJobHandler) PauseCronJob(c *gin.Context) {
+	h.setPaused(c, true)
+}
+
+// ResumeCronJob 一時停止した定期実行のジョブを再開する（管理者）
+func (h *CronJobHandler) ResumeCronJob(c *gin.Context) {
+	h.setPaused(c, false)
+}
+
+func (h *CronJobHandler) setPaused(c *gin.Context, paused bool) {
+	job, err := h.cronStateService.SetPaused(c.Param("name"), paused)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DailyAgendaHandler struct {
+	dailyAgendaService *services.DailyAgendaService
+}
+
+func NewDailyAgendaHandler(dailyAgendaService *services.DailyAgendaService) *DailyAgendaHandler {
+	return &DailyAgendaHandler{dailyAgendaService: dailyAgendaService}
+}
+
+// GetSettings 自分の毎朝のアジェンダメールの設定
+func (h *DailyAgendaHandler) GetSettings(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	settings, err := h.dailyAgendaService.GetSettings(userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings 毎朝のアジェンダメールの有効・無効と送信時刻の設定
+func (h *DailyAgendaHandler) UpdateSettings(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.DailyAgendaInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	settings, err := h.dailyAgendaService.UpdateSettings(userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeltaSyncHandler struct {
+	syncService *services.DeltaSyncService
+}
+
+func NewDeltaSyncHandler(syncService *services.DeltaSyncService) *DeltaSyncHandler {
+	return &DeltaSyncHandler{syncService: syncService}
+}
+
+// ListChanges 前回の同期の後に作成・更新・削除したタスク・イベント・コメント（since は前回の nextCursor、limit は既定100）
+//
+// since を省略すると全件を返す。hasMore の間は nextCursor を since にして続きを取得する。
+// since が古く削除の記録が残っていない場合は410（RESYNC_REQUIRED）を返すため、since を省略して取り直す。
+func (h *DeltaSyncHandler) ListChanges(c *gin.Context) {
+	opts := services.ChangesOptions{Since: c.Query("since")}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			respondError(c, http.StatusBadRequest, "limitは正の整数で指定してください")
+			return
+		}
+		opts.Limit = limit
+	}
+
+	changes, err := h.syncService.ListChanges(c.GetString("userID"), opts)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, changes)
+}
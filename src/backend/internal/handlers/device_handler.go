@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeviceHandler struct {
+	pushService *services.PushService
+}
+
+func NewDeviceHandler(pushService *services.PushService) *DeviceHandler {
+	return &DeviceHandler{pushService: pushService}
+}
+
+// List プッシュ通知を受け取る自分の端末
+func (h *DeviceHandler) List(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	sort, err := parseSort(c, services.DeviceSortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	devices, err := h.pushService.ListDevices(userID, sort)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondListPage(c, gin.H{"devices": devices}, devices, listPage{})
+}
+
+// Register 端末のプッシュ通知のトークンを登録する（登録済みのトークンは更新する）
+func (h *DeviceHandler) Register(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.DeviceTokenInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	device, err := h.pushService.RegisterDevice(userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, device)
+}
+
+// Delete 端末の登録を削除する（以降はプッシュ通知を送らない）
+func (h *DeviceHandler) Delete(c *gin.Context) {
+	userID := c.GetString("userID")
+	deviceID := c.Param("id")
+
+	if err := h.pushService.DeleteDevice(deviceID, userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "デバイスを削除しました"})
+}
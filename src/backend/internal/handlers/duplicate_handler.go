@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DuplicateHandler struct {
+	duplicateService *services.DuplicateService
+}
+
+func NewDuplicateHandler(duplicateService *services.DuplicateService) *DuplicateHandler {
+	return &DuplicateHandler{duplicateService: duplicateService}
+}
+
+// DuplicateEvent イベントの複製
+func (h *DuplicateHandler) DuplicateEvent(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.DuplicateEventInput
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+
+	result, err := h.duplicateService.DuplicateEvent(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/apierror"
+	"task-calendar-backend/internal/i18n"
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/services"
+	"task-calendar-backend/internal/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// respondServiceError サービス層のエラーをHTTPステータスに変換して返す
+func respondServiceError(c *gin.Context, err error) {
+	var conflictErr *services.ConflictError
+	if errors.As(err, &conflictErr) {
+		body := apierror.New(c, apierror.CodeScheduleConflict, err.Error())
+		body.Conflicts = conflictErr.Conflicts
+		c.JSON(http.StatusConflict, body)
+		return
+	}
+	var versionErr *models.VersionConflictError
+	if errors.As(err, &versionErr) {
+		// クライアントが最新の状態と自分の変更を見比べられるよう、サーバーの現在の状態を返す
+		body := apierror.New(c, apierror.CodeVersionConflict, err.Error())
+		body.Current = versionErr.Current
+		c.JSON(http.StatusConflict, body)
+		return
+	}
+	if errors.Is(err, services.ErrResyncRequired) {
+		c.JSON(http.StatusGone, apierror.New(c, apierror.CodeResyncRequired, err.Error()))
+		return
+	}
+	status := serviceErrorStatus(err)
+	if errors.Is(err, models.ErrVersionConflict) {
+		c.JSON(status, apierror.New(c, apierror.CodeVersionConflict, err.Error()))
+		return
+	}
+	respondError(c, status, err.Error())
+}
+
+// respondError ステータスに対応する code でエラーを返す
+func respondError(c *gin.Context, status int, message string) {
+	apierror.Respond(c, status, message)
+}
+
+// bindJSON 本文をJSONとして読み込み、binding タグで検証する（失敗した場合は400を返して false）
+func bindJSON(c *gin.Context, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		respondBindError(c, err)
+		return false
+	}
+	return true
+}
+
+// respondBindError リクエストの本文の検証エラーを、項目ごとの details とともに返す
+func respondBindError(c *gin.Context, err error) {
+	lang := i18n.FromContext(c)
+	message := "入力内容に誤りがあります"
+	var details []apierror.Detail
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, fe := range validationErrs {
+			details = append(details, apierror.Detail{Field: fieldPath(fe.Namespace()), Reason: fe.Tag(), Message: validation.Message(fe, lang)})
+		}
+	case errors.As(err, &typeErr):
+		details = append(details, apierror.Detail{Field: typeErr.Field, Reason: "type", Message: typeErrorMessage(typeErr, lang)})
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		message = "本文のJSONが不正です"
+	case errors.Is(err, io.EOF):
+		message = "本文を指定してください"
+	default:
+		message = err.Error()
+	}
+	apierror.Respond(c, http.StatusBadRequest, message, details...)
+}
+
+// fieldPath 検証エラーの項目（PollInput.options[0].startDate）から先頭の型名を除く
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// typeErrorMessage JSONの値の型が項目の型と合わない場合のメッセージ
+func typeErrorMessage(err *json.UnmarshalTypeError, lang i18n.Lang) string {
+	name := jsonTypeNames[jsonTypeName(err.Type)]
+	if lang == i18n.English {
+		return fmt.Sprintf("%s must be %s", err.Field, name[lang])
+	}
+	return fmt.Sprintf("%sの型が不正です（%sで指定してください）", err.Field, name[i18n.Japanese])
+}
+
+// jsonTypeNames JSONの型の表示名
+var jsonTypeNames = map[string]map[i18n.Lang]string{
+	"string":   {i18n.Japanese: "文字列", i18n.English: "a string"},
+	"boolean":  {i18n.Japanese: "真偽値", i18n.English: "a boolean"},
+	"integer":  {i18n.Japanese: "整数", i18n.English: "an integer"},
+	"number":   {i18n.Japanese: "数値", i18n.English: "a number"},
+	"array":    {i18n.Japanese: "配列", i18n.English: "an array"},
+	"datetime": {i18n.Japanese: "日時の文字列", i18n.English: "a date-time string"},
+	"object":   {i18n.Japanese: "オブジェクト", i18n.English: "an object"},
+}
+
+// jsonTypeName Goの型に対応するJSONの型
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Ptr:
+		return jsonTypeName(t.Elem())
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return "datetime"
+	}
+	return "object"
+}
+
+// serviceErrorStatus サービス層のエラーに対応するHTTPステータス
+func serviceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrForbidden), errors.Is(err, models.ErrReadOnlyEvent),
+		errors.Is(err, models.ErrWorkspaceMismatch):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrInvalidInput), errors.Is(err, models.ErrInvalidTimeZone),
+		errors.Is(err, models.ErrInvalidColor):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, services.ErrConflict), errors.Is(err, models.ErrVersionConflict),
+		errors.Is(err, services.ErrAlreadySeeded), errors.Is(err, services.ErrJobNotRetryable),
+		errors.Is(err, services.ErrCronJobRunning):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
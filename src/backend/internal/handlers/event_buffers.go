@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetEventBuffers イベントの前後の移動時間の設定
+func (h *EventHandler) SetEventBuffers(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.EventBuffersInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	event, err := h.eventService.SetEventBuffers(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CheckConflicts 作成・更新前のイベントと参加者の予定の重複確認
+func (h *EventHandler) CheckConflicts(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.ConflictCheckInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	conflicts, err := h.eventService.CheckConflicts(userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"conflicts": conflicts})
+}
+
+// GetEventConflicts イベントと作成者・参加者の予定の重複取得
+func (h *EventHandler) GetEventConflicts(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	conflicts, err := h.eventService.GetEventConflicts(c.Param("id"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"conflicts": conflicts})
+}
+
+// UpdateTeamEventSettings チームのイベント設定（重複の禁止など）の更新
+func (h *EventHandler) UpdateTeamEventSettings(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.TeamEventSettingsInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	team, err := h.eventService.UpdateTeamEventSettings(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, team)
+}
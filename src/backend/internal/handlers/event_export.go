@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportICS 閲覧可能なイベントをiCalendar形式でエクスポート
+func (h *EventHandler) ExportICS(c *gin.Context) {
+	h.exportICS(c, nil, "events.ics")
+}
+
+// ExportTeamICS チームのイベントをiCalendar形式でエクスポート
+func (h *EventHandler) ExportTeamICS(c *gin.Context) {
+	teamID := c.Param("id")
+	h.exportICS(c, &teamID, "team-"+teamID+".ics")
+}
+
+func (h *EventHandler) exportICS(c *gin.Context, teamID *string, filename string) {
+	userID := c.GetString("userID")
+
+	loc, err := parseLocation(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	data, err := h.eventService.ExportICS(userID, services.ExportOptions{TeamID: teamID, Location: loc})
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", data)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/services"
+	"task-calendar-backend/internal/upload"
+
+	"github.com/gin-gonic/gin"
+)
+
+// インポートできる.icsファイルの最大サイズ
+const maxImportSize = 5 << 20
+
+// importLimits インポートするファイルの制限（iCalendarはテキストのため、内容がテキストのもののみ受け付ける）
+var importLimits = upload.Limits{MaxSize: maxImportSize, AllowedTypes: []string{"text/"}}
+
+// ImportICS iCalendarファイルからイベントをインポート
+//
+// multipart/form-data の file、または text/calendar の本文を受け付ける。
+// ?dryRun=true の場合は保存せずに結果のみ返す。
+func (h *EventHandler) ImportICS(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	opts, ok := parseImportOptions(c)
+	if !ok {
+		return
+	}
+	body, ok := importBody(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.eventService.ImportICS(userID, body, opts)
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if opts.DryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, report)
+}
+
+// parseImportOptions dryRun・teamId・tz クエリを読む（不正な場合は400を返して false）
+func parseImportOptions(c *gin.Context) (services.ImportOptions, bool) {
+	opts := services.ImportOptions{}
+	if dryRun := c.Query("dryRun"); dryRun != "" {
+		v, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "dryRunが不正です")
+			return opts, false
+		}
+		opts.DryRun = v
+	}
+	if teamID := c.Query("teamId"); teamID != "" {
+		opts.TeamID = &teamID
+	}
+	if tz := c.Query("tz"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "tzが不正です")
+			return opts, false
+		}
+		opts.Location = loc
+	}
+	return opts, true
+}
+
+// importBody multipart/form-data の file、または本文（importLimits まで。メモリにためずにリクエストから読み出す）
+func importBody(c *gin.Context) (io.Reader, bool) {
+	var file *upload.File
+	var err error
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err = upload.FromRequest(c.Writer, c.Request, "file", importLimits)
+	} else {
+		file, err = upload.FromBody(c.Writer, c.Request, importLimits)
+	}
+	if err != nil {
+		respondUploadError(c, err)
+		return nil, false
+	}
+	return file, true
+}
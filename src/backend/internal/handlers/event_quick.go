@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuickEvent 自然文によるイベントの作成（create を指定しない場合は解釈のみ返す）
+func (h *EventHandler) QuickEvent(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.QuickEventInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.eventService.QuickEvent(userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	status := http.StatusOK
+	if result.Event != nil {
+		status = http.StatusCreated
+	}
+	c.JSON(status, result)
+}
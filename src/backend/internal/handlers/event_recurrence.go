@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListEvents イベント一覧取得（expand=true の場合は from〜to の発生に展開して返す）
+func (h *EventHandler) ListEvents(c *gin.Context) {
+	expand := false
+	if v := c.Query("expand"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "expandが不正です")
+			return
+		}
+		expand = b
+	}
+	if expand {
+		h.GetOccurrences(c)
+		return
+	}
+	h.GetEvents(c)
+}
+
+// GetOccurrences 閲覧可能なイベントを期間内の発生に展開して取得（絞り込み条件は parseOccurrenceFilter）
+func (h *EventHandler) GetOccurrences(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	loc, err := parseLocation(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	from, to, err := parseTimeRange(c, loc)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	occurrences, err := h.eventService.ListOccurrences(userID, from, to, parseOccurrenceFilter(c))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, services.LocalizeOccurrences(occurrences, loc))
+}
+
+// GetEventOccurrences 指定イベントの期間内の発生を取得
+func (h *EventHandler) GetEventOccurrences(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	loc, err := parseLocation(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	from, to, err := parseTimeRange(c, loc)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	occurrences, err := h.eventService.GetEventOccurrences(c.Param("id"), userID, from, to)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, services.LocalizeOccurrences(occurrences, loc))
+}
+
+// OverrideOccurrence 繰り返しイベントの回の変更（scope=this: その回だけ、following: その回以降、all: シリーズ全体）
+func (h *EventHandler) OverrideOccurrence(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	recurrenceID, err := parseRecurrenceID(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req services.OccurrenceOverrideInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if scope := c.DefaultQuery("scope", services.RecurringScopeThis); scope != services.RecurringScopeThis {
+		event, err := h.eventService.UpdateSeries(c.Param("id"), userID, recurrenceID, scope, req)
+		if err != nil {
+			respondServiceError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, event)
+		return
+	}
+
+	exception, err := h.eventService.OverrideOccurrence(c.Param("id"), userID, recurrenceID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, exception)
+}
+
+// CancelOccurrence 繰り返しイベントの特定の回のキャンセル
+func (h *EventHandler) CancelOccurrence(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	recurrenceID, err := parseRecurrenceID(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	exception, err := h.eventService.CancelOccurrence(c.Param("id"), userID, recurrenceID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, exception)
+}
+
+// GetExceptions 繰り返しイベントの例外一覧取得
+func (h *EventHandler) GetExceptions(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	exceptions, err := h.eventService.ListExceptions(c.Param("id"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, exceptions)
+}
+
+// DeleteException 例外を削除してシリーズ通りに戻す
+func (h *EventHandler) DeleteException(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.eventService.DeleteException(c.Param("id"), c.Param("exceptionId"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "例外を削除しました"})
+}
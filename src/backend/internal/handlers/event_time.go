@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MoveEvent イベントの開始・終了日時のみの変更（カレンダー上のドラッグ・リサイズ用）
+func (h *EventHandler) MoveEvent(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.EventTimeInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.eventService.MoveEvent(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
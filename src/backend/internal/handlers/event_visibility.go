@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetEventVisibility イベントの公開範囲の設定
+func (h *EventHandler) SetEventVisibility(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.EventVisibilityInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	event, err := h.eventService.SetEventVisibility(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
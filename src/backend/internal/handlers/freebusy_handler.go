@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FreeBusyHandler struct {
+	freeBusyService *services.FreeBusyService
+}
+
+func NewFreeBusyHandler(freeBusyService *services.FreeBusyService) *FreeBusyHandler {
+	return &FreeBusyHandler{freeBusyService: freeBusyService}
+}
+
+// GetUserFreeBusy ユーザーの空き状況取得（idにmeを指定した場合は自分）
+func (h *FreeBusyHandler) GetUserFreeBusy(c *gin.Context) {
+	id := c.Param("id")
+	if id == "me" {
+		id = c.GetString("userID")
+	}
+	h.respondFreeBusy(c, []string{id}, true)
+}
+
+// GetFreeBusy 複数ユーザーの空き状況取得（userIdsはカンマ区切り）
+func (h *FreeBusyHandler) GetFreeBusy(c *gin.Context) {
+	var userIDs []string
+	for _, id := range strings.Split(c.Query("userIds"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			userIDs = append(userIDs, id)
+		}
+	}
+	h.respondFreeBusy(c, userIDs, false)
+}
+
+func (h *FreeBusyHandler) respondFreeBusy(c *gin.Context, userIDs []string, single bool) {
+	userID := c.GetString("userID")
+
+	loc, err := parseLocation(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	from, to, err := parseTimeRange(c, loc)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.freeBusyService.GetFreeBusy(userID, userIDs, from, to)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	for i := range result {
+		localizeBusy(result[i].Busy, loc)
+	}
+	if single {
+		c.JSON(http.StatusOK, result[0])
+		return
+	}
+	respondList(c, result)
+}
+
+func localizeBusy(intervals []services.BusyInterval, loc *time.Location) {
+	for i := range intervals {
+		intervals[i].Start = intervals[i].Start.In(loc)
+		intervals[i].End = intervals[i].End.In(loc)
+	}
+}
+
+// GetWorkingHours 自分の勤務時間の取得
+func (h *FreeBusyHandler) GetWorkingHours(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	settings, err := h.freeBusyService.GetWorkingHours(userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateWorkingHours 自分の勤務時間とタイムゾーンの設定
+func (h *FreeBusyHandler) UpdateWorkingHours(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.WorkingHoursInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	settings, err := h.freeBusyService.SetWorkingHours(userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// FindSlots 参加者の空き状況から会議の候補時間を検索
+func (h *FreeBusyHandler) FindSlots(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	loc, err := parseLocation(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	var req services.FindSlotsInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	candidates, err := h.freeBusyService.FindSlots(userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	for i := range candidates {
+		candidates[i].Start = candidates[i].Start.In(loc)
+		candidates[i].End = candidates[i].End.In(loc)
+	}
+	respondList(c, candidates)
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	healthService *services.HealthService
+}
+
+func NewHealthHandler(healthService *services.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
+}
+
+// Live プロセスが応答できるか（livenessProbe 向け。依存するものは確認しない）
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": services.HealthOK})
+}
+
+// Ready リクエストを受け付けられるか（readinessProbe 向け）
+//
+// データベース・マイグレーション・定期実行の状態を components で返す。いずれかが使えない場合と停止中は503。
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.healthService.CheckReadiness(c.Request.Context())
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HolidayHandler struct {
+	holidayService *services.HolidayService
+}
+
+func NewHolidayHandler(holidayService *services.HolidayService) *HolidayHandler {
+	return &HolidayHandler{holidayService: holidayService}
+}
+
+// GetTeamHolidays チームに設定した国の期間内の祝日
+func (h *HolidayHandler) GetTeamHolidays(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	loc, err := parseLocation(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	from, to, err := parseTimeRange(c, loc)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	holidays, err := h.holidayService.ListTeamHolidays(c.Param("id"), userID, from, to)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, holidays)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 添付ファイルから読み込むiCalendarの最大サイズ
+const maxInboundCalendarSize = 1 << 20
+
+type InboundEmailHandler struct {
+	inboundEmailService *services.InboundEmailService
+	secret              string
+}
+
+func NewInboundEmailHandler(inboundEmailService *services.InboundEmailService, secret string) *InboundEmailHandler {
+	return &InboundEmailHandler{inboundEmailService: inboundEmailService, secret: secret}
+}
+
+// ReceiveEmail メール受信Webhook（SendGrid / Mailgun 形式のフォームに対応）
+//
+// rsvp+<token>@ 宛ては招待への出欠回答、reply+<token>@ 宛てはコメントとして処理する。
+func (h *InboundEmailHandler) ReceiveEmail(c *gin.Context) {
+	if h.secret == "" {
+		respondError(c, http.StatusNotFound, "メール受信は無効です")
+		return
+	}
+	provided := c.GetHeader("X-Inbound-Secret")
+	if provided == "" {
+		provided = c.Query("secret")
+	}
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(h.secret)) != 1 {
+		respondError(c, http.StatusUnauthorized, "認証に失敗しました")
+		return
+	}
+
+	email := services.InboundEmail{
+		To:   firstFormValue(c, "recipient", "to"),
+		From: firstFormValue(c, "sender", "from"),
+		Text: firstFormValue(c, "stripped-text", "body-plain", "text"),
+	}
+
+	if email.IsRSVP() {
+		email.Calendars = calendarAttachments(c)
+		attendee, err := h.inboundEmailService.ProcessRSVP(email)
+		if err != nil {
+			respondServiceError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, attendee)
+		return
+	}
+
+	comment, err := h.inboundEmailService.ProcessReply(email)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+func firstFormValue(c *gin.Context, keys ...string) string {
+	for _, key := range keys {
+		if v := c.PostForm(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// calendarAttachments 添付ファイルのうちiCalendar（text/calendar・application/ics・.ics）を読み込む
+func calendarAttachments(c *gin.Context) [][]byte {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil
+	}
+	var calendars [][]byte
+	for _, files := range form.File {
+		for _, fh := range files {
+			mediaType, _, _ := mime.ParseMediaType(fh.Header.Get("Content-Type"))
+			if mediaType != "text/calendar" && mediaType != "application/ics" &&
+				!strings.EqualFold(filepath.Ext(fh.Filename), ".ics") {
+				continue
+			}
+			f, err := fh.Open()
+			if err != nil {
+				continue
+			}
+			data, err := io.ReadAll(io.LimitReader(f, maxInboundCalendarSize))
+			f.Close()
+			if err == nil {
+				calendars = append(calendars, data)
+			}
+		}
+	}
+	return calendars
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/services"
+	"task-calendar-backend/internal/upload"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 復元できるアーカイブの最大サイズ
+const maxBackupSize = 256 << 20
+
+// backupLimits 復元するアーカイブの制限（zipのみ受け付ける）
+var backupLimits = upload.Limits{MaxSize: maxBackupSize, AllowedTypes: []string{"application/zip"}}
+
+type JobHandler struct {
+	jobService *services.JobService
+}
+
+func NewJobHandler(jobService *services.JobService) *JobHandler {
+	return &JobHandler{jobService: jobService}
+}
+
+type eventExportJobRequest struct {
+	TeamID   *string `json:"teamId" binding:"omitempty,notblank"`
+	TimeZone string  `json:"tz" binding:"omitempty,timezone"`
+}
+
+type taskMergeJobRequest struct {
+	TargetTaskID string `json:"targetTaskId" binding:"required,notblank"`
+	SourceTaskID string `json:"sourceTaskId" binding:"required,nefield=TargetTaskID"`
+}
+
+// CreateEventExportJob イベントのiCalendar形式でのエクスポートをジョブとして登録（202。結果はジョブの downloadUrl から取得）
+func (h *JobHandler) CreateEventExportJob(c *gin.Context) {
+	var req eventExportJobRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	h.enqueue(c, models.JobTypeEventExport, services.EventExportJobParams{TeamID: req.TeamID, TimeZone: req.TimeZone}, nil)
+}
+
+// CreateEventImportJob iCalendarファイルからのイベントのインポートをジョブとして登録（202。指定できる内容は ImportICS と同じ）
+func (h *JobHandler) CreateEventImportJob(c *gin.Context) {
+	opts, ok := parseImportOptions(c)
+	if !ok {
+		return
+	}
+	body, ok := importBody(c)
+	if !ok {
+		return
+	}
+
+	params := services.EventImportJobParams{TeamID: opts.TeamID, DryRun: opts.DryRun}
+	if opts.Location != nil {
+		params.TimeZone = opts.Location.String()
+	}
+	h.enqueue(c, models.JobTypeEventImport, params, body)
+}
+
+// CreateTaskMergeJob 重複タスクの統合をジョブとして登録（202。結果は統合後のタスク）
+func (h *JobHandler) CreateTaskMergeJob(c *gin.Context) {
+	var req taskMergeJobRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	h.enqueue(c, models.JobTypeTaskMerge, services.TaskMergeJobParams{TargetTaskID: req.TargetTaskID, SourceTaskID: req.SourceTaskID}, nil)
+}
+
+type backupExportJobRequest struct {
+	TeamID *string `json:"teamId" binding:"omitempty,notblank"`
+}
+
+// CreateBackupJob チーム（teamId。省略時はサーバー全体）のデータのアーカイブの作成をジョブとして登録（運営者。202。結果はジョブの downloadUrl から取得）
+func (h *JobHandler) CreateBackupJob(c *gin.Context) {
+	var req backupExportJobRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	h.enqueue(c, models.JobTypeBackupExport, services.BackupExportJobParams{TeamID: req.TeamID}, nil)
+}
+
+// CreateRestoreJob アーカイブからのデータの復元をジョブとして登録（運営者。202。結果は BackupRestoreReport）
+//
+// multipart/form-data の file、または application/zip の本文を受け付ける。
+func (h *JobHandler) CreateRestoreJob(c *gin.Context) {
+	var file *upload.File
+	var err error
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err = upload.FromRequest(c.Writer, c.Request, "file", backupLimits)
+	} else {
+		file, err = upload.FromBody(c.Writer, c.Request, backupLimits)
+	}
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+	h.enqueue(c, models.JobTypeBackupRestore, struct{}{}, file)
+}
+
+func (h *JobHandler) enqueue(c *gin.Context, jobType models.JobType, params interface{}, input io.Reader) {
+	userID := c.GetString("userID")
+
+	job, err := h.jobService.Enqueue(userID, jobType, params, input)
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetJob ジョブの状態・進み具合と結果（結果がファイルの場合は署名付きの downloadUrl）
+func (h *JobHandler) GetJob(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	job, err := h.jobService.GetJob(c.Param("id"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// DownloadResult ジョブの結果のファイル（署名付きURLで認可するため認証不要）
+func (h *JobHandler) DownloadResult(c *gin.Context) {
+	job, rc, err := h.jobService.OpenResult(c.Param("id"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	defer rc.Close()
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", job.ResultName))
+	c.Header("Content-Type", job.ResultContentType)
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, rc)
+}
+
+// GetJobs すべてのジョブの一覧（管理者。status・type で絞り込む。滞留や失敗したジョブの確認に使う）
+func (h *JobHandler) GetJobs(c *gin.Context) {
+	pageOpts, err := parsePageOptions(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if pageOpts.Sort, err = parseSort(c, services.CursorSortFields); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := h.jobService.ListJobs(services.JobListOptions{
+		PageOptions: pageOpts,
+		Status:      models.JobStatus(c.Query("status")),
+		Type:        models.JobType(c.Query("type")),
+	})
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondListPage(c, page, page.Jobs, listPage{NextCursor: page.NextCursor, Total: page.Total})
+}
+
+// RetryJob 失敗したジョブをもう1回実行する（管理者）
+func (h *JobHandler) RetryJob(c *gin.Context) {
+	job, err := h.jobService.RetryJob(c.Param("id"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, job)
+}
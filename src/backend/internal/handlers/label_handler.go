@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LabelHandler struct {
+	labelService *services.LabelService
+}
+
+func NewLabelHandler(labelService *services.LabelService) *LabelHandler {
+	return &LabelHandler{labelService: labelService}
+}
+
+// GetLabels チームのラベル一覧取得
+func (h *LabelHandler) GetLabels(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	sort, err := parseSort(c, services.LabelSortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	labels, err := h.labelService.ListLabels(c.Param("id"), userID, sort)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, labels)
+}
+
+// CreateLabel ラベルの登録
+func (h *LabelHandler) CreateLabel(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.LabelInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	label, err := h.labelService.CreateLabel(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, label)
+}
+
+// UpdateLabel ラベルの更新
+func (h *LabelHandler) UpdateLabel(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.LabelInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	label, err := h.labelService.UpdateLabel(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, label)
+}
+
+// DeleteLabel ラベルの削除
+func (h *LabelHandler) DeleteLabel(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.labelService.DeleteLabel(c.Param("id"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "ラベルを削除しました"})
+}
+
+// SetEventLabels イベントのタグの設定
+func (h *LabelHandler) SetEventLabels(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.EventLabelsInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	event, err := h.labelService.SetEventLabels(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+// SetTaskLabels タスクのタグの設定
+func (h *LabelHandler) SetTaskLabels(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.TaskLabelsInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	task, err := h.labelService.SetTaskLabels(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
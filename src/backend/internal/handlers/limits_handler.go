@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LimitsHandler APIの利用者が自分でリクエストの間隔を調整するための、制限の状況
+type LimitsHandler struct {
+	limiter *ratelimit.Limiter
+}
+
+func NewLimitsHandler(limiter *ratelimit.Limiter) *LimitsHandler {
+	return &LimitsHandler{limiter: limiter}
+}
+
+// GetLimits 自分のリクエスト数の上限と、現在のウィンドウの残り（制限が無効の場合 rateLimit は null）
+func (h *LimitsHandler) GetLimits(c *gin.Context) {
+	var quota *ratelimit.Quota
+	if h.limiter.Enabled() {
+		q := h.limiter.Peek(ratelimit.Key(c.GetString("userID"), c.ClientIP()))
+		quota = &q
+	}
+	c.JSON(http.StatusOK, gin.H{"rateLimit": quota})
+}
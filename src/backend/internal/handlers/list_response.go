@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 一覧の応答の形
+//
+// v1 は一覧をそのまま（配列や、CommentPage などのページングの形で）返す。v2 はすべての一覧を data（要素の配列）と
+// meta（件数・ページング・リクエストID）に分けて返し、クライアントがエンドポイントごとに応答の形を調べなくてよいようにする。
+
+// listResponse v2 の一覧の応答
+type listResponse struct {
+	Data interface{} `json:"data"`
+	Meta listMeta    `json:"meta"`
+}
+
+// listMeta 一覧の件数・ページングの情報
+type listMeta struct {
+	RequestID  string  `json:"requestId"`
+	Count      int     `json:"count"`           // data の件数
+	Total      *int64  `json:"total,omitempty"` // 条件に合う全件数（includeTotal=true の場合のみ）
+	NextCursor *string `json:"nextCursor"`      // 次のページの cursor（最後のページとページングしない一覧は null）
+	HasMore    bool    `json:"hasMore"`
+	// UnreadCount 未読の件数（通知の一覧のみ）
+	UnreadCount *int64 `json:"unreadCount,omitempty"`
+}
+
+// listPage カーソルページングの一覧の、要素以外の情報
+type listPage struct {
+	NextCursor  *string
+	Total       *int64
+	UnreadCount *int64
+}
+
+// usesListEnvelope 一覧を data・meta の形で返すか（v2 以降）
+func usesListEnvelope(c *gin.Context) bool {
+	return c.GetString("apiVersion") == "v2"
+}
+
+// includeTotal includeTotal クエリで全件数を求められているか
+func includeTotal(c *gin.Context) bool {
+	v, _ := strconv.ParseBool(c.Query("includeTotal"))
+	return v
+}
+
+// respondList ページングしない一覧を返す（v1 は配列のまま、v2 は data・meta の形）
+func respondList[T any](c *gin.Context, items []T) {
+	respondListPage(c, items, items, listPage{})
+}
+
+// respondListPage 一覧を返す（v1 は v1Body をそのまま、v2 は items を data にして page の情報を meta に入れる）
+//
+// ページングしない一覧では、includeTotal を指定された場合の全件数は items の件数になる。
+func respondListPage[T any](c *gin.Context, v1Body interface{}, items []T, page listPage) {
+	if !usesListEnvelope(c) {
+		c.JSON(http.StatusOK, v1Body)
+		return
+	}
+	if items == nil {
+		items = []T{}
+	}
+	meta := listMeta{
+		RequestID:   c.GetString("requestID"),
+		Count:       len(items),
+		Total:       page.Total,
+		NextCursor:  page.NextCursor,
+		HasMore:     page.NextCursor != nil,
+		UnreadCount: page.UnreadCount,
+	}
+	if meta.Total == nil && page.NextCursor == nil && includeTotal(c) {
+		total := int64(len(items))
+		meta.Total = &total
+	}
+	c.JSON(http.StatusOK, listResponse{Data: items, Meta: meta})
+}
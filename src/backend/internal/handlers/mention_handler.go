@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MentionHandler struct {
+	mentionBroadcastService *services.MentionBroadcastService
+}
+
+func NewMentionHandler(mentionBroadcastService *services.MentionBroadcastService) *MentionHandler {
+	return &MentionHandler{mentionBroadcastService: mentionBroadcastService}
+}
+
+// GetTeamSettings チームのメンションの設定（@team・@here を使えるメンバー）
+func (h *MentionHandler) GetTeamSettings(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	settings, err := h.mentionBroadcastService.GetSettings(c.Param("id"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateTeamSettings @team・@here を使えるメンバーの変更（MEMBERS / ADMINS / DISABLED）
+func (h *MentionHandler) UpdateTeamSettings(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.MentionSettingsInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	settings, err := h.mentionBroadcastService.UpdateSettings(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
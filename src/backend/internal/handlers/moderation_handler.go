@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ModerationHandler struct {
+	moderationService *services.ModerationService
+}
+
+func NewModerationHandler(moderationService *services.ModerationService) *ModerationHandler {
+	return &ModerationHandler{moderationService: moderationService}
+}
+
+type reportCommentRequest struct {
+	Reason string `json:"reason" binding:"required,notblank,max=1000"`
+}
+
+type resolveReportRequest struct {
+	Action models.ModerationAction `json:"action" binding:"required,oneof=HIDE DELETE WARN DISMISS"`
+	Note   string                  `json:"note" binding:"max=1000"`
+}
+
+// ReportComment コメントの通報
+func (h *ModerationHandler) ReportComment(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req reportCommentRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	report, err := h.moderationService.ReportComment(c.Param("id"), c.Param("commentId"), userID, req.Reason)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, report)
+}
+
+// GetReports モデレーションキュー取得（管理者）
+func (h *ModerationHandler) GetReports(c *gin.Context) {
+	sort, err := parseSort(c, services.ReportSortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	include, err := parseInclude(c, services.ReportIncludes)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	reports, err := h.moderationService.ListReports(c.GetString("userID"), models.ReportStatus(c.Query("status")), sort, include)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, reports)
+}
+
+// ResolveReport 通報への対応（管理者）
+func (h *ModerationHandler) ResolveReport(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req resolveReportRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	report, err := h.moderationService.ResolveReport(c.Param("id"), userID, req.Action, req.Note)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
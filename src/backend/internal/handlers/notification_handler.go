@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+	watermarks          *services.ChangeWatermarkService
+}
+
+func NewNotificationHandler(notificationService *services.NotificationService, watermarks *services.ChangeWatermarkService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService, watermarks: watermarks}
+}
+
+// notModified 通知が If-Modified-Since 以降に変更されていなければ304を返す（エラーを含め応答した場合は true）
+func (h *NotificationHandler) notModified(c *gin.Context, userID string) bool {
+	changedAt, err := h.watermarks.NotificationsChangedAt(userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return true
+	}
+	return notModified(c, changedAt)
+}
+
+// GetNotifications 通知一覧取得（unread=true で未読のみ。If-Modified-Since 以降に変更がなければ304）
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	pageOpts, err := parsePageOptions(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if pageOpts.Sort, err = parseSort(c, services.CursorSortFields); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if h.notModified(c, userID) {
+		return
+	}
+	page, err := h.notificationService.ListNotifications(userID, services.NotificationListOptions{
+		PageOptions: pageOpts,
+		UnreadOnly:  c.Query("unread") == "true",
+	})
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondListPage(c, page, page.Notifications, listPage{NextCursor: page.NextCursor, Total: page.Total, UnreadCount: &page.UnreadCount})
+}
+
+// GetNotificationGroups タスク・イベントと種別ごとにまとめた通知一覧（unread=true で未読のみ。If-Modified-Since 以降に変更がなければ304）
+func (h *NotificationHandler) GetNotificationGroups(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	pageOpts, err := parsePageOptions(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if h.notModified(c, userID) {
+		return
+	}
+	page, err := h.notificationService.ListNotificationGroups(userID, services.NotificationListOptions{
+		PageOptions: pageOpts,
+		UnreadOnly:  c.Query("unread") == "true",
+	})
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondListPage(c, page, page.Groups, listPage{NextCursor: page.NextCursor, Total: page.Total, UnreadCount: &page.UnreadCount})
+}
+
+// MarkRead 通知を既読にする
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	notification, err := h.notificationService.MarkRead(c.Param("id"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, notification)
+}
+
+// MarkAllRead 通知をすべて既読にする
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	count, err := h.notificationService.MarkAllRead(userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"updated": count})
+}
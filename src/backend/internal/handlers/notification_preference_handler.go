@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationPreferenceHandler struct {
+	notificationRouter *services.NotificationRouter
+}
+
+func NewNotificationPreferenceHandler(notificationRouter *services.NotificationRouter) *NotificationPreferenceHandler {
+	return &NotificationPreferenceHandler{notificationRouter: notificationRouter}
+}
+
+// GetMyPreferences 自分の通知種別・チャネルごとの配信設定
+func (h *NotificationPreferenceHandler) GetMyPreferences(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	settings, err := h.notificationRouter.GetUserPreferences(userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateMyPreferences 自分の配信設定を変更（enabled を null にすると既定に戻す）
+func (h *NotificationPreferenceHandler) UpdateMyPreferences(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.NotificationPreferencesInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	settings, err := h.notificationRouter.UpdateUserPreferences(userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// GetTeamPreferences チームのタスク・イベントに関する通知の既定の配信設定
+func (h *NotificationPreferenceHandler) GetTeamPreferences(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	settings, err := h.notificationRouter.GetTeamPreferences(c.Param("id"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateTeamPreferences チームの既定の配信設定を変更（チームの管理者のみ）
+func (h *NotificationPreferenceHandler) UpdateTeamPreferences(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.NotificationPreferencesInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	settings, err := h.notificationRouter.UpdateTeamPreferences(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
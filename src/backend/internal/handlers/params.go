@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePageOptions 一覧のカーソルページングの指定（cursor・limit・includeTotal）
+func parsePageOptions(c *gin.Context) (services.PageOptions, error) {
+	opts := services.PageOptions{Cursor: c.Query("cursor"), IncludeTotal: includeTotal(c)}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return opts, fmt.Errorf("limitは正の整数で指定してください")
+		}
+		opts.Limit = limit
+	}
+	return opts, nil
+}
+
+// parseSort sort クエリ（カンマ区切り、先頭の - で降順）を一覧で使える項目の並び順にする（sort=-createdAt,name など）
+func parseSort(c *gin.Context, fields services.SortableFields) (services.SortOptions, error) {
+	var order services.SortOptions
+	seen := map[string]bool{}
+	for _, key := range parseListParam(c, "sort") {
+		desc := strings.HasPrefix(key, "-")
+		key = strings.TrimLeft(key, "+-")
+		column, ok := fields[key]
+		if !ok {
+			names := make([]string, 0, len(fields))
+			for name := range fields {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("sortに指定できる項目は%sです", strings.Join(names, "・"))
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("sortに同じ項目（%s）を複数回指定できません", key)
+		}
+		seen[key] = true
+		order = append(order, services.SortField{Column: column, Desc: desc})
+	}
+	return order, nil
+}
+
+// parseInclude include クエリ（カンマ区切り）を一覧で読み込む関連にする（include=creator,assignee など）
+func parseInclude(c *gin.Context, relations services.IncludableRelations) (services.IncludeOptions, error) {
+	var include services.IncludeOptions
+	for _, name := range parseListParam(c, "include") {
+		preloads, ok := relations[name]
+		if !ok {
+			names := make([]string, 0, len(relations))
+			for name := range relations {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("includeに指定できる関連は%sです", strings.Join(names, "・"))
+		}
+		include = append(include, preloads...)
+	}
+	return include, nil
+}
+
+// parseLocation tz クエリパラメータ（IANAタイムゾーン名）を解析する（未指定はUTC）
+func parseLocation(c *gin.Context) (*time.Location, error) {
+	loc, err := models.LoadLocation(c.Query("tz"))
+	if err != nil {
+		return nil, fmt.Errorf("tzが不正です")
+	}
+	return loc, nil
+}
+
+// parseTimeParam RFC3339 または YYYY-MM-DD 形式のクエリパラメータを解析する（日付のみの場合は loc の0時）
+func parseTimeParam(c *gin.Context, key string, loc *time.Location) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("%sは必須です", key)
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.In(loc), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%sの形式が不正です（RFC3339またはYYYY-MM-DD）", key)
+}
+
+// parseOccurrenceFilter イベントの絞り込み条件（categoryIds・types・labelIds・creatorIds・attendeeIds、いずれもカンマ区切り）
+func parseOccurrenceFilter(c *gin.Context) services.OccurrenceFilter {
+	filter := services.OccurrenceFilter{
+		CategoryIDs: parseListParam(c, "categoryIds"),
+		LabelIDs:    parseListParam(c, "labelIds"),
+		CreatorIDs:  parseListParam(c, "creatorIds"),
+		AttendeeIDs: parseListParam(c, "attendeeIds"),
+	}
+	for _, t := range parseListParam(c, "types") {
+		filter.Types = append(filter.Types, models.EventType(strings.ToUpper(t)))
+	}
+	return filter
+}
+
+// parseListParam カンマ区切りのクエリパラメータ（空の要素は除く）
+func parseListParam(c *gin.Context, key string) []string {
+	var values []string
+	for _, v := range strings.Split(c.Query(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// parseOptionalTimeParam parseTimeParam と同じ形式の任意のクエリパラメータ（未指定は nil）
+func parseOptionalTimeParam(c *gin.Context, key string, loc *time.Location) (*time.Time, error) {
+	if c.Query(key) == "" {
+		return nil, nil
+	}
+	t, err := parseTimeParam(c, key, loc)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// parseRecurrenceID パスパラメータの recurrenceId（RFC3339 または 20060102T150405Z）を解析する
+func parseRecurrenceID(c *gin.Context) (time.Time, error) {
+	value := c.Param("recurrenceId")
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("recurrenceIdの形式が不正です")
+}
+
+// parseTimeRange from / to クエリパラメータを解析する（tz 指定時はそのタイムゾーンの日時として扱う）
+func parseTimeRange(c *gin.Context, loc *time.Location) (time.Time, time.Time, error) {
+	from, err := parseTimeParam(c, "from", loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := parseTimeParam(c, "to", loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from, to, nil
+}
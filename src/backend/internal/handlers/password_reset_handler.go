@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordResetHandler struct {
+	passwordResetService *services.PasswordResetService
+}
+
+func NewPasswordResetHandler(passwordResetService *services.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{passwordResetService: passwordResetService}
+}
+
+type passwordResetRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type passwordResetConfirmRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// RequestReset パスワード再設定メールの送信（登録の有無にかかわらず同じ応答を返す）
+func (h *PasswordResetHandler) RequestReset(c *gin.Context) {
+	var req passwordResetRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.passwordResetService.RequestReset(req.Email); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "登録されているメールアドレスの場合、パスワード再設定のメールを送信しました"})
+}
+
+// ResetPassword 再設定メールのトークンで新しいパスワードを設定
+func (h *PasswordResetHandler) ResetPassword(c *gin.Context) {
+	var req passwordResetConfirmRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.passwordResetService.ResetPassword(req.Token, req.Password); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "パスワードを再設定しました"})
+}
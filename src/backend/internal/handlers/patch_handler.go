@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PatchHandler JSON Merge Patch（application/merge-patch+json）による部分更新
+type PatchHandler struct {
+	patchService *services.PatchService
+}
+
+func NewPatchHandler(patchService *services.PatchService) *PatchHandler {
+	return &PatchHandler{patchService: patchService}
+}
+
+// PatchTask タスクの部分更新（dueDate・assigneeId は null で解除）
+func (h *PatchHandler) PatchTask(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.MergePatch
+	if !bindMergePatch(c, &req) {
+		return
+	}
+
+	task, err := h.patchService.PatchTask(c.Param("id"), userID, req, c.GetInt("ifMatchVersion"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// PatchEvent イベントの部分更新（タイトル・説明・場所・色・カテゴリー）
+func (h *PatchHandler) PatchEvent(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.MergePatch
+	if !bindMergePatch(c, &req) {
+		return
+	}
+
+	event, err := h.patchService.PatchEvent(c.Param("id"), userID, req, c.GetInt("ifMatchVersion"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+// PatchTeam チームの部分更新
+func (h *PatchHandler) PatchTeam(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.MergePatch
+	if !bindMergePatch(c, &req) {
+		return
+	}
+
+	team, err := h.patchService.PatchTeam(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, team)
+}
+
+// PatchProfile 自分のプロフィールの部分更新
+func (h *PatchHandler) PatchProfile(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.MergePatch
+	if !bindMergePatch(c, &req) {
+		return
+	}
+
+	user, err := h.patchService.PatchUser(userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// bindMergePatch 本文をマージパッチとして読み込む（application/merge-patch+json のほか application/json も受け付ける）
+func bindMergePatch(c *gin.Context, patch *services.MergePatch) bool {
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil || (mediaType != "application/merge-patch+json" && mediaType != "application/json") {
+		respondError(c, http.StatusUnsupportedMediaType, "Content-Typeはapplication/merge-patch+jsonで指定してください")
+		return false
+	}
+	if err := c.ShouldBindJSON(patch); err != nil {
+		respondError(c, http.StatusBadRequest, "本文はJSONのオブジェクトで指定してください")
+		return false
+	}
+	return true
+}
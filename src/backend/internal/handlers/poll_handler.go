@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PollHandler struct {
+	pollService *services.PollService
+}
+
+func NewPollHandler(pollService *services.PollService) *PollHandler {
+	return &PollHandler{pollService: pollService}
+}
+
+// GetPolls 日程調整の一覧取得
+func (h *PollHandler) GetPolls(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	sort, err := parseSort(c, services.PollSortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	include, err := parseInclude(c, services.PollIncludes)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	polls, err := h.pollService.ListPolls(userID, sort, include)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, polls)
+}
+
+// CreatePoll 日程調整の作成
+func (h *PollHandler) CreatePoll(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.PollInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	poll, err := h.pollService.CreatePoll(userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, poll)
+}
+
+// GetPoll 日程調整の取得
+func (h *PollHandler) GetPoll(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	poll, err := h.pollService.GetPoll(c.Param("id"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, poll)
+}
+
+// Vote 日程調整への投票
+func (h *PollHandler) Vote(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.PollVoteInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	poll, err := h.pollService.Vote(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, poll)
+}
+
+// ConfirmPoll 日程調整の確定（イベントを作成する）
+func (h *PollHandler) ConfirmPoll(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.PollConfirmInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.pollService.ConfirmPoll(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// DeletePoll 日程調整の削除
+func (h *PollHandler) DeletePoll(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.pollService.DeletePoll(c.Param("id"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "日程調整を削除しました"})
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteTimeout = 10 * time.Second
+	// クライアントからの応答（pong）を待つ時間。pingはその9割の間隔で送る
+	wsPongTimeout    = 60 * time.Second
+	wsPingInterval   = wsPongTimeout * 9 / 10
+	wsMaxMessageSize = 4096
+	// Server-Sent Eventsで、プロキシに接続を切られないよう送るコメント行の間隔
+	sseHeartbeatInterval = 30 * time.Second
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// 認証はトークンで行い、Cookieは使わないため接続元のOriginは制限しない
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+type RealtimeHandler struct {
+	realtimeService *services.RealtimeService
+}
+
+func NewRealtimeHandler(realtimeService *services.RealtimeService) *RealtimeHandler {
+	return &RealtimeHandler{realtimeService: realtimeService}
+}
+
+// realtimeClientMessage クライアントから送られるメッセージ（チームの購読の開始・終了、閲覧中の画面の設定）
+type realtimeClientMessage struct {
+	Action string `json:"action"` // subscribe / unsubscribe / view / unview / typing / stop_typing
+	TeamID string `json:"teamId"`
+	TaskID string `json:"taskId"` // view でタスクを閲覧中にする場合と、typing / stop_typing の対象
+}
+
+// realtimeReply クライアントからのメッセージへの応答
+type realtimeReply struct {
+	Type   string `json:"type"` // subscribed / unsubscribed / viewing / unviewed / error
+	TeamID string `json:"teamId,omitempty"`
+	TaskID string `json:"taskId,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Connect WebSocketでタスク・イベント・コメントの変更通知を受け取る
+//
+// 自分が作成・参加するイベントの変更は接続しただけで届く。チームの変更は teams クエリ（カンマ区切り）か、
+// {"action":"subscribe","teamId":"..."} を送って購読する。
+// {"action":"view","taskId":"..."}（チームのボードは teamId）を送ると、閲覧中のユーザーとして他のメンバーに表示される。
+// コメントの入力中は {"action":"typing","taskId":"..."} を送ると、タスクを閲覧中のユーザーに comment.typing が届く。
+func (h *RealtimeHandler) Connect(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// 失敗した場合の応答はUpgradeが返している
+		return
+	}
+	defer conn.Close()
+
+	sub := h.realtimeService.Subscribe(userID)
+	defer h.realtimeService.Unsubscribe(sub)
+
+	replies := make(chan realtimeReply, 16)
+	done := make(chan struct{})
+	go h.writeLoop(conn, sub, replies, done)
+	defer close(done)
+
+	for _, teamID := range strings.Split(c.Query("teams"), ",") {
+		if teamID = strings.TrimSpace(teamID); teamID != "" {
+			select {
+			case replies <- h.handleMessage(sub, realtimeClientMessage{Action: "subscribe", TeamID: teamID}):
+			default:
+				return
+			}
+		}
+	}
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+	for {
+		var msg realtimeClientMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("WebSocketの受信に失敗しました: %v", err)
+			}
+			return
+		}
+		var reply realtimeReply
+		if msg.Action == "typing" || msg.Action == "stop_typing" {
+			// 入力中の通知は頻繁に送られるため、成功しても応答しない
+			if h.handleTyping(sub, msg) {
+				continue
+			}
+			reply = realtimeReply{Type: "error", TaskID: msg.TaskID, Error: "入力中の通知を送れません"}
+		} else {
+			reply = h.handleMessage(sub, msg)
+		}
+		select {
+		case replies <- reply:
+		default:
+			// 応答を読まずに送り続けるクライアントは切断する
+			return
+		}
+	}
+}
+
+func (h *RealtimeHandler) handleMessage(sub *services.RealtimeSubscriber, msg realtimeClientMessage) realtimeReply {
+	switch msg.Action {
+	case "view":
+		return h.handleView(sub, msg)
+	case "unview":
+		h.realtimeService.Unview(sub)
+		return realtimeReply{Type: "unviewed"}
+	}
+	if msg.TeamID == "" {
+		return realtimeReply{Type: "error", Error: "teamIdは必須です"}
+	}
+	switch msg.Action {
+	case "subscribe":
+		if err := h.realtimeService.JoinTeam(sub, msg.TeamID); err != nil {
+			if !errors.Is(err, services.ErrForbidden) {
+				log.Printf("チーム %s の購読に失敗しました: %v", msg.TeamID, err)
+			}
+			return realtimeReply{Type: "error", TeamID: msg.TeamID, Error: "チームの変更を購読できません"}
+		}
+		return realtimeReply{Type: "subscribed", TeamID: msg.TeamID}
+	case "unsubscribe":
+		h.realtimeService.LeaveTeam(sub, msg.TeamID)
+		return realtimeReply{Type: "unsubscribed", TeamID: msg.TeamID}
+	}
+	return realtimeReply{Type: "error", Error: "actionはsubscribe・unsubscribe・view・unview・typing・stop_typingのいずれかを指定してください"}
+}
+
+// handleView 閲覧中のタスク（taskId）またはチームのボード（teamId）を設定する
+func (h *RealtimeHandler) handleView(sub *services.RealtimeSubscriber, msg realtimeClientMessage) realtimeReply {
+	kind, id := services.PresenceTask, msg.TaskID
+	if id == "" {
+		kind, id = services.PresenceTeam, msg.TeamID
+	}
+	if id == "" {
+		return realtimeReply{Type: "error", Error: "taskIdかteamIdを指定してください"}
+	}
+	if err := h.realtimeService.View(sub, kind, id); err != nil {
+		if !errors.Is(err, services.ErrForbidden) && !errors.Is(err, services.ErrNotFound) {
+			log.Printf("閲覧中の画面の設定に失敗しました: %v", err)
+		}
+		return realtimeReply{Type: "error", TeamID: msg.TeamID, TaskID: msg.TaskID, Error: "閲覧中の画面を設定できません"}
+	}
+	return realtimeReply{Type: "viewing", TeamID: msg.TeamID, TaskID: msg.TaskID}
+}
+
+// handleTyping タスクのコメントを入力中であること（stop_typing は入力をやめたこと）を、タスクを閲覧中のユーザーに知らせる
+func (h *RealtimeHandler) handleTyping(sub *services.RealtimeSubscriber, msg realtimeClientMessage) bool {
+	if msg.TaskID == "" {
+		return false
+	}
+	if err := h.realtimeService.Typing(sub, msg.TaskID, msg.Action == "typing"); err != nil {
+		if !errors.Is(err, services.ErrForbidden) && !errors.Is(err, services.ErrNotFound) {
+			log.Printf("入力中の通知に失敗しました: %v", err)
+		}
+		return false
+	}
+	return true
+}
+
+// GetTaskViewers タスクを閲覧中のユーザー
+func (h *RealtimeHandler) GetTaskViewers(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	presence, err := h.realtimeService.TaskViewers(c.Param("id"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, presence)
+}
+
+// GetTeamPresence チームの接続中のユーザーと、ボードを閲覧中のユーザー
+func (h *RealtimeHandler) GetTeamPresence(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	presence, err := h.realtimeService.TeamPresence(c.Param("id"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, presence)
+}
+
+// writeLoop 変更通知・応答・pingを送る（WebSocketへの書き込みはこのゴルーチンのみで行う）
+func (h *RealtimeHandler) writeLoop(conn *websocket.Conn, sub *services.RealtimeSubscriber, replies <-chan realtimeReply, done <-chan struct{}) {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+	// 書き込めなくなった場合は接続を閉じて受信側も終わらせる
+	defer conn.Close()
+
+	for {
+		var payload interface{}
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				// 通知の受け取りが追いつかず購読が終わった（クライアントは再接続して取得し直す）
+				conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too slow"), time.Now().Add(wsWriteTimeout))
+				return
+			}
+			payload = event
+		case reply := <-replies:
+			payload = reply
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+			continue
+		case <-h.realtimeService.ShuttingDown():
+			// サーバーの停止（クライアントは別のサーバーに再接続する）
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"), time.Now().Add(wsWriteTimeout))
+			return
+		case <-done:
+			return
+		}
+		conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		if err := conn.WriteJSON(payload); err != nil {
+			return
+		}
+	}
+}
+
+// Stream Server-Sent Eventsで変更通知を受け取る（WebSocketを使えない環境向け、Connectと同じ形式のメッセージ）
+//
+// 購読するチームは teams クエリ（カンマ区切り）で指定する。購読できないチームがある場合は接続せずにエラーを返す。
+func (h *RealtimeHandler) Stream(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	sub := h.realtimeService.Subscribe(userID)
+	defer h.realtimeService.Unsubscribe(sub)
+
+	var teamIDs []string
+	for _, teamID := range strings.Split(c.Query("teams"), ",") {
+		if teamID = strings.TrimSpace(teamID); teamID == "" {
+			continue
+		}
+		if err := h.realtimeService.JoinTeam(sub, teamID); err != nil {
+			respondServiceError(c, err)
+			return
+		}
+		teamIDs = append(teamIDs, teamID)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// nginxなどのプロキシにバッファリングさせない
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// 切断されたらすぐに再接続させる
+	io.WriteString(c.Writer, "retry: 3000\n\n")
+	for _, teamID := range teamIDs {
+		writeServerSentEvent(c.Writer, realtimeReply{Type: "subscribed", TeamID: teamID})
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(sseHeartbeatInterval)
+	defer ticker.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				writeServerSentEvent(w, realtimeReply{Type: "error", Error: "通知の受け取りが追いつかないため切断しました"})
+				return false
+			}
+			return writeServerSentEvent(w, event) == nil
+		case <-ticker.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		case <-h.realtimeService.ShuttingDown():
+			// サーバーの停止（クライアントは retry の間隔の後に再接続する）
+			return false
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// writeServerSentEvent メッセージをJSONにしてSSEのdata行として書き出す
+func writeServerSentEvent(w io.Writer, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+	return err
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type addReminderRequest struct {
+	MinutesBefore *int `json:"minutesBefore" binding:"required,min=0,max=40320"`
+}
+
+type ReminderHandler struct {
+	reminderService *services.ReminderService
+}
+
+func NewReminderHandler(reminderService *services.ReminderService) *ReminderHandler {
+	return &ReminderHandler{reminderService: reminderService}
+}
+
+// GetReminders イベントに設定した自分のリマインダー一覧取得
+func (h *ReminderHandler) GetReminders(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	sort, err := parseSort(c, services.ReminderSortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	reminders, err := h.reminderService.ListReminders(c.Param("id"), userID, sort)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, reminders)
+}
+
+// AddReminder リマインダー追加
+func (h *ReminderHandler) AddReminder(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req addReminderRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	reminder, err := h.reminderService.AddReminder(c.Param("id"), userID, *req.MinutesBefore)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, reminder)
+}
+
+// DeleteReminder リマインダー削除
+func (h *ReminderHandler) DeleteReminder(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.reminderService.DeleteReminder(c.Param("id"), c.Param("reminderId"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "リマインダーを削除しました"})
+}
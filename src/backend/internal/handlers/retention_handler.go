@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RetentionHandler struct {
+	retentionService *services.RetentionService
+}
+
+func NewRetentionHandler(retentionService *services.RetentionService) *RetentionHandler {
+	return &RetentionHandler{retentionService: retentionService}
+}
+
+// GetRetention 保存期間のルールと、今実行した場合の対象の件数（運営者。何も変更しない）
+func (h *RetentionHandler) GetRetention(c *gin.Context) {
+	report, err := h.retentionService.Run(true)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// RunRetention 保存期間のルールを今すぐ実行する（運営者。dryRun=true の場合は対象の件数を返すだけにする）
+func (h *RetentionHandler) RunRetention(c *gin.Context) {
+	dryRun := false
+	if v := c.Query("dryRun"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "dryRunが不正です")
+			return
+		}
+		dryRun = b
+	}
+	report, err := h.retentionService.Run(dryRun)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
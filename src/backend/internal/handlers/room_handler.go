@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RoomHandler struct {
+	roomService *services.RoomService
+}
+
+func NewRoomHandler(roomService *services.RoomService) *RoomHandler {
+	return &RoomHandler{roomService: roomService}
+}
+
+// GetRooms チームの会議室一覧取得
+func (h *RoomHandler) GetRooms(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	minCapacity := 0
+	if v := c.Query("minCapacity"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			respondError(c, http.StatusBadRequest, "minCapacityが不正です")
+			return
+		}
+		minCapacity = n
+	}
+
+	sort, err := parseSort(c, services.RoomSortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	rooms, err := h.roomService.ListRooms(c.Param("id"), userID, minCapacity, sort)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, rooms)
+}
+
+// CreateRoom 会議室の登録
+func (h *RoomHandler) CreateRoom(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.RoomInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	room, err := h.roomService.CreateRoom(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, room)
+}
+
+// UpdateRoom 会議室の更新
+func (h *RoomHandler) UpdateRoom(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.RoomInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	room, err := h.roomService.UpdateRoom(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, room)
+}
+
+// DeleteRoom 会議室の削除
+func (h *RoomHandler) DeleteRoom(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.roomService.DeleteRoom(c.Param("id"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "会議室を削除しました"})
+}
+
+// GetRoomBookings 会議室の予約状況取得
+func (h *RoomHandler) GetRoomBookings(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	loc, err := parseLocation(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	from, to, err := parseTimeRange(c, loc)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bookings, err := h.roomService.GetRoomBookings(c.Param("id"), userID, from, to)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, services.LocalizeOccurrences(bookings, loc))
+}
+
+// SetEventLocation イベントの場所・会議室の設定
+func (h *RoomHandler) SetEventLocation(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.EventLocationInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	event, err := h.roomService.SetEventLocation(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SeedHandler デモデータの投入（ENVIRONMENT=development の場合のみルートを登録する）
+type SeedHandler struct {
+	seedService *services.SeedService
+}
+
+func NewSeedHandler(seedService *services.SeedService) *SeedHandler {
+	return &SeedHandler{seedService: seedService}
+}
+
+// Seed デモのユーザー・チーム・タスク・繰り返しイベントを投入する（投入済みの場合は409）
+//
+// 応答の users のメールアドレスとパスワードでログインできる。
+func (h *SeedHandler) Seed(c *gin.Context) {
+	result, err := h.seedService.Seed()
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SharedCalendarHandler struct {
+	sharedCalendarService *services.SharedCalendarService
+}
+
+func NewSharedCalendarHandler(sharedCalendarService *services.SharedCalendarService) *SharedCalendarHandler {
+	return &SharedCalendarHandler{sharedCalendarService: sharedCalendarService}
+}
+
+// GetLinks 自分が作成した共有リンクの一覧
+func (h *SharedCalendarHandler) GetLinks(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	sort, err := parseSort(c, services.SharedLinkSortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	include, err := parseInclude(c, services.SharedLinkIncludes)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	links, err := h.sharedCalendarService.ListLinks(userID, sort, include)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, links)
+}
+
+// CreateLink 読み取り専用の共有リンクの作成
+func (h *SharedCalendarHandler) CreateLink(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.CreateSharedLinkInput
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &req) {
+			return
+		}
+	}
+
+	link, err := h.sharedCalendarService.CreateLink(userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, link)
+}
+
+// RevokeLink 共有リンクの無効化
+func (h *SharedCalendarHandler) RevokeLink(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.sharedCalendarService.RevokeLink(c.Param("id"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "共有リンクを無効にしました"})
+}
+
+// GetSharedCalendar 共有リンクで公開しているカレンダー（認証不要、トークンで認可）
+func (h *SharedCalendarHandler) GetSharedCalendar(c *gin.Context) {
+	loc, err := parseLocation(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	from, to, err := parseTimeRange(c, loc)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	calendar, err := h.sharedCalendarService.GetSharedCalendar(c.Param("token"), from, to)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, services.LocalizeSharedCalendar(calendar, loc))
+}
+
+// ExportSharedICS 共有リンクで公開しているカレンダーのiCalendar（認証不要、トークンで認可）
+func (h *SharedCalendarHandler) ExportSharedICS(c *gin.Context) {
+	loc, err := parseLocation(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	data, err := h.sharedCalendarService.ExportSharedICS(c.Param("token"), loc)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", data)
+}
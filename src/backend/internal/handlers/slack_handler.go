@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// スラッシュコマンドのリクエストの最大サイズ
+const maxSlackRequestSize = 64 << 10
+
+type SlackHandler struct {
+	slackCommandService *services.SlackCommandService
+}
+
+// NewSlackHandler slackCommandService がnilの場合、Slack連携は無効
+func NewSlackHandler(slackCommandService *services.SlackCommandService) *SlackHandler {
+	return &SlackHandler{slackCommandService: slackCommandService}
+}
+
+// HandleCommand Slackのスラッシュコマンド（署名で認証）
+//
+// Slackは200以外の応答を利用者に見せないため、利用者に伝えるエラーは応答の本文で返す。
+func (h *SlackHandler) HandleCommand(c *gin.Context) {
+	if h.slackCommandService == nil {
+		respondError(c, http.StatusNotFound, "Slack連携は無効です")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSlackRequestSize))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.slackCommandService.VerifyRequest(c.GetHeader("X-Slack-Request-Timestamp"),
+		c.GetHeader("X-Slack-Signature"), body); err != nil {
+		respondError(c, http.StatusUnauthorized, "認証に失敗しました")
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.slackCommandService.HandleCommand(c.Request.Context(), services.SlackCommand{
+		Command:   form.Get("command"),
+		Text:      form.Get("text"),
+		UserID:    form.Get("user_id"),
+		ChannelID: form.Get("channel_id"),
+	})
+	if err != nil {
+		log.Printf("Slackのコマンドの処理に失敗しました: %v", err)
+		c.JSON(http.StatusOK, gin.H{"response_type": "ephemeral", "text": "タスクを作成できませんでした。しばらくしてからもう一度お試しください"})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
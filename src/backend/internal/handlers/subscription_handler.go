@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SubscriptionHandler struct {
+	subscriptionService *services.SubscriptionService
+}
+
+func NewSubscriptionHandler(subscriptionService *services.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{subscriptionService: subscriptionService}
+}
+
+// GetSubscriptions 外部カレンダー購読一覧取得
+func (h *SubscriptionHandler) GetSubscriptions(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	sort, err := parseSort(c, services.SubscriptionSortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	subscriptions, err := h.subscriptionService.ListSubscriptions(userID, sort)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, subscriptions)
+}
+
+// CreateSubscription 外部カレンダー購読登録
+func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.SubscriptionInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	subscription, err := h.subscriptionService.CreateSubscription(userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// RefreshSubscription 外部カレンダーの即時再取得
+func (h *SubscriptionHandler) RefreshSubscription(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	subscription, err := h.subscriptionService.RefreshSubscription(c.Param("id"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteSubscription 外部カレンダー購読解除
+func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.subscriptionService.DeleteSubscription(c.Param("id"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "外部カレンダーの購読を解除しました"})
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type setRecurrenceRequest struct {
+	IsRecurring bool                  `json:"isRecurring"`
+	Recurrence  models.TaskRecurrence `json:"recurrence"`
+}
+
+// GetChecklist タスクのチェックリスト取得
+func (h *TaskHandler) GetChecklist(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	sort, err := parseSort(c, services.ChecklistItemSortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	items, err := h.taskService.GetChecklist(c.Param("id"), userID, sort)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, items)
+}
+
+// AddChecklistItem チェックリスト項目追加
+func (h *TaskHandler) AddChecklistItem(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.ChecklistItemInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	item, err := h.taskService.AddChecklistItem(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateChecklistItem チェックリスト項目更新
+func (h *TaskHandler) UpdateChecklistItem(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.ChecklistItemInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	item, err := h.taskService.UpdateChecklistItem(c.Param("id"), c.Param("itemId"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// DeleteChecklistItem チェックリスト項目削除
+func (h *TaskHandler) DeleteChecklistItem(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.taskService.DeleteChecklistItem(c.Param("id"), c.Param("itemId"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "チェックリスト項目を削除しました"})
+}
+
+// SetRecurrence タスクの繰り返し設定
+func (h *TaskHandler) SetRecurrence(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req setRecurrenceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	task, err := h.taskService.SetRecurrence(c.Param("id"), userID, req.IsRecurring, req.Recurrence)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// GetOccurrences 繰り返しタスクの各回の完了統計取得
+func (h *TaskHandler) GetOccurrences(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	stats, err := h.taskService.GetOccurrenceStats(c.Param("id"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mergeTaskRequest struct {
+	SourceTaskID string `json:"sourceTaskId" binding:"required"`
+}
+
+// MergeTask 重複タスクの統合
+func (h *TaskHandler) MergeTask(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req mergeTaskRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	task, err := h.taskService.MergeTask(c.Param("id"), req.SourceTaskID, userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// GetActivity タスクの操作履歴取得
+func (h *TaskHandler) GetActivity(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	sort, err := parseSort(c, services.TaskActivitySortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	include, err := parseInclude(c, services.TaskActivityIncludes)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	activities, err := h.taskService.GetActivity(c.Param("id"), userID, sort, include)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, activities)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WatchTask タスクのウォッチ開始
+func (h *TaskHandler) WatchTask(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.taskService.WatchTask(c.Param("id"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "タスクをウォッチしました"})
+}
+
+// UnwatchTask タスクのウォッチ解除
+func (h *TaskHandler) UnwatchTask(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.taskService.UnwatchTask(c.Param("id"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "ウォッチを解除しました"})
+}
+
+// GetWatchers タスクのウォッチャー一覧取得
+func (h *TaskHandler) GetWatchers(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	sort, err := parseSort(c, services.WatcherSortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	include, err := parseInclude(c, services.WatcherIncludes)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	watchers, err := h.taskService.GetWatchers(c.Param("id"), userID, sort, include)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, watchers)
+}
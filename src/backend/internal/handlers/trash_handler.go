@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashHandler struct {
+	trashService *services.TrashService
+}
+
+func NewTrashHandler(trashService *services.TrashService) *TrashHandler {
+	return &TrashHandler{trashService: trashService}
+}
+
+// GetTrash 復元できる削除したタスク・イベント・コメント・添付ファイル・チェックリスト項目（保存期間を過ぎたものは完全に削除される）
+func (h *TrashHandler) GetTrash(c *gin.Context) {
+	trash, err := h.trashService.ListTrash(c.GetString("userID"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, trash)
+}
+
+// RestoreTask 削除したタスクを復元する（チームのメンバー）
+func (h *TrashHandler) RestoreTask(c *gin.Context) {
+	task, err := h.trashService.RestoreTask(c.Param("id"), c.GetString("userID"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+// RestoreEvent 削除したイベントを復元する（作成者またはチーム管理者）
+func (h *TrashHandler) RestoreEvent(c *gin.Context) {
+	event, err := h.trashService.RestoreEvent(c.Param("id"), c.GetString("userID"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+// RestoreComment 削除したコメントを復元する（投稿者本人またはチーム管理者）
+func (h *TrashHandler) RestoreComment(c *gin.Context) {
+	comment, err := h.trashService.RestoreComment(c.Param("id"), c.Param("commentId"), c.GetString("userID"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+// RestoreAttachment 削除した添付ファイルを復元する（アップロードした本人またはチーム管理者）
+func (h *TrashHandler) RestoreAttachment(c *gin.Context) {
+	attachment, err := h.trashService.RestoreAttachment(c.Param("id"), c.Param("attachmentId"), c.GetString("userID"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, attachment)
+}
+
+// RestoreChecklistItem 削除したチェックリスト項目を復元する（チームのメンバー）
+func (h *TrashHandler) RestoreChecklistItem(c *gin.Context) {
+	item, err := h.trashService.RestoreChecklistItem(c.Param("id"), c.Param("itemId"), c.GetString("userID"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"task-calendar-backend/internal/upload"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondUploadError アップロードの読み出しのエラーを返す（サイズ超過は413、形式は415、ファイルがない・形式が不正な場合は400）
+//
+// ファイルは保存しながら読み出すため、サービスのエラーもそのまま渡せる（アップロード以外のエラーはサービスエラーとして返す）。
+func respondUploadError(c *gin.Context, err error) {
+	switch {
+	case upload.IsTooLarge(err):
+		respondError(c, http.StatusRequestEntityTooLarge, upload.ErrTooLarge.Error())
+	case errors.Is(err, upload.ErrUnsupportedType):
+		respondError(c, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, upload.ErrNoFile), errors.Is(err, upload.ErrInvalidForm):
+		respondError(c, http.StatusBadRequest, err.Error())
+	default:
+		respondServiceError(c, err)
+	}
+}
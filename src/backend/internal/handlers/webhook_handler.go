@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// GetEndpoints Webhookの宛先の一覧（teamId クエリでチームの宛先、省略すると自分宛ての通知の宛先）
+func (h *WebhookHandler) GetEndpoints(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	sort, err := parseSort(c, services.WebhookSortFields)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	endpoints, err := h.webhookService.ListEndpoints(userID, c.Query("teamId"), sort)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, endpoints)
+}
+
+// CreateEndpoint Webhookの宛先を登録（署名の鍵は応答でのみ返す）
+func (h *WebhookHandler) CreateEndpoint(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.WebhookEndpointInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	endpoint, err := h.webhookService.CreateEndpoint(userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, endpoint)
+}
+
+// UpdateEndpoint Webhookの宛先を変更
+func (h *WebhookHandler) UpdateEndpoint(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.WebhookEndpointUpdateInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	endpoint, err := h.webhookService.UpdateEndpoint(c.Param("id"), userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// DeleteEndpoint Webhookの宛先を削除
+func (h *WebhookHandler) DeleteEndpoint(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	if err := h.webhookService.DeleteEndpoint(c.Param("id"), userID); err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhookを削除しました"})
+}
+
+// GetDeliveries Webhookの配信の一覧（status クエリで PENDING / SUCCEEDED / DEAD に絞り込む）
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	pageOpts, err := parsePageOptions(c)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if pageOpts.Sort, err = parseSort(c, services.CursorSortFields); err != nil {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := h.webhookService.ListDeliveries(c.Param("id"), userID, services.WebhookDeliveryListOptions{
+		PageOptions: pageOpts,
+		Status:      models.WebhookDeliveryStatus(c.Query("status")),
+	})
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondListPage(c, page, page.Deliveries, listPage{NextCursor: page.NextCursor, Total: page.Total})
+}
+
+// GetDelivery 配信の内容と試行ごとの結果
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	delivery, err := h.webhookService.GetDelivery(c.Param("id"), c.Param("deliveryId"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// ReplayDelivery 配信を送り直す（新しい配信として記録する）
+func (h *WebhookHandler) ReplayDelivery(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	delivery, err := h.webhookService.ReplayDelivery(c.Param("id"), c.Param("deliveryId"), userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WeeklyDigestHandler struct {
+	weeklyDigestService *services.WeeklyDigestService
+}
+
+func NewWeeklyDigestHandler(weeklyDigestService *services.WeeklyDigestService) *WeeklyDigestHandler {
+	return &WeeklyDigestHandler{weeklyDigestService: weeklyDigestService}
+}
+
+// GetSettings 自分の週次ダイジェストメールの設定
+func (h *WeeklyDigestHandler) GetSettings(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	settings, err := h.weeklyDigestService.GetSettings(userID)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings 週次ダイジェストメールの配信の停止・再開
+func (h *WeeklyDigestHandler) UpdateSettings(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req services.WeeklyDigestInput
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	settings, err := h.weeklyDigestService.UpdateSettings(userID, req)
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
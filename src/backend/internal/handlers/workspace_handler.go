@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkspaceHandler struct {
+	workspaceService *services.WorkspaceService
+}
+
+func NewWorkspaceHandler(workspaceService *services.WorkspaceService) *WorkspaceHandler {
+	return &WorkspaceHandler{workspaceService: workspaceService}
+}
+
+type createWorkspaceRequest struct {
+	Name  string                `json:"name" binding:"required,notblank"`
+	Slug  string                `json:"slug" binding:"required"`
+	Owner workspaceOwnerRequest `json:"owner" binding:"required"`
+}
+
+type workspaceOwnerRequest struct {
+	Email     string `json:"email" binding:"required,email"`
+	Username  string `json:"username" binding:"required,notblank"`
+	Password  string `json:"password" binding:"required,min=8"`
+	FirstName string `json:"firstName" binding:"required,notblank"`
+	LastName  string `json:"lastName" binding:"required,notblank"`
+}
+
+// CreateWorkspace ワークスペースと、その管理者のユーザーを作成する（運営者。201）
+//
+// 管理者は応答の owner のメールアドレスとパスワードでログインし、チームを作成してメンバーを招待する。
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	var req createWorkspaceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	result, err := h.workspaceService.CreateWorkspace(services.WorkspaceInput{
+		Name: req.Name,
+		Slug: req.Slug,
+		Owner: services.WorkspaceOwnerInput{
+			Email:     req.Owner.Email,
+			Username:  req.Owner.Username,
+			Password:  req.Owner.Password,
+			FirstName: req.Owner.FirstName,
+			LastName:  req.Owner.LastName,
+		},
+	})
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// GetWorkspaces ワークスペースの一覧（運営者）
+func (h *WorkspaceHandler) GetWorkspaces(c *gin.Context) {
+	workspaces, err := h.workspaceService.ListWorkspaces()
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	respondList(c, workspaces)
+}
+
+// GetWorkspace ワークスペース（運営者）
+func (h *WorkspaceHandler) GetWorkspace(c *gin.Context) {
+	workspace, err := h.workspaceService.GetWorkspace(c.Param("id"))
+	if err != nil {
+		respondServiceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, workspace)
+}
@@ -0,0 +1,62 @@
+// Package i18n 応答の言語（Accept-Language で選ぶ。対応していない・指定がない場合は日本語）
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Lang 応答の言語（BCP 47 の言語の部分）
+type Lang string
+
+const (
+	Japanese Lang = "ja"
+	English  Lang = "en"
+
+	// Default 指定がない場合の言語（メッセージは日本語で書いているため）
+	Default = Japanese
+)
+
+// Negotiate Accept-Language（ja, en-US;q=0.8 など）から、対応している言語のうち最も優先度の高いものを選ぶ
+//
+// 地域の指定（en-US）は言語（en）として扱う。q=0 は「使わない」の意味のため除く。
+func Negotiate(header string) Lang {
+	type candidate struct {
+		lang Lang
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(name, "q") {
+				if v, err := strconv.ParseFloat(value, 64); err == nil {
+					q = v
+				}
+			}
+		}
+		base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		switch lang := Lang(base); lang {
+		case Japanese, English:
+			if q > 0 {
+				candidates = append(candidates, candidate{lang: lang, q: q})
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return Default
+	}
+	// 同じ優先度では先に書かれたものを選ぶ
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
+
+// FromContext リクエストの Accept-Language から選んだ言語
+func FromContext(c *gin.Context) Lang {
+	return Negotiate(c.GetHeader("Accept-Language"))
+}
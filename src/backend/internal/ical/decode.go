@@ -0,0 +1,233 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMalformed iCalendarの構文が不正
+var ErrMalformed = errors.New("iCalendarの形式が不正です")
+
+// Decode iCalendarデータを読み込み、最上位のコンポーネント（通常はVCALENDAR）を返す
+func Decode(r io.Reader) (*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var root *Component
+	var stack []*Component
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		prop, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %d行目: %v", ErrMalformed, i+1, err)
+		}
+		switch strings.ToUpper(prop.Name) {
+		case "BEGIN":
+			c := NewComponent(strings.ToUpper(prop.Value))
+			if len(stack) > 0 {
+				stack[len(stack)-1].AddComponent(c)
+			} else if root == nil {
+				root = c
+			} else {
+				return nil, fmt.Errorf("%w: 最上位のコンポーネントが複数あります", ErrMalformed)
+			}
+			stack = append(stack, c)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("%w: %d行目: END:%sが対応していません", ErrMalformed, i+1, prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("%w: %d行目: コンポーネント外のプロパティです", ErrMalformed, i+1)
+			}
+			stack[len(stack)-1].Properties = append(stack[len(stack)-1].Properties, prop)
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("%w: コンポーネントがありません", ErrMalformed)
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("%w: END:%sがありません", ErrMalformed, stack[len(stack)-1].Name)
+	}
+	return root, nil
+}
+
+// unfold 折り返された行を結合する（CRLF・LFどちらの改行も受け付ける）
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+func parseLine(line string) (Property, error) {
+	var prop Property
+
+	// 名前とパラメーターは値の最初のコロンまで（引用符内のコロン・セミコロンは除く）
+	inQuote := false
+	valueStart := -1
+	var segments []string
+	segStart := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			inQuote = !inQuote
+		case ';':
+			if !inQuote {
+				segments = append(segments, line[segStart:i])
+				segStart = i + 1
+			}
+		case ':':
+			if !inQuote {
+				segments = append(segments, line[segStart:i])
+				valueStart = i + 1
+			}
+		}
+		if valueStart >= 0 {
+			break
+		}
+	}
+	if valueStart < 0 {
+		return prop, errors.New("コロンがありません")
+	}
+	if segments[0] == "" {
+		return prop, errors.New("プロパティ名がありません")
+	}
+
+	prop.Name = strings.ToUpper(segments[0])
+	prop.Value = line[valueStart:]
+	for _, seg := range segments[1:] {
+		name, value, ok := strings.Cut(seg, "=")
+		if !ok {
+			return prop, fmt.Errorf("パラメーターが不正です: %s", seg)
+		}
+		prop.Params = append(prop.Params, Param{Name: strings.ToUpper(name), Value: strings.Trim(value, `"`)})
+	}
+	return prop, nil
+}
+
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+// UnescapeText TEXT値のエスケープを解除する
+func UnescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
+
+// Text プロパティ値をTEXTとして返す
+func (p *Property) Text() string {
+	return UnescapeText(p.Value)
+}
+
+// IsDate 値がDATE型（終日）か
+func (p *Property) IsDate() bool {
+	return strings.EqualFold(p.Param("VALUE"), "DATE") || len(p.Value) == len(dateFormat)
+}
+
+// Time DATE / DATE-TIME値を解釈する
+//
+// TZID付きはそのタイムゾーン、Z付きはUTC、どちらもない場合（フローティング）とDATE型はdefaultLocで解釈する。
+func (p *Property) Time(defaultLoc *time.Location) (time.Time, error) {
+	return parseTimeValue(p.Value, p.Param("TZID"), p.IsDate(), defaultLoc)
+}
+
+// Times EXDATEなどカンマ区切りの日時リストを解釈する
+func (p *Property) Times(defaultLoc *time.Location) ([]time.Time, error) {
+	var result []time.Time
+	for _, value := range strings.Split(p.Value, ",") {
+		t, err := parseTimeValue(strings.TrimSpace(value), p.Param("TZID"), p.IsDate(), defaultLoc)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+func parseTimeValue(value, tzid string, isDate bool, defaultLoc *time.Location) (time.Time, error) {
+	loc := defaultLoc
+	if tzid != "" {
+		// 未知のTZID（Windowsのタイムゾーン名など）はデフォルトにフォールバックする
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	if isDate {
+		return time.ParseInLocation(dateFormat, value, loc)
+	}
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(dateTimeUTCFormat, value)
+	}
+	return time.ParseInLocation(dateTimeFormat, value, loc)
+}
+
+// ParseDuration RFC 5545のDURATION値（例: PT1H30M, P1D, -P15M）を解釈する
+func ParseDuration(value string) (time.Duration, error) {
+	s := value
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign = -1
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("DURATIONが不正です: %s", value)
+	}
+	s = s[1:]
+
+	var total time.Duration
+	inTime := false
+	num := ""
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			num += string(r)
+		case r == 'T':
+			inTime = true
+		default:
+			n, err := strconv.Atoi(num)
+			if err != nil {
+				return 0, fmt.Errorf("DURATIONが不正です: %s", value)
+			}
+			num = ""
+			switch {
+			case r == 'W' && !inTime:
+				total += time.Duration(n) * 7 * 24 * time.Hour
+			case r == 'D' && !inTime:
+				total += time.Duration(n) * 24 * time.Hour
+			case r == 'H' && inTime:
+				total += time.Duration(n) * time.Hour
+			case r == 'M' && inTime:
+				total += time.Duration(n) * time.Minute
+			case r == 'S' && inTime:
+				total += time.Duration(n) * time.Second
+			default:
+				return 0, fmt.Errorf("DURATIONが不正です: %s", value)
+			}
+		}
+	}
+	if num != "" {
+		return 0, fmt.Errorf("DURATIONが不正です: %s", value)
+	}
+	return sign * total, nil
+}
@@ -0,0 +1,183 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	dateTimeUTCFormat = "20060102T150405Z"
+	dateTimeFormat    = "20060102T150405"
+	dateFormat        = "20060102"
+
+	// RFC 5545 3.1 の1行あたりの最大オクテット数（改行を除く）
+	maxLineOctets = 75
+)
+
+// Param プロパティのパラメーター（例: TZID=Asia/Tokyo）
+type Param struct {
+	Name  string
+	Value string
+}
+
+// Property コンテンツ行
+type Property struct {
+	Name   string
+	Params []Param
+	Value  string
+}
+
+// Param 指定名のパラメーター値を返す
+func (p *Property) Param(name string) string {
+	for _, param := range p.Params {
+		if strings.EqualFold(param.Name, name) {
+			return param.Value
+		}
+	}
+	return ""
+}
+
+// Component VCALENDAR / VEVENT などのコンポーネント
+type Component struct {
+	Name       string
+	Properties []Property
+	Components []*Component
+}
+
+func NewComponent(name string) *Component {
+	return &Component{Name: name}
+}
+
+// Add プロパティを追加する（値はエスケープ済みであること）
+func (c *Component) Add(name, value string, params ...Param) {
+	c.Properties = append(c.Properties, Property{Name: name, Params: params, Value: value})
+}
+
+// AddText テキスト値をエスケープして追加する
+func (c *Component) AddText(name, value string, params ...Param) {
+	c.Add(name, EscapeText(value), params...)
+}
+
+// AddComponent 子コンポーネントを追加する
+func (c *Component) AddComponent(child *Component) {
+	c.Components = append(c.Components, child)
+}
+
+// Get 指定名の最初のプロパティを返す
+func (c *Component) Get(name string) *Property {
+	for i := range c.Properties {
+		if strings.EqualFold(c.Properties[i].Name, name) {
+			return &c.Properties[i]
+		}
+	}
+	return nil
+}
+
+// GetAll 指定名のプロパティをすべて返す
+func (c *Component) GetAll(name string) []Property {
+	var result []Property
+	for _, p := range c.Properties {
+		if strings.EqualFold(p.Name, name) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// Children 指定名の子コンポーネントを返す
+func (c *Component) Children(name string) []*Component {
+	var result []*Component
+	for _, child := range c.Components {
+		if strings.EqualFold(child.Name, name) {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+// Encode コンポーネントをiCalendar形式（CRLF改行、75オクテットで折り返し）で書き出す
+func Encode(w io.Writer, c *Component) error {
+	bw := bufio.NewWriter(w)
+	if err := encode(bw, c); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func encode(w *bufio.Writer, c *Component) error {
+	if err := writeLine(w, "BEGIN:"+c.Name); err != nil {
+		return err
+	}
+	for _, p := range c.Properties {
+		var b strings.Builder
+		b.WriteString(p.Name)
+		for _, param := range p.Params {
+			b.WriteString(";")
+			b.WriteString(param.Name)
+			b.WriteString("=")
+			b.WriteString(quoteParam(param.Value))
+		}
+		b.WriteString(":")
+		b.WriteString(p.Value)
+		if err := writeLine(w, b.String()); err != nil {
+			return err
+		}
+	}
+	for _, child := range c.Components {
+		if err := encode(w, child); err != nil {
+			return err
+		}
+	}
+	return writeLine(w, "END:"+c.Name)
+}
+
+// writeLine UTF-8の文字境界を保ったまま75オクテットごとに折り返して書き出す
+func writeLine(w *bufio.Writer, line string) error {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, err := w.WriteString(line[:cut] + "\r\n "); err != nil {
+			return err
+		}
+		line = line[cut:]
+		// 継続行は先頭の空白1文字分だけ短くする
+		limit = maxLineOctets - 1
+	}
+	_, err := w.WriteString(line + "\r\n")
+	return err
+}
+
+func quoteParam(value string) string {
+	if strings.ContainsAny(value, ";:,") {
+		return `"` + strings.ReplaceAll(value, `"`, "") + `"`
+	}
+	return value
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// EscapeText TEXT値をエスケープする
+func EscapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// FormatUTC 日時をUTCのDATE-TIME形式で返す
+func FormatUTC(t time.Time) string {
+	return t.UTC().Format(dateTimeUTCFormat)
+}
+
+// FormatLocal 日時をタイムゾーン指定付き（TZIDパラメーターと組み合わせる）のDATE-TIME形式で返す
+func FormatLocal(t time.Time) string {
+	return t.Format(dateTimeFormat)
+}
+
+// FormatDate 日付をDATE形式で返す
+func FormatDate(t time.Time) string {
+	return t.Format(dateFormat)
+}
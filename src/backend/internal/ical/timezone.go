@@ -0,0 +1,71 @@
+package ical
+
+import (
+	"fmt"
+	"time"
+)
+
+// VTimezone 指定期間に適用されるオフセット遷移からVTIMEZONEコンポーネントを生成する
+//
+// IANAのルールをRRULEに変換する代わりに、期間内の遷移を個別のSTANDARD/DAYLIGHTとして列挙する。
+func VTimezone(loc *time.Location, from, to time.Time) *Component {
+	tz := NewComponent("VTIMEZONE")
+	tz.Add("TZID", loc.String())
+
+	start := from.In(loc)
+	_, prevOffset := start.Zone()
+	tz.AddComponent(observance(start, prevOffset, prevOffset))
+
+	for day := start; day.Before(to); day = day.Add(24 * time.Hour) {
+		next := day.Add(24 * time.Hour)
+		if _, offset := next.Zone(); offset != prevOffset {
+			at := findTransition(day, next)
+			tz.AddComponent(observance(at.In(loc), prevOffset, offset))
+			prevOffset = offset
+		}
+	}
+	return tz
+}
+
+// findTransition [lo, hi) の間でオフセットが切り替わる時刻を秒単位で探す
+func findTransition(lo, hi time.Time) time.Time {
+	_, base := lo.Zone()
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		if _, offset := mid.Zone(); offset == base {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
+
+func observance(at time.Time, offsetFrom, offsetTo int) *Component {
+	name := "STANDARD"
+	if at.IsDST() {
+		name = "DAYLIGHT"
+	}
+	c := NewComponent(name)
+	// DTSTARTは遷移前のオフセットでのローカル時刻で表す
+	c.Add("DTSTART", at.UTC().Add(time.Duration(offsetFrom)*time.Second).Format(dateTimeFormat))
+	c.Add("TZOFFSETFROM", formatOffset(offsetFrom))
+	c.Add("TZOFFSETTO", formatOffset(offsetTo))
+	if abbr, _ := at.Zone(); abbr != "" {
+		c.AddText("TZNAME", abbr)
+	}
+	return c
+}
+
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+	if rest := seconds % 60; rest != 0 {
+		s += fmt.Sprintf("%02d", rest)
+	}
+	return s
+}
@@ -0,0 +1,58 @@
+// Package logging 構造化ログ（JSON、1行1件）の設定
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// level 既定のロガーのレベル（SetLevel で実行中に変えられる）
+var level slog.LevelVar
+
+// Setup ログの出力を level（debug / info / warn / error）以上・format（json / text）の形式にする
+//
+// 標準の log パッケージの出力（log.Printf など）も、info のログとして同じ形式で出力される。
+func Setup(lv, format string) error {
+	if err := SetLevel(lv); err != nil {
+		return err
+	}
+	logger, err := New(os.Stdout, &level, format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// SetLevel 既定のロガーのレベルを変える（設定の再読み込み用）
+func SetLevel(lv string) error {
+	parsed, err := parseLevel(lv)
+	if err != nil {
+		return err
+	}
+	level.Set(parsed)
+	return nil
+}
+
+func parseLevel(lv string) (slog.Level, error) {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(lv)); err != nil {
+		return parsed, fmt.Errorf("ログレベルはdebug・info・warn・errorのいずれかを指定してください: %s", lv)
+	}
+	return parsed, nil
+}
+
+// New level 以上のログを format の形式で w に書き込むロガー
+func New(w io.Writer, level slog.Leveler, format string) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(format) {
+	case "", "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("ログの形式はjsonまたはtextを指定してください: %s", format)
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/apierror"
+	"task-calendar-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RequireAdmin システム管理者(ADMIN)のみアクセスを許可する
+func RequireAdmin(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user models.User
+		if err := db.Select("id", "role").First(&user, "id = ?", c.GetString("userID")).Error; err != nil {
+			apierror.Abort(c, http.StatusUnauthorized, "ユーザーが見つかりません")
+			return
+		}
+		if user.Role != models.UserRoleAdmin {
+			apierror.Abort(c, http.StatusForbidden, "管理者権限が必要です")
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePlatformAdmin 運営者（既定のワークスペースのシステム管理者）のみアクセスを許可する
+//
+// ワークスペースの作成や定期実行・非同期ジョブの管理など、すべてのワークスペースに関わる機能に使う。
+func RequirePlatformAdmin(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var user models.User
+		if err := db.Select("id", "role", "workspace_id").First(&user, "id = ?", c.GetString("userID")).Error; err != nil {
+			apierror.Abort(c, http.StatusUnauthorized, "ユーザーが見つかりません")
+			return
+		}
+		if user.Role != models.UserRoleAdmin || user.WorkspaceID != models.DefaultWorkspaceID {
+			apierror.Abort(c, http.StatusForbidden, "運営者の管理者権限が必要です")
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"time"
+
+	"task-calendar-backend/internal/ratelimit"
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIUsage リクエストを利用者・ルートごとに集計する（管理者向けの利用状況の分析に使う）
+//
+// 認証はルートのグループごとに行うため、ユーザーは後続の処理が終わってから読み取る。応答時間は後続の処理全体の時間。
+func APIUsage(usageService *services.APIUsageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Context().Value(internalRequestKey{}) != nil {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		c.Next()
+
+		userID := c.GetString("userID")
+		route := c.Request.Method
+		if path := c.FullPath(); path != "" {
+			route += " " + path
+		}
+		usageService.Record(ratelimit.Key(userID, c.ClientIP()), userID, route, c.Writer.Status(), time.Since(start), start)
+	}
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersion リクエストを処理するAPIのバージョン（v1 など）をコンテキストの apiVersion と API-Version ヘッダーに設定する
+//
+// バージョンごとにルートのグループを分けて使う。バージョンなしの /api（互換のための別名）では、別名が指すバージョンを指定する。
+func APIVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("apiVersion", version)
+		c.Header("API-Version", version)
+		c.Next()
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+
+	"task-calendar-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// BasicAuth メールアドレス（またはユーザー名）とパスワードによるBasic認証
+//
+// JWTを扱えないCalDAVクライアント向け。認証に成功すると "userID" を設定する。
+func BasicAuth(db *gorm.DB, realm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		login, password, ok := c.Request.BasicAuth()
+		if ok {
+			var user models.User
+			err := db.Select("id", "password").Where("email = ? OR username = ?", login, login).First(&user).Error
+			if err == nil && bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) == nil {
+				c.Set("userID", user.ID)
+				c.Next()
+				return
+			}
+		}
+
+		c.Header("WWW-Authenticate", `Basic realm="`+realm+`", charset="UTF-8"`)
+		c.AbortWithStatus(http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"task-calendar-backend/internal/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ETag GETのJSONの応答に、本文から求めたETagをつける
+//
+// 応答はJSONの場合のみ読み込み終わるまでためる（ファイルのダウンロードなどはそのまま送る）。
+// If-Match で比べる内部のGETと同じ値になるよう、Fields の後に登録して、fields で絞り込む前の応答全体から求める。
+func ETag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		writer := &jsonBufferWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if !writer.buffering {
+			return
+		}
+		if writer.Status() == http.StatusOK {
+			// 一覧の meta.requestId はリクエストごとに変わるため、除いてから求める
+			body := bytes.ReplaceAll(writer.body.Bytes(), []byte(`"requestId":"`+c.GetString("requestID")+`"`), nil)
+			c.Header("ETag", computeETag(body))
+		}
+		c.Writer.Write(writer.body.Bytes())
+	}
+}
+
+// IfMatch PUT・PATCH・DELETEの If-Match を、同じパスのGETの応答のETagと比べる（一致しない場合は412）
+//
+// GETのルートがないパスや、GETが成功しない場合（存在しない・権限がないなど）は比べずにハンドラーに任せる。
+// 比べた後に他の更新が割り込んでも上書きしないよう、一致したGETの応答の version を "ifMatchVersion" に入れる。
+// バージョンを持つリソースのハンドラーは、これを更新の条件にする（更新されなかった場合は412）。
+func IfMatch(engine *gin.Engine) gin.HandlerFunc {
+	var (
+		once   sync.Once
+		routes map[string]bool
+	)
+	return func(c *gin.Context) {
+		ifMatch := c.GetHeader("If-Match")
+		switch c.Request.Method {
+		case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+		if ifMatch == "" {
+			c.Next()
+			return
+		}
+		once.Do(func() {
+			routes = map[string]bool{}
+			for _, r := range engine.Routes() {
+				if r.Method == http.MethodGet {
+					routes[r.Path] = true
+				}
+			}
+		})
+		if !routes[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		current, version, ok := currentETag(engine, c.Request)
+		if !ok {
+			c.Next()
+			return
+		}
+		if !etagMatches(ifMatch, current) {
+			c.Header("ETag", current)
+			apierror.Abort(c, http.StatusPreconditionFailed, "他のユーザーが先に更新しました。最新の内容を取得してください")
+			return
+		}
+		if version > 0 {
+			c.Set("ifMatchVersion", version)
+		}
+		c.Next()
+	}
+}
+
+// currentETag 同じパスのGETを内部で実行し、応答のETagと version（ない場合は0）を返す（成功しなかった場合は false）
+func currentETag(engine *gin.Engine, r *http.Request) (string, int, bool) {
+	req := r.Clone(context.WithValue(r.Context(), internalRequestKey{}, true))
+	req.Method = http.MethodGet
+	req.Body = http.NoBody
+	req.ContentLength = 0
+	req.Header.Del("If-Match")
+	req.Header.Del("Content-Type")
+
+	rec := &discardWriter{header: http.Header{}, status: http.StatusOK}
+	engine.ServeHTTP(rec, req)
+	etag := rec.header.Get("ETag")
+	if rec.status != http.StatusOK || etag == "" {
+		return "", 0, false
+	}
+	var resource struct {
+		Version int `json:"version"`
+	}
+	json.Unmarshal(rec.body.Bytes(), &resource)
+	return etag, resource.Version, true
+}
+
+// internalRequestKey 内部で実行したリクエストであることを表すコンテキストのキー（利用状況・リクエスト数の制限には数えない）
+type internalRequestKey struct{}
+
+// etagMatches If-Match（カンマ区切り、* はすべて）に現在のETagが含まれるか（強い比較）
+func etagMatches(header, current string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || (tag == current && !strings.HasPrefix(tag, "W/")) {
+			return true
+		}
+	}
+	return false
+}
+
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// jsonBufferWriter JSONの応答の本文をためる（それ以外の応答はそのまま送る）
+type jsonBufferWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	buffering bool
+	decided   bool
+}
+
+func (w *jsonBufferWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.decided = true
+		w.buffering = strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+	}
+	if w.buffering {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *jsonBufferWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// discardWriter 内部で実行したGETのステータスとヘッダー、JSONの応答の本文を受け取る（クライアントには送らない）
+type discardWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *discardWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardWriter) Write(data []byte) (int, error) {
+	if strings.HasPrefix(w.header.Get("Content-Type"), "application/json") {
+		w.body.Write(data)
+	}
+	return len(data), nil
+}
+
+func (w *discardWriter) WriteHeader(status int) {
+	w.status = status
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fields で絞り込んだGETのETagでも If-Match が一致し、一致したGETの version をハンドラーに渡す
+func TestIfMatchPassesVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	api := r.Group("/api")
+	api.Use(Fields())
+	api.Use(ETag(), IfMatch(r))
+	api.GET("/tasks/:id", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "title": "Task", "version": 3})
+	})
+	api.PATCH("/tasks/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, strconv.Itoa(c.GetInt("ifMatchVersion")))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/tasks/k1?fields=id", nil))
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("GETの応答にETagがありません")
+	}
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/tasks/k1", nil)
+	req.Header.Set("If-Match", etag)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "3" {
+		t.Errorf("一致するETagでの更新が %d（version %s）です", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPatch, "/api/tasks/k1", nil)
+	req.Header.Set("If-Match", `"stale"`)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("古いETagでの更新が %d です（412のはず）", w.Code)
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Fields fields クエリ（カンマ区切り）で、GETのJSONの応答を指定した項目のみにする
+//
+// 入れ子の項目は . でつなぐ（fields=id,title,assignee.username）。配列の応答は要素ごとに絞り込むため、
+// ページングの応答では一覧の項目名をつけて指定する（fields=comments.id,comments.content,nextCursor）。
+// v2 の一覧の応答では data をつけて指定する（fields=data.id,data.title,meta）。
+func Fields() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fields := parseFields(c.Query("fields"))
+		if c.Request.Method != http.MethodGet || fields == nil {
+			c.Next()
+			return
+		}
+		writer := &jsonBufferWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if !writer.buffering {
+			return
+		}
+		body := writer.body.Bytes()
+		if status := writer.Status(); status >= http.StatusOK && status < http.StatusMultipleChoices {
+			if filtered, err := filterFields(body, fields); err == nil {
+				body = filtered
+			}
+		}
+		c.Writer.Write(body)
+	}
+}
+
+// fieldTree 残す項目（値がnilの項目は全体を残す）
+type fieldTree map[string]fieldTree
+
+func parseFields(value string) fieldTree {
+	var tree fieldTree
+	for _, path := range strings.Split(value, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		if tree == nil {
+			tree = fieldTree{}
+		}
+		node := tree
+		parts := strings.Split(path, ".")
+		for i, part := range parts {
+			child, exists := node[part]
+			if i == len(parts)-1 {
+				// 親の項目全体を指定した場合は、入れ子の指定より優先する
+				node[part] = nil
+				break
+			}
+			if exists && child == nil {
+				break
+			}
+			if !exists {
+				child = fieldTree{}
+				node[part] = child
+			}
+			node = child
+		}
+	}
+	return tree
+}
+
+func filterFields(body []byte, fields fieldTree) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(pickFields(value, fields))
+}
+
+func pickFields(value interface{}, fields fieldTree) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		picked := make(map[string]interface{}, len(fields))
+		for name, child := range fields {
+			item, ok := v[name]
+			if !ok {
+				continue
+			}
+			if child == nil {
+				picked[name] = item
+			} else {
+				picked[name] = pickFields(item, child)
+			}
+		}
+		return picked
+	case []interface{}:
+		picked := make([]interface{}, len(v))
+		for i, item := range v {
+			picked[i] = pickFields(item, fields)
+		}
+		return picked
+	}
+	return value
+}
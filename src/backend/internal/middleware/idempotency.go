@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"task-calendar-backend/internal/apierror"
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyFingerprintSize 再送の照合に使う本文の先頭のサイズ
+const idempotencyFingerprintSize = 1 << 20
+
+// Idempotency Idempotency-Key ヘッダーをつけたPOSTを、ユーザーごとにキーで記録する
+//
+// 同じキーで再送されたリクエストは処理せず、最初の応答を返す（Idempotent-Replayed: true）。
+// サーバーエラーになった場合（パニックを含む）はキーを消し、同じキーで再送できるようにする。AuthMiddlewareの後に使う。
+func Idempotency(idempotencyService *services.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		userID := c.GetString("userID")
+
+		// 本文は先頭の idempotencyFingerprintSize までを読んで照合に使い、残りは読まずにハンドラーに渡す
+		// （アップロードをメモリにためず、サイズの上限はハンドラーで確かめる）
+		head, err := io.ReadAll(io.LimitReader(c.Request.Body, idempotencyFingerprintSize))
+		if err != nil {
+			apierror.Abort(c, http.StatusBadRequest, "リクエストの本文を読み込めません")
+			return
+		}
+		c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(head), c.Request.Body), Closer: c.Request.Body}
+		// クエリ（?notify=false など）や利用者が異なる再送は、同じキーでも別のリクエストとして扱う
+		hash := sha256.New()
+		io.WriteString(hash, userID+"\n")
+		io.WriteString(hash, c.Request.Method+" "+c.Request.URL.Path+"?"+c.Request.URL.RawQuery+"\n")
+		if len(head) == idempotencyFingerprintSize {
+			// 先頭だけでは異なる本文を区別できないため、本文の長さも加える
+			io.WriteString(hash, "length="+strconv.FormatInt(c.Request.ContentLength, 10)+"\n")
+		}
+		hash.Write(head)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		saved, err := idempotencyService.Begin(userID, key, fingerprint)
+		switch {
+		case errors.Is(err, services.ErrInvalidInput):
+			apierror.Abort(c, http.StatusBadRequest, err.Error())
+			return
+		case errors.Is(err, services.ErrIdempotencyKeyReused):
+			apierror.Abort(c, http.StatusUnprocessableEntity, err.Error())
+			return
+		case errors.Is(err, services.ErrIdempotencyKeyInProgress):
+			apierror.Abort(c, http.StatusConflict, err.Error())
+			return
+		case err != nil:
+			apierror.Abort(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if saved != nil {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(saved.StatusCode, saved.ContentType, saved.Response)
+			c.Abort()
+			return
+		}
+
+		// ハンドラーがパニックした場合も処理中のまま残さず、キーを消して再送できるようにする
+		defer func() {
+			if r := recover(); r != nil {
+				if err := idempotencyService.Abandon(userID, key); err != nil {
+					log.Printf("Idempotency-Keyの削除に失敗しました: %v", err)
+				}
+				panic(r)
+			}
+		}()
+
+		writer := &teeWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			err = idempotencyService.Abandon(userID, key)
+		} else {
+			err = idempotencyService.Complete(userID, key, status, writer.Header().Get("Content-Type"), writer.body.Bytes())
+		}
+		if err != nil {
+			log.Printf("Idempotency-Keyの保存に失敗しました: %v", err)
+		}
+	}
+}
+
+// readCloser 読み出しと閉じる処理を別々に持つ本文
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// teeWriter 応答を送りながら本文を保存する
+type teeWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *teeWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *teeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
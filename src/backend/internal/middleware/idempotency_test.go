@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"task-calendar-backend/internal/database"
+	"task-calendar-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 同じキーでクエリだけ異なるリクエストを送った場合は、最初の応答を返さずに422にする
+func TestIdempotencyKeyReusedWithDifferentQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := database.Connect("sqlite:"+filepath.Join(t.TempDir(), "test.db"), database.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.MigrateUp(db); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("userID", "u1") }, Idempotency(services.NewIdempotencyService(db)))
+	r.POST("/tasks", func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusCreated, gin.H{"notify": c.Query("notify")})
+	})
+	post := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/tasks"+query, strings.NewReader(`{"title":"a"}`))
+		req.Header.Set("Idempotency-Key", "key-1")
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := post("?notify=true"); w.Code != http.StatusCreated {
+		t.Fatalf("最初のリクエストが %d です", w.Code)
+	}
+	w := post("?notify=true")
+	if w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("同じリクエストの再送が %d（Idempotent-Replayed: %q）です", w.Code, w.Header().Get("Idempotent-Replayed"))
+	}
+	if w := post("?notify=false"); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("クエリの異なるリクエストが %d です（422のはず）", w.Code)
+	}
+	if calls != 1 {
+		t.Errorf("ハンドラーを %d 回実行しました", calls)
+	}
+}
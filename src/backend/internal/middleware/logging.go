@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"task-calendar-backend/internal/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// quietPaths 頻繁に呼ばれるため debug でのみ記録するパス（Kubernetesのプローブ）
+var quietPaths = map[string]bool{"/healthz": true, "/readyz": true, "/health": true}
+
+// RequestLogger リクエストごとに1件のログを書く（リクエストID・ユーザー・ルート・ステータス・応答時間）
+//
+// RequestID の後に置く。5xxは error、4xxは warn、それ以外は info で記録する。
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		case quietPaths[c.Request.URL.Path]:
+			level = slog.LevelDebug
+		}
+		attrs := []slog.Attr{
+			slog.String("requestId", c.GetString("requestID")),
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Int64("latencyMs", time.Since(start).Milliseconds()),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("clientIp", c.ClientIP()),
+		}
+		if userID := c.GetString("userID"); userID != "" {
+			attrs = append(attrs, slog.String("userId", userID))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		slog.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery パニックをスタックトレースとともに記録し、500のエラー応答を返す
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, err interface{}) {
+		slog.Error("リクエストの処理中にパニックが発生しました",
+			"requestId", c.GetString("requestID"),
+			"error", err,
+			"stack", string(debug.Stack()))
+		apierror.Abort(c, http.StatusInternalServerError, "サーバーでエラーが発生しました")
+	})
+}
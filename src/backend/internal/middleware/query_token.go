@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// TokenFromQuery Authorizationヘッダーがない場合、クエリパラメータのトークンをBearerトークンとして扱う
+//
+// ヘッダーを指定できないブラウザのWebSocket接続などで、AuthMiddlewareの前に使う。
+func TokenFromQuery(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query(param); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		c.Next()
+	}
+}
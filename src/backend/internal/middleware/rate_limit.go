@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"task-calendar-backend/internal/apierror"
+	"task-calendar-backend/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit リクエスト数を制限し、X-RateLimit-Limit・X-RateLimit-Remaining・X-RateLimit-Reset ヘッダーで状況を返す
+//
+// 認証済みのリクエストはユーザーごと、それ以外はIPアドレスごとに数える。上限を超えた場合は Retry-After とともに429を返す。
+// If-Match の確認で内部で実行するリクエストは、元のリクエストで数えているため数えない。
+func RateLimit(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !limiter.Enabled() || c.Request.Context().Value(internalRequestKey{}) != nil {
+			c.Next()
+			return
+		}
+		quota, ok := limiter.Allow(ratelimit.Key(c.GetString("userID"), c.ClientIP()))
+		setRateLimitHeaders(c, quota)
+		if !ok {
+			retryAfter := int(time.Until(quota.Reset).Seconds()) + 1
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			apierror.Abort(c, http.StatusTooManyRequests, "リクエストが多すぎます。しばらくしてから再度お試しください")
+			return
+		}
+		c.Next()
+	}
+}
+
+// setRateLimitHeaders 制限の状況をヘッダーに設定する（Reset はUNIX時間の秒）
+func setRateLimitHeaders(c *gin.Context, quota ratelimit.Quota) {
+	c.Header("X-RateLimit-Limit", strconv.Itoa(quota.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(quota.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(quota.Reset.Unix(), 10))
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// クライアントが指定できるリクエストIDの形式（ログに書くため英数字と記号の一部のみ）
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// RequestID リクエストごとのIDをコンテキストの requestID と X-Request-ID ヘッダーに設定する
+//
+// クライアントが X-Request-ID を指定した場合はその値を使う。エラー応答の requestId にも同じ値が入る。
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Set("requestID", id)
+		c.Header("X-Request-ID", id)
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TaskActivity モデル（タスクの操作履歴）
+type TaskActivity struct {
+	ID        string             `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Action    TaskActivityAction `json:"action" gorm:"not null"`
+	Details   string             `json:"details"`
+	CreatedAt time.Time          `json:"createdAt"`
+	TaskID    string             `json:"taskId" gorm:"not null;index"`
+	ActorID   string             `json:"actorId" gorm:"not null"`
+
+	// Relations
+	Actor User `json:"actor" gorm:"foreignKey:ActorID"`
+}
+
+type TaskActivityAction string
+
+const (
+	TaskActivityMergedFrom      TaskActivityAction = "MERGED_FROM"
+	TaskActivityMergedInto      TaskActivityAction = "MERGED_INTO"
+	TaskActivityCommentDeleted  TaskActivityAction = "COMMENT_DELETED"
+	TaskActivityRestored        TaskActivityAction = "RESTORED"
+	TaskActivityCommentRestored TaskActivityAction = "COMMENT_RESTORED"
+)
+
+func (ta *TaskActivity) BeforeCreate(tx *gorm.DB) error {
+	if ta.ID == "" {
+		ta.ID = generateID()
+	}
+	return nil
+}
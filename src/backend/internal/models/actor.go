@@ -0,0 +1,23 @@
+package models
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type actorKey struct{}
+
+// WithActor 保存を行うユーザーをコールバックに伝えるDBを返す（変更者によって振る舞いが変わるコールバック向け）
+func WithActor(db *gorm.DB, userID string) *gorm.DB {
+	return db.WithContext(context.WithValue(db.Statement.Context, actorKey{}, userID))
+}
+
+// ActorID WithActor で渡された保存を行うユーザー（渡されていない場合は空）
+func ActorID(tx *gorm.DB) string {
+	if tx.Statement.Context == nil {
+		return ""
+	}
+	userID, _ := tx.Statement.Context.Value(actorKey{}).(string)
+	return userID
+}
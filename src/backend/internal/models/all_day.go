@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// AllDayDate 日時の日付部分（その日時自身のタイムゾーンでの日付）をUTCの0時として返す
+//
+// 終日イベントは閲覧者のタイムゾーンに関係なく同じ日付を指すよう、日付のみをUTCの0時で保存する。
+func AllDayDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// NormalizeAllDayRange 終日イベントの期間を日付単位に丸める
+//
+// 終了日はiCalendarと同じく排他的（最終日の翌日）とし、時刻を含む場合は翌日に切り上げる。
+// 終了日が開始日以前の場合は1日の予定とみなす。
+func NormalizeAllDayRange(start, end time.Time) (time.Time, time.Time) {
+	startDate := AllDayDate(start)
+	endDate := AllDayDate(end)
+	if !end.Equal(time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())) {
+		endDate = endDate.AddDate(0, 0, 1)
+	}
+	if !endDate.After(startDate) {
+		endDate = startDate.AddDate(0, 0, 1)
+	}
+	return startDate, endDate
+}
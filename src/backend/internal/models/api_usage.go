@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// APIUsage モデル（APIの利用状況の1時間ごとの集計。利用者とルートごと）
+//
+// ClientKey はレート制限と同じ単位（認証済みのリクエストは user:<ユーザーID>、それ以外は ip:<IPアドレス>）。
+// Route は「メソッド パス」の形式（GET /api/v1/tasks/:id。ルートが見つからないリクエストはパスなし）。
+type APIUsage struct {
+	HourStart      time.Time `json:"hourStart" gorm:"primaryKey"`
+	ClientKey      string    `json:"clientKey" gorm:"primaryKey;type:varchar(80)"`
+	Route          string    `json:"route" gorm:"primaryKey;type:varchar(255)"`
+	UserID         *string   `json:"userId" gorm:"index"`
+	Requests       int64     `json:"requests" gorm:"not null;default:0"`
+	ClientErrors   int64     `json:"clientErrors" gorm:"not null;default:0"` // 4xx
+	ServerErrors   int64     `json:"serverErrors" gorm:"not null;default:0"` // 5xx
+	TotalLatencyMs int64     `json:"totalLatencyMs" gorm:"not null;default:0"`
+	MaxLatencyMs   int64     `json:"maxLatencyMs" gorm:"not null;default:0"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Attachment モデル（タスク・コメントの添付ファイル）
+type Attachment struct {
+	ID          string         `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	FileName    string         `json:"fileName" gorm:"not null"`
+	ContentType string         `json:"contentType"`
+	Size        int64          `json:"size"`
+	StorageKey  string         `json:"-" gorm:"not null"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	DeletedAt   gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"` // 削除した日時（保存期間を過ぎるまでファイルごと残し、復元できる）
+	TaskID      string         `json:"taskId" gorm:"not null;index"`
+	CommentID   *string        `json:"commentId" gorm:"index"`
+	UploaderID  string         `json:"uploaderId" gorm:"not null"`
+
+	// Relations
+	Uploader User `json:"uploader" gorm:"foreignKey:UploaderID"`
+}
+
+func (a *Attachment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = generateID()
+	}
+	return nil
+}
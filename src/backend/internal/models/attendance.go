@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EventCheckIn モデル（イベントの回ごとの出席チェックイン）
+type EventCheckIn struct {
+	ID           string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	RecurrenceID time.Time `json:"recurrenceId" gorm:"not null;uniqueIndex:idx_event_check_in"` // 対象の回（繰り返しルール上の本来の開始日時）
+	CheckedInAt  time.Time `json:"checkedInAt" gorm:"not null"`
+	EventID      string    `json:"eventId" gorm:"not null;uniqueIndex:idx_event_check_in"`
+	UserID       string    `json:"userId" gorm:"not null;uniqueIndex:idx_event_check_in"`
+
+	// Relations
+	User User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+func (ci *EventCheckIn) BeforeCreate(tx *gorm.DB) error {
+	if ci.ID == "" {
+		ci.ID = generateID()
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EventAttendee モデル（イベントへの招待と出欠回答）
+type EventAttendee struct {
+	ID           string         `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Status       AttendeeStatus `json:"status" gorm:"default:'PENDING'"`
+	Comment      string         `json:"comment"`
+	RespondedAt  *time.Time     `json:"respondedAt"`
+	WaitlistedAt *time.Time     `json:"waitlistedAt,omitempty"` // キャンセル待ちになった日時（繰り上げの順番）
+	CreatedAt    time.Time      `json:"createdAt"`
+	UpdatedAt    time.Time      `json:"updatedAt"`
+	EventID      string         `json:"eventId" gorm:"not null;uniqueIndex:idx_event_attendee"`
+	UserID       string         `json:"userId" gorm:"not null;uniqueIndex:idx_event_attendee"`
+	InvitedByID  string         `json:"invitedById" gorm:"not null"`
+
+	// Relations
+	User User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+type AttendeeStatus string
+
+const (
+	AttendeeStatusPending   AttendeeStatus = "PENDING"
+	AttendeeStatusAccepted  AttendeeStatus = "ACCEPTED"
+	AttendeeStatusDeclined  AttendeeStatus = "DECLINED"
+	AttendeeStatusTentative AttendeeStatus = "TENTATIVE"
+	// AttendeeStatusWaitlisted 出席の回答が定員を超えたためキャンセル待ち（空きが出ると出席に繰り上がる）
+	AttendeeStatusWaitlisted AttendeeStatus = "WAITLISTED"
+)
+
+// InactiveAttendeeStatuses 予定の重複・空き状況などで参加者として扱わない状態
+var InactiveAttendeeStatuses = []AttendeeStatus{AttendeeStatusDeclined, AttendeeStatusWaitlisted}
+
+func (ea *EventAttendee) BeforeCreate(tx *gorm.DB) error {
+	if ea.ID == "" {
+		ea.ID = generateID()
+	}
+	return ensureSameWorkspace(tx, ea.UserID, &Event{}, ea.EventID)
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AutomationTrigger 自動化のルールを実行するきっかけ
+type AutomationTrigger string
+
+const (
+	AutomationTriggerTaskCreated       AutomationTrigger = "TASK_CREATED"
+	AutomationTriggerTaskStatusChanged AutomationTrigger = "TASK_STATUS_CHANGED"
+	AutomationTriggerTaskAssigned      AutomationTrigger = "TASK_ASSIGNED" // 担当者が新たに設定された
+	AutomationTriggerTaskOverdue       AutomationTrigger = "TASK_OVERDUE"  // 完了・キャンセルしていないタスクの期限が過ぎた
+)
+
+// AutomationActionType 自動化のルールで行う操作
+type AutomationActionType string
+
+const (
+	AutomationActionSetStatus     AutomationActionType = "SET_STATUS"
+	AutomationActionSetPriority   AutomationActionType = "SET_PRIORITY"
+	AutomationActionSetAssignee   AutomationActionType = "SET_ASSIGNEE"
+	AutomationActionUnassign      AutomationActionType = "UNASSIGN"
+	AutomationActionAddLabel      AutomationActionType = "ADD_LABEL"
+	AutomationActionRemoveLabel   AutomationActionType = "REMOVE_LABEL"
+	AutomationActionPostToChannel AutomationActionType = "POST_TO_CHANNEL" // チームのチャットのチャンネルに投稿する
+	AutomationActionNotify        AutomationActionType = "NOTIFY"          // 担当者・作成者・ウォッチャーに通知する
+)
+
+// AutomationCondition ルールを実行する条件（タスクの項目と値を比べる）
+//
+// Field は status・previousStatus（変更前のステータス）・priority・assigneeId・creatorId・title。
+// Operator は eq・ne・contains（値は1つ）、in・notIn（値は1つ以上）、empty・notEmpty（値なし）。
+type AutomationCondition struct {
+	Field    string   `json:"field" binding:"required,oneof=status previousStatus priority assigneeId creatorId title"`
+	Operator string   `json:"operator" binding:"required,oneof=eq ne in notIn contains empty notEmpty"`
+	Values   []string `json:"values,omitempty" binding:"max=20"`
+}
+
+// AutomationAction ルールで行う操作（種類ごとに使う項目が異なる）
+type AutomationAction struct {
+	Type       AutomationActionType `json:"type" binding:"required,oneof=SET_STATUS SET_PRIORITY SET_ASSIGNEE UNASSIGN ADD_LABEL REMOVE_LABEL POST_TO_CHANNEL NOTIFY"`
+	Status     TaskStatus           `json:"status,omitempty"`                    // SET_STATUS
+	Priority   Priority             `json:"priority,omitempty"`                  // SET_PRIORITY
+	UserID     string               `json:"userId,omitempty"`                    // SET_ASSIGNEE
+	LabelID    string               `json:"labelId,omitempty"`                   // ADD_LABEL・REMOVE_LABEL
+	ChannelID  string               `json:"channelId,omitempty"`                 // POST_TO_CHANNEL（TeamChatChannel.ID）
+	Recipients []string             `json:"recipients,omitempty"`                // NOTIFY（assignee・creator・watchers）
+	Message    string               `json:"message,omitempty" binding:"max=500"` // POST_TO_CHANNEL・NOTIFY の本文（省略時はルール名）
+}
+
+// AutomationRule モデル（チームのタスクの自動化のルール。きっかけ・条件・操作の組）
+type AutomationRule struct {
+	ID              string                `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Name            string                `json:"name" gorm:"not null"`
+	Enabled         bool                  `json:"enabled" gorm:"not null;default:true"`
+	Trigger         AutomationTrigger     `json:"trigger" gorm:"type:varchar(32);not null;index:idx_automation_rules_team_trigger,priority:2"`
+	Conditions      []AutomationCondition `json:"conditions" gorm:"serializer:json;type:text"` // すべて満たす場合に実行する（空は常に実行）
+	Actions         []AutomationAction    `json:"actions" gorm:"serializer:json;type:text"`
+	LastTriggeredAt *time.Time            `json:"lastTriggeredAt"`
+	CreatedAt       time.Time             `json:"createdAt"`
+	UpdatedAt       time.Time             `json:"updatedAt"`
+	TeamID          string                `json:"teamId" gorm:"not null;index:idx_automation_rules_team_trigger,priority:1"`
+	CreatorID       string                `json:"creatorId" gorm:"not null"`
+
+	// Relations
+	Team Team `json:"-" gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE"`
+}
+
+// AutomationOverdueRun モデル（期限切れのきっかけで処理したタスク。同じ期限で二度実行しない）
+type AutomationOverdueRun struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	DueDate   time.Time `json:"dueDate" gorm:"not null;uniqueIndex:idx_automation_overdue_run"`
+	CreatedAt time.Time `json:"createdAt"`
+	RuleID    string    `json:"ruleId" gorm:"not null;uniqueIndex:idx_automation_overdue_run"`
+	TaskID    string    `json:"taskId" gorm:"not null;uniqueIndex:idx_automation_overdue_run"`
+
+	// Relations
+	Rule AutomationRule `json:"-" gorm:"foreignKey:RuleID;constraint:OnDelete:CASCADE"`
+	Task Task           `json:"-" gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE"`
+}
+
+func (r *AutomationRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = generateID()
+	}
+	return nil
+}
+
+func (r *AutomationOverdueRun) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = generateID()
+	}
+	return nil
+}
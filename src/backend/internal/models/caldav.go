@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CalDAVResource モデル（CalDAVクライアントが指定したリソース名とイベントの対応）
+//
+// 対応がないイベントは "<イベントID>.ics" として公開する。
+type CalDAVResource struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Calendar  string    `json:"calendar" gorm:"not null;uniqueIndex:idx_caldav_resource"`
+	Name      string    `json:"name" gorm:"not null;uniqueIndex:idx_caldav_resource"`
+	CreatedAt time.Time `json:"createdAt"`
+	EventID   string    `json:"eventId" gorm:"not null;uniqueIndex"`
+
+	// Relations
+	Event Event `json:"-" gorm:"foreignKey:EventID;constraint:OnDelete:CASCADE"`
+}
+
+func (r *CalDAVResource) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = generateID()
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CalendarConnection モデル（OAuthで連携した外部カレンダーアカウントと認証情報）
+type CalendarConnection struct {
+	ID             string           `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Provider       CalendarProvider `json:"provider" gorm:"not null;uniqueIndex:idx_calendar_connection"`
+	AccountID      string           `json:"-" gorm:"not null;uniqueIndex:idx_calendar_connection"`
+	AccountEmail   string           `json:"accountEmail"`
+	AccessToken    string           `json:"-"`
+	RefreshToken   string           `json:"-"`
+	TokenExpiresAt time.Time        `json:"-"`
+	SyncToken      string           `json:"-"` // 差分取得用のトークン（プロバイダーごとの形式）
+	LastFullSyncAt *time.Time       `json:"lastFullSyncAt"`
+	LastSyncedAt   *time.Time       `json:"lastSyncedAt"`
+	LastError      string           `json:"lastError"`
+	CreatedAt      time.Time        `json:"createdAt"`
+	UpdatedAt      time.Time        `json:"updatedAt"`
+	UserID         string           `json:"userId" gorm:"not null;uniqueIndex:idx_calendar_connection"`
+
+	// Relations
+	Events []Event `json:"-" gorm:"foreignKey:ConnectionID;constraint:OnDelete:CASCADE"`
+}
+
+type CalendarProvider string
+
+const (
+	CalendarProviderMicrosoft CalendarProvider = "MICROSOFT"
+)
+
+func (cc *CalendarConnection) BeforeCreate(tx *gorm.DB) error {
+	if cc.ID == "" {
+		cc.ID = generateID()
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidColor 色が #RRGGBB 形式ではない
+var ErrInvalidColor = errors.New("色は#RRGGBB形式で指定してください")
+
+// EventCategory モデル（チームで管理するイベントのカテゴリーと表示色）
+type EventCategory struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Name      string    `json:"name" gorm:"not null;uniqueIndex:idx_event_categories_team_name"`
+	Color     string    `json:"color" gorm:"type:varchar(7);not null"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	TeamID    string    `json:"teamId" gorm:"not null;uniqueIndex:idx_event_categories_team_name"`
+}
+
+func (c *EventCategory) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = generateID()
+	}
+	return nil
+}
+
+func (c *EventCategory) BeforeSave(tx *gorm.DB) error {
+	color, err := NormalizeColor(c.Color)
+	if err != nil {
+		return err
+	}
+	if color == "" {
+		return ErrInvalidColor
+	}
+	c.Color = color
+	return nil
+}
+
+// NormalizeColor 色を小文字の #rrggbb にそろえる（#rgb も受け付ける、空文字は未設定）
+func NormalizeColor(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if color == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(color, "#") {
+		color = "#" + color
+	}
+	hex := color[1:]
+	if len(hex) != 3 && len(hex) != 6 {
+		return "", fmt.Errorf("%w: %s", ErrInvalidColor, color)
+	}
+	for _, r := range hex {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return "", fmt.Errorf("%w: %s", ErrInvalidColor, color)
+		}
+	}
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	return "#" + hex, nil
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ChangeWatermark モデル（範囲ごとの最後の変更日時。一覧の If-Modified-Since の判定に使う）
+//
+// Scope は「種類:対象」の形式（calendar:user:<ユーザーID>、calendar:team:<チームID>、notifications:user:<ユーザーID> など）。
+// 対象の分からない一括更新・削除は「種類:*」に記録し、その種類のすべての一覧を変更ありとみなす。
+type ChangeWatermark struct {
+	Scope     string    `json:"scope" gorm:"primaryKey;type:varchar(96)"`
+	ChangedAt time.Time `json:"changedAt" gorm:"not null"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ChatProvider 通知を投稿するチャットサービス
+type ChatProvider string
+
+const (
+	ChatProviderSlack   ChatProvider = "SLACK"
+	ChatProviderTeams   ChatProvider = "TEAMS"
+	ChatProviderDiscord ChatProvider = "DISCORD"
+)
+
+// TeamChatChannel モデル（チームの通知を投稿するチャットのチャンネル、チャットサービスごとに1つ）
+type TeamChatChannel struct {
+	ID          string       `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Provider    ChatProvider `json:"provider" gorm:"type:varchar(16);not null;uniqueIndex:idx_team_chat_channel"`
+	ChannelID   string       `json:"channelId" gorm:"not null;index"` // Slack: チャンネルID（C0123456789 など）
+	ChannelName string       `json:"channelName"`
+	WebhookURL  string       `json:"-"` // Microsoft Teams・Discord: チャンネルのWebhookのURL（投稿の認証を兼ねるため返さない）
+	// 投稿する内容
+	NotifyTaskCreated    bool      `json:"notifyTaskCreated"`
+	NotifyTaskStatus     bool      `json:"notifyTaskStatus"`
+	NotifyTaskDueSoon    bool      `json:"notifyTaskDueSoon" gorm:"not null;default:false"`
+	NotifyUpcomingEvents bool      `json:"notifyUpcomingEvents"`
+	CreatedAt            time.Time `json:"createdAt"`
+	UpdatedAt            time.Time `json:"updatedAt"`
+	TeamID               string    `json:"teamId" gorm:"not null;uniqueIndex:idx_team_chat_channel"`
+	CreatorID            string    `json:"creatorId" gorm:"not null"`
+
+	// Relations
+	Team Team `json:"-" gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE"`
+}
+
+// ChatEventPost モデル（チャットに投稿した開始前のイベントの回。再起動後の二重投稿を防ぐ）
+type ChatEventPost struct {
+	ID           string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	RecurrenceID time.Time `json:"recurrenceId" gorm:"not null;uniqueIndex:idx_chat_event_post"`
+	PostedAt     time.Time `json:"postedAt"`
+	ChannelID    string    `json:"channelId" gorm:"not null;uniqueIndex:idx_chat_event_post"` // TeamChatChannel.ID
+	EventID      string    `json:"eventId" gorm:"not null;uniqueIndex:idx_chat_event_post"`
+
+	// Relations
+	// TeamChatChannel にも ChannelID があるため、belongsTo を指定しないと逆向きの has one と解釈される
+	Channel TeamChatChannel `json:"-" gorm:"belongsTo:TeamChatChannel;foreignKey:ChannelID;references:ID;constraint:OnDelete:CASCADE"`
+	Event   Event           `json:"-" gorm:"foreignKey:EventID;constraint:OnDelete:CASCADE"`
+}
+
+// ChatTaskPost モデル（チャットに投稿した期限が近いタスク。期限を変更した場合は再び投稿する）
+type ChatTaskPost struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	DueDate   time.Time `json:"dueDate" gorm:"not null;uniqueIndex:idx_chat_task_post"`
+	PostedAt  time.Time `json:"postedAt"`
+	ChannelID string    `json:"channelId" gorm:"not null;uniqueIndex:idx_chat_task_post"` // TeamChatChannel.ID
+	TaskID    string    `json:"taskId" gorm:"not null;uniqueIndex:idx_chat_task_post"`
+
+	// Relations
+	// ChatEventPost.Channel と同じく belongsTo を指定する
+	Channel TeamChatChannel `json:"-" gorm:"belongsTo:TeamChatChannel;foreignKey:ChannelID;references:ID;constraint:OnDelete:CASCADE"`
+	Task    Task            `json:"-" gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE"`
+}
+
+func (c *TeamChatChannel) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == "" {
+		c.ID = generateID()
+	}
+	return nil
+}
+
+func (p *ChatEventPost) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = generateID()
+	}
+	return nil
+}
+
+func (p *ChatTaskPost) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = generateID()
+	}
+	return nil
+}
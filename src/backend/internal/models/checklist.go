@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ChecklistItem モデル
+type ChecklistItem struct {
+	ID            string         `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Content       string         `json:"content" gorm:"not null"`
+	Position      int            `json:"position" gorm:"default:0"`
+	IsCompleted   bool           `json:"isCompleted" gorm:"default:false"`
+	CompletedAt   *time.Time     `json:"completedAt"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+	DeletedAt     gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"` // 削除した日時（保存期間を過ぎるまで残し、復元できる）
+	TaskID        string         `json:"taskId" gorm:"not null;index"`
+	CompletedByID *string        `json:"completedById"`
+}
+
+// TaskOccurrence モデル（繰り返しタスク各回のチェックリスト完了統計）
+type TaskOccurrence struct {
+	ID             string     `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	DueDate        time.Time  `json:"dueDate" gorm:"not null"`
+	Status         TaskStatus `json:"status"`
+	TotalItems     int        `json:"totalItems"`
+	CompletedItems int        `json:"completedItems"`
+	ResetAt        time.Time  `json:"resetAt"`
+	TaskID         string     `json:"taskId" gorm:"not null;index"`
+}
+
+// TaskRecurrence 繰り返しタスクの周期
+type TaskRecurrence string
+
+const (
+	TaskRecurrenceDaily   TaskRecurrence = "DAILY"
+	TaskRecurrenceWeekly  TaskRecurrence = "WEEKLY"
+	TaskRecurrenceMonthly TaskRecurrence = "MONTHLY"
+	TaskRecurrenceYearly  TaskRecurrence = "YEARLY"
+)
+
+// Next 指定日時の次の発生日時を返す
+func (r TaskRecurrence) Next(from time.Time) (time.Time, bool) {
+	switch r {
+	case TaskRecurrenceDaily:
+		return from.AddDate(0, 0, 1), true
+	case TaskRecurrenceWeekly:
+		return from.AddDate(0, 0, 7), true
+	case TaskRecurrenceMonthly:
+		return from.AddDate(0, 1, 0), true
+	case TaskRecurrenceYearly:
+		return from.AddDate(1, 0, 0), true
+	}
+	return from, false
+}
+
+func (ci *ChecklistItem) BeforeCreate(tx *gorm.DB) error {
+	if ci.ID == "" {
+		ci.ID = generateID()
+	}
+	return nil
+}
+
+func (to *TaskOccurrence) BeforeCreate(tx *gorm.DB) error {
+	if to.ID == "" {
+		to.ID = generateID()
+	}
+	return nil
+}
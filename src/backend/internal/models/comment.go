@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CommentRevision モデル（コメント編集前の内容）
+type CommentRevision struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Content    string    `json:"content" gorm:"not null"`
+	CreatedAt  time.Time `json:"createdAt"`
+	CommentID  string    `json:"commentId" gorm:"not null;index"`
+	EditedByID string    `json:"editedById" gorm:"not null"`
+
+	// Relations
+	EditedBy User `json:"editedBy" gorm:"foreignKey:EditedByID"`
+}
+
+// CommentReaction モデル（コメントへの絵文字リアクション）
+type CommentReaction struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Emoji     string    `json:"emoji" gorm:"not null;uniqueIndex:idx_comment_reaction"`
+	CreatedAt time.Time `json:"createdAt"`
+	CommentID string    `json:"commentId" gorm:"not null;uniqueIndex:idx_comment_reaction"`
+	UserID    string    `json:"userId" gorm:"not null;uniqueIndex:idx_comment_reaction"`
+}
+
+// ReactionSummary 絵文字ごとのリアクション集計
+type ReactionSummary struct {
+	Emoji   string   `json:"emoji"`
+	Count   int      `json:"count"`
+	UserIDs []string `json:"userIds"`
+}
+
+// MentionBroadcastPolicy @team・@here（チーム全員へのメンション）を使えるメンバー
+type MentionBroadcastPolicy string
+
+const (
+	MentionBroadcastPolicyMembers  MentionBroadcastPolicy = "MEMBERS"  // メンバー全員
+	MentionBroadcastPolicyAdmins   MentionBroadcastPolicy = "ADMINS"   // オーナーと管理者のみ
+	MentionBroadcastPolicyDisabled MentionBroadcastPolicy = "DISABLED" // 使えない
+)
+
+// CommentMention モデル（コメント内の@メンション）
+type CommentMention struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	CreatedAt time.Time `json:"createdAt"`
+	CommentID string    `json:"commentId" gorm:"not null;uniqueIndex:idx_comment_mention"`
+	UserID    string    `json:"userId" gorm:"not null;uniqueIndex:idx_comment_mention"`
+
+	// Relations
+	User User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+func (cr *CommentRevision) BeforeCreate(tx *gorm.DB) error {
+	if cr.ID == "" {
+		cr.ID = generateID()
+	}
+	return nil
+}
+
+func (cr *CommentReaction) BeforeCreate(tx *gorm.DB) error {
+	if cr.ID == "" {
+		cr.ID = generateID()
+	}
+	return nil
+}
+
+func (cm *CommentMention) BeforeCreate(tx *gorm.DB) error {
+	if cm.ID == "" {
+		cm.ID = generateID()
+	}
+	return nil
+}
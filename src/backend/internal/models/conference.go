@@ -0,0 +1,10 @@
+package models
+
+// ConferenceProvider ビデオ会議サービス
+type ConferenceProvider string
+
+const (
+	ConferenceProviderZoom       ConferenceProvider = "ZOOM"
+	ConferenceProviderGoogleMeet ConferenceProvider = "GOOGLE_MEET"
+	ConferenceProviderNone       ConferenceProvider = "NONE" // 会議リンクを削除し、自動作成しない
+)
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// CronJobState モデル（定期実行のジョブごとの実行の記録。名前ごとに1件で、複数のサーバーで共有する）
+//
+// 停止中に予定の日時を過ぎたジョブを起動後に実行するため、次に実行する予定の日時を記録する。
+type CronJobState struct {
+	Name            string     `json:"name" gorm:"primaryKey;type:varchar(128)"`
+	Schedule        string     `json:"schedule" gorm:"type:varchar(64);not null"` // @every 5m などの実行の間隔
+	Paused          bool       `json:"paused" gorm:"not null;default:false"`      // 一時停止中は予定の日時を迎えても実行しない（手動では実行できる）
+	LastStartedAt   *time.Time `json:"lastStartedAt"`
+	LastFinishedAt  *time.Time `json:"lastFinishedAt"`
+	LastSucceededAt *time.Time `json:"lastSucceededAt"` // 最後に成功した実行を開始した日時
+	LastError       string     `json:"lastError,omitempty"`
+	LastDurationMs  int64      `json:"lastDurationMs"`
+	NextRunAt       *time.Time `json:"nextRunAt"` // 最後の実行の後、次に実行する予定の日時
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// DailyAgendaSetting モデル（毎朝のアジェンダメールの配信設定、ユーザーごと。時刻は User.TimeZone の壁時計時刻）
+type DailyAgendaSetting struct {
+	UserID     string    `json:"-" gorm:"primaryKey;type:varchar(25)"`
+	Enabled    bool      `json:"enabled" gorm:"not null"`
+	SendMinute int       `json:"sendMinute" gorm:"not null"`                   // 送信する時刻（0時からの分）
+	LastSentOn string    `json:"lastSentOn,omitempty" gorm:"type:varchar(10)"` // 最後に送った日（ユーザーのタイムゾーンの YYYY-MM-DD）
+	UpdatedAt  time.Time `json:"updatedAt"`
+
+	// Relations
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
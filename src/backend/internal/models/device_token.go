@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DevicePlatform プッシュ通知を受け取る端末の種類
+type DevicePlatform string
+
+const (
+	DevicePlatformIOS     DevicePlatform = "IOS"     // APNs
+	DevicePlatformAndroid DevicePlatform = "ANDROID" // FCM
+)
+
+// DeviceToken モデル（モバイルアプリが登録したプッシュ通知の宛先）
+type DeviceToken struct {
+	ID         string         `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Platform   DevicePlatform `json:"platform" gorm:"type:varchar(16);not null"`
+	Token      string         `json:"-" gorm:"not null;uniqueIndex"` // 端末を特定できるため返さない
+	DeviceName string         `json:"deviceName"`
+	LastSeenAt time.Time      `json:"lastSeenAt"` // 最後に登録し直した日時
+	CreatedAt  time.Time      `json:"createdAt"`
+	UserID     string         `json:"userId" gorm:"not null;index"`
+
+	// Relations
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (d *DeviceToken) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = generateID()
+	}
+	return nil
+}
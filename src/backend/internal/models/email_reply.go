@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// EmailReplyToken モデル（通知メールへの返信をコメントに変換するためのトークン）
+type EmailReplyToken struct {
+	Token     string    `json:"-" gorm:"primaryKey;type:varchar(64)"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt" gorm:"not null;index"`
+	UserID    string    `json:"userId" gorm:"not null"`
+	TaskID    string    `json:"taskId" gorm:"not null"`
+}
+
+// EventReplyToken モデル（招待メールへの出欠回答（iMIP REPLY）を受け付けるためのトークン）
+type EventReplyToken struct {
+	Token     string    `json:"-" gorm:"primaryKey;type:varchar(64)"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt" gorm:"not null;index"`
+	UserID    string    `json:"userId" gorm:"not null"`
+	EventID   string    `json:"eventId" gorm:"not null;index"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EventException モデル（繰り返しイベントの特定の回の変更・キャンセル）
+type EventException struct {
+	ID           string     `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	RecurrenceID time.Time  `json:"recurrenceId" gorm:"not null;uniqueIndex:idx_event_exception"` // ルール上の本来の開始日時
+	IsCancelled  bool       `json:"isCancelled" gorm:"default:false"`
+	Title        *string    `json:"title"`
+	Description  *string    `json:"description"`
+	StartDate    *time.Time `json:"startDate"`
+	EndDate      *time.Time `json:"endDate"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+	EventID      string     `json:"eventId" gorm:"not null;uniqueIndex:idx_event_exception"`
+}
+
+func (ee *EventException) BeforeCreate(tx *gorm.DB) error {
+	if ee.ID == "" {
+		ee.ID = generateID()
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EventPoll モデル（日程調整の投票。主催者が候補の日時を挙げ、招待者が投票し、確定するとイベントになる）
+type EventPoll struct {
+	ID          string          `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Title       string          `json:"title" gorm:"not null"`
+	Description string          `json:"description"`
+	Location    string          `json:"location"`
+	TimeZone    string          `json:"timeZone" gorm:"type:varchar(64)"` // 確定したイベントのタイムゾーン
+	Status      EventPollStatus `json:"status" gorm:"type:varchar(16);default:'OPEN';index"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	CreatorID   string          `json:"creatorId" gorm:"not null;index"`
+	TeamID      *string         `json:"teamId"`
+	OptionID    *string         `json:"optionId"` // 確定した候補
+	EventID     *string         `json:"eventId"`  // 確定して作成したイベント
+
+	// Relations
+	Creator  User               `json:"creator" gorm:"foreignKey:CreatorID"`
+	Options  []EventPollOption  `json:"options,omitempty" gorm:"foreignKey:PollID;constraint:OnDelete:CASCADE"`
+	Invitees []EventPollInvitee `json:"invitees,omitempty" gorm:"foreignKey:PollID;constraint:OnDelete:CASCADE"`
+}
+
+// EventPollStatus 日程調整の状態
+type EventPollStatus string
+
+const (
+	EventPollStatusOpen      EventPollStatus = "OPEN"
+	EventPollStatusConfirmed EventPollStatus = "CONFIRMED"
+)
+
+// EventPollOption モデル（日程調整の候補の日時）
+type EventPollOption struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	StartDate time.Time `json:"startDate" gorm:"not null"`
+	EndDate   time.Time `json:"endDate" gorm:"not null"`
+	PollID    string    `json:"pollId" gorm:"not null;index"`
+
+	// 集計（取得時に計算する）
+	YesCount      int `json:"yesCount" gorm:"-"`
+	IfNeededCount int `json:"ifNeededCount" gorm:"-"`
+
+	// Relations
+	Votes []EventPollVote `json:"votes" gorm:"foreignKey:OptionID;constraint:OnDelete:CASCADE"`
+}
+
+// EventPollInvitee モデル（日程調整に招待したユーザー）
+type EventPollInvitee struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	CreatedAt time.Time `json:"createdAt"`
+	PollID    string    `json:"pollId" gorm:"not null;uniqueIndex:idx_event_poll_invitee"`
+	UserID    string    `json:"userId" gorm:"not null;uniqueIndex:idx_event_poll_invitee"`
+
+	// Relations
+	User User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+// EventPollVote モデル（候補ごとの投票）
+type EventPollVote struct {
+	ID        string       `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Response  PollResponse `json:"response" gorm:"type:varchar(16);not null"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+	PollID    string       `json:"pollId" gorm:"not null;index"`
+	OptionID  string       `json:"optionId" gorm:"not null;uniqueIndex:idx_event_poll_vote"`
+	UserID    string       `json:"userId" gorm:"not null;uniqueIndex:idx_event_poll_vote"`
+}
+
+// PollResponse 候補への回答
+type PollResponse string
+
+const (
+	PollResponseYes      PollResponse = "YES"
+	PollResponseIfNeeded PollResponse = "IF_NEEDED" // 都合をつければ参加できる
+	PollResponseNo       PollResponse = "NO"
+)
+
+// Valid 定義済みの回答か
+func (r PollResponse) Valid() bool {
+	switch r {
+	case PollResponseYes, PollResponseIfNeeded, PollResponseNo:
+		return true
+	}
+	return false
+}
+
+func (p *EventPoll) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = generateID()
+	}
+	return nil
+}
+
+func (o *EventPollOption) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == "" {
+		o.ID = generateID()
+	}
+	return nil
+}
+
+func (i *EventPollInvitee) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = generateID()
+	}
+	return nil
+}
+
+func (v *EventPollVote) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == "" {
+		v.ID = generateID()
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+package models
+
+// EventStatus イベントの状態
+type EventStatus string
+
+const (
+	EventStatusConfirmed EventStatus = "CONFIRMED"
+	// EventStatusCancelled キャンセル済み（削除せずに残し、予定の重複や空き状況の判定からは除く）
+	EventStatusCancelled EventStatus = "CANCELLED"
+)
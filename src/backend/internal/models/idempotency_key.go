@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// IdempotencyKey モデル（Idempotency-Key をつけたリクエストと、その応答。再送されたリクエストには保存した応答を返す）
+type IdempotencyKey struct {
+	ID          string     `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	UserID      string     `json:"userId" gorm:"not null;uniqueIndex:idx_idempotency_user_key"`
+	Key         string     `json:"key" gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_user_key"`
+	Fingerprint string     `json:"-" gorm:"type:varchar(64);not null"` // メソッド・パス・本文のハッシュ（別のリクエストでの使い回しを検出する）
+	StatusCode  int        `json:"statusCode"`                         // 0は処理中
+	ContentType string     `json:"-"`
+	Response    []byte     `json:"-"`
+	CreatedAt   time.Time  `json:"createdAt" gorm:"index"`
+	CompletedAt *time.Time `json:"completedAt"`
+}
+
+func (k *IdempotencyKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == "" {
+		k.ID = generateID()
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JobType 非同期ジョブの種類
+type JobType string
+
+const (
+	JobTypeEventExport JobType = "events.export" // イベントのiCalendar形式でのエクスポート
+	JobTypeEventImport JobType = "events.import" // iCalendarファイルからのイベントのインポート
+	JobTypeTaskMerge   JobType = "tasks.merge"   // 重複タスクの統合
+
+	// 運営者が登録するバックアップ・復元
+	JobTypeBackupExport  JobType = "backup.export"  // チームまたはサーバー全体のデータのアーカイブの作成
+	JobTypeBackupRestore JobType = "backup.restore" // アーカイブからのデータの復元
+
+	// 以下は利用者が登録するのではなく、サーバーが後から実行する処理（UserID は空）
+	JobTypeEmailSend JobType = "email.send" // メールの送信
+	JobTypeChatPost  JobType = "chat.post"  // Slackなどのチャンネルへの投稿
+	// 保存した変更の通知（担当者の設定など）の配信。変更と同じトランザクションで登録し、コミットされた変更のみ通知する
+	JobTypeNotificationRoute JobType = "notification.route"
+	// タスクの保存をきっかけにした自動化のルールの実行。保存と同じトランザクションで登録し、コミットされた保存のみ実行する
+	JobTypeAutomationRun JobType = "automation.run"
+)
+
+// JobStatus 非同期ジョブの状態
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "PENDING"
+	JobStatusRunning   JobStatus = "RUNNING"
+	JobStatusSucceeded JobStatus = "SUCCEEDED"
+	JobStatusFailed    JobStatus = "FAILED"
+)
+
+// Job モデル（時間のかかる処理や外部への送信を後から実行する非同期ジョブ）
+//
+// 結果はJSON（Result）か、署名付きURLでダウンロードするファイル（ResultKey）のどちらかで返す。
+// 失敗した場合は MaxAttempts 回まで、間隔を空けて（RunAt を延ばして）実行し直す。
+type Job struct {
+	ID                string      `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Type              JobType     `json:"type" gorm:"type:varchar(32);not null"`
+	Status            JobStatus   `json:"status" gorm:"type:varchar(16);not null;index"`
+	Progress          int         `json:"progress" gorm:"not null;default:0"` // 0〜100
+	Params            string      `json:"-" gorm:"type:text;not null"`        // 実行時の引数（JSON）
+	InputKey          string      `json:"-"`                                  // アップロードされたファイルのストレージキー
+	Result            interface{} `json:"result,omitempty" gorm:"serializer:json;type:text"`
+	ResultKey         string      `json:"-"` // 結果のファイルのストレージキー
+	ResultName        string      `json:"resultName,omitempty"`
+	ResultContentType string      `json:"-"`
+	DownloadURL       string      `json:"downloadUrl,omitempty" gorm:"-"`        // 結果のファイルの署名付きURL（取得のたびに発行する）
+	Error             string      `json:"error,omitempty"`                       // 失敗の理由（再実行を待っている場合は直前の失敗の理由）
+	Attempts          int         `json:"attempts" gorm:"not null;default:0"`    // 実行した回数
+	MaxAttempts       int         `json:"maxAttempts" gorm:"not null;default:1"` // 実行する最大の回数（失敗した場合はこの回数まで実行し直す）
+	RunAt             time.Time   `json:"runAt" gorm:"not null;index"`           // 次に実行する日時（再実行は失敗するたびに延ばす）
+	CreatedAt         time.Time   `json:"createdAt"`
+	StartedAt         *time.Time  `json:"startedAt"`
+	CompletedAt       *time.Time  `json:"completedAt"`
+	UserID            string      `json:"userId" gorm:"not null;index"` // 登録した利用者（サーバーが登録したジョブは空）
+}
+
+func (j *Job) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == "" {
+		j.ID = generateID()
+	}
+	if j.MaxAttempts == 0 {
+		j.MaxAttempts = 1
+	}
+	if j.RunAt.IsZero() {
+		j.RunAt = time.Now()
+	}
+	return nil
+}
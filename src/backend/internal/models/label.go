@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Label モデル（チームで共有するラベル。イベント・タスクのタグとして使う）
+type Label struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Name      string    `json:"name" gorm:"not null;uniqueIndex:idx_labels_team_name"`
+	Color     string    `json:"color,omitempty" gorm:"type:varchar(7)"` // 表示色（#rrggbb、空は未設定）
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	TeamID    string    `json:"teamId" gorm:"not null;uniqueIndex:idx_labels_team_name"`
+}
+
+func (l *Label) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == "" {
+		l.ID = generateID()
+	}
+	return nil
+}
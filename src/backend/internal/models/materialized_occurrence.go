@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// MaterializedOccurrence モデル（繰り返しイベントを展開した回の索引）
+//
+// 期間での検索やリマインダーで繰り返しルールを毎回展開しないよう、バックグラウンドのジョブが
+// 現在の前後の一定期間について作成する。作成した期間と元にしたイベントの内容は Event.Occurrences* に記録する。
+type MaterializedOccurrence struct {
+	EventID      string    `json:"eventId" gorm:"primaryKey;type:varchar(25);index:idx_materialized_occurrence_range,priority:1"`
+	RecurrenceID time.Time `json:"recurrenceId" gorm:"primaryKey"` // ルール上の本来の開始日時
+	StartDate    time.Time `json:"startDate" gorm:"not null;index:idx_materialized_occurrence_range,priority:2"`
+	EndDate      time.Time `json:"endDate" gorm:"not null"`
+	IsOverride   bool      `json:"isOverride" gorm:"not null;default:false"`
+	Title        *string   `json:"title"` // 回ごとの変更（nilはイベントの値）
+	Description  *string   `json:"description"`
+}
@@ -3,19 +3,25 @@ package models
 import (
 	"time"
 
+	"task-calendar-backend/internal/recurrence"
+
 	"gorm.io/gorm"
 )
 
 // User モデル
 type User struct {
 	ID        string `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	WorkspaceID string `json:"workspaceId" gorm:"type:varchar(25);not null;default:'default';index"` // 所属するワークスペース（1人1つ）
 	Email     string `json:"email" gorm:"unique;not null"`
 	Username  string `json:"username" gorm:"unique;not null"`
 	Password  string `json:"-" gorm:"not null"`
 	FirstName string `json:"firstName" gorm:"not null"`
 	LastName  string `json:"lastName" gorm:"not null"`
 	Avatar    string `json:"avatar"`
+	AvatarKey string `json:"-" gorm:"type:varchar(255)"` // アップロードしたアバター画像のストレージのキー（URLを直接指定した場合は空）
 	Role      UserRole `json:"role" gorm:"default:'MEMBER'"`
+	TimeZone  string `json:"timeZone" gorm:"type:varchar(64)"` // 勤務時間などの基準となるIANAタイムゾーン（空はUTC）
+	HolidayCountry string `json:"holidayCountry" gorm:"type:varchar(2)"` // 勤務時間から除く祝日の国（ISO 3166-1 alpha-2、空は除かない）
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 
@@ -26,6 +32,7 @@ type User struct {
 	CreatedTasks    []Task       `json:"createdTasks" gorm:"foreignKey:CreatorID"`
 	Events          []Event      `json:"events" gorm:"foreignKey:CreatorID"`
 	Comments        []Comment    `json:"comments" gorm:"foreignKey:AuthorID"`
+	WorkingHours    []WorkingHours `json:"workingHours,omitempty" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 }
 
 type UserRole string
@@ -39,11 +46,16 @@ const (
 // Team モデル
 type Team struct {
 	ID          string `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	WorkspaceID string `json:"workspaceId" gorm:"type:varchar(25);not null;default:'default';index"` // 作成者のワークスペース
 	Name        string `json:"name" gorm:"not null"`
 	Description string `json:"description"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
 	CreatorID   string `json:"creatorId" gorm:"not null"`
+	BlockEventConflicts bool `json:"blockEventConflicts" gorm:"default:false"` // 参加者の予定と重複するイベントの保存を禁止する
+	ConferenceProvider  ConferenceProvider `json:"conferenceProvider" gorm:"default:''"` // MEETINGイベントに自動で作成するビデオ会議（空は作成しない）
+	HolidayCountry      string `json:"holidayCountry" gorm:"type:varchar(2)"` // チームのカレンダーに表示する祝日の国（ISO 3166-1 alpha-2、空は表示しない）
+	MentionBroadcastPolicy MentionBroadcastPolicy `json:"mentionBroadcastPolicy" gorm:"type:varchar(16);default:'ADMINS'"` // @team・@here を使えるメンバー
 
 	// Relations
 	Creator User         `json:"creator" gorm:"foreignKey:CreatorID"`
@@ -85,22 +97,39 @@ const (
 // Task モデル
 type Task struct {
 	ID          string `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	WorkspaceID string `json:"workspaceId" gorm:"type:varchar(25);not null;default:'default';index"` // チームのワークスペース
 	Title       string `json:"title" gorm:"not null"`
 	Description string `json:"description"`
 	Status      TaskStatus `json:"status" gorm:"default:'TODO'"`
 	Priority    Priority `json:"priority" gorm:"default:'MEDIUM'"`
 	DueDate     *time.Time `json:"dueDate"`
+	IsRecurring bool   `json:"isRecurring" gorm:"default:false"`
+	Recurrence  TaskRecurrence `json:"recurrence"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
+	Version     int    `json:"version" gorm:"not null;default:1"` // 更新のたびに増える（古いバージョンに対する更新は拒否する）
+	DeletedAt   gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"` // 削除した日時（保存期間を過ぎるまで残し、復元できる）
+	ArchivedAt  *time.Time `json:"archivedAt,omitempty" gorm:"index"` // 保存期間のルールでアーカイブした日時（アジェンダ・差分同期の全件の取得には含めない）
 	TeamID      string `json:"teamId" gorm:"not null"`
 	CreatorID   string `json:"creatorId" gorm:"not null"`
 	AssigneeID  *string `json:"assigneeId"`
+	DuplicateOfID *string `json:"duplicateOfId"`
+
+	loadedAssigneeID *string // 読み込んだ時点の担当者（担当者の変更の通知に使う）
+	loadedStatus     TaskStatus // 読み込んだ時点のステータス（ステータスの変更の通知に使う）
+	loadedDescription string    // 読み込んだ時点の説明（新たに追加されたメンションの通知に使う）
+	loadedVersion    int        // 読み込んだ時点のバージョン（楽観的排他制御に使う）
+	checkedVersion   int        // 更新で比べているバージョン
 
 	// Relations
 	Team     Team      `json:"team" gorm:"foreignKey:TeamID"`
 	Creator  User      `json:"creator" gorm:"foreignKey:CreatorID"`
 	Assignee *User     `json:"assignee" gorm:"foreignKey:AssigneeID"`
-	Comments []Comment `json:"comments" gorm:"foreignKey:TaskID"`
+	Comments []Comment `json:"-" gorm:"foreignKey:TaskID"` // GET /api/tasks/:id/comments で取得
+	PinnedComments []Comment   `json:"pinnedComments" gorm:"foreignKey:TaskID"`
+	ChecklistItems []ChecklistItem `json:"checklistItems" gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE"`
+	Watchers       []TaskWatcher   `json:"watchers,omitempty" gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE"`
+	Labels         []Label         `json:"labels,omitempty" gorm:"many2many:task_labels;constraint:OnDelete:CASCADE"` // タグ（タスクと同じチームのラベル）
 }
 
 type TaskStatus string
@@ -125,21 +154,61 @@ const (
 // Event モデル
 type Event struct {
 	ID          string `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	WorkspaceID string `json:"workspaceId" gorm:"type:varchar(25);not null;default:'default';index"` // チームのイベントはチーム、それ以外は作成者のワークスペース
 	Title       string `json:"title" gorm:"not null"`
 	Description string `json:"description"`
 	StartDate   time.Time `json:"startDate" gorm:"not null"`
 	EndDate     time.Time `json:"endDate" gorm:"not null"`
+	AllDay      bool   `json:"allDay" gorm:"default:false"` // 終日イベント（StartDate/EndDateはUTC 0時の日付、EndDateは排他的）
+	TimeZone    string `json:"timeZone" gorm:"type:varchar(64)"` // 作成時のIANAタイムゾーン（繰り返しの展開に使う、空はUTC）
 	IsRecurring bool   `json:"isRecurring" gorm:"default:false"`
 	Recurrence  string `json:"recurrence"`
 	Type        EventType `json:"type" gorm:"default:'MEETING'"`
+	Location    string `json:"location"`
+	RoomID      *string `json:"roomId" gorm:"index"` // 予約した会議室（同じ時間帯に重複して予約できない）
+	BufferBeforeMinutes int `json:"bufferBeforeMinutes" gorm:"default:0"` // 開始前の移動・準備時間（空き状況と重複の判定で予定ありとして扱う）
+	BufferAfterMinutes  int `json:"bufferAfterMinutes" gorm:"default:0"`  // 終了後の移動・片付け時間
+	Capacity    *int    `json:"capacity,omitempty"` // 出席できる参加者数の上限（作成者を除く、nilは無制限）。超えた出席の回答はキャンセル待ちになる
+	ConferenceProvider ConferenceProvider `json:"conferenceProvider,omitempty" gorm:"default:''"`
+	ConferenceURL      string     `json:"conferenceUrl,omitempty"`
+	ConferenceID       string     `json:"-"` // ビデオ会議サービス側のID
+	ConferenceStart    *time.Time `json:"-"` // ビデオ会議サービスに登録した日時（イベントの日時変更の検出に使う）
+	ConferenceEnd      *time.Time `json:"-"`
+	Color       string  `json:"color,omitempty" gorm:"type:varchar(7)"` // 表示色（#rrggbb、空はカテゴリーの色）
+	CategoryID  *string `json:"categoryId,omitempty" gorm:"index"` // チームのカテゴリー（チームのイベントのみ）
+	Visibility  EventVisibility `json:"visibility" gorm:"type:varchar(16);default:'TEAM'"`
+	Status      EventStatus `json:"status" gorm:"type:varchar(16);default:'CONFIRMED';index"`
+	CancelledAt *time.Time  `json:"cancelledAt,omitempty"`
+	CancellationReason string `json:"cancellationReason,omitempty"`
+	Sequence    int         `json:"-" gorm:"default:0"` // iCalendarのSEQUENCE（招待メールで送った内容を変更するたびに増やす）
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
+	Version     int       `json:"version" gorm:"not null;default:1"` // 更新のたびに増える（古いバージョンに対する更新は拒否する）
+	DeletedAt   gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"` // 削除した日時（保存期間を過ぎるまで残し、復元できる）
 	TeamID      *string `json:"teamId"`
 	CreatorID   string `json:"creatorId" gorm:"not null"`
+	ICalUID     string `json:"icalUid,omitempty" gorm:"column:ical_uid;index"` // 外部カレンダー由来のUID
+	SubscriptionID *string `json:"subscriptionId,omitempty" gorm:"index"` // 外部カレンダーから取り込んだ場合のみ（読み取り専用）
+	ConnectionID *string `json:"connectionId,omitempty" gorm:"index"` // 連携したOutlook等から同期した場合のみ（読み取り専用）
+	ExternalID   string  `json:"-" gorm:"index"` // 連携先サービスでのイベントID
+	OccurrencesFrom    *time.Time `json:"-"` // 回の索引（MaterializedOccurrence）を作成した期間（終日イベントはUTCの日付）
+	OccurrencesUntil   *time.Time `json:"-"`
+	OccurrencesVersion string     `json:"-" gorm:"type:varchar(64)"` // 索引の元にした日時・繰り返し・回ごとの変更の要約（変わった場合は索引を使わない）
+
+	loadedVersion  int // 読み込んだ時点のバージョン（楽観的排他制御に使う）
+	checkedVersion int // 更新で比べているバージョン
 
 	// Relations
-	Team    *Team `json:"team" gorm:"foreignKey:TeamID"`
-	Creator User  `json:"creator" gorm:"foreignKey:CreatorID"`
+	Team       *Team            `json:"team" gorm:"foreignKey:TeamID"`
+	Creator    User             `json:"creator" gorm:"foreignKey:CreatorID"`
+	Room       *Room            `json:"room,omitempty" gorm:"foreignKey:RoomID;constraint:OnDelete:SET NULL"`
+	Category   *EventCategory   `json:"category,omitempty" gorm:"foreignKey:CategoryID;constraint:OnDelete:SET NULL"`
+	Labels     []Label          `json:"labels,omitempty" gorm:"many2many:event_labels;constraint:OnDelete:CASCADE"` // タグ（イベントと同じチームのラベル）
+	Exceptions []EventException `json:"exceptions,omitempty" gorm:"foreignKey:EventID;constraint:OnDelete:CASCADE"`
+	Attendees  []EventAttendee  `json:"attendees,omitempty" gorm:"foreignKey:EventID;constraint:OnDelete:CASCADE"`
+	Reminders  []EventReminder  `json:"-" gorm:"foreignKey:EventID;constraint:OnDelete:CASCADE"`
+	CheckIns   []EventCheckIn   `json:"-" gorm:"foreignKey:EventID;constraint:OnDelete:CASCADE"`
+	Occurrences []MaterializedOccurrence `json:"-" gorm:"foreignKey:EventID;constraint:OnDelete:CASCADE"`
 }
 
 type EventType string
@@ -149,26 +218,46 @@ const (
 	EventTypeDeadline EventType = "DEADLINE"
 	EventTypeReminder EventType = "REMINDER"
 	EventTypePersonal EventType = "PERSONAL"
+	// 不在（作成者の空き状況をその期間すべて予定ありにする。終日の場合は作成時のタイムゾーンの日付）
+	EventTypeOutOfOffice EventType = "OUT_OF_OFFICE"
 )
 
 // Comment モデル
 type Comment struct {
 	ID        string `json:"id" gorm:"primaryKey;type:varchar(25)"`
 	Content   string `json:"content" gorm:"not null"`
+	ContentHTML string `json:"contentHtml" gorm:"type:text"`
+	IsEdited  bool   `json:"isEdited" gorm:"default:false"`
+	EditedAt  *time.Time `json:"editedAt"`
+	IsPinned   bool       `json:"isPinned" gorm:"default:false"`
+	PinnedAt   *time.Time `json:"pinnedAt"`
+	PinnedByID *string    `json:"pinnedById"`
+	IsHidden   bool       `json:"isHidden" gorm:"default:false"`
 	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt"` // 編集・ピン留め・非表示などの最後の変更日時（差分同期に使う。追加前のコメントは空）
+	DeletedAt gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"` // 削除した日時（保存期間を過ぎるまで残し、復元できる）
 	TaskID    string `json:"taskId" gorm:"not null"`
 	AuthorID  string `json:"authorId" gorm:"not null"`
 
 	// Relations
-	Task   Task `json:"task" gorm:"foreignKey:TaskID"`
-	Author User `json:"author" gorm:"foreignKey:AuthorID"`
+	Task      Task              `json:"task" gorm:"foreignKey:TaskID"`
+	Author    User              `json:"author" gorm:"foreignKey:AuthorID"`
+	Revisions []CommentRevision `json:"revisions,omitempty" gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE"`
+	Attachments []Attachment    `json:"attachments,omitempty" gorm:"foreignKey:CommentID;constraint:OnDelete:SET NULL"`
+	Mentions    []CommentMention `json:"mentions,omitempty" gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE"`
+
+	// 集計値（DBには保存しない）
+	Reactions []ReactionSummary `json:"reactions" gorm:"-"`
 }
 
-// BeforeCreate フック - ID生成
+// BeforeCreate フック - ID生成、ワークスペースの決定（指定がなければ作成者・チームと同じにする）
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == "" {
 		u.ID = generateID()
 	}
+	if u.WorkspaceID == "" {
+		u.WorkspaceID = DefaultWorkspaceID
+	}
 	return nil
 }
 
@@ -176,20 +265,38 @@ func (t *Team) BeforeCreate(tx *gorm.DB) error {
 	if t.ID == "" {
 		t.ID = generateID()
 	}
+	if t.WorkspaceID == "" {
+		workspaceID, err := workspaceOf(tx, &User{}, t.CreatorID)
+		if err != nil {
+			return err
+		}
+		t.WorkspaceID = workspaceID
+	}
 	return nil
 }
 
+// BeforeCreate フック - ID生成、別のワークスペースのユーザーの追加を拒否
 func (tm *TeamMember) BeforeCreate(tx *gorm.DB) error {
 	if tm.ID == "" {
 		tm.ID = generateID()
 	}
-	return nil
+	return ensureSameWorkspace(tx, tm.UserID, &Team{}, tm.TeamID)
 }
 
 func (t *Task) BeforeCreate(tx *gorm.DB) error {
 	if t.ID == "" {
 		t.ID = generateID()
 	}
+	if t.Version == 0 {
+		t.Version = 1
+	}
+	if t.WorkspaceID == "" {
+		workspaceID, err := workspaceOf(tx, &Team{}, t.TeamID)
+		if err != nil {
+			return err
+		}
+		t.WorkspaceID = workspaceID
+	}
 	return nil
 }
 
@@ -197,9 +304,65 @@ func (e *Event) BeforeCreate(tx *gorm.DB) error {
 	if e.ID == "" {
 		e.ID = generateID()
 	}
+	if e.Version == 0 {
+		e.Version = 1
+	}
+	if e.WorkspaceID == "" {
+		var workspaceID string
+		var err error
+		if e.TeamID != nil {
+			workspaceID, err = workspaceOf(tx, &Team{}, *e.TeamID)
+		} else {
+			workspaceID, err = workspaceOf(tx, &User{}, e.CreatorID)
+		}
+		if err != nil {
+			return err
+		}
+		e.WorkspaceID = workspaceID
+	}
 	return nil
 }
 
+// BeforeSave フック - 読み取り専用イベントの保護、タイムゾーンの検証、表示色の正規化、公開範囲・状態の既定値、終日イベントの日付の正規化、繰り返しルールの検証
+func (e *Event) BeforeSave(tx *gorm.DB) error {
+	if err := ensureEventWritable(tx, e); err != nil {
+		return err
+	}
+	if _, err := LoadLocation(e.TimeZone); err != nil {
+		return err
+	}
+	color, err := NormalizeColor(e.Color)
+	if err != nil {
+		return err
+	}
+	e.Color = color
+	if e.Visibility == "" {
+		e.Visibility = EventVisibilityTeam
+	}
+	if e.Status == "" {
+		e.Status = EventStatusConfirmed
+	}
+	if e.AllDay {
+		e.StartDate, e.EndDate = NormalizeAllDayRange(e.StartDate, e.EndDate)
+	}
+	if e.IsRecurring && e.Recurrence != "" {
+		if _, err := recurrence.Parse(e.Recurrence); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsExternal 外部カレンダー（ICS購読・カレンダー連携）から取り込んだ読み取り専用イベントか
+func (e *Event) IsExternal() bool {
+	return e.SubscriptionID != nil || e.ConnectionID != nil
+}
+
+// BeforeDelete フック - 読み取り専用イベントの保護
+func (e *Event) BeforeDelete(tx *gorm.DB) error {
+	return ensureEventWritable(tx, e)
+}
+
 func (c *Comment) BeforeCreate(tx *gorm.DB) error {
 	if c.ID == "" {
 		c.ID = generateID()
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CommentReport モデル（コメントの通報）
+type CommentReport struct {
+	ID           string            `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Reason       string            `json:"reason" gorm:"not null"`
+	Content      string            `json:"content"` // 通報時点のコメント本文
+	Status       ReportStatus      `json:"status" gorm:"default:'OPEN';index"`
+	Action       *ModerationAction `json:"action"`
+	Note         string            `json:"note"`
+	CreatedAt    time.Time         `json:"createdAt"`
+	ResolvedAt   *time.Time        `json:"resolvedAt"`
+	TaskID       string            `json:"taskId" gorm:"not null"`
+	CommentID    string            `json:"commentId" gorm:"not null;uniqueIndex:idx_comment_reporter"`
+	AuthorID     string            `json:"authorId" gorm:"not null"`
+	ReporterID   string            `json:"reporterId" gorm:"not null;uniqueIndex:idx_comment_reporter"`
+	ResolvedByID *string           `json:"resolvedById"`
+
+	// Relations
+	Reporter User `json:"reporter" gorm:"foreignKey:ReporterID"`
+	Author   User `json:"author" gorm:"foreignKey:AuthorID"`
+}
+
+type ReportStatus string
+
+const (
+	ReportStatusOpen     ReportStatus = "OPEN"
+	ReportStatusResolved ReportStatus = "RESOLVED"
+)
+
+type ModerationAction string
+
+const (
+	ModerationActionHide    ModerationAction = "HIDE"
+	ModerationActionDelete  ModerationAction = "DELETE"
+	ModerationActionWarn    ModerationAction = "WARN"
+	ModerationActionDismiss ModerationAction = "DISMISS"
+)
+
+// UserWarning モデル（モデレーターからの警告）
+type UserWarning struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UserID     string    `json:"userId" gorm:"not null;index"`
+	IssuedByID string    `json:"issuedById" gorm:"not null"`
+	ReportID   *string   `json:"reportId"`
+}
+
+func (cr *CommentReport) BeforeCreate(tx *gorm.DB) error {
+	if cr.ID == "" {
+		cr.ID = generateID()
+	}
+	return nil
+}
+
+func (uw *UserWarning) BeforeCreate(tx *gorm.DB) error {
+	if uw.ID == "" {
+		uw.ID = generateID()
+	}
+	return nil
+}
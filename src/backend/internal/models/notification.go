@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationTypeTaskAssigned タスクの担当者になった通知の種別
+const NotificationTypeTaskAssigned = "TASK_ASSIGNED"
+
+// Notification モデル（アプリ内の通知センターに表示する通知）
+type Notification struct {
+	ID         string     `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Type       string     `json:"type" gorm:"type:varchar(64);not null"`
+	Title      string     `json:"title" gorm:"not null"`
+	Body       string     `json:"body"`
+	EntityType string     `json:"entityType,omitempty" gorm:"type:varchar(32)"` // 通知の対象（task / event など）
+	EntityID   string     `json:"entityId,omitempty" gorm:"type:varchar(25)"`
+	ReadAt     *time.Time `json:"readAt"`
+	CreatedAt  time.Time  `json:"createdAt" gorm:"index:idx_notifications_user_created,priority:2"`
+	UserID     string     `json:"userId" gorm:"not null;index:idx_notifications_user_created,priority:1"`
+}
+
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == "" {
+		n.ID = generateID()
+	}
+	return nil
+}
+
+// AfterFind フック - 担当者・ステータス・説明の変更の検出と楽観的排他制御のため、読み込んだ時点の値を覚えておく
+func (t *Task) AfterFind(tx *gorm.DB) error {
+	t.loadedAssigneeID = t.AssigneeID
+	t.loadedStatus = t.Status
+	t.loadedDescription = t.Description
+	t.loadedVersion = t.Version
+	return nil
+}
+
+// LoadedStatus 読み込んだ時点（保存後は保存した時点）のステータス（新規作成の場合は空）
+//
+// 保存の前後で比べられるよう、AfterSave フックより前に呼ぶ。
+func (t *Task) LoadedStatus() TaskStatus {
+	return t.loadedStatus
+}
+
+// LoadedDescription 読み込んだ時点（保存後は保存した時点）の説明（新規作成の場合は空）
+//
+// 保存の前後で比べられるよう、AfterSave フックより前に呼ぶ。
+func (t *Task) LoadedDescription() string {
+	return t.loadedDescription
+}
+
+// NewAssigneeID 読み込んだ時点（新規作成の場合は作成時）から新たに設定された担当者（通知しない場合は空）
+//
+// 自分で作成して自分を担当者にした場合は通知しない。保存の前後で比べられるよう、AfterSave フックより前に呼ぶ。
+func (t *Task) NewAssigneeID() string {
+	assignee := t.AssigneeID
+	previous := t.loadedAssigneeID
+	if assignee == nil || *assignee == "" || (previous != nil && *previous == *assignee) {
+		return ""
+	}
+	if previous == nil && *assignee == t.CreatorID {
+		return ""
+	}
+	return *assignee
+}
+
+// AfterSave フック - 次の保存での変更を検出できるよう、保存した時点の値（バージョンを含む）を覚えておく
+func (t *Task) AfterSave(tx *gorm.DB) error {
+	t.loadedAssigneeID = t.AssigneeID
+	t.loadedStatus = t.Status
+	t.loadedDescription = t.Description
+	t.loadedVersion = t.Version
+	return nil
+}
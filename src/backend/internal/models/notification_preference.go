@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationChannel 通知の配信チャネル
+type NotificationChannel string
+
+const (
+	NotificationChannelInApp   NotificationChannel = "IN_APP"
+	NotificationChannelEmail   NotificationChannel = "EMAIL"
+	NotificationChannelPush    NotificationChannel = "PUSH"
+	NotificationChannelWebhook NotificationChannel = "WEBHOOK"
+)
+
+// NotificationPreference モデル（ユーザーが通知種別・チャネルごとに設定した配信の有無。チームの設定より優先する）
+type NotificationPreference struct {
+	ID        string              `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Type      string              `json:"type" gorm:"type:varchar(64);not null;uniqueIndex:idx_notification_preference"`
+	Channel   NotificationChannel `json:"channel" gorm:"type:varchar(16);not null;uniqueIndex:idx_notification_preference"`
+	Enabled   bool                `json:"enabled" gorm:"not null"`
+	UpdatedAt time.Time           `json:"updatedAt"`
+	UserID    string              `json:"userId" gorm:"not null;uniqueIndex:idx_notification_preference"`
+
+	// Relations
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (p *NotificationPreference) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = generateID()
+	}
+	return nil
+}
+
+// TeamNotificationPreference モデル（チームのタスク・イベントに関する通知の、チームの既定の配信の有無）
+type TeamNotificationPreference struct {
+	ID        string              `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Type      string              `json:"type" gorm:"type:varchar(64);not null;uniqueIndex:idx_team_notification_preference"`
+	Channel   NotificationChannel `json:"channel" gorm:"type:varchar(16);not null;uniqueIndex:idx_team_notification_preference"`
+	Enabled   bool                `json:"enabled" gorm:"not null"`
+	UpdatedAt time.Time           `json:"updatedAt"`
+	TeamID    string              `json:"teamId" gorm:"not null;uniqueIndex:idx_team_notification_preference"`
+
+	// Relations
+	Team Team `json:"-" gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE"`
+}
+
+func (p *TeamNotificationPreference) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = generateID()
+	}
+	return nil
+}
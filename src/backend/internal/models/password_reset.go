@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PasswordResetToken モデル（パスワード再設定メールで送ったトークン）
+//
+// トークン自体は保存せず、SHA-256のハッシュ値のみを保存する。一度使うか期限を過ぎると使えない。
+type PasswordResetToken struct {
+	ID        string     `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	TokenHash string     `json:"-" gorm:"type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expiresAt" gorm:"not null"`
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UserID    string     `json:"userId" gorm:"not null;index"`
+
+	// Relations
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (t *PasswordResetToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = generateID()
+	}
+	return nil
+}
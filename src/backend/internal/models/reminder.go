@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EventReminder モデル（イベント開始の何分前に通知するか、ユーザーごと）
+type EventReminder struct {
+	ID            string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	MinutesBefore int       `json:"minutesBefore" gorm:"not null;uniqueIndex:idx_event_reminder"`
+	CreatedAt     time.Time `json:"createdAt"`
+	EventID       string    `json:"eventId" gorm:"not null;uniqueIndex:idx_event_reminder"`
+	UserID        string    `json:"userId" gorm:"not null;uniqueIndex:idx_event_reminder"`
+
+	// Relations
+	Deliveries []ReminderDelivery `json:"-" gorm:"foreignKey:ReminderID;constraint:OnDelete:CASCADE"`
+}
+
+// ReminderDelivery モデル（リマインダーの送信記録。再起動後の二重送信・送信漏れを防ぐ）
+type ReminderDelivery struct {
+	ID           string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	RecurrenceID time.Time `json:"recurrenceId" gorm:"not null;uniqueIndex:idx_reminder_delivery"` // 対象の回
+	FireAt       time.Time `json:"fireAt" gorm:"not null"`
+	SentAt       time.Time `json:"sentAt"`
+	ReminderID   string    `json:"reminderId" gorm:"not null;uniqueIndex:idx_reminder_delivery"`
+}
+
+func (r *EventReminder) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = generateID()
+	}
+	return nil
+}
+
+func (d *ReminderDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = generateID()
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Room モデル（チームで予約できる会議室などの設備）
+type Room struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Name        string    `json:"name" gorm:"not null"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`                         // 建物・フロアなど
+	Capacity    int       `json:"capacity" gorm:"default:0"`        // 0は上限なし
+	TimeZone    string    `json:"timeZone" gorm:"type:varchar(64)"` // 終日の予約を判定するタイムゾーン（空はUTC）
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	TeamID      string    `json:"teamId" gorm:"not null;index"`
+}
+
+func (r *Room) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = generateID()
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SharedCalendarLink モデル（アカウントのない人に読み取り専用でカレンダーを公開するURLのトークン）
+//
+// TeamID を指定した場合はチームのカレンダー、指定しない場合は作成者自身のカレンダー（作成・参加する予定）を公開する。
+// 非公開（PRIVATE）のイベントは含めない。削除するとURLは使えなくなる。
+type SharedCalendarLink struct {
+	ID             string     `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Token          string     `json:"token" gorm:"type:varchar(64);uniqueIndex;not null"`
+	Name           string     `json:"name"`
+	ExpiresAt      *time.Time `json:"expiresAt"`
+	LastAccessedAt *time.Time `json:"lastAccessedAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	CreatorID      string     `json:"creatorId" gorm:"not null;index"`
+	TeamID         *string    `json:"teamId" gorm:"index"`
+
+	// Relations
+	Team *Team `json:"team,omitempty" gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE"`
+}
+
+func (l *SharedCalendarLink) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == "" {
+		l.ID = generateID()
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrReadOnlyEvent 外部カレンダーから取り込んだイベントは同期処理以外で変更できない
+var ErrReadOnlyEvent = errors.New("外部カレンダーのイベントは編集できません")
+
+// CalendarSubscription モデル（購読している外部ICSフィード）
+type CalendarSubscription struct {
+	ID            string     `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Name          string     `json:"name" gorm:"not null"`
+	URL           string     `json:"url" gorm:"not null"`
+	ETag          string     `json:"-"`
+	LastModified  string     `json:"-"`
+	LastFetchedAt *time.Time `json:"lastFetchedAt"`
+	LastError     string     `json:"lastError"`
+	EventCount    int        `json:"eventCount" gorm:"default:0"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+	UserID        string     `json:"userId" gorm:"not null"`
+	TeamID        *string    `json:"teamId"`
+
+	// Relations
+	Events []Event `json:"-" gorm:"foreignKey:SubscriptionID;constraint:OnDelete:CASCADE"`
+}
+
+func (cs *CalendarSubscription) BeforeCreate(tx *gorm.DB) error {
+	if cs.ID == "" {
+		cs.ID = generateID()
+	}
+	return nil
+}
+
+type externalSyncKey struct{}
+
+// ExternalSync 外部カレンダーの同期処理として読み取り専用イベントの変更を許可したDBを返す
+func ExternalSync(db *gorm.DB) *gorm.DB {
+	return db.WithContext(context.WithValue(db.Statement.Context, externalSyncKey{}, true))
+}
+
+func ensureEventWritable(tx *gorm.DB, e *Event) error {
+	if !e.IsExternal() {
+		return nil
+	}
+	if sync, _ := tx.Statement.Context.Value(externalSyncKey{}).(bool); sync {
+		return nil
+	}
+	return ErrReadOnlyEvent
+}
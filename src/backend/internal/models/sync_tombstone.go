@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SyncEntityType 差分同期で返すものの種類
+type SyncEntityType string
+
+const (
+	SyncEntityTask    SyncEntityType = "task"
+	SyncEntityEvent   SyncEntityType = "event"
+	SyncEntityComment SyncEntityType = "comment"
+)
+
+// SyncTombstone モデル（削除したタスク・イベント・コメントの記録。差分同期で削除を伝えるために一定期間残す）
+//
+// 削除を伝える相手は TeamID（チームのメンバー）と UserID（個人の予定の作成者、招待を取り消された参加者）。
+// 同じものについて相手ごとに複数の記録ができることがある。
+type SyncTombstone struct {
+	ID         string         `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	EntityType SyncEntityType `json:"entityType" gorm:"type:varchar(16);not null"`
+	EntityID   string         `json:"entityId" gorm:"type:varchar(25);not null"`
+	TeamID     *string        `json:"teamId" gorm:"index"`
+	UserID     *string        `json:"userId" gorm:"index"`
+	DeletedAt  time.Time      `json:"deletedAt" gorm:"not null;index"`
+}
+
+func (t *SyncTombstone) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = generateID()
+	}
+	return nil
+}
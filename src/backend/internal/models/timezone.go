@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrInvalidTimeZone IANAタイムゾーン名として解釈できない
+var ErrInvalidTimeZone = errors.New("タイムゾーンが不正です")
+
+var locationCache sync.Map // タイムゾーン名 -> *time.Location
+
+// LoadLocation IANAタイムゾーン名（例: Asia/Tokyo）を読み込む（空文字はUTC）
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locationCache.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTimeZone, name)
+	}
+	locationCache.Store(name, loc)
+	return loc, nil
+}
+
+// TimeZoneLocation イベントが作成されたタイムゾーン（未設定・不正な場合はUTC）
+func (e *Event) TimeZoneLocation() *time.Location {
+	loc, err := LoadLocation(e.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// In イベントの日時を指定タイムゾーンで表したコピーを返す
+//
+// 終日イベントは日付が変わらないよう、同じ日付の指定タイムゾーンでの0時にする。
+func (e Event) In(loc *time.Location) Event {
+	e.StartDate = LocalTime(e.StartDate, e.AllDay, loc)
+	e.EndDate = LocalTime(e.EndDate, e.AllDay, loc)
+	return e
+}
+
+// LocalTime 日時を指定タイムゾーンで表す（終日の日付は同じ日付の0時にする）
+func LocalTime(t time.Time, allDay bool, loc *time.Location) time.Time {
+	if allDay {
+		u := t.UTC()
+		return time.Date(u.Year(), u.Month(), u.Day(), 0, 0, 0, 0, loc)
+	}
+	return t.In(loc)
+}
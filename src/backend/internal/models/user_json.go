@@ -0,0 +1,12 @@
+package models
+
+import "encoding/json"
+
+// MarshalJSON 読み込んでいない関連のユーザー（IDが空）は null にする
+func (u User) MarshalJSON() ([]byte, error) {
+	if u.ID == "" {
+		return []byte("null"), nil
+	}
+	type user User
+	return json.Marshal(user(u))
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrVersionConflict 編集を始めた後に他のユーザーが更新していた
+var ErrVersionConflict = errors.New("他のユーザーが先に更新しました。最新の内容を確認してください")
+
+// VersionConflictError 古いバージョンに対する更新を拒否した場合のエラー（最新の状態を含む）
+type VersionConflictError struct {
+	Current interface{} // *Task または *Event
+}
+
+func (e *VersionConflictError) Error() string {
+	return ErrVersionConflict.Error()
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return ErrVersionConflict
+}
+
+// errVersionInValues 更新する値に version を含めた（比べるバージョンは WithExpectedVersion で指定する）
+var errVersionInValues = errors.New("versionは更新する値に指定できません")
+
+type expectedVersionKey struct{}
+
+// WithExpectedVersion 更新で比べるバージョン（クライアントが編集を始めた時点のバージョン）を渡す
+func WithExpectedVersion(db *gorm.DB, version int) *gorm.DB {
+	return db.WithContext(context.WithValue(db.Statement.Context, expectedVersionKey{}, version))
+}
+
+// expectedVersion WithExpectedVersion で渡された比べるバージョン
+func expectedVersion(tx *gorm.DB) (int, bool) {
+	if tx.Statement.Context == nil {
+		return 0, false
+	}
+	version, ok := tx.Statement.Context.Value(expectedVersionKey{}).(int)
+	return version, ok && version > 0
+}
+
+// BeforeUpdate フック - バージョンによる楽観的排他制御
+func (t *Task) BeforeUpdate(tx *gorm.DB) (err error) {
+	t.checkedVersion, err = beginVersionedUpdate(tx, t.ID, &t.Version, t.loadedVersion)
+	return err
+}
+
+// AfterUpdate フック - 古いバージョンに対する更新だった場合は最新の状態とともにエラーを返す
+func (t *Task) AfterUpdate(tx *gorm.DB) error {
+	expected := t.checkedVersion
+	if expected == 0 {
+		return nil
+	}
+	t.checkedVersion = 0
+	var current Task
+	err := versionConflict(tx, t.ID, &current)
+	if err != nil {
+		t.Version = expected
+	}
+	return err
+}
+
+// AfterFind フック - 更新時に比べるため、読み込んだ時点のバージョンを覚えておく
+func (e *Event) AfterFind(tx *gorm.DB) error {
+	e.loadedVersion = e.Version
+	return nil
+}
+
+// BeforeUpdate フック - バージョンによる楽観的排他制御
+func (e *Event) BeforeUpdate(tx *gorm.DB) (err error) {
+	e.checkedVersion, err = beginVersionedUpdate(tx, e.ID, &e.Version, e.loadedVersion)
+	return err
+}
+
+// AfterUpdate フック - 古いバージョンに対する更新だった場合は最新の状態とともにエラーを返す
+func (e *Event) AfterUpdate(tx *gorm.DB) error {
+	expected := e.checkedVersion
+	if expected == 0 {
+		return nil
+	}
+	e.checkedVersion = 0
+	var current Event
+	err := versionConflict(tx, e.ID, &current)
+	if err != nil {
+		e.Version = expected
+	}
+	return err
+}
+
+// AfterSave フック - 次の保存で比べるバージョンを、保存したバージョンにする
+func (e *Event) AfterSave(tx *gorm.DB) error {
+	e.loadedVersion = e.Version
+	return nil
+}
+
+// beginVersionedUpdate 更新するレコードのバージョンを1つ進め、比べるバージョンと一致する場合のみ更新する条件を加える
+//
+// 比べるのは、WithExpectedVersion で渡したバージョン、構造体の Version（クライアントが編集を始めた時点のバージョンを入れる）、
+// 読み込んだ時点のバージョンの順。更新する値（map）の version は、クライアントの入力で比べる条件を変えられないようエラーにする。
+// IDの分からない一括更新はmapで更新する場合のみバージョンを進め、読み込まずに構造体で保存した場合はバージョンを変えない。
+// 比べる条件を加えた場合は比べるバージョンを返す。
+func beginVersionedUpdate(tx *gorm.DB, id string, version *int, loaded int) (int, error) {
+	values, isMap := tx.Statement.Dest.(map[string]interface{})
+	for _, key := range []string{"version", "Version"} {
+		if _, ok := values[key]; ok {
+			return 0, errVersionInValues
+		}
+	}
+	if id == "" {
+		if isMap {
+			tx.Statement.SetColumn("version", gorm.Expr("version + 1"))
+		}
+		return 0, nil
+	}
+
+	expected := loaded
+	if *version != 0 {
+		expected = *version
+	}
+	if v, ok := expectedVersion(tx); ok {
+		expected = v
+	}
+	if expected == 0 {
+		if isMap {
+			tx.Statement.SetColumn("version", gorm.Expr("version + 1"))
+		} else {
+			tx.Statement.Omit("version")
+		}
+		return 0, nil
+	}
+
+	tx.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "version"}, Value: expected},
+	}})
+	*version = expected + 1
+	tx.Statement.SetColumn("version", expected+1)
+	return expected, nil
+}
+
+// versionConflict 更新されなかった原因がバージョンの不一致であれば、最新の状態を current に読み込んでエラーを返す
+//
+// フックに渡される tx は新しいセッションのため、更新件数は実行中の Statement.DB から読む。
+func versionConflict(tx *gorm.DB, id string, current interface{}) error {
+	if updated := tx.Statement.DB; updated.Error != nil || updated.RowsAffected > 0 {
+		return nil
+	}
+	err := tx.First(current, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return &VersionConflictError{Current: current}
+}
@@ -0,0 +1,22 @@
+package models
+
+// EventVisibility イベントの公開範囲
+type EventVisibility string
+
+const (
+	// EventVisibilityPrivate 作成者と参加者のみ閲覧できる（他の人の空き状況には「予定あり」とだけ表示する）
+	EventVisibilityPrivate EventVisibility = "PRIVATE"
+	// EventVisibilityTeam 作成者・参加者とチームのメンバーが閲覧できる
+	EventVisibilityTeam EventVisibility = "TEAM"
+	// EventVisibilityPublic ログインしているすべてのユーザーが閲覧できる
+	EventVisibilityPublic EventVisibility = "PUBLIC"
+)
+
+// Valid 定義済みの公開範囲か
+func (v EventVisibility) Valid() bool {
+	switch v {
+	case EventVisibilityPrivate, EventVisibilityTeam, EventVisibilityPublic:
+		return true
+	}
+	return false
+}
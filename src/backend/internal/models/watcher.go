@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TaskWatcher モデル（タスクのウォッチャー）
+type TaskWatcher struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	CreatedAt time.Time `json:"createdAt"`
+	TaskID    string    `json:"taskId" gorm:"not null;uniqueIndex:idx_task_watcher"`
+	UserID    string    `json:"userId" gorm:"not null;uniqueIndex:idx_task_watcher"`
+
+	// Relations
+	User User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+func (tw *TaskWatcher) BeforeCreate(tx *gorm.DB) error {
+	if tw.ID == "" {
+		tw.ID = generateID()
+	}
+	return nil
+}
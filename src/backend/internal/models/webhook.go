@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookEndpoint モデル（変更・通知を送るWebhookの宛先）
+//
+// TeamID がある場合はチームのタスク・イベント・コメントの変更を、ない場合は作成者宛ての通知を送る。
+type WebhookEndpoint struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	URL        string    `json:"url" gorm:"not null"`
+	Secret     string    `json:"-" gorm:"not null"`                           // 署名の鍵（作成時のみ返す）
+	EventTypes []string  `json:"eventTypes" gorm:"serializer:json;type:text"` // 送るイベントの種類（空はすべて）
+	Active     bool      `json:"active" gorm:"not null;default:true"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+	TeamID     *string   `json:"teamId" gorm:"index"`
+	CreatorID  string    `json:"creatorId" gorm:"not null;index"`
+
+	// Relations
+	Team *Team `json:"-" gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE"`
+}
+
+// WebhookDeliveryStatus Webhookの配信の状態
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "SUCCEEDED"
+	WebhookDeliveryDead      WebhookDeliveryStatus = "DEAD" // 再試行の上限に達した（デッドレター）
+)
+
+// WebhookDelivery モデル（Webhookの配信。失敗した場合は間隔を空けて再試行する）
+type WebhookDelivery struct {
+	ID             string                `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	EventType      string                `json:"eventType" gorm:"type:varchar(64);not null"`
+	Payload        string                `json:"payload" gorm:"type:text;not null"`
+	Status         WebhookDeliveryStatus `json:"status" gorm:"type:varchar(16);not null;index:idx_webhook_delivery_due,priority:1"`
+	Attempts       int                   `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time             `json:"nextAttemptAt" gorm:"index:idx_webhook_delivery_due,priority:2"`
+	LastStatusCode int                   `json:"lastStatusCode,omitempty"`
+	LastError      string                `json:"lastError,omitempty"`
+	DeliveredAt    *time.Time            `json:"deliveredAt"`
+	CreatedAt      time.Time             `json:"createdAt" gorm:"index:idx_webhook_delivery_endpoint,priority:2"`
+	EndpointID     string                `json:"endpointId" gorm:"not null;index:idx_webhook_delivery_endpoint,priority:1"`
+	ReplayOfID     *string               `json:"replayOfId,omitempty" gorm:"type:varchar(25)"` // 再送した元の配信
+
+	// Relations
+	Endpoint WebhookEndpoint          `json:"-" gorm:"foreignKey:EndpointID;constraint:OnDelete:CASCADE"`
+	History  []WebhookDeliveryAttempt `json:"history,omitempty" gorm:"foreignKey:DeliveryID;constraint:OnDelete:CASCADE"`
+}
+
+// WebhookDeliveryAttempt モデル（Webhookの配信の試行ごとの結果）
+type WebhookDeliveryAttempt struct {
+	ID         string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	StatusCode int       `json:"statusCode"` // 応答がなかった場合は0（応答の本文は記録しない）
+	LatencyMs  int64     `json:"latencyMs"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	DeliveryID string    `json:"deliveryId" gorm:"not null;index"`
+}
+
+func (e *WebhookEndpoint) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = generateID()
+	}
+	return nil
+}
+
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = generateID()
+	}
+	return nil
+}
+
+func (a *WebhookDeliveryAttempt) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = generateID()
+	}
+	return nil
+}
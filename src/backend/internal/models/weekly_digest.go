@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// WeeklyDigestSetting モデル（週次ダイジェストメールの配信設定、ユーザーごと。設定がないユーザーには配信する）
+type WeeklyDigestSetting struct {
+	UserID       string    `json:"-" gorm:"primaryKey;type:varchar(25)"`
+	OptedOut     bool      `json:"optedOut" gorm:"not null;default:false"`
+	LastSentWeek string    `json:"lastSentWeek,omitempty" gorm:"type:varchar(8)"` // 最後に送った週（ユーザーのタイムゾーンのISO週 YYYY-Www）
+	UpdatedAt    time.Time `json:"updatedAt"`
+
+	// Relations
+	User User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
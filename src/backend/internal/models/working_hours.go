@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorkingHours モデル（ユーザーの曜日ごとの勤務時間。User.TimeZone の壁時計時刻）
+type WorkingHours struct {
+	ID          string       `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Weekday     time.Weekday `json:"weekday" gorm:"not null"`     // 0=日曜日
+	StartMinute int          `json:"startMinute" gorm:"not null"` // 0時からの分
+	EndMinute   int          `json:"endMinute" gorm:"not null"`
+	UserID      string       `json:"userId" gorm:"not null;index"`
+}
+
+func (w *WorkingHours) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = generateID()
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 既定のワークスペース（マルチテナントにする前のデータと、ワークスペースを指定せずに登録したユーザーが所属する）
+const DefaultWorkspaceID = "default"
+
+// ErrWorkspaceMismatch 別のワークスペースのユーザー・チーム・イベントを結びつけようとした
+var ErrWorkspaceMismatch = errors.New("別のワークスペースのユーザー・チームは指定できません")
+
+// Workspace モデル（テナント。組織ごとにデータを分ける）
+//
+// ユーザー・チーム・タスク・イベントが WorkspaceID を持ち、それ以外のデータはこれらを通じて所属する。
+// チーム・タスク・イベントの WorkspaceID は、作成時に作成者・チームから決める。
+type Workspace struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(25)"`
+	Name      string    `json:"name" gorm:"not null"`
+	Slug      string    `json:"slug" gorm:"type:varchar(64);not null;uniqueIndex"` // URLなどに使う英小文字・数字・ハイフンの識別子
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (w *Workspace) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = generateID()
+	}
+	return nil
+}
+
+// workspaceOf model のテーブルで id の行が所属するワークスペース（フックの中から使うため、新しいセッションで読む）
+func workspaceOf(tx *gorm.DB, model interface{}, id string) (string, error) {
+	var workspaceID string
+	err := tx.Session(&gorm.Session{NewDB: true}).Model(model).
+		Select("workspace_id").Where("id = ?", id).Limit(1).Scan(&workspaceID).Error
+	return workspaceID, err
+}
+
+// ensureSameWorkspace userID のユーザーが model の id の行と同じワークスペースに所属しているか確認する
+func ensureSameWorkspace(tx *gorm.DB, userID string, model interface{}, id string) error {
+	userWorkspace, err := workspaceOf(tx, &User{}, userID)
+	if err != nil {
+		return err
+	}
+	workspace, err := workspaceOf(tx, model, id)
+	if err != nil {
+		return err
+	}
+	if userWorkspace != "" && workspace != "" && userWorkspace != workspace {
+		return ErrWorkspaceMismatch
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Package ratelimit キー（ユーザー・IPアドレス）ごとのリクエスト数の制限
+//
+// 数はプロセスのメモリに保持するため、複数のサーバーで動かす場合はサーバーごとの制限になる。
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Quota キーの現在の制限の状況
+type Quota struct {
+	Limit     int       `json:"limit"`     // ウィンドウごとのリクエスト数の上限
+	Remaining int       `json:"remaining"` // 現在のウィンドウで残っているリクエスト数
+	Reset     time.Time `json:"reset"`     // 現在のウィンドウが終わり、数が戻る日時
+	Window    int       `json:"windowSeconds"`
+}
+
+type window struct {
+	start time.Time
+	count int
+}
+
+// Key リクエスト数を数えるキー（認証済みはユーザーごと、認証前はIPアドレスごと）
+func Key(userID, clientIP string) string {
+	if userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + clientIP
+}
+
+// Limiter 固定ウィンドウで、キーごとのリクエスト数を limit までに制限する
+type Limiter struct {
+	limit  int
+	period time.Duration
+
+	mu        sync.Mutex
+	windows   map[string]*window
+	lastSweep time.Time
+}
+
+// New period ごとに limit 回までリクエストを許可する（limit が0以下の場合は制限しない）
+func New(limit int, period time.Duration) *Limiter {
+	return &Limiter{limit: limit, period: period, windows: map[string]*window{}}
+}
+
+// Enabled 制限が有効か
+func (l *Limiter) Enabled() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit > 0 && l.period > 0
+}
+
+// SetLimits 上限とウィンドウの長さを変える（設定の再読み込み用。数えたリクエスト数は新しいウィンドウから数え直す）
+func (l *Limiter) SetLimits(limit int, period time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if limit == l.limit && period == l.period {
+		return
+	}
+	l.limit = limit
+	l.period = period
+	l.windows = map[string]*window{}
+}
+
+// Allow リクエストを1回数え、上限以内であれば true を返す
+func (l *Limiter) Allow(key string) (Quota, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+	w := l.current(key, now)
+	if w.count >= l.limit {
+		return l.quota(w), false
+	}
+	w.count++
+	return l.quota(w), true
+}
+
+// Peek リクエストを数えずに、キーの現在の状況を返す
+func (l *Limiter) Peek(key string) Quota {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.period {
+		w = &window{start: now.Truncate(l.period)}
+	}
+	return l.quota(w)
+}
+
+func (l *Limiter) current(key string, now time.Time) *window {
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.period {
+		w = &window{start: now.Truncate(l.period)}
+		l.windows[key] = w
+	}
+	return w
+}
+
+func (l *Limiter) quota(w *window) Quota {
+	remaining := l.limit - w.count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Quota{
+		Limit:     l.limit,
+		Remaining: remaining,
+		Reset:     w.start.Add(l.period),
+		Window:    int(l.period / time.Second),
+	}
+}
+
+// sweep 終わったウィンドウを消す（ウィンドウの長さごとに1回）
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.period {
+		return
+	}
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= l.period {
+			delete(l.windows, key)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,627 @@
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency RRULEのFREQ
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// 1周期あたりに一件も発生しない状態が続いた場合に展開を打ち切る周期数
+const maxEmptyPeriods = 1000
+
+var ErrInvalidRule = errors.New("繰り返しルールが不正です")
+
+// WeekdayNum BYDAYの要素（例: MO, 1MO, -1FR）
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int // 0は「すべての該当曜日」
+}
+
+// Rule RFC 5545 の RRULE
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []int
+	BySetPos   []int
+	WeekStart  time.Weekday
+
+	// UNTILが日付のみ・タイムゾーン指定なしの場合など、開始日時のタイムゾーンで解釈する値
+	untilDate  *civilDate
+	untilFloat *time.Time
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Parse RRULE文字列を解析する（"RRULE:" 接頭辞の有無、"DAILY" などの周期のみの旧形式に対応）
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "RRULE:"), "rrule:")
+	if s == "" {
+		return nil, fmt.Errorf("%w: 空のルールです", ErrInvalidRule)
+	}
+	if !strings.Contains(s, "=") {
+		s = "FREQ=" + s
+	}
+
+	r := &Rule{Interval: 1, WeekStart: time.Monday}
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidRule, part)
+		}
+		key = strings.ToUpper(strings.TrimSpace(key))
+		value = strings.ToUpper(strings.TrimSpace(value))
+
+		var err error
+		switch key {
+		case "FREQ":
+			switch Frequency(value) {
+			case Daily, Weekly, Monthly, Yearly:
+				r.Freq = Frequency(value)
+			default:
+				err = fmt.Errorf("未対応のFREQです: %s", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err == nil && r.Interval < 1 {
+				err = errors.New("INTERVALは1以上で指定してください")
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if err == nil && r.Count < 1 {
+				err = errors.New("COUNTは1以上で指定してください")
+			}
+		case "UNTIL":
+			err = r.parseUntil(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseIntList(value, -31, 31)
+		case "BYMONTH":
+			r.ByMonth, err = parseIntList(value, 1, 12)
+		case "BYSETPOS":
+			r.BySetPos, err = parseIntList(value, -366, 366)
+		case "WKST":
+			wd, ok := weekdayCodes[value]
+			if !ok {
+				err = fmt.Errorf("WKSTが不正です: %s", value)
+			}
+			r.WeekStart = wd
+		default:
+			err = fmt.Errorf("未対応の項目です: %s", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQは必須です", ErrInvalidRule)
+	}
+	if r.Count > 0 && (r.Until != nil || r.untilDate != nil || r.untilFloat != nil) {
+		return nil, fmt.Errorf("%w: COUNTとUNTILは同時に指定できません", ErrInvalidRule)
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return nil, fmt.Errorf("%w: WEEKLYではBYMONTHDAYを指定できません", ErrInvalidRule)
+	}
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return nil, fmt.Errorf("%w: 序数付きのBYDAYはMONTHLY/YEARLYでのみ指定できます", ErrInvalidRule)
+		}
+	}
+	return r, nil
+}
+
+func (r *Rule) parseUntil(value string) error {
+	switch {
+	case len(value) == 8:
+		t, err := time.Parse("20060102", value)
+		if err != nil {
+			return err
+		}
+		d := civilOf(t)
+		r.untilDate = &d
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return err
+		}
+		r.Until = &t
+	default:
+		t, err := time.Parse("20060102T150405", value)
+		if err != nil {
+			return err
+		}
+		r.untilFloat = &t
+	}
+	return nil
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var result []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) < 2 {
+			return nil, fmt.Errorf("BYDAYが不正です: %s", item)
+		}
+		wd, ok := weekdayCodes[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("BYDAYが不正です: %s", item)
+		}
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("BYDAYが不正です: %s", item)
+			}
+		}
+		result = append(result, WeekdayNum{Weekday: wd, N: n})
+	}
+	return result, nil
+}
+
+func parseIntList(value string, min, max int) ([]int, error) {
+	var result []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || n == 0 || n < min || n > max {
+			return nil, fmt.Errorf("値が不正です: %s", item)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+// String ルールをRRULE形式（"RRULE:"なし）で出力する
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	switch {
+	case r.Until != nil:
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	case r.untilDate != nil:
+		parts = append(parts, fmt.Sprintf("UNTIL=%04d%02d%02d", r.untilDate.year, r.untilDate.month, r.untilDate.day))
+	case r.untilFloat != nil:
+		parts = append(parts, "UNTIL="+r.untilFloat.Format("20060102T150405"))
+	}
+	if len(r.ByDay) > 0 {
+		items := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			code := weekdayCode(wd.Weekday)
+			if wd.N != 0 {
+				code = strconv.Itoa(wd.N) + code
+			}
+			items[i] = code
+		}
+		parts = append(parts, "BYDAY="+strings.Join(items, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCode(r.WeekStart))
+	}
+	return strings.Join(parts, ";")
+}
+
+func weekdayCode(wd time.Weekday) string {
+	for code, w := range weekdayCodes {
+		if w == wd {
+			return code
+		}
+	}
+	return ""
+}
+
+func joinInts(values []int) string {
+	items := make([]string, len(values))
+	for i, v := range values {
+		items[i] = strconv.Itoa(v)
+	}
+	return strings.Join(items, ",")
+}
+
+// Iterate 開始日時から順に発生日時を列挙する。fnがfalseを返すと終了する
+// 時刻は開始日時のタイムゾーンの壁時計で保持されるため、夏時間の切り替えをまたいでも同じ時刻になる
+func (r *Rule) Iterate(dtstart time.Time, fn func(time.Time) bool) {
+	loc := dtstart.Location()
+	until := r.untilFor(loc)
+	start := civilOf(dtstart)
+
+	emitted := 0
+	emit := func(t time.Time) bool {
+		if until != nil && t.After(*until) {
+			return false
+		}
+		if r.Count > 0 && emitted >= r.Count {
+			return false
+		}
+		emitted++
+		return fn(t)
+	}
+
+	// DTSTARTは常に最初の発生として扱う（RFC 5545）
+	if !emit(dtstart) {
+		return
+	}
+
+	empty := 0
+	for period := 0; ; period++ {
+		days := r.candidates(start, period)
+		found := false
+		for _, d := range days {
+			if !d.after(start) {
+				continue
+			}
+			found = true
+			t := time.Date(d.year, d.month, d.day, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Nanosecond(), loc)
+			if !emit(t) {
+				return
+			}
+		}
+		if found {
+			empty = 0
+		} else if empty++; empty > maxEmptyPeriods {
+			return
+		}
+	}
+}
+
+// Between 開始日時が [from, to) に含まれる発生日時を返す
+func (r *Rule) Between(dtstart, from, to time.Time) []time.Time {
+	var result []time.Time
+	r.Iterate(dtstart, func(t time.Time) bool {
+		if !t.Before(to) {
+			return false
+		}
+		if !t.Before(from) {
+			result = append(result, t)
+		}
+		return true
+	})
+	return result
+}
+
+func (r *Rule) untilFor(loc *time.Location) *time.Time {
+	switch {
+	case r.Until != nil:
+		return r.Until
+	case r.untilDate != nil:
+		t := time.Date(r.untilDate.year, r.untilDate.month, r.untilDate.day, 23, 59, 59, 999999999, loc)
+		return &t
+	case r.untilFloat != nil:
+		f := r.untilFloat
+		t := time.Date(f.Year(), f.Month(), f.Day(), f.Hour(), f.Minute(), f.Second(), 0, loc)
+		return &t
+	}
+	return nil
+}
+
+// candidates period番目の周期に含まれる発生日を昇順で返す
+func (r *Rule) candidates(start civilDate, period int) []civilDate {
+	var days []civilDate
+	switch r.Freq {
+	case Daily:
+		d := start.addDays(period * r.Interval)
+		if r.matchesMonth(d) && r.matchesMonthDay(d) && r.matchesWeekday(d) {
+			days = []civilDate{d}
+		}
+	case Weekly:
+		offset := (int(start.weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := start.addDays(-offset + period*r.Interval*7)
+		weekdays := []time.Weekday{start.weekday()}
+		if len(r.ByDay) > 0 {
+			weekdays = weekdays[:0]
+			for _, wd := range r.ByDay {
+				weekdays = append(weekdays, wd.Weekday)
+			}
+		}
+		for _, wd := range weekdays {
+			d := weekStart.addDays((int(wd) - int(r.WeekStart) + 7) % 7)
+			if r.matchesMonth(d) {
+				days = append(days, d)
+			}
+		}
+	case Monthly:
+		y, m := addMonths(start.year, start.month, period*r.Interval)
+		if r.matchesMonth(civilDate{year: y, month: m, day: 1}) {
+			days = r.daysInMonth(y, m, start)
+		}
+	case Yearly:
+		y := start.year + period*r.Interval
+		switch {
+		case len(r.ByMonth) > 0:
+			for _, m := range r.ByMonth {
+				days = append(days, r.daysInMonth(y, time.Month(m), start)...)
+			}
+		case len(r.ByMonthDay) > 0:
+			for m := time.January; m <= time.December; m++ {
+				days = append(days, r.daysInMonth(y, m, start)...)
+			}
+		case len(r.ByDay) > 0:
+			days = nthWeekdays(civilDate{year: y, month: time.January, day: 1}, daysIn(y), r.ByDay)
+		default:
+			if start.day <= daysInMonth(y, start.month) {
+				days = []civilDate{{year: y, month: start.month, day: start.day}}
+			}
+		}
+	}
+
+	sortDates(days)
+	days = dedupe(days)
+	return r.applySetPos(days)
+}
+
+// daysInMonth 月単位でBYMONTHDAY / BYDAYを展開する
+func (r *Rule) daysInMonth(y int, m time.Month, start civilDate) []civilDate {
+	n := daysInMonth(y, m)
+	first := civilDate{year: y, month: m, day: 1}
+
+	var byMonthDay []civilDate
+	for _, md := range r.ByMonthDay {
+		day := md
+		if md < 0 {
+			day = n + md + 1
+		}
+		if day >= 1 && day <= n {
+			byMonthDay = append(byMonthDay, civilDate{year: y, month: m, day: day})
+		}
+	}
+
+	switch {
+	case len(r.ByDay) > 0 && len(r.ByMonthDay) > 0:
+		var days []civilDate
+		for _, d := range byMonthDay {
+			if r.matchesWeekday(d) {
+				days = append(days, d)
+			}
+		}
+		return days
+	case len(r.ByDay) > 0:
+		return nthWeekdays(first, n, r.ByDay)
+	case len(r.ByMonthDay) > 0:
+		return byMonthDay
+	default:
+		// 存在しない日（2月30日など）はスキップする
+		if start.day <= n {
+			return []civilDate{{year: y, month: m, day: start.day}}
+		}
+		return nil
+	}
+}
+
+func (r *Rule) applySetPos(days []civilDate) []civilDate {
+	if len(r.BySetPos) == 0 || len(days) == 0 {
+		return days
+	}
+	var result []civilDate
+	for _, pos := range r.BySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(days) + pos
+		}
+		if i >= 0 && i < len(days) {
+			result = append(result, days[i])
+		}
+	}
+	sortDates(result)
+	return dedupe(result)
+}
+
+func (r *Rule) matchesMonth(d civilDate) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if time.Month(m) == d.month {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesMonthDay(d civilDate) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	n := daysInMonth(d.year, d.month)
+	for _, md := range r.ByMonthDay {
+		if md == d.day || (md < 0 && n+md+1 == d.day) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesWeekday(d civilDate) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	wd := d.weekday()
+	for _, bd := range r.ByDay {
+		if bd.Weekday == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// nthWeekdays first から n 日間の範囲で BYDAY（序数付きを含む）に該当する日を返す
+func nthWeekdays(first civilDate, n int, byDay []WeekdayNum) []civilDate {
+	var days []civilDate
+	for _, bd := range byDay {
+		var matches []civilDate
+		offset := (int(bd.Weekday) - int(first.weekday()) + 7) % 7
+		for i := offset; i < n; i += 7 {
+			matches = append(matches, first.addDays(i))
+		}
+		switch {
+		case bd.N == 0:
+			days = append(days, matches...)
+		case bd.N > 0 && bd.N <= len(matches):
+			days = append(days, matches[bd.N-1])
+		case bd.N < 0 && -bd.N <= len(matches):
+			days = append(days, matches[len(matches)+bd.N])
+		}
+	}
+	return days
+}
+
+// civilDate タイムゾーンに依存しない日付
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func civilOf(t time.Time) civilDate {
+	return civilDate{year: t.Year(), month: t.Month(), day: t.Day()}
+}
+
+func (d civilDate) toTime() time.Time {
+	return time.Date(d.year, d.month, d.day, 0, 0, 0, 0, time.UTC)
+}
+
+func (d civilDate) addDays(n int) civilDate {
+	return civilOf(d.toTime().AddDate(0, 0, n))
+}
+
+func (d civilDate) weekday() time.Weekday {
+	return d.toTime().Weekday()
+}
+
+func (d civilDate) after(o civilDate) bool {
+	return d.toTime().After(o.toTime())
+}
+
+func addMonths(y int, m time.Month, n int) (int, time.Month) {
+	total := y*12 + int(m) - 1 + n
+	return total / 12, time.Month(total%12 + 1)
+}
+
+func daysInMonth(y int, m time.Month) int {
+	return time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func daysIn(y int) int {
+	return time.Date(y, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+}
+
+func sortDates(days []civilDate) {
+	sort.Slice(days, func(i, j int) bool { return days[j].after(days[i]) })
+}
+
+func dedupe(days []civilDate) []civilDate {
+	if len(days) < 2 {
+		return days
+	}
+	result := days[:1]
+	for _, d := range days[1:] {
+		if d != result[len(result)-1] {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// WithUTCUntil UNTILを指定タイムゾーンで解釈したUTC日時に置き換えたコピーを返す
+//
+// RFC 5545ではDTSTARTがTZID付きの場合、UNTILはUTCで指定する必要がある。
+func (r *Rule) WithUTCUntil(loc *time.Location) *Rule {
+	copied := *r
+	if until := r.untilFor(loc); until != nil {
+		utc := until.UTC().Truncate(time.Second)
+		copied.Until = &utc
+		copied.untilDate = nil
+		copied.untilFloat = nil
+	}
+	return &copied
+}
+
+// WithDateUntil UNTILを日付（DATE）形式に置き換えたコピーを返す
+//
+// DTSTARTがDATE型（終日イベント）の場合、UNTILもDATE型で指定する必要がある。
+func (r *Rule) WithDateUntil(loc *time.Location) *Rule {
+	copied := *r
+	if until := r.untilFor(loc); until != nil {
+		d := civilOf(until.In(loc))
+		copied.Until = nil
+		copied.untilFloat = nil
+		copied.untilDate = &d
+	}
+	return &copied
+}
+
+// EndingBefore 指定日時より前の発生で終わるルールのコピーを返す（COUNTはUNTILに置き換える）
+//
+// 繰り返しを途中で分割するときに、分割前のシリーズに使う。
+func (r *Rule) EndingBefore(t time.Time) *Rule {
+	copied := *r
+	until := t.Add(-time.Second).UTC()
+	copied.Until = &until
+	copied.untilDate = nil
+	copied.untilFloat = nil
+	copied.Count = 0
+	return &copied
+}
+
+// ShiftDays 発生日をdays日ずらしたルールのコピーを返す
+//
+// BYDAYは曜日をずらす。序数付きのBYDAY・BYMONTHDAY・BYSETPOSを含むルールはずらせないためfalseを返す。
+func (r *Rule) ShiftDays(days int) (*Rule, bool) {
+	copied := *r
+	if days == 0 {
+		return &copied, true
+	}
+	if len(r.ByMonthDay) > 0 || len(r.BySetPos) > 0 {
+		return nil, false
+	}
+	copied.ByDay = make([]WeekdayNum, len(r.ByDay))
+	for i, wd := range r.ByDay {
+		if wd.N != 0 {
+			return nil, false
+		}
+		copied.ByDay[i] = WeekdayNum{Weekday: time.Weekday(((int(wd.Weekday)+days)%7 + 7) % 7)}
+	}
+	return &copied, true
+}
@@ -0,0 +1,221 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// date UTCの日時
+func date(y int, m time.Month, d, hour int) time.Time {
+	return time.Date(y, m, d, hour, 0, 0, 0, time.UTC)
+}
+
+// occurrences ルールを先頭から最大 limit 件展開する
+func occurrences(t *testing.T, rule string, dtstart time.Time, limit int) []time.Time {
+	t.Helper()
+	r, err := Parse(rule)
+	if err != nil {
+		t.Fatalf("%s の解析に失敗しました: %v", rule, err)
+	}
+	var result []time.Time
+	r.Iterate(dtstart, func(occ time.Time) bool {
+		result = append(result, occ)
+		return len(result) < limit
+	})
+	return result
+}
+
+func assertTimes(t *testing.T, got, want []time.Time) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("発生が %d 件です（%d 件のはず）: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("%d 件目が %v です（%v のはず）", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestIterate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		want    []time.Time
+	}{
+		{
+			name:    "序数付きのBYDAY（毎月最終金曜日）",
+			rule:    "FREQ=MONTHLY;COUNT=4;BYDAY=-1FR",
+			dtstart: date(2026, time.January, 30, 9),
+			want: []time.Time{
+				date(2026, time.January, 30, 9), date(2026, time.February, 27, 9),
+				date(2026, time.March, 27, 9), date(2026, time.April, 24, 9),
+			},
+		},
+		{
+			name:    "序数付きのBYDAY（毎月第2月曜日）",
+			rule:    "RRULE:FREQ=MONTHLY;COUNT=3;BYDAY=2MO",
+			dtstart: date(2026, time.January, 12, 9),
+			want: []time.Time{
+				date(2026, time.January, 12, 9), date(2026, time.February, 9, 9), date(2026, time.March, 9, 9),
+			},
+		},
+		{
+			name:    "BYMONTHDAY=31は31日のない月を飛ばす",
+			rule:    "FREQ=MONTHLY;COUNT=4;BYMONTHDAY=31",
+			dtstart: date(2026, time.January, 31, 9),
+			want: []time.Time{
+				date(2026, time.January, 31, 9), date(2026, time.March, 31, 9),
+				date(2026, time.May, 31, 9), date(2026, time.July, 31, 9),
+			},
+		},
+		{
+			name:    "BYMONTHDAY=-1は各月の末日",
+			rule:    "FREQ=MONTHLY;COUNT=3;BYMONTHDAY=-1",
+			dtstart: date(2026, time.January, 31, 9),
+			want: []time.Time{
+				date(2026, time.January, 31, 9), date(2026, time.February, 28, 9), date(2026, time.March, 31, 9),
+			},
+		},
+		{
+			name:    "BYSETPOS（毎月最後の平日）",
+			rule:    "FREQ=MONTHLY;COUNT=6;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			dtstart: date(2026, time.January, 30, 9),
+			want: []time.Time{
+				date(2026, time.January, 30, 9), date(2026, time.February, 27, 9), date(2026, time.March, 31, 9),
+				date(2026, time.April, 30, 9), date(2026, time.May, 29, 9), date(2026, time.June, 30, 9),
+			},
+		},
+		{
+			name:    "COUNTはDTSTARTを含めて数える",
+			rule:    "FREQ=DAILY;COUNT=3",
+			dtstart: date(2026, time.March, 1, 9),
+			want: []time.Time{
+				date(2026, time.March, 1, 9), date(2026, time.March, 2, 9), date(2026, time.March, 3, 9),
+			},
+		},
+		{
+			name:    "UNTIL（UTC）より後の発生は含めない",
+			rule:    "FREQ=DAILY;UNTIL=20260303T000000Z",
+			dtstart: date(2026, time.March, 1, 9),
+			want:    []time.Time{date(2026, time.March, 1, 9), date(2026, time.March, 2, 9)},
+		},
+		{
+			name:    "日付のみのUNTILはその日の発生を含める",
+			rule:    "FREQ=DAILY;UNTIL=20260303",
+			dtstart: date(2026, time.March, 1, 9),
+			want: []time.Time{
+				date(2026, time.March, 1, 9), date(2026, time.March, 2, 9), date(2026, time.March, 3, 9),
+			},
+		},
+		{
+			// RFC 5545 3.8.5.3 の例
+			name:    "WKST=MO",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=MO",
+			dtstart: date(1997, time.August, 5, 9),
+			want: []time.Time{
+				date(1997, time.August, 5, 9), date(1997, time.August, 10, 9),
+				date(1997, time.August, 19, 9), date(1997, time.August, 24, 9),
+			},
+		},
+		{
+			name:    "WKST=SU",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;COUNT=4;BYDAY=TU,SU;WKST=SU",
+			dtstart: date(1997, time.August, 5, 9),
+			want: []time.Time{
+				date(1997, time.August, 5, 9), date(1997, time.August, 17, 9),
+				date(1997, time.August, 19, 9), date(1997, time.August, 31, 9),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertTimes(t, occurrences(t, tt.rule, tt.dtstart, 100), tt.want)
+		})
+	}
+}
+
+// 夏時間の切り替えをまたいでも、開始日時のタイムゾーンの壁時計時刻で発生する
+func TestIterateAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("タイムゾーンを読み込めません: %v", err)
+	}
+	local := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 9, 0, 0, 0, loc) }
+
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		want    []time.Time
+		wantUTC []int // 各発生のUTCの時
+	}{
+		{
+			name:    "夏時間の開始（3月8日）",
+			rule:    "FREQ=DAILY;COUNT=3",
+			dtstart: local(time.March, 7),
+			want:    []time.Time{local(time.March, 7), local(time.March, 8), local(time.March, 9)},
+			wantUTC: []int{14, 13, 13},
+		},
+		{
+			name:    "夏時間の終了（11月1日）",
+			rule:    "FREQ=WEEKLY;COUNT=2",
+			dtstart: local(time.October, 29),
+			want:    []time.Time{local(time.October, 29), local(time.November, 5)},
+			wantUTC: []int{13, 14},
+		},
+		{
+			name:    "タイムゾーンなしのUNTILは開始日時のタイムゾーンで解釈する",
+			rule:    "FREQ=DAILY;UNTIL=20260308T090000",
+			dtstart: local(time.March, 7),
+			want:    []time.Time{local(time.March, 7), local(time.March, 8)},
+			wantUTC: []int{14, 13},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := occurrences(t, tt.rule, tt.dtstart, 100)
+			assertTimes(t, got, tt.want)
+			for i, occ := range got {
+				if occ.Location() != loc || occ.Hour() != 9 {
+					t.Errorf("%d 件目が %v です（%s の9時のはず）", i+1, occ, loc)
+				}
+				if h := occ.UTC().Hour(); h != tt.wantUTC[i] {
+					t.Errorf("%d 件目がUTCの %d 時です（%d 時のはず）", i+1, h, tt.wantUTC[i])
+				}
+			}
+		})
+	}
+}
+
+// Between は開始日時が [from, to) に含まれる発生のみ返す
+func TestBetween(t *testing.T) {
+	r, err := Parse("FREQ=WEEKLY;BYDAY=MO,WE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := r.Between(date(2026, time.January, 5, 9), date(2026, time.January, 7, 9), date(2026, time.January, 14, 9))
+	assertTimes(t, got, []time.Time{date(2026, time.January, 7, 9), date(2026, time.January, 12, 9)})
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20260303",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=WEEKLY;WKST=XX",
+	} {
+		if _, err := Parse(rule); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("%q のエラーが %v です（ErrInvalidRule のはず）", rule, err)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ensureTeamMember ユーザーがチームのアクティブメンバーか確認する
+func ensureTeamMember(db *gorm.DB, teamID, userID string) error {
+	var count int64
+	if err := db.Model(&models.TeamMember{}).
+		Where("team_id = ? AND user_id = ? AND status = ?", teamID, userID, models.TeamMemberStatusActive).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// findTaskForMember タスクを取得し、ユーザーがそのチームのメンバーか確認する（別のワークスペースのタスクは見つからないものとする）
+func findTaskForMember(db *gorm.DB, taskID, userID string) (*models.Task, error) {
+	var task models.Task
+	if err := db.Scopes(inWorkspaceOf(userID)).First(&task, "id = ?", taskID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if err := ensureTeamMember(db, task.TeamID, userID); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// isTeamAdmin ユーザーがチームのオーナーまたは管理者か判定する
+func isTeamAdmin(db *gorm.DB, teamID, userID string) (bool, error) {
+	var count int64
+	err := db.Model(&models.TeamMember{}).
+		Where("team_id = ? AND user_id = ? AND status = ? AND role IN ?", teamID, userID,
+			models.TeamMemberStatusActive, []models.TeamMemberRole{models.TeamMemberRoleOwner, models.TeamMemberRoleAdmin}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// findEventForUser イベントを取得し、閲覧できることを確認する
+//
+// 作成者と招待された参加者は常に、チームメンバーは非公開でない場合に、それ以外のユーザーは公開のイベントのみ閲覧できる。
+// 公開のイベントも、別のワークスペースのものは見つからないものとする。
+func findEventForUser(db *gorm.DB, eventID, userID string) (*models.Event, error) {
+	var event models.Event
+	if err := db.Scopes(inWorkspaceOf(userID)).First(&event, "id = ?", eventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if event.CreatorID == userID {
+		return &event, nil
+	}
+	var attendees int64
+	if err := db.Model(&models.EventAttendee{}).Where("event_id = ? AND user_id = ?", eventID, userID).
+		Count(&attendees).Error; err != nil {
+		return nil, err
+	}
+	if attendees > 0 || event.Visibility == models.EventVisibilityPublic {
+		return &event, nil
+	}
+	if event.TeamID == nil || event.Visibility == models.EventVisibilityPrivate {
+		return nil, ErrForbidden
+	}
+	if err := ensureTeamMember(db, *event.TeamID, userID); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// visibleEventsScope ユーザーの一覧に表示するイベント（自分が作成、招待された、または所属チームの非公開でないもの）に絞り込む
+//
+// 公開のイベントでも、他のチームのものは一覧には含めない。
+func visibleEventsScope(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		teamIDs := db.Session(&gorm.Session{NewDB: true}).Model(&models.TeamMember{}).
+			Select("team_id").Where("user_id = ? AND status = ?", userID, models.TeamMemberStatusActive)
+		invited := db.Session(&gorm.Session{NewDB: true}).Model(&models.EventAttendee{}).
+			Select("event_id").Where("user_id = ?", userID)
+		return inWorkspaceOf(userID)(db).
+			Where("events.creator_id = ? OR (events.team_id IN (?) AND events.visibility <> ?) OR events.id IN (?)",
+				userID, teamIDs, models.EventVisibilityPrivate, invited)
+	}
+}
+
+// hidePrivateEventsScope 他の人の非公開イベント（自分が作成・招待されていないもの）を除く
+func hidePrivateEventsScope(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		invited := db.Session(&gorm.Session{NewDB: true}).Model(&models.EventAttendee{}).
+			Select("event_id").Where("user_id = ?", userID)
+		return db.Where("events.visibility <> ? OR events.creator_id = ? OR events.id IN (?)",
+			models.EventVisibilityPrivate, userID, invited)
+	}
+}
+
+// findEditableEvent 作成者またはチーム管理者のみ編集できるイベントを取得する
+func findEditableEvent(db *gorm.DB, eventID, userID string) (*models.Event, error) {
+	event, err := findEventForUser(db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if event.IsExternal() {
+		return nil, fmt.Errorf("%w: %v", ErrForbidden, models.ErrReadOnlyEvent)
+	}
+	if event.CreatorID == userID {
+		return event, nil
+	}
+	if event.TeamID != nil {
+		admin, err := isTeamAdmin(db, *event.TeamID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if admin {
+			return event, nil
+		}
+	}
+	return nil, ErrForbidden
+}
+
+// privateEventTitle 閲覧できない非公開の予定に表示するタイトル
+const privateEventTitle = "予定あり"
+
+// maskPrivateOccurrences 閲覧できない非公開の予定の発生からタイトルと説明を取り除く
+func maskPrivateOccurrences(db *gorm.DB, occurrences []EventOccurrence, userID string) ([]EventOccurrence, error) {
+	var privateIDs []string
+	for _, occ := range occurrences {
+		if occ.Visibility == models.EventVisibilityPrivate && occ.CreatorID != userID {
+			privateIDs = append(privateIDs, occ.EventID)
+		}
+	}
+	if len(privateIDs) == 0 {
+		return occurrences, nil
+	}
+	var invited []string
+	if err := db.Model(&models.EventAttendee{}).Where("event_id IN ? AND user_id = ?", privateIDs, userID).
+		Pluck("event_id", &invited).Error; err != nil {
+		return nil, err
+	}
+	canView := make(map[string]bool, len(invited))
+	for _, id := range invited {
+		canView[id] = true
+	}
+	for i := range occurrences {
+		occ := &occurrences[i]
+		if occ.Visibility == models.EventVisibilityPrivate && occ.CreatorID != userID && !canView[occ.EventID] {
+			occ.Title = privateEventTitle
+			occ.Description = ""
+		}
+	}
+	return occurrences, nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// アジェンダの項目の種類
+const (
+	AgendaItemEvent   = "EVENT"
+	AgendaItemTask    = "TASK"
+	AgendaItemHoliday = "HOLIDAY"
+)
+
+// AgendaItem アジェンダの項目（イベントの発生、タスクの期限、または所属チームの祝日）
+type AgendaItem struct {
+	Kind      string           `json:"kind"` // EVENT / TASK / HOLIDAY
+	ID        string           `json:"id"`   // イベントID・タスクID（祝日は空）
+	Title     string           `json:"title"`
+	StartDate time.Time        `json:"startDate"` // タスクは期限
+	EndDate   *time.Time       `json:"endDate,omitempty"`
+	AllDay    bool             `json:"allDay"`
+	TeamID    *string          `json:"teamId"`
+	Event     *EventOccurrence `json:"event,omitempty"`
+	Task      *models.Task     `json:"task,omitempty"`
+	Holiday   *Holiday         `json:"holiday,omitempty"`
+}
+
+var agendaKindOrder = map[string]int{AgendaItemHoliday: 0, AgendaItemEvent: 1, AgendaItemTask: 2}
+
+type AgendaService struct {
+	db           *gorm.DB
+	eventService *EventService
+	holidays     *HolidayService
+}
+
+func NewAgendaService(db *gorm.DB, eventService *EventService, holidays *HolidayService) *AgendaService {
+	return &AgendaService{db: db, eventService: eventService, holidays: holidays}
+}
+
+// GetAgenda 期間内の自分のイベントの発生と、タスクの期限を日時順にまとめた一覧
+//
+// イベントはカレンダーと同じく閲覧できるもの、タスクは所属するチームの自分が担当する（担当者未設定の場合は自分が作成した）
+// キャンセル以外のもの。祝日は所属するチームに設定された国のもの。同じ日時では祝日、イベント、タスクの順に並べる。
+// 絞り込み条件を指定した場合、祝日は含めず、タスクは作成者のみで絞り込む（イベントにしかない条件がある場合は含めない）。
+func (s *AgendaService) GetAgenda(userID string, from, to time.Time, filter OccurrenceFilter) ([]AgendaItem, error) {
+	occurrences, err := s.eventService.ListOccurrences(userID, from, to, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	teamIDs := s.db.Session(&gorm.Session{NewDB: true}).Model(&models.TeamMember{}).
+		Select("team_id").Where("user_id = ? AND status = ?", userID, models.TeamMemberStatusActive)
+	var tasks []models.Task
+	eventOnly := len(filter.CategoryIDs) > 0 || len(filter.Types) > 0 || len(filter.LabelIDs) > 0 || len(filter.AttendeeIDs) > 0
+	if !eventOnly {
+		query := s.db.Preload("Assignee").
+			Where("team_id IN (?) AND due_date >= ? AND due_date < ? AND status <> ? AND archived_at IS NULL",
+				teamIDs, from, to, models.TaskStatusCancelled).
+			Where("assignee_id = ? OR (assignee_id IS NULL AND creator_id = ?)", userID, userID)
+		if len(filter.CreatorIDs) > 0 {
+			query = query.Where("creator_id IN ?", filter.CreatorIDs)
+		}
+		if err := query.Find(&tasks).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var countries []string
+	if filter.IsZero() {
+		if err := s.db.Model(&models.Team{}).Distinct("holiday_country").
+			Where("id IN (?) AND holiday_country <> ''", teamIDs).Pluck("holiday_country", &countries).Error; err != nil {
+			return nil, err
+		}
+	}
+	var holidays []Holiday
+	for _, country := range countries {
+		list, err := s.holidays.Between(country, from, to)
+		if err != nil {
+			log.Printf("%sの祝日を取得できませんでした: %v", country, err)
+			continue
+		}
+		holidays = append(holidays, list...)
+	}
+
+	items := make([]AgendaItem, 0, len(holidays)+len(occurrences)+len(tasks))
+	for i := range holidays {
+		holiday := &holidays[i]
+		end := holiday.Date.AddDate(0, 0, 1)
+		items = append(items, AgendaItem{
+			Kind:      AgendaItemHoliday,
+			Title:     holiday.Name,
+			StartDate: holiday.Date,
+			EndDate:   &end,
+			AllDay:    true,
+			Holiday:   holiday,
+		})
+	}
+	for i := range occurrences {
+		occ := &occurrences[i]
+		end := occ.EndDate
+		items = append(items, AgendaItem{
+			Kind:      AgendaItemEvent,
+			ID:        occ.EventID,
+			Title:     occ.Title,
+			StartDate: occ.StartDate,
+			EndDate:   &end,
+			AllDay:    occ.AllDay,
+			TeamID:    occ.TeamID,
+			Event:     occ,
+		})
+	}
+	for i := range tasks {
+		task := &tasks[i]
+		teamID := task.TeamID
+		items = append(items, AgendaItem{
+			Kind:      AgendaItemTask,
+			ID:        task.ID,
+			Title:     task.Title,
+			StartDate: *task.DueDate,
+			TeamID:    &teamID,
+			Task:      task,
+		})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].StartDate.Equal(items[j].StartDate) {
+			return items[i].StartDate.Before(items[j].StartDate)
+		}
+		return agendaKindOrder[items[i].Kind] < agendaKindOrder[items[j].Kind]
+	})
+	return items, nil
+}
+
+// LocalizeAgenda 項目の日時を閲覧者のタイムゾーンで表す
+func LocalizeAgenda(items []AgendaItem, loc *time.Location) []AgendaItem {
+	for i := range items {
+		item := &items[i]
+		item.StartDate = models.LocalTime(item.StartDate, item.AllDay, loc)
+		if item.EndDate != nil {
+			end := models.LocalTime(*item.EndDate, item.AllDay, loc)
+			item.EndDate = &end
+		}
+		if item.Event != nil {
+			localized := LocalizeOccurrences([]EventOccurrence{*item.Event}, loc)
+			item.Event = &localized[0]
+		}
+	}
+	return items
+}
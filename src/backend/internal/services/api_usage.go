@@ -0,0 +1,255 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"task-calendar-backend/internal/database"
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	apiUsageRetention    = 90 * 24 * time.Hour
+	defaultAPIUsageLimit = 50
+	maxAPIUsageLimit     = 500
+)
+
+// APIUsageGroupBy 利用状況を集計する単位
+type APIUsageGroupBy string
+
+const (
+	APIUsageByClient APIUsageGroupBy = "client" // 利用者（ユーザー・IPアドレス）ごと
+	APIUsageByRoute  APIUsageGroupBy = "route"  // ルートごと
+)
+
+// apiUsageKey メモリ上で集計する単位（1時間・利用者・ルート）
+type apiUsageKey struct {
+	hourStart time.Time
+	clientKey string
+	route     string
+}
+
+// APIUsageService APIの利用者・ルートごとのリクエスト数・エラー率・応答時間の集計
+//
+// リクエストのたびにデータベースへ書き込まないよう、メモリ上で集計して定期実行の Flush でまとめて加算する。
+// 複数のサーバーで動かしても、それぞれの集計が同じ行に加算される。
+type APIUsageService struct {
+	db *gorm.DB
+
+	mu      sync.Mutex
+	pending map[apiUsageKey]*models.APIUsage
+}
+
+func NewAPIUsageService(db *gorm.DB) *APIUsageService {
+	return &APIUsageService{db: db, pending: map[apiUsageKey]*models.APIUsage{}}
+}
+
+// Record 1件のリクエストを集計に加える（userID は認証していない場合は空）
+func (s *APIUsageService) Record(clientKey, userID, route string, status int, latency time.Duration, at time.Time) {
+	key := apiUsageKey{hourStart: at.UTC().Truncate(time.Hour), clientKey: clientKey, route: route}
+	latencyMs := latency.Milliseconds()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usage, ok := s.pending[key]
+	if !ok {
+		usage = &models.APIUsage{HourStart: key.hourStart, ClientKey: clientKey, Route: route}
+		if userID != "" {
+			usage.UserID = &userID
+		}
+		s.pending[key] = usage
+	}
+	usage.Requests++
+	switch {
+	case status >= http.StatusInternalServerError:
+		usage.ServerErrors++
+	case status >= http.StatusBadRequest:
+		usage.ClientErrors++
+	}
+	usage.TotalLatencyMs += latencyMs
+	if latencyMs > usage.MaxLatencyMs {
+		usage.MaxLatencyMs = latencyMs
+	}
+}
+
+// Flush メモリ上の集計をデータベースに加算する（定期実行。失敗した場合は次回に持ち越す）
+func (s *APIUsageService) Flush() error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = map[apiUsageKey]*models.APIUsage{}
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// 同じ行を同時に加算するサーバー同士がデッドロックしないよう、キーの順に書き込む
+	usages := make([]models.APIUsage, 0, len(pending))
+	for _, usage := range pending {
+		usages = append(usages, *usage)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		a, b := usages[i], usages[j]
+		if !a.HourStart.Equal(b.HourStart) {
+			return a.HourStart.Before(b.HourStart)
+		}
+		if a.ClientKey != b.ClientKey {
+			return a.ClientKey < b.ClientKey
+		}
+		return a.Route < b.Route
+	})
+	err := s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "hour_start"}, {Name: "client_key"}, {Name: "route"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"requests":         gorm.Expr("api_usages.requests + EXCLUDED.requests"),
+			"client_errors":    gorm.Expr("api_usages.client_errors + EXCLUDED.client_errors"),
+			"server_errors":    gorm.Expr("api_usages.server_errors + EXCLUDED.server_errors"),
+			"total_latency_ms": gorm.Expr("api_usages.total_latency_ms + EXCLUDED.total_latency_ms"),
+			"max_latency_ms":   gorm.Expr(database.Greatest(s.db) + "(api_usages.max_latency_ms, EXCLUDED.max_latency_ms)"),
+		}),
+	}).CreateInBatches(&usages, 100).Error
+	if err != nil {
+		s.restore(pending)
+	}
+	return err
+}
+
+// restore 書き込めなかった集計を、その間に集計した分と合わせて戻す
+func (s *APIUsageService) restore(pending map[apiUsageKey]*models.APIUsage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, usage := range pending {
+		current, ok := s.pending[key]
+		if !ok {
+			s.pending[key] = usage
+			continue
+		}
+		current.Requests += usage.Requests
+		current.ClientErrors += usage.ClientErrors
+		current.ServerErrors += usage.ServerErrors
+		current.TotalLatencyMs += usage.TotalLatencyMs
+		if usage.MaxLatencyMs > current.MaxLatencyMs {
+			current.MaxLatencyMs = usage.MaxLatencyMs
+		}
+	}
+}
+
+// PurgeExpired 保存期間を過ぎた集計を削除する（定期実行）
+func (s *APIUsageService) PurgeExpired() error {
+	return s.db.Where("hour_start < ?", time.Now().Add(-apiUsageRetention)).Delete(&models.APIUsage{}).Error
+}
+
+// APIUsageQuery 利用状況の集計の条件
+type APIUsageQuery struct {
+	From    time.Time
+	To      time.Time
+	GroupBy APIUsageGroupBy // 空は利用者ごと
+	UserID  string          // 指定した場合はそのユーザーのリクエストのみ
+	Limit   int             // リクエスト数の多い順に返す件数（既定50・最大500）
+}
+
+// APIUsageSummary 利用者またはルートごとの利用状況
+type APIUsageSummary struct {
+	Key          string       `json:"key"` // 利用者（user:<ID>・ip:<IPアドレス>）またはルート
+	UserID       *string      `json:"userId,omitempty"`
+	User         *models.User `json:"user,omitempty"`
+	Requests     int64        `json:"requests"`
+	ClientErrors int64        `json:"clientErrors"`
+	ServerErrors int64        `json:"serverErrors"`
+	ErrorRate    float64      `json:"errorRate"` // 4xx・5xxの割合（0〜1）
+	AvgLatencyMs float64      `json:"avgLatencyMs"`
+	MaxLatencyMs int64        `json:"maxLatencyMs"`
+}
+
+// SummarizeUsage 期間内の利用状況を、利用者またはルートごとにリクエスト数の多い順で集計する（管理者向け）
+//
+// 集計は1時間単位のため、期間の端は時間の区切りに広げて数える。まだ Flush していない直近の分は含まない。レプリカがあればレプリカで集計する。
+func (s *APIUsageService) SummarizeUsage(q APIUsageQuery) ([]APIUsageSummary, error) {
+	if !q.From.Before(q.To) {
+		return nil, fmt.Errorf("%w: fromはtoより前の日時を指定してください", ErrInvalidInput)
+	}
+	var column string
+	switch q.GroupBy {
+	case "", APIUsageByClient:
+		column = "client_key"
+	case APIUsageByRoute:
+		column = "route"
+	default:
+		return nil, fmt.Errorf("%w: groupByはclientまたはrouteを指定してください", ErrInvalidInput)
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultAPIUsageLimit
+	}
+	if limit > maxAPIUsageLimit {
+		limit = maxAPIUsageLimit
+	}
+
+	query := database.Read(s.db).Model(&models.APIUsage{}).
+		Select(column+" AS key, MAX(user_id) AS user_id, SUM(requests) AS requests, "+
+			"SUM(client_errors) AS client_errors, SUM(server_errors) AS server_errors, "+
+			"SUM(total_latency_ms) AS total_latency_ms, MAX(max_latency_ms) AS max_latency_ms").
+		Where("hour_start >= ? AND hour_start < ?", q.From.UTC().Truncate(time.Hour), q.To.UTC())
+	if q.UserID != "" {
+		query = query.Where("user_id = ?", q.UserID)
+	}
+	var rows []struct {
+		Key            string
+		UserID         *string
+		Requests       int64
+		ClientErrors   int64
+		ServerErrors   int64
+		TotalLatencyMs int64
+		MaxLatencyMs   int64
+	}
+	if err := query.Group(column).Order("requests DESC, key ASC").Limit(limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	summaries := make([]APIUsageSummary, 0, len(rows))
+	var userIDs []string
+	for _, row := range rows {
+		summary := APIUsageSummary{
+			Key:          row.Key,
+			Requests:     row.Requests,
+			ClientErrors: row.ClientErrors,
+			ServerErrors: row.ServerErrors,
+			MaxLatencyMs: row.MaxLatencyMs,
+		}
+		if row.Requests > 0 {
+			summary.ErrorRate = float64(row.ClientErrors+row.ServerErrors) / float64(row.Requests)
+			summary.AvgLatencyMs = float64(row.TotalLatencyMs) / float64(row.Requests)
+		}
+		// ルートごとの集計では複数のユーザーが混ざるため、ユーザーはつけない
+		if q.GroupBy != APIUsageByRoute && strings.HasPrefix(row.Key, "user:") && row.UserID != nil {
+			summary.UserID = row.UserID
+			userIDs = append(userIDs, *row.UserID)
+		}
+		summaries = append(summaries, summary)
+	}
+	if len(userIDs) == 0 {
+		return summaries, nil
+	}
+
+	var users []models.User
+	if err := s.db.Select("id", "email", "username", "first_name", "last_name", "avatar", "role").
+		Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*models.User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+	for i := range summaries {
+		if summaries[i].UserID != nil {
+			summaries[i].User = byID[*summaries[i].UserID]
+		}
+	}
+	return summaries, nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"io"
+
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/storage"
+	"task-calendar-backend/internal/upload"
+
+	"gorm.io/gorm"
+)
+
+// ErrFileTooLarge アップロードサイズ上限を超えた場合のエラー
+var ErrFileTooLarge = upload.ErrTooLarge
+
+type AttachmentService struct {
+	db            *gorm.DB
+	storage       storage.Storage
+	maxUploadSize int64
+}
+
+func NewAttachmentService(db *gorm.DB, store storage.Storage, maxUploadSize int64) *AttachmentService {
+	return &AttachmentService{db: db, storage: store, maxUploadSize: maxUploadSize}
+}
+
+// MaxUploadSize アップロード可能な最大サイズ（バイト）
+func (s *AttachmentService) MaxUploadSize() int64 {
+	return s.maxUploadSize
+}
+
+// UploadCommentAttachment コメントにファイルを添付する（投稿者またはチーム管理者のみ）
+func (s *AttachmentService) UploadCommentAttachment(taskID, commentID, userID string, file *upload.File) (*models.Attachment, error) {
+	comment, err := findEditableTaskComment(s.db, taskID, commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := models.Attachment{
+		TaskID:     taskID,
+		CommentID:  &comment.ID,
+		UploaderID: userID,
+		FileName:   file.Name,
+	}
+	if err := s.store(&attachment, file); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Create(&attachment).Error; err != nil {
+		s.storage.Delete(attachment.StorageKey)
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// OpenAttachment 添付ファイルのメタデータと内容を取得する
+func (s *AttachmentService) OpenAttachment(taskID, attachmentID, userID string) (*models.Attachment, io.ReadCloser, error) {
+	attachment, err := s.findAttachment(taskID, attachmentID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rc, err := s.storage.Open(attachment.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, rc, nil
+}
+
+// DeleteAttachment 添付ファイルを削除する（アップロードした本人またはチーム管理者のみ）
+//
+// ファイルは保存期間を過ぎて完全に削除するまでストレージに残し、ゴミ箱から復元できる。
+func (s *AttachmentService) DeleteAttachment(taskID, attachmentID, userID string) error {
+	attachment, err := s.findAttachment(taskID, attachmentID, userID)
+	if err != nil {
+		return err
+	}
+
+	if attachment.UploaderID != userID {
+		var task models.Task
+		if err := s.db.Select("team_id").First(&task, "id = ?", taskID).Error; err != nil {
+			return err
+		}
+		admin, err := isTeamAdmin(s.db, task.TeamID, userID)
+		if err != nil {
+			return err
+		}
+		if !admin {
+			return ErrForbidden
+		}
+	}
+
+	return s.db.Delete(attachment).Error
+}
+
+// store ファイルをストレージに書き込む（サイズの上限はアップロードの読み出しで確かめる）
+func (s *AttachmentService) store(attachment *models.Attachment, file *upload.File) error {
+	key, err := storage.NewKey("attachments/" + attachment.TaskID)
+	if err != nil {
+		return err
+	}
+	size, err := s.storage.Save(key, file)
+	if err != nil {
+		return err
+	}
+
+	attachment.ContentType = file.ContentType
+	attachment.StorageKey = key
+	attachment.Size = size
+	return nil
+}
+
+func (s *AttachmentService) findAttachment(taskID, attachmentID, userID string) (*models.Attachment, error) {
+	if _, err := findTaskForMember(s.db, taskID, userID); err != nil {
+		return nil, err
+	}
+
+	var attachment models.Attachment
+	if err := s.db.First(&attachment, "id = ? AND task_id = ?", attachmentID, taskID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 開始前からチェックインを受け付ける時間
+const checkInOpensBefore = 15 * time.Minute
+
+// CheckInInput 出席チェックイン（recurrenceId を省略すると開催中の回にチェックインする）
+type CheckInInput struct {
+	RecurrenceID *time.Time `json:"recurrenceId"`
+}
+
+// OccurrenceAttendance 回ごとの出席状況
+type OccurrenceAttendance struct {
+	RecurrenceID time.Time             `json:"recurrenceId"`
+	StartDate    time.Time             `json:"startDate"`
+	EndDate      time.Time             `json:"endDate"`
+	CheckIns     []models.EventCheckIn `json:"checkIns"`
+}
+
+// MemberAttendance 参加者ごとの出席回数と出席率
+type MemberAttendance struct {
+	User     models.User `json:"user"`
+	Attended int         `json:"attended"`
+	Rate     float64     `json:"rate"` // 期間内の回のうち出席した割合（0〜1）
+}
+
+// AttendanceSummary イベント（繰り返しの場合はシリーズ全体）の出席集計
+type AttendanceSummary struct {
+	EventID     string                 `json:"eventId"`
+	From        time.Time              `json:"from"`
+	To          time.Time              `json:"to"`
+	Occurrences []OccurrenceAttendance `json:"occurrences"`
+	Members     []MemberAttendance     `json:"members"`
+}
+
+type AttendanceService struct {
+	db *gorm.DB
+}
+
+func NewAttendanceService(db *gorm.DB) *AttendanceService {
+	return &AttendanceService{db: db}
+}
+
+// CheckIn イベントの開催中（開始15分前から終了まで）に出席をチェックインする
+//
+// 同じ回に既にチェックインしている場合はその記録を返す。
+func (s *AttendanceService) CheckIn(eventID, userID string, input CheckInInput) (*models.EventCheckIn, error) {
+	event, err := findEventForUser(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == models.EventStatusCancelled {
+		return nil, fmt.Errorf("%w: キャンセルされたイベントにはチェックインできません", ErrInvalidInput)
+	}
+
+	now := time.Now()
+	occ, err := s.openOccurrence(event, now, input.RecurrenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing models.EventCheckIn
+	err = s.db.Preload("User").
+		First(&existing, "event_id = ? AND user_id = ? AND recurrence_id = ?", event.ID, userID, occ.RecurrenceID).Error
+	if err == nil {
+		return &existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	checkIn := models.EventCheckIn{
+		EventID:      event.ID,
+		UserID:       userID,
+		RecurrenceID: occ.RecurrenceID,
+		CheckedInAt:  now,
+	}
+	if err := s.db.Create(&checkIn).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Preload("User").First(&checkIn, "id = ?", checkIn.ID).Error; err != nil {
+		return nil, err
+	}
+	return &checkIn, nil
+}
+
+// openOccurrence チェックインを受け付けている回（recurrenceID 指定時はその回のみ）
+func (s *AttendanceService) openOccurrence(event *models.Event, now time.Time, recurrenceID *time.Time) (*EventOccurrence, error) {
+	var exceptions []models.EventException
+	if event.IsRecurring {
+		if err := s.db.Where("event_id = ?", event.ID).Find(&exceptions).Error; err != nil {
+			return nil, err
+		}
+	}
+	loc := event.TimeZoneLocation()
+	occurrences, err := expandEvent(event, exceptions, now.In(loc), now.Add(checkInOpensBefore).In(loc))
+	if err != nil {
+		return nil, err
+	}
+	for i := range occurrences {
+		if recurrenceID == nil || occurrences[i].RecurrenceID.Equal(*recurrenceID) {
+			return &occurrences[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: チェックインはイベントの開始%d分前から終了までの間にできます",
+		ErrInvalidInput, int(checkInOpensBefore.Minutes()))
+}
+
+// GetAttendance イベントの出席集計（編集権限のあるユーザーのみ）
+//
+// from / to を省略した場合、繰り返しイベントは最初の回から現在までの回、単発のイベントはその回を集計する。
+// 参加者は作成者・辞退やキャンセル待ちでない招待者と、期間内にチェックインしたユーザー。
+func (s *AttendanceService) GetAttendance(eventID, userID string, from, to *time.Time) (*AttendanceSummary, error) {
+	event, err := findEditableEvent(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rangeFrom, rangeTo := event.StartDate, event.EndDate
+	if event.IsRecurring {
+		rangeTo = time.Now().Add(checkInOpensBefore)
+	}
+	if from != nil {
+		rangeFrom = *from
+	}
+	if to != nil {
+		rangeTo = *to
+	}
+	if err := validateExpansionRange(rangeFrom, rangeTo); err != nil {
+		return nil, err
+	}
+
+	var exceptions []models.EventException
+	if event.IsRecurring {
+		if err := s.db.Where("event_id = ?", event.ID).Find(&exceptions).Error; err != nil {
+			return nil, err
+		}
+	}
+	loc := event.TimeZoneLocation()
+	occurrences, err := expandEvent(event, exceptions, rangeFrom.In(loc), rangeTo.In(loc))
+	if err != nil {
+		return nil, err
+	}
+
+	recurrenceIDs := make([]time.Time, len(occurrences))
+	for i := range occurrences {
+		recurrenceIDs[i] = occurrences[i].RecurrenceID
+	}
+	var checkIns []models.EventCheckIn
+	if len(recurrenceIDs) > 0 {
+		if err := s.db.Preload("User").Where("event_id = ? AND recurrence_id IN ?", event.ID, recurrenceIDs).
+			Order("checked_in_at ASC").Find(&checkIns).Error; err != nil {
+			return nil, err
+		}
+	}
+	byRecurrenceID := make(map[int64][]models.EventCheckIn, len(occurrences))
+	attended := map[string]int{}
+	for _, ci := range checkIns {
+		byRecurrenceID[ci.RecurrenceID.Unix()] = append(byRecurrenceID[ci.RecurrenceID.Unix()], ci)
+		attended[ci.UserID]++
+	}
+
+	summary := &AttendanceSummary{
+		EventID:     event.ID,
+		From:        rangeFrom,
+		To:          rangeTo,
+		Occurrences: make([]OccurrenceAttendance, 0, len(occurrences)),
+		Members:     []MemberAttendance{},
+	}
+	for _, occ := range occurrences {
+		list := byRecurrenceID[occ.RecurrenceID.Unix()]
+		if list == nil {
+			list = []models.EventCheckIn{}
+		}
+		summary.Occurrences = append(summary.Occurrences, OccurrenceAttendance{
+			RecurrenceID: occ.RecurrenceID,
+			StartDate:    occ.StartDate,
+			EndDate:      occ.EndDate,
+			CheckIns:     list,
+		})
+	}
+
+	members, err := s.attendanceMembers(event, checkIns)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range members {
+		member := MemberAttendance{User: user, Attended: attended[user.ID]}
+		if len(occurrences) > 0 {
+			member.Rate = float64(member.Attended) / float64(len(occurrences))
+		}
+		summary.Members = append(summary.Members, member)
+	}
+	return summary, nil
+}
+
+// attendanceMembers 集計対象のユーザー（作成者、辞退・キャンセル待ちでない招待者、チェックインしたユーザーの順）
+func (s *AttendanceService) attendanceMembers(event *models.Event, checkIns []models.EventCheckIn) ([]models.User, error) {
+	var attendeeIDs []string
+	if err := s.db.Model(&models.EventAttendee{}).
+		Where("event_id = ? AND status NOT IN ?", event.ID, models.InactiveAttendeeStatuses).
+		Order("created_at ASC").Pluck("user_id", &attendeeIDs).Error; err != nil {
+		return nil, err
+	}
+	ids := append([]string{event.CreatorID}, attendeeIDs...)
+	for _, ci := range checkIns {
+		ids = append(ids, ci.UserID)
+	}
+
+	var users []models.User
+	if err := s.db.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[string]models.User, len(users))
+	for _, u := range users {
+		byID[u.ID] = u
+	}
+
+	seen := map[string]bool{}
+	var members []models.User
+	for _, id := range ids {
+		user, ok := byID[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		members = append(members, user)
+	}
+	return members, nil
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 通知種別
+const (
+	NotificationTypeEventInvitation = "EVENT_INVITATION"
+	NotificationTypeEventRSVP       = "EVENT_RSVP"
+)
+
+// RSVPInput 招待への回答
+type RSVPInput struct {
+	Status  string `json:"status" binding:"required,oneof=ACCEPTED DECLINED TENTATIVE"`
+	Comment string `json:"comment" binding:"max=500"`
+}
+
+type AttendeeService struct {
+	db          *gorm.DB
+	notifier    Notifier
+	invitations *InvitationService
+}
+
+func NewAttendeeService(db *gorm.DB, notifier Notifier, invitations *InvitationService) *AttendeeService {
+	return &AttendeeService{db: db, notifier: notifier, invitations: invitations}
+}
+
+// PreloadAttendees イベント取得時に参加者とユーザー情報を読み込むスコープ
+func PreloadAttendees(db *gorm.DB) *gorm.DB {
+	return db.Preload("Attendees", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Preload("Attendees.User")
+}
+
+// ListAttendees イベントの参加者一覧
+func (s *AttendeeService) ListAttendees(eventID, userID string, sort SortOptions, include IncludeOptions) ([]models.EventAttendee, error) {
+	if _, err := findEventForUser(s.db, eventID, userID); err != nil {
+		return nil, err
+	}
+
+	var attendees []models.EventAttendee
+	if err := orderBy(preload(s.db, include).Where("event_id = ?", eventID), sort, "created_at ASC").
+		Find(&attendees).Error; err != nil {
+		return nil, err
+	}
+	return attendees, nil
+}
+
+// InviteResult 招待後の参加者一覧と、招待したユーザーの予定との重複
+type InviteResult struct {
+	Attendees []models.EventAttendee `json:"attendees"`
+	Conflicts []EventConflict        `json:"conflicts"`
+}
+
+// InviteAttendees ユーザーをイベントに招待する（招待済みのユーザーはそのまま、新たに招待したユーザーには招待メールも送る）
+//
+// チームで重複が禁止されている場合、招待するユーザーの予定と重なるときは招待せず *ConflictError を返す。
+func (s *AttendeeService) InviteAttendees(eventID, userID string, userIDs []string) (*InviteResult, error) {
+	event, err := findEditableEvent(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == models.EventStatusCancelled {
+		return nil, fmt.Errorf("%w: キャンセルされたイベントには招待できません", ErrInvalidInput)
+	}
+	if len(userIDs) == 0 {
+		return nil, fmt.Errorf("%w: userIdsは必須です", ErrInvalidInput)
+	}
+
+	var users []models.User
+	if err := s.db.Select("id").Scopes(inWorkspaceOf(userID)).Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(users))
+	for _, u := range users {
+		found[u.ID] = true
+	}
+	for _, id := range userIDs {
+		if !found[id] {
+			return nil, fmt.Errorf("%w: ユーザー %s が見つかりません", ErrInvalidInput, id)
+		}
+	}
+
+	var existing []models.EventAttendee
+	if err := s.db.Where("event_id = ?", eventID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	invited := make(map[string]bool, len(existing))
+	for _, a := range existing {
+		invited[a.UserID] = true
+	}
+
+	var created []models.EventAttendee
+	var newUserIDs []string
+	for _, id := range userIDs {
+		if invited[id] {
+			continue
+		}
+		invited[id] = true
+		newUserIDs = append(newUserIDs, id)
+		created = append(created, models.EventAttendee{
+			EventID:     eventID,
+			UserID:      id,
+			Status:      models.AttendeeStatusPending,
+			InvitedByID: userID,
+		})
+	}
+
+	conflicts, err := eventConflicts(s.db, event, newUserIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(created) > 0 {
+		if err := s.db.Create(&created).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var notified []string
+	for _, a := range created {
+		if a.UserID == userID {
+			continue
+		}
+		notified = append(notified, a.UserID)
+		if err := s.notifier.Notify(NotificationMessage{
+			UserID:     a.UserID,
+			Type:       NotificationTypeEventInvitation,
+			Title:      "イベントに招待されました",
+			Body:       fmt.Sprintf("%s（%s）", event.Title, event.StartDate.Format("2006-01-02 15:04")),
+			EntityType: "event",
+			EntityID:   event.ID,
+		}); err != nil {
+			log.Printf("招待通知の送信に失敗しました: %v", err)
+		}
+	}
+	s.invitations.SendInvitations(event, notified)
+
+	attendees, err := s.ListAttendees(eventID, userID, nil, IncludeOptions{"User"})
+	if err != nil {
+		return nil, err
+	}
+	return &InviteResult{Attendees: attendees, Conflicts: conflicts}, nil
+}
+
+// RemoveAttendee 参加者を外す（本人による辞退、または編集権限のあるユーザー）
+//
+// 出席の参加者を外して定員に空きが出た場合は、キャンセル待ちの人を繰り上げる。
+func (s *AttendeeService) RemoveAttendee(eventID, attendeeUserID, userID string) error {
+	var event *models.Event
+	var err error
+	if attendeeUserID == userID {
+		event, err = findEventForUser(s.db, eventID, userID)
+	} else {
+		event, err = findEditableEvent(s.db, eventID, userID)
+	}
+	if err != nil {
+		return err
+	}
+
+	var promoted []models.EventAttendee
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockEventAttendees(tx, event.ID); err != nil {
+			return err
+		}
+		var attendee models.EventAttendee
+		if err := tx.First(&attendee, "event_id = ? AND user_id = ?", eventID, attendeeUserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if err := tx.Delete(&attendee).Error; err != nil {
+			return err
+		}
+		if attendee.Status == models.AttendeeStatusAccepted {
+			promoted, err = promoteWaitlist(tx, event)
+		}
+		return err
+	}); err != nil {
+		return err
+	}
+	s.notifyPromoted(event, promoted)
+	return nil
+}
+
+// RespondToInvitation 招待に出欠を回答する
+//
+// 定員のあるイベントで出席者が定員に達している場合、出席の回答はキャンセル待ち（WAITLISTED）になる。
+// 出席から辞退・仮承諾に変えて空きが出た場合は、キャンセル待ちの人を回答順に繰り上げて通知する。
+func (s *AttendeeService) RespondToInvitation(eventID, userID string, input RSVPInput) (*models.EventAttendee, error) {
+	status := models.AttendeeStatus(strings.ToUpper(input.Status))
+	switch status {
+	case models.AttendeeStatusAccepted, models.AttendeeStatusDeclined, models.AttendeeStatusTentative:
+	default:
+		return nil, fmt.Errorf("%w: statusはACCEPTED・DECLINED・TENTATIVEのいずれかを指定してください", ErrInvalidInput)
+	}
+
+	event, err := findEventForUser(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var attendee models.EventAttendee
+	var promoted []models.EventAttendee
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockEventAttendees(tx, event.ID); err != nil {
+			return err
+		}
+		if err := tx.First(&attendee, "event_id = ? AND user_id = ?", eventID, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: このイベントに招待されていません", ErrForbidden)
+			}
+			return err
+		}
+		previous := attendee.Status
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"status":        status,
+			"comment":       input.Comment,
+			"responded_at":  &now,
+			"waitlisted_at": nil,
+		}
+		if status == models.AttendeeStatusAccepted && previous != models.AttendeeStatusAccepted {
+			full, err := eventIsFull(tx, event)
+			if err != nil {
+				return err
+			}
+			if full {
+				updates["status"] = models.AttendeeStatusWaitlisted
+				updates["waitlisted_at"] = attendee.WaitlistedAt
+				if attendee.WaitlistedAt == nil {
+					updates["waitlisted_at"] = &now
+				}
+			}
+		}
+		if err := tx.Model(&attendee).Updates(updates).Error; err != nil {
+			return err
+		}
+		if previous == models.AttendeeStatusAccepted && status != models.AttendeeStatusAccepted {
+			var err error
+			promoted, err = promoteWaitlist(tx, event)
+			return err
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if event.CreatorID != userID {
+		if err := s.notifier.Notify(NotificationMessage{
+			UserID:     event.CreatorID,
+			Type:       NotificationTypeEventRSVP,
+			Title:      "イベントへの出欠回答がありました",
+			Body:       fmt.Sprintf("%s: %s", event.Title, attendee.Status),
+			EntityType: "event",
+			EntityID:   event.ID,
+		}); err != nil {
+			log.Printf("出欠回答通知の送信に失敗しました: %v", err)
+		}
+	}
+	s.notifyPromoted(event, promoted)
+
+	if err := s.db.Preload("User").First(&attendee, "id = ?", attendee.ID).Error; err != nil {
+		return nil, err
+	}
+	return &attendee, nil
+}
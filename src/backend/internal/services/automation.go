@@ -0,0 +1,616 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// automationAppliedKey ルールの操作による保存につける設定（その保存では他のルールを実行しない）
+const automationAppliedKey = "automation:applied"
+
+// NotificationTypeAutomation 自動化のルールの NOTIFY による通知
+const NotificationTypeAutomation = "AUTOMATION"
+
+var automationTaskStatuses = map[models.TaskStatus]bool{
+	models.TaskStatusTodo:       true,
+	models.TaskStatusInProgress: true,
+	models.TaskStatusInReview:   true,
+	models.TaskStatusDone:       true,
+	models.TaskStatusCancelled:  true,
+}
+
+var automationPriorities = map[models.Priority]bool{
+	models.PriorityLow:    true,
+	models.PriorityMedium: true,
+	models.PriorityHigh:   true,
+	models.PriorityUrgent: true,
+}
+
+var automationRecipients = map[string]bool{"assignee": true, "creator": true, "watchers": true}
+
+// AutomationRuleInput 自動化のルールの作成・更新（更新では条件・操作を置き換える。enabled は省略すると作成時は有効、更新時は変えない）
+type AutomationRuleInput struct {
+	Name       string                       `json:"name" binding:"required,notblank,max=100"`
+	Enabled    *bool                        `json:"enabled"`
+	Trigger    models.AutomationTrigger     `json:"trigger" binding:"required,oneof=TASK_CREATED TASK_STATUS_CHANGED TASK_ASSIGNED TASK_OVERDUE"`
+	Conditions []models.AutomationCondition `json:"conditions" binding:"max=10,dive"`
+	Actions    []models.AutomationAction    `json:"actions" binding:"required,min=1,max=10,dive"`
+}
+
+// AutomationService チームのタスクの自動化のルール（きっかけ・条件・操作）の管理と実行
+//
+// タスクの作成・ステータスの変更・担当者の設定は、保存と同じトランザクションで非同期ジョブ（automation.run）に登録し、
+// コミットされた後にジョブで実行する。期限切れは定期実行で処理する。
+// ルールの操作による変更では他のルールを実行しない（ルール同士が連鎖して繰り返さないようにする）。
+type AutomationService struct {
+	db       *gorm.DB
+	jobs     *JobService
+	chat     *ChatIntegrationService
+	notifier *NotificationRouter
+}
+
+// automationRunMaxAttempts ルールの実行に失敗した場合に実行する回数（実行はトランザクションで行うため、失敗した実行の変更は残らない）
+const automationRunMaxAttempts = 3
+
+// automationRunJobParams 自動化のルールの実行のジョブの引数
+type automationRunJobParams struct {
+	TaskID         string                     `json:"taskId"`
+	PreviousStatus models.TaskStatus          `json:"previousStatus"`
+	Triggers       []models.AutomationTrigger `json:"triggers"`
+}
+
+func NewAutomationService(db *gorm.DB, jobs *JobService, chat *ChatIntegrationService, notifier *NotificationRouter) *AutomationService {
+	s := &AutomationService{db: db, jobs: jobs, chat: chat, notifier: notifier}
+	jobs.Register(models.JobTypeAutomationRun, s.runRulesJob)
+	return s
+}
+
+// ListRules チームの自動化のルール一覧（チームのメンバー）
+func (s *AutomationService) ListRules(teamID, userID string, sort SortOptions) ([]models.AutomationRule, error) {
+	if err := ensureTeamMember(s.db, teamID, userID); err != nil {
+		return nil, err
+	}
+	var rules []models.AutomationRule
+	if err := orderBy(s.db.Where("team_id = ?", teamID), sort, "created_at ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// CreateRule 自動化のルールを登録する（チームの管理者のみ）
+func (s *AutomationService) CreateRule(teamID, userID string, input AutomationRuleInput) (*models.AutomationRule, error) {
+	if err := s.ensureTeamAdmin(teamID, userID); err != nil {
+		return nil, err
+	}
+	rule := models.AutomationRule{TeamID: teamID, CreatorID: userID, Enabled: true}
+	if err := s.applyRuleInput(&rule, input); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// UpdateRule 自動化のルールを更新する（チームの管理者のみ）
+func (s *AutomationService) UpdateRule(ruleID, userID string, input AutomationRuleInput) (*models.AutomationRule, error) {
+	rule, err := s.findRuleForAdmin(ruleID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRuleInput(rule, input); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(rule).Error; err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule 自動化のルールを削除する（チームの管理者のみ）
+func (s *AutomationService) DeleteRule(ruleID, userID string) error {
+	rule, err := s.findRuleForAdmin(ruleID, userID)
+	if err != nil {
+		return err
+	}
+	return s.db.Delete(rule).Error
+}
+
+func (s *AutomationService) findRuleForAdmin(ruleID, userID string) (*models.AutomationRule, error) {
+	var rule models.AutomationRule
+	if err := s.db.First(&rule, "id = ?", ruleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if err := s.ensureTeamAdmin(rule.TeamID, userID); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (s *AutomationService) ensureTeamAdmin(teamID, userID string) error {
+	admin, err := isTeamAdmin(s.db, teamID, userID)
+	if err != nil {
+		return err
+	}
+	if !admin {
+		return fmt.Errorf("%w: 自動化のルールはチームの管理者のみ変更できます", ErrForbidden)
+	}
+	return nil
+}
+
+// applyRuleInput 条件・操作の内容を確かめてルールに反映する（担当者・ラベル・チャンネルはルールと同じチームのもののみ）
+func (s *AutomationService) applyRuleInput(rule *models.AutomationRule, input AutomationRuleInput) error {
+	for i, cond := range input.Conditions {
+		if err := validateAutomationCondition(cond, input.Trigger); err != nil {
+			return fmt.Errorf("%w: conditions[%d]: %v", ErrInvalidInput, i, err)
+		}
+	}
+	for i, action := range input.Actions {
+		problem, err := s.validateAutomationAction(rule.TeamID, action)
+		if err != nil {
+			return err
+		}
+		if problem != "" {
+			return fmt.Errorf("%w: actions[%d]: %s", ErrInvalidInput, i, problem)
+		}
+	}
+
+	rule.Name = strings.TrimSpace(input.Name)
+	rule.Trigger = input.Trigger
+	rule.Conditions = input.Conditions
+	rule.Actions = input.Actions
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+	return nil
+}
+
+func validateAutomationCondition(cond models.AutomationCondition, trigger models.AutomationTrigger) error {
+	if cond.Field == "previousStatus" && trigger != models.AutomationTriggerTaskStatusChanged {
+		return errors.New("previousStatusはTASK_STATUS_CHANGEDのルールでのみ使えます")
+	}
+	switch cond.Operator {
+	case "eq", "ne", "contains":
+		if len(cond.Values) != 1 {
+			return fmt.Errorf("%sでは値を1つ指定してください", cond.Operator)
+		}
+	case "in", "notIn":
+		if len(cond.Values) == 0 {
+			return fmt.Errorf("%sでは値を1つ以上指定してください", cond.Operator)
+		}
+	case "empty", "notEmpty":
+		if len(cond.Values) != 0 {
+			return fmt.Errorf("%sでは値を指定できません", cond.Operator)
+		}
+	}
+	for _, v := range cond.Values {
+		switch cond.Field {
+		case "status", "previousStatus":
+			if !automationTaskStatuses[models.TaskStatus(v)] {
+				return fmt.Errorf("ステータス（%s）が不正です", v)
+			}
+		case "priority":
+			if !automationPriorities[models.Priority(v)] {
+				return fmt.Errorf("優先度（%s）が不正です", v)
+			}
+		}
+	}
+	return nil
+}
+
+// validateAutomationAction 操作の種類ごとに必要な項目を確かめる（内容の誤りは problem で返す）
+func (s *AutomationService) validateAutomationAction(teamID string, action models.AutomationAction) (problem string, err error) {
+	switch action.Type {
+	case models.AutomationActionSetStatus:
+		if !automationTaskStatuses[action.Status] {
+			return "statusが不正です", nil
+		}
+	case models.AutomationActionSetPriority:
+		if !automationPriorities[action.Priority] {
+			return "priorityが不正です", nil
+		}
+	case models.AutomationActionSetAssignee:
+		if action.UserID == "" {
+			return "userIdは必須です", nil
+		}
+		if err := ensureTeamMember(s.db, teamID, action.UserID); err != nil {
+			if errors.Is(err, ErrForbidden) {
+				return "担当者はチームのメンバーを指定してください", nil
+			}
+			return "", err
+		}
+	case models.AutomationActionAddLabel, models.AutomationActionRemoveLabel:
+		if action.LabelID == "" {
+			return "labelIdは必須です", nil
+		}
+		var count int64
+		if err := s.db.Model(&models.Label{}).Where("id = ? AND team_id = ?", action.LabelID, teamID).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return "ラベルはチームのものを指定してください", nil
+		}
+	case models.AutomationActionPostToChannel:
+		if action.ChannelID == "" {
+			return "channelIdは必須です", nil
+		}
+		var count int64
+		if err := s.db.Model(&models.TeamChatChannel{}).Where("id = ? AND team_id = ?", action.ChannelID, teamID).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return "チャンネルはチームに設定したものを指定してください", nil
+		}
+	case models.AutomationActionNotify:
+		if len(action.Recipients) == 0 {
+			return "recipientsは必須です", nil
+		}
+		for _, r := range action.Recipients {
+			if !automationRecipients[r] {
+				return "recipientsはassignee・creator・watchersのいずれかで指定してください", nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// RegisterCallbacks タスクの作成・ステータスの変更・担当者の設定で自動化のルールを実行するgormのコールバックを登録する
+//
+// 変更は読み込んだ時点と比べて判定するため、読み込まずに一括更新した場合は実行しない。
+// ルールの実行は保存と同じトランザクションでジョブに登録する（ロールバックされた保存ではルールを実行しない）。
+func (s *AutomationService) RegisterCallbacks() error {
+	callbacks := s.db.Callback()
+	if err := callbacks.Create().After("gorm:create").Before("gorm:after_create").
+		Register("automation:after_create", s.taskSaved(true)); err != nil {
+		return err
+	}
+	return callbacks.Update().After("gorm:update").Before("gorm:after_update").
+		Register("automation:after_update", s.taskSaved(false))
+}
+
+func (s *AutomationService) taskSaved(created bool) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx.Error != nil || tx.RowsAffected == 0 || !tx.Statement.ReflectValue.IsValid() {
+			return
+		}
+		if _, applied := tx.Get(automationAppliedKey); applied {
+			return
+		}
+		value := reflect.Indirect(tx.Statement.ReflectValue)
+		var tasks []*models.Task
+		switch value.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < value.Len(); i++ {
+				elem := reflect.Indirect(value.Index(i))
+				if !elem.CanAddr() {
+					continue
+				}
+				if task, ok := elem.Addr().Interface().(*models.Task); ok {
+					tasks = append(tasks, task)
+				}
+			}
+		case reflect.Struct:
+			if value.CanAddr() {
+				if task, ok := value.Addr().Interface().(*models.Task); ok {
+					tasks = append(tasks, task)
+				}
+			}
+		}
+
+		for _, task := range tasks {
+			if task.ID == "" || task.TeamID == "" {
+				continue
+			}
+			var triggers []models.AutomationTrigger
+			previousStatus := task.LoadedStatus()
+			switch {
+			case created:
+				triggers = append(triggers, models.AutomationTriggerTaskCreated)
+			case previousStatus != "" && task.Status != "" && task.Status != previousStatus:
+				triggers = append(triggers, models.AutomationTriggerTaskStatusChanged)
+			}
+			if task.NewAssigneeID() != "" {
+				triggers = append(triggers, models.AutomationTriggerTaskAssigned)
+			}
+			if len(triggers) == 0 {
+				continue
+			}
+			params := automationRunJobParams{TaskID: task.ID, PreviousStatus: previousStatus, Triggers: triggers}
+			if err := s.jobs.EnqueueBackground(tx, models.JobTypeAutomationRun, params, automationRunMaxAttempts); err != nil {
+				tx.AddError(err)
+				return
+			}
+		}
+	}
+}
+
+// runRulesJob 保存をきっかけにしたルールを、保存したタスクに1つのトランザクションで実行する（登録した後に削除されたタスクには実行しない）
+func (s *AutomationService) runRulesJob(job *models.Job, _ io.Reader, _ func(int)) (*JobOutput, error) {
+	var params automationRunJobParams
+	if err := DecodeJobParams(job, &params); err != nil {
+		return nil, PermanentJobError(err)
+	}
+	return nil, s.db.Transaction(func(tx *gorm.DB) error {
+		var task models.Task
+		if err := tx.First(&task, "id = ?", params.TaskID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		return s.runRules(tx, &task, params.PreviousStatus, params.Triggers)
+	})
+}
+
+// runRules チームの有効なルールのうち、きっかけと条件を満たすものを作成順に実行する
+func (s *AutomationService) runRules(db *gorm.DB, task *models.Task, previousStatus models.TaskStatus, triggers []models.AutomationTrigger) error {
+	var rules []models.AutomationRule
+	if err := db.Where("team_id = ? AND trigger IN ? AND enabled = ?", task.TeamID, triggers, true).
+		Order("created_at ASC").Find(&rules).Error; err != nil {
+		return err
+	}
+	for i := range rules {
+		if !automationMatches(&rules[i], task, previousStatus) {
+			continue
+		}
+		if err := s.applyRule(db, &rules[i], task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunOverdueRules 期限を過ぎたタスクに、期限切れのきっかけのルールを実行する（定期実行）
+//
+// ルールの作成より前の期限は対象にしない。同じタスクの同じ期限に対しては一度だけ実行する（期限を延ばした場合は再び実行する）。
+func (s *AutomationService) RunOverdueRules() error {
+	var rules []models.AutomationRule
+	if err := s.db.Where("trigger = ? AND enabled = ?", models.AutomationTriggerTaskOverdue, true).
+		Order("created_at ASC").Find(&rules).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range rules {
+		rule := &rules[i]
+		var tasks []models.Task
+		if err := s.db.Where("team_id = ? AND due_date >= ? AND due_date <= ? AND status NOT IN ?", rule.TeamID,
+			rule.CreatedAt, now, []models.TaskStatus{models.TaskStatusDone, models.TaskStatusCancelled}).
+			Where("NOT EXISTS (SELECT 1 FROM automation_overdue_runs r WHERE r.rule_id = ? AND r.task_id = tasks.id AND r.due_date = tasks.due_date)", rule.ID).
+			Find(&tasks).Error; err != nil {
+			return err
+		}
+		for j := range tasks {
+			task := &tasks[j]
+			err := s.db.Transaction(func(tx *gorm.DB) error {
+				run := models.AutomationOverdueRun{RuleID: rule.ID, TaskID: task.ID, DueDate: *task.DueDate}
+				result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&run)
+				if result.Error != nil || result.RowsAffected == 0 {
+					return result.Error
+				}
+				if !automationMatches(rule, task, "") {
+					return nil
+				}
+				return s.applyRule(tx, rule, task)
+			})
+			if err != nil {
+				log.Printf("自動化のルール（%s）をタスク %s に実行できませんでした: %v", rule.ID, task.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// automationMatches ルールの条件をすべて満たすか
+func automationMatches(rule *models.AutomationRule, task *models.Task, previousStatus models.TaskStatus) bool {
+	for _, cond := range rule.Conditions {
+		var value string
+		switch cond.Field {
+		case "status":
+			value = string(task.Status)
+		case "previousStatus":
+			value = string(previousStatus)
+		case "priority":
+			value = string(task.Priority)
+		case "assigneeId":
+			if task.AssigneeID != nil {
+				value = *task.AssigneeID
+			}
+		case "creatorId":
+			value = task.CreatorID
+		case "title":
+			value = task.Title
+		default:
+			return false
+		}
+		if !automationCompare(cond, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func automationCompare(cond models.AutomationCondition, value string) bool {
+	switch cond.Operator {
+	case "eq":
+		return len(cond.Values) == 1 && value == cond.Values[0]
+	case "ne":
+		return len(cond.Values) == 1 && value != cond.Values[0]
+	case "in", "notIn":
+		found := false
+		for _, v := range cond.Values {
+			if v == value {
+				found = true
+				break
+			}
+		}
+		return found == (cond.Operator == "in")
+	case "contains":
+		return len(cond.Values) == 1 && strings.Contains(strings.ToLower(value), strings.ToLower(cond.Values[0]))
+	case "empty":
+		return value == ""
+	case "notEmpty":
+		return value != ""
+	}
+	return false
+}
+
+// applyRule ルールの操作をタスクに行う（db のトランザクションで保存し、保存した値を task にも反映する）
+//
+// 担当者がチームを抜けた・ラベルが削除されたなど、ルールの作成後に行えなくなった操作は飛ばす。
+func (s *AutomationService) applyRule(db *gorm.DB, rule *models.AutomationRule, task *models.Task) error {
+	db = db.Set(automationAppliedKey, true).Session(&gorm.Session{})
+	updates := map[string]interface{}{}
+	var posts []models.AutomationAction
+	var notifies []models.AutomationAction
+	for _, action := range rule.Actions {
+		switch action.Type {
+		case models.AutomationActionSetStatus:
+			updates["status"] = action.Status
+		case models.AutomationActionSetPriority:
+			updates["priority"] = action.Priority
+		case models.AutomationActionSetAssignee:
+			if err := ensureTeamMember(db, task.TeamID, action.UserID); err != nil {
+				if errors.Is(err, ErrForbidden) {
+					log.Printf("自動化のルール（%s）の担当者 %s はチームのメンバーではありません", rule.ID, action.UserID)
+					continue
+				}
+				return err
+			}
+			updates["assignee_id"] = action.UserID
+		case models.AutomationActionUnassign:
+			updates["assignee_id"] = nil
+		case models.AutomationActionAddLabel:
+			if err := db.Exec("INSERT INTO task_labels (task_id, label_id) SELECT ?, id FROM labels WHERE id = ? AND team_id = ? ON CONFLICT DO NOTHING",
+				task.ID, action.LabelID, task.TeamID).Error; err != nil {
+				return err
+			}
+		case models.AutomationActionRemoveLabel:
+			if err := db.Exec("DELETE FROM task_labels WHERE task_id = ? AND label_id = ?", task.ID, action.LabelID).Error; err != nil {
+				return err
+			}
+		case models.AutomationActionPostToChannel:
+			posts = append(posts, action)
+		case models.AutomationActionNotify:
+			notifies = append(notifies, action)
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := db.Model(&models.Task{ID: task.ID, TeamID: task.TeamID}).Updates(updates).Error; err != nil {
+			return err
+		}
+		if status, ok := updates["status"].(models.TaskStatus); ok {
+			task.Status = status
+		}
+		if priority, ok := updates["priority"].(models.Priority); ok {
+			task.Priority = priority
+		}
+		if assigneeID, ok := updates["assignee_id"]; ok {
+			if id, ok := assigneeID.(string); ok {
+				task.AssigneeID = &id
+			} else {
+				task.AssigneeID = nil
+			}
+		}
+		if task.Version > 0 {
+			task.Version++
+		}
+	}
+	now := time.Now()
+	if err := db.Model(&models.AutomationRule{}).Where("id = ?", rule.ID).UpdateColumn("last_triggered_at", now).Error; err != nil {
+		return err
+	}
+
+	for _, action := range posts {
+		s.postToChannel(db, rule, task, action)
+	}
+	for _, action := range notifies {
+		if err := s.notify(db, rule, task, action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postToChannel ルールのチャンネルへの投稿を、非同期ジョブとして登録する
+func (s *AutomationService) postToChannel(db *gorm.DB, rule *models.AutomationRule, task *models.Task, action models.AutomationAction) {
+	if s.chat == nil {
+		return
+	}
+	var channel models.TeamChatChannel
+	if err := db.Where("id = ? AND team_id = ?", action.ChannelID, task.TeamID).First(&channel).Error; err != nil {
+		log.Printf("自動化のルール（%s）の投稿先のチャンネルを取得できませんでした: %v", rule.ID, err)
+		return
+	}
+	msg := ChatMessage{
+		Text:      automationMessage(rule, action),
+		LinkTitle: task.Title,
+		LinkURL:   s.chat.taskURL(task.ID),
+	}
+	if err := s.chat.enqueuePost(db, &channel, msg); err != nil {
+		log.Printf("%sへの自動化のルール（%s）の投稿の登録に失敗しました: %v", channel.Provider, rule.ID, err)
+	}
+}
+
+// notify ルールの宛先（担当者・作成者・ウォッチャー）への通知を、ルールの操作と同じトランザクションでジョブに登録する
+func (s *AutomationService) notify(db *gorm.DB, rule *models.AutomationRule, task *models.Task, action models.AutomationAction) error {
+	if s.notifier == nil {
+		return nil
+	}
+	recipients := map[string]bool{}
+	for _, r := range action.Recipients {
+		switch r {
+		case "assignee":
+			if task.AssigneeID != nil && *task.AssigneeID != "" {
+				recipients[*task.AssigneeID] = true
+			}
+		case "creator":
+			recipients[task.CreatorID] = true
+		case "watchers":
+			var watcherIDs []string
+			if err := db.Model(&models.TaskWatcher{}).Where("task_id = ?", task.ID).Pluck("user_id", &watcherIDs).Error; err != nil {
+				return err
+			}
+			for _, id := range watcherIDs {
+				recipients[id] = true
+			}
+		}
+	}
+
+	msgs := make([]NotificationMessage, 0, len(recipients))
+	for userID := range recipients {
+		msgs = append(msgs, NotificationMessage{
+			UserID:     userID,
+			Type:       NotificationTypeAutomation,
+			Title:      automationMessage(rule, action),
+			Body:       task.Title,
+			EntityType: "task",
+			EntityID:   task.ID,
+		})
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return s.notifier.enqueueRoute(db, task.TeamID, msgs...)
+}
+
+func automationMessage(rule *models.AutomationRule, action models.AutomationAction) string {
+	if action.Message != "" {
+		return action.Message
+	}
+	return rule.Name
+}
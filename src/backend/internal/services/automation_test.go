@@ -0,0 +1,71 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// タスクの保存をきっかけにしたルールは、保存がコミットされた後にジョブで実行し、ロールバックされた保存では実行しない
+func TestAutomationRunsAfterCommit(t *testing.T) {
+	db := openTestDB(t)
+	seedTeam(t, db)
+	execAll(t, db, `INSERT INTO automation_rules (id, name, enabled, trigger, conditions, actions, team_id, creator_id)
+		VALUES ('r1', '作成したら優先度を上げる', true, 'TASK_CREATED', '[]', '[{"type":"SET_PRIORITY","priority":"HIGH"}]', 't1', 'u1')`)
+	jobs := NewJobService(db, nil, "secret", "/api/v1/jobs")
+	router := NewNotificationRouter(db, jobs)
+	automation := NewAutomationService(db, jobs, NewChatIntegrationService(db, jobs, "http://localhost"), router)
+	if err := automation.RegisterCallbacks(); err != nil {
+		t.Fatal(err)
+	}
+	newTask := func() *models.Task {
+		return &models.Task{ID: "k1", Title: "Task", TeamID: "t1", CreatorID: "u1", Priority: models.PriorityLow}
+	}
+	priority := func() models.Priority {
+		var task models.Task
+		if err := db.First(&task, "id = ?", "k1").Error; err != nil {
+			t.Fatal(err)
+		}
+		return task.Priority
+	}
+
+	rollback := errors.New("rollback")
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newTask()).Error; err != nil {
+			return err
+		}
+		return rollback
+	}); !errors.Is(err, rollback) {
+		t.Fatalf("トランザクションのエラーが %v です", err)
+	}
+	var count int64
+	if err := db.Model(&models.Job{}).Where("type = ?", models.JobTypeAutomationRun).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("ロールバックした保存のルールの実行が %d 件登録されています", count)
+	}
+
+	if err := db.Create(newTask()).Error; err != nil {
+		t.Fatal(err)
+	}
+	if p := priority(); p != models.PriorityLow {
+		t.Fatalf("コミットする前にルールを実行しました（優先度 %s）", p)
+	}
+	if err := jobs.RunPending(); err != nil {
+		t.Fatal(err)
+	}
+	if p := priority(); p != models.PriorityHigh {
+		t.Errorf("ジョブを実行した後の優先度が %s です（HIGH のはず）", p)
+	}
+	var job models.Job
+	if err := db.First(&job, "type = ?", models.JobTypeAutomationRun).Error; err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != models.JobStatusSucceeded {
+		t.Errorf("ジョブの状態が %s です（%s）", job.Status, job.Error)
+	}
+}
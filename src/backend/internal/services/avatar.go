@@ -0,0 +1,125 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"path"
+
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/storage"
+	"task-calendar-backend/internal/upload"
+
+	"gorm.io/gorm"
+)
+
+// avatarTypes アバターとして受け付ける画像の形式（内容から判定する）
+var avatarTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp"}
+
+// AvatarService ユーザーがアップロードしたアバター画像
+//
+// 画像はアップロードのたびに新しいキーで保存し、ユーザーの avatar を配信のURL（キーごとに変わる）にする。
+// URLが変わるため、配信ではブラウザに長くキャッシュさせられる。
+type AvatarService struct {
+	db      *gorm.DB
+	storage storage.Storage
+	maxSize int64
+	urlBase string
+}
+
+// NewAvatarService urlBase は配信のURLのパス（/api/v1/users など。ユーザーIDと /avatar を続ける）
+func NewAvatarService(db *gorm.DB, store storage.Storage, maxSize int64, urlBase string) *AvatarService {
+	return &AvatarService{db: db, storage: store, maxSize: maxSize, urlBase: urlBase}
+}
+
+// Limits アバター画像のアップロードの制限
+func (s *AvatarService) Limits() upload.Limits {
+	return upload.Limits{MaxSize: s.maxSize, AllowedTypes: avatarTypes}
+}
+
+// SetAvatar 画像を保存して自分のアバターにする（前にアップロードした画像は削除する）
+func (s *AvatarService) SetAvatar(userID string, file *upload.File) (*models.User, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	key, err := storage.NewKey("avatars/" + userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.storage.Save(key, file); err != nil {
+		return nil, err
+	}
+
+	previousKey := user.AvatarKey
+	avatar := s.urlBase + "/" + userID + "/avatar?v=" + path.Base(key)
+	if err := s.db.Model(user).Updates(map[string]interface{}{"avatar": avatar, "avatar_key": key}).Error; err != nil {
+		s.storage.Delete(key)
+		return nil, err
+	}
+	s.deleteFile(previousKey)
+	user.Avatar, user.AvatarKey = avatar, key
+	return user, nil
+}
+
+// DeleteAvatar 自分のアバターを削除する（URLを直接指定したアバターも消す）
+func (s *AvatarService) DeleteAvatar(userID string) (*models.User, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(user).Updates(map[string]interface{}{"avatar": "", "avatar_key": ""}).Error; err != nil {
+		return nil, err
+	}
+	s.deleteFile(user.AvatarKey)
+	user.Avatar, user.AvatarKey = "", ""
+	return user, nil
+}
+
+// OpenAvatar ユーザーがアップロードしたアバター画像と、その形式（アップロードしていない場合は ErrNotFound）
+func (s *AvatarService) OpenAvatar(userID string) (io.ReadCloser, string, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if user.AvatarKey == "" {
+		return nil, "", ErrNotFound
+	}
+	rc, err := s.storage.Open(user.AvatarKey)
+	if err != nil {
+		return nil, "", err
+	}
+	// 形式は保存していないため、先頭の内容から判定し直す（アップロード時に画像であることは確かめている）
+	head := make([]byte, 512)
+	n, err := io.ReadFull(rc, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		rc.Close()
+		return nil, "", err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head[:n]), rc), rc}, http.DetectContentType(head[:n]), nil
+}
+
+func (s *AvatarService) findUser(userID string) (*models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *AvatarService) deleteFile(key string) {
+	if key == "" {
+		return
+	}
+	if err := s.storage.Delete(key); err != nil {
+		log.Printf("アバター画像の削除に失敗しました（%s）: %v", key, err)
+	}
+}
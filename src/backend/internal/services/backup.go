@@ -0,0 +1,579 @@
+package services
+
+import (
+	"archive/zip"
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/database"
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/storage"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	backupFormat       = 1 // アーカイブの形式のバージョン（読み書きの方法を変えたら上げる）
+	backupManifestName = "manifest.json"
+	backupTablesDir    = "tables/" // テーブルごとの行（1行1つのJSON）
+	backupFilesDir     = "files/"  // 添付ファイル・アバター画像（ストレージのキーをそのまま名前にする）
+	backupRestoreBatch = 200       // 復元で一度に書き込む行数
+)
+
+// バックアップの対象を絞り込む条件で使う、チームのタスク・イベント・コメントのIDのサブクエリ
+const (
+	backupTeamTasks     = "SELECT id FROM tasks WHERE team_id = @team"
+	backupTeamEvents    = "SELECT id FROM events WHERE team_id = @team AND connection_id IS NULL"
+	backupTeamComments  = "SELECT id FROM comments WHERE task_id IN (" + backupTeamTasks + ")"
+	backupTeamWorkspace = "SELECT workspace_id FROM teams WHERE id = @team"
+)
+
+// backupTable バックアップするテーブル（backupTables の順に復元するため、外部キーで参照されるテーブルを先に並べる）
+type backupTable struct {
+	name  string
+	keys  []string // 主キー（復元で既存の行と突き合わせる）
+	team  string   // チームのバックアップで対象にする行の条件（@team はチームのID。空のテーブルはサーバー全体のバックアップのみ）
+	files []string // ストレージのキーを持つ列（ファイルもアーカイブに含める）
+	// shared チームのバックアップでは、参照のために含めるだけのテーブル（復元では既存の行を上書きしない）
+	shared bool
+}
+
+// backupTables バックアップするテーブル
+//
+// 非同期ジョブ・冪等キー・APIの利用状況・差分同期の記録など、運用のための一時的なデータは含めない。
+var backupTables = []backupTable{
+	{name: "workspaces", team: "id = (" + backupTeamWorkspace + ")", shared: true},
+	{name: "users", team: "workspace_id = (" + backupTeamWorkspace + ")", files: []string{"avatar_key"}, shared: true},
+	{name: "teams", team: "id = @team"},
+	{name: "team_members", team: "team_id = @team"},
+	{name: "calendar_subscriptions", team: "team_id = @team"},
+	{name: "calendar_connections"},
+	{name: "labels", team: "team_id = @team"},
+	{name: "rooms", team: "team_id = @team"},
+	{name: "event_categories", team: "team_id = @team"},
+	{name: "tasks", team: "team_id = @team"},
+	{name: "task_labels", keys: []string{"task_id", "label_id"}, team: "task_id IN (" + backupTeamTasks + ")"},
+	{name: "events", team: "team_id = @team AND connection_id IS NULL"},
+	{name: "event_labels", keys: []string{"event_id", "label_id"}, team: "event_id IN (" + backupTeamEvents + ")"},
+	{name: "comments", team: "task_id IN (" + backupTeamTasks + ")"},
+	{name: "checklist_items", team: "task_id IN (" + backupTeamTasks + ")"},
+	{name: "task_occurrences", team: "task_id IN (" + backupTeamTasks + ")"},
+	{name: "task_activities", team: "task_id IN (" + backupTeamTasks + ")"},
+	{name: "comment_revisions", team: "comment_id IN (" + backupTeamComments + ")"},
+	{name: "comment_reactions", team: "comment_id IN (" + backupTeamComments + ")"},
+	{name: "attachments", team: "task_id IN (" + backupTeamTasks + ")", files: []string{"storage_key"}},
+	{name: "comment_mentions", team: "comment_id IN (" + backupTeamComments + ")"},
+	{name: "task_watchers", team: "task_id IN (" + backupTeamTasks + ")"},
+	{name: "comment_reports", team: "task_id IN (" + backupTeamTasks + ")"},
+	{name: "user_warnings"},
+	{name: "email_reply_tokens", keys: []string{"token"}, team: "task_id IN (" + backupTeamTasks + ")"},
+	{name: "event_reply_tokens", keys: []string{"token"}, team: "event_id IN (" + backupTeamEvents + ")"},
+	{name: "event_exceptions", team: "event_id IN (" + backupTeamEvents + ")"},
+	{name: "cal_dav_resources", team: "event_id IN (" + backupTeamEvents + ")"},
+	{name: "event_attendees", team: "event_id IN (" + backupTeamEvents + ")"},
+	{name: "event_reminders", team: "event_id IN (" + backupTeamEvents + ")"},
+	{name: "reminder_deliveries", team: "reminder_id IN (SELECT id FROM event_reminders WHERE event_id IN (" + backupTeamEvents + "))"},
+	{name: "working_hours"},
+	{name: "event_check_ins", team: "event_id IN (" + backupTeamEvents + ")"},
+	{name: "daily_agenda_settings", keys: []string{"user_id"}},
+	{name: "shared_calendar_links", team: "team_id = @team"},
+	{name: "event_polls", team: "team_id = @team"},
+	{name: "event_poll_options", team: "poll_id IN (SELECT id FROM event_polls WHERE team_id = @team)"},
+	{name: "event_poll_invitees", team: "poll_id IN (SELECT id FROM event_polls WHERE team_id = @team)"},
+	{name: "event_poll_votes", team: "poll_id IN (SELECT id FROM event_polls WHERE team_id = @team)"},
+	{name: "materialized_occurrences", keys: []string{"event_id", "recurrence_id"}, team: "event_id IN (" + backupTeamEvents + ")"},
+	{name: "notifications"},
+	{name: "password_reset_tokens"},
+	{name: "chat_event_posts", team: "event_id IN (" + backupTeamEvents + ")"},
+	{name: "chat_task_posts", team: "task_id IN (" + backupTeamTasks + ")"},
+	{name: "team_chat_channels", team: "team_id = @team"},
+	{name: "weekly_digest_settings", keys: []string{"user_id"}},
+	{name: "notification_preferences"},
+	{name: "team_notification_preferences", team: "team_id = @team"},
+	{name: "webhook_endpoints", team: "team_id = @team"},
+	{name: "webhook_deliveries", team: "endpoint_id IN (SELECT id FROM webhook_endpoints WHERE team_id = @team)"},
+	{name: "webhook_delivery_attempts", team: "delivery_id IN (SELECT id FROM webhook_deliveries WHERE endpoint_id IN (SELECT id FROM webhook_endpoints WHERE team_id = @team))"},
+	{name: "device_tokens"},
+	{name: "automation_rules", team: "team_id = @team"},
+	{name: "automation_overdue_runs", team: "rule_id IN (SELECT id FROM automation_rules WHERE team_id = @team)"},
+}
+
+func (t backupTable) primaryKeys() []string {
+	if len(t.keys) == 0 {
+		return []string{"id"}
+	}
+	return t.keys
+}
+
+// BackupManifest アーカイブの内容（manifest.json）
+type BackupManifest struct {
+	Format        int                `json:"format"`
+	SchemaVersion int64              `json:"schemaVersion"` // 作成したときのマイグレーションのバージョン（同じバージョンのサーバーにのみ復元できる）
+	TeamID        *string            `json:"teamId,omitempty"`
+	CreatedAt     time.Time          `json:"createdAt"`
+	Tables        []BackupTableEntry `json:"tables"`
+	Files         int                `json:"files"`
+}
+
+// BackupTableEntry アーカイブに含めたテーブル
+type BackupTableEntry struct {
+	Name        string   `json:"name"`
+	Rows        int      `json:"rows"`
+	TimeColumns []string `json:"timeColumns,omitempty"` // 日時の列（復元で文字列から戻す）
+}
+
+// BackupRestoreReport 復元の結果
+type BackupRestoreReport struct {
+	TeamID        *string            `json:"teamId,omitempty"`
+	SchemaVersion int64              `json:"schemaVersion"`
+	CreatedAt     time.Time          `json:"createdAt"` // アーカイブを作成した日時
+	Tables        []BackupTableEntry `json:"tables"`    // 書き込んだ行数
+	Files         int                `json:"files"`
+	MissingFiles  int                `json:"missingFiles"` // 行はあるがアーカイブにないファイル
+}
+
+// BackupService チームまたはサーバー全体のデータのアーカイブ（zip）の作成と、アーカイブからの復元（運営者向け）
+//
+// 作成は1つの読み取り専用のトランザクション（PostgreSQLは REPEATABLE READ）で読み出すため、実行中の変更が混ざらない。
+// 復元は1つのトランザクションで、主キーが同じ行を上書きし、ない行を追加する（アーカイブにない行は削除しない）。
+// チームのアーカイブでは、参照のために含めたワークスペースとユーザーは既存の行を上書きしない。
+// 復元した変更は差分同期・Webhookには伝えないため、クライアントは全件を同期し直す必要がある。
+type BackupService struct {
+	db      *gorm.DB
+	storage storage.Storage
+}
+
+func NewBackupService(db *gorm.DB, storage storage.Storage) *BackupService {
+	return &BackupService{db: db, storage: storage}
+}
+
+// Export teamID のチーム（nil の場合はサーバー全体）のデータのアーカイブのファイル名と、zipを書き出す関数を返す
+//
+// アーカイブはメモリにためずに w に書き出す（添付ファイルを含めると大きくなるため）。
+func (s *BackupService) Export(teamID *string, progress func(int)) (string, func(w io.Writer) error, error) {
+	if teamID != nil {
+		var count int64
+		if err := s.db.Model(&models.Team{}).Where("id = ?", *teamID).Count(&count).Error; err != nil {
+			return "", nil, err
+		}
+		if count == 0 {
+			return "", nil, ErrNotFound
+		}
+	}
+
+	now := time.Now().UTC()
+	filename := "backup-" + now.Format("20060102-150405") + ".zip"
+	if teamID != nil {
+		filename = "backup-team-" + *teamID + "-" + now.Format("20060102-150405") + ".zip"
+	}
+	write := func(w io.Writer) error {
+		return s.writeArchive(w, BackupManifest{Format: backupFormat, TeamID: teamID, CreatedAt: now, Tables: []BackupTableEntry{}}, progress)
+	}
+	return filename, write, nil
+}
+
+// writeArchive 1つのスナップショットから読み出した行と、行が参照するファイルをzipに書き出す（manifest.json は最後に書く）
+func (s *BackupService) writeArchive(w io.Writer, manifest BackupManifest, progress func(int)) error {
+	archive := zip.NewWriter(w)
+	fileKeys := map[string]struct{}{}
+	err := s.readTransaction(func(tx *gorm.DB) error {
+		version, err := database.SchemaVersion(tx)
+		if err != nil {
+			return err
+		}
+		manifest.SchemaVersion = version
+		for i, table := range backupTables {
+			if manifest.TeamID != nil && table.team == "" {
+				continue
+			}
+			entry, err := exportBackupTable(tx, archive, table, manifest.TeamID, fileKeys)
+			if err != nil {
+				return fmt.Errorf("%s のバックアップに失敗しました: %w", table.name, err)
+			}
+			manifest.Tables = append(manifest.Tables, *entry)
+			progress(5 + 75*(i+1)/len(backupTables))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for key := range fileKeys {
+		saved, err := s.exportBackupFile(archive, key)
+		if err != nil {
+			return err
+		}
+		if saved {
+			manifest.Files++
+		}
+	}
+	progress(90)
+
+	mw, err := archive.Create(backupManifestName)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(mw).Encode(manifest); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// readTransaction 作成中に変更が混ざらないよう、1つのスナップショットから読み出すトランザクションで fn を実行する
+//
+// SQLiteは開発・テスト用のため、トランザクションを使わない（読み出し中のトランザクションがあると、進み具合の記録を書き込めない）。
+func (s *BackupService) readTransaction(fn func(tx *gorm.DB) error) error {
+	if database.IsSQLite(s.db) {
+		return fn(s.db)
+	}
+	return s.db.Transaction(fn, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+}
+
+// exportBackupTable テーブルの行を主キーの順に tables/<テーブル名>.jsonl に書き出す（ファイルの列のキーは fileKeys に集める）
+func exportBackupTable(tx *gorm.DB, archive *zip.Writer, table backupTable, teamID *string, fileKeys map[string]struct{}) (*BackupTableEntry, error) {
+	query := tx.Table(table.name).Order(strings.Join(table.primaryKeys(), ", "))
+	if teamID != nil {
+		query = query.Where(table.team, sql.Named("team", *teamID))
+	}
+	rows, err := query.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	w, err := archive.Create(backupTablesDir + table.name + ".jsonl")
+	if err != nil {
+		return nil, err
+	}
+	encoder := json.NewEncoder(w)
+	entry := &BackupTableEntry{Name: table.name}
+	timeColumns := map[string]struct{}{}
+	for rows.Next() {
+		row := map[string]interface{}{}
+		if err := tx.ScanRows(rows, &row); err != nil {
+			return nil, err
+		}
+		for column, value := range row {
+			if _, ok := value.(time.Time); ok {
+				timeColumns[column] = struct{}{}
+			}
+		}
+		for _, column := range table.files {
+			if key, ok := row[column].(string); ok && key != "" {
+				fileKeys[key] = struct{}{}
+			}
+		}
+		if err := encoder.Encode(row); err != nil {
+			return nil, err
+		}
+		entry.Rows++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for column := range timeColumns {
+		entry.TimeColumns = append(entry.TimeColumns, column)
+	}
+	sort.Strings(entry.TimeColumns)
+	return entry, nil
+}
+
+// exportBackupFile ストレージのファイルを files/<キー> に書き出す（見つからないファイルはログに出して飛ばす）
+func (s *BackupService) exportBackupFile(archive *zip.Writer, key string) (bool, error) {
+	rc, err := s.storage.Open(key)
+	if err != nil {
+		log.Printf("バックアップするファイルを開けませんでした（%s）: %v", key, err)
+		return false, nil
+	}
+	defer rc.Close()
+	w, err := archive.Create(backupFilesDir + key)
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(w, rc); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Restore アーカイブからデータを復元する（アーカイブを作成したときと同じスキーマのバージョンのサーバーにのみ復元できる）
+//
+// zipは途中から読み出すため、input がファイル（ストレージのファイルなど）の場合はそのまま、それ以外は一時ファイルに書き出して読む。
+func (s *BackupService) Restore(input io.Reader, progress func(int)) (*BackupRestoreReport, error) {
+	file, cleanup, err := backupArchiveFile(input)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(file, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("%w: バックアップのアーカイブ（zip）ではありません", ErrInvalidInput)
+	}
+	entries := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		entries[f.Name] = f
+	}
+	manifest, err := readBackupManifest(entries)
+	if err != nil {
+		return nil, err
+	}
+	version, err := database.SchemaVersion(s.db)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.SchemaVersion != version {
+		return nil, fmt.Errorf("%w: アーカイブのスキーマのバージョン（%d）がサーバー（%d）と異なります",
+			ErrInvalidInput, manifest.SchemaVersion, version)
+	}
+
+	tables := make(map[string]BackupTableEntry, len(manifest.Tables))
+	for _, entry := range manifest.Tables {
+		tables[entry.Name] = entry
+	}
+	for name := range tables {
+		if !knownBackupTable(name) {
+			return nil, fmt.Errorf("%w: アーカイブに不明なテーブル（%s）が含まれています", ErrInvalidInput, name)
+		}
+	}
+
+	report := &BackupRestoreReport{
+		TeamID:        manifest.TeamID,
+		SchemaVersion: manifest.SchemaVersion,
+		CreatedAt:     manifest.CreatedAt,
+		Tables:        []BackupTableEntry{},
+	}
+	fileKeys := map[string]struct{}{}
+	tableProgress := progress
+	if database.IsSQLite(s.db) {
+		// SQLiteは書き込み中のトランザクションがあると、進み具合の記録を書き込めない
+		tableProgress = func(int) {}
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for i, table := range backupTables {
+			entry, ok := tables[table.name]
+			if !ok {
+				continue
+			}
+			f, ok := entries[backupTablesDir+table.name+".jsonl"]
+			if !ok {
+				return fmt.Errorf("%w: アーカイブに %s の行がありません", ErrInvalidInput, table.name)
+			}
+			keepExisting := manifest.TeamID != nil && table.shared
+			restored, err := restoreBackupTable(tx, f, table, entry.TimeColumns, keepExisting, fileKeys)
+			if err != nil {
+				return err
+			}
+			report.Tables = append(report.Tables, BackupTableEntry{Name: table.name, Rows: restored})
+			tableProgress(5 + 80*(i+1)/len(backupTables))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for key := range fileKeys {
+		f, ok := entries[backupFilesDir+key]
+		if !ok {
+			report.MissingFiles++
+			continue
+		}
+		if err := s.restoreBackupFile(f, key); err != nil {
+			return nil, err
+		}
+		report.Files++
+	}
+	return report, nil
+}
+
+// backupArchiveFile input を途中から読み出せるファイルにする（終わったら cleanup を呼ぶ）
+func backupArchiveFile(input io.Reader) (*os.File, func(), error) {
+	if f, ok := input.(*os.File); ok {
+		return f, func() {}, nil
+	}
+	f, err := os.CreateTemp("", "backup-*.zip")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() {
+		f.Close()
+		os.Remove(f.Name())
+	}
+	if _, err := io.Copy(f, input); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return f, cleanup, nil
+}
+
+func readBackupManifest(entries map[string]*zip.File) (*BackupManifest, error) {
+	f, ok := entries[backupManifestName]
+	if !ok {
+		return nil, fmt.Errorf("%w: アーカイブに %s がありません", ErrInvalidInput, backupManifestName)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s を読み出せません", ErrInvalidInput, backupManifestName)
+	}
+	defer rc.Close()
+	var manifest BackupManifest
+	if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: %s の形式が不正です", ErrInvalidInput, backupManifestName)
+	}
+	if manifest.Format != backupFormat {
+		return nil, fmt.Errorf("%w: アーカイブの形式（%d）に対応していません", ErrInvalidInput, manifest.Format)
+	}
+	return &manifest, nil
+}
+
+func knownBackupTable(name string) bool {
+	for _, table := range backupTables {
+		if table.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreBackupTable アーカイブの行を backupRestoreBatch 件ずつ書き込む（主キーが同じ行は上書きし、keepExisting の場合はそのままにする）
+//
+// 列の名前はSQLに使うため、テーブルにある列のみ受け付ける。
+func restoreBackupTable(tx *gorm.DB, f *zip.File, table backupTable, timeColumns []string, keepExisting bool, fileKeys map[string]struct{}) (int, error) {
+	columnTypes, err := tx.Migrator().ColumnTypes(table.name)
+	if err != nil {
+		return 0, err
+	}
+	columns := make(map[string]struct{}, len(columnTypes))
+	for _, columnType := range columnTypes {
+		columns[columnType.Name()] = struct{}{}
+	}
+	isTime := make(map[string]bool, len(timeColumns))
+	for _, column := range timeColumns {
+		isTime[column] = true
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s の行を読み出せません", ErrInvalidInput, table.name)
+	}
+	defer rc.Close()
+	decoder := json.NewDecoder(bufio.NewReader(rc))
+	decoder.UseNumber()
+
+	restored := 0
+	var batch []map[string]interface{}
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := tx.Table(table.name).Clauses(backupConflict(table, batch[0], keepExisting)).
+			Create(&batch).Error; err != nil {
+			return fmt.Errorf("%s の復元に失敗しました: %w", table.name, err)
+		}
+		restored += len(batch)
+		batch = nil
+		return nil
+	}
+	for {
+		var row map[string]interface{}
+		if err := decoder.Decode(&row); err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, fmt.Errorf("%w: %s の行の形式が不正です", ErrInvalidInput, table.name)
+		}
+		for column, value := range row {
+			if _, ok := columns[column]; !ok {
+				return 0, fmt.Errorf("%w: %s に列 %s はありません", ErrInvalidInput, table.name, column)
+			}
+			if row[column], err = backupValue(value, isTime[column]); err != nil {
+				return 0, fmt.Errorf("%w: %s の列 %s の値が不正です", ErrInvalidInput, table.name, column)
+			}
+		}
+		for _, column := range table.files {
+			if key, ok := row[column].(string); ok && key != "" {
+				fileKeys[key] = struct{}{}
+			}
+		}
+		batch = append(batch, row)
+		if len(batch) >= backupRestoreBatch {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+	return restored, nil
+}
+
+// backupConflict 主キーが同じ行がある場合の扱い（keepExisting でなければ、主キー以外の列をアーカイブの値で上書きする）
+func backupConflict(table backupTable, row map[string]interface{}, keepExisting bool) clause.OnConflict {
+	keys := table.primaryKeys()
+	conflict := clause.OnConflict{}
+	isKey := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		conflict.Columns = append(conflict.Columns, clause.Column{Name: key})
+		isKey[key] = true
+	}
+	var updates []string
+	for column := range row {
+		if !isKey[column] {
+			updates = append(updates, column)
+		}
+	}
+	if keepExisting || len(updates) == 0 {
+		conflict.DoNothing = true
+		return conflict
+	}
+	conflict.DoUpdates = clause.AssignmentColumns(updates)
+	return conflict
+}
+
+// backupValue JSONから読んだ値を書き込む値に戻す（数値は整数を優先し、日時の列は time.Time にする）
+func backupValue(value interface{}, isTime bool) (interface{}, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		return v.Float64()
+	case string:
+		if isTime {
+			return time.Parse(time.RFC3339Nano, v)
+		}
+		return v, nil
+	case nil, bool:
+		return v, nil
+	default:
+		return nil, errors.New("対応していない値です")
+	}
+}
+
+// restoreBackupFile アーカイブのファイルをストレージに書き込む（同じキーのファイルは置き換える）
+func (s *BackupService) restoreBackupFile(f *zip.File, key string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: ファイル %s を読み出せません", ErrInvalidInput, key)
+	}
+	defer rc.Close()
+	if _, err := s.storage.Save(key, rc); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,448 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/ical"
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// CalDAVで公開するカレンダー名
+const (
+	CalDAVPersonalCalendar = "personal"
+	caldavTeamPrefix       = "team-"
+)
+
+// CalDAVCalendar CalDAVのカレンダーコレクション（個人カレンダーと所属チームごと）
+type CalDAVCalendar struct {
+	Name        string
+	DisplayName string
+	TeamID      *string
+	CTag        string
+}
+
+// CalDAVObject カレンダーオブジェクトリソース（1イベント = 1つの.ics）
+type CalDAVObject struct {
+	Name    string
+	ETag    string
+	Data    []byte
+	EventID string
+}
+
+// CalDAVPrecondition PUT / DELETE の条件付きリクエストヘッダー
+type CalDAVPrecondition struct {
+	IfMatch     string // 指定時は現在のETagと一致する場合のみ実行
+	IfNoneMatch bool   // If-None-Match: * （新規作成のみ許可）
+}
+
+type CalDAVService struct {
+	db           *gorm.DB
+	eventService *EventService
+}
+
+func NewCalDAVService(db *gorm.DB, eventService *EventService) *CalDAVService {
+	return &CalDAVService{db: db, eventService: eventService}
+}
+
+// Calendars ユーザーが利用できるカレンダー一覧
+func (s *CalDAVService) Calendars(userID string) ([]CalDAVCalendar, error) {
+	calendars := []CalDAVCalendar{{Name: CalDAVPersonalCalendar, DisplayName: "個人"}}
+
+	var teams []models.Team
+	if err := s.db.Joins("JOIN team_members ON team_members.team_id = teams.id").
+		Where("team_members.user_id = ? AND team_members.status = ?", userID, models.TeamMemberStatusActive).
+		Order("teams.name ASC").Find(&teams).Error; err != nil {
+		return nil, err
+	}
+	for i := range teams {
+		calendars = append(calendars, CalDAVCalendar{
+			Name:        caldavTeamPrefix + teams[i].ID,
+			DisplayName: teams[i].Name,
+			TeamID:      &teams[i].ID,
+		})
+	}
+
+	for i := range calendars {
+		ctag, err := s.ctag(userID, &calendars[i])
+		if err != nil {
+			return nil, err
+		}
+		calendars[i].CTag = ctag
+	}
+	return calendars, nil
+}
+
+// Calendar カレンダーを取得し、アクセス権を確認する
+func (s *CalDAVService) Calendar(userID, name string) (*CalDAVCalendar, error) {
+	cal := &CalDAVCalendar{Name: name}
+	switch {
+	case name == CalDAVPersonalCalendar:
+		cal.DisplayName = "個人"
+	case strings.HasPrefix(name, caldavTeamPrefix):
+		teamID := strings.TrimPrefix(name, caldavTeamPrefix)
+		if err := ensureTeamMember(s.db, teamID, userID); err != nil {
+			return nil, err
+		}
+		var team models.Team
+		if err := s.db.First(&team, "id = ?", teamID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+		cal.DisplayName = team.Name
+		cal.TeamID = &team.ID
+	default:
+		return nil, ErrNotFound
+	}
+
+	ctag, err := s.ctag(userID, cal)
+	if err != nil {
+		return nil, err
+	}
+	cal.CTag = ctag
+	return cal, nil
+}
+
+// Objects カレンダー内のすべてのオブジェクト
+func (s *CalDAVService) Objects(userID string, cal *CalDAVCalendar) ([]CalDAVObject, error) {
+	var events []models.Event
+	if err := s.db.Scopes(calendarEventsScope(userID, cal)).Order("start_date ASC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return s.toObjects(cal, events)
+}
+
+// Object リソース名でオブジェクトを取得する
+func (s *CalDAVService) Object(userID string, cal *CalDAVCalendar, name string) (*CalDAVObject, error) {
+	event, err := s.findObjectEvent(userID, cal, name)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := s.toObjects(cal, []models.Event{*event})
+	if err != nil {
+		return nil, err
+	}
+	return &objects[0], nil
+}
+
+// PutObject iCalendarデータでオブジェクトを作成・更新する（作成した場合はtrue）
+func (s *CalDAVService) PutObject(userID string, cal *CalDAVCalendar, name string, r io.Reader, cond CalDAVPrecondition) (*CalDAVObject, bool, error) {
+	existing, err := s.findObjectEvent(userID, cal, name)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, false, err
+	}
+	if err := s.checkPrecondition(cal, existing, cond); err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		if err := s.ensureCanEdit(existing, userID); err != nil {
+			return nil, false, err
+		}
+	}
+
+	imported, err := decodeCalendarObject(r)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// 同じカレンダー内でUIDが重複するオブジェクトは作れない
+	if imported.event.ICalUID != "" {
+		query := s.db.Model(&models.Event{}).Scopes(calendarEventsScope(userID, cal)).
+			Where("ical_uid = ?", imported.event.ICalUID)
+		if existing != nil {
+			query = query.Where("events.id <> ?", existing.ID)
+		}
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return nil, false, err
+		}
+		if count > 0 {
+			return nil, false, fmt.Errorf("%w: 同じUIDのイベントが既に存在します", ErrInvalidInput)
+		}
+	}
+
+	eventID := ""
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if existing == nil {
+			event := imported.event
+			event.CreatorID = userID
+			event.TeamID = cal.TeamID
+			if err := tx.Create(&event).Error; err != nil {
+				return err
+			}
+			eventID = event.ID
+			if err := tx.Create(&models.CalDAVResource{Calendar: cal.Name, Name: name, EventID: eventID}).Error; err != nil {
+				return err
+			}
+		} else {
+			eventID = existing.ID
+			if err := tx.Model(existing).Updates(map[string]interface{}{
+				"title":        imported.event.Title,
+				"description":  imported.event.Description,
+				"start_date":   imported.event.StartDate,
+				"end_date":     imported.event.EndDate,
+				"all_day":      imported.event.AllDay,
+				"time_zone":    imported.event.TimeZone,
+				"location":     imported.event.Location,
+				"is_recurring": imported.event.IsRecurring,
+				"recurrence":   imported.event.Recurrence,
+				"type":         imported.event.Type,
+				"visibility":   imported.event.Visibility,
+				"status":       imported.event.Status,
+				"ical_uid":     imported.event.ICalUID,
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("event_id = ?", eventID).Delete(&models.EventException{}).Error; err != nil {
+				return err
+			}
+		}
+
+		for i := range imported.exceptions {
+			imported.exceptions[i].EventID = eventID
+			if err := tx.Create(&imported.exceptions[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	object, err := s.Object(userID, cal, name)
+	if err != nil {
+		return nil, false, err
+	}
+	return object, existing == nil, nil
+}
+
+// DeleteObject オブジェクトを削除する（イベントは論理削除し、アプリから復元できるよう回ごとの変更も残す）
+func (s *CalDAVService) DeleteObject(userID string, cal *CalDAVCalendar, name string, cond CalDAVPrecondition) error {
+	event, err := s.findObjectEvent(userID, cal, name)
+	if err != nil {
+		return err
+	}
+	if err := s.checkPrecondition(cal, event, cond); err != nil {
+		return err
+	}
+	if err := s.ensureCanEdit(event, userID); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("event_id = ?", event.ID).Delete(&models.CalDAVResource{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(event).Error
+	})
+}
+
+func (s *CalDAVService) checkPrecondition(cal *CalDAVCalendar, existing *models.Event, cond CalDAVPrecondition) error {
+	if cond.IfNoneMatch && existing != nil {
+		return ErrPreconditionFailed
+	}
+	if cond.IfMatch == "" || (cond.IfMatch == "*" && existing != nil) {
+		return nil
+	}
+	if existing == nil {
+		return ErrPreconditionFailed
+	}
+	objects, err := s.toObjects(cal, []models.Event{*existing})
+	if err != nil {
+		return err
+	}
+	if objects[0].ETag != cond.IfMatch {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// ensureCanEdit 作成者またはチーム管理者のみ既存イベントを変更できる
+func (s *CalDAVService) ensureCanEdit(event *models.Event, userID string) error {
+	_, err := findEditableEvent(s.db, event.ID, userID)
+	return err
+}
+
+// findObjectEvent リソース名に対応するイベントを探す（対応表になければ "<イベントID>.ics" とみなす）
+func (s *CalDAVService) findObjectEvent(userID string, cal *CalDAVCalendar, name string) (*models.Event, error) {
+	eventID := strings.TrimSuffix(name, ".ics")
+	var resource models.CalDAVResource
+	err := s.db.First(&resource, "calendar = ? AND name = ?", cal.Name, name).Error
+	switch {
+	case err == nil:
+		eventID = resource.EventID
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	var event models.Event
+	if err := s.db.Scopes(calendarEventsScope(userID, cal)).First(&event, "events.id = ?", eventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if resource.ID == "" {
+		// 別名で公開しているイベントをIDの名前で参照させない
+		var count int64
+		if err := s.db.Model(&models.CalDAVResource{}).Where("event_id = ?", event.ID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, ErrNotFound
+		}
+	}
+	return &event, nil
+}
+
+func (s *CalDAVService) toObjects(cal *CalDAVCalendar, events []models.Event) ([]CalDAVObject, error) {
+	exceptions, err := s.eventService.loadExceptions(events)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string)
+	if len(events) > 0 {
+		ids := make([]string, len(events))
+		for i := range events {
+			ids[i] = events[i].ID
+		}
+		var resources []models.CalDAVResource
+		if err := s.db.Where("calendar = ? AND event_id IN ?", cal.Name, ids).Find(&resources).Error; err != nil {
+			return nil, err
+		}
+		for _, r := range resources {
+			names[r.EventID] = r.Name
+		}
+	}
+
+	objects := make([]CalDAVObject, 0, len(events))
+	for i := range events {
+		event := &events[i]
+		data, err := encodeCalendarObject(event, exceptions[event.ID])
+		if err != nil {
+			return nil, err
+		}
+		name, ok := names[event.ID]
+		if !ok {
+			name = event.ID + ".ics"
+		}
+		sum := sha1.Sum(data)
+		objects = append(objects, CalDAVObject{
+			Name:    name,
+			ETag:    `"` + hex.EncodeToString(sum[:]) + `"`,
+			Data:    data,
+			EventID: event.ID,
+		})
+	}
+	return objects, nil
+}
+
+// ctag カレンダーの内容が変わると変化する値（イベント・例外の件数と最終更新日時から作る）
+func (s *CalDAVService) ctag(userID string, cal *CalDAVCalendar) (string, error) {
+	var eventStats, exceptionStats struct {
+		Count     int64
+		UpdatedAt *time.Time
+	}
+	if err := s.db.Model(&models.Event{}).Scopes(calendarEventsScope(userID, cal)).
+		Select("COUNT(*) AS count, MAX(events.updated_at) AS updated_at").Scan(&eventStats).Error; err != nil {
+		return "", err
+	}
+	if err := s.db.Model(&models.EventException{}).
+		Joins("JOIN events ON events.id = event_exceptions.event_id AND events.deleted_at IS NULL").
+		Scopes(calendarEventsScope(userID, cal)).
+		Select("COUNT(*) AS count, MAX(event_exceptions.updated_at) AS updated_at").Scan(&exceptionStats).Error; err != nil {
+		return "", err
+	}
+
+	unix := func(t *time.Time) int64 {
+		if t == nil {
+			return 0
+		}
+		return t.UnixNano()
+	}
+	return fmt.Sprintf("%d-%d-%d-%d", eventStats.Count, unix(eventStats.UpdatedAt),
+		exceptionStats.Count, unix(exceptionStats.UpdatedAt)), nil
+}
+
+// calendarEventsScope カレンダーに含まれるイベントに絞り込む
+func calendarEventsScope(userID string, cal *CalDAVCalendar) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if cal.TeamID != nil {
+			return db.Where("events.team_id = ?", *cal.TeamID).Scopes(hidePrivateEventsScope(userID))
+		}
+		return db.Where("events.team_id IS NULL AND events.creator_id = ?", userID)
+	}
+}
+
+// encodeCalendarObject 1イベント分のVCALENDARを出力する（DTSTAMPは更新日時に固定し、ETagを安定させる）
+func encodeCalendarObject(event *models.Event, exceptions []models.EventException) ([]byte, error) {
+	cal := ical.NewComponent("VCALENDAR")
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", icalProductID)
+	for _, vtimezone := range timezoneComponents([]models.Event{*event}, time.UTC) {
+		cal.AddComponent(vtimezone)
+	}
+	for _, vevent := range eventComponents(event, exceptions, time.UTC, ical.FormatUTC(event.UpdatedAt)) {
+		cal.AddComponent(vevent)
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, cal); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeCalendarObject CalDAVのPUT本文（1つのUIDのVEVENT群）をイベントに変換する
+func decodeCalendarObject(r io.Reader) (*importedEvent, error) {
+	cal, err := ical.Decode(r)
+	if err != nil {
+		if errors.Is(err, ical.ErrMalformed) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		return nil, err
+	}
+	if cal.Name != "VCALENDAR" {
+		return nil, fmt.Errorf("%w: VCALENDARではありません", ErrInvalidInput)
+	}
+
+	var master *ical.Component
+	var overrides []*ical.Component
+	uid := ""
+	for _, vevent := range cal.Children("VEVENT") {
+		p := vevent.Get("UID")
+		if p == nil {
+			return nil, fmt.Errorf("%w: UIDがありません", ErrInvalidInput)
+		}
+		if uid != "" && p.Value != uid {
+			return nil, fmt.Errorf("%w: 1つのリソースに複数のUIDは含められません", ErrInvalidInput)
+		}
+		uid = p.Value
+		if vevent.Get("RECURRENCE-ID") != nil {
+			overrides = append(overrides, vevent)
+		} else {
+			master = vevent
+		}
+	}
+	if uid == "" {
+		return nil, fmt.Errorf("%w: VEVENTがありません", ErrInvalidInput)
+	}
+
+	imported, err := convertVEvent(master, overrides, time.UTC)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	return imported, nil
+}
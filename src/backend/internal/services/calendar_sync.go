@@ -0,0 +1,387 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 連携開始から認可完了までの有効期限
+	oauthStateTTL = 10 * time.Minute
+	// 差分同期だけを続けず、定期的に取得期間を更新して全件を取り直す間隔
+	fullSyncInterval = 7 * 24 * time.Hour
+)
+
+// ErrSyncTokenExpired 差分取得用のトークンが失効しており、全件の取り直しが必要
+var ErrSyncTokenExpired = errors.New("同期トークンが失効しています")
+
+// OAuthToken プロバイダーから取得したトークン
+type OAuthToken struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// ExternalAccount 連携したアカウントの情報
+type ExternalAccount struct {
+	ID    string
+	Email string
+}
+
+// ExternalEvent プロバイダーから取得したイベント（Removedの場合はExternalIDのみ有効）
+type ExternalEvent struct {
+	ExternalID  string
+	ICalUID     string
+	Title       string
+	Description string
+	StartDate   time.Time
+	EndDate     time.Time
+	AllDay      bool
+	Removed     bool
+}
+
+// ExternalChanges 同期で得られた変更
+type ExternalChanges struct {
+	Events        []ExternalEvent
+	NextSyncToken string
+	FullSync      bool // trueの場合、Eventsに含まれないイベントは削除されたものとして扱う
+}
+
+// CalendarProvider 外部カレンダーサービスとの連携（OAuth認可とイベントの取得）
+type CalendarProvider interface {
+	Provider() models.CalendarProvider
+	AuthURL(state string) string
+	Exchange(ctx context.Context, code string) (*OAuthToken, error)
+	Refresh(ctx context.Context, refreshToken string) (*OAuthToken, error)
+	Account(ctx context.Context, accessToken string) (*ExternalAccount, error)
+	// FetchChanges syncTokenが空の場合は全件を取得する
+	FetchChanges(ctx context.Context, accessToken, syncToken string) (*ExternalChanges, error)
+}
+
+// CalendarSyncService 連携した外部カレンダーのイベントを読み取り専用イベントとして取り込む
+type CalendarSyncService struct {
+	db          *gorm.DB
+	providers   map[models.CalendarProvider]CalendarProvider
+	stateSecret []byte
+}
+
+func NewCalendarSyncService(db *gorm.DB, stateSecret string, providers ...CalendarProvider) *CalendarSyncService {
+	registered := make(map[models.CalendarProvider]CalendarProvider, len(providers))
+	for _, p := range providers {
+		registered[p.Provider()] = p
+	}
+	return &CalendarSyncService{db: db, providers: registered, stateSecret: []byte(stateSecret)}
+}
+
+// AuthorizeURL 連携を開始するための認可画面のURL
+func (s *CalendarSyncService) AuthorizeURL(userID string, provider models.CalendarProvider) (string, error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return "", err
+	}
+	return p.AuthURL(s.signState(userID, provider, time.Now().Add(oauthStateTTL))), nil
+}
+
+// CompleteAuthorization 認可コードをトークンに交換して連携を保存し、初回同期を行う
+func (s *CalendarSyncService) CompleteAuthorization(ctx context.Context, provider models.CalendarProvider, code, state string) (*models.CalendarConnection, error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := s.verifyState(state, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.Exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	account, err := p.Account(ctx, token.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// 同じアカウントを再連携した場合は認証情報を更新する
+	var connection models.CalendarConnection
+	err = s.db.Where("provider = ? AND account_id = ? AND user_id = ?", provider, account.ID, userID).First(&connection).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		connection = models.CalendarConnection{Provider: provider, AccountID: account.ID, UserID: userID}
+	case err != nil:
+		return nil, err
+	}
+	connection.AccountEmail = account.Email
+	connection.AccessToken = token.AccessToken
+	connection.RefreshToken = token.RefreshToken
+	connection.TokenExpiresAt = token.ExpiresAt
+	connection.LastError = ""
+	if err := s.db.Save(&connection).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.sync(ctx, &connection); err != nil {
+		log.Printf("カレンダー連携 %s の同期に失敗しました: %v", connection.ID, err)
+	}
+	return s.findConnection(connection.ID, userID)
+}
+
+// ListConnections ユーザーのカレンダー連携一覧
+func (s *CalendarSyncService) ListConnections(userID string, sort SortOptions) ([]models.CalendarConnection, error) {
+	var connections []models.CalendarConnection
+	if err := orderBy(s.db.Where("user_id = ?", userID), sort, "created_at ASC").Find(&connections).Error; err != nil {
+		return nil, err
+	}
+	return connections, nil
+}
+
+// SyncConnection 連携を今すぐ同期する
+func (s *CalendarSyncService) SyncConnection(ctx context.Context, connectionID, userID string) (*models.CalendarConnection, error) {
+	connection, err := s.findConnection(connectionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sync(ctx, connection); err != nil {
+		log.Printf("カレンダー連携 %s の同期に失敗しました: %v", connection.ID, err)
+	}
+	return s.findConnection(connectionID, userID)
+}
+
+// DeleteConnection 連携を解除し、取り込んだイベントを削除する
+func (s *CalendarSyncService) DeleteConnection(connectionID, userID string) error {
+	connection, err := s.findConnection(connectionID, userID)
+	if err != nil {
+		return err
+	}
+	return models.ExternalSync(s.db).Transaction(func(tx *gorm.DB) error {
+		// 取り込んだイベントは外部のカレンダーの写しのため、論理削除せずに完全に削除する
+		if err := tx.Unscoped().Where("connection_id = ?", connection.ID).Delete(&models.Event{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(connection).Error
+	})
+}
+
+// SyncAllConnections すべての連携を同期する（Cronジョブ用）
+func (s *CalendarSyncService) SyncAllConnections() error {
+	var connections []models.CalendarConnection
+	if err := s.db.Find(&connections).Error; err != nil {
+		return err
+	}
+	for i := range connections {
+		if _, ok := s.providers[connections[i].Provider]; !ok {
+			continue
+		}
+		if err := s.sync(context.Background(), &connections[i]); err != nil {
+			log.Printf("カレンダー連携 %s の同期に失敗しました: %v", connections[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// sync トークンを必要に応じて更新し、変更を取り込んで結果を記録する
+func (s *CalendarSyncService) sync(ctx context.Context, connection *models.CalendarConnection) error {
+	err := s.syncChanges(ctx, connection)
+
+	now := time.Now()
+	updates := map[string]interface{}{"last_synced_at": now, "last_error": ""}
+	if err != nil {
+		updates["last_error"] = err.Error()
+	}
+	if updateErr := s.db.Model(connection).Updates(updates).Error; updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+func (s *CalendarSyncService) syncChanges(ctx context.Context, connection *models.CalendarConnection) error {
+	p, err := s.provider(connection.Provider)
+	if err != nil {
+		return err
+	}
+	accessToken, err := s.accessToken(ctx, p, connection)
+	if err != nil {
+		return err
+	}
+
+	syncToken := connection.SyncToken
+	if connection.LastFullSyncAt == nil || time.Since(*connection.LastFullSyncAt) > fullSyncInterval {
+		syncToken = ""
+	}
+	changes, err := p.FetchChanges(ctx, accessToken, syncToken)
+	if errors.Is(err, ErrSyncTokenExpired) {
+		changes, err = p.FetchChanges(ctx, accessToken, "")
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.applyChanges(connection, changes); err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{"sync_token": changes.NextSyncToken}
+	if changes.FullSync {
+		updates["last_full_sync_at"] = time.Now()
+	}
+	return s.db.Model(connection).Updates(updates).Error
+}
+
+// accessToken 期限切れが近いアクセストークンはリフレッシュする
+func (s *CalendarSyncService) accessToken(ctx context.Context, p CalendarProvider, connection *models.CalendarConnection) (string, error) {
+	if time.Until(connection.TokenExpiresAt) > time.Minute {
+		return connection.AccessToken, nil
+	}
+	if connection.RefreshToken == "" {
+		return "", errors.New("再連携が必要です（リフレッシュトークンがありません）")
+	}
+
+	token, err := p.Refresh(ctx, connection.RefreshToken)
+	if err != nil {
+		return "", fmt.Errorf("トークンの更新に失敗しました。再連携が必要な可能性があります: %w", err)
+	}
+	updates := map[string]interface{}{"access_token": token.AccessToken, "token_expires_at": token.ExpiresAt}
+	if token.RefreshToken != "" {
+		updates["refresh_token"] = token.RefreshToken
+	}
+	if err := s.db.Model(connection).Updates(updates).Error; err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// applyChanges 取得した変更を連携のイベントに反映する
+func (s *CalendarSyncService) applyChanges(connection *models.CalendarConnection, changes *ExternalChanges) error {
+	return models.ExternalSync(s.db).Transaction(func(tx *gorm.DB) error {
+		var existing []models.Event
+		if err := tx.Where("connection_id = ?", connection.ID).Find(&existing).Error; err != nil {
+			return err
+		}
+		byExternalID := make(map[string]*models.Event, len(existing))
+		for i := range existing {
+			byExternalID[existing[i].ExternalID] = &existing[i]
+		}
+
+		seen := make(map[string]bool, len(changes.Events))
+		var removed []string
+		for _, ext := range changes.Events {
+			event, ok := byExternalID[ext.ExternalID]
+			if ext.Removed {
+				if ok {
+					removed = append(removed, event.ID)
+				}
+				continue
+			}
+			seen[ext.ExternalID] = true
+
+			if ok {
+				if err := tx.Model(event).Updates(map[string]interface{}{
+					"title":       ext.Title,
+					"description": ext.Description,
+					"start_date":  ext.StartDate,
+					"end_date":    ext.EndDate,
+					"all_day":     ext.AllDay,
+					"ical_uid":    ext.ICalUID,
+				}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Create(&models.Event{
+				Title:        ext.Title,
+				Description:  ext.Description,
+				StartDate:    ext.StartDate,
+				EndDate:      ext.EndDate,
+				AllDay:       ext.AllDay,
+				Type:         models.EventTypeMeeting,
+				CreatorID:    connection.UserID,
+				ICalUID:      ext.ICalUID,
+				ConnectionID: &connection.ID,
+				ExternalID:   ext.ExternalID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		if changes.FullSync {
+			for externalID, event := range byExternalID {
+				if !seen[externalID] {
+					removed = append(removed, event.ID)
+				}
+			}
+		}
+		if len(removed) == 0 {
+			return nil
+		}
+		return tx.Unscoped().Where("id IN ?", removed).Delete(&models.Event{}).Error
+	})
+}
+
+func (s *CalendarSyncService) provider(provider models.CalendarProvider) (CalendarProvider, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %sとの連携は設定されていません", ErrInvalidInput, provider)
+	}
+	return p, nil
+}
+
+func (s *CalendarSyncService) findConnection(connectionID, userID string) (*models.CalendarConnection, error) {
+	var connection models.CalendarConnection
+	if err := s.db.First(&connection, "id = ?", connectionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if connection.UserID != userID {
+		return nil, ErrForbidden
+	}
+	return &connection, nil
+}
+
+// signState 認可リクエストに付けるstate（ユーザーID・プロバイダー・有効期限をHMACで署名）
+func (s *CalendarSyncService) signState(userID string, provider models.CalendarProvider, expiresAt time.Time) string {
+	payload := strings.Join([]string{userID, string(provider), strconv.FormatInt(expiresAt.Unix(), 10)}, "|")
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + s.stateSignature(encoded)
+}
+
+func (s *CalendarSyncService) verifyState(state string, provider models.CalendarProvider) (string, error) {
+	invalid := fmt.Errorf("%w: stateが不正か期限切れです", ErrInvalidInput)
+
+	encoded, signature, ok := strings.Cut(state, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.stateSignature(encoded))) {
+		return "", invalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", invalid
+	}
+	parts := strings.Split(string(payload), "|")
+	if len(parts) != 3 || parts[1] != string(provider) {
+		return "", invalid
+	}
+	expiresAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return "", invalid
+	}
+	return parts[0], nil
+}
+
+func (s *CalendarSyncService) stateSignature(encoded string) string {
+	mac := hmac.New(sha256.New, s.stateSecret)
+	mac.Write([]byte("calendar-oauth-state:" + encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
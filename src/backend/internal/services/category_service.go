@@ -0,0 +1,180 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// EventCategoryInput イベントカテゴリーの作成・更新
+type EventCategoryInput struct {
+	Name  string `json:"name" binding:"required,notblank,max=50"`
+	Color string `json:"color" binding:"required,color"`
+}
+
+// EventAppearanceInput イベントの表示色とカテゴリーの設定（空にすると解除する）
+type EventAppearanceInput struct {
+	Color      string  `json:"color" binding:"omitempty,color"`
+	CategoryID *string `json:"categoryId"`
+}
+
+type CategoryService struct {
+	db *gorm.DB
+}
+
+func NewCategoryService(db *gorm.DB) *CategoryService {
+	return &CategoryService{db: db}
+}
+
+// ListCategories チームのイベントカテゴリー一覧
+func (s *CategoryService) ListCategories(teamID, userID string, sort SortOptions) ([]models.EventCategory, error) {
+	if err := ensureTeamMember(s.db, teamID, userID); err != nil {
+		return nil, err
+	}
+	var categories []models.EventCategory
+	if err := orderBy(s.db.Where("team_id = ?", teamID), sort, "name ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// CreateCategory イベントカテゴリーを登録する（チーム管理者のみ）
+func (s *CategoryService) CreateCategory(teamID, userID string, input EventCategoryInput) (*models.EventCategory, error) {
+	if err := s.ensureCategoryAdmin(teamID, userID); err != nil {
+		return nil, err
+	}
+	category := models.EventCategory{TeamID: teamID}
+	if err := s.applyCategoryInput(&category, input); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// UpdateCategory イベントカテゴリーの名前・色を更新する（チーム管理者のみ）
+func (s *CategoryService) UpdateCategory(categoryID, userID string, input EventCategoryInput) (*models.EventCategory, error) {
+	category, err := s.findCategory(categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureCategoryAdmin(category.TeamID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.applyCategoryInput(category, input); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(category).Error; err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteCategory イベントカテゴリーを削除する（設定していたイベントのカテゴリーは解除される）
+func (s *CategoryService) DeleteCategory(categoryID, userID string) error {
+	category, err := s.findCategory(categoryID)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureCategoryAdmin(category.TeamID, userID); err != nil {
+		return err
+	}
+	return s.db.Delete(category).Error
+}
+
+// SetEventAppearance イベントの表示色とカテゴリーを設定する
+//
+// カテゴリーはイベントと同じチームのもののみ設定できる。個人のイベントは色のみ設定できる。
+func (s *CategoryService) SetEventAppearance(eventID, userID string, input EventAppearanceInput) (*models.Event, error) {
+	event, err := findEditableEvent(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	color, err := models.NormalizeColor(input.Color)
+	if err != nil {
+		return nil, err
+	}
+	categoryID := input.CategoryID
+	if categoryID != nil && *categoryID == "" {
+		categoryID = nil
+	}
+	if categoryID != nil {
+		category, err := s.findCategory(*categoryID)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil, fmt.Errorf("%w: カテゴリーが見つかりません", ErrInvalidInput)
+			}
+			return nil, err
+		}
+		if event.TeamID == nil || *event.TeamID != category.TeamID {
+			return nil, fmt.Errorf("%w: カテゴリーはイベントと同じチームのものを指定してください", ErrInvalidInput)
+		}
+	}
+
+	if err := s.db.Model(event).Updates(map[string]interface{}{
+		"color":       color,
+		"category_id": categoryID,
+	}).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Preload("Category").First(event, "id = ?", event.ID).Error; err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (s *CategoryService) findCategory(categoryID string) (*models.EventCategory, error) {
+	var category models.EventCategory
+	if err := s.db.First(&category, "id = ?", categoryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (s *CategoryService) ensureCategoryAdmin(teamID, userID string) error {
+	admin, err := isTeamAdmin(s.db, teamID, userID)
+	if err != nil {
+		return err
+	}
+	if !admin {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func (s *CategoryService) applyCategoryInput(category *models.EventCategory, input EventCategoryInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return fmt.Errorf("%w: nameは必須です", ErrInvalidInput)
+	}
+	color, err := models.NormalizeColor(input.Color)
+	if err != nil {
+		return err
+	}
+	if color == "" {
+		return fmt.Errorf("%w: colorは必須です", ErrInvalidInput)
+	}
+
+	var count int64
+	if err := s.db.Model(&models.EventCategory{}).
+		Where("team_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", category.TeamID, name, category.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: 同じ名前のカテゴリーが既にあります", ErrInvalidInput)
+	}
+
+	category.Name = name
+	category.Color = color
+	return nil
+}
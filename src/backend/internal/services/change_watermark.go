@@ -0,0 +1,186 @@
+package services
+
+import (
+	"database/sql"
+	"reflect"
+	"sort"
+	"time"
+
+	"task-calendar-backend/internal/database"
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 変更日時を記録する一覧の種類
+const (
+	watermarkCalendar      = "calendar"      // アジェンダ（イベント・タスクの期限・祝日）
+	watermarkNotifications = "notifications" // 通知
+)
+
+func watermarkScope(kind, target, id string) string {
+	return kind + ":" + target + ":" + id
+}
+
+// watermarkAll 対象の分からない変更を記録する範囲
+func watermarkAll(kind string) string {
+	return kind + ":*"
+}
+
+// ChangeWatermarkService 一覧の最後の変更日時（If-Modified-Since の判定に使う）
+//
+// 削除は一覧の updated_at の最大値に表れないため、変更のたびにユーザー・チームごとの日時を記録する。
+type ChangeWatermarkService struct {
+	db *gorm.DB
+}
+
+func NewChangeWatermarkService(db *gorm.DB) *ChangeWatermarkService {
+	return &ChangeWatermarkService{db: db}
+}
+
+// CalendarChangedAt ユーザーのアジェンダに含まれうるものの最後の変更日時（記録がなければゼロ値）
+//
+// 自分の・所属するチームのイベントとタスク、チームへの所属、チームの祝日の国の変更が対象。
+func (s *ChangeWatermarkService) CalendarChangedAt(userID string) (time.Time, error) {
+	teamScopes := s.db.Session(&gorm.Session{NewDB: true}).Model(&models.TeamMember{}).
+		Select("CAST(? AS text) || team_id", watermarkCalendar+":team:").
+		Where("user_id = ? AND status = ?", userID, models.TeamMemberStatusActive)
+	return s.changedAt(s.db.Where("scope IN ? OR scope IN (?)",
+		[]string{watermarkScope(watermarkCalendar, "user", userID), watermarkAll(watermarkCalendar)}, teamScopes))
+}
+
+// NotificationsChangedAt ユーザーの通知の最後の変更日時（記録がなければゼロ値）
+func (s *ChangeWatermarkService) NotificationsChangedAt(userID string) (time.Time, error) {
+	return s.changedAt(s.db.Where("scope IN ?",
+		[]string{watermarkScope(watermarkNotifications, "user", userID), watermarkAll(watermarkNotifications)}))
+}
+
+func (s *ChangeWatermarkService) changedAt(query *gorm.DB) (time.Time, error) {
+	var changedAt sql.NullTime
+	if err := query.Model(&models.ChangeWatermark{}).Select("MAX(changed_at)").Row().Scan(&changedAt); err != nil {
+		return time.Time{}, err
+	}
+	return changedAt.Time, nil
+}
+
+// RegisterCallbacks イベント・タスク・通知などの作成・更新・削除の日時を記録するgormのコールバックを登録する
+//
+// 記録は変更と同じトランザクションで行うため、ロールバックされた変更は記録に残らない。
+// IDの分からない一括更新・削除（条件での削除など）は、その種類のすべての一覧の変更として記録する。
+func (s *ChangeWatermarkService) RegisterCallbacks() error {
+	callbacks := s.db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("watermark:after_create", s.recordChanges); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("watermark:after_update", s.recordChanges); err != nil {
+		return err
+	}
+	return callbacks.Delete().After("gorm:delete").Register("watermark:after_delete", s.recordChanges)
+}
+
+func (s *ChangeWatermarkService) recordChanges(tx *gorm.DB) {
+	if tx.Error != nil || tx.RowsAffected == 0 || !tx.Statement.ReflectValue.IsValid() {
+		return
+	}
+	scopes := map[string]bool{}
+	value := reflect.Indirect(tx.Statement.ReflectValue)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			s.collectScopes(tx, reflect.Indirect(value.Index(i)), scopes)
+		}
+	case reflect.Struct:
+		s.collectScopes(tx, value, scopes)
+	}
+	if len(scopes) == 0 {
+		return
+	}
+
+	// 同じ範囲を同時に記録するトランザクション同士がデッドロックしないよう、範囲の順に記録する
+	now := time.Now()
+	watermarks := make([]models.ChangeWatermark, 0, len(scopes))
+	for scope := range scopes {
+		watermarks = append(watermarks, models.ChangeWatermark{Scope: scope, ChangedAt: now})
+	}
+	sort.Slice(watermarks, func(i, j int) bool { return watermarks[i].Scope < watermarks[j].Scope })
+	if err := tx.Session(&gorm.Session{NewDB: true}).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "scope"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"changed_at": gorm.Expr(database.Greatest(tx) + "(change_watermarks.changed_at, EXCLUDED.changed_at)"),
+		}),
+	}).Create(&watermarks).Error; err != nil {
+		tx.AddError(err)
+	}
+}
+
+func (s *ChangeWatermarkService) collectScopes(tx *gorm.DB, value reflect.Value, scopes map[string]bool) {
+	if value.Kind() != reflect.Struct || !value.CanInterface() {
+		return
+	}
+	switch record := value.Interface().(type) {
+	case models.Event:
+		if record.ID == "" || record.CreatorID == "" {
+			scopes[watermarkAll(watermarkCalendar)] = true
+			return
+		}
+		s.collectEventScopes(tx, record.ID, record.TeamID, record.CreatorID, scopes)
+	case models.EventException:
+		s.collectEventScopesByID(tx, record.EventID, scopes)
+	case models.EventAttendee:
+		if record.UserID != "" {
+			scopes[watermarkScope(watermarkCalendar, "user", record.UserID)] = true
+		}
+		s.collectEventScopesByID(tx, record.EventID, scopes)
+	case models.Task:
+		// 担当者の変更前の値は分からないため、チーム全体を変更ありとする
+		if record.TeamID == "" {
+			scopes[watermarkAll(watermarkCalendar)] = true
+			return
+		}
+		scopes[watermarkScope(watermarkCalendar, "team", record.TeamID)] = true
+	case models.Team:
+		if record.ID == "" {
+			scopes[watermarkAll(watermarkCalendar)] = true
+			return
+		}
+		scopes[watermarkScope(watermarkCalendar, "team", record.ID)] = true
+	case models.TeamMember:
+		if record.UserID == "" {
+			scopes[watermarkAll(watermarkCalendar)] = true
+			return
+		}
+		scopes[watermarkScope(watermarkCalendar, "user", record.UserID)] = true
+	case models.Notification:
+		if record.UserID == "" {
+			scopes[watermarkAll(watermarkNotifications)] = true
+			return
+		}
+		scopes[watermarkScope(watermarkNotifications, "user", record.UserID)] = true
+	}
+}
+
+// collectEventScopesByID イベントIDからイベントの範囲を求める（イベントが見つからない場合はすべての一覧）
+func (s *ChangeWatermarkService) collectEventScopesByID(tx *gorm.DB, eventID string, scopes map[string]bool) {
+	var event models.Event
+	if eventID == "" || tx.Session(&gorm.Session{NewDB: true}).Select("id", "team_id", "creator_id").
+		Where("id = ?", eventID).Take(&event).Error != nil {
+		scopes[watermarkAll(watermarkCalendar)] = true
+		return
+	}
+	s.collectEventScopes(tx, event.ID, event.TeamID, event.CreatorID, scopes)
+}
+
+// collectEventScopes イベントのチーム（非公開のイベントも公開範囲の変更に備えて含める）と作成者・参加者
+func (s *ChangeWatermarkService) collectEventScopes(tx *gorm.DB, eventID string, teamID *string, creatorID string, scopes map[string]bool) {
+	if teamID != nil && *teamID != "" {
+		scopes[watermarkScope(watermarkCalendar, "team", *teamID)] = true
+	}
+	scopes[watermarkScope(watermarkCalendar, "user", creatorID)] = true
+	var attendeeIDs []string
+	tx.Session(&gorm.Session{NewDB: true}).Model(&models.EventAttendee{}).Where("event_id = ?", eventID).
+		Pluck("user_id", &attendeeIDs)
+	for _, id := range attendeeIDs {
+		scopes[watermarkScope(watermarkCalendar, "user", id)] = true
+	}
+}
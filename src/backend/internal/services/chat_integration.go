@@ -0,0 +1,469 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// イベント開始の何分前にチャットに投稿するか
+	chatUpcomingEventLead = 15 * time.Minute
+	// 期限の何時間前のタスクを期限が近いとして投稿するか
+	chatTaskDueSoonLead = 24 * time.Hour
+	chatPostTimeout     = 15 * time.Second
+	// 投稿に失敗した場合に試す最大の回数（非同期ジョブとして間隔を空けて投稿し直す）
+	chatPostMaxAttempts = 5
+)
+
+// taskStatusLabels チャットの投稿に使うタスクのステータスの表示名
+var taskStatusLabels = map[models.TaskStatus]string{
+	models.TaskStatusTodo:       "未着手",
+	models.TaskStatusInProgress: "進行中",
+	models.TaskStatusInReview:   "レビュー中",
+	models.TaskStatusDone:       "完了",
+	models.TaskStatusCancelled:  "キャンセル",
+}
+
+// ChatMessage チャットに投稿するメッセージ（書式はチャットサービスごとに整える）
+type ChatMessage struct {
+	Text      string // 本文（リンクの前に置く）
+	LinkTitle string
+	LinkURL   string
+	Facts     []ChatFact // 日時・場所などの補足（項目名と値の組）
+}
+
+// ChatFact メッセージに添える項目
+type ChatFact struct {
+	Title string
+	Value string
+}
+
+// ChatPoster チャットサービスへの投稿
+type ChatPoster interface {
+	Provider() models.ChatProvider
+	// Validate チャンネルの設定に投稿先が指定されているか確認する（ErrInvalidInput）
+	Validate(channel *models.TeamChatChannel) error
+	Post(ctx context.Context, channel *models.TeamChatChannel, msg ChatMessage) error
+}
+
+// TeamChatChannelInput チームのチャンネル設定（通知の種類は省略すると、期限が近いタスク以外は有効）
+//
+// 投稿先はチャットサービスごとに、Slackは channelId、Microsoft Teams は webhookUrl で指定する。
+// webhookUrl は返さないため、更新時に省略した場合は設定済みのURLを使い続ける。
+type TeamChatChannelInput struct {
+	ChannelID            string `json:"channelId"`
+	ChannelName          string `json:"channelName"`
+	WebhookURL           string `json:"webhookUrl" binding:"omitempty,http_url"`
+	NotifyTaskCreated    *bool  `json:"notifyTaskCreated"`
+	NotifyTaskStatus     *bool  `json:"notifyTaskStatus"`
+	NotifyTaskDueSoon    *bool  `json:"notifyTaskDueSoon"`
+	NotifyUpcomingEvents *bool  `json:"notifyUpcomingEvents"`
+}
+
+// ChatIntegrationService タスクの作成・ステータス変更と開始前のイベントを、チームのチャットのチャンネルに投稿する
+type ChatIntegrationService struct {
+	db      *gorm.DB
+	jobs    *JobService
+	posters map[models.ChatProvider]ChatPoster
+	appURL  string // 投稿に載せるリンクの基準（フロントエンドのURL）
+}
+
+// chatPostJobParams チャットへの投稿のジョブの引数
+type chatPostJobParams struct {
+	ChannelID string      `json:"channelId"`
+	Message   ChatMessage `json:"message"`
+}
+
+// NewChatIntegrationService 投稿は非同期ジョブ（chat.post）として jobs で実行する
+func NewChatIntegrationService(db *gorm.DB, jobs *JobService, appURL string, posters ...ChatPoster) *ChatIntegrationService {
+	registered := make(map[models.ChatProvider]ChatPoster, len(posters))
+	for _, p := range posters {
+		registered[p.Provider()] = p
+	}
+	s := &ChatIntegrationService{db: db, jobs: jobs, posters: registered, appURL: strings.TrimRight(appURL, "/")}
+	jobs.Register(models.JobTypeChatPost, s.runPostJob)
+	return s
+}
+
+// ListTeamChannels チームに設定したチャットのチャンネル一覧（チームのメンバーのみ）
+func (s *ChatIntegrationService) ListTeamChannels(teamID, userID string) ([]models.TeamChatChannel, error) {
+	if err := ensureTeamMember(s.db, teamID, userID); err != nil {
+		return nil, err
+	}
+	var channels []models.TeamChatChannel
+	if err := s.db.Where("team_id = ?", teamID).Order("provider ASC").Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// SetTeamChannel チームの通知を投稿するチャンネルを設定する（チームの管理者のみ、設定済みの場合は置き換える）
+func (s *ChatIntegrationService) SetTeamChannel(teamID, userID string, provider models.ChatProvider, input TeamChatChannelInput) (*models.TeamChatChannel, error) {
+	if err := s.ensureTeamAdmin(teamID, userID); err != nil {
+		return nil, err
+	}
+	poster, ok := s.posters[provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %sとの連携は設定されていません", ErrInvalidInput, provider)
+	}
+
+	var channel models.TeamChatChannel
+	err := s.db.Where("team_id = ? AND provider = ?", teamID, provider).First(&channel).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		channel = models.TeamChatChannel{
+			TeamID:               teamID,
+			Provider:             provider,
+			CreatorID:            userID,
+			NotifyTaskCreated:    true,
+			NotifyTaskStatus:     true,
+			NotifyUpcomingEvents: true,
+		}
+	}
+	channel.ChannelID = strings.TrimSpace(input.ChannelID)
+	channel.ChannelName = strings.TrimSpace(input.ChannelName)
+	if webhookURL := strings.TrimSpace(input.WebhookURL); webhookURL != "" {
+		channel.WebhookURL = webhookURL
+	}
+	if input.NotifyTaskCreated != nil {
+		channel.NotifyTaskCreated = *input.NotifyTaskCreated
+	}
+	if input.NotifyTaskStatus != nil {
+		channel.NotifyTaskStatus = *input.NotifyTaskStatus
+	}
+	if input.NotifyTaskDueSoon != nil {
+		channel.NotifyTaskDueSoon = *input.NotifyTaskDueSoon
+	}
+	if input.NotifyUpcomingEvents != nil {
+		channel.NotifyUpcomingEvents = *input.NotifyUpcomingEvents
+	}
+	if err := poster.Validate(&channel); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(&channel).Error; err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+// DeleteTeamChannel チームのチャンネル設定を削除する（チームの管理者のみ）
+func (s *ChatIntegrationService) DeleteTeamChannel(teamID, userID string, provider models.ChatProvider) error {
+	if err := s.ensureTeamAdmin(teamID, userID); err != nil {
+		return err
+	}
+	result := s.db.Where("team_id = ? AND provider = ?", teamID, provider).Delete(&models.TeamChatChannel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *ChatIntegrationService) ensureTeamAdmin(teamID, userID string) error {
+	admin, err := isTeamAdmin(s.db, teamID, userID)
+	if err != nil {
+		return err
+	}
+	if !admin {
+		return fmt.Errorf("%w: チャット連携の設定はチームの管理者のみ変更できます", ErrForbidden)
+	}
+	return nil
+}
+
+// RegisterCallbacks タスクの作成・ステータス変更をチャットに投稿するgormのコールバックを登録する
+//
+// ステータスの変更は読み込んだ時点と比べて判定するため、読み込まずに一括更新した場合は投稿しない。
+// 投稿は保存を待たせないよう、変更と同じトランザクションで非同期ジョブとして登録する。
+func (s *ChatIntegrationService) RegisterCallbacks() error {
+	callbacks := s.db.Callback()
+	if err := callbacks.Create().After("gorm:create").Before("gorm:after_create").
+		Register("chat:after_create", s.taskChanged(true)); err != nil {
+		return err
+	}
+	return callbacks.Update().After("gorm:update").Before("gorm:after_update").
+		Register("chat:after_update", s.taskChanged(false))
+}
+
+func (s *ChatIntegrationService) taskChanged(created bool) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx.Error != nil || tx.RowsAffected == 0 || !tx.Statement.ReflectValue.IsValid() {
+			return
+		}
+		value := reflect.Indirect(tx.Statement.ReflectValue)
+		var tasks []*models.Task
+		switch value.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < value.Len(); i++ {
+				elem := reflect.Indirect(value.Index(i))
+				if !elem.CanAddr() {
+					continue
+				}
+				if task, ok := elem.Addr().Interface().(*models.Task); ok {
+					tasks = append(tasks, task)
+				}
+			}
+		case reflect.Struct:
+			if value.CanAddr() {
+				if task, ok := value.Addr().Interface().(*models.Task); ok {
+					tasks = append(tasks, task)
+				}
+			}
+		}
+
+		db := tx.Session(&gorm.Session{NewDB: true})
+		for _, task := range tasks {
+			if task.ID == "" || task.TeamID == "" {
+				continue
+			}
+			switch {
+			case created:
+				s.postToTeam(db, task.TeamID, func(c *models.TeamChatChannel) bool { return c.NotifyTaskCreated }, ChatMessage{
+					Text:      "新しいタスクが作成されました",
+					LinkTitle: task.Title,
+					LinkURL:   s.taskURL(task.ID),
+				})
+			case task.LoadedStatus() != "" && task.Status != "" && task.Status != task.LoadedStatus():
+				s.postToTeam(db, task.TeamID, func(c *models.TeamChatChannel) bool { return c.NotifyTaskStatus }, ChatMessage{
+					Text: fmt.Sprintf("タスクのステータスが「%s」から「%s」に変わりました",
+						taskStatusLabel(task.LoadedStatus()), taskStatusLabel(task.Status)),
+					LinkTitle: task.Title,
+					LinkURL:   s.taskURL(task.ID),
+				})
+			}
+		}
+	}
+}
+
+// postToTeam チームのチャンネルのうち wants を満たすものへの投稿を、非同期ジョブとして登録する
+func (s *ChatIntegrationService) postToTeam(db *gorm.DB, teamID string, wants func(*models.TeamChatChannel) bool, msg ChatMessage) {
+	var channels []models.TeamChatChannel
+	if err := db.Where("team_id = ?", teamID).Find(&channels).Error; err != nil {
+		log.Printf("チーム %s のチャット連携を取得できませんでした: %v", teamID, err)
+		return
+	}
+	for i := range channels {
+		if !wants(&channels[i]) {
+			continue
+		}
+		if err := s.enqueuePost(db, &channels[i], msg); err != nil {
+			log.Printf("%sへの投稿の登録に失敗しました（チーム %s）: %v", channels[i].Provider, teamID, err)
+		}
+	}
+}
+
+// enqueuePost チャンネルへの投稿を非同期ジョブとして登録する（失敗した場合は chatPostMaxAttempts 回まで投稿し直す）
+func (s *ChatIntegrationService) enqueuePost(db *gorm.DB, channel *models.TeamChatChannel, msg ChatMessage) error {
+	return s.jobs.EnqueueBackground(db, models.JobTypeChatPost, chatPostJobParams{ChannelID: channel.ID, Message: msg}, chatPostMaxAttempts)
+}
+
+// runPostJob 投稿のジョブを実行する（登録した後にチャンネルの設定が削除された場合は投稿しない）
+func (s *ChatIntegrationService) runPostJob(job *models.Job, _ io.Reader, _ func(int)) (*JobOutput, error) {
+	var params chatPostJobParams
+	if err := DecodeJobParams(job, &params); err != nil {
+		return nil, PermanentJobError(err)
+	}
+	var channel models.TeamChatChannel
+	if err := s.db.First(&channel, "id = ?", params.ChannelID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if _, ok := s.posters[channel.Provider]; !ok {
+		return nil, PermanentJobError(fmt.Errorf("%sとの連携は設定されていません", channel.Provider))
+	}
+	return nil, s.post(&channel, params.Message)
+}
+
+func (s *ChatIntegrationService) post(channel *models.TeamChatChannel, msg ChatMessage) error {
+	poster, ok := s.posters[channel.Provider]
+	if !ok {
+		return fmt.Errorf("%sとの連携は設定されていません", channel.Provider)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), chatPostTimeout)
+	defer cancel()
+	return poster.Post(ctx, channel, msg)
+}
+
+// PostUpcomingEvents まもなく開始するチームのイベントをチャットに投稿する（Cronジョブ用）
+//
+// 投稿した回はChatEventPostに記録し、同じ回を二重に投稿しない。非公開・キャンセル済みのイベントは投稿しない。
+func (s *ChatIntegrationService) PostUpcomingEvents() error {
+	byTeam, teamIDs, err := s.channelsByTeam("notify_upcoming_events")
+	if err != nil || len(teamIDs) == 0 {
+		return err
+	}
+
+	now := time.Now()
+	until := now.Add(chatUpcomingEventLead)
+	var events []models.Event
+	if err := s.db.Where("team_id IN ?", teamIDs).
+		Where("is_recurring = ? OR (start_date > ? AND start_date <= ?)", true, now, until).
+		Where("status <> ? AND visibility <> ?", models.EventStatusCancelled, models.EventVisibilityPrivate).
+		Preload("Exceptions").Find(&events).Error; err != nil {
+		return err
+	}
+	indexed, _, err := indexedOccurrences(s.db, events, now, until)
+	if err != nil {
+		return err
+	}
+
+	for i := range events {
+		event := &events[i]
+		occurrences, ok := indexed[event.ID]
+		if !ok {
+			if occurrences, err = expandEvent(event, event.Exceptions, now, until); err != nil {
+				continue
+			}
+		}
+		for _, occ := range occurrences {
+			// 開始済みの回（期間の途中から重なる回）は投稿しない
+			if !occ.StartDate.After(now) || occ.StartDate.After(until) {
+				continue
+			}
+			for j := range byTeam[*event.TeamID] {
+				if err := s.postUpcoming(&byTeam[*event.TeamID][j], event, occ); err != nil {
+					log.Printf("イベント %s の投稿に失敗しました: %v", event.ID, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// postUpcoming まもなく開始する回の投稿を登録する（記録済みなら投稿済み）
+func (s *ChatIntegrationService) postUpcoming(channel *models.TeamChatChannel, event *models.Event, occ EventOccurrence) error {
+	post := models.ChatEventPost{
+		ChannelID:    channel.ID,
+		EventID:      event.ID,
+		RecurrenceID: occ.RecurrenceID,
+		PostedAt:     time.Now(),
+	}
+	msg := ChatMessage{
+		Text:      fmt.Sprintf("%d分後にイベントが始まります", int(time.Until(occ.StartDate).Round(time.Minute).Minutes())),
+		LinkTitle: occ.Title,
+		LinkURL:   s.eventURL(event.ID),
+		Facts: []ChatFact{
+			{Title: "日時", Value: occ.StartDate.Format("15:04") + "〜" + occ.EndDate.Format("15:04 MST")},
+		},
+	}
+	if location := eventLocation(event); location != "" {
+		msg.Facts = append(msg.Facts, ChatFact{Title: "場所", Value: location})
+	}
+	if event.ConferenceURL != "" {
+		msg.Facts = append(msg.Facts, ChatFact{Title: "ビデオ会議", Value: event.ConferenceURL})
+	}
+	return s.enqueueOnce(&post, channel, msg)
+}
+
+// PostDueSoonTasks 期限が近い未完了のタスクをチャットに投稿する（Cronジョブ用）
+//
+// 投稿したタスクと期限はChatTaskPostに記録し、期限を変更しない限り二重に投稿しない。
+func (s *ChatIntegrationService) PostDueSoonTasks() error {
+	byTeam, teamIDs, err := s.channelsByTeam("notify_task_due_soon")
+	if err != nil || len(teamIDs) == 0 {
+		return err
+	}
+
+	now := time.Now()
+	var tasks []models.Task
+	if err := s.db.Preload("Assignee").Where("team_id IN ?", teamIDs).
+		Where("due_date > ? AND due_date <= ?", now, now.Add(chatTaskDueSoonLead)).
+		Where("status NOT IN ?", []models.TaskStatus{models.TaskStatusDone, models.TaskStatusCancelled}).
+		Order("due_date ASC").Find(&tasks).Error; err != nil {
+		return err
+	}
+	for i := range tasks {
+		task := &tasks[i]
+		for j := range byTeam[task.TeamID] {
+			if err := s.postDueSoon(&byTeam[task.TeamID][j], task); err != nil {
+				log.Printf("タスク %s の投稿に失敗しました: %v", task.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// postDueSoon 期限が近いタスクの投稿を登録する（記録済みなら投稿済み）
+func (s *ChatIntegrationService) postDueSoon(channel *models.TeamChatChannel, task *models.Task) error {
+	post := models.ChatTaskPost{
+		ChannelID: channel.ID,
+		TaskID:    task.ID,
+		DueDate:   *task.DueDate,
+		PostedAt:  time.Now(),
+	}
+	msg := ChatMessage{
+		Text:      "期限が近いタスクがあります",
+		LinkTitle: task.Title,
+		LinkURL:   s.taskURL(task.ID),
+		Facts: []ChatFact{
+			{Title: "期限", Value: task.DueDate.UTC().Format("2006-01-02 15:04 MST")},
+			{Title: "ステータス", Value: taskStatusLabel(task.Status)},
+		},
+	}
+	if task.Assignee != nil {
+		msg.Facts = append(msg.Facts, ChatFact{Title: "担当者", Value: userDisplayName(task.Assignee)})
+	}
+	return s.enqueueOnce(&post, channel, msg)
+}
+
+// enqueueOnce 投稿記録を作成できた場合のみ、同じトランザクションで投稿のジョブを登録する（記録済みなら投稿済み）
+func (s *ChatIntegrationService) enqueueOnce(record interface{}, channel *models.TeamChatChannel, msg ChatMessage) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return s.enqueuePost(tx, channel, msg)
+	})
+}
+
+// channelsByTeam 指定した種類の通知を有効にしたチャンネル（連携が有効なチャットサービスのみ）をチームごとにまとめる
+func (s *ChatIntegrationService) channelsByTeam(notifyColumn string) (map[string][]models.TeamChatChannel, []string, error) {
+	var channels []models.TeamChatChannel
+	if err := s.db.Where(notifyColumn+" = ?", true).Find(&channels).Error; err != nil {
+		return nil, nil, err
+	}
+	byTeam := map[string][]models.TeamChatChannel{}
+	var teamIDs []string
+	for _, c := range channels {
+		if _, ok := s.posters[c.Provider]; !ok {
+			continue
+		}
+		if _, ok := byTeam[c.TeamID]; !ok {
+			teamIDs = append(teamIDs, c.TeamID)
+		}
+		byTeam[c.TeamID] = append(byTeam[c.TeamID], c)
+	}
+	return byTeam, teamIDs, nil
+}
+
+func (s *ChatIntegrationService) taskURL(taskID string) string {
+	return s.appURL + "/tasks/" + taskID
+}
+
+func (s *ChatIntegrationService) eventURL(eventID string) string {
+	return s.appURL + "/events/" + eventID
+}
+
+func taskStatusLabel(status models.TaskStatus) string {
+	if label, ok := taskStatusLabels[status]; ok {
+		return label
+	}
+	return string(status)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var (
+	markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+	mentionPattern   = regexp.MustCompile(`(?:^|[^A-Za-z0-9_@])@([A-Za-z0-9_][A-Za-z0-9_.-]*)`)
+	codeBlockPattern = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+)
+
+// renderMarkdown コメント本文をHTMLに変換する（生のHTMLはエスケープされる）
+func renderMarkdown(content string) (string, error) {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(content), &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// extractMentions 本文から@メンションされたユーザー名を重複なく抽出する（コード部分は除外）
+func extractMentions(content string) []string {
+	text := codeBlockPattern.ReplaceAllString(content, " ")
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		name := strings.TrimRight(m[1], ".-")
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,578 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxReactionEmojiLength = 32
+
+type CommentService struct {
+	db             *gorm.DB
+	notifier       Notifier
+	replyAddresses *ReplyAddressService
+}
+
+func NewCommentService(db *gorm.DB, notifier Notifier, replyAddresses *ReplyAddressService) *CommentService {
+	return &CommentService{db: db, notifier: notifier, replyAddresses: replyAddresses}
+}
+
+// CommentListOptions コメント一覧の取得条件
+type CommentListOptions struct {
+	PageOptions
+	Order   string // newest（既定）または oldest
+	Include IncludeOptions
+}
+
+// CommentPage コメント一覧の1ページ分
+type CommentPage struct {
+	Comments   []models.Comment `json:"comments"`
+	NextCursor *string          `json:"nextCursor"`
+	Total      *int64           `json:"total,omitempty"` // includeTotal を指定した場合のみ
+}
+
+// ListComments タスクのコメントをカーソルページングで取得
+func (s *CommentService) ListComments(taskID, userID string, opts CommentListOptions) (*CommentPage, error) {
+	if _, err := findTaskForMember(s.db, taskID, userID); err != nil {
+		return nil, err
+	}
+
+	desc := true
+	switch opts.Order {
+	case "", "newest":
+	case "oldest":
+		desc = false
+	default:
+		return nil, fmt.Errorf("%w: orderはnewestまたはoldestを指定してください", ErrInvalidInput)
+	}
+	total, err := countTotal(s.db.Model(&models.Comment{}).
+		Where("task_id = ? AND is_hidden = ?", taskID, false), opts.PageOptions)
+	if err != nil {
+		return nil, err
+	}
+	query, limit, err := paginate(preload(s.db, opts.Include).
+		Where("task_id = ? AND is_hidden = ?", taskID, false), opts.PageOptions, desc)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []models.Comment
+	if err := query.Find(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	page := &CommentPage{Total: total}
+	page.Comments, page.NextCursor = pageOf(comments, limit, func(c *models.Comment) (time.Time, string) {
+		return c.CreatedAt, c.ID
+	})
+	if err := attachReactionSummaries(s.db, page.Comments); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// CreateComment コメントを投稿する（Markdown変換とメンション処理を含む）
+func (s *CommentService) CreateComment(taskID, userID, content string) (*models.Comment, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, fmt.Errorf("%w: contentは必須です", ErrInvalidInput)
+	}
+
+	task, err := findTaskForMember(s.db, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	broadcast := hasBroadcastMention(content)
+	if broadcast {
+		if err := ensureCanBroadcastMention(s.db, task.TeamID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	html, err := renderMarkdown(content)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := models.Comment{
+		TaskID:      task.ID,
+		AuthorID:    userID,
+		Content:     content,
+		ContentHTML: html,
+	}
+
+	var mentioned []models.User
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		var syncErr error
+		mentioned, syncErr = syncCommentMentions(tx, task, &comment)
+		return syncErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var broadcastTo []string
+	if broadcast {
+		broadcastTo = s.broadcastRecipients(task, &comment, mentioned)
+	}
+	s.notifyMentions(task, &comment, mentioned)
+	s.notifyBroadcast(task, &comment, broadcastTo)
+	s.notifyNewComment(task, &comment, mentioned, broadcastTo)
+	return s.loadComment(comment.ID)
+}
+
+// UpdateComment コメントを編集し、編集前の内容を履歴に残す
+func (s *CommentService) UpdateComment(taskID, commentID, userID, content string) (*models.Comment, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, fmt.Errorf("%w: contentは必須です", ErrInvalidInput)
+	}
+
+	comment, err := findEditableTaskComment(s.db, taskID, commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Content == content {
+		return s.loadComment(comment.ID)
+	}
+
+	var task models.Task
+	if err := s.db.First(&task, "id = ?", taskID).Error; err != nil {
+		return nil, err
+	}
+	// 編集で新たに書かれた場合のみチーム全員に通知する
+	broadcast := hasBroadcastMention(content) && !hasBroadcastMention(comment.Content)
+	if broadcast {
+		if err := ensureCanBroadcastMention(s.db, task.TeamID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	html, err := renderMarkdown(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var mentioned []models.User
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.CommentRevision{
+			CommentID:  comment.ID,
+			Content:    comment.Content,
+			EditedByID: userID,
+		}).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(comment).Updates(map[string]interface{}{
+			"content":      content,
+			"content_html": html,
+			"is_edited":    true,
+			"edited_at":    &now,
+		}).Error; err != nil {
+			return err
+		}
+		comment.Content = content
+
+		var syncErr error
+		mentioned, syncErr = syncCommentMentions(tx, &task, comment)
+		return syncErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var broadcastTo []string
+	if broadcast {
+		broadcastTo = s.broadcastRecipients(&task, comment, mentioned)
+	}
+	s.notifyMentions(&task, comment, mentioned)
+	s.notifyBroadcast(&task, comment, broadcastTo)
+	return s.loadComment(comment.ID)
+}
+
+// DeleteComment コメントを削除し、タスクの操作履歴に記録する
+func (s *CommentService) DeleteComment(taskID, commentID, userID string) error {
+	comment, err := findEditableTaskComment(s.db, taskID, commentID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return deleteCommentTx(tx, comment, userID)
+	})
+}
+
+// GetCommentHistory コメントの編集履歴を取得
+func (s *CommentService) GetCommentHistory(taskID, commentID, userID string) ([]models.CommentRevision, error) {
+	if _, err := findTaskComment(s.db, taskID, commentID, userID); err != nil {
+		return nil, err
+	}
+
+	var revisions []models.CommentRevision
+	if err := s.db.Preload("EditedBy").Where("comment_id = ?", commentID).
+		Order("created_at DESC").Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// AddReaction コメントにリアクションを追加（同じ絵文字の重複は無視）
+func (s *CommentService) AddReaction(taskID, commentID, userID, emoji string) (*models.Comment, error) {
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" || utf8.RuneCountInString(emoji) > maxReactionEmojiLength || strings.ContainsAny(emoji, " \t\n") {
+		return nil, fmt.Errorf("%w: emojiが不正です", ErrInvalidInput)
+	}
+
+	comment, err := findTaskComment(s.db, taskID, commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reaction := models.CommentReaction{CommentID: comment.ID, UserID: userID, Emoji: emoji}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&reaction).Error; err != nil {
+		return nil, err
+	}
+	return s.loadComment(comment.ID)
+}
+
+// RemoveReaction コメントから自分のリアクションを削除
+func (s *CommentService) RemoveReaction(taskID, commentID, userID, emoji string) (*models.Comment, error) {
+	comment, err := findTaskComment(s.db, taskID, commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Where("comment_id = ? AND user_id = ? AND emoji = ?", comment.ID, userID, emoji).
+		Delete(&models.CommentReaction{}).Error; err != nil {
+		return nil, err
+	}
+	return s.loadComment(comment.ID)
+}
+
+// PinComment コメントをタスクの先頭に固定/固定解除する（タスク作成者またはチーム管理者のみ）
+func (s *CommentService) PinComment(taskID, commentID, userID string, pinned bool) (*models.Task, error) {
+	comment, err := findTaskComment(s.db, taskID, commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var task models.Task
+	if err := s.db.First(&task, "id = ?", taskID).Error; err != nil {
+		return nil, err
+	}
+	if task.CreatorID != userID {
+		admin, err := isTeamAdmin(s.db, task.TeamID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !admin {
+			return nil, ErrForbidden
+		}
+	}
+
+	updates := map[string]interface{}{
+		"is_pinned":    pinned,
+		"pinned_at":    nil,
+		"pinned_by_id": nil,
+	}
+	if pinned {
+		now := time.Now()
+		updates["pinned_at"] = &now
+		updates["pinned_by_id"] = &userID
+	}
+	if err := s.db.Model(comment).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Scopes(PreloadPinnedComments).First(&task, "id = ?", taskID).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// PreloadPinnedComments タスク取得時に固定されたコメントを読み込むスコープ
+func PreloadPinnedComments(db *gorm.DB) *gorm.DB {
+	return db.Preload("PinnedComments", func(db *gorm.DB) *gorm.DB {
+		return db.Where("is_pinned = ? AND is_hidden = ?", true, false).Order("pinned_at ASC").Preload("Author")
+	})
+}
+
+// loadComment レスポンス用に関連とリアクション集計を含めてコメントを取得
+func (s *CommentService) loadComment(commentID string) (*models.Comment, error) {
+	var comment models.Comment
+	if err := s.db.Preload("Author").Preload("Attachments").Preload("Mentions.User").
+		First(&comment, "id = ?", commentID).Error; err != nil {
+		return nil, err
+	}
+	comments := []models.Comment{comment}
+	if err := attachReactionSummaries(s.db, comments); err != nil {
+		return nil, err
+	}
+	return &comments[0], nil
+}
+
+// notifyMentions 新たにメンションされたユーザーのうち、ウォッチャーでない人に通知する
+func (s *CommentService) notifyMentions(task *models.Task, comment *models.Comment, mentioned []models.User) {
+	if len(mentioned) == 0 {
+		return
+	}
+
+	var watcherIDs []string
+	s.db.Model(&models.TaskWatcher{}).Where("task_id = ?", task.ID).Pluck("user_id", &watcherIDs)
+	watching := make(map[string]bool, len(watcherIDs))
+	for _, id := range watcherIDs {
+		watching[id] = true
+	}
+
+	for _, user := range mentioned {
+		if user.ID == comment.AuthorID || watching[user.ID] {
+			continue
+		}
+		msg := NotificationMessage{
+			UserID:     user.ID,
+			Type:       NotificationTypeMention,
+			Title:      fmt.Sprintf("「%s」でメンションされました", task.Title),
+			Body:       comment.Content,
+			EntityType: "task",
+			EntityID:   task.ID,
+		}
+		if s.replyAddresses.Enabled() {
+			replyTo, err := s.replyAddresses.IssueReplyAddress(user.ID, task.ID)
+			if err != nil {
+				log.Printf("返信先アドレスの発行に失敗しました: %v", err)
+			}
+			msg.ReplyTo = replyTo
+		}
+		if err := s.notifier.Notify(msg); err != nil {
+			log.Printf("メンション通知の送信に失敗しました: %v", err)
+		}
+	}
+}
+
+// broadcastRecipients @team・@here で通知するチームのメンバー（投稿者と、個別にメンションされた人を除く）
+func (s *CommentService) broadcastRecipients(task *models.Task, comment *models.Comment, mentioned []models.User) []string {
+	exclude := map[string]bool{comment.AuthorID: true}
+	for _, user := range mentioned {
+		exclude[user.ID] = true
+	}
+	recipients, err := broadcastRecipients(s.db, task.TeamID, exclude)
+	if err != nil {
+		log.Printf("チーム全員へのメンションの宛先の取得に失敗しました: %v", err)
+	}
+	return recipients
+}
+
+// notifyBroadcast @team・@here でチームのメンバーに通知する（ウォッチャーにもコメントの通知の代わりに送る）
+func (s *CommentService) notifyBroadcast(task *models.Task, comment *models.Comment, recipientIDs []string) {
+	for _, id := range recipientIDs {
+		err := s.notifier.Notify(NotificationMessage{
+			UserID:     id,
+			Type:       NotificationTypeMention,
+			Title:      fmt.Sprintf("「%s」でチーム全員にメンションされました", task.Title),
+			Body:       comment.Content,
+			EntityType: "task",
+			EntityID:   task.ID,
+		})
+		if err != nil {
+			log.Printf("メンション通知の送信に失敗しました: %v", err)
+		}
+	}
+}
+
+// notifyNewComment ウォッチャーと担当者に新しいコメントを通知する（投稿者と、メンション通知を受けた人を除く）
+func (s *CommentService) notifyNewComment(task *models.Task, comment *models.Comment, mentioned []models.User, broadcastTo []string) {
+	var recipientIDs []string
+	s.db.Model(&models.TaskWatcher{}).Where("task_id = ?", task.ID).Pluck("user_id", &recipientIDs)
+	if task.AssigneeID != nil {
+		recipientIDs = append(recipientIDs, *task.AssigneeID)
+	}
+
+	skip := map[string]bool{comment.AuthorID: true}
+	watching := make(map[string]bool, len(recipientIDs))
+	for _, id := range recipientIDs {
+		watching[id] = true
+	}
+	for _, user := range mentioned {
+		if !watching[user.ID] {
+			skip[user.ID] = true
+		}
+	}
+	for _, id := range broadcastTo {
+		skip[id] = true
+	}
+
+	for _, id := range recipientIDs {
+		if skip[id] {
+			continue
+		}
+		skip[id] = true
+		err := s.notifier.Notify(NotificationMessage{
+			UserID:     id,
+			Type:       NotificationTypeTaskComment,
+			Title:      fmt.Sprintf("「%s」にコメントが追加されました", task.Title),
+			Body:       comment.Content,
+			EntityType: "task",
+			EntityID:   task.ID,
+		})
+		if err != nil {
+			log.Printf("コメント通知の送信に失敗しました: %v", err)
+		}
+	}
+}
+
+// syncCommentMentions 本文のメンションをチームメンバーに解決して保存し、新規にメンションされたユーザーを返す
+func syncCommentMentions(tx *gorm.DB, task *models.Task, comment *models.Comment) ([]models.User, error) {
+	var names []string
+	for _, name := range extractMentions(comment.Content) {
+		if !broadcastMentionNames[name] {
+			names = append(names, name)
+		}
+	}
+
+	var users []models.User
+	if len(names) > 0 {
+		if err := tx.Joins("JOIN team_members ON team_members.user_id = users.id").
+			Where("users.username IN ? AND team_members.team_id = ? AND team_members.status = ?",
+				names, task.TeamID, models.TeamMemberStatusActive).
+			Find(&users).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var existing []models.CommentMention
+	if err := tx.Where("comment_id = ?", comment.ID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	already := make(map[string]bool, len(existing))
+	for _, m := range existing {
+		already[m.UserID] = true
+	}
+
+	current := make(map[string]bool, len(users))
+	var added []models.User
+	for _, user := range users {
+		current[user.ID] = true
+		if already[user.ID] {
+			continue
+		}
+		if err := tx.Create(&models.CommentMention{CommentID: comment.ID, UserID: user.ID}).Error; err != nil {
+			return nil, err
+		}
+		added = append(added, user)
+	}
+
+	// 編集でメンションが外れたユーザーの記録を削除
+	for _, m := range existing {
+		if !current[m.UserID] {
+			if err := tx.Delete(&models.CommentMention{}, "id = ?", m.ID).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+	return added, nil
+}
+
+// deleteCommentTx コメントを削除し（論理削除）、タスクの操作履歴に記録する
+//
+// 復元できるよう、履歴・リアクション・メンション・添付ファイルは完全に削除するまで残す（purgeCommentsTx）。
+func deleteCommentTx(tx *gorm.DB, comment *models.Comment, actorID string) error {
+	if err := tx.Delete(comment).Error; err != nil {
+		return err
+	}
+	return recordTaskActivity(tx, comment.TaskID, actorID, models.TaskActivityCommentDeleted,
+		fmt.Sprintf("コメント %s を削除しました", comment.ID))
+}
+
+// findTaskComment タスクに属するコメントを取得（チームメンバーのみ）
+func findTaskComment(db *gorm.DB, taskID, commentID, userID string) (*models.Comment, error) {
+	if _, err := findTaskForMember(db, taskID, userID); err != nil {
+		return nil, err
+	}
+
+	var comment models.Comment
+	if err := db.First(&comment, "id = ? AND task_id = ?", commentID, taskID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// findEditableTaskComment 投稿者本人またはチーム管理者のみ編集・削除できるコメントを取得
+func findEditableTaskComment(db *gorm.DB, taskID, commentID, userID string) (*models.Comment, error) {
+	comment, err := findTaskComment(db, taskID, commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.AuthorID == userID {
+		return comment, nil
+	}
+
+	var task models.Task
+	if err := db.Select("team_id").First(&task, "id = ?", taskID).Error; err != nil {
+		return nil, err
+	}
+	admin, err := isTeamAdmin(db, task.TeamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin {
+		return nil, ErrForbidden
+	}
+	return comment, nil
+}
+
+// attachReactionSummaries コメント一覧にリアクション集計をまとめて付与する
+func attachReactionSummaries(db *gorm.DB, comments []models.Comment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(comments))
+	for i, c := range comments {
+		ids[i] = c.ID
+	}
+
+	var reactions []models.CommentReaction
+	if err := db.Where("comment_id IN ?", ids).Order("created_at ASC").Find(&reactions).Error; err != nil {
+		return err
+	}
+
+	summaries := make(map[string][]models.ReactionSummary)
+	for _, r := range reactions {
+		list := summaries[r.CommentID]
+		found := false
+		for i := range list {
+			if list[i].Emoji == r.Emoji {
+				list[i].Count++
+				list[i].UserIDs = append(list[i].UserIDs, r.UserID)
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, models.ReactionSummary{Emoji: r.Emoji, Count: 1, UserIDs: []string{r.UserID}})
+		}
+		summaries[r.CommentID] = list
+	}
+
+	for i := range comments {
+		comments[i].Reactions = summaries[comments[i].ID]
+		if comments[i].Reactions == nil {
+			comments[i].Reactions = []models.ReactionSummary{}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 一度の定期実行で処理するイベント数の上限
+const conferenceSyncBatch = 50
+
+// ConferenceRequest ビデオ会議の作成・更新内容
+type ConferenceRequest struct {
+	Title     string
+	StartDate time.Time
+	EndDate   time.Time
+	TimeZone  string
+	Recurring bool
+	HostEmail string // 会議の主催者（イベントの作成者）
+}
+
+// Conference 作成したビデオ会議
+type Conference struct {
+	ID      string
+	JoinURL string
+}
+
+// ConferenceProvider ビデオ会議サービスとの連携
+type ConferenceProvider interface {
+	Provider() models.ConferenceProvider
+	CreateMeeting(ctx context.Context, req ConferenceRequest) (*Conference, error)
+	// UpdateMeeting 日時などの変更を反映する（日時を持たないサービスでは何もしない）
+	UpdateMeeting(ctx context.Context, meetingID string, req ConferenceRequest) error
+	DeleteMeeting(ctx context.Context, meetingID string) error
+}
+
+// ConferenceService イベントのビデオ会議リンクの作成と、日時変更の反映
+type ConferenceService struct {
+	db        *gorm.DB
+	providers map[models.ConferenceProvider]ConferenceProvider
+}
+
+func NewConferenceService(db *gorm.DB, providers ...ConferenceProvider) *ConferenceService {
+	registered := make(map[models.ConferenceProvider]ConferenceProvider, len(providers))
+	for _, p := range providers {
+		registered[p.Provider()] = p
+	}
+	return &ConferenceService{db: db, providers: registered}
+}
+
+// AddConference イベントにビデオ会議リンクを作成する（既存のリンクは置き換える）
+func (s *ConferenceService) AddConference(ctx context.Context, eventID, userID string, provider models.ConferenceProvider) (*models.Event, error) {
+	event, err := findEditableEvent(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.provider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	s.deleteMeeting(ctx, event)
+	if err := s.createMeeting(ctx, p, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// RemoveConference ビデオ会議リンクを削除し、チームの設定による自動作成も行わないようにする
+func (s *ConferenceService) RemoveConference(ctx context.Context, eventID, userID string) error {
+	event, err := findEditableEvent(s.db, eventID, userID)
+	if err != nil {
+		return err
+	}
+	s.deleteMeeting(ctx, event)
+	return s.db.Model(event).Updates(map[string]interface{}{
+		"conference_provider": models.ConferenceProviderNone,
+		"conference_url":      "",
+		"conference_id":       "",
+		"conference_start":    nil,
+		"conference_end":      nil,
+	}).Error
+}
+
+// SyncConferences チームで設定したビデオ会議をMEETINGイベントに作成し、日時が変わったイベントの会議を更新する
+func (s *ConferenceService) SyncConferences() error {
+	if len(s.providers) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	now := time.Now()
+
+	// チームの設定による自動作成（終了済みの単発イベントは対象外）
+	var pending []models.Event
+	if err := s.db.Select("events.*").
+		Joins("JOIN teams ON teams.id = events.team_id").
+		Where("teams.conference_provider <> '' AND events.type = ? AND events.conference_provider = ''", models.EventTypeMeeting).
+		Where("events.subscription_id IS NULL AND events.connection_id IS NULL").
+		Where("events.is_recurring = ? OR events.end_date > ?", true, now).
+		Order("events.start_date ASC").Limit(conferenceSyncBatch).Find(&pending).Error; err != nil {
+		return err
+	}
+	teamProviders := make(map[string]models.ConferenceProvider)
+	if len(pending) > 0 {
+		var teamIDs []string
+		for _, e := range pending {
+			teamIDs = append(teamIDs, *e.TeamID)
+		}
+		var teams []models.Team
+		if err := s.db.Select("id", "conference_provider").Where("id IN ?", teamIDs).Find(&teams).Error; err != nil {
+			return err
+		}
+		for _, t := range teams {
+			teamProviders[t.ID] = t.ConferenceProvider
+		}
+	}
+	for i := range pending {
+		p, ok := s.providers[teamProviders[*pending[i].TeamID]]
+		if !ok {
+			continue
+		}
+		if err := s.createMeeting(ctx, p, &pending[i]); err != nil {
+			log.Printf("イベント %s のビデオ会議の作成に失敗しました: %v", pending[i].ID, err)
+		}
+	}
+
+	// 日時の変更を反映する
+	var changed []models.Event
+	if err := s.db.Where("conference_id <> '' AND (conference_start IS NULL OR conference_start <> start_date OR conference_end <> end_date)").
+		Limit(conferenceSyncBatch).Find(&changed).Error; err != nil {
+		return err
+	}
+	for i := range changed {
+		event := &changed[i]
+		p, ok := s.providers[event.ConferenceProvider]
+		if !ok {
+			continue
+		}
+		req, err := s.conferenceRequest(event)
+		if err != nil {
+			log.Printf("イベント %s のビデオ会議の更新に失敗しました: %v", event.ID, err)
+			continue
+		}
+		if err := p.UpdateMeeting(ctx, event.ConferenceID, req); err != nil {
+			log.Printf("イベント %s のビデオ会議の更新に失敗しました: %v", event.ID, err)
+			continue
+		}
+		if err := s.db.Model(event).Updates(map[string]interface{}{
+			"conference_start": event.StartDate,
+			"conference_end":   event.EndDate,
+		}).Error; err != nil {
+			log.Printf("イベント %s のビデオ会議の更新に失敗しました: %v", event.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *ConferenceService) createMeeting(ctx context.Context, p ConferenceProvider, event *models.Event) error {
+	req, err := s.conferenceRequest(event)
+	if err != nil {
+		return err
+	}
+	conference, err := p.CreateMeeting(ctx, req)
+	if err != nil {
+		return err
+	}
+	return s.db.Model(event).Updates(map[string]interface{}{
+		"conference_provider": p.Provider(),
+		"conference_url":      conference.JoinURL,
+		"conference_id":       conference.ID,
+		"conference_start":    event.StartDate,
+		"conference_end":      event.EndDate,
+	}).Error
+}
+
+// deleteMeeting 作成済みの会議をサービスから削除する（失敗してもリンクの置き換え・削除は続ける）
+func (s *ConferenceService) deleteMeeting(ctx context.Context, event *models.Event) {
+	if event.ConferenceID == "" {
+		return
+	}
+	p, ok := s.providers[event.ConferenceProvider]
+	if !ok {
+		return
+	}
+	if err := p.DeleteMeeting(ctx, event.ConferenceID); err != nil {
+		log.Printf("イベント %s のビデオ会議の削除に失敗しました: %v", event.ID, err)
+	}
+}
+
+func (s *ConferenceService) conferenceRequest(event *models.Event) (ConferenceRequest, error) {
+	var creator models.User
+	if err := s.db.Select("id", "email").First(&creator, "id = ?", event.CreatorID).Error; err != nil {
+		return ConferenceRequest{}, err
+	}
+	timeZone := event.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	return ConferenceRequest{
+		Title:     event.Title,
+		StartDate: event.StartDate,
+		EndDate:   event.EndDate,
+		TimeZone:  timeZone,
+		Recurring: event.IsRecurring,
+		HostEmail: creator.Email,
+	}, nil
+}
+
+func (s *ConferenceService) provider(provider models.ConferenceProvider) (ConferenceProvider, error) {
+	p, ok := s.providers[models.ConferenceProvider(strings.ToUpper(string(provider)))]
+	if !ok {
+		return nil, fmt.Errorf("%w: ビデオ会議サービス %s は設定されていません", ErrInvalidInput, provider)
+	}
+	return p, nil
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+	"log"
+)
+
+// AddJob 定期実行ジョブを登録する
+func (s *CronService) AddJob(name, spec string, job func() error) error {
+	_, err := s.cron.AddFunc(spec, func() {
+		if err := job(); err != nil {
+			log.Printf("ジョブ %s の実行に失敗しました: %v", name, err)
+		}
+	})
+	return err
+}
+
+// Shutdown 新しいジョブの実行を止め、実行中のジョブの終了を待つ（ctx の期限を過ぎた場合は待たずに返す）
+func (s *CronService) Shutdown(ctx context.Context) error {
+	select {
+	case <-s.cron.Stop().Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 予定の日時からこれ以上過ぎても実行されていないジョブを、停止中に実行されなかったとみなす
+const cronMissedRunGrace = time.Minute
+
+// ErrCronJobRunning 手動で実行しようとした定期実行のジョブがこのサーバーで実行中
+var ErrCronJobRunning = errors.New("このジョブは実行中です")
+
+// CronStateService 定期実行のジョブごとの実行の記録（前回・次回の実行日時、結果）をデータベースに残す
+//
+// 定期実行の間隔はサーバーの起動から数えるため、再起動を繰り返すと間隔の長いジョブ（1日ごとの削除など）が実行されないことがある。
+// 起動時に RunMissed を呼ぶと、記録した次回の予定を過ぎたジョブを実行する。
+// 停止中の分も処理する必要のあるジョブ（リマインダー・アジェンダメールなど）は TrackSince で登録し、前回成功した日時を受け取る。
+// 管理者は一覧で結果を確認し、手動で実行したり、一時停止したりできる（一時停止はデータベースに記録し、すべてのサーバーで止まる）。
+type CronStateService struct {
+	db *gorm.DB
+
+	mu   sync.Mutex
+	jobs []*trackedCronJob
+}
+
+// trackedCronJob 記録する定期実行のジョブ
+type trackedCronJob struct {
+	name     string
+	spec     string
+	schedule cron.Schedule // 間隔の指定が不正な場合は nil（CronService.AddJob で登録に失敗する）
+	job      func(since time.Time) error
+	running  atomic.Int32 // このサーバーで実行中の数
+}
+
+// CronJobStatus 定期実行のジョブの状態（管理者向けの一覧）
+type CronJobStatus struct {
+	models.CronJobState
+	Running bool `json:"running"` // 一覧を返したサーバーで実行中か
+}
+
+func NewCronStateService(db *gorm.DB) *CronStateService {
+	return &CronStateService{db: db}
+}
+
+// Track 実行を記録するジョブにする（CronService.AddJob に渡す）
+func (s *CronStateService) Track(name, spec string, job func() error) func() error {
+	return s.TrackSince(name, spec, func(time.Time) error { return job() })
+}
+
+// TrackSince 実行を記録するジョブにする。job には前回成功した実行を開始した日時（記録がない場合はゼロ値）を渡す
+func (s *CronStateService) TrackSince(name, spec string, job func(since time.Time) error) func() error {
+	tracked := &trackedCronJob{name: name, spec: spec, job: job}
+	if schedule, err := cron.ParseStandard(spec); err == nil {
+		tracked.schedule = schedule
+	}
+
+	s.mu.Lock()
+	s.jobs = append(s.jobs, tracked)
+	s.mu.Unlock()
+	return func() error {
+		tracked.running.Add(1)
+		defer tracked.running.Add(-1)
+		return s.run(tracked, false)
+	}
+}
+
+// run ジョブを実行し、開始・終了の日時と結果、次に実行する予定の日時を記録する（記録に失敗してもジョブは実行する）
+//
+// 一時停止中のジョブは、手動での実行（manual）でなければ実行しない。
+func (s *CronStateService) run(job *trackedCronJob, manual bool) error {
+	started := time.Now()
+	var since time.Time
+	var state models.CronJobState
+	if err := s.db.Where("name = ?", job.name).Limit(1).Find(&state).Error; err != nil {
+		log.Printf("定期実行のジョブ（%s）の記録の取得に失敗しました: %v", job.name, err)
+	} else {
+		if state.Paused && !manual {
+			return nil
+		}
+		if state.LastSucceededAt != nil {
+			since = *state.LastSucceededAt
+		}
+	}
+	s.logRecord(job, map[string]interface{}{"last_started_at": started})
+
+	err := job.job(since)
+
+	finished := time.Now()
+	updates := map[string]interface{}{
+		"last_finished_at": finished,
+		"last_duration_ms": finished.Sub(started).Milliseconds(),
+		"last_error":       "",
+		"next_run_at":      job.next(finished),
+	}
+	if err != nil {
+		updates["last_error"] = err.Error()
+	} else {
+		updates["last_succeeded_at"] = started
+	}
+	s.logRecord(job, updates)
+	return err
+}
+
+// record ジョブの記録を更新する（なければ作成する）
+func (s *CronStateService) record(job *trackedCronJob, updates map[string]interface{}) error {
+	updates["schedule"] = job.spec
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.CronJobState{Name: job.name, Schedule: job.spec}).Error; err != nil {
+		return err
+	}
+	return s.db.Model(&models.CronJobState{}).Where("name = ?", job.name).Updates(updates).Error
+}
+
+// logRecord ジョブの記録を更新する（失敗してもジョブの実行は止めないため、ログに残すだけにする）
+func (s *CronStateService) logRecord(job *trackedCronJob, updates map[string]interface{}) {
+	if err := s.record(job, updates); err != nil {
+		log.Printf("定期実行のジョブ（%s）の記録に失敗しました: %v", job.name, err)
+	}
+}
+
+// next from の後に実行する予定の日時（間隔の指定が不正な場合は nil）
+func (j *trackedCronJob) next(from time.Time) *time.Time {
+	if j.schedule == nil {
+		return nil
+	}
+	next := j.schedule.Next(from)
+	return &next
+}
+
+// RunMissed 記録した次回の予定を過ぎても実行されていないジョブ（停止中に予定の日時を迎えたもの）を実行する（起動時に呼ぶ）
+//
+// 記録のないジョブ（初めて登録したもの）と一時停止中のジョブは実行しない。実行はバックグラウンドで順に行う。
+func (s *CronStateService) RunMissed() {
+	now := time.Now()
+	var missed []*trackedCronJob
+	for _, job := range s.trackedJobs() {
+		var state models.CronJobState
+		result := s.db.Where("name = ?", job.name).Limit(1).Find(&state)
+		if result.Error != nil {
+			log.Printf("定期実行のジョブ（%s）の記録の取得に失敗しました: %v", job.name, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			s.logRecord(job, map[string]interface{}{"next_run_at": job.next(now)})
+			continue
+		}
+		if !state.Paused && state.NextRunAt != nil && state.NextRunAt.Before(now.Add(-cronMissedRunGrace)) {
+			log.Printf("停止中に実行されなかった定期実行のジョブを実行します: %s（予定 %s）", job.name, state.NextRunAt.Format(time.RFC3339))
+			missed = append(missed, job)
+		}
+	}
+	if len(missed) == 0 {
+		return
+	}
+	go func() {
+		for _, job := range missed {
+			job.running.Add(1)
+			if err := s.run(job, false); err != nil {
+				log.Printf("ジョブ %s の実行に失敗しました: %v", job.name, err)
+			}
+			job.running.Add(-1)
+		}
+	}()
+}
+
+func (s *CronStateService) trackedJobs() []*trackedCronJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*trackedCronJob(nil), s.jobs...)
+}
+
+func (s *CronStateService) findJob(name string) (*trackedCronJob, error) {
+	for _, job := range s.trackedJobs() {
+		if job.name == name {
+			return job, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// ListJobs 定期実行のジョブの一覧（登録した順。前回の実行の結果・エラーと次に実行する予定の日時）
+func (s *CronStateService) ListJobs() ([]CronJobStatus, error) {
+	jobs := s.trackedJobs()
+	names := make([]string, len(jobs))
+	for i, job := range jobs {
+		names[i] = job.name
+	}
+	var states []models.CronJobState
+	if len(names) > 0 {
+		if err := s.db.Where("name IN ?", names).Find(&states).Error; err != nil {
+			return nil, err
+		}
+	}
+	byName := make(map[string]models.CronJobState, len(states))
+	for _, state := range states {
+		byName[state.Name] = state
+	}
+
+	statuses := make([]CronJobStatus, len(jobs))
+	for i, job := range jobs {
+		state, ok := byName[job.name]
+		if !ok {
+			state = models.CronJobState{Name: job.name}
+		}
+		state.Schedule = job.spec
+		statuses[i] = CronJobStatus{CronJobState: state, Running: job.running.Load() > 0}
+	}
+	return statuses, nil
+}
+
+// GetJob 定期実行のジョブの状態
+func (s *CronStateService) GetJob(name string) (*CronJobStatus, error) {
+	job, err := s.findJob(name)
+	if err != nil {
+		return nil, err
+	}
+	var state models.CronJobState
+	if err := s.db.Where("name = ?", name).Limit(1).Find(&state).Error; err != nil {
+		return nil, err
+	}
+	state.Name = job.name
+	state.Schedule = job.spec
+	return &CronJobStatus{CronJobState: state, Running: job.running.Load() > 0}, nil
+}
+
+// TriggerJob 定期実行のジョブを今すぐバックグラウンドで実行する（一時停止中でも実行する。このサーバーで実行中の場合は ErrCronJobRunning）
+func (s *CronStateService) TriggerJob(name string) (*CronJobStatus, error) {
+	job, err := s.findJob(name)
+	if err != nil {
+		return nil, err
+	}
+	if !job.running.CompareAndSwap(0, 1) {
+		return nil, ErrCronJobRunning
+	}
+	go func() {
+		defer job.running.Add(-1)
+		log.Printf("定期実行のジョブを手動で実行します: %s", job.name)
+		if err := s.run(job, true); err != nil {
+			log.Printf("ジョブ %s の実行に失敗しました: %v", job.name, err)
+		}
+	}()
+	return s.GetJob(name)
+}
+
+// SetPaused 定期実行のジョブを一時停止する・再開する（実行中の回は止めない）
+func (s *CronStateService) SetPaused(name string, paused bool) (*CronJobStatus, error) {
+	job, err := s.findJob(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.record(job, map[string]interface{}{"paused": paused}); err != nil {
+		return nil, err
+	}
+	return s.GetJob(name)
+}
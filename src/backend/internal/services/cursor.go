@@ -0,0 +1,109 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// PageOptions 一覧のカーソルページングの指定（cursor は前のページの nextCursor、limit は既定20・最大100）
+type PageOptions struct {
+	Cursor string
+	Limit  int
+	// Sort 作成日時の昇順・降順（CursorSortFields）。指定した場合は一覧の既定の順より優先する
+	Sort SortOptions
+	// IncludeTotal 条件に合う全件数も数える（件数の多い一覧では遅くなるため、指定した場合のみ）
+	IncludeTotal bool
+}
+
+// encodeCursor 作成日時とIDからページングカーソルを生成する
+func encodeCursor(createdAt time.Time, id string) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor ページングカーソルを作成日時とIDに戻す
+func decodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("%w: cursorが不正です", ErrInvalidInput)
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return time.Time{}, "", fmt.Errorf("%w: cursorが不正です", ErrInvalidInput)
+	}
+	t, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("%w: cursorが不正です", ErrInvalidInput)
+	}
+	return t, parts[1], nil
+}
+
+// normalizeLimit 取得件数を既定値と上限の範囲に収める
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		return maxPageLimit
+	}
+	return limit
+}
+
+// paginate 作成日時・IDの順（desc は新しい順）に並べ、カーソルの次から limit+1 件を取得するクエリにする
+//
+// 取得した結果は pageOf で1ページ分と次のカーソルに分ける。
+func paginate(query *gorm.DB, opts PageOptions, desc bool) (*gorm.DB, int, error) {
+	limit := normalizeLimit(opts.Limit)
+	if len(opts.Sort) > 0 {
+		desc = opts.Sort[0].Desc
+	}
+	if opts.Cursor != "" {
+		createdAt, id, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, 0, err
+		}
+		if desc {
+			query = query.Where("(created_at < ?) OR (created_at = ? AND id < ?)", createdAt, createdAt, id)
+		} else {
+			query = query.Where("(created_at > ?) OR (created_at = ? AND id > ?)", createdAt, createdAt, id)
+		}
+	}
+	if desc {
+		query = query.Order("created_at DESC, id DESC")
+	} else {
+		query = query.Order("created_at ASC, id ASC")
+	}
+	return query.Limit(limit + 1), limit, nil
+}
+
+// countTotal IncludeTotal の場合に、paginate する前の条件に合う全件数を数える（指定がなければ nil）
+func countTotal(query *gorm.DB, opts PageOptions) (*int64, error) {
+	if !opts.IncludeTotal {
+		return nil, nil
+	}
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	return &total, nil
+}
+
+// pageOf paginate で取得した limit+1 件を1ページ分に切り詰め、続きがあれば次のカーソルを返す
+func pageOf[T any](items []T, limit int, key func(*T) (time.Time, string)) ([]T, *string) {
+	if len(items) <= limit {
+		return items, nil
+	}
+	items = items[:limit]
+	createdAt, id := key(&items[limit-1])
+	next := encodeCursor(createdAt, id)
+	return items, &next
+}
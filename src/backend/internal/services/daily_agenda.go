@@ -0,0 +1,237 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// 送信時刻を指定しない場合の既定（7:00）
+	defaultDailyAgendaMinute = 7 * 60
+	// サーバー停止中などで送れなかったアジェンダメールを後から送る猶予
+	dailyAgendaCatchUp = 3 * time.Hour
+)
+
+// DailyAgendaInput アジェンダメールの設定（省略した項目は変更しない）
+type DailyAgendaInput struct {
+	Enabled *bool   `json:"enabled"`
+	SendAt  *string `json:"sendAt" binding:"omitempty,datetime=15:04"` // HH:MM（User.TimeZone の時刻）
+}
+
+// DailyAgendaSettings アジェンダメールの設定
+type DailyAgendaSettings struct {
+	Enabled  bool   `json:"enabled"`
+	SendAt   string `json:"sendAt"`
+	TimeZone string `json:"timeZone"` // 送信時刻の基準（勤務時間の設定と同じ）
+}
+
+type DailyAgendaService struct {
+	db            *gorm.DB
+	agendaService *AgendaService
+	mailer        Mailer
+}
+
+func NewDailyAgendaService(db *gorm.DB, agendaService *AgendaService, mailer Mailer) *DailyAgendaService {
+	return &DailyAgendaService{db: db, agendaService: agendaService, mailer: mailer}
+}
+
+// GetSettings アジェンダメールの設定を取得（未設定の場合は無効・7:00）
+func (s *DailyAgendaService) GetSettings(userID string) (*DailyAgendaSettings, error) {
+	var user models.User
+	if err := s.db.Select("id", "time_zone").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	setting, err := s.loadSetting(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &DailyAgendaSettings{
+		Enabled:  setting.Enabled,
+		SendAt:   formatClock(setting.SendMinute),
+		TimeZone: user.TimeZone,
+	}, nil
+}
+
+// UpdateSettings アジェンダメールの有効・無効と送信時刻を設定する
+func (s *DailyAgendaService) UpdateSettings(userID string, input DailyAgendaInput) (*DailyAgendaSettings, error) {
+	setting, err := s.loadSetting(userID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Enabled != nil {
+		setting.Enabled = *input.Enabled
+	}
+	if input.SendAt != nil {
+		minute, err := parseClock(*input.SendAt)
+		if err != nil {
+			return nil, err
+		}
+		if minute >= 24*60 {
+			return nil, fmt.Errorf("%w: sendAtは00:00〜23:59で指定してください", ErrInvalidInput)
+		}
+		setting.SendMinute = minute
+	}
+
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "send_minute", "updated_at"}),
+	}).Create(setting).Error; err != nil {
+		return nil, err
+	}
+	return s.GetSettings(userID)
+}
+
+func (s *DailyAgendaService) loadSetting(userID string) (*models.DailyAgendaSetting, error) {
+	var setting models.DailyAgendaSetting
+	result := s.db.Where("user_id = ?", userID).Limit(1).Find(&setting)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		setting = models.DailyAgendaSetting{UserID: userID, SendMinute: defaultDailyAgendaMinute}
+	}
+	return &setting, nil
+}
+
+// SendDailyAgendas 送信時刻を迎えたユーザーに今日の予定をメールで送る（Cronジョブ用）
+//
+// 日付はユーザーのタイムゾーンで判断し、送った日を記録して1日1通にする。
+// 送信時刻から dailyAgendaCatchUp を過ぎた場合と、イベントも期限のタスクもない日は送らない。
+// ただし since（前回成功した実行の日時）が送信時刻より前の場合は、停止中に送信時刻を迎えたとみなしてその日のうちは送る。
+func (s *DailyAgendaService) SendDailyAgendas(since time.Time) error {
+	var settings []models.DailyAgendaSetting
+	if err := s.db.Preload("User").Where("enabled = ?", true).Find(&settings).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range settings {
+		setting := &settings[i]
+		if setting.User.Email == "" {
+			continue
+		}
+		loc, err := models.LoadLocation(setting.User.TimeZone)
+		if err != nil {
+			loc = time.UTC
+		}
+		local := now.In(loc)
+		today := local.Format("2006-01-02")
+		dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		sendAt := dayStart.Add(time.Duration(setting.SendMinute) * time.Minute)
+		missed := !since.IsZero() && since.Before(sendAt)
+		if setting.LastSentOn == today || now.Before(sendAt) || (now.After(sendAt.Add(dailyAgendaCatchUp)) && !missed) {
+			continue
+		}
+
+		// 送った日を記録できた場合のみ送る（複数のサーバーで実行しても二重に送らない）
+		result := s.db.Model(&models.DailyAgendaSetting{}).
+			Where("user_id = ? AND (last_sent_on IS NULL OR last_sent_on <> ?)", setting.UserID, today).
+			UpdateColumn("last_sent_on", today)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		if err := s.send(&setting.User, loc, dayStart); err != nil {
+			log.Printf("アジェンダメールの送信に失敗しました（%s）: %v", setting.User.Email, err)
+		}
+	}
+	return nil
+}
+
+// dailyAgendaView アジェンダメールのテンプレートに渡す内容
+type dailyAgendaView struct {
+	Name     string
+	Date     string
+	Holidays []string
+	Events   []dailyAgendaLine
+	Tasks    []dailyAgendaLine
+}
+
+type dailyAgendaLine struct {
+	When     string
+	Title    string
+	Location string
+	Detail   string
+}
+
+func (s *DailyAgendaService) send(user *models.User, loc *time.Location, dayStart time.Time) error {
+	items, err := s.agendaService.GetAgenda(user.ID, dayStart, dayStart.AddDate(0, 0, 1), OccurrenceFilter{})
+	if err != nil {
+		return err
+	}
+	items = LocalizeAgenda(items, loc)
+
+	locations, err := s.eventLocations(items)
+	if err != nil {
+		return err
+	}
+
+	view := dailyAgendaView{
+		Name: userDisplayName(user),
+		Date: dayStart.Format("2006-01-02") + "（" + weekdayLabels[dayStart.Weekday()] + "）",
+	}
+	for _, item := range items {
+		switch item.Kind {
+		case AgendaItemHoliday:
+			view.Holidays = append(view.Holidays, item.Title)
+		case AgendaItemEvent:
+			line := dailyAgendaLine{When: "終日", Title: item.Title}
+			if !item.AllDay {
+				line.When = item.StartDate.Format("15:04") + "〜" + item.EndDate.Format("15:04")
+				if !item.EndDate.Before(dayStart.AddDate(0, 0, 1)) {
+					line.When = item.StartDate.Format("15:04") + "〜"
+				}
+			}
+			line.Location = locations[item.ID]
+			view.Events = append(view.Events, line)
+		case AgendaItemTask:
+			line := dailyAgendaLine{When: item.StartDate.Format("15:04") + "まで", Title: item.Title}
+			if item.Task != nil && (item.Task.Priority == models.PriorityHigh || item.Task.Priority == models.PriorityUrgent) {
+				line.Detail = "優先度: " + string(item.Task.Priority)
+			}
+			view.Tasks = append(view.Tasks, line)
+		}
+	}
+	if len(view.Events) == 0 && len(view.Tasks) == 0 {
+		return nil
+	}
+
+	msg, err := renderEmail(EmailTemplateDailyAgenda, view)
+	if err != nil {
+		return err
+	}
+	msg.To = user.Email
+	return s.mailer.Send(msg)
+}
+
+// eventLocations アジェンダのイベントの場所（会議室を含む、イベントIDごと）
+func (s *DailyAgendaService) eventLocations(items []AgendaItem) (map[string]string, error) {
+	var ids []string
+	for _, item := range items {
+		if item.Kind == AgendaItemEvent {
+			ids = append(ids, item.ID)
+		}
+	}
+	locations := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return locations, nil
+	}
+	var events []models.Event
+	if err := s.db.Preload("Room").Select("id", "location", "room_id").Where("id IN ?", ids).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	for i := range events {
+		locations[events[i].ID] = eventLocation(&events[i])
+	}
+	return locations, nil
+}
+
+var weekdayLabels = [...]string{"日", "月", "火", "水", "木", "金", "土"}
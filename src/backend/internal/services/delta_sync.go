@@ -0,0 +1,427 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultChangesLimit = 100
+	maxChangesLimit     = 500
+	// 削除の記録を残す期間（これより前に同期を終えたクライアントは全件を取り直す）
+	syncTombstoneRetention = 30 * 24 * time.Hour
+	// 変更からこの時間が経つまでは返さない
+	//
+	// 更新日時はコミット前に決まるため、実行中のトランザクションの変更を飛ばして cursor が先に進まないようにする。
+	syncSettle = 5 * time.Second
+)
+
+// ErrResyncRequired 差分同期の since が古く、その後の削除の記録が残っていない（全件の取り直しが必要）
+var ErrResyncRequired = errors.New("sinceが古すぎます。sinceを指定せずに全件を取り直してください")
+
+// EntityChanges 1種類の変更（前回の同期の後に作成・更新したもの、削除したもののID）
+type EntityChanges[T any] struct {
+	Created []T      `json:"created"`
+	Updated []T      `json:"updated"`
+	Deleted []string `json:"deleted"`
+}
+
+// ChangeSet 差分同期の応答
+type ChangeSet struct {
+	Tasks    EntityChanges[models.Task]    `json:"tasks"`
+	Events   EntityChanges[models.Event]   `json:"events"`
+	Comments EntityChanges[models.Comment] `json:"comments"`
+	// NextCursor 次の同期で since に指定する値（hasMore の場合は続きの取得に使う）
+	NextCursor string `json:"nextCursor"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+// ChangesOptions 差分同期の指定（since は前回の nextCursor、空は全件。limit は既定100・最大500）
+type ChangesOptions struct {
+	Since string
+	Limit int
+}
+
+// syncCursor 差分同期の位置
+//
+// At・ID は返し終えた最後の変更（変更日時とIDの順）、From は同期を始めた位置（全件の取得ではゼロ値）。
+// From より後に作成したものを created、それ以外を updated として返す。
+type syncCursor struct {
+	At   time.Time
+	ID   string
+	From time.Time
+}
+
+func (c syncCursor) encode() string {
+	raw := strings.Join([]string{c.At.UTC().Format(time.RFC3339Nano), c.ID, c.From.UTC().Format(time.RFC3339Nano)}, "|")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeSyncCursor(cursor string) (syncCursor, error) {
+	invalid := fmt.Errorf("%w: sinceが不正です", ErrInvalidInput)
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return syncCursor{}, invalid
+	}
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 {
+		return syncCursor{}, invalid
+	}
+	at, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return syncCursor{}, invalid
+	}
+	from, err := time.Parse(time.RFC3339Nano, parts[2])
+	if err != nil {
+		return syncCursor{}, invalid
+	}
+	return syncCursor{At: at, ID: parts[1], From: from}, nil
+}
+
+// DeltaSyncService オフラインで使うクライアント向けの差分同期（所属するチームのタスク・見られるイベント・タスクのコメント）
+//
+// 変更は更新日時とIDの順に返し、cursor にはどこまで返したかを記録する。
+// 削除は行が残らないため、gormのコールバックで削除の記録（SyncTombstone）を残して返す。
+// チームから外れた場合のそのチームのタスク・イベントは削除として返さない（クライアントは所属するチームと照らし合わせる）。
+type DeltaSyncService struct {
+	db *gorm.DB
+}
+
+func NewDeltaSyncService(db *gorm.DB) *DeltaSyncService {
+	return &DeltaSyncService{db: db}
+}
+
+// syncChange 返す候補の変更
+type syncChange struct {
+	at    time.Time
+	id    string
+	apply func(set *ChangeSet, from time.Time)
+}
+
+// ListChanges since より後のタスク・イベント・コメントの作成・更新・削除を、変更の古い順に limit 件まで返す
+//
+// since を指定しない場合は現在のものをすべて created として返す（削除は返さない）。
+// hasMore の間は nextCursor で続きを取得し、最後の nextCursor を次の同期の since にする。
+func (s *DeltaSyncService) ListChanges(userID string, opts ChangesOptions) (*ChangeSet, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultChangesLimit
+	}
+	if limit > maxChangesLimit {
+		limit = maxChangesLimit
+	}
+	now := time.Now()
+	until := now.Add(-syncSettle)
+
+	var cursor syncCursor
+	if opts.Since != "" {
+		var err error
+		if cursor, err = decodeSyncCursor(opts.Since); err != nil {
+			return nil, err
+		}
+		if !cursor.From.IsZero() && cursor.From.Before(now.Add(-syncTombstoneRetention)) {
+			return nil, ErrResyncRequired
+		}
+	}
+	set := &ChangeSet{}
+	if !cursor.At.Before(until) {
+		set.NextCursor = opts.Since
+		return set.normalize(), nil
+	}
+
+	var changes []syncChange
+	for _, list := range []func(string, syncCursor, time.Time, int) ([]syncChange, error){
+		s.taskChanges, s.eventChanges, s.commentChanges,
+	} {
+		found, err := list(userID, cursor, until, limit)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, found...)
+	}
+	if opts.Since != "" {
+		found, err := s.tombstoneChanges(userID, cursor, until, limit)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, found...)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if !changes[i].at.Equal(changes[j].at) {
+			return changes[i].at.Before(changes[j].at)
+		}
+		return changes[i].id < changes[j].id
+	})
+
+	next := syncCursor{At: until, From: until}
+	if len(changes) > limit {
+		changes = changes[:limit]
+		last := changes[limit-1]
+		next = syncCursor{At: last.at, ID: last.id, From: cursor.From}
+		set.HasMore = true
+	}
+	for _, change := range changes {
+		change.apply(set, cursor.From)
+	}
+	if err := s.dropVisibleEvents(userID, set); err != nil {
+		return nil, err
+	}
+	set.NextCursor = next.encode()
+	return set.normalize(), nil
+}
+
+// afterCursor 変更日時の列が cursor より後、until より前のもの（変更日時・IDの順に limit+1 件）
+func afterCursor(query *gorm.DB, changedAt, id string, cursor syncCursor, until time.Time, limit int) *gorm.DB {
+	return query.
+		Where("("+changedAt+" > ? OR ("+changedAt+" = ? AND "+id+" > ?)) AND "+changedAt+" < ?",
+			cursor.At, cursor.At, cursor.ID, until).
+		Order(changedAt + " ASC, " + id + " ASC").
+		Limit(limit + 1)
+}
+
+// activeTeamIDs ユーザーが所属するチームのIDのサブクエリ
+func (s *DeltaSyncService) activeTeamIDs(userID string) *gorm.DB {
+	return s.db.Session(&gorm.Session{NewDB: true}).Model(&models.TeamMember{}).
+		Select("team_id").Where("user_id = ? AND status = ?", userID, models.TeamMemberStatusActive)
+}
+
+// taskChanges 所属するチームのタスクの変更（全件の取得ではアーカイブしたタスクを含めない）
+func (s *DeltaSyncService) taskChanges(userID string, cursor syncCursor, until time.Time, limit int) ([]syncChange, error) {
+	query := s.db.Where("tasks.team_id IN (?)", s.activeTeamIDs(userID))
+	if cursor.At.IsZero() {
+		query = query.Where("tasks.archived_at IS NULL")
+	}
+	var tasks []models.Task
+	if err := afterCursor(query, "tasks.updated_at", "tasks.id", cursor, until, limit).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	changes := make([]syncChange, 0, len(tasks))
+	for _, task := range tasks {
+		task := task
+		changes = append(changes, syncChange{at: task.UpdatedAt, id: task.ID, apply: func(set *ChangeSet, from time.Time) {
+			if task.CreatedAt.After(from) {
+				set.Tasks.Created = append(set.Tasks.Created, task)
+			} else {
+				set.Tasks.Updated = append(set.Tasks.Updated, task)
+			}
+		}})
+	}
+	return changes, nil
+}
+
+func (s *DeltaSyncService) eventChanges(userID string, cursor syncCursor, until time.Time, limit int) ([]syncChange, error) {
+	var events []models.Event
+	if err := afterCursor(s.db.Scopes(visibleEventsScope(userID)),
+		"events.updated_at", "events.id", cursor, until, limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	changes := make([]syncChange, 0, len(events))
+	for _, event := range events {
+		event := event
+		changes = append(changes, syncChange{at: event.UpdatedAt, id: event.ID, apply: func(set *ChangeSet, from time.Time) {
+			if event.CreatedAt.After(from) {
+				set.Events.Created = append(set.Events.Created, event)
+			} else {
+				set.Events.Updated = append(set.Events.Updated, event)
+			}
+		}})
+	}
+	return changes, nil
+}
+
+// commentChanges 所属するチームのタスクのコメントの変更（非表示にしたコメントは削除として返す）
+func (s *DeltaSyncService) commentChanges(userID string, cursor syncCursor, until time.Time, limit int) ([]syncChange, error) {
+	taskIDs := s.db.Session(&gorm.Session{NewDB: true}).Model(&models.Task{}).
+		Select("id").Where("team_id IN (?)", s.activeTeamIDs(userID))
+	query := s.db.Where("comments.task_id IN (?)", taskIDs)
+	if cursor.At.IsZero() {
+		query = query.Where("comments.is_hidden = ?", false)
+	}
+	var comments []models.Comment
+	if err := afterCursor(query, "COALESCE(comments.updated_at, comments.created_at)", "comments.id",
+		cursor, until, limit).Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	if err := attachReactionSummaries(s.db, comments); err != nil {
+		return nil, err
+	}
+	changes := make([]syncChange, 0, len(comments))
+	for _, comment := range comments {
+		comment := comment
+		at := comment.CreatedAt
+		if comment.UpdatedAt != nil {
+			at = *comment.UpdatedAt
+		}
+		changes = append(changes, syncChange{at: at, id: comment.ID, apply: func(set *ChangeSet, from time.Time) {
+			switch {
+			case comment.IsHidden:
+				set.Comments.Deleted = append(set.Comments.Deleted, comment.ID)
+			case comment.CreatedAt.After(from):
+				set.Comments.Created = append(set.Comments.Created, comment)
+			default:
+				set.Comments.Updated = append(set.Comments.Updated, comment)
+			}
+		}})
+	}
+	return changes, nil
+}
+
+// tombstoneChanges 所属するチームのもの・自分あての削除の記録
+func (s *DeltaSyncService) tombstoneChanges(userID string, cursor syncCursor, until time.Time, limit int) ([]syncChange, error) {
+	var tombstones []models.SyncTombstone
+	if err := afterCursor(s.db.Where("team_id IN (?) OR user_id = ?", s.activeTeamIDs(userID), userID),
+		"deleted_at", "id", cursor, until, limit).Find(&tombstones).Error; err != nil {
+		return nil, err
+	}
+	changes := make([]syncChange, 0, len(tombstones))
+	for _, tombstone := range tombstones {
+		tombstone := tombstone
+		changes = append(changes, syncChange{at: tombstone.DeletedAt, id: tombstone.ID, apply: func(set *ChangeSet, _ time.Time) {
+			switch tombstone.EntityType {
+			case models.SyncEntityTask:
+				set.Tasks.Deleted = append(set.Tasks.Deleted, tombstone.EntityID)
+			case models.SyncEntityEvent:
+				set.Events.Deleted = append(set.Events.Deleted, tombstone.EntityID)
+			case models.SyncEntityComment:
+				set.Comments.Deleted = append(set.Comments.Deleted, tombstone.EntityID)
+			}
+		}})
+	}
+	return changes, nil
+}
+
+// dropVisibleEvents 招待を取り消された後もチームのイベントとして見られるイベントを、削除から除く
+func (s *DeltaSyncService) dropVisibleEvents(userID string, set *ChangeSet) error {
+	if len(set.Events.Deleted) == 0 {
+		return nil
+	}
+	var visible []string
+	if err := s.db.Model(&models.Event{}).Scopes(visibleEventsScope(userID)).
+		Where("events.id IN ?", set.Events.Deleted).Pluck("events.id", &visible).Error; err != nil {
+		return err
+	}
+	if len(visible) == 0 {
+		return nil
+	}
+	keep := map[string]bool{}
+	for _, id := range visible {
+		keep[id] = true
+	}
+	deleted := set.Events.Deleted[:0]
+	for _, id := range set.Events.Deleted {
+		if !keep[id] {
+			deleted = append(deleted, id)
+		}
+	}
+	set.Events.Deleted = deleted
+	return nil
+}
+
+// normalize 空の一覧を null ではなく [] にし、同じものの重複した削除をまとめる
+func (set *ChangeSet) normalize() *ChangeSet {
+	normalizeChanges(&set.Tasks)
+	normalizeChanges(&set.Events)
+	normalizeChanges(&set.Comments)
+	return set
+}
+
+func normalizeChanges[T any](changes *EntityChanges[T]) {
+	if changes.Created == nil {
+		changes.Created = []T{}
+	}
+	if changes.Updated == nil {
+		changes.Updated = []T{}
+	}
+	seen := map[string]bool{}
+	deleted := []string{}
+	for _, id := range changes.Deleted {
+		if !seen[id] {
+			seen[id] = true
+			deleted = append(deleted, id)
+		}
+	}
+	changes.Deleted = deleted
+}
+
+// PurgeTombstones 保存期間を過ぎた削除の記録を削除する（定期実行）
+func (s *DeltaSyncService) PurgeTombstones() error {
+	return s.db.Where("deleted_at < ?", time.Now().Add(-syncTombstoneRetention)).
+		Delete(&models.SyncTombstone{}).Error
+}
+
+// RegisterCallbacks タスク・イベント・コメントの削除と、イベントの招待の取り消しを記録するgormのコールバックを登録する
+//
+// 記録は削除と同じトランザクションで行う。IDの分からない一括削除（条件での削除など）は記録できない。
+func (s *DeltaSyncService) RegisterCallbacks() error {
+	return s.db.Callback().Delete().After("gorm:delete").Register("delta_sync:after_delete", s.recordDeletes)
+}
+
+func (s *DeltaSyncService) recordDeletes(tx *gorm.DB) {
+	if tx.Error != nil || tx.RowsAffected == 0 || !tx.Statement.ReflectValue.IsValid() {
+		return
+	}
+	var tombstones []models.SyncTombstone
+	value := reflect.Indirect(tx.Statement.ReflectValue)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			tombstones = append(tombstones, s.tombstonesFor(tx, reflect.Indirect(value.Index(i)))...)
+		}
+	case reflect.Struct:
+		tombstones = s.tombstonesFor(tx, value)
+	}
+	if len(tombstones) == 0 {
+		return
+	}
+	if err := tx.Session(&gorm.Session{NewDB: true}).Create(&tombstones).Error; err != nil {
+		tx.AddError(err)
+	}
+}
+
+func (s *DeltaSyncService) tombstonesFor(tx *gorm.DB, value reflect.Value) []models.SyncTombstone {
+	if value.Kind() != reflect.Struct || !value.CanInterface() {
+		return nil
+	}
+	now := time.Now()
+	switch record := value.Interface().(type) {
+	case models.Task:
+		if record.ID == "" || record.TeamID == "" {
+			return nil
+		}
+		teamID := record.TeamID
+		return []models.SyncTombstone{{EntityType: models.SyncEntityTask, EntityID: record.ID, TeamID: &teamID, DeletedAt: now}}
+	case models.Event:
+		if record.ID == "" || record.CreatorID == "" {
+			return nil
+		}
+		creatorID := record.CreatorID
+		return []models.SyncTombstone{{EntityType: models.SyncEntityEvent, EntityID: record.ID,
+			TeamID: record.TeamID, UserID: &creatorID, DeletedAt: now}}
+	case models.EventAttendee:
+		if record.EventID == "" || record.UserID == "" {
+			return nil
+		}
+		userID := record.UserID
+		return []models.SyncTombstone{{EntityType: models.SyncEntityEvent, EntityID: record.EventID, UserID: &userID, DeletedAt: now}}
+	case models.Comment:
+		if record.ID == "" || record.TaskID == "" {
+			return nil
+		}
+		var task models.Task
+		if err := tx.Session(&gorm.Session{NewDB: true}).Select("id", "team_id").
+			Where("id = ?", record.TaskID).Take(&task).Error; err != nil {
+			return nil
+		}
+		return []models.SyncTombstone{{EntityType: models.SyncEntityComment, EntityID: record.ID, TeamID: &task.TeamID, DeletedAt: now}}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"task-calendar-backend/internal/models"
+)
+
+// Discordの埋め込み（embed）の上限
+const (
+	discordEmbedTitleLimit      = 256
+	discordEmbedDescLimit       = 4096
+	discordEmbedFieldValueLimit = 1024
+	discordEmbedColor           = 0x3B82F6
+)
+
+// DiscordWebhookPoster Discord のチャンネルのWebhookに、埋め込み（embed）付きのメッセージを投稿する
+type DiscordWebhookPoster struct {
+	client *http.Client
+}
+
+func NewDiscordWebhookPoster() *DiscordWebhookPoster {
+	return &DiscordWebhookPoster{client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+func (p *DiscordWebhookPoster) Provider() models.ChatProvider {
+	return models.ChatProviderDiscord
+}
+
+func (p *DiscordWebhookPoster) Validate(channel *models.TeamChatChannel) error {
+	if channel.WebhookURL == "" {
+		return fmt.Errorf("%w: webhookUrlは必須です", ErrInvalidInput)
+	}
+	u, err := url.Parse(channel.WebhookURL)
+	if err != nil || u.Scheme != "https" || !strings.HasPrefix(u.Path, "/api/webhooks/") {
+		return fmt.Errorf("%w: webhookUrlはDiscordのWebhookのURL（https://discord.com/api/webhooks/...）で指定してください", ErrInvalidInput)
+	}
+	switch strings.ToLower(u.Hostname()) {
+	case "discord.com", "discordapp.com", "ptb.discord.com", "canary.discord.com":
+	default:
+		return fmt.Errorf("%w: webhookUrlはDiscordのWebhookのURL（https://discord.com/api/webhooks/...）で指定してください", ErrInvalidInput)
+	}
+	return nil
+}
+
+func (p *DiscordWebhookPoster) Post(ctx context.Context, channel *models.TeamChatChannel, msg ChatMessage) error {
+	data, err := json.Marshal(discordWebhookMessage(msg))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, channel.WebhookURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// wait を指定しない場合は204を返す
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("DiscordのWebhookがステータス %d を返しました: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// discordWebhookMessage メッセージをDiscordのWebhookの形式（https://discord.com/developers/docs/resources/webhook）にする
+func discordWebhookMessage(msg ChatMessage) map[string]interface{} {
+	embed := map[string]interface{}{
+		"description": truncateRunes(msg.Text, discordEmbedDescLimit),
+		"color":       discordEmbedColor,
+	}
+	if msg.LinkTitle != "" {
+		embed["title"] = truncateRunes(msg.LinkTitle, discordEmbedTitleLimit)
+	}
+	if msg.LinkURL != "" {
+		embed["url"] = msg.LinkURL
+	}
+	if len(msg.Facts) > 0 {
+		fields := make([]map[string]interface{}, 0, len(msg.Facts))
+		for _, f := range msg.Facts {
+			fields = append(fields, map[string]interface{}{
+				"name":   truncateRunes(f.Title, discordEmbedTitleLimit),
+				"value":  truncateRunes(f.Value, discordEmbedFieldValueLimit),
+				"inline": true,
+			})
+		}
+		embed["fields"] = fields
+	}
+	return map[string]interface{}{
+		"embeds": []map[string]interface{}{embed},
+		// 本文中の @everyone などでメンションしない
+		"allowed_mentions": map[string]interface{}{"parse": []string{}},
+	}
+}
+
+// truncateRunes 文字数が limit を超える場合は末尾を省略する
+func truncateRunes(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// DuplicateEventInput イベントの複製の内容
+//
+// StartDate を指定するとその日時に、指定しない場合は元の日時を ShiftMonths / ShiftDays だけずらした日時に複製する。
+// ずらす場合はイベントのタイムゾーンの壁時計時刻を保つ。
+type DuplicateEventInput struct {
+	Title         *string    `json:"title" binding:"omitempty,notblank,max=200"`
+	StartDate     *time.Time `json:"startDate"`
+	ShiftMonths   int        `json:"shiftMonths"`
+	ShiftDays     int        `json:"shiftDays"`
+	CopyAttendees bool       `json:"copyAttendees"`
+}
+
+// DuplicateResult 複製したイベントと、参加者の予定との重複
+type DuplicateResult struct {
+	Event     *models.Event   `json:"event"`
+	Conflicts []EventConflict `json:"conflicts"`
+}
+
+type DuplicateService struct {
+	db              *gorm.DB
+	attendeeService *AttendeeService
+}
+
+func NewDuplicateService(db *gorm.DB, attendeeService *AttendeeService) *DuplicateService {
+	return &DuplicateService{db: db, attendeeService: attendeeService}
+}
+
+// DuplicateEvent 閲覧できるイベントを自分のイベントとして複製する
+//
+// 会議室の予約・ビデオ会議・回ごとの変更は複製しない。チームのイベントは自分がメンバーの場合のみ
+// 同じチームに複製し、それ以外は個人のイベントにする。参加者を複製する場合は改めて招待する（招待メールも送る）。
+func (s *DuplicateService) DuplicateEvent(eventID, userID string, input DuplicateEventInput) (*DuplicateResult, error) {
+	source, err := findEventForUser(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end, err := duplicateRange(source, input)
+	if err != nil {
+		return nil, err
+	}
+	title := source.Title
+	if input.Title != nil {
+		title = strings.TrimSpace(*input.Title)
+		if title == "" {
+			return nil, fmt.Errorf("%w: titleは必須です", ErrInvalidInput)
+		}
+	}
+
+	event := models.Event{
+		Title:       title,
+		Description: source.Description,
+		StartDate:   start,
+		EndDate:     end,
+		AllDay:      source.AllDay,
+		TimeZone:    source.TimeZone,
+		IsRecurring: source.IsRecurring,
+		Recurrence:  source.Recurrence,
+		Type:        source.Type,
+		Location:    source.Location,
+		Color:       source.Color,
+		Visibility:  source.Visibility,
+		CreatorID:   userID,
+
+		BufferBeforeMinutes: source.BufferBeforeMinutes,
+		BufferAfterMinutes:  source.BufferAfterMinutes,
+	}
+	if source.TeamID != nil && ensureTeamMember(s.db, *source.TeamID, userID) == nil {
+		event.TeamID = source.TeamID
+		event.CategoryID = source.CategoryID
+	}
+	if err := s.db.Create(&event).Error; err != nil {
+		return nil, err
+	}
+
+	result := &DuplicateResult{Event: &event, Conflicts: []EventConflict{}}
+	if input.CopyAttendees {
+		inviteeIDs, err := s.duplicateInvitees(source, userID)
+		if err != nil {
+			s.db.Unscoped().Delete(&event)
+			return nil, err
+		}
+		if len(inviteeIDs) > 0 {
+			invited, err := s.attendeeService.InviteAttendees(event.ID, userID, inviteeIDs)
+			if err != nil {
+				// チームで重複が禁止されている場合などは複製自体を取り消す
+				s.db.Unscoped().Delete(&event)
+				return nil, err
+			}
+			result.Conflicts = invited.Conflicts
+		}
+	}
+
+	if err := s.db.Scopes(PreloadAttendees).Preload("Category").First(&event, "id = ?", event.ID).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// duplicateInvitees 複製先に招待するユーザー（元の参加者と作成者から、複製するユーザー自身を除く）
+func (s *DuplicateService) duplicateInvitees(source *models.Event, userID string) ([]string, error) {
+	var attendeeIDs []string
+	if err := s.db.Model(&models.EventAttendee{}).Where("event_id = ?", source.ID).
+		Order("created_at ASC").Pluck("user_id", &attendeeIDs).Error; err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{userID: true}
+	var ids []string
+	for _, id := range append([]string{source.CreatorID}, attendeeIDs...) {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// duplicateRange 複製先の開始・終了日時（元のイベントの長さを保つ）
+func duplicateRange(source *models.Event, input DuplicateEventInput) (time.Time, time.Time, error) {
+	if input.StartDate != nil && (input.ShiftMonths != 0 || input.ShiftDays != 0) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: startDateとshiftMonths・shiftDaysは同時に指定できません", ErrInvalidInput)
+	}
+
+	if source.AllDay {
+		days := int(source.EndDate.Sub(source.StartDate).Hours()/24 + 0.5)
+		start := source.StartDate.UTC().AddDate(0, input.ShiftMonths, input.ShiftDays)
+		if input.StartDate != nil {
+			start = models.AllDayDate(*input.StartDate)
+		}
+		return start, start.AddDate(0, 0, days), nil
+	}
+
+	duration := source.EndDate.Sub(source.StartDate)
+	start := source.StartDate.In(source.TimeZoneLocation()).AddDate(0, input.ShiftMonths, input.ShiftDays)
+	if input.StartDate != nil {
+		start = *input.StartDate
+	}
+	return start, start.Add(duration), nil
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const replyTokenTTL = 30 * 24 * time.Hour
+
+var (
+	// 返信メールの引用部分の開始を示す行
+	replyQuoteHeaders = []*regexp.Regexp{
+		regexp.MustCompile(`^On .+wrote:\s*$`),
+		regexp.MustCompile(`^.+に書きました[:：]\s*$`),
+		regexp.MustCompile(`^-+\s*Original Message\s*-+$`),
+		regexp.MustCompile(`^From: `),
+	}
+)
+
+// ReplyAddressService 通知メールの返信先アドレスを発行する
+type ReplyAddressService struct {
+	db     *gorm.DB
+	domain string
+}
+
+func NewReplyAddressService(db *gorm.DB, domain string) *ReplyAddressService {
+	return &ReplyAddressService{db: db, domain: domain}
+}
+
+// Enabled 返信先ドメインが設定されているか
+func (s *ReplyAddressService) Enabled() bool {
+	return s != nil && s.domain != ""
+}
+
+// IssueReplyAddress ユーザーとタスクに紐づく返信先アドレス（reply+<token>@domain）を発行する
+func (s *ReplyAddressService) IssueReplyAddress(userID, taskID string) (string, error) {
+	if !s.Enabled() {
+		return "", nil
+	}
+
+	value, err := newReplyToken()
+	if err != nil {
+		return "", err
+	}
+	token := models.EmailReplyToken{
+		Token:     value,
+		UserID:    userID,
+		TaskID:    taskID,
+		ExpiresAt: time.Now().Add(replyTokenTTL),
+	}
+	if err := s.db.Create(&token).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("reply+%s@%s", token.Token, s.domain), nil
+}
+
+// IssueRSVPAddress 招待メールの出欠回答の送信先アドレス（rsvp+<token>@domain）を発行する
+//
+// 有効期限はイベントの終了日時（繰り返しイベントは発行から1年）まで。
+func (s *ReplyAddressService) IssueRSVPAddress(userID string, event *models.Event) (string, error) {
+	if !s.Enabled() {
+		return "", nil
+	}
+
+	value, err := newReplyToken()
+	if err != nil {
+		return "", err
+	}
+	expiresAt := event.EndDate
+	if event.IsRecurring {
+		expiresAt = time.Now().AddDate(1, 0, 0)
+	}
+	token := models.EventReplyToken{
+		Token:     value,
+		UserID:    userID,
+		EventID:   event.ID,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.db.Create(&token).Error; err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("rsvp+%s@%s", token.Token, s.domain), nil
+}
+
+// IssuedRSVPAddress 最後に発行した出欠回答の送信先アドレス（未発行の場合は空）
+func (s *ReplyAddressService) IssuedRSVPAddress(userID, eventID string) string {
+	if !s.Enabled() {
+		return ""
+	}
+	var token models.EventReplyToken
+	if err := s.db.Where("event_id = ? AND user_id = ?", eventID, userID).Order("created_at DESC").
+		First(&token).Error; err != nil {
+		return ""
+	}
+	return fmt.Sprintf("rsvp+%s@%s", token.Token, s.domain)
+}
+
+func newReplyToken() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// InboundEmail 受信したメールの内容
+type InboundEmail struct {
+	To        string
+	From      string
+	Text      string
+	Calendars [][]byte // 添付されたiCalendar（text/calendar）
+}
+
+// InboundEmailService 受信メールをコメント・出欠回答に変換する
+type InboundEmailService struct {
+	db              *gorm.DB
+	commentService  *CommentService
+	attendeeService *AttendeeService
+}
+
+func NewInboundEmailService(db *gorm.DB, commentService *CommentService, attendeeService *AttendeeService) *InboundEmailService {
+	return &InboundEmailService{db: db, commentService: commentService, attendeeService: attendeeService}
+}
+
+// IsRSVP 招待メールへの出欠回答（rsvp+<token>@ 宛て）か
+func (email InboundEmail) IsRSVP() bool {
+	return extractAddressToken(email.To, "rsvp+") != ""
+}
+
+// ProcessReply 返信メールを該当タスクへのコメントとして投稿する
+func (s *InboundEmailService) ProcessReply(email InboundEmail) (*models.Comment, error) {
+	tokenValue := extractAddressToken(email.To, "reply+")
+	if tokenValue == "" {
+		return nil, fmt.Errorf("%w: 返信先アドレスが不正です", ErrInvalidInput)
+	}
+
+	var token models.EmailReplyToken
+	if err := s.db.First(&token, "token = ?", tokenValue).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return nil, fmt.Errorf("%w: 返信の有効期限が切れています", ErrInvalidInput)
+	}
+
+	if err := s.ensureSender(email, token.UserID); err != nil {
+		return nil, err
+	}
+
+	content := stripEmailReply(email.Text)
+	if content == "" {
+		return nil, fmt.Errorf("%w: 本文が空です", ErrInvalidInput)
+	}
+	return s.commentService.CreateComment(token.TaskID, token.UserID, content)
+}
+
+// ensureSender 送信元がトークンの持ち主であることを確認する
+func (s *InboundEmailService) ensureSender(email InboundEmail, userID string) error {
+	from, err := mail.ParseAddress(email.From)
+	if err != nil {
+		return fmt.Errorf("%w: 送信元アドレスが不正です", ErrInvalidInput)
+	}
+	var user models.User
+	if err := s.db.Select("id", "email").First(&user, "id = ?", userID).Error; err != nil {
+		return err
+	}
+	if !strings.EqualFold(user.Email, from.Address) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// extractAddressToken 宛先（複数可）から <prefix><token>@ のトークンを取り出す
+func extractAddressToken(to, prefix string) string {
+	addresses, err := mail.ParseAddressList(to)
+	if err != nil {
+		addresses = []*mail.Address{{Address: strings.TrimSpace(to)}}
+	}
+	for _, addr := range addresses {
+		local, _, ok := strings.Cut(strings.ToLower(addr.Address), "@")
+		if !ok {
+			continue
+		}
+		if token, found := strings.CutPrefix(local, prefix); found && token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// stripEmailReply 返信メールから引用部分と署名を取り除く
+func stripEmailReply(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+
+	var kept []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "--" || strings.HasPrefix(trimmed, ">") {
+			break
+		}
+		quoted := false
+		for _, re := range replyQuoteHeaders {
+			if re.MatchString(trimmed) {
+				quoted = true
+				break
+			}
+		}
+		if quoted {
+			break
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
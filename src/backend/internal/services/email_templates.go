@@ -0,0 +1,84 @@
+package services
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+// メールのテンプレート
+//
+// 種別ごとに <種別>.txt.tmpl（"subject" と "text" を定義）と、あれば <種別>.html.tmpl（"content" を定義）を置く。
+// HTML版は layout.html.tmpl の中に埋め込んで送る。
+//
+//go:embed templates/email
+var emailTemplateFS embed.FS
+
+// メールの種別
+const (
+	EmailTemplateInvitation    = "invitation"
+	EmailTemplateCancellation  = "cancellation"
+	EmailTemplateReminder      = "reminder"
+	EmailTemplateNotification  = "notification"
+	EmailTemplatePasswordReset = "password_reset"
+	EmailTemplateDailyAgenda   = "daily_agenda"
+	EmailTemplateWeeklyDigest  = "weekly_digest"
+)
+
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template // HTML版がない場合はnil
+}
+
+var emailTemplates = loadEmailTemplates()
+
+func loadEmailTemplates() map[string]emailTemplate {
+	const dir = "templates/email"
+	files, err := fs.Glob(emailTemplateFS, dir+"/*.txt.tmpl")
+	if err != nil {
+		panic(err)
+	}
+	templates := make(map[string]emailTemplate, len(files))
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".txt.tmpl")
+		t := emailTemplate{
+			text: texttemplate.Must(texttemplate.ParseFS(emailTemplateFS, file)),
+		}
+		htmlFile := path.Join(dir, name+".html.tmpl")
+		if _, err := fs.Stat(emailTemplateFS, htmlFile); err == nil {
+			t.html = htmltemplate.Must(htmltemplate.ParseFS(emailTemplateFS, path.Join(dir, "layout.html.tmpl"), htmlFile))
+		}
+		templates[name] = t
+	}
+	return templates
+}
+
+// renderEmail 種別のテンプレートからメールの件名と本文を作る（宛先は呼び出し元で設定する）
+func renderEmail(name string, data interface{}) (EmailMessage, error) {
+	t, ok := emailTemplates[name]
+	if !ok {
+		return EmailMessage{}, fmt.Errorf("メールのテンプレート %s がありません", name)
+	}
+
+	var subject, text bytes.Buffer
+	if err := t.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return EmailMessage{}, err
+	}
+	if err := t.text.ExecuteTemplate(&text, "text", data); err != nil {
+		return EmailMessage{}, err
+	}
+	msg := EmailMessage{Subject: strings.TrimSpace(subject.String()), Text: text.String()}
+	if t.html != nil {
+		var html bytes.Buffer
+		if err := t.html.ExecuteTemplate(&html, "layout", data); err != nil {
+			return EmailMessage{}, err
+		}
+		msg.HTML = html.String()
+	}
+	return msg, nil
+}
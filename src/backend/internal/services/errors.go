@@ -0,0 +1,13 @@
+package services
+
+import "errors"
+
+// サービス層で共通して使うエラー
+var (
+	ErrNotFound     = errors.New("リソースが見つかりません")
+	ErrForbidden    = errors.New("この操作を行う権限がありません")
+	ErrInvalidInput = errors.New("入力内容が正しくありません")
+
+	ErrPreconditionFailed = errors.New("リソースが他の操作で更新されています")
+	ErrConflict           = errors.New("他の予定と重複しています")
+)
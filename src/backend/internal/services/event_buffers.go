@@ -0,0 +1,55 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"task-calendar-backend/internal/models"
+)
+
+// 前後の移動時間の上限（分）
+const maxBufferMinutes = 12 * 60
+
+// EventBuffersInput イベントの前後の移動時間の設定
+type EventBuffersInput struct {
+	BeforeMinutes int `json:"beforeMinutes" binding:"min=0,max=720"`
+	AfterMinutes  int `json:"afterMinutes" binding:"min=0,max=720"`
+}
+
+// SetEventBuffers イベントの開始前・終了後の移動時間を設定する
+//
+// 移動時間は空き状況と予定の重複の判定で予定ありとして扱うが、イベントとしては表示しない。
+func (s *EventService) SetEventBuffers(eventID, userID string, input EventBuffersInput) (*models.Event, error) {
+	event, err := findEditableEvent(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if event.AllDay {
+		return nil, fmt.Errorf("%w: 終日イベントには移動時間を設定できません", ErrInvalidInput)
+	}
+	if err := validateBuffers(input.BeforeMinutes, input.AfterMinutes); err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(event).Updates(map[string]interface{}{
+		"buffer_before_minutes": input.BeforeMinutes,
+		"buffer_after_minutes":  input.AfterMinutes,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func validateBuffers(before, after int) error {
+	if before < 0 || before > maxBufferMinutes || after < 0 || after > maxBufferMinutes {
+		return fmt.Errorf("%w: 移動時間は0〜%d分で指定してください", ErrInvalidInput, maxBufferMinutes)
+	}
+	return nil
+}
+
+// eventBuffers イベントの開始前・終了後の移動時間（終日イベントにはない）
+func eventBuffers(event *models.Event) (time.Duration, time.Duration) {
+	if event.AllDay {
+		return 0, 0
+	}
+	return time.Duration(event.BufferBeforeMinutes) * time.Minute, time.Duration(event.BufferAfterMinutes) * time.Minute
+}
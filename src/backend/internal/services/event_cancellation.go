@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+)
+
+// 通知種別
+const (
+	NotificationTypeEventCancelled = "EVENT_CANCELLED"
+)
+
+// CancelEventInput イベントのキャンセル
+type CancelEventInput struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// CancelEvent イベントをキャンセルする
+//
+// 削除せずにキャンセル済み（CANCELLED）として残し、一覧には表示したまま予定の重複・空き状況・
+// 会議室の予約・リマインダーの対象から外す。辞退していない参加者（と作成者）に通知し、キャンセルメールも送る。
+func (s *AttendeeService) CancelEvent(eventID, userID string, input CancelEventInput) (*models.Event, error) {
+	event, err := findEditableEvent(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == models.EventStatusCancelled {
+		return nil, fmt.Errorf("%w: このイベントは既にキャンセルされています", ErrInvalidInput)
+	}
+
+	now := time.Now()
+	if err := s.db.Model(event).Updates(map[string]interface{}{
+		"status":              models.EventStatusCancelled,
+		"cancelled_at":        &now,
+		"cancellation_reason": strings.TrimSpace(input.Reason),
+		"sequence":            event.Sequence + 1,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	var attendeeIDs []string
+	if err := s.db.Model(&models.EventAttendee{}).
+		Where("event_id = ? AND status <> ?", event.ID, models.AttendeeStatusDeclined).
+		Order("created_at ASC").Pluck("user_id", &attendeeIDs).Error; err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{userID: true}
+	var notified []string
+	for _, id := range append([]string{event.CreatorID}, attendeeIDs...) {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		notified = append(notified, id)
+		body := fmt.Sprintf("%s（%s）", event.Title, event.StartDate.Format("2006-01-02 15:04"))
+		if event.CancellationReason != "" {
+			body += "\n理由: " + event.CancellationReason
+		}
+		if err := s.notifier.Notify(NotificationMessage{
+			UserID:     id,
+			Type:       NotificationTypeEventCancelled,
+			Title:      "イベントがキャンセルされました",
+			Body:       body,
+			EntityType: "event",
+			EntityID:   event.ID,
+		}); err != nil {
+			log.Printf("キャンセル通知の送信に失敗しました: %v", err)
+		}
+	}
+	s.invitations.SendCancellations(event, notified)
+
+	if err := s.db.Scopes(PreloadAttendees).First(event, "id = ?", event.ID).Error; err != nil {
+		return nil, err
+	}
+	return event, nil
+}
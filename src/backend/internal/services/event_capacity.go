@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 通知種別
+const (
+	NotificationTypeEventWaitlistPromoted = "EVENT_WAITLIST_PROMOTED"
+)
+
+// SetCapacityInput イベントの定員（nullで定員なし）
+type SetCapacityInput struct {
+	Capacity *int `json:"capacity" binding:"omitempty,min=1"`
+}
+
+// SetCapacity イベントの定員を設定する
+//
+// 出席者数より少ない定員も設定できる（既に出席の人はそのまま、以降の出席の回答がキャンセル待ちになる）。
+// 定員を増やす・なくすと、空いた分だけキャンセル待ちの人を繰り上げて通知する。
+func (s *AttendeeService) SetCapacity(eventID, userID string, input SetCapacityInput) (*models.Event, error) {
+	event, err := findEditableEvent(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Capacity != nil && *input.Capacity < 1 {
+		return nil, fmt.Errorf("%w: capacityは1以上で指定してください", ErrInvalidInput)
+	}
+
+	var promoted []models.EventAttendee
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockEventAttendees(tx, event.ID); err != nil {
+			return err
+		}
+		if err := tx.Model(event).Update("capacity", input.Capacity).Error; err != nil {
+			return err
+		}
+		event.Capacity = input.Capacity
+		promoted, err = promoteWaitlist(tx, event)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	s.notifyPromoted(event, promoted)
+
+	if err := s.db.Scopes(PreloadAttendees).First(event, "id = ?", event.ID).Error; err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// lockEventAttendees 同じイベントの出欠の変更を直列化する（定員の判定と繰り上げのため）
+func lockEventAttendees(tx *gorm.DB, eventID string) error {
+	var event models.Event
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&event, "id = ?", eventID).Error
+}
+
+// acceptedAttendeeCount 定員の対象となる出席者数（作成者を除く）
+func acceptedAttendeeCount(tx *gorm.DB, event *models.Event) (int, error) {
+	var count int64
+	if err := tx.Model(&models.EventAttendee{}).
+		Where("event_id = ? AND user_id <> ? AND status = ?", event.ID, event.CreatorID, models.AttendeeStatusAccepted).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// eventIsFull 出席者が定員に達しているか（定員なしは常にfalse）
+func eventIsFull(tx *gorm.DB, event *models.Event) (bool, error) {
+	if event.Capacity == nil {
+		return false, nil
+	}
+	accepted, err := acceptedAttendeeCount(tx, event)
+	if err != nil {
+		return false, err
+	}
+	return accepted >= *event.Capacity, nil
+}
+
+// promoteWaitlist 定員の空きの分だけ、キャンセル待ちの人をキャンセル待ちになった順に出席に繰り上げる
+func promoteWaitlist(tx *gorm.DB, event *models.Event) ([]models.EventAttendee, error) {
+	query := tx.Where("event_id = ? AND status = ?", event.ID, models.AttendeeStatusWaitlisted).
+		Order("waitlisted_at ASC, created_at ASC")
+	if event.Capacity != nil {
+		accepted, err := acceptedAttendeeCount(tx, event)
+		if err != nil {
+			return nil, err
+		}
+		free := *event.Capacity - accepted
+		if free <= 0 {
+			return nil, nil
+		}
+		query = query.Limit(free)
+	}
+
+	var promoted []models.EventAttendee
+	if err := query.Find(&promoted).Error; err != nil {
+		return nil, err
+	}
+	if len(promoted) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(promoted))
+	for i := range promoted {
+		ids[i] = promoted[i].ID
+		promoted[i].Status = models.AttendeeStatusAccepted
+		promoted[i].WaitlistedAt = nil
+	}
+	if err := tx.Model(&models.EventAttendee{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"status":        models.AttendeeStatusAccepted,
+		"waitlisted_at": nil,
+		"updated_at":    time.Now(),
+	}).Error; err != nil {
+		return nil, err
+	}
+	return promoted, nil
+}
+
+// notifyPromoted 繰り上げで出席になった人に通知する
+func (s *AttendeeService) notifyPromoted(event *models.Event, promoted []models.EventAttendee) {
+	for _, a := range promoted {
+		if err := s.notifier.Notify(NotificationMessage{
+			UserID:     a.UserID,
+			Type:       NotificationTypeEventWaitlistPromoted,
+			Title:      "キャンセル待ちから出席に繰り上がりました",
+			Body:       fmt.Sprintf("%s（%s）", event.Title, event.StartDate.Format("2006-01-02 15:04")),
+			EntityType: "event",
+			EntityID:   event.ID,
+		}); err != nil {
+			log.Printf("繰り上げ通知の送信に失敗しました: %v", err)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 繰り返しイベントの重複を確認する期間（開始日時から）
+const conflictHorizon = 90 * 24 * time.Hour
+
+// 一度に返す重複の上限
+const maxConflicts = 100
+
+// EventConflict 参加者の既存の予定との重複
+type EventConflict struct {
+	UserID          string    `json:"userId"`
+	EventID         string    `json:"eventId,omitempty"` // 閲覧権限のない予定の場合は省略
+	Title           string    `json:"title,omitempty"`
+	StartDate       time.Time `json:"startDate"` // 重複している既存の予定の日時
+	EndDate         time.Time `json:"endDate"`
+	OccurrenceStart time.Time `json:"occurrenceStart"` // 重複している対象イベントの回の開始日時
+}
+
+// ConflictError チームの設定で重複が禁止されている場合のエラー
+type ConflictError struct {
+	Conflicts []EventConflict
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("参加者の予定と%d件重複しています", len(e.Conflicts))
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// ConflictCheckInput 保存前のイベントの重複確認
+type ConflictCheckInput struct {
+	EventID     string    `json:"eventId"` // 更新の場合は対象イベント（自身との重複は除く）
+	StartDate   time.Time `json:"startDate" binding:"required"`
+	EndDate     time.Time `json:"endDate" binding:"required,gtefield=StartDate"`
+	AllDay      bool      `json:"allDay"`
+	IsRecurring bool      `json:"isRecurring"`
+	Recurrence  string    `json:"recurrence"`
+	TimeZone    string    `json:"timeZone" binding:"omitempty,timezone"`
+	AttendeeIDs []string  `json:"attendeeIds"`
+
+	BufferBeforeMinutes int `json:"bufferBeforeMinutes" binding:"min=0,max=720"`
+	BufferAfterMinutes  int `json:"bufferAfterMinutes" binding:"min=0,max=720"`
+}
+
+// TeamEventSettingsInput チームのイベント設定の更新（指定した項目のみ更新する）
+type TeamEventSettingsInput struct {
+	BlockEventConflicts *bool `json:"blockEventConflicts"`
+	// ConferenceProvider MEETINGイベントに自動で作成するビデオ会議（ZOOM / GOOGLE_MEET、空文字で無効）
+	ConferenceProvider *string `json:"conferenceProvider" binding:"omitempty,oneof=ZOOM GOOGLE_MEET"`
+	// HolidayCountry チームのカレンダーに表示する祝日の国（ISO 3166-1 alpha-2、空文字で表示しない）
+	HolidayCountry *string `json:"holidayCountry"`
+}
+
+// CheckConflicts 作成・更新前のイベントが作成者と参加者の予定と重複していないか確認する
+func (s *EventService) CheckConflicts(userID string, input ConflictCheckInput) ([]EventConflict, error) {
+	event := models.Event{
+		StartDate:   input.StartDate,
+		EndDate:     input.EndDate,
+		AllDay:      input.AllDay,
+		IsRecurring: input.IsRecurring,
+		Recurrence:  input.Recurrence,
+		TimeZone:    input.TimeZone,
+		CreatorID:   userID,
+
+		BufferBeforeMinutes: input.BufferBeforeMinutes,
+		BufferAfterMinutes:  input.BufferAfterMinutes,
+	}
+	if err := validateBuffers(event.BufferBeforeMinutes, event.BufferAfterMinutes); err != nil {
+		return nil, err
+	}
+	if input.EventID != "" {
+		existing, err := findEventForUser(s.db, input.EventID, userID)
+		if err != nil {
+			return nil, err
+		}
+		event.ID = existing.ID
+		event.CreatorID = existing.CreatorID
+	}
+	if !event.EndDate.After(event.StartDate) && !event.AllDay {
+		return nil, fmt.Errorf("%w: endDateはstartDateより後の日時を指定してください", ErrInvalidInput)
+	}
+	if _, err := models.LoadLocation(event.TimeZone); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if event.AllDay {
+		event.StartDate, event.EndDate = models.NormalizeAllDayRange(event.StartDate, event.EndDate)
+	}
+
+	var exceptions []models.EventException
+	if event.ID != "" && event.IsRecurring {
+		if err := s.db.Where("event_id = ?", event.ID).Find(&exceptions).Error; err != nil {
+			return nil, err
+		}
+	}
+	return findConflicts(s.db, &event, exceptions, append([]string{event.CreatorID}, input.AttendeeIDs...), userID)
+}
+
+// GetEventConflicts 保存済みのイベントと作成者・参加者（辞退・キャンセル待ちの人を除く）の予定との重複
+func (s *EventService) GetEventConflicts(eventID, userID string) ([]EventConflict, error) {
+	event, err := findEventForUser(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var attendeeIDs []string
+	if err := s.db.Model(&models.EventAttendee{}).
+		Where("event_id = ? AND status NOT IN ?", event.ID, models.InactiveAttendeeStatuses).
+		Pluck("user_id", &attendeeIDs).Error; err != nil {
+		return nil, err
+	}
+	conflicts, err := eventConflicts(s.db, event, append([]string{event.CreatorID}, attendeeIDs...), userID)
+	var conflictErr *ConflictError
+	if errors.As(err, &conflictErr) {
+		// 確認のみのため、重複が禁止されていてもエラーにしない
+		return conflicts, nil
+	}
+	return conflicts, err
+}
+
+// UpdateTeamEventSettings チームのイベント設定を更新する（チーム管理者のみ）
+func (s *EventService) UpdateTeamEventSettings(teamID, userID string, input TeamEventSettingsInput) (*models.Team, error) {
+	admin, err := isTeamAdmin(s.db, teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin {
+		return nil, ErrForbidden
+	}
+
+	updates := make(map[string]interface{})
+	if input.BlockEventConflicts != nil {
+		updates["block_event_conflicts"] = *input.BlockEventConflicts
+	}
+	if input.ConferenceProvider != nil {
+		provider := models.ConferenceProvider(strings.ToUpper(strings.TrimSpace(*input.ConferenceProvider)))
+		switch provider {
+		case "", models.ConferenceProviderZoom, models.ConferenceProviderGoogleMeet:
+		default:
+			return nil, fmt.Errorf("%w: conferenceProviderはZOOMまたはGOOGLE_MEETで指定してください", ErrInvalidInput)
+		}
+		updates["conference_provider"] = provider
+	}
+	if input.HolidayCountry != nil {
+		country, err := normalizeHolidayCountry(*input.HolidayCountry)
+		if err != nil {
+			return nil, err
+		}
+		updates["holiday_country"] = country
+	}
+
+	var team models.Team
+	if err := s.db.First(&team, "id = ?", teamID).Error; err != nil {
+		return nil, err
+	}
+	if len(updates) > 0 {
+		if err := s.db.Model(&team).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &team, nil
+}
+
+// eventConflicts 保存済みのイベントと指定ユーザーの予定との重複を求める
+//
+// チームで重複が禁止されている場合は、重複があれば *ConflictError を返す。
+func eventConflicts(db *gorm.DB, event *models.Event, userIDs []string, viewerID string) ([]EventConflict, error) {
+	var exceptions []models.EventException
+	if event.IsRecurring {
+		if err := db.Where("event_id = ?", event.ID).Find(&exceptions).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	conflicts, err := findConflicts(db, event, exceptions, userIDs, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 && event.TeamID != nil {
+		var team models.Team
+		if err := db.Select("id", "block_event_conflicts").First(&team, "id = ?", *event.TeamID).Error; err != nil {
+			return nil, err
+		}
+		if team.BlockEventConflicts {
+			return conflicts, &ConflictError{Conflicts: conflicts}
+		}
+	}
+	return conflicts, nil
+}
+
+// findConflicts イベントの各回とユーザーの既存の予定（作成した、または辞退・キャンセル待ちでない招待）の重なりを求める
+//
+// 終日イベントは予定の有無を表すものとして扱い、重複の対象にしない（終日の不在は作成者の予定として重複の対象にする）。
+// 前後の移動時間も予定に含めて判定する（返す日時は既存の予定自体の日時）。
+func findConflicts(db *gorm.DB, event *models.Event, exceptions []models.EventException, userIDs []string, viewerID string) ([]EventConflict, error) {
+	userIDs = uniqueStrings(userIDs)
+	if event.AllDay || len(userIDs) == 0 {
+		return []EventConflict{}, nil
+	}
+
+	from := event.StartDate
+	to := from.Add(conflictHorizon)
+	if event.EndDate.After(to) {
+		to = event.EndDate
+	}
+	occurrences, err := expandBusyEvent(event, exceptions, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(occurrences) == 0 {
+		return []EventConflict{}, nil
+	}
+	rangeStart, rangeEnd := occurrences[0].StartDate, occurrences[0].EndDate
+	for _, occ := range occurrences {
+		if occ.StartDate.Before(rangeStart) {
+			rangeStart = occ.StartDate
+		}
+		if occ.EndDate.After(rangeEnd) {
+			rangeEnd = occ.EndDate
+		}
+	}
+
+	others, participants, err := participantEvents(db, userIDs, rangeStart, rangeEnd, event.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(others) == 0 {
+		return []EventConflict{}, nil
+	}
+	canView, err := viewableEventIDs(db, others, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptionsByEvent, err := loadEventExceptions(db, others)
+	if err != nil {
+		return nil, err
+	}
+
+	before, _ := eventBuffers(event)
+	conflicts := []EventConflict{}
+	for i := range others {
+		otherBefore, otherAfter := eventBuffers(&others[i])
+		expanded, err := expandBusyEvent(&others[i], exceptionsByEvent[others[i].ID], rangeStart, rangeEnd)
+		if err != nil {
+			// ルールが壊れた予定は重複判定に使わない
+			continue
+		}
+		for _, other := range expanded {
+			for _, occ := range occurrences {
+				if !other.StartDate.Before(occ.EndDate) || !other.EndDate.After(occ.StartDate) {
+					continue
+				}
+				for _, participant := range participants[others[i].ID] {
+					if others[i].Type == models.EventTypeOutOfOffice && participant.UserID != others[i].CreatorID {
+						continue
+					}
+					conflict := EventConflict{
+						UserID:          participant.UserID,
+						StartDate:       other.StartDate.Add(otherBefore),
+						EndDate:         other.EndDate.Add(-otherAfter),
+						OccurrenceStart: occ.StartDate.Add(before),
+					}
+					if canView[others[i].ID] {
+						conflict.EventID = others[i].ID
+						conflict.Title = other.Title
+					}
+					conflicts = append(conflicts, conflict)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		return conflicts[i].OccurrenceStart.Before(conflicts[j].OccurrenceStart)
+	})
+	if len(conflicts) > maxConflicts {
+		conflicts = conflicts[:maxConflicts]
+	}
+	return conflicts, nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
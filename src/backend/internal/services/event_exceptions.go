@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/recurrence"
+
+	"gorm.io/gorm"
+)
+
+// OccurrenceOverrideInput 繰り返しイベントの特定の回の変更内容
+type OccurrenceOverrideInput struct {
+	Title       *string    `json:"title" binding:"omitempty,notblank,max=200"`
+	Description *string    `json:"description"`
+	StartDate   *time.Time `json:"startDate"`
+	EndDate     *time.Time `json:"endDate"`
+}
+
+// OverrideOccurrence 繰り返しイベントの特定の回だけ内容を変更する
+func (s *EventService) OverrideOccurrence(eventID, userID string, recurrenceID time.Time, input OccurrenceOverrideInput) (*models.EventException, error) {
+	event, err := s.findRecurringEventForEdit(eventID, userID, recurrenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := recurrenceID
+	if input.StartDate != nil {
+		start = *input.StartDate
+	}
+	end := recurrenceID.Add(event.EndDate.Sub(event.StartDate))
+	if input.EndDate != nil {
+		end = *input.EndDate
+	}
+	if event.AllDay {
+		start, end = models.NormalizeAllDayRange(start, end)
+		if input.StartDate != nil {
+			input.StartDate = &start
+		}
+		if input.EndDate != nil {
+			input.EndDate = &end
+		}
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("%w: 終了日時は開始日時より後にしてください", ErrInvalidInput)
+	}
+	if event.RoomID != nil && (input.StartDate != nil || input.EndDate != nil) {
+		var room models.Room
+		if err := s.db.First(&room, "id = ?", *event.RoomID).Error; err != nil {
+			return nil, err
+		}
+		moved := []EventOccurrence{{StartDate: start, EndDate: end, AllDay: event.AllDay}}
+		if err := ensureRoomAvailable(s.db, &room, event.ID, moved); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.upsertException(event.ID, recurrenceID, func(ex *models.EventException) {
+		ex.IsCancelled = false
+		if input.Title != nil {
+			ex.Title = input.Title
+		}
+		if input.Description != nil {
+			ex.Description = input.Description
+		}
+		if input.StartDate != nil {
+			ex.StartDate = input.StartDate
+		}
+		if input.EndDate != nil {
+			ex.EndDate = input.EndDate
+		}
+	})
+}
+
+// CancelOccurrence 繰り返しイベントの特定の回だけキャンセルする（EXDATE）
+func (s *EventService) CancelOccurrence(eventID, userID string, recurrenceID time.Time) (*models.EventException, error) {
+	event, err := s.findRecurringEventForEdit(eventID, userID, recurrenceID)
+	if err != nil {
+		return nil, err
+	}
+	return s.upsertException(event.ID, recurrenceID, func(ex *models.EventException) {
+		ex.IsCancelled = true
+	})
+}
+
+// ListExceptions 繰り返しイベントの例外一覧を取得
+func (s *EventService) ListExceptions(eventID, userID string) ([]models.EventException, error) {
+	if _, err := findEventForUser(s.db, eventID, userID); err != nil {
+		return nil, err
+	}
+
+	var exceptions []models.EventException
+	if err := s.db.Where("event_id = ?", eventID).Order("recurrence_id ASC").Find(&exceptions).Error; err != nil {
+		return nil, err
+	}
+	return exceptions, nil
+}
+
+// DeleteException 例外を削除して、その回をシリーズ通りに戻す
+func (s *EventService) DeleteException(eventID, exceptionID, userID string) error {
+	if _, err := findEditableEvent(s.db, eventID, userID); err != nil {
+		return err
+	}
+
+	result := s.db.Where("id = ? AND event_id = ?", exceptionID, eventID).Delete(&models.EventException{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *EventService) upsertException(eventID string, recurrenceID time.Time, apply func(*models.EventException)) (*models.EventException, error) {
+	var ex models.EventException
+	err := s.db.Where("event_id = ? AND recurrence_id = ?", eventID, recurrenceID).First(&ex).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ex = models.EventException{EventID: eventID, RecurrenceID: recurrenceID}
+	}
+
+	apply(&ex)
+	if err := s.db.Save(&ex).Error; err != nil {
+		return nil, err
+	}
+	return &ex, nil
+}
+
+// findRecurringEventForEdit 編集権限を確認し、recurrenceIDがシリーズの実在する回か検証する
+func (s *EventService) findRecurringEventForEdit(eventID, userID string, recurrenceID time.Time) (*models.Event, error) {
+	event, err := findEditableEvent(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !event.IsRecurring || event.Recurrence == "" {
+		return nil, fmt.Errorf("%w: 繰り返しイベントではありません", ErrInvalidInput)
+	}
+
+	rule, err := recurrence.Parse(event.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if !isRuleOccurrence(rule, eventDTStart(event), recurrenceID) {
+		return nil, fmt.Errorf("%w: 指定された日時はこのイベントの発生日時ではありません", ErrInvalidInput)
+	}
+	return event, nil
+}
+
+// isRuleOccurrence 日時がルール上の発生日時の一つか判定する
+func isRuleOccurrence(rule *recurrence.Rule, dtstart, t time.Time) bool {
+	found := false
+	rule.Iterate(dtstart, func(occ time.Time) bool {
+		if occ.Equal(t) {
+			found = true
+		}
+		return occ.Before(t)
+	})
+	return found
+}
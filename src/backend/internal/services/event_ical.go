@@ -0,0 +1,235 @@
+package services
+
+import (
+	"bytes"
+	"time"
+
+	"task-calendar-backend/internal/ical"
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/recurrence"
+)
+
+const icalProductID = "-//TaskCalendar//TaskCalendar//JA"
+
+// ExportOptions iCalendarエクスポートの対象と出力タイムゾーン
+type ExportOptions struct {
+	TeamID   *string        // 指定時はチームのイベントのみ
+	Location *time.Location // nilまたはUTCの場合はUTC（Z付き）で出力
+}
+
+// ExportICS 閲覧できるイベントをiCalendar（RFC 5545）形式で出力する
+func (s *EventService) ExportICS(userID string, opts ExportOptions) ([]byte, error) {
+	query := s.db.Preload("Room").Order("start_date ASC")
+	calendarName := "TaskCalendar"
+	if opts.TeamID != nil {
+		if err := ensureTeamMember(s.db, *opts.TeamID, userID); err != nil {
+			return nil, err
+		}
+		var team models.Team
+		if err := s.db.First(&team, "id = ?", *opts.TeamID).Error; err != nil {
+			return nil, err
+		}
+		calendarName = team.Name
+		query = query.Where("team_id = ?", *opts.TeamID).Scopes(hidePrivateEventsScope(userID))
+	} else {
+		query = query.Scopes(visibleEventsScope(userID))
+	}
+
+	var events []models.Event
+	if err := query.Find(&events).Error; err != nil {
+		return nil, err
+	}
+	exceptions, err := s.loadExceptions(events)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, buildCalendar(calendarName, events, exceptions, opts.Location)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func buildCalendar(name string, events []models.Event, exceptions map[string][]models.EventException, loc *time.Location) *ical.Component {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	cal := ical.NewComponent("VCALENDAR")
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", icalProductID)
+	cal.Add("CALSCALE", "GREGORIAN")
+	cal.Add("METHOD", "PUBLISH")
+	cal.AddText("X-WR-CALNAME", name)
+	if loc != time.UTC {
+		cal.Add("X-WR-TIMEZONE", loc.String())
+	}
+
+	for _, vtimezone := range timezoneComponents(events, loc) {
+		cal.AddComponent(vtimezone)
+	}
+
+	stamp := ical.FormatUTC(time.Now())
+	for i := range events {
+		for _, vevent := range eventComponents(&events[i], exceptions[events[i].ID], loc, stamp) {
+			cal.AddComponent(vevent)
+		}
+	}
+	return cal
+}
+
+// timezoneComponents 出力に使うタイムゾーンごとのVTIMEZONEを生成する
+func timezoneComponents(events []models.Event, loc *time.Location) []*ical.Component {
+	if len(events) == 0 {
+		return nil
+	}
+	// 繰り返しイベントは終わりがないことがあるため、少なくとも5年先までの遷移を含める
+	from := events[0].StartDate
+	to := time.Now().AddDate(5, 0, 0)
+	var locations []*time.Location
+	seen := make(map[string]bool)
+	for i := range events {
+		if events[i].StartDate.Before(from) {
+			from = events[i].StartDate
+		}
+		if events[i].EndDate.After(to) {
+			to = events[i].EndDate
+		}
+		l := exportLocation(&events[i], loc)
+		if l != time.UTC && !seen[l.String()] {
+			seen[l.String()] = true
+			locations = append(locations, l)
+		}
+	}
+
+	components := make([]*ical.Component, 0, len(locations))
+	for _, l := range locations {
+		components = append(components, ical.VTimezone(l, from, to))
+	}
+	return components
+}
+
+// exportLocation イベントの日時を出力するタイムゾーン
+//
+// 時刻付きの繰り返しイベントは、クライアントが夏時間を正しく展開できるよう作成時のタイムゾーンで出力する。
+func exportLocation(event *models.Event, loc *time.Location) *time.Location {
+	if event.IsRecurring && !event.AllDay && event.TimeZone != "" {
+		return event.TimeZoneLocation()
+	}
+	return loc
+}
+
+// eventComponents イベント本体と、上書きされた回ごとのVEVENT（RECURRENCE-ID付き）を生成する
+func eventComponents(event *models.Event, exceptions []models.EventException, loc *time.Location, stamp string) []*ical.Component {
+	uid := eventUID(event)
+	loc = exportLocation(event, loc)
+	addTime := func(c *ical.Component, name string, t time.Time) {
+		if event.AllDay {
+			// 終日イベントはタイムゾーンなしの日付として出力する
+			c.Add(name, ical.FormatDate(t.UTC()), ical.Param{Name: "VALUE", Value: "DATE"})
+			return
+		}
+		addDateTime(c, name, t, loc)
+	}
+
+	main := ical.NewComponent("VEVENT")
+	main.Add("UID", uid)
+	main.Add("DTSTAMP", stamp)
+	addTime(main, "DTSTART", event.StartDate)
+	addTime(main, "DTEND", event.EndDate)
+	main.AddText("SUMMARY", event.Title)
+	if event.Description != "" {
+		main.AddText("DESCRIPTION", event.Description)
+	}
+	if location := eventLocation(event); location != "" {
+		main.AddText("LOCATION", location)
+	}
+	main.AddText("CATEGORIES", string(event.Type))
+	if class := eventClass(event); class != "" {
+		main.Add("CLASS", class)
+	}
+	if event.Status == models.EventStatusCancelled {
+		main.Add("STATUS", "CANCELLED")
+	}
+	main.Add("CREATED", ical.FormatUTC(event.CreatedAt))
+	main.Add("LAST-MODIFIED", ical.FormatUTC(event.UpdatedAt))
+
+	components := []*ical.Component{main}
+	if !event.IsRecurring || event.Recurrence == "" {
+		return components
+	}
+	rule, err := recurrence.Parse(event.Recurrence)
+	if err != nil {
+		// 解釈できないルールは単発イベントとして出力する
+		return components
+	}
+	if event.AllDay {
+		main.Add("RRULE", rule.WithDateUntil(time.UTC).String())
+	} else {
+		main.Add("RRULE", rule.WithUTCUntil(event.TimeZoneLocation()).String())
+	}
+
+	duration := event.EndDate.Sub(event.StartDate)
+	for i := range exceptions {
+		ex := &exceptions[i]
+		if ex.IsCancelled {
+			addTime(main, "EXDATE", ex.RecurrenceID)
+			continue
+		}
+
+		occ := applyException(newOccurrence(event, ex.RecurrenceID, duration), ex)
+		override := ical.NewComponent("VEVENT")
+		override.Add("UID", uid)
+		override.Add("DTSTAMP", stamp)
+		addTime(override, "RECURRENCE-ID", ex.RecurrenceID)
+		addTime(override, "DTSTART", occ.StartDate)
+		addTime(override, "DTEND", occ.EndDate)
+		override.AddText("SUMMARY", occ.Title)
+		if occ.Description != "" {
+			override.AddText("DESCRIPTION", occ.Description)
+		}
+		override.AddText("CATEGORIES", string(event.Type))
+		override.Add("LAST-MODIFIED", ical.FormatUTC(ex.UpdatedAt))
+		components = append(components, override)
+	}
+	return components
+}
+
+// eventClass 公開範囲に対応するCLASS（チーム内公開は既定のため出力しない）
+func eventClass(event *models.Event) string {
+	switch event.Visibility {
+	case models.EventVisibilityPrivate:
+		return "PRIVATE"
+	case models.EventVisibilityPublic:
+		return "PUBLIC"
+	}
+	return ""
+}
+
+// eventLocation 場所と予約した会議室の名前（会議室は読み込まれている場合のみ）
+func eventLocation(event *models.Event) string {
+	if event.Room == nil {
+		return event.Location
+	}
+	if event.Location == "" {
+		return event.Room.Name
+	}
+	return event.Room.Name + " " + event.Location
+}
+
+// eventUID 外部カレンダー由来のUIDがあればそれを、なければイベントIDからUIDを作る
+func eventUID(event *models.Event) string {
+	if event.ICalUID != "" {
+		return event.ICalUID
+	}
+	return event.ID + "@taskcalendar"
+}
+
+func addDateTime(c *ical.Component, name string, t time.Time, loc *time.Location) {
+	if loc == time.UTC {
+		c.Add(name, ical.FormatUTC(t))
+		return
+	}
+	c.Add(name, ical.FormatLocal(t.In(loc)), ical.Param{Name: "TZID", Value: loc.String()})
+}
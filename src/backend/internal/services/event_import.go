@@ -0,0 +1,372 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/ical"
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/recurrence"
+
+	"gorm.io/gorm"
+)
+
+// インポート結果のステータス
+const (
+	ImportStatusCreated = "created"
+	ImportStatusFailed  = "failed"
+)
+
+// ImportOptions iCalendarインポートの設定
+type ImportOptions struct {
+	TeamID   *string        // 指定時はチームのイベントとして作成
+	DryRun   bool           // 解析・検証のみ行い保存しない
+	Location *time.Location // フローティング時刻・終日イベントの解釈に使う（nilはUTC）
+}
+
+// ImportResult VEVENT（UID単位）ごとのインポート結果
+type ImportResult struct {
+	UID         string   `json:"uid"`
+	Title       string   `json:"title"`
+	Status      string   `json:"status"`
+	EventID     string   `json:"eventId,omitempty"`
+	IsRecurring bool     `json:"isRecurring"`
+	Exceptions  int      `json:"exceptions"`
+	Warnings    []string `json:"warnings,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// ImportReport インポート全体の結果
+type ImportReport struct {
+	DryRun  bool           `json:"dryRun"`
+	Total   int            `json:"total"`
+	Created int            `json:"created"`
+	Failed  int            `json:"failed"`
+	Events  []ImportResult `json:"events"`
+}
+
+// importedEvent VEVENTから変換したイベントと例外
+type importedEvent struct {
+	event      models.Event
+	exceptions []models.EventException
+	warnings   []string
+}
+
+// ImportICS iCalendarデータのVEVENTをイベントとして取り込む
+func (s *EventService) ImportICS(userID string, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	if opts.TeamID != nil {
+		if err := ensureTeamMember(s.db, *opts.TeamID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	cal, err := ical.Decode(r)
+	if err != nil {
+		if errors.Is(err, ical.ErrMalformed) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		return nil, err
+	}
+	if cal.Name != "VCALENDAR" {
+		return nil, fmt.Errorf("%w: VCALENDARではありません", ErrInvalidInput)
+	}
+
+	loc := opts.Location
+	if loc == nil {
+		loc = calendarLocation(cal, time.UTC)
+	}
+
+	uids, masters, overrides := groupVEvents(cal)
+
+	report := &ImportReport{DryRun: opts.DryRun, Total: len(uids), Events: []ImportResult{}}
+	for _, uid := range uids {
+		result := ImportResult{UID: uid}
+		imported, err := convertVEvent(masters[uid], overrides[uid], loc)
+		if err == nil {
+			imported.event.CreatorID = userID
+			imported.event.TeamID = opts.TeamID
+			result.Title = imported.event.Title
+			result.IsRecurring = imported.event.IsRecurring
+			result.Exceptions = len(imported.exceptions)
+			result.Warnings = imported.warnings
+			if !opts.DryRun {
+				err = s.saveImportedEvent(imported)
+				result.EventID = imported.event.ID
+			}
+		}
+
+		if err != nil {
+			result.Status = ImportStatusFailed
+			result.Error = err.Error()
+			report.Failed++
+		} else {
+			result.Status = ImportStatusCreated
+			report.Created++
+		}
+		report.Events = append(report.Events, result)
+	}
+	return report, nil
+}
+
+// groupVEvents 同じUIDのVEVENTを本体（RECURRENCE-IDなし）と上書き回に分けてまとめる（UIDは出現順）
+func groupVEvents(cal *ical.Component) ([]string, map[string]*ical.Component, map[string][]*ical.Component) {
+	var uids []string
+	masters := make(map[string]*ical.Component)
+	overrides := make(map[string][]*ical.Component)
+	for i, vevent := range cal.Children("VEVENT") {
+		uid := fmt.Sprintf("event-%d", i+1)
+		if p := vevent.Get("UID"); p != nil && p.Value != "" {
+			uid = p.Value
+		}
+		if _, seen := masters[uid]; !seen && len(overrides[uid]) == 0 {
+			uids = append(uids, uid)
+		}
+		if vevent.Get("RECURRENCE-ID") != nil {
+			overrides[uid] = append(overrides[uid], vevent)
+		} else {
+			masters[uid] = vevent
+		}
+	}
+	return uids, masters, overrides
+}
+
+// calendarLocation X-WR-TIMEZONEがあればフローティング時刻の解釈に使う
+func calendarLocation(cal *ical.Component, fallback *time.Location) *time.Location {
+	if tz := cal.Get("X-WR-TIMEZONE"); tz != nil {
+		if l, err := time.LoadLocation(tz.Value); err == nil {
+			return l
+		}
+	}
+	return fallback
+}
+
+func (s *EventService) saveImportedEvent(imported *importedEvent) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&imported.event).Error; err != nil {
+			return err
+		}
+		for i := range imported.exceptions {
+			imported.exceptions[i].EventID = imported.event.ID
+			if err := tx.Create(&imported.exceptions[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// convertVEvent VEVENT（と上書き回）をイベントモデルに変換する
+func convertVEvent(master *ical.Component, overrides []*ical.Component, loc *time.Location) (*importedEvent, error) {
+	if master == nil {
+		return nil, errors.New("繰り返しの本体となるVEVENTがありません")
+	}
+
+	// DATE型のDTSTARTは終日イベント。日付はUTCの0時として扱い、EXDATEなども同じ基準で解釈する
+	allDay := master.Get("DTSTART") != nil && master.Get("DTSTART").IsDate()
+	if allDay {
+		loc = time.UTC
+	}
+	start, end, err := veventTimes(master, loc)
+	if err != nil {
+		return nil, err
+	}
+	timeZone := ""
+	if allDay {
+		start, end = models.NormalizeAllDayRange(start, end)
+	} else if l := start.Location(); l != time.UTC {
+		// TZID付き・フローティング時刻の場合はそのタイムゾーンで繰り返しを展開する
+		timeZone = l.String()
+	}
+	imported := &importedEvent{event: models.Event{
+		Title:      veventText(master, "SUMMARY"),
+		StartDate:  start,
+		EndDate:    end,
+		AllDay:     allDay,
+		TimeZone:   timeZone,
+		Type:       veventType(master),
+		Visibility: veventVisibility(master),
+		Status:     veventStatus(master),
+	}}
+	if imported.event.Title == "" {
+		imported.event.Title = "（無題）"
+	}
+	imported.event.Description = veventText(master, "DESCRIPTION")
+	imported.event.Location = veventText(master, "LOCATION")
+	if p := master.Get("UID"); p != nil {
+		imported.event.ICalUID = p.Value
+	}
+
+	rrules := master.GetAll("RRULE")
+	if len(rrules) == 0 {
+		if len(overrides) > 0 {
+			imported.warnings = append(imported.warnings, "繰り返しルールがないため、上書きされた回は無視しました")
+		}
+		return imported, nil
+	}
+	if len(rrules) > 1 {
+		imported.warnings = append(imported.warnings, "複数のRRULEのうち最初のもののみ取り込みました")
+	}
+	if master.Get("RDATE") != nil {
+		imported.warnings = append(imported.warnings, "RDATEには対応していないため無視しました")
+	}
+	rule, err := recurrence.Parse(rrules[0].Value)
+	if err != nil {
+		return nil, fmt.Errorf("RRULEが不正です: %v", err)
+	}
+	imported.event.IsRecurring = true
+	imported.event.Recurrence = rule.String()
+
+	// 同じ回に複数の指定がある場合は後のもの（上書き回）を優先する
+	byRecurrenceID := make(map[int64]models.EventException)
+	var order []int64
+	add := func(ex models.EventException) {
+		key := ex.RecurrenceID.Unix()
+		if !isRuleOccurrence(rule, start, ex.RecurrenceID) {
+			imported.warnings = append(imported.warnings,
+				fmt.Sprintf("%sは繰り返しルール上の日時ではないため無視しました", ex.RecurrenceID.Format(time.RFC3339)))
+			return
+		}
+		if _, ok := byRecurrenceID[key]; !ok {
+			order = append(order, key)
+		}
+		byRecurrenceID[key] = ex
+	}
+
+	for _, exdate := range master.GetAll("EXDATE") {
+		times, err := exdate.Times(loc)
+		if err != nil {
+			return nil, fmt.Errorf("EXDATEが不正です: %v", err)
+		}
+		for _, t := range times {
+			add(models.EventException{RecurrenceID: t, IsCancelled: true})
+		}
+	}
+	for _, override := range overrides {
+		recurrenceID, err := override.Get("RECURRENCE-ID").Time(loc)
+		if err != nil {
+			return nil, fmt.Errorf("RECURRENCE-IDが不正です: %v", err)
+		}
+		ex, err := overrideException(&imported.event, override, recurrenceID, loc)
+		if err != nil {
+			return nil, err
+		}
+		add(ex)
+	}
+
+	for _, key := range order {
+		imported.exceptions = append(imported.exceptions, byRecurrenceID[key])
+	}
+	return imported, nil
+}
+
+// overrideException 上書き回のVEVENTから本体と異なる項目だけを例外として取り出す
+func overrideException(event *models.Event, vevent *ical.Component, recurrenceID time.Time, loc *time.Location) (models.EventException, error) {
+	ex := models.EventException{RecurrenceID: recurrenceID}
+	if p := vevent.Get("STATUS"); p != nil && strings.EqualFold(p.Value, "CANCELLED") {
+		ex.IsCancelled = true
+		return ex, nil
+	}
+
+	start, end, err := veventTimes(vevent, loc)
+	if err != nil {
+		return ex, err
+	}
+	if event.AllDay {
+		start, end = models.NormalizeAllDayRange(start, end)
+	}
+	if !start.Equal(recurrenceID) {
+		ex.StartDate = &start
+	}
+	if !end.Equal(recurrenceID.Add(event.EndDate.Sub(event.StartDate))) {
+		ex.EndDate = &end
+	}
+	if title := veventText(vevent, "SUMMARY"); title != "" && title != event.Title {
+		ex.Title = &title
+	}
+	if vevent.Get("DESCRIPTION") != nil {
+		if description := veventText(vevent, "DESCRIPTION"); description != event.Description {
+			ex.Description = &description
+		}
+	}
+	return ex, nil
+}
+
+// veventTimes DTSTARTとDTEND（またはDURATION）から開始・終了日時を求める
+func veventTimes(vevent *ical.Component, loc *time.Location) (time.Time, time.Time, error) {
+	dtstart := vevent.Get("DTSTART")
+	if dtstart == nil {
+		return time.Time{}, time.Time{}, errors.New("DTSTARTがありません")
+	}
+	start, err := dtstart.Time(loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("DTSTARTが不正です: %v", err)
+	}
+
+	var end time.Time
+	switch {
+	case vevent.Get("DTEND") != nil:
+		if end, err = vevent.Get("DTEND").Time(loc); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("DTENDが不正です: %v", err)
+		}
+	case vevent.Get("DURATION") != nil:
+		d, err := ical.ParseDuration(vevent.Get("DURATION").Value)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end = start.Add(d)
+	case dtstart.IsDate():
+		// 終日イベントでDTENDがない場合は1日とみなす
+		end = start.AddDate(0, 0, 1)
+	default:
+		end = start
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, errors.New("終了日時が開始日時より前です")
+	}
+	return start, end, nil
+}
+
+func veventText(vevent *ical.Component, name string) string {
+	if p := vevent.Get(name); p != nil {
+		return strings.TrimSpace(p.Text())
+	}
+	return ""
+}
+
+// veventType CATEGORIESにイベント種別が含まれていればそれを使う
+func veventType(vevent *ical.Component) models.EventType {
+	for _, p := range vevent.GetAll("CATEGORIES") {
+		for _, category := range strings.Split(p.Text(), ",") {
+			switch t := models.EventType(strings.ToUpper(strings.TrimSpace(category))); t {
+			case models.EventTypeMeeting, models.EventTypeDeadline, models.EventTypeReminder, models.EventTypePersonal,
+				models.EventTypeOutOfOffice:
+				return t
+			}
+		}
+	}
+	return models.EventTypeMeeting
+}
+
+// veventVisibility CLASSを公開範囲に変換する（PRIVATE・CONFIDENTIALは非公開、未指定はチーム内公開）
+func veventVisibility(vevent *ical.Component) models.EventVisibility {
+	if p := vevent.Get("CLASS"); p != nil {
+		switch strings.ToUpper(strings.TrimSpace(p.Value)) {
+		case "PRIVATE", "CONFIDENTIAL":
+			return models.EventVisibilityPrivate
+		case "PUBLIC":
+			return models.EventVisibilityPublic
+		}
+	}
+	return models.EventVisibilityTeam
+}
+
+// veventStatus STATUS:CANCELLEDのイベントはキャンセル済みとして取り込む
+func veventStatus(vevent *ical.Component) models.EventStatus {
+	if p := vevent.Get("STATUS"); p != nil && strings.EqualFold(strings.TrimSpace(p.Value), "CANCELLED") {
+		return models.EventStatusCancelled
+	}
+	return models.EventStatusConfirmed
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 通知種別
+const (
+	NotificationTypeEventOwnershipTransferred = "EVENT_OWNERSHIP_TRANSFERRED"
+)
+
+// TransferOwnershipInput イベントの作成者（主催者）の変更
+type TransferOwnershipInput struct {
+	NewOwnerID string `json:"newOwnerId" binding:"required"`
+	// KeepAsAttendee 元の作成者を出席の参加者として残す（チームを離れる場合などは false）
+	KeepAsAttendee bool `json:"keepAsAttendee"`
+}
+
+// TransferOwnership イベント（繰り返しの場合はシリーズ全体）の作成者を別のユーザーに変更する
+//
+// 作成者またはチーム管理者のみ実行でき、チームのイベントはチームのメンバーにのみ引き継げる。
+// 新しい作成者が参加者だった場合は参加者から外し、編集権限・出欠回答の通知は新しい作成者に移る。
+// 元の作成者は KeepAsAttendee の場合は出席の参加者として残し、それ以外はリマインダーも削除する。
+// 参加者には主催者の変わった招待メールを送り直す。
+func (s *AttendeeService) TransferOwnership(eventID, userID string, input TransferOwnershipInput) (*models.Event, error) {
+	event, err := findEditableEvent(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == models.EventStatusCancelled {
+		return nil, fmt.Errorf("%w: キャンセルされたイベントの作成者は変更できません", ErrInvalidInput)
+	}
+	previousID := event.CreatorID
+	if input.NewOwnerID == previousID {
+		return nil, fmt.Errorf("%w: 現在の作成者と同じユーザーです", ErrInvalidInput)
+	}
+
+	var newOwner models.User
+	if err := s.db.Select("id").Scopes(inWorkspaceOf(userID)).First(&newOwner, "id = ?", input.NewOwnerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: ユーザー %s が見つかりません", ErrInvalidInput, input.NewOwnerID)
+		}
+		return nil, err
+	}
+	if event.TeamID != nil {
+		if err := ensureTeamMember(s.db, *event.TeamID, newOwner.ID); err != nil {
+			if errors.Is(err, ErrForbidden) {
+				return nil, fmt.Errorf("%w: チームのイベントはチームのメンバーにのみ引き継げます", ErrInvalidInput)
+			}
+			return nil, err
+		}
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockEventAttendees(tx, event.ID); err != nil {
+			return err
+		}
+		if err := tx.Model(event).Updates(map[string]interface{}{
+			"creator_id": newOwner.ID,
+			"sequence":   event.Sequence + 1,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ? AND user_id = ?", event.ID, newOwner.ID).
+			Delete(&models.EventAttendee{}).Error; err != nil {
+			return err
+		}
+		if !input.KeepAsAttendee {
+			return tx.Where("event_id = ? AND user_id = ?", event.ID, previousID).Delete(&models.EventReminder{}).Error
+		}
+		now := time.Now()
+		return tx.Create(&models.EventAttendee{
+			EventID:     event.ID,
+			UserID:      previousID,
+			Status:      models.AttendeeStatusAccepted,
+			RespondedAt: &now,
+			InvitedByID: newOwner.ID,
+		}).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	if newOwner.ID != userID {
+		if err := s.notifier.Notify(NotificationMessage{
+			UserID:     newOwner.ID,
+			Type:       NotificationTypeEventOwnershipTransferred,
+			Title:      "イベントの作成者になりました",
+			Body:       fmt.Sprintf("%s（%s）", event.Title, event.StartDate.Format("2006-01-02 15:04")),
+			EntityType: "event",
+			EntityID:   event.ID,
+		}); err != nil {
+			log.Printf("作成者の変更通知の送信に失敗しました: %v", err)
+		}
+	}
+
+	var attendeeIDs []string
+	if err := s.db.Model(&models.EventAttendee{}).
+		Where("event_id = ? AND status <> ?", event.ID, models.AttendeeStatusDeclined).
+		Order("created_at ASC").Pluck("user_id", &attendeeIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Scopes(PreloadAttendees).Preload("Creator").First(event, "id = ?", event.ID).Error; err != nil {
+		return nil, err
+	}
+	s.invitations.SendInvitations(event, attendeeIDs)
+	return event, nil
+}
@@ -0,0 +1,337 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"task-calendar-backend/internal/database"
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/recurrence"
+
+	"gorm.io/gorm"
+)
+
+// 一度に展開できる期間の上限
+const maxExpansionRange = 366 * 24 * time.Hour
+
+// 終日イベントはUTCの日付で保存しているため、タイムゾーンの最大の時差分だけ広く検索する
+const allDaySlack = 14 * time.Hour
+
+// EventOccurrence 繰り返しイベントを展開した個々の発生
+type EventOccurrence struct {
+	EventID      string                 `json:"eventId"`
+	Title        string                 `json:"title"`
+	Description  string                 `json:"description"`
+	Type         models.EventType       `json:"type"`
+	StartDate    time.Time              `json:"startDate"`
+	EndDate      time.Time              `json:"endDate"`
+	RecurrenceID time.Time              `json:"recurrenceId"` // 繰り返しルール上の本来の開始日時
+	AllDay       bool                   `json:"allDay"`
+	TimeZone     string                 `json:"timeZone"`
+	IsRecurring  bool                   `json:"isRecurring"`
+	IsOverride   bool                   `json:"isOverride"`
+	Visibility   models.EventVisibility `json:"visibility"`
+	Status       models.EventStatus     `json:"status"`
+	Color        string                 `json:"color,omitempty"` // イベントの表示色（未設定ならカテゴリーの色）
+	CategoryID   *string                `json:"categoryId,omitempty"`
+	LabelIDs     []string               `json:"labelIds,omitempty"` // タグ（ラベルは読み込まれている場合のみ）
+	TeamID       *string                `json:"teamId"`
+	CreatorID    string                 `json:"creatorId"`
+}
+
+// OccurrenceFilter 発生一覧の絞り込み条件（指定した条件をすべて満たすイベントのみ、各条件はいずれかに一致すればよい）
+type OccurrenceFilter struct {
+	CategoryIDs []string           // カテゴリー
+	Types       []models.EventType // イベント種別
+	LabelIDs    []string           // タグ
+	CreatorIDs  []string           // 作成者
+	AttendeeIDs []string           // 参加者（辞退・キャンセル待ちを除く招待）
+}
+
+// IsZero 絞り込み条件を指定していないか
+func (f OccurrenceFilter) IsZero() bool {
+	return len(f.CategoryIDs) == 0 && len(f.Types) == 0 && len(f.LabelIDs) == 0 &&
+		len(f.CreatorIDs) == 0 && len(f.AttendeeIDs) == 0
+}
+
+// scope 絞り込み条件をイベントの検索に適用する
+func (f OccurrenceFilter) scope(db *gorm.DB) *gorm.DB {
+	if len(f.CategoryIDs) > 0 {
+		db = db.Where("events.category_id IN ?", f.CategoryIDs)
+	}
+	if len(f.Types) > 0 {
+		db = db.Where("events.type IN ?", f.Types)
+	}
+	if len(f.LabelIDs) > 0 {
+		db = db.Where("events.id IN (?)", db.Session(&gorm.Session{NewDB: true}).Table("event_labels").
+			Select("event_id").Where("label_id IN ?", f.LabelIDs))
+	}
+	if len(f.CreatorIDs) > 0 {
+		db = db.Where("events.creator_id IN ?", f.CreatorIDs)
+	}
+	if len(f.AttendeeIDs) > 0 {
+		db = db.Where("events.id IN (?)", db.Session(&gorm.Session{NewDB: true}).Model(&models.EventAttendee{}).
+			Select("event_id").Where("user_id IN ? AND status NOT IN ?", f.AttendeeIDs, models.InactiveAttendeeStatuses))
+	}
+	return db
+}
+
+// ExpandEvent イベントを [from, to) と重なる発生に展開する（例外・上書きを反映）
+func (s *EventService) ExpandEvent(event *models.Event, from, to time.Time) ([]EventOccurrence, error) {
+	var exceptions []models.EventException
+	if event.IsRecurring {
+		if err := s.db.Where("event_id = ?", event.ID).Find(&exceptions).Error; err != nil {
+			return nil, err
+		}
+	}
+	return expandEvent(event, exceptions, from, to)
+}
+
+func expandEvent(event *models.Event, exceptions []models.EventException, from, to time.Time) ([]EventOccurrence, error) {
+	duration := event.EndDate.Sub(event.StartDate)
+	dtstart := eventDTStart(event)
+	if event.AllDay {
+		// 終日イベントは日付で判定するため、期間の壁時計時刻をそのままUTCとして比較する
+		from, to = floatingUTC(from), floatingUTC(to)
+	}
+
+	if !event.IsRecurring || event.Recurrence == "" {
+		if dtstart.Before(to) && dtstart.Add(duration).After(from) {
+			return []EventOccurrence{newOccurrence(event, dtstart, duration)}, nil
+		}
+		return nil, nil
+	}
+
+	rule, err := recurrence.Parse(event.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	byRecurrenceID := make(map[int64]*models.EventException, len(exceptions))
+	for i := range exceptions {
+		byRecurrenceID[exceptions[i].RecurrenceID.Unix()] = &exceptions[i]
+	}
+
+	var occurrences []EventOccurrence
+	rule.Iterate(dtstart, func(start time.Time) bool {
+		if !start.Before(to) {
+			return false
+		}
+		if _, ok := byRecurrenceID[start.Unix()]; ok {
+			return true
+		}
+		if start.Add(duration).After(from) {
+			occurrences = append(occurrences, newOccurrence(event, start, duration))
+		}
+		return true
+	})
+
+	// 上書きされた回は移動先の日時で期間判定する
+	for i := range exceptions {
+		ex := &exceptions[i]
+		if ex.IsCancelled {
+			continue
+		}
+		occ := applyException(newOccurrence(event, ex.RecurrenceID, duration), ex)
+		if occ.StartDate.Before(to) && occ.EndDate.After(from) {
+			occurrences = append(occurrences, occ)
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].StartDate.Before(occurrences[j].StartDate)
+	})
+	return occurrences, nil
+}
+
+func applyException(occ EventOccurrence, ex *models.EventException) EventOccurrence {
+	occ.IsOverride = true
+	if ex.Title != nil {
+		occ.Title = *ex.Title
+	}
+	if ex.Description != nil {
+		occ.Description = *ex.Description
+	}
+	if ex.StartDate != nil {
+		occ.StartDate = *ex.StartDate
+	}
+	if ex.EndDate != nil {
+		occ.EndDate = *ex.EndDate
+	}
+	return occ
+}
+
+// GetEventOccurrences 指定イベントの期間内の発生を取得
+func (s *EventService) GetEventOccurrences(eventID, userID string, from, to time.Time) ([]EventOccurrence, error) {
+	if err := validateExpansionRange(from, to); err != nil {
+		return nil, err
+	}
+	event, err := findEventForUser(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	indexed, _, err := indexedOccurrences(s.db, []models.Event{*event}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if occurrences, ok := indexed[event.ID]; ok {
+		return occurrences, nil
+	}
+	return s.ExpandEvent(event, from, to)
+}
+
+// ListOccurrences ユーザーが閲覧できるイベントを期間内の発生に展開して開始日時順に返す（レプリカがあればレプリカから読む）
+func (s *EventService) ListOccurrences(userID string, from, to time.Time, filter OccurrenceFilter) ([]EventOccurrence, error) {
+	if err := validateExpansionRange(from, to); err != nil {
+		return nil, err
+	}
+
+	db := database.Read(s.db)
+	query := db.Preload("Category").Preload("Labels").Scopes(visibleEventsScope(userID), filter.scope)
+	var events []models.Event
+	if err := query.
+		Where("(all_day = ? AND start_date < ? AND (is_recurring = ? OR end_date > ?)) OR "+
+			"(all_day = ? AND start_date < ? AND (is_recurring = ? OR end_date > ?))",
+			false, to, true, from,
+			true, to.Add(allDaySlack), true, from.Add(-allDaySlack)).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	// 回の索引が使える繰り返しイベントは展開せずに索引から取得する
+	indexed, rest, err := indexedOccurrences(db, events, from, to)
+	if err != nil {
+		return nil, err
+	}
+	exceptions, err := s.loadExceptions(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences := []EventOccurrence{}
+	for i := range events {
+		occurrences = append(occurrences, indexed[events[i].ID]...)
+	}
+	for i := range rest {
+		expanded, err := expandEvent(&rest[i], exceptions[rest[i].ID], from, to)
+		if err != nil {
+			// ルールが壊れたイベントは一覧全体を失敗させず、単発として扱う
+			expanded = nil
+			if rest[i].StartDate.Before(to) && rest[i].EndDate.After(from) {
+				expanded = []EventOccurrence{newOccurrence(&rest[i], rest[i].StartDate, rest[i].EndDate.Sub(rest[i].StartDate))}
+			}
+		}
+		occurrences = append(occurrences, expanded...)
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].StartDate.Before(occurrences[j].StartDate)
+	})
+	return occurrences, nil
+}
+
+func newOccurrence(event *models.Event, start time.Time, duration time.Duration) EventOccurrence {
+	return EventOccurrence{
+		EventID:      event.ID,
+		Title:        event.Title,
+		Description:  event.Description,
+		Type:         event.Type,
+		StartDate:    start,
+		EndDate:      start.Add(duration),
+		RecurrenceID: start,
+		AllDay:       event.AllDay,
+		TimeZone:     event.TimeZone,
+		IsRecurring:  event.IsRecurring,
+		Visibility:   event.Visibility,
+		Status:       event.Status,
+		Color:        eventColor(event),
+		CategoryID:   event.CategoryID,
+		LabelIDs:     eventLabelIDs(event),
+		TeamID:       event.TeamID,
+		CreatorID:    event.CreatorID,
+	}
+}
+
+func eventLabelIDs(event *models.Event) []string {
+	if len(event.Labels) == 0 {
+		return nil
+	}
+	ids := make([]string, len(event.Labels))
+	for i := range event.Labels {
+		ids[i] = event.Labels[i].ID
+	}
+	return ids
+}
+
+// eventColor イベントの表示色（未設定の場合はカテゴリーの色、カテゴリーは読み込まれている場合のみ）
+func eventColor(event *models.Event) string {
+	if event.Color == "" && event.Category != nil {
+		return event.Category.Color
+	}
+	return event.Color
+}
+
+// eventDTStart 繰り返し展開の起点
+//
+// 時刻付きのイベントは作成時のタイムゾーンで展開し、夏時間の切り替え後も同じ壁時計時刻に発生させる。
+// 終日イベントはUTCの日付で展開し、サーバーのタイムゾーンで日付がずれないようにする。
+func eventDTStart(event *models.Event) time.Time {
+	if event.AllDay {
+		return event.StartDate.UTC()
+	}
+	return event.StartDate.In(event.TimeZoneLocation())
+}
+
+// floatingUTC 日時の壁時計時刻を保ったままUTCに置き換える
+func floatingUTC(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// LocalizeOccurrences 発生の日時を閲覧者のタイムゾーンで表す
+func LocalizeOccurrences(occurrences []EventOccurrence, loc *time.Location) []EventOccurrence {
+	for i := range occurrences {
+		occ := &occurrences[i]
+		occ.StartDate = models.LocalTime(occ.StartDate, occ.AllDay, loc)
+		occ.EndDate = models.LocalTime(occ.EndDate, occ.AllDay, loc)
+		occ.RecurrenceID = models.LocalTime(occ.RecurrenceID, occ.AllDay, loc)
+	}
+	return occurrences
+}
+
+func validateExpansionRange(from, to time.Time) error {
+	if !to.After(from) {
+		return fmt.Errorf("%w: toはfromより後の日時を指定してください", ErrInvalidInput)
+	}
+	if to.Sub(from) > maxExpansionRange {
+		return fmt.Errorf("%w: 取得期間は366日以内で指定してください", ErrInvalidInput)
+	}
+	return nil
+}
+
+// loadExceptions 繰り返しイベントの例外をまとめて取得する
+func (s *EventService) loadExceptions(events []models.Event) (map[string][]models.EventException, error) {
+	return loadEventExceptions(s.db, events)
+}
+
+func loadEventExceptions(db *gorm.DB, events []models.Event) (map[string][]models.EventException, error) {
+	var ids []string
+	for _, e := range events {
+		if e.IsRecurring {
+			ids = append(ids, e.ID)
+		}
+	}
+	result := make(map[string][]models.EventException)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	var exceptions []models.EventException
+	if err := db.Where("event_id IN ?", ids).Find(&exceptions).Error; err != nil {
+		return nil, err
+	}
+	for _, ex := range exceptions {
+		result[ex.EventID] = append(result[ex.EventID], ex)
+	}
+	return result, nil
+}
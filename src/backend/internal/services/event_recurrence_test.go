@@ -0,0 +1,160 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// createEvents IDを指定したイベントを登録する（チーム t1 の、u1 が作成したイベント）
+func createEvents(t *testing.T, db *gorm.DB, events ...models.Event) {
+	t.Helper()
+	for i := range events {
+		e := &events[i]
+		e.TeamID = stringPtr("t1")
+		e.CreatorID = "u1"
+		if err := db.Create(e).Error; err != nil {
+			t.Fatalf("イベント %s を登録できません: %v", e.ID, err)
+		}
+	}
+}
+
+func stringPtr(s string) *string { return &s }
+
+// occurrenceKeys 発生をイベントIDと開始日時で表す
+func occurrenceKeys(occurrences []EventOccurrence) string {
+	keys := make([]string, len(occurrences))
+	for i, occ := range occurrences {
+		keys[i] = occ.EventID + "@" + occ.StartDate.UTC().Format("01-02T15")
+	}
+	return strings.Join(keys, ",")
+}
+
+// キャンセルした回（EXDATE）は展開に含めず、上書きした回は移動先の日時で返す
+func TestExpandEventExdate(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("タイムゾーンを読み込めません: %v", err)
+	}
+	start := time.Date(2026, time.March, 2, 10, 0, 0, 0, loc)
+	event := &models.Event{
+		ID:          "e1",
+		Title:       "定例",
+		StartDate:   start.UTC(),
+		EndDate:     start.Add(time.Hour).UTC(),
+		TimeZone:    "Asia/Tokyo",
+		IsRecurring: true,
+		Recurrence:  "FREQ=DAILY;COUNT=5",
+	}
+	// 例外の回はUTCで保存されている
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 10, 0, 0, 0, loc).UTC() }
+	moved := time.Date(2026, time.March, 4, 15, 0, 0, 0, loc)
+	movedEnd := moved.Add(time.Hour)
+	exceptions := []models.EventException{
+		{EventID: "e1", RecurrenceID: day(3), IsCancelled: true},
+		{EventID: "e1", RecurrenceID: day(4), StartDate: &moved, EndDate: &movedEnd},
+	}
+
+	got, err := expandEvent(event, exceptions, day(1), day(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{day(2), moved, day(5), day(6)}
+	if len(got) != len(want) {
+		t.Fatalf("発生が %d 件です（%d 件のはず）: %+v", len(got), len(want), got)
+	}
+	for i, occ := range got {
+		if !occ.StartDate.Equal(want[i]) {
+			t.Errorf("%d 件目の開始が %v です（%v のはず）", i+1, occ.StartDate, want[i])
+		}
+		if occ.IsOverride != occ.RecurrenceID.Equal(day(4)) {
+			t.Errorf("%d 件目の IsOverride が %v です", i+1, occ.IsOverride)
+		}
+	}
+}
+
+// ルールが壊れた繰り返しイベントは一覧全体を失敗させず、期間と重なる場合のみ単発の発生として返す
+func TestListOccurrencesBrokenRule(t *testing.T) {
+	db := openTestDB(t)
+	seedTeam(t, db)
+	at := func(d, hour int) time.Time { return time.Date(2026, time.March, d, hour, 0, 0, 0, time.UTC) }
+	createEvents(t, db,
+		models.Event{ID: "e1", Title: "定例", StartDate: at(2, 10), EndDate: at(2, 11), IsRecurring: true, Recurrence: "FREQ=DAILY;COUNT=3"},
+		models.Event{ID: "e2", Title: "壊れたルール", StartDate: at(3, 12), EndDate: at(3, 13), IsRecurring: true, Recurrence: "DAILY"},
+		models.Event{ID: "e3", Title: "期間外の壊れたルール", StartDate: at(20, 12), EndDate: at(20, 13), IsRecurring: true, Recurrence: "DAILY"},
+	)
+	// 保存時の検証より前に登録された、解析できないルール
+	execAll(t, db, `UPDATE events SET recurrence = 'FREQ=SOMETIMES' WHERE id IN ('e2', 'e3')`)
+	s := &EventService{db: db}
+
+	occurrences, err := s.ListOccurrences("u2", at(1, 0), at(10, 0), OccurrenceFilter{})
+	if err != nil {
+		t.Fatalf("ルールが壊れたイベントがあると一覧を取得できません: %v", err)
+	}
+	if got, want := occurrenceKeys(occurrences), "e1@03-02T10,e1@03-03T10,e2@03-03T12,e1@03-04T10"; got != want {
+		t.Errorf("発生が %s です（%s のはず）", got, want)
+	}
+}
+
+// 終日イベントは閲覧者のタイムゾーンの日付で期間と比べる（UTCの日時では重ならない場合も含め、日付が重なるものを返す）
+func TestListOccurrencesAllDayWindow(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("タイムゾーンを読み込めません: %v", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("タイムゾーンを読み込めません: %v", err)
+	}
+	db := openTestDB(t)
+	seedTeam(t, db)
+	day := func(d int) time.Time { return time.Date(2026, time.March, d, 0, 0, 0, 0, time.UTC) }
+	createEvents(t, db,
+		models.Event{ID: "d5", Title: "5日", StartDate: day(5), EndDate: day(6), AllDay: true},
+		models.Event{ID: "d6", Title: "6日", StartDate: day(6), EndDate: day(7), AllDay: true},
+		models.Event{ID: "d7", Title: "7日", StartDate: day(7), EndDate: day(8), AllDay: true},
+	)
+	s := &EventService{db: db}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     string
+	}{
+		// UTCでは3月7日1時〜4時だが、ニューヨークでは3月6日の夜
+		{
+			name: "ニューヨークの3月6日の夜",
+			from: time.Date(2026, time.March, 6, 20, 0, 0, 0, newYork),
+			to:   time.Date(2026, time.March, 6, 23, 0, 0, 0, newYork),
+			want: "d6@03-06T00",
+		},
+		// UTCでは3月6日16時〜19時だが、東京では3月7日の朝
+		{
+			name: "東京の3月7日の朝",
+			from: time.Date(2026, time.March, 7, 1, 0, 0, 0, tokyo),
+			to:   time.Date(2026, time.March, 7, 4, 0, 0, 0, tokyo),
+			want: "d7@03-07T00",
+		},
+		{
+			name: "東京の3月5日から2日間",
+			from: time.Date(2026, time.March, 5, 0, 0, 0, 0, tokyo),
+			to:   time.Date(2026, time.March, 7, 0, 0, 0, 0, tokyo),
+			want: "d5@03-05T00,d6@03-06T00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occurrences, err := s.ListOccurrences("u2", tt.from, tt.to, OccurrenceFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := occurrenceKeys(occurrences); got != tt.want {
+				t.Errorf("発生が %s です（%s のはず）", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/recurrence"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 繰り返しイベントの回を変更するときの範囲
+const (
+	RecurringScopeThis      = "this"      // その回だけ
+	RecurringScopeFollowing = "following" // その回以降
+	RecurringScopeAll       = "all"       // シリーズ全体
+)
+
+// UpdateSeries 繰り返しイベントの回を起点に、その回以降（following）またはシリーズ全体を変更する
+//
+// 日時の変更は、変更した回と同じ日数・同じ時刻になるようにシリーズの各回に反映する（回ごとの変更も同じだけずらす）。
+// その回以降を変更する場合は、元のシリーズをその回の前で終わらせ、その回以降を新しいシリーズ（参加者・リマインダーを引き継ぐ）に分割する。
+// 最初の回を指定した場合はシリーズ全体の変更と同じになる。
+func (s *EventService) UpdateSeries(eventID, userID string, recurrenceID time.Time, scope string, input OccurrenceOverrideInput) (*models.Event, error) {
+	if scope != RecurringScopeFollowing && scope != RecurringScopeAll {
+		return nil, fmt.Errorf("%w: scopeはthis・following・allのいずれかを指定してください", ErrInvalidInput)
+	}
+	event, err := s.findRecurringEventForEdit(eventID, userID, recurrenceID)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := recurrence.Parse(event.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	dtstart := eventDTStart(event)
+	if recurrenceID.Equal(dtstart) {
+		scope = RecurringScopeAll
+	}
+
+	start := recurrenceID
+	if input.StartDate != nil {
+		start = *input.StartDate
+	}
+	end := start.Add(event.EndDate.Sub(event.StartDate))
+	if input.EndDate != nil {
+		end = *input.EndDate
+	}
+	if event.AllDay {
+		start, end = models.NormalizeAllDayRange(start, end)
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("%w: 終了日時は開始日時より後にしてください", ErrInvalidInput)
+	}
+
+	loc := dtstart.Location()
+	days, shift := seriesShift(recurrenceID.In(loc), start.In(loc))
+	newRule, ok := rule.ShiftDays(days)
+	if !ok {
+		return nil, fmt.Errorf("%w: 日付や第n曜日を指定した繰り返しは、日をまたいでずらせません", ErrInvalidInput)
+	}
+
+	target := event
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if scope == RecurringScopeAll {
+			newStart := shift(dtstart)
+			updates := map[string]interface{}{
+				"start_date": newStart,
+				"end_date":   newStart.Add(end.Sub(start)),
+				"recurrence": newRule.String(),
+			}
+			applySeriesInput(updates, input)
+			if err := tx.Model(event).Updates(updates).Error; err != nil {
+				return err
+			}
+			event.StartDate = updates["start_date"].(time.Time)
+			event.Recurrence = updates["recurrence"].(string)
+			if err := moveSeriesRecords(tx, event.ID, event, recurrenceID, time.Time{}, shift, newRule); err != nil {
+				return err
+			}
+			return ensureSeriesRoomAvailable(tx, event)
+		}
+
+		if rule.Count > 0 {
+			// COUNTは分割前後の回数の合計が元の回数になるように分ける
+			before := 0
+			rule.Iterate(dtstart, func(t time.Time) bool {
+				if !t.Before(recurrenceID) {
+					return false
+				}
+				before++
+				return true
+			})
+			newRule.Count = rule.Count - before
+		}
+		if err := tx.Model(event).Update("recurrence", rule.EndingBefore(recurrenceID).String()).Error; err != nil {
+			return err
+		}
+
+		series := splitSeries(event, start, end, newRule)
+		if input.Title != nil {
+			series.Title = *input.Title
+		}
+		if input.Description != nil {
+			series.Description = *input.Description
+		}
+		if err := tx.Create(series).Error; err != nil {
+			return err
+		}
+		if err := copySeriesParticipants(tx, event.ID, series.ID); err != nil {
+			return err
+		}
+		if err := moveSeriesRecords(tx, event.ID, series, recurrenceID, recurrenceID, shift, newRule); err != nil {
+			return err
+		}
+		target = series
+		return ensureSeriesRoomAvailable(tx, series)
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Scopes(PreloadAttendees).First(target, "id = ?", target.ID).Error; err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// seriesShift ある回の日時の変更（from → to）の日数の差と、シリーズの他の回に同じ変更を当てはめる関数
+//
+// 日数と壁時計の時刻でずらすため、夏時間の切り替えをまたいでも各回の時刻は揃う。
+func seriesShift(from, to time.Time) (int, func(time.Time) time.Time) {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(toDate.Sub(fromDate).Hours() / 24)
+	loc := to.Location()
+	return days, func(t time.Time) time.Time {
+		t = t.In(loc)
+		return time.Date(t.Year(), t.Month(), t.Day()+days, to.Hour(), to.Minute(), to.Second(), to.Nanosecond(), loc)
+	}
+}
+
+func applySeriesInput(updates map[string]interface{}, input OccurrenceOverrideInput) {
+	if input.Title != nil {
+		updates["title"] = *input.Title
+	}
+	if input.Description != nil {
+		updates["description"] = *input.Description
+	}
+}
+
+// splitSeries 分割後の新しいシリーズ（元のシリーズの内容を引き継ぐ。外部カレンダーのUID・作成済みのビデオ会議は引き継がない）
+func splitSeries(event *models.Event, start, end time.Time, rule *recurrence.Rule) *models.Event {
+	series := *event
+	series.ID = ""
+	series.StartDate = start
+	series.EndDate = end
+	series.Recurrence = rule.String()
+	series.ICalUID = ""
+	series.Sequence = 0
+	series.CreatedAt = time.Time{}
+	series.UpdatedAt = time.Time{}
+	series.OccurrencesFrom = nil
+	series.OccurrencesUntil = nil
+	series.OccurrencesVersion = ""
+	if event.ConferenceID != "" {
+		// ビデオ会議の同期で新しいシリーズ用の会議を作り直す
+		series.ConferenceProvider = ""
+		series.ConferenceURL = ""
+		series.ConferenceID = ""
+		series.ConferenceStart = nil
+		series.ConferenceEnd = nil
+	}
+	series.Team = nil
+	series.Creator = models.User{}
+	series.Room = nil
+	series.Category = nil
+	series.Exceptions = nil
+	series.Attendees = nil
+	series.Reminders = nil
+	series.CheckIns = nil
+	series.Occurrences = nil
+	return &series
+}
+
+// copySeriesParticipants 参加者（回答を含む）とリマインダーを分割後のシリーズにコピーする
+func copySeriesParticipants(tx *gorm.DB, fromEventID, toEventID string) error {
+	var attendees []models.EventAttendee
+	if err := tx.Where("event_id = ?", fromEventID).Find(&attendees).Error; err != nil {
+		return err
+	}
+	for i := range attendees {
+		attendees[i].ID = ""
+		attendees[i].EventID = toEventID
+	}
+	if len(attendees) > 0 {
+		if err := tx.Create(&attendees).Error; err != nil {
+			return err
+		}
+	}
+
+	var reminders []models.EventReminder
+	if err := tx.Where("event_id = ?", fromEventID).Find(&reminders).Error; err != nil {
+		return err
+	}
+	for i := range reminders {
+		reminders[i].ID = ""
+		reminders[i].EventID = toEventID
+	}
+	if len(reminders) > 0 {
+		return tx.Create(&reminders).Error
+	}
+	return nil
+}
+
+// moveSeriesRecords 回ごとの変更とチェックインを、日時をずらして変更後のシリーズに付け替える
+//
+// from 以降（ゼロ値の場合はすべて）の回が対象。変更の起点の回の変更は破棄し、ずらした結果シリーズの回でなくなったものも破棄する。
+func moveSeriesRecords(tx *gorm.DB, eventID string, series *models.Event, recurrenceID, from time.Time, shift func(time.Time) time.Time, rule *recurrence.Rule) error {
+	dtstart := eventDTStart(series)
+	valid := func(t time.Time) bool {
+		return isRuleOccurrence(rule, dtstart, t)
+	}
+
+	var exceptions []models.EventException
+	if err := tx.Where("event_id = ? AND recurrence_id >= ?", eventID, from).Find(&exceptions).Error; err != nil {
+		return err
+	}
+	// 一意制約に触れないように、削除してから付け替えたものを作り直す
+	if err := tx.Where("event_id = ? AND recurrence_id >= ?", eventID, from).Delete(&models.EventException{}).Error; err != nil {
+		return err
+	}
+	var moved []models.EventException
+	for _, ex := range exceptions {
+		if ex.RecurrenceID.Equal(recurrenceID) {
+			continue
+		}
+		ex.EventID = series.ID
+		ex.RecurrenceID = shift(ex.RecurrenceID)
+		if valid(ex.RecurrenceID) {
+			moved = append(moved, ex)
+		}
+	}
+	if len(moved) > 0 {
+		if err := tx.Create(&moved).Error; err != nil {
+			return err
+		}
+	}
+
+	var checkIns []models.EventCheckIn
+	if err := tx.Where("event_id = ? AND recurrence_id >= ?", eventID, from).Find(&checkIns).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("event_id = ? AND recurrence_id >= ?", eventID, from).Delete(&models.EventCheckIn{}).Error; err != nil {
+		return err
+	}
+	var movedCheckIns []models.EventCheckIn
+	for _, ci := range checkIns {
+		ci.EventID = series.ID
+		ci.RecurrenceID = shift(ci.RecurrenceID)
+		if valid(ci.RecurrenceID) {
+			movedCheckIns = append(movedCheckIns, ci)
+		}
+	}
+	if len(movedCheckIns) > 0 {
+		return tx.Create(&movedCheckIns).Error
+	}
+	return nil
+}
+
+// ensureSeriesRoomAvailable 変更後のシリーズが会議室の他の予約と重ならないことを確認する
+func ensureSeriesRoomAvailable(tx *gorm.DB, event *models.Event) error {
+	if event.RoomID == nil {
+		return nil
+	}
+	var room models.Room
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&room, "id = ?", *event.RoomID).Error; err != nil {
+		return err
+	}
+	var current models.Event
+	if err := tx.First(&current, "id = ?", event.ID).Error; err != nil {
+		return err
+	}
+	var exceptions []models.EventException
+	if err := tx.Where("event_id = ?", event.ID).Find(&exceptions).Error; err != nil {
+		return err
+	}
+	occurrences, err := expandEvent(&current, exceptions, current.StartDate, current.StartDate.Add(roomBookingHorizon))
+	if err != nil {
+		return err
+	}
+	return ensureRoomAvailable(tx, &room, event.ID, occurrences)
+}
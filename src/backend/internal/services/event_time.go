@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// EventTimeInput カレンダー上のドラッグ・リサイズによる日時の変更
+//
+// UpdatedAt には取得時のイベントの updatedAt を指定し、その後に他の操作で更新されていた場合は変更しない（楽観的排他制御）。
+type EventTimeInput struct {
+	StartDate time.Time `json:"startDate" binding:"required"`
+	EndDate   time.Time `json:"endDate" binding:"required,gtfield=StartDate"`
+	UpdatedAt time.Time `json:"updatedAt" binding:"required"`
+}
+
+// EventTimeResult 日時の変更結果（変更後の日時と updatedAt、参加者の予定との重複のみ返す）
+type EventTimeResult struct {
+	ID        string          `json:"id"`
+	StartDate time.Time       `json:"startDate"`
+	EndDate   time.Time       `json:"endDate"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	Conflicts []EventConflict `json:"conflicts"`
+}
+
+// MoveEvent イベントの開始・終了日時だけを変更する
+//
+// 繰り返しイベントは回ごとに変更するため対象外。会議室の予約とチームの重複禁止の設定は通常の更新と同じく確認する。
+func (s *EventService) MoveEvent(eventID, userID string, input EventTimeInput) (*EventTimeResult, error) {
+	event, err := findEditableEvent(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if event.IsRecurring {
+		return nil, fmt.Errorf("%w: 繰り返しイベントは回ごとに変更してください", ErrInvalidInput)
+	}
+	// データベースの精度（マイクロ秒）に揃えて比較する
+	expected := input.UpdatedAt.Truncate(time.Microsecond)
+	if !event.UpdatedAt.Equal(expected) {
+		return nil, ErrPreconditionFailed
+	}
+
+	moved := *event
+	moved.StartDate, moved.EndDate = input.StartDate, input.EndDate
+	if moved.AllDay {
+		moved.StartDate, moved.EndDate = models.NormalizeAllDayRange(moved.StartDate, moved.EndDate)
+	}
+	if !moved.EndDate.After(moved.StartDate) {
+		return nil, fmt.Errorf("%w: endDateはstartDateより後の日時を指定してください", ErrInvalidInput)
+	}
+
+	if moved.RoomID != nil {
+		var room models.Room
+		if err := s.db.First(&room, "id = ?", *moved.RoomID).Error; err != nil {
+			return nil, err
+		}
+		occurrence := []EventOccurrence{{StartDate: moved.StartDate, EndDate: moved.EndDate, AllDay: moved.AllDay}}
+		if err := ensureRoomAvailable(s.db, &room, moved.ID, occurrence); err != nil {
+			return nil, err
+		}
+	}
+	var attendeeIDs []string
+	if err := s.db.Model(&models.EventAttendee{}).
+		Where("event_id = ? AND status NOT IN ?", event.ID, models.InactiveAttendeeStatuses).
+		Pluck("user_id", &attendeeIDs).Error; err != nil {
+		return nil, err
+	}
+	conflicts, err := eventConflicts(s.db, &moved, append([]string{event.CreatorID}, attendeeIDs...), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Truncate(time.Microsecond)
+	result := s.db.Model(&models.Event{}).Where("id = ? AND updated_at = ?", event.ID, expected).
+		UpdateColumns(map[string]interface{}{
+			"start_date": moved.StartDate,
+			"end_date":   moved.EndDate,
+			"updated_at": now,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		// 確認してから更新するまでの間に他の操作で更新された
+		return nil, ErrPreconditionFailed
+	}
+
+	return &EventTimeResult{
+		ID:        event.ID,
+		StartDate: moved.StartDate,
+		EndDate:   moved.EndDate,
+		UpdatedAt: now,
+		Conflicts: conflicts,
+	}, nil
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"task-calendar-backend/internal/models"
+)
+
+// EventVisibilityInput イベントの公開範囲の設定
+type EventVisibilityInput struct {
+	Visibility string `json:"visibility" binding:"required,oneof=PRIVATE TEAM PUBLIC"`
+}
+
+// SetEventVisibility イベントの公開範囲（PRIVATE / TEAM / PUBLIC）を設定する
+func (s *EventService) SetEventVisibility(eventID, userID string, input EventVisibilityInput) (*models.Event, error) {
+	event, err := findEditableEvent(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	visibility := models.EventVisibility(strings.ToUpper(strings.TrimSpace(input.Visibility)))
+	if !visibility.Valid() {
+		return nil, fmt.Errorf("%w: visibilityはPRIVATE・TEAM・PUBLICのいずれかを指定してください", ErrInvalidInput)
+	}
+	if err := s.db.Model(event).Update("visibility", visibility).Error; err != nil {
+		return nil, err
+	}
+	return event, nil
+}
@@ -0,0 +1,548 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 空き状況の種別
+const (
+	BusyStatusBusy                = "BUSY"
+	BusyStatusTentative           = "TENTATIVE"
+	BusyStatusOutsideWorkingHours = "OUTSIDE_WORKING_HOURS"
+	BusyStatusOutOfOffice         = "OUT_OF_OFFICE"
+)
+
+// 一度に空き状況を取得できるユーザー数の上限
+const maxFreeBusyUsers = 50
+
+// BusyInterval 予定あり（または勤務時間外）の区間
+type BusyInterval struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Status  string    `json:"status"`
+	EventID string    `json:"eventId,omitempty"` // 本人とチームメイトのみ
+	Title   string    `json:"title,omitempty"`
+}
+
+// UserFreeBusy ユーザーごとの空き状況
+type UserFreeBusy struct {
+	UserID   string         `json:"userId"`
+	TimeZone string         `json:"timeZone"`
+	Busy     []BusyInterval `json:"busy"`
+}
+
+// WorkingHoursSlot 曜日ごとの勤務時間（HH:MM、終了は24:00まで指定可）
+type WorkingHoursSlot struct {
+	Weekday int    `json:"weekday"`
+	Start   string `json:"start" binding:"required"`
+	End     string `json:"end" binding:"required"`
+}
+
+// WorkingHoursInput 勤務時間の設定（曜日ごとの一覧で置き換える）
+type WorkingHoursInput struct {
+	TimeZone       string             `json:"timeZone" binding:"omitempty,timezone"`
+	HolidayCountry string             `json:"holidayCountry"` // 祝日を勤務時間から除く国（空は除かない）
+	Hours          []WorkingHoursSlot `json:"hours"`
+}
+
+// WorkingHoursSettings ユーザーの勤務時間の設定
+type WorkingHoursSettings struct {
+	TimeZone       string                `json:"timeZone"`
+	HolidayCountry string                `json:"holidayCountry"`
+	Hours          []models.WorkingHours `json:"hours"`
+}
+
+type FreeBusyService struct {
+	db       *gorm.DB
+	holidays *HolidayService
+}
+
+func NewFreeBusyService(db *gorm.DB, holidays *HolidayService) *FreeBusyService {
+	return &FreeBusyService{db: db, holidays: holidays}
+}
+
+// GetWorkingHours 勤務時間の設定を取得
+func (s *FreeBusyService) GetWorkingHours(userID string) (*WorkingHoursSettings, error) {
+	var user models.User
+	if err := s.db.Select("id", "time_zone", "holiday_country").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	hours, err := loadWorkingHours(s.db, []string{userID})
+	if err != nil {
+		return nil, err
+	}
+	settings := &WorkingHoursSettings{TimeZone: user.TimeZone, HolidayCountry: user.HolidayCountry, Hours: hours[userID]}
+	if settings.Hours == nil {
+		settings.Hours = []models.WorkingHours{}
+	}
+	return settings, nil
+}
+
+// SetWorkingHours 勤務時間・タイムゾーン・祝日の国を設定する（空の一覧は勤務時間の指定なし）
+func (s *FreeBusyService) SetWorkingHours(userID string, input WorkingHoursInput) (*WorkingHoursSettings, error) {
+	if _, err := models.LoadLocation(input.TimeZone); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	country, err := normalizeHolidayCountry(input.HolidayCountry)
+	if err != nil {
+		return nil, err
+	}
+	if country != "" {
+		if err := s.holidays.EnsureSupported(country); err != nil {
+			return nil, err
+		}
+	}
+	hours := make([]models.WorkingHours, 0, len(input.Hours))
+	for _, slot := range input.Hours {
+		if slot.Weekday < 0 || slot.Weekday > 6 {
+			return nil, fmt.Errorf("%w: weekdayは0（日曜日）から6（土曜日）で指定してください", ErrInvalidInput)
+		}
+		start, err := parseClock(slot.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(slot.End)
+		if err != nil {
+			return nil, err
+		}
+		if end <= start {
+			return nil, fmt.Errorf("%w: 終了時刻は開始時刻より後にしてください", ErrInvalidInput)
+		}
+		hours = append(hours, models.WorkingHours{
+			Weekday:     time.Weekday(slot.Weekday),
+			StartMinute: start,
+			EndMinute:   end,
+			UserID:      userID,
+		})
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"time_zone":       input.TimeZone,
+			"holiday_country": country,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.WorkingHours{}).Error; err != nil {
+			return err
+		}
+		if len(hours) > 0 {
+			return tx.Create(&hours).Error
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return s.GetWorkingHours(userID)
+}
+
+// GetFreeBusy ユーザーの [from, to) の予定あり区間を取得する
+//
+// 予定名は本人とチームメイトにのみ返し、それ以外には時間帯のみを返す。
+func (s *FreeBusyService) GetFreeBusy(viewerID string, userIDs []string, from, to time.Time) ([]UserFreeBusy, error) {
+	if err := validateExpansionRange(from, to); err != nil {
+		return nil, err
+	}
+	userIDs = uniqueStrings(userIDs)
+	if len(userIDs) == 0 {
+		return nil, fmt.Errorf("%w: userIdsは必須です", ErrInvalidInput)
+	}
+	if len(userIDs) > maxFreeBusyUsers {
+		return nil, fmt.Errorf("%w: 一度に指定できるユーザーは%d人までです", ErrInvalidInput, maxFreeBusyUsers)
+	}
+
+	var users []models.User
+	if err := s.db.Select("id", "time_zone", "holiday_country").Scopes(inWorkspaceOf(viewerID)).
+		Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) != len(userIDs) {
+		return nil, ErrNotFound
+	}
+	timeZones := make(map[string]string, len(users))
+	holidays := make(map[string]map[string]bool, len(users))
+	for _, u := range users {
+		timeZones[u.ID] = u.TimeZone
+		holidays[u.ID] = s.holidays.holidayDates(u.HolidayCountry, from, to)
+	}
+
+	teammates, err := teammateIDs(s.db, viewerID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := loadWorkingHours(s.db, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	busy, err := eventBusyIntervals(s.db, userIDs, from, to, viewerID, teammates)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]UserFreeBusy, 0, len(userIDs))
+	for _, id := range userIDs {
+		intervals := busy[id]
+		loc, _ := models.LoadLocation(timeZones[id])
+		for _, interval := range outsideWorkingHours(hours[id], holidays[id], loc, from, to) {
+			intervals = append(intervals, BusyInterval{Start: interval.start, End: interval.end, Status: BusyStatusOutsideWorkingHours})
+		}
+		sort.SliceStable(intervals, func(i, j int) bool {
+			return intervals[i].Start.Before(intervals[j].Start)
+		})
+		if intervals == nil {
+			intervals = []BusyInterval{}
+		}
+		result = append(result, UserFreeBusy{UserID: id, TimeZone: timeZones[id], Busy: intervals})
+	}
+	return result, nil
+}
+
+// eventBusyIntervals ユーザーごとの予定の区間（辞退・キャンセル待ちでない招待を含む、終日の予定は不在のみ含める。前後の移動時間も予定に含める）
+//
+// 不在は作成者の予定としてのみ扱い、招待された人の空き状況には含めない。
+//
+// viewerIDを指定した場合、閲覧者に予定のIDとタイトルを含める。公開の予定は誰にでも、非公開の予定は閲覧できる人にだけ、
+// それ以外の予定は teammates の予定か閲覧できる場合に含める。
+func eventBusyIntervals(db *gorm.DB, userIDs []string, from, to time.Time, viewerID string, teammates map[string]bool) (map[string][]BusyInterval, error) {
+	events, participants, err := participantEvents(db, userIDs, from, to, "")
+	if err != nil {
+		return nil, err
+	}
+	exceptions, err := loadEventExceptions(db, events)
+	if err != nil {
+		return nil, err
+	}
+	canView := map[string]bool{}
+	if viewerID != "" {
+		if canView, err = viewableEventIDs(db, events, viewerID); err != nil {
+			return nil, err
+		}
+	}
+	showDetails := func(event *models.Event, userID string) bool {
+		if viewerID == "" {
+			return false
+		}
+		switch event.Visibility {
+		case models.EventVisibilityPublic:
+			return true
+		case models.EventVisibilityPrivate:
+			return canView[event.ID]
+		}
+		return teammates[userID] || canView[event.ID]
+	}
+
+	result := make(map[string][]BusyInterval, len(userIDs))
+	for i := range events {
+		occurrences, err := expandBusyEvent(&events[i], exceptions[events[i].ID], from, to)
+		if err != nil {
+			continue
+		}
+		outOfOffice := events[i].Type == models.EventTypeOutOfOffice
+		for _, occ := range occurrences {
+			for _, participant := range participants[events[i].ID] {
+				interval := BusyInterval{Start: occ.StartDate, End: occ.EndDate, Status: BusyStatusBusy}
+				switch {
+				case outOfOffice && participant.UserID != events[i].CreatorID:
+					continue
+				case outOfOffice:
+					interval.Status = BusyStatusOutOfOffice
+				case participant.Status != models.AttendeeStatusAccepted:
+					interval.Status = BusyStatusTentative
+				}
+				if showDetails(&events[i], participant.UserID) {
+					interval.EventID = events[i].ID
+					interval.Title = occ.Title
+				}
+				result[participant.UserID] = append(result[participant.UserID], interval)
+			}
+		}
+	}
+	return result, nil
+}
+
+// teammateIDs 指定ユーザーのうち本人、またはいずれかのチームの同じメンバーである人
+func teammateIDs(db *gorm.DB, viewerID string, userIDs []string) (map[string]bool, error) {
+	viewerTeams := db.Session(&gorm.Session{NewDB: true}).Model(&models.TeamMember{}).
+		Select("team_id").Where("user_id = ? AND status = ?", viewerID, models.TeamMemberStatusActive)
+	var ids []string
+	if err := db.Model(&models.TeamMember{}).
+		Where("user_id IN ? AND status = ? AND team_id IN (?)", userIDs, models.TeamMemberStatusActive, viewerTeams).
+		Distinct().Pluck("user_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	result := map[string]bool{viewerID: true}
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, nil
+}
+
+func loadWorkingHours(db *gorm.DB, userIDs []string) (map[string][]models.WorkingHours, error) {
+	var hours []models.WorkingHours
+	if err := db.Where("user_id IN ?", userIDs).Order("weekday ASC, start_minute ASC").Find(&hours).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string][]models.WorkingHours)
+	for _, h := range hours {
+		result[h.UserID] = append(result[h.UserID], h)
+	}
+	return result, nil
+}
+
+// timeInterval [start, end) の区間
+type timeInterval struct {
+	start time.Time
+	end   time.Time
+}
+
+// workingIntervals 勤務時間を [from, to) 内の区間に展開する（勤務時間の指定がなければ期間全体、祝日は勤務しない）
+func workingIntervals(hours []models.WorkingHours, holidays map[string]bool, loc *time.Location, from, to time.Time) []timeInterval {
+	if len(hours) == 0 {
+		return []timeInterval{{start: from, end: to}}
+	}
+	var intervals []timeInterval
+	// 時差で日付がずれるため前日から確認する
+	day := from.In(loc).AddDate(0, 0, -1)
+	for d := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc); d.Before(to); d = d.AddDate(0, 0, 1) {
+		if holidays[d.Format("2006-01-02")] {
+			continue
+		}
+		for _, h := range hours {
+			if h.Weekday != d.Weekday() {
+				continue
+			}
+			// 夏時間の切り替え日も壁時計時刻で区切る
+			start := time.Date(d.Year(), d.Month(), d.Day(), 0, h.StartMinute, 0, 0, loc)
+			end := time.Date(d.Year(), d.Month(), d.Day(), 0, h.EndMinute, 0, 0, loc)
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			if start.Before(end) {
+				intervals = append(intervals, timeInterval{start: start, end: end})
+			}
+		}
+	}
+	return mergeIntervals(intervals)
+}
+
+// outsideWorkingHours [from, to) のうち勤務時間外の区間
+func outsideWorkingHours(hours []models.WorkingHours, holidays map[string]bool, loc *time.Location, from, to time.Time) []timeInterval {
+	if len(hours) == 0 {
+		return nil
+	}
+	var result []timeInterval
+	cursor := from
+	for _, w := range workingIntervals(hours, holidays, loc, from, to) {
+		if cursor.Before(w.start) {
+			result = append(result, timeInterval{start: cursor, end: w.start})
+		}
+		cursor = w.end
+	}
+	if cursor.Before(to) {
+		result = append(result, timeInterval{start: cursor, end: to})
+	}
+	return result
+}
+
+// mergeIntervals 重なる・隣接する区間をまとめて開始日時順に返す
+func mergeIntervals(intervals []timeInterval) []timeInterval {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start.Before(intervals[j].start)
+	})
+	var merged []timeInterval
+	for _, interval := range intervals {
+		if n := len(merged); n > 0 && !interval.start.After(merged[n-1].end) {
+			if interval.end.After(merged[n-1].end) {
+				merged[n-1].end = interval.end
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+// parseClock HH:MM を0時からの分に変換する（24:00まで）
+func parseClock(value string) (int, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) == 2 {
+		h, errH := strconv.Atoi(parts[0])
+		m, errM := strconv.Atoi(parts[1])
+		if errH == nil && errM == nil && h >= 0 && m >= 0 && m < 60 && (h < 24 || (h == 24 && m == 0)) {
+			return h*60 + m, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: 時刻 %s はHH:MM形式で指定してください", ErrInvalidInput, value)
+}
+
+// formatClock 0時からの分をHH:MMで表す
+func formatClock(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+// 候補時間の検索
+const (
+	maxSlotSearchRange = 31 * 24 * time.Hour
+	defaultSlotStep    = 30 * time.Minute
+	defaultSlotLimit   = 10
+	maxSlotLimit       = 50
+)
+
+// FindSlotsInput 会議の候補時間の検索条件
+type FindSlotsInput struct {
+	AttendeeIDs     []string  `json:"attendeeIds" binding:"required,min=1,max=50"`
+	DurationMinutes int       `json:"durationMinutes" binding:"required,min=1,max=1440"`
+	From            time.Time `json:"from" binding:"required"`
+	To              time.Time `json:"to" binding:"required,gtfield=From"`
+	StepMinutes     int       `json:"stepMinutes" binding:"omitempty,min=5,max=1440"` // 候補の開始時刻の間隔（既定30分）
+	Limit           int       `json:"limit" binding:"min=0"`
+}
+
+// SlotCandidate 会議の候補時間（スコアが高いほど良い）
+type SlotCandidate struct {
+	Start               time.Time `json:"start"`
+	End                 time.Time `json:"end"`
+	Score               int       `json:"score"`
+	TentativeUserIDs    []string  `json:"tentativeUserIds"`    // 仮の予定と重なる参加者
+	OutsideHoursUserIDs []string  `json:"outsideHoursUserIds"` // 勤務時間外になる参加者
+}
+
+// FindSlots 依頼者と参加者の全員が予定のない（不在でない）時間を候補として、勤務時間内に収まる人が多い順に返す
+//
+// 仮の予定（未回答・仮承諾）との重なりや勤務時間外は除外せず減点する。勤務時間は各参加者のタイムゾーンで判定する。
+func (s *FreeBusyService) FindSlots(userID string, input FindSlotsInput) ([]SlotCandidate, error) {
+	if input.DurationMinutes <= 0 || input.DurationMinutes > 24*60 {
+		return nil, fmt.Errorf("%w: durationMinutesは1〜1440で指定してください", ErrInvalidInput)
+	}
+	if !input.To.After(input.From) {
+		return nil, fmt.Errorf("%w: toはfromより後の日時を指定してください", ErrInvalidInput)
+	}
+	if input.To.Sub(input.From) > maxSlotSearchRange {
+		return nil, fmt.Errorf("%w: 検索期間は31日以内で指定してください", ErrInvalidInput)
+	}
+	step := defaultSlotStep
+	if input.StepMinutes != 0 {
+		if input.StepMinutes < 5 || input.StepMinutes > 24*60 {
+			return nil, fmt.Errorf("%w: stepMinutesは5〜1440で指定してください", ErrInvalidInput)
+		}
+		step = time.Duration(input.StepMinutes) * time.Minute
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultSlotLimit
+	}
+	if limit > maxSlotLimit {
+		limit = maxSlotLimit
+	}
+
+	userIDs := uniqueStrings(append([]string{userID}, input.AttendeeIDs...))
+	if len(userIDs) > maxFreeBusyUsers {
+		return nil, fmt.Errorf("%w: 一度に指定できるユーザーは%d人までです", ErrInvalidInput, maxFreeBusyUsers)
+	}
+	var users []models.User
+	if err := s.db.Select("id", "time_zone", "holiday_country").Scopes(inWorkspaceOf(userID)).
+		Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) != len(userIDs) {
+		return nil, fmt.Errorf("%w: 存在しないユーザーが含まれています", ErrInvalidInput)
+	}
+
+	duration := time.Duration(input.DurationMinutes) * time.Minute
+	from := input.From
+	if now := time.Now(); from.Before(now) {
+		from = now
+	}
+	// 候補の開始時刻は区切りの良い時刻（stepの倍数）にそろえる
+	if t := from.Truncate(step); t.Before(from) {
+		from = t.Add(step)
+	}
+	to := input.To
+	if from.Add(duration).After(to) {
+		return []SlotCandidate{}, nil
+	}
+
+	busy, err := eventBusyIntervals(s.db, userIDs, from, to, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := loadWorkingHours(s.db, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	working := make(map[string][]timeInterval, len(users))
+	for _, u := range users {
+		if len(hours[u.ID]) == 0 {
+			continue
+		}
+		loc, _ := models.LoadLocation(u.TimeZone)
+		working[u.ID] = workingIntervals(hours[u.ID], s.holidays.holidayDates(u.HolidayCountry, from, to), loc, from, to)
+	}
+
+	var candidates []SlotCandidate
+	for start := from; !start.Add(duration).After(to); start = start.Add(step) {
+		end := start.Add(duration)
+		candidate := SlotCandidate{Start: start, End: end, Score: 100, TentativeUserIDs: []string{}, OutsideHoursUserIDs: []string{}}
+		available := true
+		for _, id := range userIDs {
+			tentative := false
+			for _, b := range busy[id] {
+				if !b.Start.Before(end) || !b.End.After(start) {
+					continue
+				}
+				if b.Status == BusyStatusBusy || b.Status == BusyStatusOutOfOffice {
+					available = false
+					break
+				}
+				tentative = true
+			}
+			if !available {
+				break
+			}
+			if tentative {
+				candidate.TentativeUserIDs = append(candidate.TentativeUserIDs, id)
+				candidate.Score -= 30
+			}
+			if w, ok := working[id]; ok && !containsInterval(w, start, end) {
+				candidate.OutsideHoursUserIDs = append(candidate.OutsideHoursUserIDs, id)
+				candidate.Score -= 20
+			}
+		}
+		if available {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	// スコアが同じなら早い時間を優先する
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	if candidates == nil {
+		candidates = []SlotCandidate{}
+	}
+	return candidates, nil
+}
+
+// containsInterval [start, end) が区間のいずれかに収まるか
+func containsInterval(intervals []timeInterval, start, end time.Time) bool {
+	for _, interval := range intervals {
+		if !interval.start.After(start) && !interval.end.Before(end) {
+			return true
+		}
+	}
+	return false
+}
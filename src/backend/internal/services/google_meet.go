@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	googleMeetAPIURL = "https://meet.googleapis.com/v2"
+	googleMeetScope  = "https://www.googleapis.com/auth/meetings.space.created"
+)
+
+// GoogleMeetConferenceProvider サービスアカウント（ドメイン全体の委任）による Google Meet の会議スペースの作成
+//
+// 会議スペースは日時を持たないため、イベントの日時変更で更新する必要はない。
+type GoogleMeetConferenceProvider struct {
+	clientEmail string
+	privateKey  interface{}
+	tokenURL    string
+	client      *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]googleToken // 主催者ごとのアクセストークン
+}
+
+type googleToken struct {
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewGoogleMeetConferenceProvider サービスアカウントの鍵ファイル（JSON）を読み込む
+func NewGoogleMeetConferenceProvider(credentialsFile string) (*GoogleMeetConferenceProvider, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	var key struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("サービスアカウントの鍵ファイルを解析できません: %v", err)
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(key.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("サービスアカウントの秘密鍵を読み込めません: %v", err)
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &GoogleMeetConferenceProvider{
+		clientEmail: key.ClientEmail,
+		privateKey:  privateKey,
+		tokenURL:    key.TokenURI,
+		client:      &http.Client{Timeout: 30 * time.Second},
+		tokens:      make(map[string]googleToken),
+	}, nil
+}
+
+func (p *GoogleMeetConferenceProvider) Provider() models.ConferenceProvider {
+	return models.ConferenceProviderGoogleMeet
+}
+
+func (p *GoogleMeetConferenceProvider) CreateMeeting(ctx context.Context, req ConferenceRequest) (*Conference, error) {
+	token, err := p.token(ctx, req.HostEmail)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, googleMeetAPIURL+"/spaces", strings.NewReader("{}"))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+token)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var space struct {
+		Name       string `json:"name"`
+		MeetingURI string `json:"meetingUri"`
+		Error      struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&space); err != nil {
+		return nil, fmt.Errorf("Google Meetの応答を解析できません（HTTP %d）", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Google Meet APIがエラーを返しました（HTTP %d）: %s", resp.StatusCode, space.Error.Message)
+	}
+	return &Conference{ID: space.Name, JoinURL: space.MeetingURI}, nil
+}
+
+func (p *GoogleMeetConferenceProvider) UpdateMeeting(ctx context.Context, meetingID string, req ConferenceRequest) error {
+	return nil
+}
+
+// DeleteMeeting 会議スペースは削除できないため何もしない（リンクはイベントから外れる）
+func (p *GoogleMeetConferenceProvider) DeleteMeeting(ctx context.Context, meetingID string) error {
+	return nil
+}
+
+// token 主催者になりすましたアクセストークンを署名付きJWTで取得する（有効期限まで使い回す）
+func (p *GoogleMeetConferenceProvider) token(ctx context.Context, subject string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t, ok := p.tokens[subject]; ok && time.Now().Before(t.expiresAt.Add(-time.Minute)) {
+		return t.accessToken, nil
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   p.clientEmail,
+		"scope": googleMeetScope,
+		"aud":   p.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+	if subject != "" {
+		claims["sub"] = subject
+	}
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(p.privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("Googleのトークンの応答を解析できません（HTTP %d）", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return "", fmt.Errorf("Googleのトークンを取得できませんでした: %s %s", body.Error, body.ErrorDescription)
+	}
+	p.tokens[subject] = googleToken{accessToken: body.AccessToken, expiresAt: now.Add(time.Duration(body.ExpiresIn) * time.Second)}
+	return body.AccessToken, nil
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"task-calendar-backend/internal/database"
+
+	"gorm.io/gorm"
+)
+
+const (
+	healthCheckTimeout = 2 * time.Second
+	// 定期実行の動作確認の記録の間隔（この3倍の間記録がなければ止まっているとみなす）
+	CronHeartbeatInterval = 30 * time.Second
+	// マイグレーションの確認結果を使い回す時間（プローブのたびに schema_migrations を読まないようにする）
+	migrationCheckTTL = time.Minute
+)
+
+// ComponentStatus 依存するものの状態
+type ComponentStatus struct {
+	Status    string     `json:"status"` // ok / unavailable
+	LatencyMs *int64     `json:"latencyMs,omitempty"`
+	LastRunAt *time.Time `json:"lastRunAt,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// ReadinessReport リクエストを受け付けられるかの確認結果
+type ReadinessReport struct {
+	Status     string                     `json:"status"` // すべて ok なら ok、それ以外は unavailable
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// Ready すべての依存するものが使えるか
+func (r *ReadinessReport) Ready() bool {
+	return r.Status == HealthOK
+}
+
+const (
+	HealthOK          = "ok"
+	HealthUnavailable = "unavailable"
+)
+
+// HealthService 稼働確認（Kubernetesのプローブ向け）
+//
+// データベースへの接続、マイグレーション（組み込まれたものがすべて適用されているか）、定期実行が動いているかを確認する。
+// 定期実行は CronHeartbeat を定期実行のジョブとして登録し、最後に実行された日時で確認する。
+type HealthService struct {
+	db        *gorm.DB
+	startedAt time.Time
+
+	mu               sync.Mutex
+	lastHeartbeat    time.Time
+	migrationChecked time.Time
+	migrationErr     error
+	shuttingDown     bool
+}
+
+func NewHealthService(db *gorm.DB) *HealthService {
+	return &HealthService{db: db, startedAt: time.Now()}
+}
+
+// CronHeartbeat 定期実行が動いていることを記録する（CronHeartbeatInterval ごとの定期実行）
+func (s *HealthService) CronHeartbeat() error {
+	s.mu.Lock()
+	s.lastHeartbeat = time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// ShuttingDown 停止を始めたことを記録する（以降は準備できていないと返し、新しいリクエストを振り分けさせない）
+func (s *HealthService) ShuttingDown() {
+	s.mu.Lock()
+	s.shuttingDown = true
+	s.mu.Unlock()
+}
+
+// CheckReadiness データベース・マイグレーション・定期実行の状態を確認する
+func (s *HealthService) CheckReadiness(ctx context.Context) *ReadinessReport {
+	report := &ReadinessReport{Status: HealthOK, Components: map[string]ComponentStatus{
+		"database":   s.checkDatabase(ctx),
+		"migrations": s.checkMigrations(),
+		"cron":       s.checkCron(),
+	}}
+	s.mu.Lock()
+	shuttingDown := s.shuttingDown
+	s.mu.Unlock()
+	if shuttingDown {
+		report.Components["server"] = ComponentStatus{Status: HealthUnavailable, Error: "停止中です"}
+	}
+	for _, component := range report.Components {
+		if component.Status != HealthOK {
+			report.Status = HealthUnavailable
+		}
+	}
+	return report
+}
+
+func (s *HealthService) checkDatabase(ctx context.Context) ComponentStatus {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return ComponentStatus{Status: HealthUnavailable, Error: err.Error()}
+	}
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	started := time.Now()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return ComponentStatus{Status: HealthUnavailable, Error: err.Error()}
+	}
+	latency := time.Since(started).Milliseconds()
+	return ComponentStatus{Status: HealthOK, LatencyMs: &latency}
+}
+
+// checkMigrations 組み込まれたマイグレーションがすべて適用されているか
+func (s *HealthService) checkMigrations() ComponentStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Since(s.migrationChecked) >= migrationCheckTTL {
+		s.migrationErr = database.CheckSchema(s.db)
+		s.migrationChecked = time.Now()
+	}
+	if s.migrationErr != nil {
+		return ComponentStatus{Status: HealthUnavailable, Error: s.migrationErr.Error()}
+	}
+	return ComponentStatus{Status: HealthOK}
+}
+
+// checkCron 定期実行が CronHeartbeatInterval の3倍以内に実行されているか（起動直後は最初の実行を待つ）
+func (s *HealthService) checkCron() ComponentStatus {
+	s.mu.Lock()
+	last := s.lastHeartbeat
+	s.mu.Unlock()
+	since := last
+	if since.IsZero() {
+		since = s.startedAt
+	}
+	status := ComponentStatus{Status: HealthOK}
+	if !last.IsZero() {
+		status.LastRunAt = &last
+	}
+	if time.Since(since) > 3*CronHeartbeatInterval {
+		status.Status = HealthUnavailable
+		status.Error = "定期実行が止まっています"
+	}
+	return status
+}
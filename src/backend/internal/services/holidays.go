@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Holiday 祝日
+type Holiday struct {
+	Date    time.Time `json:"date"` // UTC 0時の日付
+	Name    string    `json:"name"`
+	Country string    `json:"country"`
+}
+
+// HolidayProvider 国ごとの祝日の取得先
+type HolidayProvider interface {
+	// Holidays 指定した年の祝日を日付順に返す（対応していない国は ErrInvalidInput）
+	Holidays(country string, year int) ([]Holiday, error)
+}
+
+// BuiltinHolidayProvider 祝日法などの規則から祝日を計算する（日本・米国の連邦祝日に対応）
+type BuiltinHolidayProvider struct{}
+
+func (BuiltinHolidayProvider) Holidays(country string, year int) ([]Holiday, error) {
+	var days map[time.Time]string
+	switch country {
+	case "JP":
+		days = japaneseHolidays(year)
+	case "US":
+		days = usFederalHolidays(year)
+	default:
+		return nil, fmt.Errorf("%w: %sの祝日には対応していません", ErrInvalidInput, country)
+	}
+
+	holidays := make([]Holiday, 0, len(days))
+	for date, name := range days {
+		holidays = append(holidays, Holiday{Date: date, Name: name, Country: country})
+	}
+	sort.Slice(holidays, func(i, j int) bool {
+		return holidays[i].Date.Before(holidays[j].Date)
+	})
+	return holidays, nil
+}
+
+// japaneseHolidays 現行の祝日法による日本の祝日（振替休日・国民の休日を含む）
+func japaneseHolidays(year int) map[time.Time]string {
+	days := map[time.Time]string{}
+	add := func(month time.Month, day int, name string) {
+		days[holidayDate(year, month, day)] = name
+	}
+	// 春分・秋分の日は1980〜2099年に使える近似式で求める
+	equinox := func(base float64) int {
+		y := year - 1980
+		return int(base+0.242194*float64(y)) - y/4
+	}
+
+	add(time.January, 1, "元日")
+	days[nthWeekday(year, time.January, time.Monday, 2)] = "成人の日"
+	add(time.February, 11, "建国記念の日")
+	add(time.February, 23, "天皇誕生日")
+	add(time.March, equinox(20.8431), "春分の日")
+	add(time.April, 29, "昭和の日")
+	add(time.May, 3, "憲法記念日")
+	add(time.May, 4, "みどりの日")
+	add(time.May, 5, "こどもの日")
+	days[nthWeekday(year, time.July, time.Monday, 3)] = "海の日"
+	add(time.August, 11, "山の日")
+	days[nthWeekday(year, time.September, time.Monday, 3)] = "敬老の日"
+	add(time.September, equinox(23.2488), "秋分の日")
+	days[nthWeekday(year, time.October, time.Monday, 2)] = "スポーツの日"
+	add(time.November, 3, "文化の日")
+	add(time.November, 23, "勤労感謝の日")
+
+	// 前日と翌日が祝日の平日は国民の休日
+	for d := holidayDate(year, time.January, 2); d.Year() == year; d = d.AddDate(0, 0, 1) {
+		if _, ok := days[d]; ok || d.Weekday() == time.Sunday {
+			continue
+		}
+		_, before := days[d.AddDate(0, 0, -1)]
+		_, after := days[d.AddDate(0, 0, 1)]
+		if before && after {
+			days[d] = "国民の休日"
+		}
+	}
+
+	// 日曜日の祝日は、その後の最初の祝日でない日が振替休日
+	var sundays []time.Time
+	for d := range days {
+		if d.Weekday() == time.Sunday {
+			sundays = append(sundays, d)
+		}
+	}
+	for _, d := range sundays {
+		next := d.AddDate(0, 0, 1)
+		for {
+			if _, ok := days[next]; !ok {
+				break
+			}
+			next = next.AddDate(0, 0, 1)
+		}
+		days[next] = "振替休日"
+	}
+	return days
+}
+
+// usFederalHolidays 米国の連邦祝日（土曜日は前日、日曜日は翌日の振替も含む）
+func usFederalHolidays(year int) map[time.Time]string {
+	days := map[time.Time]string{}
+	fixed := func(month time.Month, day int, name string) {
+		d := holidayDate(year, month, day)
+		days[d] = name
+		switch d.Weekday() {
+		case time.Saturday:
+			days[d.AddDate(0, 0, -1)] = name + " (observed)"
+		case time.Sunday:
+			days[d.AddDate(0, 0, 1)] = name + " (observed)"
+		}
+	}
+
+	fixed(time.January, 1, "New Year's Day")
+	days[nthWeekday(year, time.January, time.Monday, 3)] = "Martin Luther King Jr. Day"
+	days[nthWeekday(year, time.February, time.Monday, 3)] = "Washington's Birthday"
+	days[lastWeekday(year, time.May, time.Monday)] = "Memorial Day"
+	fixed(time.June, 19, "Juneteenth National Independence Day")
+	fixed(time.July, 4, "Independence Day")
+	days[nthWeekday(year, time.September, time.Monday, 1)] = "Labor Day"
+	days[nthWeekday(year, time.October, time.Monday, 2)] = "Columbus Day"
+	fixed(time.November, 11, "Veterans Day")
+	days[nthWeekday(year, time.November, time.Thursday, 4)] = "Thanksgiving Day"
+	fixed(time.December, 25, "Christmas Day")
+	return days
+}
+
+func holidayDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// nthWeekday その月の第n weekday
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	first := holidayDate(year, month, 1)
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+7*(n-1))
+}
+
+// lastWeekday その月の最後の weekday
+func lastWeekday(year int, month time.Month, weekday time.Weekday) time.Time {
+	last := holidayDate(year, month+1, 0)
+	offset := (int(last.Weekday()) - int(weekday) + 7) % 7
+	return last.AddDate(0, 0, -offset)
+}
+
+// NagerHolidayProvider Nager.Date 互換のAPI（/api/v3/PublicHolidays/{year}/{country}）から祝日を取得する
+//
+// 取得した祝日は1日キャッシュし、APIが使えない場合は fallback（設定されていれば）から取得する。
+type NagerHolidayProvider struct {
+	baseURL  string
+	client   *http.Client
+	fallback HolidayProvider
+
+	mu    sync.Mutex
+	cache map[string]cachedHolidays
+}
+
+type cachedHolidays struct {
+	holidays  []Holiday
+	fetchedAt time.Time
+}
+
+const holidayCacheTTL = 24 * time.Hour
+
+func NewNagerHolidayProvider(baseURL string, fallback HolidayProvider) *NagerHolidayProvider {
+	return &NagerHolidayProvider{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		client:   &http.Client{Timeout: 15 * time.Second},
+		fallback: fallback,
+		cache:    map[string]cachedHolidays{},
+	}
+}
+
+func (p *NagerHolidayProvider) Holidays(country string, year int) ([]Holiday, error) {
+	key := fmt.Sprintf("%s-%d", country, year)
+	p.mu.Lock()
+	cached, ok := p.cache[key]
+	p.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < holidayCacheTTL {
+		return cached.holidays, nil
+	}
+
+	holidays, err := p.fetch(country, year)
+	if err != nil {
+		if ok {
+			// 取得に失敗した場合は期限切れのキャッシュを使う
+			return cached.holidays, nil
+		}
+		if p.fallback != nil {
+			log.Printf("祝日APIから%sの祝日を取得できませんでした: %v", key, err)
+			return p.fallback.Holidays(country, year)
+		}
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.cache[key] = cachedHolidays{holidays: holidays, fetchedAt: time.Now()}
+	p.mu.Unlock()
+	return holidays, nil
+}
+
+func (p *NagerHolidayProvider) fetch(country string, year int) ([]Holiday, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/api/v3/PublicHolidays/%d/%s", p.baseURL, year, country), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusNoContent:
+		return nil, fmt.Errorf("%w: %sの祝日には対応していません", ErrInvalidInput, country)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("祝日APIがステータス %d を返しました", resp.StatusCode)
+	}
+
+	var items []struct {
+		Date      string `json:"date"`
+		LocalName string `json:"localName"`
+		Global    bool   `json:"global"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, err
+	}
+	holidays := make([]Holiday, 0, len(items))
+	for _, item := range items {
+		// 一部の地域のみの祝日は除く
+		if !item.Global {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", item.Date)
+		if err != nil {
+			continue
+		}
+		holidays = append(holidays, Holiday{Date: date, Name: item.LocalName, Country: country})
+	}
+	return holidays, nil
+}
+
+type HolidayService struct {
+	db       *gorm.DB
+	provider HolidayProvider
+}
+
+func NewHolidayService(db *gorm.DB, provider HolidayProvider) *HolidayService {
+	return &HolidayService{db: db, provider: provider}
+}
+
+// ListTeamHolidays チームに設定した国の [from, to) の日付の祝日（未設定の場合は空）
+func (s *HolidayService) ListTeamHolidays(teamID, userID string, from, to time.Time) ([]Holiday, error) {
+	if err := ensureTeamMember(s.db, teamID, userID); err != nil {
+		return nil, err
+	}
+	if err := validateExpansionRange(from, to); err != nil {
+		return nil, err
+	}
+	var country string
+	if err := s.db.Table("teams").Where("id = ?", teamID).Pluck("holiday_country", &country).Error; err != nil {
+		return nil, err
+	}
+	if country == "" {
+		return []Holiday{}, nil
+	}
+	return s.Between(country, from, to)
+}
+
+// EnsureSupported 国の祝日を取得できることを確認する
+func (s *HolidayService) EnsureSupported(country string) error {
+	_, err := s.provider.Holidays(country, time.Now().Year())
+	return err
+}
+
+// Between 国の祝日のうち、日付が [from, to) の日付に含まれるもの
+func (s *HolidayService) Between(country string, from, to time.Time) ([]Holiday, error) {
+	start, end := floatingUTC(from).Truncate(24*time.Hour), floatingUTC(to)
+	holidays := []Holiday{}
+	for year := start.Year(); year <= end.Year(); year++ {
+		list, err := s.provider.Holidays(country, year)
+		if err != nil {
+			return nil, err
+		}
+		for _, h := range list {
+			if !h.Date.Before(start) && h.Date.Before(end) {
+				holidays = append(holidays, h)
+			}
+		}
+	}
+	return holidays, nil
+}
+
+// holidayDates 勤務時間の計算に使う祝日の日付の集合（"2006-01-02"、取得できない場合はログに残して空）
+func (s *HolidayService) holidayDates(country string, from, to time.Time) map[string]bool {
+	dates := map[string]bool{}
+	if s == nil || country == "" {
+		return dates
+	}
+	// 時差で日付がずれるため前後1日を含める
+	holidays, err := s.Between(country, from.AddDate(0, 0, -1), to.AddDate(0, 0, 1))
+	if err != nil {
+		log.Printf("%sの祝日を取得できませんでした: %v", country, err)
+		return dates
+	}
+	for _, h := range holidays {
+		dates[h.Date.Format("2006-01-02")] = true
+	}
+	return dates
+}
+
+// normalizeHolidayCountry 祝日の国コード（ISO 3166-1 alpha-2）を大文字に揃えて検証する（空は設定なし）
+func normalizeHolidayCountry(value string) (string, error) {
+	country := strings.ToUpper(strings.TrimSpace(value))
+	if country == "" {
+		return "", nil
+	}
+	if len(country) != 2 || country[0] < 'A' || country[0] > 'Z' || country[1] < 'A' || country[1] > 'Z' {
+		return "", fmt.Errorf("%w: holidayCountryはISO 3166-1の2文字の国コードで指定してください", ErrInvalidInput)
+	}
+	return country, nil
+}
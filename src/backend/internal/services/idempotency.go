@@ -0,0 +1,85 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Idempotency-Key を保存しておく期間（これより後の再送は新しいリクエストとして扱う）
+const idempotencyKeyTTL = 24 * time.Hour
+
+// ErrIdempotencyKeyReused 同じキーが別の内容のリクエストで使われた
+var ErrIdempotencyKeyReused = errors.New("Idempotency-Keyが別の内容のリクエストで使われています")
+
+// ErrIdempotencyKeyInProgress 同じキーのリクエストをまだ処理している
+var ErrIdempotencyKeyInProgress = errors.New("同じIdempotency-Keyのリクエストを処理中です")
+
+// IdempotencyService Idempotency-Key をつけたリクエストの記録と、再送されたリクエストへの応答の再利用
+type IdempotencyService struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyService(db *gorm.DB) *IdempotencyService {
+	return &IdempotencyService{db: db}
+}
+
+// Begin キーでの処理を始める。処理済みのリクエストの再送であれば保存した応答を返す（nilの場合はリクエストを処理する）
+func (s *IdempotencyService) Begin(userID, key, fingerprint string) (*models.IdempotencyKey, error) {
+	if len(key) > 255 {
+		return nil, fmt.Errorf("%w: Idempotency-Keyは255文字以内で指定してください", ErrInvalidInput)
+	}
+	// 期限の切れたキーは使い回せるよう先に消す
+	if err := s.db.Where("user_id = ? AND key = ? AND created_at < ?", userID, key, time.Now().Add(-idempotencyKeyTTL)).
+		Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return nil, err
+	}
+
+	record := models.IdempotencyKey{UserID: userID, Key: key, Fingerprint: fingerprint}
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return nil, nil
+	}
+
+	var existing models.IdempotencyKey
+	if err := s.db.First(&existing, "user_id = ? AND key = ?", userID, key).Error; err != nil {
+		return nil, err
+	}
+	if existing.Fingerprint != fingerprint {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if existing.CompletedAt == nil {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return &existing, nil
+}
+
+// Complete 応答を保存する（再送されたリクエストに返す）
+func (s *IdempotencyService) Complete(userID, key string, status int, contentType string, body []byte) error {
+	now := time.Now()
+	return s.db.Model(&models.IdempotencyKey{}).Where("user_id = ? AND key = ?", userID, key).
+		Updates(map[string]interface{}{
+			"status_code":  status,
+			"content_type": contentType,
+			"response":     body,
+			"completed_at": now,
+		}).Error
+}
+
+// Abandon 処理に失敗したキーを消す（同じキーで再送できるようにする）
+func (s *IdempotencyService) Abandon(userID, key string) error {
+	return s.db.Where("user_id = ? AND key = ?", userID, key).Delete(&models.IdempotencyKey{}).Error
+}
+
+// PurgeExpired 期限の切れたキーを削除する（定期実行）
+func (s *IdempotencyService) PurgeExpired() error {
+	return s.db.Where("created_at < ?", time.Now().Add(-idempotencyKeyTTL)).Delete(&models.IdempotencyKey{}).Error
+}
@@ -0,0 +1,28 @@
+package services
+
+import "gorm.io/gorm"
+
+// IncludeOptions include クエリで指定した、応答に含める関連（Preload する関連の名前）
+type IncludeOptions []string
+
+// IncludableRelations 一覧で include に指定できる関連（include で指定する名前 → Preload する関連）
+type IncludableRelations map[string][]string
+
+// 一覧ごとに include で指定できる関連（指定しなかった関連は読み込まず、応答では null・空になる）
+var (
+	PollIncludes         = IncludableRelations{"creator": {"Creator"}}
+	AttendeeIncludes     = IncludableRelations{"user": {"User"}}
+	SharedLinkIncludes   = IncludableRelations{"team": {"Team"}}
+	ReportIncludes       = IncludableRelations{"reporter": {"Reporter"}, "author": {"Author"}}
+	WatcherIncludes      = IncludableRelations{"user": {"User"}}
+	TaskActivityIncludes = IncludableRelations{"actor": {"Actor"}}
+	CommentIncludes      = IncludableRelations{"author": {"Author"}, "attachments": {"Attachments"}, "mentions": {"Mentions.User"}}
+)
+
+// preload include で指定した関連を読み込むクエリにする
+func preload(query *gorm.DB, include IncludeOptions) *gorm.DB {
+	for _, relation := range include {
+		query = query.Preload(relation)
+	}
+	return query
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/ical"
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// iMIPのPARTSTATと出欠の状態の対応
+var attendeePartStats = map[models.AttendeeStatus]string{
+	models.AttendeeStatusPending:   "NEEDS-ACTION",
+	models.AttendeeStatusAccepted:  "ACCEPTED",
+	models.AttendeeStatusDeclined:  "DECLINED",
+	models.AttendeeStatusTentative: "TENTATIVE",
+}
+
+// attendeePartStat 招待メールに書くPARTSTAT（キャンセル待ちは出席が確定していないため仮承諾として送る）
+func attendeePartStat(status models.AttendeeStatus) string {
+	if status == models.AttendeeStatusWaitlisted {
+		return "TENTATIVE"
+	}
+	return attendeePartStats[status]
+}
+
+// InvitationService 招待メール（iMIP、RFC 6047）を送る
+type InvitationService struct {
+	db             *gorm.DB
+	mailer         Mailer
+	replyAddresses *ReplyAddressService
+}
+
+func NewInvitationService(db *gorm.DB, mailer Mailer, replyAddresses *ReplyAddressService) *InvitationService {
+	return &InvitationService{db: db, mailer: mailer, replyAddresses: replyAddresses}
+}
+
+// SendInvitations 招待したユーザーにiCalendar（METHOD:REQUEST）付きの招待メールを送る
+//
+// 返信先ドメインが設定されている場合、ORGANIZERをユーザーごとの出欠回答用アドレスにして、
+// カレンダーアプリからの回答（METHOD:REPLY）を受け付けられるようにする。送信の失敗はログに残して続行する。
+func (s *InvitationService) SendInvitations(event *models.Event, userIDs []string) {
+	s.send(event, userIDs, "REQUEST")
+}
+
+// SendCancellations 参加者にiCalendar（METHOD:CANCEL）付きのキャンセルメールを送り、カレンダーアプリ上の予定を取り消させる
+func (s *InvitationService) SendCancellations(event *models.Event, userIDs []string) {
+	s.send(event, userIDs, "CANCEL")
+}
+
+func (s *InvitationService) send(event *models.Event, userIDs []string, method string) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	var creator models.User
+	if err := s.db.First(&creator, "id = ?", event.CreatorID).Error; err != nil {
+		log.Printf("招待メールの作成に失敗しました: %v", err)
+		return
+	}
+	var attendees []models.EventAttendee
+	if err := s.db.Preload("User").Where("event_id = ?", event.ID).Order("created_at ASC").
+		Find(&attendees).Error; err != nil {
+		log.Printf("招待メールの作成に失敗しました: %v", err)
+		return
+	}
+	var exceptions []models.EventException
+	if event.IsRecurring {
+		if err := s.db.Where("event_id = ?", event.ID).Find(&exceptions).Error; err != nil {
+			log.Printf("招待メールの作成に失敗しました: %v", err)
+			return
+		}
+	}
+	if event.RoomID != nil && event.Room == nil {
+		var room models.Room
+		if err := s.db.First(&room, "id = ?", *event.RoomID).Error; err == nil {
+			event.Room = &room
+		}
+	}
+
+	byUserID := make(map[string]*models.User, len(attendees))
+	for i := range attendees {
+		byUserID[attendees[i].UserID] = &attendees[i].User
+	}
+	for _, id := range userIDs {
+		user := byUserID[id]
+		if user == nil || user.Email == "" {
+			continue
+		}
+		var err error
+		if method == "CANCEL" {
+			err = s.sendCancellation(event, exceptions, &creator, attendees, user)
+		} else {
+			err = s.sendInvitation(event, exceptions, &creator, attendees, user)
+		}
+		if err != nil {
+			log.Printf("招待メールの送信に失敗しました（%s）: %v", user.Email, err)
+		}
+	}
+}
+
+func (s *InvitationService) sendInvitation(event *models.Event, exceptions []models.EventException, creator *models.User, attendees []models.EventAttendee, to *models.User) error {
+	organizerAddress := creator.Email
+	rsvpAddress, err := s.replyAddresses.IssueRSVPAddress(to.ID, event)
+	if err != nil {
+		return err
+	}
+	if rsvpAddress != "" {
+		organizerAddress = rsvpAddress
+	}
+
+	data, err := invitationICS(event, exceptions, creator, organizerAddress, attendees, "REQUEST")
+	if err != nil {
+		return err
+	}
+
+	msg, err := renderEmail(EmailTemplateInvitation, invitationEmailView{
+		Organizer:     userDisplayName(creator),
+		Title:         event.Title,
+		When:          invitationWhen(event, to),
+		Location:      eventLocation(event),
+		ConferenceURL: event.ConferenceURL,
+		Description:   event.Description,
+	})
+	if err != nil {
+		return err
+	}
+	msg.To = to.Email
+	msg.ReplyTo = rsvpAddress
+	msg.Calendar = &EmailCalendar{Method: "REQUEST", Data: data}
+	return s.mailer.Send(msg)
+}
+
+// sendCancellation キャンセルメールを送る（ORGANIZERは招待時と同じアドレスにしてカレンダーアプリが同じ予定と判断できるようにする）
+func (s *InvitationService) sendCancellation(event *models.Event, exceptions []models.EventException, creator *models.User, attendees []models.EventAttendee, to *models.User) error {
+	organizerAddress := creator.Email
+	if rsvpAddress := s.replyAddresses.IssuedRSVPAddress(to.ID, event.ID); rsvpAddress != "" {
+		organizerAddress = rsvpAddress
+	}
+
+	data, err := invitationICS(event, exceptions, creator, organizerAddress, attendees, "CANCEL")
+	if err != nil {
+		return err
+	}
+
+	msg, err := renderEmail(EmailTemplateCancellation, cancellationEmailView{
+		Organizer: userDisplayName(creator),
+		Title:     event.Title,
+		When:      invitationWhen(event, to),
+		Reason:    event.CancellationReason,
+	})
+	if err != nil {
+		return err
+	}
+	msg.To = to.Email
+	msg.Calendar = &EmailCalendar{Method: "CANCEL", Data: data}
+	return s.mailer.Send(msg)
+}
+
+// invitationEmailView 招待メールのテンプレートに渡す内容
+type invitationEmailView struct {
+	Organizer     string
+	Title         string
+	When          string
+	Location      string
+	ConferenceURL string
+	Description   string
+}
+
+// cancellationEmailView キャンセルメールのテンプレートに渡す内容
+type cancellationEmailView struct {
+	Organizer string
+	Title     string
+	When      string
+	Reason    string
+}
+
+// invitationWhen 招待メールに書く開始日時（受信者のタイムゾーン、終日イベントは日付のみ）
+func invitationWhen(event *models.Event, to *models.User) string {
+	if event.AllDay {
+		return event.StartDate.UTC().Format("2006-01-02")
+	}
+	loc, _ := models.LoadLocation(to.TimeZone)
+	return event.StartDate.In(loc).Format("2006-01-02 15:04")
+}
+
+// invitationICS 招待用のiCalendar（METHOD:REQUEST / CANCEL、ORGANIZERとATTENDEE付き）を生成する
+func invitationICS(event *models.Event, exceptions []models.EventException, creator *models.User, organizerAddress string, attendees []models.EventAttendee, method string) ([]byte, error) {
+	cal := buildCalendar(event.Title, []models.Event{*event}, map[string][]models.EventException{event.ID: exceptions}, nil)
+	cal.Get("METHOD").Value = method
+	for _, vevent := range cal.Children("VEVENT") {
+		vevent.Add("SEQUENCE", fmt.Sprint(event.Sequence))
+		vevent.Add("ORGANIZER", "mailto:"+organizerAddress, ical.Param{Name: "CN", Value: userDisplayName(creator)})
+		for _, a := range attendees {
+			if a.User.Email == "" {
+				continue
+			}
+			vevent.Add("ATTENDEE", "mailto:"+a.User.Email,
+				ical.Param{Name: "CN", Value: userDisplayName(&a.User)},
+				ical.Param{Name: "ROLE", Value: "REQ-PARTICIPANT"},
+				ical.Param{Name: "PARTSTAT", Value: attendeePartStat(a.Status)},
+				ical.Param{Name: "RSVP", Value: "TRUE"})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, cal); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ProcessRSVP 招待メールへの出欠回答（iCalendarのMETHOD:REPLY）を参加者の回答に反映する
+func (s *InboundEmailService) ProcessRSVP(email InboundEmail) (*models.EventAttendee, error) {
+	tokenValue := extractAddressToken(email.To, "rsvp+")
+	if tokenValue == "" {
+		return nil, fmt.Errorf("%w: 返信先アドレスが不正です", ErrInvalidInput)
+	}
+
+	var token models.EventReplyToken
+	if err := s.db.First(&token, "token = ?", tokenValue).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return nil, fmt.Errorf("%w: 返信の有効期限が切れています", ErrInvalidInput)
+	}
+	if err := s.ensureSender(email, token.UserID); err != nil {
+		return nil, err
+	}
+
+	var event models.Event
+	if err := s.db.First(&event, "id = ?", token.EventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	var user models.User
+	if err := s.db.Select("id", "email").First(&user, "id = ?", token.UserID).Error; err != nil {
+		return nil, err
+	}
+
+	input, err := parseICalReply(email.Calendars, eventUID(&event), user.Email)
+	if err != nil {
+		return nil, err
+	}
+	return s.attendeeService.RespondToInvitation(event.ID, user.ID, *input)
+}
+
+// parseICalReply METHOD:REPLYのiCalendarから、指定イベント・参加者の出欠回答を取り出す（回ごとの回答は無視する）
+func parseICalReply(calendars [][]byte, uid, email string) (*RSVPInput, error) {
+	if len(calendars) == 0 {
+		return nil, fmt.Errorf("%w: 出欠回答のiCalendarが添付されていません", ErrInvalidInput)
+	}
+	for _, data := range calendars {
+		cal, err := ical.Decode(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+		if method := cal.Get("METHOD"); method == nil || !strings.EqualFold(method.Value, "REPLY") {
+			continue
+		}
+		for _, vevent := range cal.Children("VEVENT") {
+			if p := vevent.Get("UID"); p == nil || p.Value != uid {
+				continue
+			}
+			if vevent.Get("RECURRENCE-ID") != nil {
+				continue
+			}
+			attendee := replyAttendee(vevent, email)
+			if attendee == nil {
+				continue
+			}
+			var status models.AttendeeStatus
+			for st, partStat := range attendeePartStats {
+				if strings.EqualFold(attendee.Param("PARTSTAT"), partStat) {
+					status = st
+				}
+			}
+			if status == "" || status == models.AttendeeStatusPending {
+				return nil, fmt.Errorf("%w: 出欠が回答されていません", ErrInvalidInput)
+			}
+			input := &RSVPInput{Status: string(status)}
+			if comment := vevent.Get("COMMENT"); comment != nil {
+				input.Comment = comment.Text()
+			}
+			return input, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: このイベントへの出欠回答が見つかりません", ErrInvalidInput)
+}
+
+// replyAttendee 回答者のATTENDEE（アドレスが一致するもの、なければ唯一のATTENDEE）
+func replyAttendee(vevent *ical.Component, email string) *ical.Property {
+	attendees := vevent.GetAll("ATTENDEE")
+	for i := range attendees {
+		address := strings.TrimPrefix(strings.ToLower(attendees[i].Value), "mailto:")
+		if strings.EqualFold(address, email) {
+			return &attendees[i]
+		}
+	}
+	if len(attendees) == 1 {
+		return &attendees[0]
+	}
+	return nil
+}
+
+// userDisplayName ユーザーの表示名（姓名、未設定の場合はユーザー名）
+func userDisplayName(user *models.User) string {
+	if name := strings.TrimSpace(user.LastName + " " + user.FirstName); name != "" {
+		return name
+	}
+	return user.Username
+}
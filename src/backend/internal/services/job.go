@@ -0,0 +1,514 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"task-calendar-backend/internal/database"
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/storage"
+
+	"gorm.io/gorm"
+)
+
+const (
+	jobBatchSize   = 10               // 1回の実行で取り出すジョブの数
+	jobWorkers     = 2                // 同時に実行するジョブの数
+	jobTimeout     = 30 * time.Minute // 実行中のまま止まったジョブを失敗にするまでの時間
+	jobRetention   = 7 * 24 * time.Hour
+	jobDownloadTTL = 15 * time.Minute // 結果のファイルの署名付きURLの有効期間
+	jobRetryBase   = 30 * time.Second // 最初の再実行までの間隔（失敗するたびに倍にする）
+	jobRetryMax    = time.Hour
+)
+
+// ErrJobNotRetryable 再実行できないジョブ（失敗していない、またはアップロードされたファイルを削除済み）
+var ErrJobNotRetryable = errors.New("このジョブは再実行できません")
+
+// PermanentJobError 実行し直しても成功しない失敗（宛先の誤りなど）として返す。回数が残っていても再実行せずに失敗にする
+func PermanentJobError(err error) error {
+	return &permanentJobError{err: err}
+}
+
+type permanentJobError struct {
+	err error
+}
+
+func (e *permanentJobError) Error() string { return e.err.Error() }
+func (e *permanentJobError) Unwrap() error { return e.err }
+
+// JobOutput ジョブの結果（File または WriteFile がある場合は Result の代わりに、署名付きURLでダウンロードさせる）
+type JobOutput struct {
+	Result interface{}
+	File   []byte
+	// WriteFile File の代わりに結果のファイルを書き出す（大きなファイルをメモリにためずにストレージに保存する。失敗した場合はジョブの失敗になる）
+	WriteFile   func(w io.Writer) error
+	FileName    string
+	ContentType string
+}
+
+func (o *JobOutput) hasFile() bool {
+	return o != nil && (o.File != nil || o.WriteFile != nil)
+}
+
+// JobRunner ジョブの処理
+//
+// input はアップロードされたファイル（ない場合は nil）。progress で進み具合（0〜100）を記録できる。
+type JobRunner func(job *models.Job, input io.Reader, progress func(percent int)) (*JobOutput, error)
+
+// JobService エクスポート・インポート・統合など時間のかかる処理や、メール・チャットへの送信を、非同期ジョブとして後から実行する
+//
+// 登録したジョブは定期実行の RunPending が取り出して実行する。ジョブはデータベースに保存するため、サーバーを再起動しても失われない。
+// 失敗したジョブは MaxAttempts 回まで、間隔を倍にしながら実行し直す。結果のファイルはストレージに保存し、
+// 取得のたびに発行する署名付きURL（認証なしでダウンロードできる）で返す。
+type JobService struct {
+	db           *gorm.DB
+	storage      storage.Storage
+	signingKey   []byte
+	downloadBase string
+	runners      map[models.JobType]JobRunner
+}
+
+// NewJobService downloadBase は署名付きURLのパス（/api/v1/jobs など。ジョブIDと /download を続ける）
+func NewJobService(db *gorm.DB, store storage.Storage, signingKey, downloadBase string) *JobService {
+	return &JobService{
+		db:           db,
+		storage:      store,
+		signingKey:   []byte(signingKey),
+		downloadBase: downloadBase,
+		runners:      map[models.JobType]JobRunner{},
+	}
+}
+
+// Register ジョブの種類ごとの処理を登録する（起動時に呼ぶ）
+func (s *JobService) Register(jobType models.JobType, runner JobRunner) {
+	s.runners[jobType] = runner
+}
+
+// Enqueue ジョブを登録する（params は実行時に DecodeJobParams で読み出す。input は実行まで保存しておく）
+func (s *JobService) Enqueue(userID string, jobType models.JobType, params interface{}, input io.Reader) (*models.Job, error) {
+	if _, ok := s.runners[jobType]; !ok {
+		return nil, fmt.Errorf("%w: ジョブの種類（%s）が不正です", ErrInvalidInput, jobType)
+	}
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	job := models.Job{UserID: userID, Type: jobType, Status: models.JobStatusPending, Params: string(encoded)}
+	if input != nil {
+		key, err := storage.NewKey("jobs/input")
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.storage.Save(key, input); err != nil {
+			return nil, err
+		}
+		job.InputKey = key
+	}
+	if err := s.db.Create(&job).Error; err != nil {
+		if job.InputKey != "" {
+			s.storage.Delete(job.InputKey)
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// EnqueueBackground サーバーが後から実行するジョブ（メールの送信など）を登録する
+//
+// db にトランザクションを渡すと、変更と同じトランザクションで登録する（ロールバックされた変更のジョブは実行しない）。
+// 失敗した場合は maxAttempts 回まで実行し直す。
+func (s *JobService) EnqueueBackground(db *gorm.DB, jobType models.JobType, params interface{}, maxAttempts int) error {
+	if _, ok := s.runners[jobType]; !ok {
+		return fmt.Errorf("%w: ジョブの種類（%s）が不正です", ErrInvalidInput, jobType)
+	}
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	job := models.Job{Type: jobType, Status: models.JobStatusPending, Params: string(encoded), MaxAttempts: maxAttempts}
+	return db.Session(&gorm.Session{NewDB: true}).Create(&job).Error
+}
+
+// DecodeJobParams 登録時の引数を読み出す
+func DecodeJobParams(job *models.Job, params interface{}) error {
+	return json.Unmarshal([]byte(job.Params), params)
+}
+
+// GetJob 自分のジョブの状態（結果のファイルがある場合は署名付きURLをつける）
+func (s *JobService) GetJob(jobID, userID string) (*models.Job, error) {
+	var job models.Job
+	if err := s.db.First(&job, "id = ?", jobID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if job.UserID != userID {
+		return nil, ErrNotFound
+	}
+	if job.Status == models.JobStatusSucceeded && job.ResultKey != "" {
+		expiresAt := time.Now().Add(jobDownloadTTL).Unix()
+		job.DownloadURL = fmt.Sprintf("%s/%s/download?expires=%d&signature=%s",
+			s.downloadBase, job.ID, expiresAt, s.downloadSignature(job.ID, expiresAt))
+	}
+	return &job, nil
+}
+
+// OpenResult 署名付きURLの結果のファイルを開く（署名が不正か期限切れの場合は ErrForbidden）
+func (s *JobService) OpenResult(jobID, expires, signature string) (*models.Job, io.ReadCloser, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt ||
+		!hmac.Equal([]byte(signature), []byte(s.downloadSignature(jobID, expiresAt))) {
+		return nil, nil, fmt.Errorf("%w: ダウンロードのURLが不正か期限切れです", ErrForbidden)
+	}
+
+	var job models.Job
+	if err := s.db.First(&job, "id = ?", jobID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	if job.ResultKey == "" {
+		return nil, nil, ErrNotFound
+	}
+	rc, err := s.storage.Open(job.ResultKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &job, rc, nil
+}
+
+func (s *JobService) downloadSignature(jobID string, expiresAt int64) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte("job-download:" + jobID + "." + strconv.FormatInt(expiresAt, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// RunPending 実行する日時を迎えた待機中のジョブを取り出して実行する（定期実行）
+//
+// 実行中のまま jobTimeout を過ぎたジョブ（実行中にサーバーが停止したものなど）は、回数が残っていれば実行し直し、なければ失敗にする。
+func (s *JobService) RunPending() error {
+	now := time.Now()
+	stale := s.db.Model(&models.Job{}).Where("status = ? AND started_at < ?", models.JobStatusRunning, now.Add(-jobTimeout))
+	if err := stale.Session(&gorm.Session{}).Where("attempts < max_attempts").
+		Updates(map[string]interface{}{
+			"status": models.JobStatusPending,
+			"error":  "処理が中断されました",
+			"run_at": now,
+		}).Error; err != nil {
+		return err
+	}
+	if err := stale.Session(&gorm.Session{}).Where("attempts >= max_attempts").
+		Updates(map[string]interface{}{
+			"status":       models.JobStatusFailed,
+			"error":        "処理が中断されました",
+			"completed_at": now,
+		}).Error; err != nil {
+		return err
+	}
+
+	var jobs []models.Job
+	if err := s.db.Where("status = ? AND run_at <= ?", models.JobStatusPending, now).
+		Order("run_at ASC").Limit(jobBatchSize).Find(&jobs).Error; err != nil {
+		return err
+	}
+
+	sem := make(chan struct{}, jobWorkers)
+	var wg sync.WaitGroup
+	for i := range jobs {
+		job := &jobs[i]
+		// 他のサーバーが先に取り出したジョブは実行しない
+		result := s.db.Model(&models.Job{}).Where("id = ? AND status = ?", job.ID, models.JobStatusPending).
+			Updates(map[string]interface{}{
+				"status":     models.JobStatusRunning,
+				"started_at": now,
+				"attempts":   gorm.Expr("attempts + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		job.Attempts++
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			if err := s.run(job); err != nil {
+				log.Printf("ジョブの結果の記録に失敗しました（%s）: %v", job.ID, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// run ジョブを実行し、結果（ファイルはストレージに保存する）または失敗の理由を記録する（回数が残っていれば再実行を予定する）
+func (s *JobService) run(job *models.Job) error {
+	output, err := s.execute(job)
+	if err == nil && output.hasFile() {
+		err = s.saveResultFile(job, output)
+	}
+	now := time.Now()
+	if err != nil && job.Attempts < job.MaxAttempts && retryableJobError(err) {
+		delay := jobRetryDelay(job.Attempts)
+		log.Printf("ジョブ（%s・%s）に失敗したため %s 後に実行し直します（%d/%d回目）: %v",
+			job.Type, job.ID, delay, job.Attempts, job.MaxAttempts, err)
+		return s.db.Model(&models.Job{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+			"status": models.JobStatusPending,
+			"error":  jobErrorMessage(err),
+			"run_at": now.Add(delay),
+		}).Error
+	}
+
+	updates := map[string]interface{}{"completed_at": now}
+	if err != nil {
+		log.Printf("ジョブ（%s・%s）に失敗しました: %v", job.Type, job.ID, err)
+		updates["status"] = models.JobStatusFailed
+		updates["error"] = jobErrorMessage(err)
+	} else {
+		updates["error"] = ""
+		updates["status"] = models.JobStatusSucceeded
+		updates["progress"] = 100
+		if output != nil && !output.hasFile() && output.Result != nil {
+			result, err := json.Marshal(output.Result)
+			if err != nil {
+				return err
+			}
+			updates["result"] = string(result)
+		}
+		updates["result_key"] = job.ResultKey
+		updates["result_name"] = job.ResultName
+		updates["result_content_type"] = job.ResultContentType
+	}
+	if job.InputKey != "" {
+		if err := s.storage.Delete(job.InputKey); err != nil {
+			log.Printf("ジョブの入力ファイルの削除に失敗しました（%s）: %v", job.ID, err)
+		}
+		updates["input_key"] = ""
+	}
+	return s.db.Model(&models.Job{}).Where("id = ?", job.ID).Updates(updates).Error
+}
+
+func (s *JobService) execute(job *models.Job) (output *JobOutput, err error) {
+	runner, ok := s.runners[job.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: ジョブの種類（%s）が不正です", ErrInvalidInput, job.Type)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("ジョブの実行中にパニックが発生しました: %v", r)
+		}
+	}()
+
+	var input io.Reader
+	if job.InputKey != "" {
+		rc, err := s.storage.Open(job.InputKey)
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		input = rc
+	}
+	progress := func(percent int) {
+		if percent < 0 || percent > 100 {
+			return
+		}
+		if err := s.db.Model(&models.Job{}).Where("id = ?", job.ID).UpdateColumn("progress", percent).Error; err != nil {
+			log.Printf("ジョブの進み具合の記録に失敗しました（%s）: %v", job.ID, err)
+		}
+	}
+	return runner(job, input, progress)
+}
+
+func (s *JobService) saveResultFile(job *models.Job, output *JobOutput) error {
+	key, err := storage.NewKey("jobs/results")
+	if err != nil {
+		return err
+	}
+	var r io.Reader = bytes.NewReader(output.File)
+	if output.WriteFile != nil {
+		pr, pw := io.Pipe()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			defer func() {
+				if r := recover(); r != nil {
+					pw.CloseWithError(fmt.Errorf("結果のファイルの書き出し中にパニックが発生しました: %v", r))
+				}
+			}()
+			pw.CloseWithError(output.WriteFile(pw))
+		}()
+		// 保存に失敗した場合も書き出しを止め、終わるのを待つ
+		defer func() {
+			pr.Close()
+			<-done
+		}()
+		r = pr
+	}
+	if _, err := s.storage.Save(key, r); err != nil {
+		return err
+	}
+	job.ResultKey = key
+	job.ResultName = output.FileName
+	job.ResultContentType = output.ContentType
+	return nil
+}
+
+// jobErrorMessage 失敗の理由として利用者に見せるメッセージ（想定外のエラーは内容を伏せる）
+func jobErrorMessage(err error) string {
+	if isKnownJobError(err) {
+		return err.Error()
+	}
+	return "処理中にエラーが発生しました"
+}
+
+func isKnownJobError(err error) bool {
+	for _, known := range []error{ErrInvalidInput, ErrNotFound, ErrForbidden, ErrConflict, ErrPreconditionFailed} {
+		if errors.Is(err, known) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryableJobError 実行し直せば成功する可能性のある失敗か（入力の誤りなど利用者に見せるエラーと、PermanentJobError は実行し直さない）
+func retryableJobError(err error) bool {
+	var permanent *permanentJobError
+	return !errors.As(err, &permanent) && !isKnownJobError(err)
+}
+
+// jobRetryDelay attempts 回失敗した後、次に実行するまでの間隔
+func jobRetryDelay(attempts int) time.Duration {
+	delay := jobRetryBase
+	for i := 1; i < attempts && delay < jobRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > jobRetryMax {
+		delay = jobRetryMax
+	}
+	return delay
+}
+
+// PurgeExpired 保存期間を過ぎた完了・失敗したジョブと、結果のファイルを削除する（定期実行）
+func (s *JobService) PurgeExpired() error {
+	var jobs []models.Job
+	if err := s.db.Select("id", "result_key").
+		Where("status IN ? AND completed_at < ?", []models.JobStatus{models.JobStatusSucceeded, models.JobStatusFailed}, time.Now().Add(-jobRetention)).
+		Find(&jobs).Error; err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if job.ResultKey != "" {
+			if err := s.storage.Delete(job.ResultKey); err != nil {
+				log.Printf("ジョブの結果のファイルの削除に失敗しました（%s）: %v", job.ID, err)
+				continue
+			}
+		}
+		if err := s.db.Delete(&models.Job{}, "id = ?", job.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JobListOptions ジョブの一覧の取得条件（管理者向け）
+type JobListOptions struct {
+	PageOptions
+	Status models.JobStatus // 空はすべて
+	Type   models.JobType   // 空はすべて
+}
+
+// JobPage ジョブの一覧の1ページ分（新しい順）
+type JobPage struct {
+	Jobs       []models.Job `json:"jobs"`
+	NextCursor *string      `json:"nextCursor"`
+	Total      *int64       `json:"total,omitempty"` // includeTotal を指定した場合のみ
+}
+
+// ListJobs すべての利用者とサーバーのジョブの一覧（管理者向け。滞留や失敗の確認に使う。レプリカがあればレプリカから読む）
+func (s *JobService) ListJobs(opts JobListOptions) (*JobPage, error) {
+	query := database.Read(s.db).Model(&models.Job{})
+	switch opts.Status {
+	case "":
+	case models.JobStatusPending, models.JobStatusRunning, models.JobStatusSucceeded, models.JobStatusFailed:
+		query = query.Where("status = ?", opts.Status)
+	default:
+		return nil, fmt.Errorf("%w: statusはPENDING・RUNNING・SUCCEEDED・FAILEDのいずれかで指定してください", ErrInvalidInput)
+	}
+	if opts.Type != "" {
+		query = query.Where("type = ?", opts.Type)
+	}
+	total, err := countTotal(query, opts.PageOptions)
+	if err != nil {
+		return nil, err
+	}
+	query, limit, err := paginate(query, opts.PageOptions, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []models.Job
+	if err := query.Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	page := &JobPage{Total: total}
+	page.Jobs, page.NextCursor = pageOf(jobs, limit, func(j *models.Job) (time.Time, string) {
+		return j.CreatedAt, j.ID
+	})
+	return page, nil
+}
+
+// jobTypesWithInput アップロードされたファイルを使うジョブの種類（ファイルは終わったときに削除するため再実行できない）
+var jobTypesWithInput = map[models.JobType]bool{
+	models.JobTypeEventImport:   true,
+	models.JobTypeBackupRestore: true,
+}
+
+// RetryJob 失敗したジョブをもう1回実行する（管理者向け。アップロードされたファイルを使うジョブは、ファイルを削除済みのため再実行できない）
+func (s *JobService) RetryJob(jobID string) (*models.Job, error) {
+	var job models.Job
+	if err := s.db.First(&job, "id = ?", jobID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if job.Status != models.JobStatusFailed || jobTypesWithInput[job.Type] {
+		return nil, ErrJobNotRetryable
+	}
+
+	result := s.db.Model(&models.Job{}).Where("id = ? AND status = ?", job.ID, models.JobStatusFailed).
+		Updates(map[string]interface{}{
+			"status":       models.JobStatusPending,
+			"max_attempts": job.Attempts + 1,
+			"run_at":       time.Now(),
+			"completed_at": nil,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrJobNotRetryable
+	}
+	if err := s.db.First(&job, "id = ?", job.ID).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
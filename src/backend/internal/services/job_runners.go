@@ -0,0 +1,135 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"task-calendar-backend/internal/models"
+)
+
+// EventExportJobParams イベントのエクスポートのジョブの引数
+type EventExportJobParams struct {
+	TeamID   *string `json:"teamId,omitempty"` // 指定時はチームのイベントのみ
+	TimeZone string  `json:"tz,omitempty"`     // 空の場合はUTC
+}
+
+// EventImportJobParams イベントのインポートのジョブの引数（ファイルはジョブの入力として保存する）
+type EventImportJobParams struct {
+	TeamID   *string `json:"teamId,omitempty"`
+	TimeZone string  `json:"tz,omitempty"`
+	DryRun   bool    `json:"dryRun"`
+}
+
+// TaskMergeJobParams 重複タスクの統合のジョブの引数
+type TaskMergeJobParams struct {
+	TargetTaskID string `json:"targetTaskId"`
+	SourceTaskID string `json:"sourceTaskId"`
+}
+
+// BackupExportJobParams バックアップの作成のジョブの引数
+type BackupExportJobParams struct {
+	TeamID *string `json:"teamId,omitempty"` // 指定時はチームのデータのみ（空の場合はサーバー全体）
+}
+
+// EventExportJob 閲覧できるイベントを.icsファイルにするジョブ（ExportICS と同じ内容）
+func EventExportJob(events *EventService) JobRunner {
+	return func(job *models.Job, _ io.Reader, progress func(int)) (*JobOutput, error) {
+		var params EventExportJobParams
+		if err := DecodeJobParams(job, &params); err != nil {
+			return nil, err
+		}
+		loc, err := jobLocation(params.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		data, err := events.ExportICS(job.UserID, ExportOptions{TeamID: params.TeamID, Location: loc})
+		if err != nil {
+			return nil, err
+		}
+		progress(90)
+
+		filename := "events.ics"
+		if params.TeamID != nil {
+			filename = "team-" + *params.TeamID + ".ics"
+		}
+		return &JobOutput{File: data, FileName: filename, ContentType: "text/calendar; charset=utf-8"}, nil
+	}
+}
+
+// EventImportJob アップロードされた.icsファイルからイベントを取り込むジョブ（結果は ImportReport）
+func EventImportJob(events *EventService) JobRunner {
+	return func(job *models.Job, input io.Reader, progress func(int)) (*JobOutput, error) {
+		if input == nil {
+			return nil, fmt.Errorf("%w: インポートするファイルがありません", ErrInvalidInput)
+		}
+		var params EventImportJobParams
+		if err := DecodeJobParams(job, &params); err != nil {
+			return nil, err
+		}
+		loc, err := jobLocation(params.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		report, err := events.ImportICS(job.UserID, input, ImportOptions{TeamID: params.TeamID, DryRun: params.DryRun, Location: loc})
+		if err != nil {
+			return nil, err
+		}
+		return &JobOutput{Result: report}, nil
+	}
+}
+
+// TaskMergeJob 重複タスクを統合するジョブ（結果は統合後のタスク）
+func TaskMergeJob(tasks *TaskService) JobRunner {
+	return func(job *models.Job, _ io.Reader, progress func(int)) (*JobOutput, error) {
+		var params TaskMergeJobParams
+		if err := DecodeJobParams(job, &params); err != nil {
+			return nil, err
+		}
+		task, err := tasks.MergeTask(params.TargetTaskID, params.SourceTaskID, job.UserID)
+		if err != nil {
+			return nil, err
+		}
+		return &JobOutput{Result: task}, nil
+	}
+}
+
+// BackupExportJob チームまたはサーバー全体のデータのアーカイブ（zip）を作成するジョブ
+func BackupExportJob(backups *BackupService) JobRunner {
+	return func(job *models.Job, _ io.Reader, progress func(int)) (*JobOutput, error) {
+		var params BackupExportJobParams
+		if err := DecodeJobParams(job, &params); err != nil {
+			return nil, err
+		}
+		filename, write, err := backups.Export(params.TeamID, progress)
+		if err != nil {
+			return nil, err
+		}
+		return &JobOutput{WriteFile: write, FileName: filename, ContentType: "application/zip"}, nil
+	}
+}
+
+// BackupRestoreJob アップロードされたアーカイブからデータを復元するジョブ（結果は BackupRestoreReport）
+func BackupRestoreJob(backups *BackupService) JobRunner {
+	return func(job *models.Job, input io.Reader, progress func(int)) (*JobOutput, error) {
+		if input == nil {
+			return nil, fmt.Errorf("%w: 復元するアーカイブがありません", ErrInvalidInput)
+		}
+		report, err := backups.Restore(input, progress)
+		if err != nil {
+			return nil, err
+		}
+		return &JobOutput{Result: report}, nil
+	}
+}
+
+func jobLocation(tz string) (*time.Location, error) {
+	if tz == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("%w: tzが不正です", ErrInvalidInput)
+	}
+	return loc, nil
+}
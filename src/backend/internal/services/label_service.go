@@ -0,0 +1,202 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// LabelInput ラベルの作成・更新
+type LabelInput struct {
+	Name  string `json:"name" binding:"required,notblank,max=50"`
+	Color string `json:"color" binding:"omitempty,color"`
+}
+
+// EventLabelsInput イベントのタグの設定（指定したラベルで置き換える、空で解除）
+type EventLabelsInput struct {
+	LabelIDs []string `json:"labelIds"`
+}
+
+// TaskLabelsInput タスクのタグの設定（指定したラベルで置き換える、空で解除）
+type TaskLabelsInput struct {
+	LabelIDs []string `json:"labelIds"`
+}
+
+type LabelService struct {
+	db *gorm.DB
+}
+
+func NewLabelService(db *gorm.DB) *LabelService {
+	return &LabelService{db: db}
+}
+
+// ListLabels チームのラベル一覧
+func (s *LabelService) ListLabels(teamID, userID string, sort SortOptions) ([]models.Label, error) {
+	if err := ensureTeamMember(s.db, teamID, userID); err != nil {
+		return nil, err
+	}
+	var labels []models.Label
+	if err := orderBy(s.db.Where("team_id = ?", teamID), sort, "name ASC").Find(&labels).Error; err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// CreateLabel ラベルを登録する（チームのメンバー）
+func (s *LabelService) CreateLabel(teamID, userID string, input LabelInput) (*models.Label, error) {
+	if err := ensureTeamMember(s.db, teamID, userID); err != nil {
+		return nil, err
+	}
+	label := models.Label{TeamID: teamID}
+	if err := s.applyLabelInput(&label, input); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&label).Error; err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+// UpdateLabel ラベルの名前・色を更新する（チームのメンバー）
+func (s *LabelService) UpdateLabel(labelID, userID string, input LabelInput) (*models.Label, error) {
+	label, err := s.findLabel(labelID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyLabelInput(label, input); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(label).Error; err != nil {
+		return nil, err
+	}
+	return label, nil
+}
+
+// DeleteLabel ラベルを削除する（イベント・タスクのタグからも外れる、チーム管理者のみ）
+func (s *LabelService) DeleteLabel(labelID, userID string) error {
+	label, err := s.findLabel(labelID, userID)
+	if err != nil {
+		return err
+	}
+	admin, err := isTeamAdmin(s.db, label.TeamID, userID)
+	if err != nil {
+		return err
+	}
+	if !admin {
+		return ErrForbidden
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM event_labels WHERE label_id = ?", label.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM task_labels WHERE label_id = ?", label.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(label).Error
+	})
+}
+
+// SetEventLabels イベントのタグを設定する（イベントと同じチームのラベルのみ）
+func (s *LabelService) SetEventLabels(eventID, userID string, input EventLabelsInput) (*models.Event, error) {
+	event, err := findEditableEvent(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	labelIDs := uniqueStrings(input.LabelIDs)
+	var labels []models.Label
+	if len(labelIDs) > 0 {
+		if event.TeamID == nil {
+			return nil, fmt.Errorf("%w: タグはチームのイベントにのみ設定できます", ErrInvalidInput)
+		}
+		if err := s.db.Where("id IN ? AND team_id = ?", labelIDs, *event.TeamID).Find(&labels).Error; err != nil {
+			return nil, err
+		}
+		if len(labels) != len(labelIDs) {
+			return nil, fmt.Errorf("%w: ラベルはイベントと同じチームのものを指定してください", ErrInvalidInput)
+		}
+	}
+
+	if err := s.db.Model(event).Association("Labels").Replace(labels); err != nil {
+		return nil, err
+	}
+	if err := s.db.Preload("Labels", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
+	}).First(event, "id = ?", event.ID).Error; err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// SetTaskLabels タスクのタグを設定する（タスクと同じチームのラベルのみ）
+func (s *LabelService) SetTaskLabels(taskID, userID string, input TaskLabelsInput) (*models.Task, error) {
+	task, err := findTaskForMember(s.db, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	labelIDs := uniqueStrings(input.LabelIDs)
+	var labels []models.Label
+	if len(labelIDs) > 0 {
+		if err := s.db.Where("id IN ? AND team_id = ?", labelIDs, task.TeamID).Find(&labels).Error; err != nil {
+			return nil, err
+		}
+		if len(labels) != len(labelIDs) {
+			return nil, fmt.Errorf("%w: ラベルはタスクと同じチームのものを指定してください", ErrInvalidInput)
+		}
+	}
+
+	if err := s.db.Model(task).Association("Labels").Replace(labels); err != nil {
+		return nil, err
+	}
+	if err := s.db.Preload("Labels", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
+	}).First(task, "id = ?", task.ID).Error; err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// findLabel ラベルを取得し、ユーザーがそのチームのメンバーか確認する
+func (s *LabelService) findLabel(labelID, userID string) (*models.Label, error) {
+	var label models.Label
+	if err := s.db.First(&label, "id = ?", labelID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if err := ensureTeamMember(s.db, label.TeamID, userID); err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+func (s *LabelService) applyLabelInput(label *models.Label, input LabelInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return fmt.Errorf("%w: nameは必須です", ErrInvalidInput)
+	}
+	color, err := models.NormalizeColor(input.Color)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	var count int64
+	if err := s.db.Model(&models.Label{}).
+		Where("team_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", label.TeamID, name, label.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: 同じ名前のラベルが既にあります", ErrInvalidInput)
+	}
+
+	label.Name = name
+	label.Color = color
+	return nil
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"time"
+
+	"task-calendar-backend/internal/models"
+)
+
+// EmailMessage 送信するメールの内容
+type EmailMessage struct {
+	To       string
+	ReplyTo  string
+	Subject  string
+	Text     string
+	HTML     string         // HTML版の本文（空の場合はテキストのみ）
+	Calendar *EmailCalendar // 招待などのiCalendar（本文の別形式と添付ファイルの両方で送る）
+}
+
+// EmailCalendar メールに含めるiCalendar（iMIP、RFC 6047）
+type EmailCalendar struct {
+	Method string // REQUEST / CANCEL など（VCALENDARのMETHODと同じ値）
+	Data   []byte
+}
+
+// Mailer メールの送信先
+type Mailer interface {
+	Send(msg EmailMessage) error
+}
+
+// LogMailer メールをログに出力するだけのMailer実装
+type LogMailer struct{}
+
+func (LogMailer) Send(msg EmailMessage) error {
+	log.Printf("メール to=%s %s", msg.To, msg.Subject)
+	return nil
+}
+
+// SMTPMailer SMTPサーバー経由でメールを送信する
+type SMTPMailer struct {
+	addr     string
+	auth     smtp.Auth
+	from     string // Fromヘッダー（表示名付き可）
+	envelope string // SMTPのMAIL FROMに使うアドレス
+}
+
+// NewSMTPMailer SMTPサーバーのホスト・ポートと送信元アドレスを指定して作成する（usernameが空の場合は認証しない）
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	m := &SMTPMailer{addr: net.JoinHostPort(host, fmt.Sprint(port)), from: from, envelope: from}
+	if addr, err := mail.ParseAddress(from); err == nil {
+		m.envelope = addr.Address
+	}
+	if username != "" {
+		m.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return m
+}
+
+func (m *SMTPMailer) Send(msg EmailMessage) error {
+	body, err := buildEmail(m.from, msg)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(m.addr, m.auth, m.envelope, []string{msg.To}, body)
+}
+
+// QueuedMailer メールを非同期ジョブ（email.send）として送る
+//
+// 送信待ちのメールはデータベースに保存するため、サーバーを再起動しても失われない。
+// 一時的なエラー（接続エラーやSMTPの4xx応答）で失敗した場合は、間隔を倍にしながら attempts 回まで再送する。
+// 宛先の誤りなど恒久的なエラー（SMTPの5xx応答）は再送しない。
+type QueuedMailer struct {
+	jobs     *JobService
+	attempts int // 最初の送信を含めた最大の送信回数
+}
+
+// NewQueuedMailer mailer は実際に送信するMailer（ジョブの実行時に使う）
+func NewQueuedMailer(jobs *JobService, mailer Mailer, attempts int) *QueuedMailer {
+	jobs.Register(models.JobTypeEmailSend, func(job *models.Job, _ io.Reader, _ func(int)) (*JobOutput, error) {
+		var msg EmailMessage
+		if err := DecodeJobParams(job, &msg); err != nil {
+			return nil, PermanentJobError(err)
+		}
+		if err := mailer.Send(msg); err != nil {
+			if !isTemporaryMailError(err) {
+				return nil, PermanentJobError(err)
+			}
+			return nil, err
+		}
+		return nil, nil
+	})
+	return &QueuedMailer{jobs: jobs, attempts: attempts}
+}
+
+func (m *QueuedMailer) Send(msg EmailMessage) error {
+	return m.jobs.EnqueueBackground(m.jobs.db, models.JobTypeEmailSend, msg, m.attempts)
+}
+
+// isTemporaryMailError 再送すれば成功する可能性のあるエラーか（接続エラーやSMTPの4xx応答）
+func isTemporaryMailError(err error) bool {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code < 500
+	}
+	return true
+}
+
+// buildEmail メールをMIME形式で組み立てる
+//
+// 本文はテキストのみの場合を除き multipart/alternative（text/plain、text/html、text/calendar の順）にする。
+// iCalendarがある場合はさらに multipart/mixed の中に本文と invite.ics の添付を入れる。
+// カレンダーアプリは本文の text/calendar、それ以外のメーラーは添付を使う。
+func buildEmail(from string, msg EmailMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", from)
+	header("To", msg.To)
+	if msg.ReplyTo != "" {
+		header("Reply-To", msg.ReplyTo)
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	if msg.HTML == "" && msg.Calendar == nil {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "base64")
+		buf.WriteString("\r\n")
+		writeBase64(&buf, []byte(msg.Text))
+		return buf.Bytes(), nil
+	}
+
+	var altBuf bytes.Buffer
+	alternative := multipart.NewWriter(&altBuf)
+	if err := writePart(alternative, "text/plain; charset=utf-8", "", []byte(msg.Text)); err != nil {
+		return nil, err
+	}
+	if msg.HTML != "" {
+		if err := writePart(alternative, "text/html; charset=utf-8", "", []byte(msg.HTML)); err != nil {
+			return nil, err
+		}
+	}
+	if msg.Calendar != nil {
+		calendarType := fmt.Sprintf("text/calendar; charset=utf-8; method=%s", msg.Calendar.Method)
+		if err := writePart(alternative, calendarType, "", msg.Calendar.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := alternative.Close(); err != nil {
+		return nil, err
+	}
+	alternativeType := "multipart/alternative; boundary=" + alternative.Boundary()
+
+	if msg.Calendar == nil {
+		header("Content-Type", alternativeType)
+		buf.WriteString("\r\n")
+		buf.Write(altBuf.Bytes())
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	buf.WriteString("\r\n")
+
+	altHeader := textproto.MIMEHeader{}
+	altHeader.Set("Content-Type", alternativeType)
+	part, err := mixed.CreatePart(altHeader)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(altBuf.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := writePart(mixed, "application/ics; name=invite.ics", "attachment; filename=invite.ics", msg.Calendar.Data); err != nil {
+		return nil, err
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writePart(w *multipart.Writer, contentType, disposition string, data []byte) error {
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Transfer-Encoding", "base64")
+	if disposition != "" {
+		h.Set("Content-Disposition", disposition)
+	}
+	part, err := w.CreatePart(h)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	writeBase64(&buf, data)
+	_, err = part.Write(buf.Bytes())
+	return err
+}
+
+// writeBase64 76文字ごとに改行したBase64を書き出す
+func writeBase64(buf *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+}
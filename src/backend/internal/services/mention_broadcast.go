@@ -0,0 +1,196 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// broadcastMentionNames チーム全員へのメンション（@here は @team と同じく、アクティブなメンバー全員に通知する）
+var broadcastMentionNames = map[string]bool{"team": true, "here": true}
+
+// MentionSettings チームのメンションの設定
+type MentionSettings struct {
+	BroadcastPolicy models.MentionBroadcastPolicy `json:"broadcastPolicy"`
+}
+
+// MentionSettingsInput チームのメンションの設定の変更
+type MentionSettingsInput struct {
+	BroadcastPolicy models.MentionBroadcastPolicy `json:"broadcastPolicy" binding:"required,oneof=MEMBERS ADMINS DISABLED"`
+}
+
+// MentionBroadcastService コメントやタスクの説明の @team・@here で、チームのアクティブなメンバー全員に通知する
+//
+// 使えるメンバーはチームごとに管理者が設定する（既定はオーナーと管理者のみ）。
+type MentionBroadcastService struct {
+	db     *gorm.DB
+	router *NotificationRouter
+}
+
+func NewMentionBroadcastService(db *gorm.DB, router *NotificationRouter) *MentionBroadcastService {
+	return &MentionBroadcastService{db: db, router: router}
+}
+
+// GetSettings チームのメンションの設定（チームメンバーのみ）
+func (s *MentionBroadcastService) GetSettings(teamID, userID string) (*MentionSettings, error) {
+	if err := ensureTeamMember(s.db, teamID, userID); err != nil {
+		return nil, err
+	}
+	var team models.Team
+	if err := s.db.First(&team, "id = ?", teamID).Error; err != nil {
+		return nil, err
+	}
+	return &MentionSettings{BroadcastPolicy: mentionBroadcastPolicy(&team)}, nil
+}
+
+// UpdateSettings @team・@here を使えるメンバーを変更する（チームの管理者のみ）
+func (s *MentionBroadcastService) UpdateSettings(teamID, userID string, input MentionSettingsInput) (*MentionSettings, error) {
+	admin, err := isTeamAdmin(s.db, teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin {
+		return nil, ErrForbidden
+	}
+	policy := models.MentionBroadcastPolicy(strings.ToUpper(strings.TrimSpace(string(input.BroadcastPolicy))))
+	switch policy {
+	case models.MentionBroadcastPolicyMembers, models.MentionBroadcastPolicyAdmins, models.MentionBroadcastPolicyDisabled:
+	default:
+		return nil, fmt.Errorf("%w: broadcastPolicyはMEMBERS・ADMINS・DISABLEDのいずれかで指定してください", ErrInvalidInput)
+	}
+	if err := s.db.Model(&models.Team{}).Where("id = ?", teamID).
+		Update("mention_broadcast_policy", policy).Error; err != nil {
+		return nil, err
+	}
+	return &MentionSettings{BroadcastPolicy: policy}, nil
+}
+
+// RegisterCallbacks タスクの説明に新たに書かれた @team・@here を通知するgormのコールバックを登録する
+//
+// 使う権限のないユーザーによる保存はエラーにする。保存を行うユーザーは models.WithActor で渡す
+// （渡されていない場合は、新規作成では作成者とし、更新では通知しない）。
+func (s *MentionBroadcastService) RegisterCallbacks() error {
+	callbacks := s.db.Callback()
+	if err := callbacks.Create().After("gorm:create").Before("gorm:after_create").
+		Register("mention:after_create", func(tx *gorm.DB) { s.taskDescriptionChanged(tx, true) }); err != nil {
+		return err
+	}
+	return callbacks.Update().After("gorm:update").Before("gorm:after_update").
+		Register("mention:after_update", func(tx *gorm.DB) { s.taskDescriptionChanged(tx, false) })
+}
+
+func (s *MentionBroadcastService) taskDescriptionChanged(tx *gorm.DB, created bool) {
+	if tx.Error != nil || tx.RowsAffected == 0 || !tx.Statement.ReflectValue.IsValid() {
+		return
+	}
+	value := reflect.Indirect(tx.Statement.ReflectValue)
+	if value.Kind() != reflect.Struct || !value.CanAddr() {
+		return
+	}
+	task, ok := value.Addr().Interface().(*models.Task)
+	if !ok || task.ID == "" {
+		return
+	}
+	if !hasBroadcastMention(task.Description) || hasBroadcastMention(task.LoadedDescription()) {
+		return
+	}
+	actorID := models.ActorID(tx)
+	if actorID == "" && created {
+		actorID = task.CreatorID
+	}
+	if actorID == "" {
+		return
+	}
+
+	db := tx.Session(&gorm.Session{NewDB: true})
+	if err := ensureCanBroadcastMention(db, task.TeamID, actorID); err != nil {
+		tx.AddError(err)
+		return
+	}
+	recipients, err := broadcastRecipients(db, task.TeamID, map[string]bool{actorID: true})
+	if err != nil {
+		tx.AddError(err)
+		return
+	}
+
+	title := fmt.Sprintf("「%s」の説明でチーム全員にメンションされました", task.Title)
+	msgs := make([]NotificationMessage, len(recipients))
+	for i, userID := range recipients {
+		msgs[i] = NotificationMessage{
+			UserID:     userID,
+			Type:       NotificationTypeMention,
+			Title:      title,
+			Body:       task.Description,
+			EntityType: "task",
+			EntityID:   task.ID,
+		}
+	}
+	// 保存と同じトランザクションで登録し、コミットされた後に配信する（ロールバックされた保存では通知しない）
+	if len(msgs) > 0 {
+		if err := s.router.enqueueRoute(tx, task.TeamID, msgs...); err != nil {
+			log.Printf("メンション通知の登録に失敗しました: %v", err)
+		}
+	}
+}
+
+// hasBroadcastMention 本文に @team・@here があるか（コード部分は除外）
+func hasBroadcastMention(content string) bool {
+	for _, name := range extractMentions(content) {
+		if broadcastMentionNames[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func mentionBroadcastPolicy(team *models.Team) models.MentionBroadcastPolicy {
+	if team.MentionBroadcastPolicy == "" {
+		return models.MentionBroadcastPolicyAdmins
+	}
+	return team.MentionBroadcastPolicy
+}
+
+// ensureCanBroadcastMention チームの設定で @team・@here を使えるか確認する
+func ensureCanBroadcastMention(db *gorm.DB, teamID, userID string) error {
+	var team models.Team
+	if err := db.First(&team, "id = ?", teamID).Error; err != nil {
+		return err
+	}
+	switch mentionBroadcastPolicy(&team) {
+	case models.MentionBroadcastPolicyMembers:
+		return ensureTeamMember(db, teamID, userID)
+	case models.MentionBroadcastPolicyAdmins:
+		admin, err := isTeamAdmin(db, teamID, userID)
+		if err != nil {
+			return err
+		}
+		if !admin {
+			return fmt.Errorf("%w: このチームで@team・@hereを使えるのはオーナーと管理者のみです", ErrForbidden)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: このチームでは@team・@hereは使えません", ErrForbidden)
+	}
+}
+
+// broadcastRecipients チーム全員へのメンションを通知するアクティブなメンバー（exclude のユーザーを除く）
+func broadcastRecipients(db *gorm.DB, teamID string, exclude map[string]bool) ([]string, error) {
+	var memberIDs []string
+	if err := db.Model(&models.TeamMember{}).
+		Where("team_id = ? AND status = ?", teamID, models.TeamMemberStatusActive).
+		Pluck("user_id", &memberIDs).Error; err != nil {
+		return nil, err
+	}
+	recipients := make([]string, 0, len(memberIDs))
+	for _, id := range memberIDs {
+		if !exclude[id] {
+			recipients = append(recipients, id)
+		}
+	}
+	return recipients, nil
+}
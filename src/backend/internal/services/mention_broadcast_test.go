@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// タスクの説明の @team は保存と同じトランザクションで通知のジョブに登録し、ロールバックされた保存では登録しない
+func TestTaskDescriptionMentionWaitsForCommit(t *testing.T) {
+	db := openTestDB(t)
+	seedTeam(t, db)
+	router := NewNotificationRouter(db, NewJobService(db, nil, "secret", "/api/v1/jobs"))
+	if err := NewMentionBroadcastService(db, router).RegisterCallbacks(); err != nil {
+		t.Fatal(err)
+	}
+	newTask := func() *models.Task {
+		return &models.Task{ID: "k1", Title: "Task", Description: "@team 確認してください", TeamID: "t1", CreatorID: "u1"}
+	}
+	countJobs := func() int64 {
+		var count int64
+		if err := db.Model(&models.Job{}).Where("type = ?", models.JobTypeNotificationRoute).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+
+	rollback := errors.New("rollback")
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := models.WithActor(tx, "u1").Create(newTask()).Error; err != nil {
+			return err
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("トランザクションのエラーが %v です", err)
+	}
+	if n := countJobs(); n != 0 {
+		t.Fatalf("ロールバックした保存のメンション通知が %d 件登録されています", n)
+	}
+
+	if err := models.WithActor(db, "u1").Create(newTask()).Error; err != nil {
+		t.Fatal(err)
+	}
+	var job models.Job
+	if err := db.First(&job, "type = ?", models.JobTypeNotificationRoute).Error; err != nil {
+		t.Fatalf("保存したメンションの通知が登録されていません: %v", err)
+	}
+	var params notificationRouteJobParams
+	if err := DecodeJobParams(&job, &params); err != nil {
+		t.Fatal(err)
+	}
+	if len(params.Messages) != 1 || params.Messages[0].UserID != "u2" || params.Messages[0].Type != NotificationTypeMention {
+		t.Errorf("通知が %+v です（u2 へのメンションのみのはず）", params.Messages)
+	}
+}
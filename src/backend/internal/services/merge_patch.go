@@ -0,0 +1,311 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// MergePatch JSON Merge Patch（RFC 7396）の本文。指定したキーのみ変更し、null は値を消す
+type MergePatch map[string]json.RawMessage
+
+// patchField マージパッチで変更できる項目
+type patchField struct {
+	column string
+	// null を指定した場合に保存する値（nullable でない項目に null は指定できない）
+	nullable bool
+	cleared  interface{}
+	decode   func(name string, value json.RawMessage) (interface{}, error)
+}
+
+// taskPatchFields タスクで変更できる項目（version は変更できない。編集を始めた時点のバージョンとの比較はサーバーが行う）
+var taskPatchFields = map[string]patchField{
+	"title":       {column: "title", decode: patchRequiredString},
+	"description": {column: "description", nullable: true, cleared: "", decode: patchString},
+	"status": {column: "status", decode: patchEnum(models.TaskStatusTodo, models.TaskStatusInProgress,
+		models.TaskStatusInReview, models.TaskStatusDone, models.TaskStatusCancelled)},
+	"priority": {column: "priority", decode: patchEnum(models.PriorityLow, models.PriorityMedium,
+		models.PriorityHigh, models.PriorityUrgent)},
+	"dueDate":    {column: "due_date", nullable: true, cleared: (*time.Time)(nil), decode: patchTime},
+	"assigneeId": {column: "assignee_id", nullable: true, cleared: (*string)(nil), decode: patchRequiredString},
+}
+
+// eventPatchFields イベントで変更できる項目（日時・繰り返し・会議室などは専用のAPIで変更する）
+var eventPatchFields = map[string]patchField{
+	"title":       {column: "title", decode: patchRequiredString},
+	"description": {column: "description", nullable: true, cleared: "", decode: patchString},
+	"location":    {column: "location", nullable: true, cleared: "", decode: patchString},
+	"color":       {column: "color", nullable: true, cleared: "", decode: patchString},
+	"categoryId":  {column: "category_id", nullable: true, cleared: (*string)(nil), decode: patchRequiredString},
+}
+
+// teamPatchFields チームで変更できる項目
+var teamPatchFields = map[string]patchField{
+	"name":                {column: "name", decode: patchRequiredString},
+	"description":         {column: "description", nullable: true, cleared: "", decode: patchString},
+	"blockEventConflicts": {column: "block_event_conflicts", decode: patchBool},
+	"holidayCountry":      {column: "holiday_country", nullable: true, cleared: "", decode: patchString},
+}
+
+// userPatchFields プロフィールで変更できる項目
+var userPatchFields = map[string]patchField{
+	"firstName":      {column: "first_name", decode: patchRequiredString},
+	"lastName":       {column: "last_name", decode: patchRequiredString},
+	"avatar":         {column: "avatar", nullable: true, cleared: "", decode: patchString},
+	"timeZone":       {column: "time_zone", nullable: true, cleared: "", decode: patchString},
+	"holidayCountry": {column: "holiday_country", nullable: true, cleared: "", decode: patchString},
+}
+
+// PatchService タスク・イベント・チーム・プロフィールの部分更新（PATCH）
+type PatchService struct {
+	db *gorm.DB
+}
+
+func NewPatchService(db *gorm.DB) *PatchService {
+	return &PatchService{db: db}
+}
+
+// PatchTask タスクを部分更新する（担当者はチームのメンバーのみ）
+//
+// expectedVersion は If-Match で確かめた編集を始めた時点のバージョン（0 の場合は読み込んだ時点のバージョン）。
+// 確かめた後に他の更新が割り込んでいた場合は ErrPreconditionFailed を返す。
+func (s *PatchService) PatchTask(taskID, userID string, patch MergePatch, expectedVersion int) (*models.Task, error) {
+	task, err := findTaskForMember(s.db, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	updates, err := patch.updates(taskPatchFields)
+	if err != nil {
+		return nil, err
+	}
+	if assigneeID, ok := updates["assignee_id"].(string); ok {
+		if err := ensureTeamMember(s.db, task.TeamID, assigneeID); err != nil {
+			if errors.Is(err, ErrForbidden) {
+				return nil, fmt.Errorf("%w: 担当者はチームのメンバーを指定してください", ErrInvalidInput)
+			}
+			return nil, err
+		}
+	}
+	if len(updates) > 0 {
+		if err := patchVersioned(s.db, userID, expectedVersion).Model(task).Updates(updates).Error; err != nil {
+			return nil, patchVersionError(err, expectedVersion)
+		}
+	}
+	if err := s.db.Preload("Assignee").First(task, "id = ?", task.ID).Error; err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// PatchEvent イベントを部分更新する（作成者・チーム管理者のみ。expectedVersion は PatchTask と同じ）
+func (s *PatchService) PatchEvent(eventID, userID string, patch MergePatch, expectedVersion int) (*models.Event, error) {
+	event, err := findEditableEvent(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	updates, err := patch.updates(eventPatchFields)
+	if err != nil {
+		return nil, err
+	}
+	if color, ok := updates["color"].(string); ok {
+		if updates["color"], err = models.NormalizeColor(color); err != nil {
+			return nil, err
+		}
+	}
+	if categoryID, ok := updates["category_id"].(string); ok {
+		var category models.EventCategory
+		err := s.db.First(&category, "id = ?", categoryID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: カテゴリーが見つかりません", ErrInvalidInput)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if event.TeamID == nil || *event.TeamID != category.TeamID {
+			return nil, fmt.Errorf("%w: カテゴリーはイベントと同じチームのものを指定してください", ErrInvalidInput)
+		}
+	}
+	if len(updates) > 0 {
+		if err := patchVersioned(s.db, userID, expectedVersion).Model(event).Updates(updates).Error; err != nil {
+			return nil, patchVersionError(err, expectedVersion)
+		}
+	}
+	if err := s.db.Preload("Category").First(event, "id = ?", event.ID).Error; err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// PatchTeam チームを部分更新する（オーナー・管理者のみ）
+func (s *PatchService) PatchTeam(teamID, userID string, patch MergePatch) (*models.Team, error) {
+	var team models.Team
+	if err := s.db.First(&team, "id = ?", teamID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	admin, err := isTeamAdmin(s.db, teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin {
+		return nil, fmt.Errorf("%w: チームを変更できるのはオーナーと管理者のみです", ErrForbidden)
+	}
+	updates, err := patch.updates(teamPatchFields)
+	if err != nil {
+		return nil, err
+	}
+	if country, ok := updates["holiday_country"].(string); ok {
+		if updates["holiday_country"], err = normalizeHolidayCountry(country); err != nil {
+			return nil, err
+		}
+	}
+	if len(updates) > 0 {
+		if err := models.WithActor(s.db, userID).Model(&team).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &team, nil
+}
+
+// PatchUser 自分のプロフィールを部分更新する
+func (s *PatchService) PatchUser(userID string, patch MergePatch) (*models.User, error) {
+	var user models.User
+	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	updates, err := patch.updates(userPatchFields)
+	if err != nil {
+		return nil, err
+	}
+	if tz, ok := updates["time_zone"].(string); ok {
+		if _, err := models.LoadLocation(tz); err != nil {
+			return nil, err
+		}
+	}
+	if country, ok := updates["holiday_country"].(string); ok {
+		if updates["holiday_country"], err = normalizeHolidayCountry(country); err != nil {
+			return nil, err
+		}
+	}
+	if len(updates) > 0 {
+		if err := s.db.Model(&user).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &user, nil
+}
+
+// patchVersioned 変更者と、指定された場合は比べるバージョンを渡したDB
+func patchVersioned(db *gorm.DB, userID string, expectedVersion int) *gorm.DB {
+	db = models.WithActor(db, userID)
+	if expectedVersion > 0 {
+		db = models.WithExpectedVersion(db, expectedVersion)
+	}
+	return db
+}
+
+// patchVersionError If-Match で確かめたバージョンが更新の時点で古くなっていた場合は ErrPreconditionFailed にする
+func patchVersionError(err error, expectedVersion int) error {
+	if expectedVersion > 0 && errors.Is(err, models.ErrVersionConflict) {
+		return ErrPreconditionFailed
+	}
+	return err
+}
+
+// updates パッチを更新する列と値にする（変更できない項目や型の合わない値はエラー）
+func (p MergePatch) updates(fields map[string]patchField) (map[string]interface{}, error) {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	updates := make(map[string]interface{}, len(p))
+	for _, name := range names {
+		field, ok := fields[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %sは変更できません", ErrInvalidInput, name)
+		}
+		value := p[name]
+		if string(value) == "null" {
+			if !field.nullable {
+				return nil, fmt.Errorf("%w: %sにnullは指定できません", ErrInvalidInput, name)
+			}
+			updates[field.column] = field.cleared
+			continue
+		}
+		decoded, err := field.decode(name, value)
+		if err != nil {
+			return nil, err
+		}
+		updates[field.column] = decoded
+	}
+	return updates, nil
+}
+
+func patchString(name string, value json.RawMessage) (interface{}, error) {
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return nil, fmt.Errorf("%w: %sは文字列で指定してください", ErrInvalidInput, name)
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// patchRequiredString 空にできない文字列（消す場合は null を指定する）
+func patchRequiredString(name string, value json.RawMessage) (interface{}, error) {
+	s, err := patchString(name, value)
+	if err != nil {
+		return nil, err
+	}
+	if s == "" {
+		return nil, fmt.Errorf("%w: %sを空にはできません", ErrInvalidInput, name)
+	}
+	return s, nil
+}
+
+func patchBool(name string, value json.RawMessage) (interface{}, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err != nil {
+		return nil, fmt.Errorf("%w: %sはtrueまたはfalseで指定してください", ErrInvalidInput, name)
+	}
+	return b, nil
+}
+
+func patchTime(name string, value json.RawMessage) (interface{}, error) {
+	var t time.Time
+	if err := json.Unmarshal(value, &t); err != nil {
+		return nil, fmt.Errorf("%w: %sはRFC3339形式で指定してください", ErrInvalidInput, name)
+	}
+	return &t, nil
+}
+
+// patchEnum 決められた値のいずれかの文字列
+func patchEnum[T ~string](values ...T) func(string, json.RawMessage) (interface{}, error) {
+	return func(name string, value json.RawMessage) (interface{}, error) {
+		var s string
+		if err := json.Unmarshal(value, &s); err == nil {
+			for _, v := range values {
+				if string(v) == s {
+					return v, nil
+				}
+			}
+		}
+		allowed := make([]string, len(values))
+		for i, v := range values {
+			allowed[i] = string(v)
+		}
+		return nil, fmt.Errorf("%w: %sは%sのいずれかで指定してください", ErrInvalidInput, name, strings.Join(allowed, "・"))
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"task-calendar-backend/internal/models"
+)
+
+// 同じバージョン（If-Match で確かめたバージョン）をもとにした2つの更新を同時に行うと、一方のみ成功し、もう一方は上書きせずに ErrPreconditionFailed になる
+func TestPatchTaskConcurrentUpdates(t *testing.T) {
+	db := openTestDB(t)
+	seedTeam(t, db)
+	execAll(t, db, `INSERT INTO tasks (id, title, team_id, creator_id) VALUES ('k1', 'Task', 't1', 'u1')`)
+	s := NewPatchService(db)
+
+	titles := []string{"A", "B"}
+	errs := make([]error, len(titles))
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i, title := range titles {
+		wg.Add(1)
+		go func(i int, title string) {
+			defer wg.Done()
+			<-start
+			_, errs[i] = s.PatchTask("k1", "u1", MergePatch{"title": json.RawMessage(`"` + title + `"`)}, 1)
+		}(i, title)
+	}
+	close(start)
+	wg.Wait()
+
+	winner := ""
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			if winner != "" {
+				t.Fatal("同じバージョンをもとにした更新が両方とも成功しました")
+			}
+			winner = titles[i]
+		case !errors.Is(err, ErrPreconditionFailed):
+			t.Errorf("%s の更新のエラーが %v です（ErrPreconditionFailed のはず）", titles[i], err)
+		}
+	}
+	if winner == "" {
+		t.Fatal("どちらの更新も成功しませんでした")
+	}
+
+	var task models.Task
+	if err := db.First(&task, "id = ?", "k1").Error; err != nil {
+		t.Fatal(err)
+	}
+	if task.Title != winner || task.Version != 2 {
+		t.Errorf("タスクが %q（バージョン %d）です（%q・バージョン2のはず）", task.Title, task.Version, winner)
+	}
+}
+
+func TestMergePatchUpdates(t *testing.T) {
+	due := time.Date(2026, time.April, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		patch   string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:  "指定したキーのみ変更する",
+			patch: `{"title":" 新しい件名 ","priority":"HIGH","dueDate":"2026-04-01T09:00:00Z"}`,
+			want:  map[string]interface{}{"title": "新しい件名", "priority": models.PriorityHigh, "due_date": &due},
+		},
+		{
+			name:  "null は値を消す",
+			patch: `{"description":null,"dueDate":null,"assigneeId":null}`,
+			want:  map[string]interface{}{"description": "", "due_date": (*time.Time)(nil), "assignee_id": (*string)(nil)},
+		},
+		{name: "空のパッチは何も変更しない", patch: `{}`, want: map[string]interface{}{}},
+		{name: "nullable でない項目に null は指定できない", patch: `{"title":null}`, wantErr: true},
+		{name: "未知のキー", patch: `{"title":"A","foo":"bar"}`, wantErr: true},
+		{name: "読み取り専用のキー（version）", patch: `{"version":5}`, wantErr: true},
+		{name: "読み取り専用のキー（id）", patch: `{"id":"other"}`, wantErr: true},
+		{name: "読み取り専用のキー（creatorId）", patch: `{"creatorId":"u2"}`, wantErr: true},
+		// 変更できる項目はすべて値なので、入れ子のオブジェクトをマージせずに拒否する
+		{name: "入れ子のオブジェクト", patch: `{"title":{"ja":"件名"}}`, wantErr: true},
+		{name: "入れ子のオブジェクト（null を含む）", patch: `{"description":{"text":null}}`, wantErr: true},
+		{name: "空にできない文字列", patch: `{"title":"  "}`, wantErr: true},
+		{name: "決められた値以外", patch: `{"status":"UNKNOWN"}`, wantErr: true},
+		{name: "日時の形式", patch: `{"dueDate":"2026/04/01"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch MergePatch
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatal(err)
+			}
+			got, err := patch.updates(taskPatchFields)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Fatalf("エラーが %v です（ErrInvalidInput のはず）", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("更新内容が %#v です（%#v のはず）", got, tt.want)
+			}
+		})
+	}
+}
+
+// 指定しなかった項目はそのまま残り、拒否したパッチは一部だけ適用されることもない
+func TestPatchTaskMerge(t *testing.T) {
+	db := openTestDB(t)
+	seedTeam(t, db)
+	execAll(t, db, `INSERT INTO tasks (id, title, description, priority, due_date, assignee_id, team_id, creator_id) `+
+		`VALUES ('k1', 'Task', 'メモ', 'HIGH', '2026-04-01 09:00:00', 'u2', 't1', 'u1')`)
+	s := NewPatchService(db)
+
+	for _, body := range []string{`{"title":"A","version":5}`, `{"title":"A","dueDate":{"date":"2026-05-01"}}`} {
+		var patch MergePatch
+		if err := json.Unmarshal([]byte(body), &patch); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.PatchTask("k1", "u1", patch, 0); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s のエラーが %v です（ErrInvalidInput のはず）", body, err)
+		}
+	}
+
+	patch := MergePatch{"title": json.RawMessage(`"更新後"`), "description": json.RawMessage(`null`), "dueDate": json.RawMessage(`null`)}
+	task, err := s.PatchTask("k1", "u1", patch, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Title != "更新後" || task.Description != "" || task.DueDate != nil {
+		t.Errorf("変更した項目が %q・%q・%v です", task.Title, task.Description, task.DueDate)
+	}
+	if task.Priority != models.PriorityHigh || task.AssigneeID == nil || *task.AssigneeID != "u2" {
+		t.Errorf("指定しなかった項目が変わりました: 優先度 %s・担当者 %v", task.Priority, task.AssigneeID)
+	}
+	if task.Version != 2 {
+		t.Errorf("バージョンが %d です（拒否したパッチは数えず2のはず）", task.Version)
+	}
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+)
+
+const (
+	microsoftGraphURL = "https://graph.microsoft.com/v1.0"
+	microsoftScopes   = "offline_access User.Read Calendars.Read"
+	// 取り込む期間（calendarViewの差分取得は期間の指定が必要）
+	microsoftSyncPast   = 30 * 24 * time.Hour
+	microsoftSyncFuture = 365 * 24 * time.Hour
+)
+
+// MicrosoftCalendarProvider Microsoft Graph API による Microsoft 365 / Outlook カレンダー連携
+type MicrosoftCalendarProvider struct {
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	authority    string
+	client       *http.Client
+}
+
+func NewMicrosoftCalendarProvider(clientID, clientSecret, redirectURL, tenant string) *MicrosoftCalendarProvider {
+	if tenant == "" {
+		tenant = "common"
+	}
+	return &MicrosoftCalendarProvider{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		authority:    "https://login.microsoftonline.com/" + url.PathEscape(tenant) + "/oauth2/v2.0",
+		client:       &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *MicrosoftCalendarProvider) Provider() models.CalendarProvider {
+	return models.CalendarProviderMicrosoft
+}
+
+func (p *MicrosoftCalendarProvider) AuthURL(state string) string {
+	query := url.Values{
+		"client_id":     {p.clientID},
+		"response_type": {"code"},
+		"redirect_uri":  {p.redirectURL},
+		"response_mode": {"query"},
+		"scope":         {microsoftScopes},
+		"state":         {state},
+	}
+	return p.authority + "/authorize?" + query.Encode()
+}
+
+func (p *MicrosoftCalendarProvider) Exchange(ctx context.Context, code string) (*OAuthToken, error) {
+	return p.requestToken(ctx, url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {p.redirectURL},
+	})
+}
+
+func (p *MicrosoftCalendarProvider) Refresh(ctx context.Context, refreshToken string) (*OAuthToken, error) {
+	return p.requestToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+func (p *MicrosoftCalendarProvider) requestToken(ctx context.Context, form url.Values) (*OAuthToken, error) {
+	form.Set("client_id", p.clientID)
+	form.Set("client_secret", p.clientSecret)
+	form.Set("scope", microsoftScopes)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.authority+"/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		RefreshToken     string `json:"refresh_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return nil, fmt.Errorf("トークンの応答を解析できません（HTTP %d）", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return nil, fmt.Errorf("%w: トークンを取得できませんでした: %s %s", ErrInvalidInput, body.Error, body.ErrorDescription)
+	}
+	return &OAuthToken{
+		AccessToken:  body.AccessToken,
+		RefreshToken: body.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(body.ExpiresIn) * time.Second),
+	}, nil
+}
+
+func (p *MicrosoftCalendarProvider) Account(ctx context.Context, accessToken string) (*ExternalAccount, error) {
+	var me struct {
+		ID                string `json:"id"`
+		Mail              string `json:"mail"`
+		UserPrincipalName string `json:"userPrincipalName"`
+	}
+	if err := p.get(ctx, accessToken, microsoftGraphURL+"/me?$select=id,mail,userPrincipalName", &me); err != nil {
+		return nil, err
+	}
+	email := me.Mail
+	if email == "" {
+		email = me.UserPrincipalName
+	}
+	return &ExternalAccount{ID: me.ID, Email: email}, nil
+}
+
+// graphEvent calendarView の差分取得で返るイベント
+type graphEvent struct {
+	ID          string `json:"id"`
+	ICalUID     string `json:"iCalUId"`
+	Subject     string `json:"subject"`
+	BodyPreview string `json:"bodyPreview"`
+	IsCancelled bool   `json:"isCancelled"`
+	IsAllDay    bool   `json:"isAllDay"`
+	Start       struct {
+		DateTime string `json:"dateTime"`
+	} `json:"start"`
+	End struct {
+		DateTime string `json:"dateTime"`
+	} `json:"end"`
+	Removed *struct {
+		Reason string `json:"reason"`
+	} `json:"@removed"`
+}
+
+// FetchChanges calendarView/delta で変更を取得する（繰り返しは個々の回に展開された状態で返る）
+func (p *MicrosoftCalendarProvider) FetchChanges(ctx context.Context, accessToken, syncToken string) (*ExternalChanges, error) {
+	changes := &ExternalChanges{FullSync: syncToken == ""}
+
+	next := syncToken
+	if next == "" {
+		now := time.Now().UTC()
+		query := url.Values{
+			"startDateTime": {now.Add(-microsoftSyncPast).Format(time.RFC3339)},
+			"endDateTime":   {now.Add(microsoftSyncFuture).Format(time.RFC3339)},
+		}
+		next = microsoftGraphURL + "/me/calendarView/delta?" + query.Encode()
+	}
+
+	for next != "" {
+		var page struct {
+			Value     []graphEvent `json:"value"`
+			NextLink  string       `json:"@odata.nextLink"`
+			DeltaLink string       `json:"@odata.deltaLink"`
+		}
+		if err := p.get(ctx, accessToken, next, &page); err != nil {
+			return nil, err
+		}
+
+		for _, e := range page.Value {
+			if e.Removed != nil || e.IsCancelled {
+				changes.Events = append(changes.Events, ExternalEvent{ExternalID: e.ID, Removed: true})
+				continue
+			}
+			start, err := parseGraphDateTime(e.Start.DateTime)
+			if err != nil {
+				continue
+			}
+			end, err := parseGraphDateTime(e.End.DateTime)
+			if err != nil {
+				continue
+			}
+			if e.IsAllDay {
+				// 終日イベントの日時はイベントのタイムゾーンでの0時で返るため、日付のみを使う
+				start, end = models.NormalizeAllDayRange(start, end)
+			}
+			title := e.Subject
+			if title == "" {
+				title = "（無題）"
+			}
+			changes.Events = append(changes.Events, ExternalEvent{
+				ExternalID:  e.ID,
+				ICalUID:     e.ICalUID,
+				Title:       title,
+				Description: e.BodyPreview,
+				StartDate:   start,
+				EndDate:     end,
+				AllDay:      e.IsAllDay,
+			})
+		}
+
+		next = page.NextLink
+		if page.DeltaLink != "" {
+			changes.NextSyncToken = page.DeltaLink
+		}
+	}
+	return changes, nil
+}
+
+func (p *MicrosoftCalendarProvider) get(ctx context.Context, accessToken, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	// 日時はUTCで受け取る
+	req.Header.Set("Prefer", `outlook.timezone="UTC", odata.maxpagesize=100`)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusGone:
+		return ErrSyncTokenExpired
+	case resp.StatusCode != http.StatusOK:
+		var body struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body)
+		if body.Error.Code == "SyncStateNotFound" || body.Error.Code == "syncStateNotFound" {
+			return ErrSyncTokenExpired
+		}
+		return fmt.Errorf("Microsoft Graph APIの呼び出しに失敗しました（HTTP %d %s）", resp.StatusCode, body.Error.Code)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errors.New("Microsoft Graph APIの応答を解析できません")
+	}
+	return nil
+}
+
+func parseGraphDateTime(value string) (time.Time, error) {
+	// 例: 2024-03-05T09:00:00.0000000（Preferヘッダーにより常にUTC）
+	return time.ParseInLocation("2006-01-02T15:04:05.9999999", value, time.UTC)
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+)
+
+// teamsWebhookHosts Microsoft Teams のIncoming Webhook・Power AutomateのWorkflowsのURLのホスト（サブドメインを含む）
+var teamsWebhookHosts = []string{
+	"webhook.office.com",
+	"outlook.office.com",
+	"outlook.office365.com",
+	"logic.azure.com",
+	"api.powerplatform.com",
+}
+
+// TeamsWebhookPoster Microsoft Teams のチャンネルのIncoming Webhookに、アダプティブカードを投稿する
+//
+// 登録できるのは Microsoft Teams・Power Automate のホストのみで、名前解決した結果が内部のアドレスの場合も送信しない。
+type TeamsWebhookPoster struct {
+	client *http.Client
+}
+
+func NewTeamsWebhookPoster() *TeamsWebhookPoster {
+	return &TeamsWebhookPoster{client: newExternalClient(15 * time.Second)}
+}
+
+func (p *TeamsWebhookPoster) Provider() models.ChatProvider {
+	return models.ChatProviderTeams
+}
+
+func (p *TeamsWebhookPoster) Validate(channel *models.TeamChatChannel) error {
+	if channel.WebhookURL == "" {
+		return fmt.Errorf("%w: webhookUrlは必須です", ErrInvalidInput)
+	}
+	u, err := url.Parse(channel.WebhookURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%w: webhookUrlはhttpsのURLで指定してください", ErrInvalidInput)
+	}
+	if !isTeamsWebhookHost(u.Hostname()) {
+		return fmt.Errorf("%w: webhookUrlはMicrosoft TeamsまたはPower AutomateのWebhookのURLを指定してください", ErrInvalidInput)
+	}
+	return nil
+}
+
+func isTeamsWebhookHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, h := range teamsWebhookHosts {
+		if host == h || strings.HasSuffix(host, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *TeamsWebhookPoster) Post(ctx context.Context, channel *models.TeamChatChannel, msg ChatMessage) error {
+	data, err := json.Marshal(teamsAdaptiveCardMessage(msg))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, channel.WebhookURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Incoming Webhookは200、Power AutomateのWorkflowsは202を返す
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Microsoft Teamsのwebhookがステータス %d を返しました: %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+// teamsAdaptiveCardMessage メッセージをアダプティブカード（https://adaptivecards.io/）の添付にする
+func teamsAdaptiveCardMessage(msg ChatMessage) map[string]interface{} {
+	body := []map[string]interface{}{
+		{"type": "TextBlock", "text": msg.Text, "wrap": true, "size": "Small", "isSubtle": true},
+	}
+	if msg.LinkTitle != "" {
+		body = append(body, map[string]interface{}{
+			"type": "TextBlock", "text": msg.LinkTitle, "wrap": true, "size": "Medium", "weight": "Bolder",
+		})
+	}
+	if len(msg.Facts) > 0 {
+		facts := make([]map[string]string, 0, len(msg.Facts))
+		for _, f := range msg.Facts {
+			facts = append(facts, map[string]string{"title": f.Title, "value": f.Value})
+		}
+		body = append(body, map[string]interface{}{"type": "FactSet", "facts": facts})
+	}
+
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if msg.LinkURL != "" {
+		card["actions"] = []map[string]interface{}{
+			{"type": "Action.OpenUrl", "title": "TaskCalendarで開く", "url": msg.LinkURL},
+		}
+	}
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{"contentType": "application/vnd.microsoft.card.adaptive", "content": card},
+		},
+	}
+}
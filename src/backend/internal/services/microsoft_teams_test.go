@@ -0,0 +1,38 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"task-calendar-backend/internal/models"
+)
+
+// Microsoft Teams・Power Automate 以外のホストはWebhookのURLに登録できない
+func TestTeamsWebhookValidate(t *testing.T) {
+	p := NewTeamsWebhookPoster()
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://contoso.webhook.office.com/webhookb2/abc/IncomingWebhook/def/ghi", true},
+		{"https://outlook.office.com/webhook/abc/IncomingWebhook/def", true},
+		{"https://prod-12.westus.logic.azure.com:443/workflows/abc/triggers/manual/paths/invoke?sig=x", true},
+		{"https://default123.environment.api.powerplatform.com/powerautomate/automations/direct/workflows/abc", true},
+		{"", false},
+		{"http://contoso.webhook.office.com/webhookb2/abc", false},
+		{"https://example.com/webhook", false},
+		{"https://webhook.office.com.example.com/webhook", false},
+		{"https://evilwebhook.office.com/webhook", false},
+		{"https://127.0.0.1/webhook", false},
+		{"https://169.254.169.254/latest/meta-data/", false},
+	}
+	for _, tt := range tests {
+		err := p.Validate(&models.TeamChatChannel{WebhookURL: tt.url})
+		switch {
+		case tt.ok && err != nil:
+			t.Errorf("%q を登録できません: %v", tt.url, err)
+		case !tt.ok && !errors.Is(err, ErrInvalidInput):
+			t.Errorf("%q のエラーが %v です（ErrInvalidInput のはず）", tt.url, err)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 通知種別
+const (
+	NotificationTypeModerationWarning = "MODERATION_WARNING"
+)
+
+type ModerationService struct {
+	db       *gorm.DB
+	notifier Notifier
+}
+
+func NewModerationService(db *gorm.DB, notifier Notifier) *ModerationService {
+	return &ModerationService{db: db, notifier: notifier}
+}
+
+// ReportComment コメントを通報する（同じユーザーからの重複通報はまとめる）
+func (s *ModerationService) ReportComment(taskID, commentID, userID, reason string) (*models.CommentReport, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reasonは必須です", ErrInvalidInput)
+	}
+
+	comment, err := findTaskComment(s.db, taskID, commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.AuthorID == userID {
+		return nil, fmt.Errorf("%w: 自分のコメントは通報できません", ErrInvalidInput)
+	}
+
+	var report models.CommentReport
+	err = s.db.Where("comment_id = ? AND reporter_id = ?", comment.ID, userID).First(&report).Error
+	if err == nil {
+		return &report, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	report = models.CommentReport{
+		TaskID:     taskID,
+		CommentID:  comment.ID,
+		AuthorID:   comment.AuthorID,
+		ReporterID: userID,
+		Reason:     reason,
+		Content:    comment.Content,
+		Status:     models.ReportStatusOpen,
+	}
+	if err := s.db.Create(&report).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ListReports モデレーションキューを取得（管理者のワークスペースの通報のみ。statusを省略すると未対応のみ）
+func (s *ModerationService) ListReports(adminID string, status models.ReportStatus, sort SortOptions, include IncludeOptions) ([]models.CommentReport, error) {
+	if status == "" {
+		status = models.ReportStatusOpen
+	}
+
+	var reports []models.CommentReport
+	query := preload(s.db, include).Scopes(reportsInWorkspaceOf(adminID)).Where("status = ?", status)
+	if err := orderBy(query, sort, "created_at ASC").Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// ResolveReport 通報に対応する（非表示・削除・投稿者への警告・却下）
+// 同じコメントへの未対応の通報もまとめて対応済みにする
+func (s *ModerationService) ResolveReport(reportID, adminID string, action models.ModerationAction, note string) (*models.CommentReport, error) {
+	var report models.CommentReport
+	if err := s.db.Scopes(reportsInWorkspaceOf(adminID)).First(&report, "id = ?", reportID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if report.Status != models.ReportStatusOpen {
+		return nil, fmt.Errorf("%w: この通報は対応済みです", ErrInvalidInput)
+	}
+
+	switch action {
+	case models.ModerationActionHide, models.ModerationActionDelete,
+		models.ModerationActionWarn, models.ModerationActionDismiss:
+	default:
+		return nil, fmt.Errorf("%w: actionが不正です", ErrInvalidInput)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		switch action {
+		case models.ModerationActionHide:
+			if err := tx.Model(&models.Comment{}).Where("id = ?", report.CommentID).
+				Update("is_hidden", true).Error; err != nil {
+				return err
+			}
+		case models.ModerationActionDelete:
+			var comment models.Comment
+			err := tx.First(&comment, "id = ?", report.CommentID).Error
+			if err == nil {
+				if err := deleteCommentTx(tx, &comment, adminID); err != nil {
+					return err
+				}
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		case models.ModerationActionWarn:
+			if err := tx.Create(&models.UserWarning{
+				UserID:     report.AuthorID,
+				IssuedByID: adminID,
+				Reason:     note,
+				ReportID:   &report.ID,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		return tx.Model(&models.CommentReport{}).
+			Where("comment_id = ? AND status = ?", report.CommentID, models.ReportStatusOpen).
+			Updates(map[string]interface{}{
+				"status":         models.ReportStatusResolved,
+				"action":         action,
+				"note":           note,
+				"resolved_by_id": adminID,
+				"resolved_at":    &now,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if action == models.ModerationActionWarn {
+		if err := s.notifier.Notify(NotificationMessage{
+			UserID:     report.AuthorID,
+			Type:       NotificationTypeModerationWarning,
+			Title:      "あなたのコメントについて管理者から警告がありました",
+			Body:       note,
+			EntityType: "task",
+			EntityID:   report.TaskID,
+		}); err != nil {
+			log.Printf("警告通知の送信に失敗しました: %v", err)
+		}
+	}
+
+	if err := s.db.Preload("Reporter").Preload("Author").First(&report, "id = ?", report.ID).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// reportsInWorkspaceOf 管理者と同じワークスペースのタスクへのコメントの通報に絞り込む（管理者は自分の組織の通報のみ扱う）
+func reportsInWorkspaceOf(adminID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		tasks := db.Session(&gorm.Session{NewDB: true}).Model(&models.Task{}).Select("id").Scopes(inWorkspaceOf(adminID))
+		return db.Where("comment_reports.task_id IN (?)", tasks)
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"task-calendar-backend/internal/models"
+)
+
+// notificationGroupKey 通知をまとめる単位（種別と対象。対象のない通知はまとめない）
+const notificationGroupKey = "(type || '|' || COALESCE(entity_type, '') || '|' || " +
+	"CASE WHEN COALESCE(entity_id, '') = '' THEN id ELSE entity_id END)"
+
+// notificationGroupSummaries 複数件をまとめたときの見出し（対象のタイトル、件数の順に埋め込む）
+var notificationGroupSummaries = map[string]string{
+	NotificationTypeTaskComment: "「%s」に%d件の新しいコメント",
+	NotificationTypeMention:     "「%s」で%d件のメンション",
+	NotificationTypeEventRSVP:   "「%s」に%d件の出欠の回答",
+}
+
+// NotificationGroup 同じ対象・種別の通知をまとめたもの（通知のドロップダウン向け）
+type NotificationGroup struct {
+	Key         string              `json:"key"`
+	Type        string              `json:"type"`
+	EntityType  string              `json:"entityType,omitempty"`
+	EntityID    string              `json:"entityId,omitempty"`
+	EntityTitle string              `json:"entityTitle,omitempty"` // 対象のタスク・イベントのタイトル
+	Summary     string              `json:"summary"`
+	Count       int64               `json:"count"`
+	UnreadCount int64               `json:"unreadCount"`
+	LatestAt    time.Time           `json:"latestAt"`
+	Latest      models.Notification `json:"latest"` // まとめた中で最も新しい通知
+}
+
+// notificationGroupRow 通知をまとめた集計結果の1行
+type notificationGroupRow struct {
+	Type        string
+	EntityType  string
+	EntityID    string
+	GroupKey    string
+	Count       int64
+	UnreadCount int64
+	LatestAt    time.Time
+}
+
+// NotificationGroupPage まとめた通知の1ページ分（最新の通知が新しい順）と未読件数
+type NotificationGroupPage struct {
+	Groups      []NotificationGroup `json:"groups"`
+	NextCursor  *string             `json:"nextCursor"`
+	UnreadCount int64               `json:"unreadCount"`
+	Total       *int64              `json:"total,omitempty"` // まとめた単位の数（includeTotal を指定した場合のみ）
+}
+
+// ListNotificationGroups 自分宛ての通知をタスク・イベントと種別ごとにまとめて、カーソルページングで取得
+func (s *NotificationService) ListNotificationGroups(userID string, opts NotificationListOptions) (*NotificationGroupPage, error) {
+	limit := normalizeLimit(opts.Limit)
+
+	grouped := s.db.Model(&models.Notification{}).
+		Select("MIN(type) AS type, MIN(entity_type) AS entity_type, MIN(entity_id) AS entity_id, "+
+			notificationGroupKey+" AS group_key, COUNT(*) AS count, "+
+			"SUM(CASE WHEN read_at IS NULL THEN 1 ELSE 0 END) AS unread_count, MAX(created_at) AS latest_at").
+		Where("user_id = ?", userID).
+		Group(notificationGroupKey)
+	if opts.UnreadOnly {
+		grouped = grouped.Where("read_at IS NULL")
+	}
+
+	total, err := countTotal(s.db.Table("(?) AS g", grouped), opts.PageOptions)
+	if err != nil {
+		return nil, err
+	}
+	query := s.db.Table("(?) AS g", grouped)
+	if opts.Cursor != "" {
+		latestAt, key, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("(latest_at < ?) OR (latest_at = ? AND group_key < ?)", latestAt, latestAt, key)
+	}
+
+	var rows []notificationGroupRow
+	if err := query.Order("latest_at DESC, group_key DESC").Limit(limit + 1).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	page := &NotificationGroupPage{Groups: []NotificationGroup{}, Total: total}
+	rows, page.NextCursor = pageOf(rows, limit, func(row *notificationGroupRow) (time.Time, string) {
+		return row.LatestAt, row.GroupKey
+	})
+
+	if len(rows) > 0 {
+		keys := make([]string, len(rows))
+		for i, row := range rows {
+			keys[i] = row.GroupKey
+		}
+		latest, err := s.latestInGroups(userID, keys, rows[len(rows)-1].LatestAt, opts.UnreadOnly)
+		if err != nil {
+			return nil, err
+		}
+		titles, err := s.entityTitles(rows)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			group := NotificationGroup{
+				Key:         row.GroupKey,
+				Type:        row.Type,
+				EntityType:  row.EntityType,
+				EntityID:    row.EntityID,
+				EntityTitle: titles[row.EntityType+"/"+row.EntityID],
+				Count:       row.Count,
+				UnreadCount: row.UnreadCount,
+				LatestAt:    row.LatestAt,
+				Latest:      latest[row.GroupKey],
+			}
+			group.Summary = notificationGroupSummary(&group)
+			page.Groups = append(page.Groups, group)
+		}
+	}
+
+	if err := s.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&page.UnreadCount).Error; err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// latestInGroups まとめた単位ごとの最も新しい通知
+func (s *NotificationService) latestInGroups(userID string, keys []string, since time.Time, unreadOnly bool) (map[string]models.Notification, error) {
+	query := s.db.Select("*, "+notificationGroupKey+" AS group_key").
+		Where("user_id = ? AND created_at >= ? AND "+notificationGroupKey+" IN ?", userID, since, keys)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	var notifications []struct {
+		models.Notification
+		GroupKey string
+	}
+	if err := query.Model(&models.Notification{}).Order("created_at DESC, id DESC").
+		Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	latest := make(map[string]models.Notification, len(keys))
+	for _, n := range notifications {
+		if _, ok := latest[n.GroupKey]; !ok {
+			latest[n.GroupKey] = n.Notification
+		}
+	}
+	return latest, nil
+}
+
+// entityTitles 通知の対象のタスク・イベントのタイトル（キーは "task/<id>" など）
+func (s *NotificationService) entityTitles(rows []notificationGroupRow) (map[string]string, error) {
+	var taskIDs, eventIDs []string
+	for _, row := range rows {
+		switch row.EntityType {
+		case "task":
+			taskIDs = append(taskIDs, row.EntityID)
+		case "event":
+			eventIDs = append(eventIDs, row.EntityID)
+		}
+	}
+	titles := make(map[string]string)
+	if len(taskIDs) > 0 {
+		var tasks []models.Task
+		if err := s.db.Select("id", "title").Where("id IN ?", taskIDs).Find(&tasks).Error; err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			titles["task/"+task.ID] = task.Title
+		}
+	}
+	if len(eventIDs) > 0 {
+		var events []models.Event
+		if err := s.db.Select("id", "title").Where("id IN ?", eventIDs).Find(&events).Error; err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			titles["event/"+event.ID] = event.Title
+		}
+	}
+	return titles, nil
+}
+
+// notificationGroupSummary まとめた通知の見出し（1件の場合は通知のタイトル）
+func notificationGroupSummary(group *NotificationGroup) string {
+	if group.Count <= 1 {
+		return group.Latest.Title
+	}
+	if format, ok := notificationGroupSummaries[group.Type]; ok && group.EntityTitle != "" {
+		return fmt.Sprintf(format, group.EntityTitle, group.Count)
+	}
+	return fmt.Sprintf("%s（ほか%d件）", group.Latest.Title, group.Count-1)
+}
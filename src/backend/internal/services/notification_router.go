@@ -0,0 +1,419 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// notificationTypes 配信の設定ができる通知種別
+var notificationTypes = []string{
+	NotificationTypeTaskAssigned,
+	NotificationTypeTaskComment,
+	NotificationTypeMention,
+	NotificationTypeEventInvitation,
+	NotificationTypeEventRSVP,
+	NotificationTypeEventReminder,
+	NotificationTypeEventCancelled,
+	NotificationTypeEventOwnershipTransferred,
+	NotificationTypeEventWaitlistPromoted,
+	NotificationTypePollInvitation,
+	NotificationTypeModerationWarning,
+	NotificationTypeAutomation,
+}
+
+// notificationChannelDefaults ユーザーもチームも設定していない場合の配信の有無
+//
+// Webhookはユーザーが自分で登録した宛先に送るため、通知種別ごとに有効にしたもののみ送る。
+var notificationChannelDefaults = map[models.NotificationChannel]bool{
+	models.NotificationChannelInApp:   true,
+	models.NotificationChannelEmail:   true,
+	models.NotificationChannelPush:    true,
+	models.NotificationChannelWebhook: false,
+}
+
+// 設定の出どころ
+const (
+	NotificationPreferenceSourceDefault = "DEFAULT"
+	NotificationPreferenceSourceTeam    = "TEAM"
+	NotificationPreferenceSourceUser    = "USER"
+)
+
+// NotificationPreferenceSetting 通知種別・チャネルごとの配信の有無
+type NotificationPreferenceSetting struct {
+	Type    string                     `json:"type"`
+	Channel models.NotificationChannel `json:"channel"`
+	Enabled bool                       `json:"enabled"`
+	Source  string                     `json:"source"` // DEFAULT / TEAM / USER
+}
+
+// NotificationPreferenceItem 変更する設定（enabled が null の場合は設定を削除して既定に戻す）
+type NotificationPreferenceItem struct {
+	Type    string                     `json:"type" binding:"required,max=64"`
+	Channel models.NotificationChannel `json:"channel" binding:"required,oneof=IN_APP EMAIL PUSH WEBHOOK"`
+	Enabled *bool                      `json:"enabled"`
+}
+
+// NotificationPreferencesInput 通知の配信設定の変更（指定しなかった種別・チャネルは変更しない）
+type NotificationPreferencesInput struct {
+	Preferences []NotificationPreferenceItem `json:"preferences" binding:"required,dive"`
+}
+
+// notificationTypeFilter 一部の通知種別のみ配信するチャネル（プッシュ通知など）が実装する
+type notificationTypeFilter interface {
+	Supports(notificationType string) bool
+}
+
+// NotificationRouter 通知を種別とユーザー・チームの設定に応じて、各チャネル（アプリ内・メール・プッシュ・Webhook）に振り分ける
+//
+// 設定はユーザー、通知の対象（タスク・イベント）のチーム、チャネルの既定の順に優先する。
+type NotificationRouter struct {
+	db       *gorm.DB
+	jobs     *JobService
+	channels map[models.NotificationChannel]Notifier
+	order    []models.NotificationChannel
+}
+
+// NewNotificationRouter 保存に伴う通知は、非同期ジョブ（notification.route）として jobs で配信する
+func NewNotificationRouter(db *gorm.DB, jobs *JobService) *NotificationRouter {
+	r := &NotificationRouter{db: db, jobs: jobs, channels: map[models.NotificationChannel]Notifier{}}
+	jobs.Register(models.JobTypeNotificationRoute, r.runRouteJob)
+	return r
+}
+
+// notificationRouteJobParams 通知の配信のジョブの引数
+type notificationRouteJobParams struct {
+	TeamID   string                `json:"teamId"`
+	Messages []NotificationMessage `json:"messages"`
+}
+
+// enqueueRoute 通知を非同期ジョブとして登録する（コールバックでは tx を渡し、保存と同じトランザクションで登録する）
+//
+// ロールバックされた保存の通知は送らず、ジョブはコミットされた後に実行するため保存した内容を読める。
+// 一部のチャネルに配信してから失敗した場合に重複して届かないよう、実行し直さない。
+func (r *NotificationRouter) enqueueRoute(db *gorm.DB, teamID string, msgs ...NotificationMessage) error {
+	return r.jobs.EnqueueBackground(db, models.JobTypeNotificationRoute, notificationRouteJobParams{TeamID: teamID, Messages: msgs}, 1)
+}
+
+// runRouteJob 通知の配信のジョブを実行する（一部の宛先で失敗しても残りには配信し、最初のエラーを返す）
+func (r *NotificationRouter) runRouteJob(job *models.Job, _ io.Reader, _ func(int)) (*JobOutput, error) {
+	var params notificationRouteJobParams
+	if err := DecodeJobParams(job, &params); err != nil {
+		return nil, PermanentJobError(err)
+	}
+	var first error
+	for _, msg := range params.Messages {
+		if err := r.route(msg, params.TeamID); err != nil && first == nil {
+			first = err
+		}
+	}
+	return nil, first
+}
+
+// Register チャネルの配信先を登録する（起動時に呼ぶ。登録のないチャネルには配信しない）
+func (r *NotificationRouter) Register(channel models.NotificationChannel, notifier Notifier) {
+	if _, ok := r.channels[channel]; !ok {
+		r.order = append(r.order, channel)
+	}
+	r.channels[channel] = notifier
+}
+
+// Notify 配信が有効なチャネルに通知する（一部のチャネルで失敗しても残りには配信し、最初のエラーを返す）
+func (r *NotificationRouter) Notify(msg NotificationMessage) error {
+	teamID, err := r.entityTeamID(msg.EntityType, msg.EntityID)
+	if err != nil {
+		return err
+	}
+	return r.route(msg, teamID)
+}
+
+// route teamID のチームの設定も踏まえて、配信が有効なチャネルに通知する
+func (r *NotificationRouter) route(msg NotificationMessage, teamID string) error {
+	enabled, err := r.resolve(msg, teamID)
+	if err != nil {
+		return err
+	}
+	var first error
+	for _, channel := range r.order {
+		if !enabled[channel] || !r.supports(channel, msg.Type) {
+			continue
+		}
+		if err := r.channels[channel].Notify(msg); err != nil && first == nil {
+			first = fmt.Errorf("%sへの通知に失敗しました: %w", channel, err)
+		}
+	}
+	return first
+}
+
+// supports チャネルが通知種別を配信するか
+func (r *NotificationRouter) supports(channel models.NotificationChannel, notificationType string) bool {
+	filter, ok := r.channels[channel].(notificationTypeFilter)
+	return !ok || filter.Supports(notificationType)
+}
+
+// resolve 通知を配信するチャネル
+func (r *NotificationRouter) resolve(msg NotificationMessage, teamID string) (map[models.NotificationChannel]bool, error) {
+	enabled := make(map[models.NotificationChannel]bool, len(notificationChannelDefaults))
+	for channel, on := range notificationChannelDefaults {
+		enabled[channel] = on
+	}
+
+	if teamID != "" {
+		var teamPrefs []models.TeamNotificationPreference
+		if err := r.db.Where("team_id = ? AND type = ?", teamID, msg.Type).Find(&teamPrefs).Error; err != nil {
+			return nil, err
+		}
+		for _, p := range teamPrefs {
+			enabled[p.Channel] = p.Enabled
+		}
+	}
+
+	var userPrefs []models.NotificationPreference
+	if err := r.db.Where("user_id = ? AND type = ?", msg.UserID, msg.Type).Find(&userPrefs).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range userPrefs {
+		enabled[p.Channel] = p.Enabled
+	}
+	return enabled, nil
+}
+
+// entityTeamID 通知の対象のタスク・イベントのチーム（チームのないイベントや、その他の対象は空）
+func (r *NotificationRouter) entityTeamID(entityType, entityID string) (string, error) {
+	if entityID == "" {
+		return "", nil
+	}
+	var teamIDs []string
+	switch entityType {
+	case "task":
+		if err := r.db.Model(&models.Task{}).Where("id = ?", entityID).Pluck("team_id", &teamIDs).Error; err != nil {
+			return "", err
+		}
+	case "event":
+		if err := r.db.Model(&models.Event{}).Where("id = ? AND team_id IS NOT NULL", entityID).
+			Pluck("team_id", &teamIDs).Error; err != nil {
+			return "", err
+		}
+	}
+	if len(teamIDs) == 0 {
+		return "", nil
+	}
+	return teamIDs[0], nil
+}
+
+// GetUserPreferences 自分の通知の配信設定（登録されたチャネルの、全通知種別分）
+//
+// チームの設定は通知の対象ごとに変わるため含めない。
+func (r *NotificationRouter) GetUserPreferences(userID string) ([]NotificationPreferenceSetting, error) {
+	var prefs []models.NotificationPreference
+	if err := r.db.Where("user_id = ?", userID).Find(&prefs).Error; err != nil {
+		return nil, err
+	}
+	overrides := make(map[string]bool, len(prefs))
+	for _, p := range prefs {
+		overrides[preferenceKey(p.Type, p.Channel)] = p.Enabled
+	}
+	return r.settings(overrides, NotificationPreferenceSourceUser), nil
+}
+
+// UpdateUserPreferences 自分の通知の配信設定を変更する
+func (r *NotificationRouter) UpdateUserPreferences(userID string, input NotificationPreferencesInput) ([]NotificationPreferenceSetting, error) {
+	if err := validatePreferenceItems(input.Preferences); err != nil {
+		return nil, err
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range input.Preferences {
+			if item.Enabled == nil {
+				if err := tx.Where("user_id = ? AND type = ? AND channel = ?", userID, item.Type, item.Channel).
+					Delete(&models.NotificationPreference{}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}, {Name: "channel"}},
+				DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+			}).Create(&models.NotificationPreference{
+				UserID:  userID,
+				Type:    item.Type,
+				Channel: item.Channel,
+				Enabled: *item.Enabled,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetUserPreferences(userID)
+}
+
+// GetTeamPreferences チームの通知の配信設定（チームのメンバーのみ）
+func (r *NotificationRouter) GetTeamPreferences(teamID, userID string) ([]NotificationPreferenceSetting, error) {
+	if err := ensureTeamMember(r.db, teamID, userID); err != nil {
+		return nil, err
+	}
+	var prefs []models.TeamNotificationPreference
+	if err := r.db.Where("team_id = ?", teamID).Find(&prefs).Error; err != nil {
+		return nil, err
+	}
+	overrides := make(map[string]bool, len(prefs))
+	for _, p := range prefs {
+		overrides[preferenceKey(p.Type, p.Channel)] = p.Enabled
+	}
+	return r.settings(overrides, NotificationPreferenceSourceTeam), nil
+}
+
+// UpdateTeamPreferences チームの通知の配信設定を変更する（チームの管理者のみ）
+func (r *NotificationRouter) UpdateTeamPreferences(teamID, userID string, input NotificationPreferencesInput) ([]NotificationPreferenceSetting, error) {
+	admin, err := isTeamAdmin(r.db, teamID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !admin {
+		return nil, fmt.Errorf("%w: チームの通知設定はチームの管理者のみ変更できます", ErrForbidden)
+	}
+	if err := validatePreferenceItems(input.Preferences); err != nil {
+		return nil, err
+	}
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range input.Preferences {
+			if item.Enabled == nil {
+				if err := tx.Where("team_id = ? AND type = ? AND channel = ?", teamID, item.Type, item.Channel).
+					Delete(&models.TeamNotificationPreference{}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "team_id"}, {Name: "type"}, {Name: "channel"}},
+				DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+			}).Create(&models.TeamNotificationPreference{
+				TeamID:  teamID,
+				Type:    item.Type,
+				Channel: item.Channel,
+				Enabled: *item.Enabled,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetTeamPreferences(teamID, userID)
+}
+
+// settings 登録されたチャネルと全通知種別の組み合わせのうち、配信するものの設定（overrides にないものは既定）
+func (r *NotificationRouter) settings(overrides map[string]bool, source string) []NotificationPreferenceSetting {
+	settings := make([]NotificationPreferenceSetting, 0, len(notificationTypes)*len(r.order))
+	for _, t := range notificationTypes {
+		for _, channel := range r.order {
+			if !r.supports(channel, t) {
+				continue
+			}
+			setting := NotificationPreferenceSetting{
+				Type:    t,
+				Channel: channel,
+				Enabled: notificationChannelDefaults[channel],
+				Source:  NotificationPreferenceSourceDefault,
+			}
+			if on, ok := overrides[preferenceKey(t, channel)]; ok {
+				setting.Enabled = on
+				setting.Source = source
+			}
+			settings = append(settings, setting)
+		}
+	}
+	return settings
+}
+
+func validatePreferenceItems(items []NotificationPreferenceItem) error {
+	for _, item := range items {
+		known := false
+		for _, t := range notificationTypes {
+			if t == item.Type {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("%w: 通知種別 %s は設定できません", ErrInvalidInput, item.Type)
+		}
+		if _, ok := notificationChannelDefaults[item.Channel]; !ok {
+			return fmt.Errorf("%w: チャネル %s は存在しません", ErrInvalidInput, item.Channel)
+		}
+	}
+	return nil
+}
+
+func preferenceKey(notificationType string, channel models.NotificationChannel) string {
+	return notificationType + "/" + string(channel)
+}
+
+// RegisterCallbacks タスクの担当者が新たに設定されたことを通知するgormのコールバックを登録する
+//
+// 担当者の変更は読み込んだ時点と比べて判定するため、読み込まずに一括更新した場合は通知しない。
+// 通知は保存と同じトランザクションでジョブに登録し、コミットされた後に配信する。
+func (r *NotificationRouter) RegisterCallbacks() error {
+	callbacks := r.db.Callback()
+	if err := callbacks.Create().After("gorm:create").Before("gorm:after_create").
+		Register("notification:after_create", r.taskAssigned); err != nil {
+		return err
+	}
+	return callbacks.Update().After("gorm:update").Before("gorm:after_update").
+		Register("notification:after_update", r.taskAssigned)
+}
+
+func (r *NotificationRouter) taskAssigned(tx *gorm.DB) {
+	if tx.Error != nil || tx.RowsAffected == 0 || !tx.Statement.ReflectValue.IsValid() {
+		return
+	}
+	value := reflect.Indirect(tx.Statement.ReflectValue)
+	var tasks []*models.Task
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			elem := reflect.Indirect(value.Index(i))
+			if !elem.CanAddr() {
+				continue
+			}
+			if task, ok := elem.Addr().Interface().(*models.Task); ok {
+				tasks = append(tasks, task)
+			}
+		}
+	case reflect.Struct:
+		if value.CanAddr() {
+			if task, ok := value.Addr().Interface().(*models.Task); ok {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+
+	for _, task := range tasks {
+		assigneeID := task.NewAssigneeID()
+		if task.ID == "" || assigneeID == "" {
+			continue
+		}
+		msg := NotificationMessage{
+			UserID:     assigneeID,
+			Type:       NotificationTypeTaskAssigned,
+			Title:      "タスクの担当者になりました",
+			Body:       task.Title,
+			EntityType: "task",
+			EntityID:   task.ID,
+		}
+		if err := r.enqueueRoute(tx, task.TeamID, msg); err != nil {
+			log.Printf("担当者の通知の登録に失敗しました: %v", err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 担当者の通知は保存と同じトランザクションでジョブに登録し、ロールバックされた保存では登録しない
+func TestTaskAssignedNotificationWaitsForCommit(t *testing.T) {
+	db := openTestDB(t)
+	seedTeam(t, db)
+	router := NewNotificationRouter(db, NewJobService(db, nil, "secret", "/api/v1/jobs"))
+	if err := router.RegisterCallbacks(); err != nil {
+		t.Fatal(err)
+	}
+	assignee := "u2"
+	newTask := func() *models.Task {
+		return &models.Task{ID: "k1", Title: "Task", TeamID: "t1", CreatorID: "u1", AssigneeID: &assignee}
+	}
+	countJobs := func() int64 {
+		var count int64
+		if err := db.Model(&models.Job{}).Where("type = ?", models.JobTypeNotificationRoute).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		return count
+	}
+
+	rollback := errors.New("rollback")
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newTask()).Error; err != nil {
+			return err
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatalf("トランザクションのエラーが %v です", err)
+	}
+	if n := countJobs(); n != 0 {
+		t.Fatalf("ロールバックした保存の通知が %d 件登録されています", n)
+	}
+
+	if err := db.Create(newTask()).Error; err != nil {
+		t.Fatal(err)
+	}
+	if n := countJobs(); n != 1 {
+		t.Fatalf("保存した担当者の通知が %d 件登録されています（1件のはず）", n)
+	}
+	var job models.Job
+	if err := db.First(&job, "type = ?", models.JobTypeNotificationRoute).Error; err != nil {
+		t.Fatal(err)
+	}
+	var params notificationRouteJobParams
+	if err := DecodeJobParams(&job, &params); err != nil {
+		t.Fatal(err)
+	}
+	if params.TeamID != "t1" || len(params.Messages) != 1 || params.Messages[0].UserID != assignee {
+		t.Errorf("ジョブの引数が %+v です", params)
+	}
+}
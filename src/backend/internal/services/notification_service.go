@@ -0,0 +1,101 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// NotificationListOptions 通知一覧の取得条件
+type NotificationListOptions struct {
+	PageOptions
+	UnreadOnly bool
+}
+
+// NotificationPage 通知一覧の1ページ分（新しい順）と未読件数
+type NotificationPage struct {
+	Notifications []models.Notification `json:"notifications"`
+	NextCursor    *string               `json:"nextCursor"`
+	UnreadCount   int64                 `json:"unreadCount"`
+	Total         *int64                `json:"total,omitempty"` // includeTotal を指定した場合のみ
+}
+
+// NotificationService アプリ内の通知センター（Notifier として通知を保存する）
+type NotificationService struct {
+	db *gorm.DB
+}
+
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{db: db}
+}
+
+// Notify 通知を通知センターに保存する
+func (s *NotificationService) Notify(msg NotificationMessage) error {
+	return s.db.Create(&models.Notification{
+		UserID:     msg.UserID,
+		Type:       msg.Type,
+		Title:      msg.Title,
+		Body:       msg.Body,
+		EntityType: msg.EntityType,
+		EntityID:   msg.EntityID,
+	}).Error
+}
+
+// ListNotifications 自分宛ての通知をカーソルページングで取得
+func (s *NotificationService) ListNotifications(userID string, opts NotificationListOptions) (*NotificationPage, error) {
+	query := s.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if opts.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	total, err := countTotal(query, opts.PageOptions)
+	if err != nil {
+		return nil, err
+	}
+	query, limit, err := paginate(query, opts.PageOptions, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var notifications []models.Notification
+	if err := query.Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	page := &NotificationPage{Total: total}
+	page.Notifications, page.NextCursor = pageOf(notifications, limit, func(n *models.Notification) (time.Time, string) {
+		return n.CreatedAt, n.ID
+	})
+	if err := s.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&page.UnreadCount).Error; err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// MarkRead 通知を既読にする（既読の場合はそのまま）
+func (s *NotificationService) MarkRead(notificationID, userID string) (*models.Notification, error) {
+	var notification models.Notification
+	if err := s.db.First(&notification, "id = ? AND user_id = ?", notificationID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if notification.ReadAt == nil {
+		now := time.Now()
+		if err := s.db.Model(&notification).Update("read_at", now).Error; err != nil {
+			return nil, err
+		}
+		notification.ReadAt = &now
+	}
+	return &notification, nil
+}
+
+// MarkAllRead 自分宛ての未読の通知をすべて既読にし、既読にした件数を返す
+func (s *NotificationService) MarkAllRead(userID string) (int64, error) {
+	result := s.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"log"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// NotificationMessage ユーザーに届ける通知の内容
+type NotificationMessage struct {
+	UserID     string
+	Type       string
+	Title      string
+	Body       string
+	EntityType string
+	EntityID   string
+	ReplyTo    string // メール通知の返信先（返信でコメントを投稿できる場合のみ）
+}
+
+// 通知種別
+const (
+	NotificationTypeMention      = "MENTION"
+	NotificationTypeTaskComment  = "TASK_COMMENT"
+	NotificationTypeTaskAssigned = models.NotificationTypeTaskAssigned
+)
+
+// Notifier 通知の配信先
+type Notifier interface {
+	Notify(msg NotificationMessage) error
+}
+
+// LogNotifier 通知をログに出力するだけのNotifier実装
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(msg NotificationMessage) error {
+	log.Printf("通知 [%s] user=%s %s: %s", msg.Type, msg.UserID, msg.Title, msg.Body)
+	return nil
+}
+
+// MultiNotifier 複数の配信先に通知する（一部の配信先で失敗しても残りには配信し、最初のエラーを返す）
+type MultiNotifier []Notifier
+
+func (m MultiNotifier) Notify(msg NotificationMessage) error {
+	var first error
+	for _, n := range m {
+		if err := n.Notify(msg); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// notificationEmailTemplates 専用のメールテンプレートがある通知種別（それ以外は EmailTemplateNotification）
+var notificationEmailTemplates = map[string]string{
+	NotificationTypeEventReminder: EmailTemplateReminder,
+}
+
+// notificationEmailView 通知メールのテンプレートに渡す内容
+type notificationEmailView struct {
+	Name     string
+	Title    string
+	Body     string
+	CanReply bool
+}
+
+// EmailNotifier 通知をユーザーのメールアドレスに送る
+type EmailNotifier struct {
+	db     *gorm.DB
+	mailer Mailer
+}
+
+func NewEmailNotifier(db *gorm.DB, mailer Mailer) *EmailNotifier {
+	return &EmailNotifier{db: db, mailer: mailer}
+}
+
+func (n *EmailNotifier) Notify(msg NotificationMessage) error {
+	var user models.User
+	if err := n.db.Select("id", "email", "username", "first_name", "last_name").
+		First(&user, "id = ?", msg.UserID).Error; err != nil {
+		return err
+	}
+
+	name, ok := notificationEmailTemplates[msg.Type]
+	if !ok {
+		name = EmailTemplateNotification
+	}
+	email, err := renderEmail(name, notificationEmailView{
+		Name:     userDisplayName(&user),
+		Title:    msg.Title,
+		Body:     msg.Body,
+		CanReply: msg.ReplyTo != "",
+	})
+	if err != nil {
+		return err
+	}
+	email.To = user.Email
+	email.ReplyTo = msg.ReplyTo
+	return n.mailer.Send(email)
+}
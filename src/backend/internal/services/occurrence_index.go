@@ -0,0 +1,243 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 繰り返しイベントの回の索引を作成する期間（現在から）
+const (
+	occurrenceIndexPast    = 31 * 24 * time.Hour
+	occurrenceIndexHorizon = 366 * 24 * time.Hour
+	// 索引の残りの期間がこれを切ったら期間を先に延ばす
+	occurrenceIndexRenewal = 30 * 24 * time.Hour
+)
+
+type OccurrenceIndexService struct {
+	db *gorm.DB
+}
+
+func NewOccurrenceIndexService(db *gorm.DB) *OccurrenceIndexService {
+	return &OccurrenceIndexService{db: db}
+}
+
+// RefreshOccurrenceIndex 繰り返しイベントの回の索引を作成・更新する（Cronジョブ用）
+//
+// 索引がない、元にした内容から変わった、または残りの期間が短くなったイベントについて、
+// 現在の occurrenceIndexPast 前から occurrenceIndexHorizon 先までの回を作り直す。
+func (s *OccurrenceIndexService) RefreshOccurrenceIndex() error {
+	// 繰り返しでなくなったイベントの索引は使われないため削除する
+	if err := s.db.Where("event_id IN (?)", s.db.Model(&models.Event{}).Select("id").Where("is_recurring = ?", false)).
+		Delete(&models.MaterializedOccurrence{}).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	renewBefore := now.Add(occurrenceIndexHorizon - occurrenceIndexRenewal)
+	var events []models.Event
+	return s.db.Where("is_recurring = ?", true).FindInBatches(&events, 200, func(tx *gorm.DB, batch int) error {
+		versions, err := occurrenceVersions(s.db, events)
+		if err != nil {
+			return err
+		}
+		for i := range events {
+			event := &events[i]
+			if event.OccurrencesVersion == versions[event.ID] && event.OccurrencesUntil != nil &&
+				event.OccurrencesUntil.After(renewBefore) {
+				continue
+			}
+			if err := s.rebuild(event, versions[event.ID], now); err != nil {
+				log.Printf("イベント %s の回の索引を作成できませんでした: %v", event.ID, err)
+			}
+		}
+		return nil
+	}).Error
+}
+
+// rebuild イベントの回の索引を作り直す
+func (s *OccurrenceIndexService) rebuild(event *models.Event, version string, now time.Time) error {
+	var exceptions []models.EventException
+	if err := s.db.Where("event_id = ?", event.ID).Find(&exceptions).Error; err != nil {
+		return err
+	}
+	from, until := now.Add(-occurrenceIndexPast), now.Add(occurrenceIndexHorizon)
+	occurrences, err := expandEvent(event, exceptions, from, until)
+	if err != nil {
+		return err
+	}
+	if event.AllDay {
+		from, until = floatingUTC(from), floatingUTC(until)
+	}
+
+	byRecurrenceID := make(map[int64]*models.EventException, len(exceptions))
+	for i := range exceptions {
+		byRecurrenceID[exceptions[i].RecurrenceID.Unix()] = &exceptions[i]
+	}
+	rows := make([]models.MaterializedOccurrence, 0, len(occurrences))
+	for _, occ := range occurrences {
+		row := models.MaterializedOccurrence{
+			EventID:      event.ID,
+			RecurrenceID: occ.RecurrenceID,
+			StartDate:    occ.StartDate,
+			EndDate:      occ.EndDate,
+			IsOverride:   occ.IsOverride,
+		}
+		if ex, ok := byRecurrenceID[occ.RecurrenceID.Unix()]; ok && occ.IsOverride {
+			row.Title = ex.Title
+			row.Description = ex.Description
+		}
+		rows = append(rows, row)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("event_id = ?", event.ID).Delete(&models.MaterializedOccurrence{}).Error; err != nil {
+			return err
+		}
+		if len(rows) > 0 {
+			if err := tx.CreateInBatches(&rows, 500).Error; err != nil {
+				return err
+			}
+		}
+		// イベントの更新日時を変えないよう、フックを通さずに記録する
+		return tx.Model(&models.Event{}).Where("id = ?", event.ID).UpdateColumns(map[string]interface{}{
+			"occurrences_from":    from,
+			"occurrences_until":   until,
+			"occurrences_version": version,
+		}).Error
+	})
+}
+
+// occurrenceVersions 繰り返しイベントごとの、回の展開結果に影響する内容の要約
+//
+// 日時・繰り返しルールと、回ごとの変更の件数・最終更新日時から求めるため、回ごとの変更を読み込まずに比較できる。
+func occurrenceVersions(db *gorm.DB, events []models.Event) (map[string]string, error) {
+	var ids []string
+	for _, e := range events {
+		if e.IsRecurring {
+			ids = append(ids, e.ID)
+		}
+	}
+	versions := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return versions, nil
+	}
+
+	// 最終更新日時は要約に含めるだけなので文字列で受け取る（SQLiteは集計した日時を文字列で返す）
+	var stats []struct {
+		EventID string
+		Count   int64
+		Latest  string
+	}
+	if err := db.Model(&models.EventException{}).
+		Select("event_id, COUNT(*) AS count, MAX(updated_at) AS latest").
+		Where("event_id IN ?", ids).Group("event_id").Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+	type exceptionStat struct {
+		count  int64
+		latest string
+	}
+	byEvent := make(map[string]exceptionStat, len(stats))
+	for _, st := range stats {
+		byEvent[st.EventID] = exceptionStat{count: st.Count, latest: st.Latest}
+	}
+
+	for _, e := range events {
+		if !e.IsRecurring {
+			continue
+		}
+		st := byEvent[e.ID]
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%d|%t|%s|%s|%d|%s",
+			e.StartDate.UnixMicro(), e.EndDate.UnixMicro(), e.AllDay, e.TimeZone, e.Recurrence,
+			st.count, st.latest)))
+		versions[e.ID] = hex.EncodeToString(sum[:])
+	}
+	return versions, nil
+}
+
+// indexedOccurrences 回の索引が最新で [from, to) を含むイベントについて、[from, to) と重なる回を索引から取得する
+//
+// 索引を使えないイベント（単発、索引の作成前、内容の変更後に未更新、索引の期間外）は rest として返す。
+func indexedOccurrences(db *gorm.DB, events []models.Event, from, to time.Time) (map[string][]EventOccurrence, []models.Event, error) {
+	indexed := map[string][]EventOccurrence{}
+	var candidates []models.Event
+	var rest []models.Event
+	for _, e := range events {
+		if e.IsRecurring && e.OccurrencesFrom != nil && e.OccurrencesUntil != nil {
+			candidates = append(candidates, e)
+		} else {
+			rest = append(rest, e)
+		}
+	}
+	if len(candidates) == 0 {
+		return indexed, rest, nil
+	}
+	versions, err := occurrenceVersions(db, candidates)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[string]*models.Event, len(candidates))
+	var timedIDs, allDayIDs []string
+	for i := range candidates {
+		e := &candidates[i]
+		lo, hi := from, to
+		if e.AllDay {
+			lo, hi = floatingUTC(from), floatingUTC(to)
+		}
+		if e.OccurrencesVersion != versions[e.ID] || e.OccurrencesFrom.After(lo) || e.OccurrencesUntil.Before(hi) {
+			rest = append(rest, *e)
+			continue
+		}
+		byID[e.ID] = e
+		indexed[e.ID] = []EventOccurrence{}
+		if e.AllDay {
+			allDayIDs = append(allDayIDs, e.ID)
+		} else {
+			timedIDs = append(timedIDs, e.ID)
+		}
+	}
+	if len(byID) == 0 {
+		return indexed, rest, nil
+	}
+
+	var rows []models.MaterializedOccurrence
+	query := db.Where("event_id IN ? AND start_date < ? AND end_date > ?", timedIDs, to, from)
+	if len(allDayIDs) > 0 {
+		query = query.Or("event_id IN ? AND start_date < ? AND end_date > ?", allDayIDs, floatingUTC(to), floatingUTC(from))
+	}
+	if err := query.Order("start_date ASC").Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, row := range rows {
+		e := byID[row.EventID]
+		if e == nil {
+			continue
+		}
+		occ := newOccurrence(e, row.RecurrenceID, e.EndDate.Sub(e.StartDate))
+		occ.StartDate = row.StartDate
+		occ.EndDate = row.EndDate
+		occ.IsOverride = row.IsOverride
+		if row.Title != nil {
+			occ.Title = *row.Title
+		}
+		if row.Description != nil {
+			occ.Description = *row.Description
+		}
+		// 展開した場合と同じく、時刻付きはイベントのタイムゾーン、終日はUTCで表す
+		loc := time.UTC
+		if !e.AllDay {
+			loc = e.TimeZoneLocation()
+		}
+		occ.StartDate, occ.EndDate, occ.RecurrenceID = occ.StartDate.In(loc), occ.EndDate.In(loc), occ.RecurrenceID.In(loc)
+		indexed[row.EventID] = append(indexed[row.EventID], occ)
+	}
+	return indexed, rest, nil
+}
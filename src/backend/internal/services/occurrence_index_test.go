@@ -0,0 +1,151 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// seedIndexedEvents 現在の前後にまたがる時刻付き（e1、例外あり）と終日（e2）の繰り返しイベントを登録する
+func seedIndexedEvents(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	seedTeam(t, db)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	start := today.AddDate(0, 0, -14).Add(9 * time.Hour)
+	createEvents(t, db,
+		models.Event{ID: "e1", Title: "定例", StartDate: start, EndDate: start.Add(time.Hour),
+			IsRecurring: true, Recurrence: "FREQ=WEEKLY;BYDAY=MO,TH"},
+		models.Event{ID: "e2", Title: "月末", StartDate: today.AddDate(0, 0, -14), EndDate: today.AddDate(0, 0, -13),
+			AllDay: true, IsRecurring: true, Recurrence: "FREQ=MONTHLY;BYMONTHDAY=-1"},
+	)
+
+	// 現在より後の2回目をキャンセルし、3回目の件名と日時を変更する
+	var event models.Event
+	if err := db.First(&event, "id = ?", "e1").Error; err != nil {
+		t.Fatal(err)
+	}
+	upcoming, err := expandEvent(&event, nil, time.Now(), time.Now().AddDate(0, 1, 0))
+	if err != nil || len(upcoming) < 3 {
+		t.Fatalf("e1 の回を展開できません: %v（%d 件）", err, len(upcoming))
+	}
+	title := "移動した回"
+	moved := upcoming[2].RecurrenceID.Add(3 * time.Hour)
+	movedEnd := moved.Add(30 * time.Minute)
+	for _, ex := range []models.EventException{
+		{ID: "x1", EventID: "e1", RecurrenceID: upcoming[1].RecurrenceID, IsCancelled: true},
+		{ID: "x2", EventID: "e1", RecurrenceID: upcoming[2].RecurrenceID, Title: &title, StartDate: &moved, EndDate: &movedEnd},
+	} {
+		if err := db.Create(&ex).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// describeOccurrences 索引と展開の結果を比べるための表現
+func describeOccurrences(occurrences []EventOccurrence) string {
+	lines := make([]string, len(occurrences))
+	for i, occ := range occurrences {
+		lines[i] = fmt.Sprintf("%s %s〜%s (%s) %q override=%t", occ.EventID,
+			occ.StartDate.UTC().Format(time.RFC3339), occ.EndDate.UTC().Format(time.RFC3339),
+			occ.RecurrenceID.UTC().Format(time.RFC3339), occ.Title, occ.IsOverride)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// assertIndexMatchesExpansion [from, to) について、索引から取得した回がイベントを展開した結果と一致する
+func assertIndexMatchesExpansion(t *testing.T, db *gorm.DB, from, to time.Time) {
+	t.Helper()
+	var events []models.Event
+	if err := db.Order("id").Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	indexed, rest, err := indexedOccurrences(db, events, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range rest {
+		t.Errorf("イベント %s の索引が使われませんでした", e.ID)
+	}
+	exceptions, err := loadEventExceptions(db, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range events {
+		e := &events[i]
+		expanded, err := expandEvent(e, exceptions[e.ID], from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(expanded) == 0 {
+			t.Fatalf("イベント %s は期間内に回がありません（比較になりません）", e.ID)
+		}
+		if got, want := describeOccurrences(indexed[e.ID]), describeOccurrences(expanded); got != want {
+			t.Errorf("イベント %s の索引の回が展開と異なります\n索引:\n%s\n展開:\n%s", e.ID, got, want)
+		}
+	}
+}
+
+func TestOccurrenceIndexMatchesExpansion(t *testing.T) {
+	db := openTestDB(t)
+	seedIndexedEvents(t, db)
+	if err := NewOccurrenceIndexService(db).RefreshOccurrenceIndex(); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	assertIndexMatchesExpansion(t, db, now.AddDate(0, 0, -7), now.AddDate(0, 2, 0))
+	assertIndexMatchesExpansion(t, db, now.AddDate(0, 7, 0), now.AddDate(0, 10, 0))
+}
+
+// ルール・回ごとの変更を変えると索引は使われず、次の更新で作り直される
+func TestOccurrenceIndexInvalidatedByChanges(t *testing.T) {
+	db := openTestDB(t)
+	seedIndexedEvents(t, db)
+	s := NewOccurrenceIndexService(db)
+	if err := s.RefreshOccurrenceIndex(); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	from, to := now, now.AddDate(0, 2, 0)
+	restOf := func() []string {
+		t.Helper()
+		var events []models.Event
+		if err := db.Order("id").Find(&events).Error; err != nil {
+			t.Fatal(err)
+		}
+		_, rest, err := indexedOccurrences(db, events, from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]string, len(rest))
+		for i, e := range rest {
+			ids[i] = e.ID
+		}
+		return ids
+	}
+
+	if err := db.Model(&models.Event{ID: "e1"}).Update("recurrence", "FREQ=DAILY").Error; err != nil {
+		t.Fatal(err)
+	}
+	monthEnd := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	if err := db.Create(&models.EventException{ID: "x3", EventID: "e2", RecurrenceID: monthEnd, IsCancelled: true}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(restOf(), ","); got != "e1,e2" {
+		t.Errorf("変更後に索引を使わなかったイベントが %q です（e1,e2 のはず）", got)
+	}
+
+	if err := s.RefreshOccurrenceIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if got := restOf(); len(got) != 0 {
+		t.Errorf("作り直した後も索引を使わなかったイベントがあります: %v", got)
+	}
+	assertIndexMatchesExpansion(t, db, from, to)
+}
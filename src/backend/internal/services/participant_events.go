@@ -0,0 +1,121 @@
+package services
+
+import (
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// eventParticipant 予定に参加するユーザーと出欠（作成者はACCEPTED）
+type eventParticipant struct {
+	UserID string
+	Status models.AttendeeStatus
+}
+
+// participantEvents ユーザーが作成した、または辞退・キャンセル待ちでない招待の時刻付きの予定（終日の不在を含む）のうち、[from, to) と重なり得るものを取得する
+//
+// 予定IDごとに、対象ユーザーのうちその予定に参加する人を返す。
+func participantEvents(db *gorm.DB, userIDs []string, from, to time.Time, excludeEventID string) ([]models.Event, map[string][]eventParticipant, error) {
+	invited := db.Session(&gorm.Session{NewDB: true}).Model(&models.EventAttendee{}).
+		Select("event_id").Where("user_id IN ? AND status NOT IN ?", userIDs, models.InactiveAttendeeStatuses)
+	// 終日の不在は日付で保存しているため時差の分だけ、前後の移動時間（最大 maxBufferMinutes）の分も含めて前後に広げて取得する
+	query := db.Where("all_day = ? OR type = ?", false, models.EventTypeOutOfOffice).
+		Where("start_date < ? AND (is_recurring = ? OR end_date > ?)", to.Add(24*time.Hour), true, from.Add(-24*time.Hour)).
+		Where("creator_id IN ? OR id IN (?)", userIDs, invited).
+		Where("status <> ?", models.EventStatusCancelled)
+	if excludeEventID != "" {
+		query = query.Where("id <> ?", excludeEventID)
+	}
+	var events []models.Event
+	if err := query.Find(&events).Error; err != nil {
+		return nil, nil, err
+	}
+	participants := make(map[string][]eventParticipant, len(events))
+	if len(events) == 0 {
+		return events, participants, nil
+	}
+
+	ids := make([]string, len(events))
+	for i := range events {
+		ids[i] = events[i].ID
+	}
+	var attendees []models.EventAttendee
+	if err := db.Where("event_id IN ? AND user_id IN ? AND status NOT IN ?", ids, userIDs, models.InactiveAttendeeStatuses).
+		Find(&attendees).Error; err != nil {
+		return nil, nil, err
+	}
+
+	target := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		target[id] = true
+	}
+	creators := make(map[string]string, len(events))
+	for i := range events {
+		creators[events[i].ID] = events[i].CreatorID
+		if target[events[i].CreatorID] {
+			participants[events[i].ID] = append(participants[events[i].ID],
+				eventParticipant{UserID: events[i].CreatorID, Status: models.AttendeeStatusAccepted})
+		}
+	}
+	for _, a := range attendees {
+		if a.UserID != creators[a.EventID] {
+			participants[a.EventID] = append(participants[a.EventID], eventParticipant{UserID: a.UserID, Status: a.Status})
+		}
+	}
+	return events, participants, nil
+}
+
+// expandBusyEvent 予定を [from, to) と重なる回に展開する（各回の日時は前後の移動時間を含む）
+//
+// 終日の不在は、作成時のタイムゾーンでその日の0時から翌日の0時までの予定として扱う。
+func expandBusyEvent(event *models.Event, exceptions []models.EventException, from, to time.Time) ([]EventOccurrence, error) {
+	if !event.AllDay {
+		before, after := eventBuffers(event)
+		occurrences, err := expandEvent(event, exceptions, from.Add(-after), to.Add(before))
+		if err != nil {
+			return nil, err
+		}
+		for i := range occurrences {
+			occurrences[i].StartDate = occurrences[i].StartDate.Add(-before)
+			occurrences[i].EndDate = occurrences[i].EndDate.Add(after)
+		}
+		return occurrences, nil
+	}
+	occurrences, err := expandEvent(event, exceptions, from.Add(-24*time.Hour), to.Add(24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	loc := event.TimeZoneLocation()
+	result := occurrences[:0]
+	for _, occ := range occurrences {
+		start, end := occ.StartDate.UTC(), occ.EndDate.UTC()
+		occ.StartDate = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+		occ.EndDate = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, loc)
+		if occ.StartDate.Before(to) && occ.EndDate.After(from) {
+			result = append(result, occ)
+		}
+	}
+	return result, nil
+}
+
+// viewableEventIDs 指定した予定のうちユーザーが閲覧できるもの
+func viewableEventIDs(db *gorm.DB, events []models.Event, viewerID string) (map[string]bool, error) {
+	ids := make([]string, len(events))
+	for i := range events {
+		ids[i] = events[i].ID
+	}
+	var visible []string
+	if len(ids) > 0 {
+		if err := db.Model(&models.Event{}).Scopes(visibleEventsScope(viewerID)).
+			Where("events.id IN ?", ids).Pluck("events.id", &visible).Error; err != nil {
+			return nil, err
+		}
+	}
+	canView := make(map[string]bool, len(visible))
+	for _, id := range visible {
+		canView[id] = true
+	}
+	return canView, nil
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// パスワード再設定のトークンの有効期間
+	passwordResetTTL = time.Hour
+	// 同じユーザーに再設定メールを続けて送らない間隔
+	passwordResetInterval = time.Minute
+	minPasswordLength     = 8
+)
+
+// passwordResetEmailView パスワード再設定メールのテンプレートに渡す内容
+type passwordResetEmailView struct {
+	Name      string
+	URL       string
+	ExpiresIn string
+}
+
+type PasswordResetService struct {
+	db       *gorm.DB
+	mailer   Mailer
+	resetURL string // トークンを token クエリに付けてメールに載せる、フロントエンドの再設定画面のURL
+}
+
+func NewPasswordResetService(db *gorm.DB, mailer Mailer, resetURL string) *PasswordResetService {
+	return &PasswordResetService{db: db, mailer: mailer, resetURL: resetURL}
+}
+
+// RequestReset メールアドレスのユーザーにパスワード再設定メールを送る
+//
+// 登録されているかを知られないよう、ユーザーが見つからない場合もエラーにしない。
+// 以前に送った未使用のトークンは使えなくする。
+func (s *PasswordResetService) RequestReset(email string) error {
+	var user models.User
+	err := s.db.First(&user, "LOWER(email) = ?", strings.ToLower(strings.TrimSpace(email))).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var recent int64
+	if err := s.db.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND created_at > ?", user.ID, time.Now().Add(-passwordResetInterval)).
+		Count(&recent).Error; err != nil {
+		return err
+	}
+	if recent > 0 {
+		return nil
+	}
+
+	token, err := newPasswordResetToken()
+	if err != nil {
+		return err
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).
+			Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: hashPasswordResetToken(token),
+			ExpiresAt: time.Now().Add(passwordResetTTL),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	link, err := url.Parse(s.resetURL)
+	if err != nil {
+		return err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	msg, err := renderEmail(EmailTemplatePasswordReset, passwordResetEmailView{
+		Name:      userDisplayName(&user),
+		URL:       link.String(),
+		ExpiresIn: fmt.Sprintf("%d分", int(passwordResetTTL.Minutes())),
+	})
+	if err != nil {
+		return err
+	}
+	msg.To = user.Email
+	return s.mailer.Send(msg)
+}
+
+// ResetPassword 再設定メールのトークンを確認して新しいパスワードを設定する
+func (s *PasswordResetService) ResetPassword(token, password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("%w: パスワードは%d文字以上で指定してください", ErrInvalidInput, minPasswordLength)
+	}
+	invalid := fmt.Errorf("%w: パスワード再設定のリンクが無効か、期限が切れています", ErrInvalidInput)
+
+	var reset models.PasswordResetToken
+	err := s.db.First(&reset, "token_hash = ? AND used_at IS NULL AND expires_at > ?",
+		hashPasswordResetToken(token), time.Now()).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return invalid
+	}
+	if err != nil {
+		return err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// 同じトークンで同時に再設定された場合は先に使った方のみ有効にする
+		result := tx.Model(&models.PasswordResetToken{}).Where("id = ? AND used_at IS NULL", reset.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return invalid
+		}
+		return tx.Model(&models.User{}).Where("id = ?", reset.UserID).Update("password", string(hashed)).Error
+	})
+}
+
+func newPasswordResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashPasswordResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
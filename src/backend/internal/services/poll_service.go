@@ -0,0 +1,371 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 通知種別
+const (
+	NotificationTypePollInvitation = "POLL_INVITATION"
+)
+
+// 日程調整の候補の上限
+const maxPollOptions = 30
+
+// PollInput 日程調整の作成
+type PollInput struct {
+	Title       string            `json:"title" binding:"required,notblank,max=200"`
+	Description string            `json:"description"`
+	Location    string            `json:"location"`
+	TimeZone    string            `json:"timeZone" binding:"omitempty,timezone"`
+	TeamID      *string           `json:"teamId"`
+	Options     []PollOptionInput `json:"options" binding:"required,min=1,max=30,dive"`
+	InviteeIDs  []string          `json:"inviteeIds"`
+}
+
+// PollOptionInput 日程調整の候補の日時
+type PollOptionInput struct {
+	StartDate time.Time `json:"startDate" binding:"required"`
+	EndDate   time.Time `json:"endDate" binding:"required,gtfield=StartDate"`
+}
+
+// PollVoteInput 日程調整への投票（指定しなかった候補への投票は取り消す）
+type PollVoteInput struct {
+	Votes []PollVote `json:"votes" binding:"dive"`
+}
+
+// PollVote 候補への回答
+type PollVote struct {
+	OptionID string `json:"optionId" binding:"required"`
+	Response string `json:"response" binding:"required,oneof=YES IF_NEEDED NO"`
+}
+
+// PollConfirmInput 日程調整の確定
+type PollConfirmInput struct {
+	OptionID string `json:"optionId" binding:"required"`
+}
+
+// PollConfirmResult 確定して作成したイベントと、招待者の予定との重複
+type PollConfirmResult struct {
+	Poll      *models.EventPoll `json:"poll"`
+	Event     *models.Event     `json:"event"`
+	Conflicts []EventConflict   `json:"conflicts"`
+}
+
+type PollService struct {
+	db              *gorm.DB
+	notifier        Notifier
+	attendeeService *AttendeeService
+}
+
+func NewPollService(db *gorm.DB, notifier Notifier, attendeeService *AttendeeService) *PollService {
+	return &PollService{db: db, notifier: notifier, attendeeService: attendeeService}
+}
+
+// ListPolls 作成した、または招待された日程調整の一覧（既定は新しい順）
+func (s *PollService) ListPolls(userID string, sort SortOptions, include IncludeOptions) ([]models.EventPoll, error) {
+	invited := s.db.Session(&gorm.Session{NewDB: true}).Model(&models.EventPollInvitee{}).
+		Select("poll_id").Where("user_id = ?", userID)
+	var polls []models.EventPoll
+	query := preload(s.db, include).Where("creator_id = ? OR id IN (?)", userID, invited)
+	if err := orderBy(query, sort, "created_at DESC").Find(&polls).Error; err != nil {
+		return nil, err
+	}
+	return polls, nil
+}
+
+// CreatePoll 日程調整を作成し、招待者に通知する
+//
+// チームを指定する場合は作成者がそのチームのメンバーであること。作成者は招待者に含めない。
+func (s *PollService) CreatePoll(userID string, input PollInput) (*models.EventPoll, error) {
+	title := strings.TrimSpace(input.Title)
+	if title == "" {
+		return nil, fmt.Errorf("%w: titleは必須です", ErrInvalidInput)
+	}
+	if _, err := models.LoadLocation(input.TimeZone); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if len(input.Options) == 0 || len(input.Options) > maxPollOptions {
+		return nil, fmt.Errorf("%w: 候補の日時は1〜%d件で指定してください", ErrInvalidInput, maxPollOptions)
+	}
+	options := make([]models.EventPollOption, 0, len(input.Options))
+	for _, o := range input.Options {
+		if !o.EndDate.After(o.StartDate) {
+			return nil, fmt.Errorf("%w: 候補の終了日時は開始日時より後にしてください", ErrInvalidInput)
+		}
+		options = append(options, models.EventPollOption{StartDate: o.StartDate, EndDate: o.EndDate})
+	}
+	sort.Slice(options, func(i, j int) bool {
+		return options[i].StartDate.Before(options[j].StartDate)
+	})
+
+	if input.TeamID != nil && *input.TeamID == "" {
+		input.TeamID = nil
+	}
+	if input.TeamID != nil {
+		if err := ensureTeamMember(s.db, *input.TeamID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	var inviteeIDs []string
+	for _, id := range uniqueStrings(input.InviteeIDs) {
+		if id != userID {
+			inviteeIDs = append(inviteeIDs, id)
+		}
+	}
+	if len(inviteeIDs) > 0 {
+		var count int64
+		if err := s.db.Model(&models.User{}).Scopes(inWorkspaceOf(userID)).Where("id IN ?", inviteeIDs).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if int(count) != len(inviteeIDs) {
+			return nil, fmt.Errorf("%w: 招待するユーザーが見つかりません", ErrInvalidInput)
+		}
+	}
+
+	poll := models.EventPoll{
+		Title:       title,
+		Description: input.Description,
+		Location:    input.Location,
+		TimeZone:    input.TimeZone,
+		Status:      models.EventPollStatusOpen,
+		CreatorID:   userID,
+		TeamID:      input.TeamID,
+		Options:     options,
+	}
+	for _, id := range inviteeIDs {
+		poll.Invitees = append(poll.Invitees, models.EventPollInvitee{UserID: id})
+	}
+	if err := s.db.Create(&poll).Error; err != nil {
+		return nil, err
+	}
+
+	for _, id := range inviteeIDs {
+		if err := s.notifier.Notify(NotificationMessage{
+			UserID:     id,
+			Type:       NotificationTypePollInvitation,
+			Title:      "日程調整に招待されました",
+			Body:       poll.Title,
+			EntityType: "poll",
+			EntityID:   poll.ID,
+		}); err != nil {
+			log.Printf("日程調整の通知の送信に失敗しました: %v", err)
+		}
+	}
+	return s.GetPoll(poll.ID, userID)
+}
+
+// GetPoll 日程調整の候補・投票・招待者（作成者と招待者のみ）
+func (s *PollService) GetPoll(pollID, userID string) (*models.EventPoll, error) {
+	poll, err := s.findPollForUser(pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Preload("Creator").
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("start_date ASC")
+		}).
+		Preload("Options.Votes").
+		Preload("Invitees", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("Invitees.User").
+		First(poll, "id = ?", poll.ID).Error; err != nil {
+		return nil, err
+	}
+	for i := range poll.Options {
+		for _, v := range poll.Options[i].Votes {
+			switch v.Response {
+			case models.PollResponseYes:
+				poll.Options[i].YesCount++
+			case models.PollResponseIfNeeded:
+				poll.Options[i].IfNeededCount++
+			}
+		}
+	}
+	return poll, nil
+}
+
+// Vote 日程調整に投票する（自分の投票を置き換える。確定後は投票できない）
+func (s *PollService) Vote(pollID, userID string, input PollVoteInput) (*models.EventPoll, error) {
+	poll, err := s.findPollForUser(pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if poll.Status != models.EventPollStatusOpen {
+		return nil, fmt.Errorf("%w: 確定した日程調整には投票できません", ErrPreconditionFailed)
+	}
+
+	var optionIDs []string
+	if err := s.db.Model(&models.EventPollOption{}).Where("poll_id = ?", poll.ID).
+		Pluck("id", &optionIDs).Error; err != nil {
+		return nil, err
+	}
+	valid := make(map[string]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		valid[id] = true
+	}
+	seen := map[string]bool{}
+	votes := make([]models.EventPollVote, 0, len(input.Votes))
+	for _, v := range input.Votes {
+		if !valid[v.OptionID] {
+			return nil, fmt.Errorf("%w: 候補 %s が見つかりません", ErrInvalidInput, v.OptionID)
+		}
+		if seen[v.OptionID] {
+			return nil, fmt.Errorf("%w: 同じ候補に複数回投票できません", ErrInvalidInput)
+		}
+		seen[v.OptionID] = true
+		response := models.PollResponse(strings.ToUpper(strings.TrimSpace(v.Response)))
+		if !response.Valid() {
+			return nil, fmt.Errorf("%w: responseはYES・IF_NEEDED・NOのいずれかを指定してください", ErrInvalidInput)
+		}
+		votes = append(votes, models.EventPollVote{
+			PollID:   poll.ID,
+			OptionID: v.OptionID,
+			UserID:   userID,
+			Response: response,
+		})
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("poll_id = ? AND user_id = ?", poll.ID, userID).
+			Delete(&models.EventPollVote{}).Error; err != nil {
+			return err
+		}
+		if len(votes) > 0 {
+			return tx.Create(&votes).Error
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return s.GetPoll(poll.ID, userID)
+}
+
+// ConfirmPoll 候補を確定してイベントを作成し、招待者をイベントに招待する（作成者のみ）
+//
+// 招待者には通常のイベントの招待と同じく通知と招待メールを送る。チームで重複が禁止されていて
+// 招待者の予定と重なる場合はイベントを作成せず、日程調整は確定前に戻す。
+func (s *PollService) ConfirmPoll(pollID, userID string, input PollConfirmInput) (*PollConfirmResult, error) {
+	poll, err := s.findPollForUser(pollID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if poll.CreatorID != userID {
+		return nil, ErrForbidden
+	}
+	var option models.EventPollOption
+	if err := s.db.First(&option, "id = ? AND poll_id = ?", input.OptionID, poll.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: 候補が見つかりません", ErrInvalidInput)
+		}
+		return nil, err
+	}
+
+	// 同時に確定されないように、状態を先に変えてから作成する
+	result := s.db.Model(&models.EventPoll{}).
+		Where("id = ? AND status = ?", poll.ID, models.EventPollStatusOpen).
+		Updates(map[string]interface{}{"status": models.EventPollStatusConfirmed, "option_id": option.ID})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: 日程調整は既に確定しています", ErrPreconditionFailed)
+	}
+	reopen := func() {
+		if err := s.db.Model(&models.EventPoll{}).Where("id = ?", poll.ID).
+			Updates(map[string]interface{}{"status": models.EventPollStatusOpen, "option_id": nil}).Error; err != nil {
+			log.Printf("日程調整 %s を確定前に戻せませんでした: %v", poll.ID, err)
+		}
+	}
+
+	event := models.Event{
+		Title:       poll.Title,
+		Description: poll.Description,
+		Location:    poll.Location,
+		StartDate:   option.StartDate,
+		EndDate:     option.EndDate,
+		TimeZone:    poll.TimeZone,
+		CreatorID:   userID,
+		TeamID:      poll.TeamID,
+	}
+	if err := s.db.Create(&event).Error; err != nil {
+		reopen()
+		return nil, err
+	}
+
+	conflicts := []EventConflict{}
+	var inviteeIDs []string
+	if err := s.db.Model(&models.EventPollInvitee{}).Where("poll_id = ?", poll.ID).
+		Order("created_at ASC").Pluck("user_id", &inviteeIDs).Error; err != nil {
+		s.db.Unscoped().Delete(&event)
+		reopen()
+		return nil, err
+	}
+	if len(inviteeIDs) > 0 {
+		invited, err := s.attendeeService.InviteAttendees(event.ID, userID, inviteeIDs)
+		if err != nil {
+			s.db.Unscoped().Delete(&event)
+			reopen()
+			return nil, err
+		}
+		conflicts = invited.Conflicts
+	}
+
+	if err := s.db.Model(&models.EventPoll{}).Where("id = ?", poll.ID).Update("event_id", event.ID).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Scopes(PreloadAttendees).First(&event, "id = ?", event.ID).Error; err != nil {
+		return nil, err
+	}
+	confirmed, err := s.GetPoll(poll.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &PollConfirmResult{Poll: confirmed, Event: &event, Conflicts: conflicts}, nil
+}
+
+// DeletePoll 日程調整を削除する（作成者のみ。確定して作成したイベントは残る）
+func (s *PollService) DeletePoll(pollID, userID string) error {
+	poll, err := s.findPollForUser(pollID, userID)
+	if err != nil {
+		return err
+	}
+	if poll.CreatorID != userID {
+		return ErrForbidden
+	}
+	return s.db.Delete(poll).Error
+}
+
+// findPollForUser 日程調整を取得し、ユーザーが作成者か招待者か確認する
+func (s *PollService) findPollForUser(pollID, userID string) (*models.EventPoll, error) {
+	var poll models.EventPoll
+	if err := s.db.First(&poll, "id = ?", pollID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if poll.CreatorID == userID {
+		return &poll, nil
+	}
+	var count int64
+	if err := s.db.Model(&models.EventPollInvitee{}).Where("poll_id = ? AND user_id = ?", poll.ID, userID).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrForbidden
+	}
+	return &poll, nil
+}
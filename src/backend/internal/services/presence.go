@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+
+	"task-calendar-backend/internal/models"
+)
+
+// 閲覧中の画面の種類
+const (
+	PresenceTask = "task" // タスクの詳細
+	PresenceTeam = "team" // チームのボード
+)
+
+// RealtimeEventPresenceUpdated 閲覧中・接続中のユーザーが変わったことの通知（受け取った側で取得し直す）
+const RealtimeEventPresenceUpdated = "presence.updated"
+
+// presenceLocation 購読者が閲覧中の画面
+type presenceLocation struct {
+	kind   string // task / team
+	id     string
+	teamID string // 変更を通知するチーム
+}
+
+// Presence 閲覧中・接続中のユーザー（同じユーザーの複数の接続は1人として数える）
+type Presence struct {
+	Online  []models.User `json:"online,omitempty"` // チームの変更を購読している（アプリを開いている）ユーザー
+	Viewers []models.User `json:"viewers"`
+}
+
+// View 閲覧中のタスク（kind が task）またはチームのボード（kind が team）を設定する（チームのメンバーのみ）
+//
+// 閲覧中の画面は接続ごとに1つで、別の画面を設定すると前の画面の閲覧は終わる。
+func (s *RealtimeService) View(sub *RealtimeSubscriber, kind, id string) error {
+	var location presenceLocation
+	switch kind {
+	case PresenceTask:
+		task, err := findTaskForMember(s.db, id, sub.UserID)
+		if err != nil {
+			return err
+		}
+		location = presenceLocation{kind: PresenceTask, id: task.ID, teamID: task.TeamID}
+	case PresenceTeam:
+		if err := ensureTeamMember(s.db, id, sub.UserID); err != nil {
+			return err
+		}
+		location = presenceLocation{kind: PresenceTeam, id: id, teamID: id}
+	default:
+		return fmt.Errorf("%w: 閲覧中の画面はtaskかteamで指定してください", ErrInvalidInput)
+	}
+
+	s.mu.Lock()
+	previous := sub.viewing
+	sub.viewing = &location
+	s.mu.Unlock()
+
+	if previous != nil && *previous != location {
+		s.publishPresence(*previous)
+	}
+	s.publishPresence(location)
+	return nil
+}
+
+// Unview 閲覧中の画面の設定を外す
+func (s *RealtimeService) Unview(sub *RealtimeSubscriber) {
+	s.mu.Lock()
+	previous := sub.viewing
+	sub.viewing = nil
+	s.mu.Unlock()
+
+	if previous != nil {
+		s.publishPresence(*previous)
+	}
+}
+
+// TaskViewers タスクを閲覧中のユーザー（チームのメンバーのみ）
+func (s *RealtimeService) TaskViewers(taskID, userID string) (*Presence, error) {
+	if _, err := findTaskForMember(s.db, taskID, userID); err != nil {
+		return nil, err
+	}
+	viewers, err := s.presenceUsers(func(sub *RealtimeSubscriber) bool {
+		return sub.viewing != nil && sub.viewing.kind == PresenceTask && sub.viewing.id == taskID
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Presence{Viewers: viewers}, nil
+}
+
+// TeamPresence チームの接続中のユーザーと、チームのボードを閲覧中のユーザー（チームのメンバーのみ）
+func (s *RealtimeService) TeamPresence(teamID, userID string) (*Presence, error) {
+	if err := ensureTeamMember(s.db, teamID, userID); err != nil {
+		return nil, err
+	}
+	online, err := s.presenceUsers(func(sub *RealtimeSubscriber) bool {
+		return sub.teams[teamID]
+	})
+	if err != nil {
+		return nil, err
+	}
+	viewers, err := s.presenceUsers(func(sub *RealtimeSubscriber) bool {
+		return sub.viewing != nil && sub.viewing.kind == PresenceTeam && sub.viewing.id == teamID
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Presence{Online: online, Viewers: viewers}, nil
+}
+
+// presenceUsers 条件に合う購読者のユーザー
+func (s *RealtimeService) presenceUsers(match func(*RealtimeSubscriber) bool) ([]models.User, error) {
+	s.mu.Lock()
+	seen := map[string]bool{}
+	var userIDs []string
+	for sub := range s.subscribers {
+		if !seen[sub.UserID] && match(sub) {
+			seen[sub.UserID] = true
+			userIDs = append(userIDs, sub.UserID)
+		}
+	}
+	s.mu.Unlock()
+
+	users := []models.User{}
+	if len(userIDs) == 0 {
+		return users, nil
+	}
+	if err := s.db.Where("id IN ?", userIDs).Order("username").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// publishPresence 画面の閲覧中・接続中のユーザーが変わったことをチームに通知する
+func (s *RealtimeService) publishPresence(location presenceLocation) {
+	s.Publish(RealtimeEvent{
+		Type:       RealtimeEventPresenceUpdated,
+		EntityType: location.kind,
+		EntityID:   location.id,
+		TeamID:     location.teamID,
+	})
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const pushSendTimeout = 15 * time.Second
+
+// errPushTokenInvalid 端末のトークンが無効になった（アプリの削除など）。トークンの登録を削除する
+var errPushTokenInvalid = errors.New("プッシュ通知の宛先が無効です")
+
+// pushNotificationTypes プッシュ通知で送る通知種別
+var pushNotificationTypes = map[string]bool{
+	NotificationTypeEventReminder: true,
+	NotificationTypeMention:       true,
+}
+
+// PushMessage 端末に送るプッシュ通知の内容
+type PushMessage struct {
+	Title string
+	Body  string
+	// アプリで開く画面を決めるための値
+	Data map[string]string
+}
+
+// PushSender プッシュ通知の送信サービス（FCM・APNs）
+type PushSender interface {
+	Platform() models.DevicePlatform
+	// Send トークンが無効になっている場合は errPushTokenInvalid を返す
+	Send(ctx context.Context, token string, msg PushMessage) error
+}
+
+// DeviceTokenInput プッシュ通知の宛先の登録
+type DeviceTokenInput struct {
+	Platform   models.DevicePlatform `json:"platform" binding:"required,oneof=IOS ANDROID"`
+	Token      string                `json:"token" binding:"required,max=4096"`
+	DeviceName string                `json:"deviceName" binding:"max=100"`
+}
+
+// PushService モバイルアプリの端末の登録と、リマインダー・メンションのプッシュ通知
+type PushService struct {
+	db      *gorm.DB
+	senders map[models.DevicePlatform]PushSender
+}
+
+func NewPushService(db *gorm.DB, senders ...PushSender) *PushService {
+	registered := make(map[models.DevicePlatform]PushSender, len(senders))
+	for _, sender := range senders {
+		registered[sender.Platform()] = sender
+	}
+	return &PushService{db: db, senders: registered}
+}
+
+// Enabled 送信サービスが1つでも設定されているか
+func (s *PushService) Enabled() bool {
+	return len(s.senders) > 0
+}
+
+// ListDevices 自分が登録した端末
+func (s *PushService) ListDevices(userID string, sort SortOptions) ([]models.DeviceToken, error) {
+	var devices []models.DeviceToken
+	if err := orderBy(s.db.Where("user_id = ?", userID), sort, "last_seen_at DESC").Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// RegisterDevice 端末を登録する（アプリの起動ごとに呼ぶ。別のユーザーが登録していたトークンは、ログインし直したユーザーのものにする）
+func (s *PushService) RegisterDevice(userID string, input DeviceTokenInput) (*models.DeviceToken, error) {
+	if _, ok := s.senders[input.Platform]; !ok {
+		return nil, fmt.Errorf("%w: %sへのプッシュ通知は設定されていません", ErrInvalidInput, input.Platform)
+	}
+	token := strings.TrimSpace(input.Token)
+	if token == "" || len(token) > 4096 {
+		return nil, fmt.Errorf("%w: tokenが不正です", ErrInvalidInput)
+	}
+
+	device := models.DeviceToken{
+		UserID:     userID,
+		Platform:   input.Platform,
+		Token:      token,
+		DeviceName: strings.TrimSpace(input.DeviceName),
+		LastSeenAt: time.Now(),
+	}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "device_name", "last_seen_at"}),
+	}).Create(&device).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.First(&device, "token = ?", token).Error; err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// DeleteDevice 端末の登録を削除する（ログアウト時など）
+func (s *PushService) DeleteDevice(deviceID, userID string) error {
+	result := s.db.Where("id = ? AND user_id = ?", deviceID, userID).Delete(&models.DeviceToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Supports プッシュ通知で送る通知種別か（通知の振り分けで使う）
+func (s *PushService) Supports(notificationType string) bool {
+	return pushNotificationTypes[notificationType]
+}
+
+// Notify 通知をユーザーの全端末に送る（無効になったトークンは登録を削除する）
+func (s *PushService) Notify(msg NotificationMessage) error {
+	if !s.Supports(msg.Type) {
+		return nil
+	}
+	var devices []models.DeviceToken
+	if err := s.db.Where("user_id = ?", msg.UserID).Find(&devices).Error; err != nil {
+		return err
+	}
+
+	push := PushMessage{
+		Title: msg.Title,
+		Body:  msg.Body,
+		Data:  map[string]string{"type": msg.Type, "entityType": msg.EntityType, "entityId": msg.EntityID},
+	}
+	var first error
+	for i := range devices {
+		device := &devices[i]
+		sender, ok := s.senders[device.Platform]
+		if !ok {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), pushSendTimeout)
+		err := sender.Send(ctx, device.Token, push)
+		cancel()
+		if errors.Is(err, errPushTokenInvalid) {
+			if err := s.db.Delete(device).Error; err != nil {
+				log.Printf("無効になったプッシュ通知の宛先の削除に失敗しました: %v", err)
+			}
+			continue
+		}
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	apnsProductionURL = "https://api.push.apple.com"
+	apnsSandboxURL    = "https://api.sandbox.push.apple.com"
+	// プロバイダートークンは20分〜60分の間に作り直す必要がある
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+// APNsSender Apple Push Notification service（トークン認証）によるiOS端末へのプッシュ通知
+type APNsSender struct {
+	keyID   string
+	teamID  string
+	topic   string // アプリのバンドルID
+	baseURL string
+	key     interface{}
+	client  *http.Client
+
+	mu        sync.Mutex
+	token     string
+	tokenTime time.Time
+}
+
+// NewAPNsSender APNsの認証キー（.p8）を読み込む（production が false の場合は開発用の環境に送る）
+func NewAPNsSender(keyFile, keyID, teamID, topic string, production bool) (*APNsSender, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("APNsの認証キーを読み込めません: %v", err)
+	}
+	if keyID == "" || teamID == "" || topic == "" {
+		return nil, fmt.Errorf("APNsのキーID・チームID・トピックは必須です")
+	}
+	baseURL := apnsSandboxURL
+	if production {
+		baseURL = apnsProductionURL
+	}
+	// APNsはHTTP/2のみ受け付ける（http.Client はTLSで自動的にHTTP/2を使う）
+	return &APNsSender{
+		keyID:   keyID,
+		teamID:  teamID,
+		topic:   topic,
+		baseURL: baseURL,
+		key:     key,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (s *APNsSender) Platform() models.DevicePlatform {
+	return models.DevicePlatformIOS
+}
+
+func (s *APNsSender) Send(ctx context.Context, token string, msg PushMessage) error {
+	providerToken, err := s.providerToken()
+	if err != nil {
+		return err
+	}
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{"title": msg.Title, "body": msg.Body},
+			"sound": "default",
+		},
+	}
+	for k, v := range msg.Data {
+		payload[k] = v
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/3/device/"+token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("apns-topic", s.topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	var result struct {
+		Reason string `json:"reason"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&result)
+	if resp.StatusCode == http.StatusGone || result.Reason == "BadDeviceToken" || result.Reason == "Unregistered" {
+		return errPushTokenInvalid
+	}
+	return fmt.Errorf("APNsがエラーを返しました（HTTP %d）: %s", resp.StatusCode, result.Reason)
+}
+
+// providerToken 認証キーで署名したプロバイダートークン（有効な間は使い回す）
+func (s *APNsSender) providerToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Since(s.tokenTime) < apnsTokenLifetime {
+		return s.token, nil
+	}
+	now := time.Now()
+	t := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": s.teamID,
+		"iat": now.Unix(),
+	})
+	t.Header["kid"] = s.keyID
+	signed, err := t.SignedString(s.key)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.tokenTime = signed, now
+	return signed, nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// FCMSender Firebase Cloud Messaging（HTTP v1 API）によるAndroid端末へのプッシュ通知
+type FCMSender struct {
+	projectID   string
+	clientEmail string
+	privateKey  interface{}
+	tokenURL    string
+	client      *http.Client
+
+	mu    sync.Mutex
+	token googleToken
+}
+
+// NewFCMSender Firebaseのサービスアカウントの鍵ファイル（JSON）を読み込む
+func NewFCMSender(credentialsFile string) (*FCMSender, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	var key struct {
+		ProjectID   string `json:"project_id"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("サービスアカウントの鍵ファイルを解析できません: %v", err)
+	}
+	if key.ProjectID == "" {
+		return nil, fmt.Errorf("サービスアカウントの鍵ファイルに project_id がありません")
+	}
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(key.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("サービスアカウントの秘密鍵を読み込めません: %v", err)
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &FCMSender{
+		projectID:   key.ProjectID,
+		clientEmail: key.ClientEmail,
+		privateKey:  privateKey,
+		tokenURL:    key.TokenURI,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (s *FCMSender) Platform() models.DevicePlatform {
+	return models.DevicePlatformAndroid
+}
+
+func (s *FCMSender) Send(ctx context.Context, token string, msg PushMessage) error {
+	accessToken, err := s.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token":        token,
+			"notification": map[string]string{"title": msg.Title, "body": msg.Body},
+			"data":         msg.Data,
+			"android":      map[string]string{"priority": "high"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	endpoint := "https://fcm.googleapis.com/v1/projects/" + url.PathEscape(s.projectID) + "/messages:send"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	var result struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result)
+	for _, d := range result.Error.Details {
+		if d.ErrorCode == "UNREGISTERED" || d.ErrorCode == "INVALID_ARGUMENT" {
+			return errPushTokenInvalid
+		}
+	}
+	return fmt.Errorf("FCMがエラーを返しました（HTTP %d）: %s", resp.StatusCode, result.Error.Message)
+}
+
+// accessToken 署名付きJWTでアクセストークンを取得する（有効期限まで使い回す）
+func (s *FCMSender) accessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Now().Before(s.token.expiresAt.Add(-time.Minute)) {
+		return s.token.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.clientEmail,
+		"scope": fcmScope,
+		"aud":   s.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(s.privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var body struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int    `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("Googleのトークンの応答を解析できません（HTTP %d）", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return "", fmt.Errorf("Googleのトークンを取得できませんでした: %s %s", body.Error, body.ErrorDescription)
+	}
+	s.token = googleToken{accessToken: body.AccessToken, expiresAt: now.Add(time.Duration(body.ExpiresIn) * time.Second)}
+	return body.AccessToken, nil
+}
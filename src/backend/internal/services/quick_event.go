@@ -0,0 +1,565 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/recurrence"
+)
+
+// 自然文の長さの上限
+const maxQuickEventText = 500
+
+// 時刻の指定だけで終了時刻がない場合の長さ
+const defaultQuickEventDuration = time.Hour
+
+// QuickEventInput 自然文（例: "Standup every weekday 9:30-9:45"）によるイベントの作成
+type QuickEventInput struct {
+	Text     string `json:"text" binding:"required,notblank,max=500"`
+	TimeZone string `json:"timeZone" binding:"omitempty,timezone"` // 空の場合はユーザーのタイムゾーン
+	Create   bool   `json:"create"`                                // false の場合は解釈した結果を返すだけで作成しない
+}
+
+// QuickEventDraft 自然文から読み取ったイベントの内容
+type QuickEventDraft struct {
+	Title       string    `json:"title"`
+	StartDate   time.Time `json:"startDate"`
+	EndDate     time.Time `json:"endDate"`
+	AllDay      bool      `json:"allDay"`
+	TimeZone    string    `json:"timeZone"`
+	IsRecurring bool      `json:"isRecurring"`
+	Recurrence  string    `json:"recurrence,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	Summary     string    `json:"summary"` // 確認用の解釈（例: 「Standup 2026-10-16 09:30〜09:45 平日の毎日」）
+}
+
+// QuickEventResult 自然文の解釈と、作成した場合はそのイベント
+type QuickEventResult struct {
+	Interpretation QuickEventDraft `json:"interpretation"`
+	Event          *models.Event   `json:"event,omitempty"`
+}
+
+// QuickEvent 自然文を解釈し、create を指定した場合は個人のイベントとして作成する
+//
+// 日付を省略した場合は今日（時刻が過ぎていれば翌日、繰り返しの場合は最初に該当する日）、
+// 時刻を省略した場合は終日のイベントになる。
+func (s *EventService) QuickEvent(userID string, input QuickEventInput) (*QuickEventResult, error) {
+	timeZone := input.TimeZone
+	if timeZone == "" {
+		var user models.User
+		if err := s.db.Select("id", "time_zone").First(&user, "id = ?", userID).Error; err != nil {
+			return nil, err
+		}
+		timeZone = user.TimeZone
+	}
+	loc, err := models.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	draft, err := parseQuickEvent(input.Text, time.Now().In(loc))
+	if err != nil {
+		return nil, err
+	}
+	draft.TimeZone = timeZone
+	result := &QuickEventResult{Interpretation: *draft}
+	if !input.Create {
+		return result, nil
+	}
+
+	event := models.Event{
+		Title:       draft.Title,
+		StartDate:   draft.StartDate,
+		EndDate:     draft.EndDate,
+		AllDay:      draft.AllDay,
+		TimeZone:    draft.TimeZone,
+		IsRecurring: draft.IsRecurring,
+		Recurrence:  draft.Recurrence,
+		Location:    draft.Location,
+		CreatorID:   userID,
+	}
+	if err := s.db.Create(&event).Error; err != nil {
+		return nil, err
+	}
+	result.Event = &event
+	return result, nil
+}
+
+const quickWeekday = `(monday|mon|tuesday|tues|tue|wednesday|wed|thursday|thurs|thu|friday|fri|saturday|sat|sunday|sun)`
+const quickMonth = `(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?`
+
+var (
+	quickLocationRe  = regexp.MustCompile(`\s@\s*(.+)$`)
+	quickWeekdaysRe  = regexp.MustCompile(`(?i)\b(?:every\s+weekdays?|on\s+weekdays|weekdays)\b`)
+	quickWeekendsRe  = regexp.MustCompile(`(?i)\b(?:every\s+weekends?|on\s+weekends|weekends)\b`)
+	quickEveryDaysRe = regexp.MustCompile(`(?i)\bevery\s+(other\s+)?(` + quickWeekday + `(?:\s*(?:,|and|&|/)\s*` + quickWeekday + `)*)\b`)
+	quickIntervalRe  = regexp.MustCompile(`(?i)\bevery\s+(\d+|other)\s+(day|week|month|year)s?\b`)
+	quickEveryRe     = regexp.MustCompile(`(?i)\bevery\s+(day|week|month|year)\b|\b(daily|weekly|monthly|yearly|annually)\b`)
+	quickCountRe     = regexp.MustCompile(`(?i)\b(\d+)\s+times\b`)
+	quickWeekdayRe   = regexp.MustCompile(`(?i)` + quickWeekday)
+
+	quickISODateRe   = regexp.MustCompile(`(?i)\b(?:on\s+)?(\d{4})-(\d{1,2})-(\d{1,2})\b`)
+	quickMonthDayRe  = regexp.MustCompile(`(?i)\b(?:on\s+)?` + quickMonth + `\s+(\d{1,2})(?:st|nd|rd|th)?(?:,?\s+(\d{4}))?\b`)
+	quickDayMonthRe  = regexp.MustCompile(`(?i)\b(?:on\s+)?(\d{1,2})(?:st|nd|rd|th)?\s+` + quickMonth + `(?:\s+(\d{4}))?\b`)
+	quickSlashDateRe = regexp.MustCompile(`(?i)\b(?:on\s+)?(\d{1,2})/(\d{1,2})(?:/(\d{4}))?\b`)
+	quickRelativeRe  = regexp.MustCompile(`(?i)\b(day\s+after\s+tomorrow|tomorrow|today|tonight)\b`)
+	quickOnWeekdayRe = regexp.MustCompile(`(?i)\b(?:on\s+)?(next\s+|this\s+)?` + quickWeekday + `\b`)
+
+	quickDurationRe = regexp.MustCompile(`(?i)\bfor\s+(\d+(?:\.\d+)?|an?|half\s+an)\s*(minutes?|mins?|m|hours?|hrs?|h)\b`)
+	quickRangeRe    = regexp.MustCompile(`(?i)\b(?:from\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)?\s*(?:-|–|~|to|until|till)\s*(\d{1,2})(?::(\d{2}))?\s*(am|pm)?\b`)
+	quickMeridiemRe = regexp.MustCompile(`(?i)\b(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)\b`)
+	quickClockRe    = regexp.MustCompile(`(?i)\b(?:at\s+)?(\d{1,2}):(\d{2})\b`)
+	quickNoonRe     = regexp.MustCompile(`(?i)\b(?:at\s+)?(noon|midnight)\b`)
+
+	quickConnectorRe = regexp.MustCompile(`(?i)^(?:(?:on|at|from|for|every|,|-)\s+)+|(?:\s+(?:on|at|from|for|every|,|-))+$`)
+)
+
+var quickMonths = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+var quickWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// quickText 自然文から読み取った部分を取り除きながら解釈する
+type quickText struct {
+	text string
+}
+
+// take 最初に一致した部分を取り除いて、そのサブマッチを返す（一致しない場合は nil）
+func (q *quickText) take(re *regexp.Regexp) []string {
+	loc := re.FindStringSubmatchIndex(q.text)
+	if loc == nil {
+		return nil
+	}
+	match := make([]string, len(loc)/2)
+	for i := range match {
+		if loc[2*i] >= 0 {
+			match[i] = q.text[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	q.text = q.text[:loc[0]] + " " + q.text[loc[1]:]
+	return match
+}
+
+// parseQuickEvent 自然文をイベントの内容に変換する（now は基準となるタイムゾーンの現在日時）
+func parseQuickEvent(text string, now time.Time) (*QuickEventDraft, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("%w: textは必須です", ErrInvalidInput)
+	}
+	if len([]rune(text)) > maxQuickEventText {
+		return nil, fmt.Errorf("%w: textは%d文字以内で指定してください", ErrInvalidInput, maxQuickEventText)
+	}
+	q := &quickText{text: text}
+	draft := &QuickEventDraft{}
+
+	if m := q.take(quickLocationRe); m != nil {
+		draft.Location = strings.TrimSpace(m[1])
+	}
+
+	rule := quickRecurrence(q)
+
+	loc := now.Location()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	day, explicitDate, err := quickDate(q, today)
+	if err != nil {
+		return nil, err
+	}
+
+	var duration time.Duration
+	if m := q.take(quickDurationRe); m != nil {
+		duration = quickDuration(m[1], m[2])
+	}
+	startMinute, endMinute, hasTime, err := quickTimes(q)
+	if err != nil {
+		return nil, err
+	}
+
+	title := strings.Join(strings.Fields(q.text), " ")
+	for {
+		trimmed := strings.TrimSpace(quickConnectorRe.ReplaceAllString(title, ""))
+		if trimmed == title {
+			break
+		}
+		title = trimmed
+	}
+	if title == "" {
+		return nil, fmt.Errorf("%w: タイトルを読み取れませんでした", ErrInvalidInput)
+	}
+	draft.Title = title
+
+	// 繰り返しの場合は最初に該当する日から始める
+	matches := func(d time.Time) bool {
+		if rule == nil || len(rule.ByDay) == 0 {
+			return true
+		}
+		for _, wd := range rule.ByDay {
+			if wd.Weekday == d.Weekday() {
+				return true
+			}
+		}
+		return false
+	}
+	advance := func() {
+		day = day.AddDate(0, 0, 1)
+		for i := 0; i < 7 && !matches(day); i++ {
+			day = day.AddDate(0, 0, 1)
+		}
+	}
+	if !matches(day) {
+		advance()
+	}
+
+	if hasTime {
+		at := func(d time.Time, minute int) time.Time {
+			return time.Date(d.Year(), d.Month(), d.Day(), minute/60, minute%60, 0, 0, loc)
+		}
+		start := at(day, startMinute)
+		if !explicitDate && start.Before(now) {
+			advance()
+			start = at(day, startMinute)
+		}
+		end := start.Add(defaultQuickEventDuration)
+		switch {
+		case endMinute >= 0:
+			end = at(day, endMinute)
+			if !end.After(start) {
+				end = end.AddDate(0, 0, 1)
+			}
+		case duration > 0:
+			end = start.Add(duration)
+		}
+		draft.StartDate, draft.EndDate = start, end
+	} else {
+		draft.AllDay = true
+		draft.StartDate = models.AllDayDate(day)
+		draft.EndDate = draft.StartDate.AddDate(0, 0, 1)
+	}
+
+	if rule != nil {
+		draft.IsRecurring = true
+		draft.Recurrence = rule.String()
+	}
+	draft.Summary = quickSummary(draft, rule)
+	return draft, nil
+}
+
+// quickRecurrence 繰り返しの指定（"every weekday" "every mon and wed" "every 2 weeks" "daily" など）
+func quickRecurrence(q *quickText) *recurrence.Rule {
+	var rule *recurrence.Rule
+	newRule := func(freq recurrence.Frequency, interval int) *recurrence.Rule {
+		return &recurrence.Rule{Freq: freq, Interval: interval, WeekStart: time.Monday}
+	}
+	switch {
+	case q.take(quickWeekdaysRe) != nil:
+		rule = newRule(recurrence.Weekly, 1)
+		for wd := time.Monday; wd <= time.Friday; wd++ {
+			rule.ByDay = append(rule.ByDay, recurrence.WeekdayNum{Weekday: wd})
+		}
+	case q.take(quickWeekendsRe) != nil:
+		rule = newRule(recurrence.Weekly, 1)
+		rule.ByDay = []recurrence.WeekdayNum{{Weekday: time.Saturday}, {Weekday: time.Sunday}}
+	default:
+		if m := q.take(quickEveryDaysRe); m != nil {
+			interval := 1
+			if m[1] != "" {
+				interval = 2
+			}
+			rule = newRule(recurrence.Weekly, interval)
+			seen := map[time.Weekday]bool{}
+			for _, name := range quickWeekdayRe.FindAllString(m[2], -1) {
+				wd := quickWeekdays[strings.ToLower(name)[:3]]
+				if !seen[wd] {
+					seen[wd] = true
+					rule.ByDay = append(rule.ByDay, recurrence.WeekdayNum{Weekday: wd})
+				}
+			}
+		} else if m := q.take(quickIntervalRe); m != nil {
+			interval := 2
+			if n, err := strconv.Atoi(m[1]); err == nil && n > 0 {
+				interval = n
+			}
+			rule = newRule(quickFrequency(m[2]), interval)
+		} else if m := q.take(quickEveryRe); m != nil {
+			unit := m[1]
+			if unit == "" {
+				unit = m[2]
+			}
+			rule = newRule(quickFrequency(unit), 1)
+		}
+	}
+	if rule == nil {
+		return nil
+	}
+	if m := q.take(quickCountRe); m != nil {
+		rule.Count, _ = strconv.Atoi(m[1])
+	}
+	return rule
+}
+
+func quickFrequency(unit string) recurrence.Frequency {
+	switch strings.ToLower(unit) {
+	case "day", "daily":
+		return recurrence.Daily
+	case "week", "weekly":
+		return recurrence.Weekly
+	case "month", "monthly":
+		return recurrence.Monthly
+	default:
+		return recurrence.Yearly
+	}
+}
+
+// quickDate 日付の指定（省略した場合は today）と、日付を明示したか
+//
+// 年を省略した日付が過去になる場合は翌年の日付にする。
+func quickDate(q *quickText, today time.Time) (time.Time, bool, error) {
+	loc := today.Location()
+	date := func(year int, month time.Month, day int, yearGiven bool) (time.Time, bool, error) {
+		if month < time.January || month > time.December || day < 1 || day > 31 {
+			return time.Time{}, false, fmt.Errorf("%w: 日付を読み取れませんでした", ErrInvalidInput)
+		}
+		d := time.Date(year, month, day, 0, 0, 0, 0, loc)
+		if d.Day() != day {
+			return time.Time{}, false, fmt.Errorf("%w: 存在しない日付です", ErrInvalidInput)
+		}
+		if !yearGiven && d.Before(today) {
+			d = d.AddDate(1, 0, 0)
+		}
+		return d, true, nil
+	}
+	year := func(value string) (int, bool) {
+		if value == "" {
+			return today.Year(), false
+		}
+		y, _ := strconv.Atoi(value)
+		return y, true
+	}
+
+	if m := q.take(quickISODateRe); m != nil {
+		y, _ := strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		d, _ := strconv.Atoi(m[3])
+		return date(y, time.Month(mo), d, true)
+	}
+	if m := q.take(quickMonthDayRe); m != nil {
+		d, _ := strconv.Atoi(m[2])
+		y, given := year(m[3])
+		return date(y, quickMonths[strings.ToLower(m[1])], d, given)
+	}
+	if m := q.take(quickDayMonthRe); m != nil {
+		d, _ := strconv.Atoi(m[1])
+		y, given := year(m[3])
+		return date(y, quickMonths[strings.ToLower(m[2])], d, given)
+	}
+	if m := q.take(quickSlashDateRe); m != nil {
+		mo, _ := strconv.Atoi(m[1])
+		d, _ := strconv.Atoi(m[2])
+		y, given := year(m[3])
+		return date(y, time.Month(mo), d, given)
+	}
+	if m := q.take(quickRelativeRe); m != nil {
+		switch strings.Join(strings.Fields(strings.ToLower(m[1])), " ") {
+		case "tomorrow":
+			return today.AddDate(0, 0, 1), true, nil
+		case "day after tomorrow":
+			return today.AddDate(0, 0, 2), true, nil
+		default:
+			return today, true, nil
+		}
+	}
+	if m := q.take(quickOnWeekdayRe); m != nil {
+		wd := quickWeekdays[strings.ToLower(m[2])[:3]]
+		days := (int(wd) - int(today.Weekday()) + 7) % 7
+		if days == 0 && strings.HasPrefix(strings.ToLower(m[1]), "next") {
+			days = 7
+		}
+		return today.AddDate(0, 0, days), true, nil
+	}
+	return today, false, nil
+}
+
+// quickDuration "for 30 minutes" "for 1.5 hours" "for an hour" などの長さ
+func quickDuration(amount, unit string) time.Duration {
+	var value float64
+	switch strings.ToLower(strings.Join(strings.Fields(amount), " ")) {
+	case "a", "an":
+		value = 1
+	case "half an":
+		value = 0.5
+	default:
+		value, _ = strconv.ParseFloat(amount, 64)
+	}
+	if strings.HasPrefix(strings.ToLower(unit), "h") {
+		return time.Duration(value * float64(time.Hour))
+	}
+	return time.Duration(value * float64(time.Minute))
+}
+
+// quickTimes 開始・終了の時刻（0時からの分、終了時刻がない場合は -1）
+func quickTimes(q *quickText) (int, int, bool, error) {
+	invalid := func() (int, int, bool, error) {
+		return 0, 0, false, fmt.Errorf("%w: 時刻を読み取れませんでした", ErrInvalidInput)
+	}
+	if m := q.take(quickRangeRe); m != nil {
+		startMeridiem, endMeridiem := strings.ToLower(m[3]), strings.ToLower(m[6])
+		end, ok := quickClock(m[4], m[5], endMeridiem)
+		if !ok {
+			return invalid()
+		}
+		start, ok := quickClock(m[1], m[2], startMeridiem)
+		if !ok {
+			return invalid()
+		}
+		if startMeridiem == "" && endMeridiem != "" {
+			// "9-10am" "11-1pm" は終了の午前・午後に合わせ、開始が終了より後になる場合は午前とする
+			if adjusted, ok := quickClock(m[1], m[2], endMeridiem); ok && adjusted < end {
+				start = adjusted
+			}
+		}
+		if startMeridiem == "" && endMeridiem == "" && end <= start && end < 12*60 {
+			// "3-4" は同じ午前・午後、"11-1" は午後1時まで
+			end += 12 * 60
+		}
+		return start, end, true, nil
+	}
+	if m := q.take(quickMeridiemRe); m != nil {
+		start, ok := quickClock(m[1], m[2], strings.ToLower(m[3]))
+		if !ok {
+			return invalid()
+		}
+		return start, -1, true, nil
+	}
+	if m := q.take(quickClockRe); m != nil {
+		start, ok := quickClock(m[1], m[2], "")
+		if !ok {
+			return invalid()
+		}
+		return start, -1, true, nil
+	}
+	if m := q.take(quickNoonRe); m != nil {
+		if strings.ToLower(m[1]) == "noon" {
+			return 12 * 60, -1, true, nil
+		}
+		return 0, -1, true, nil
+	}
+	return 0, -1, false, nil
+}
+
+// quickClock 時・分・午前午後を0時からの分に変換する
+func quickClock(hour, minute, meridiem string) (int, bool) {
+	h, err := strconv.Atoi(hour)
+	if err != nil {
+		return 0, false
+	}
+	mi := 0
+	if minute != "" {
+		if mi, err = strconv.Atoi(minute); err != nil {
+			return 0, false
+		}
+	}
+	if mi > 59 {
+		return 0, false
+	}
+	switch meridiem {
+	case "am":
+		if h < 1 || h > 12 {
+			return 0, false
+		}
+		if h == 12 {
+			h = 0
+		}
+	case "pm":
+		if h < 1 || h > 12 {
+			return 0, false
+		}
+		if h != 12 {
+			h += 12
+		}
+	default:
+		if h > 23 {
+			return 0, false
+		}
+	}
+	return h*60 + mi, true
+}
+
+// quickSummary 確認用の解釈（タイトル・日時・繰り返し・場所）
+func quickSummary(draft *QuickEventDraft, rule *recurrence.Rule) string {
+	parts := []string{draft.Title}
+	if draft.AllDay {
+		parts = append(parts, draft.StartDate.Format("2006-01-02")+"（終日）")
+	} else {
+		end := draft.EndDate.Format("15:04")
+		if !sameDate(draft.StartDate, draft.EndDate) {
+			end = draft.EndDate.Format("2006-01-02 15:04")
+		}
+		parts = append(parts, draft.StartDate.Format("2006-01-02 15:04")+"〜"+end)
+	}
+	if rule != nil {
+		parts = append(parts, recurrenceLabel(rule))
+	}
+	if draft.Location != "" {
+		parts = append(parts, "@ "+draft.Location)
+	}
+	return strings.Join(parts, " ")
+}
+
+func sameDate(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// recurrenceLabel 繰り返しの説明（例: 「平日の毎日」「2週間ごと 月・水曜日」）
+func recurrenceLabel(rule *recurrence.Rule) string {
+	var label string
+	switch rule.Freq {
+	case recurrence.Daily:
+		label = "毎日"
+		if rule.Interval > 1 {
+			label = fmt.Sprintf("%d日ごと", rule.Interval)
+		}
+	case recurrence.Weekly:
+		label = "毎週"
+		if rule.Interval > 1 {
+			label = fmt.Sprintf("%d週間ごと", rule.Interval)
+		}
+		if len(rule.ByDay) > 0 {
+			days := make([]string, len(rule.ByDay))
+			weekdays := len(rule.ByDay) == 5
+			for i, wd := range rule.ByDay {
+				days[i] = weekdayLabels[wd.Weekday]
+				if wd.Weekday == time.Saturday || wd.Weekday == time.Sunday {
+					weekdays = false
+				}
+			}
+			if weekdays && rule.Interval <= 1 {
+				label = "平日の毎日"
+			} else {
+				label += " " + strings.Join(days, "・") + "曜日"
+			}
+		}
+	case recurrence.Monthly:
+		label = "毎月"
+		if rule.Interval > 1 {
+			label = fmt.Sprintf("%dか月ごと", rule.Interval)
+		}
+	default:
+		label = "毎年"
+		if rule.Interval > 1 {
+			label = fmt.Sprintf("%d年ごと", rule.Interval)
+		}
+	}
+	if rule.Count > 0 {
+		label += fmt.Sprintf("（%d回）", rule.Count)
+	}
+	return label
+}
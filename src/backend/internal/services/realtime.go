@@ -0,0 +1,282 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 購読者ごとに溜めておける未送信の変更通知の数（溢れた購読者は切断する）
+const realtimeBufferSize = 64
+
+// RealtimeEvent リアルタイムに配信する変更の通知
+//
+// 内容は含めないため、受け取った側で必要に応じて取得し直す（閲覧権限の確認はGETのエンドポイントで行う）。
+type RealtimeEvent struct {
+	Type       string `json:"type"` // task.created / event.updated / comment.deleted など
+	EntityType string `json:"entityType"`
+	EntityID   string `json:"entityId"`
+	TeamID     string `json:"teamId,omitempty"`
+	TaskID     string `json:"taskId,omitempty"` // コメントの場合の対象タスク
+	UserID     string `json:"userId,omitempty"` // 入力中の通知では入力しているユーザー、競合の通知では更新を拒否されたユーザー（分かる場合）
+}
+
+// RealtimeSubscriber 変更通知の購読（自分宛ての通知と、参加したチームの通知を受け取る）
+type RealtimeSubscriber struct {
+	UserID  string
+	events  chan RealtimeEvent
+	teams   map[string]bool
+	viewing *presenceLocation // 閲覧中のタスク・チームのボード
+	closed  bool
+
+	typingTaskID string // コメントを入力中のタスク
+	typingAt     time.Time
+}
+
+// Events 変更通知を受け取るチャネル（購読が終わると閉じる）
+func (s *RealtimeSubscriber) Events() <-chan RealtimeEvent {
+	return s.events
+}
+
+// RealtimeService タスク・イベント・コメントの変更を、チームのメンバーに配信する
+type RealtimeService struct {
+	db *gorm.DB
+
+	mu          sync.Mutex
+	subscribers map[*RealtimeSubscriber]struct{}
+
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+}
+
+func NewRealtimeService(db *gorm.DB) *RealtimeService {
+	return &RealtimeService{db: db, subscribers: map[*RealtimeSubscriber]struct{}{}, shutdown: make(chan struct{})}
+}
+
+// Shutdown サーバーの停止を接続中のクライアントに知らせる（WebSocket・Server-Sent Eventsの接続を終わらせる）
+//
+// 接続は停止するまで続くため、実行中のリクエストの終了を待つ前に呼ぶ。クライアントは別のサーバーに再接続する。
+func (s *RealtimeService) Shutdown() {
+	s.shutdownOnce.Do(func() { close(s.shutdown) })
+}
+
+// ShuttingDown サーバーの停止を始めると閉じるチャネル
+func (s *RealtimeService) ShuttingDown() <-chan struct{} {
+	return s.shutdown
+}
+
+// Subscribe 変更通知の購読を始める（最初は自分宛ての通知のみ）
+func (s *RealtimeService) Subscribe(userID string) *RealtimeSubscriber {
+	sub := &RealtimeSubscriber{
+		UserID: userID,
+		events: make(chan RealtimeEvent, realtimeBufferSize),
+		teams:  map[string]bool{},
+	}
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+	return sub
+}
+
+// Unsubscribe 購読を終える（閲覧中・接続中・入力中の表示からも外す）
+func (s *RealtimeService) Unsubscribe(sub *RealtimeSubscriber) {
+	s.mu.Lock()
+	viewing := sub.viewing
+	typingTaskID := sub.typingTaskID
+	teamIDs := make([]string, 0, len(sub.teams))
+	for teamID := range sub.teams {
+		teamIDs = append(teamIDs, teamID)
+	}
+	s.closeLocked(sub)
+	s.mu.Unlock()
+
+	if viewing != nil {
+		s.publishPresence(*viewing)
+	}
+	if typingTaskID != "" {
+		s.publishToTaskViewers(RealtimeEvent{
+			Type:       RealtimeEventCommentTypingStopped,
+			EntityType: "task",
+			EntityID:   typingTaskID,
+			TaskID:     typingTaskID,
+			UserID:     sub.UserID,
+		})
+	}
+	for _, teamID := range teamIDs {
+		s.publishPresence(presenceLocation{kind: PresenceTeam, id: teamID, teamID: teamID})
+	}
+}
+
+// JoinTeam チームの変更通知を受け取るようにする（チームのメンバーのみ。チームの接続中のユーザーに加わる）
+func (s *RealtimeService) JoinTeam(sub *RealtimeSubscriber, teamID string) error {
+	if err := ensureTeamMember(s.db, teamID, sub.UserID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	sub.teams[teamID] = true
+	s.mu.Unlock()
+	s.publishPresence(presenceLocation{kind: PresenceTeam, id: teamID, teamID: teamID})
+	return nil
+}
+
+// LeaveTeam チームの変更通知を受け取らないようにする
+func (s *RealtimeService) LeaveTeam(sub *RealtimeSubscriber, teamID string) {
+	s.mu.Lock()
+	_, joined := sub.teams[teamID]
+	delete(sub.teams, teamID)
+	s.mu.Unlock()
+	if joined {
+		s.publishPresence(presenceLocation{kind: PresenceTeam, id: teamID, teamID: teamID})
+	}
+}
+
+// Publish チーム（TeamIDがある場合）と userIDs のユーザーに変更を通知する
+//
+// 受け取りが追いつかない購読者は、通知の取りこぼしに気づけるよう切断する。
+func (s *RealtimeService) Publish(event RealtimeEvent, userIDs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subscribers {
+		if !sub.receives(event, userIDs) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			s.closeLocked(sub)
+		}
+	}
+}
+
+func (s *RealtimeService) closeLocked(sub *RealtimeSubscriber) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(s.subscribers, sub)
+	close(sub.events)
+}
+
+func (sub *RealtimeSubscriber) receives(event RealtimeEvent, userIDs []string) bool {
+	if event.TeamID != "" && sub.teams[event.TeamID] {
+		return true
+	}
+	for _, id := range userIDs {
+		if id == sub.UserID {
+			return true
+		}
+	}
+	return false
+}
+
+// RegisterCallbacks タスク・イベント・コメントの作成・更新・削除を配信するgormのコールバックを登録する
+//
+// IDの分からない一括更新・削除は配信しない。トランザクション内の変更はコミット前に配信されるが、
+// 受け取った側で取得し直すため、ロールバックされた場合も表示が食い違うことはない。
+func (s *RealtimeService) RegisterCallbacks() error {
+	callbacks := s.db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("realtime:after_create", s.publishChanges("created")); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("realtime:after_update", s.publishChanges("updated")); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:after_update").Register("realtime:conflict", s.publishConflict); err != nil {
+		return err
+	}
+	return callbacks.Delete().After("gorm:delete").Register("realtime:after_delete", s.publishChanges("deleted"))
+}
+
+func (s *RealtimeService) publishChanges(action string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx.Error != nil || tx.RowsAffected == 0 || !tx.Statement.ReflectValue.IsValid() {
+			return
+		}
+		value := reflect.Indirect(tx.Statement.ReflectValue)
+		switch value.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < value.Len(); i++ {
+				s.publishRecord(tx, action, reflect.Indirect(value.Index(i)))
+			}
+		case reflect.Struct:
+			s.publishRecord(tx, action, value)
+		}
+	}
+}
+
+// publishConflict 古いバージョンに対する更新を拒否したことを、チーム（イベントは作成者と参加者）に知らせる
+//
+// 同じタスク・イベントを編集中の他のユーザーが、自分の変更が競合しうることに気づけるようにする。
+func (s *RealtimeService) publishConflict(tx *gorm.DB) {
+	var conflictErr *models.VersionConflictError
+	if !errors.As(tx.Error, &conflictErr) {
+		return
+	}
+	actorID := models.ActorID(tx)
+	switch current := conflictErr.Current.(type) {
+	case *models.Task:
+		s.Publish(RealtimeEvent{
+			Type:       "task.conflict",
+			EntityType: "task",
+			EntityID:   current.ID,
+			TeamID:     current.TeamID,
+			UserID:     actorID,
+		})
+	case *models.Event:
+		event := RealtimeEvent{Type: "event.conflict", EntityType: "event", EntityID: current.ID, UserID: actorID}
+		if current.TeamID != nil && current.Visibility != models.EventVisibilityPrivate {
+			event.TeamID = *current.TeamID
+		}
+		var attendeeIDs []string
+		tx.Session(&gorm.Session{NewDB: true}).Model(&models.EventAttendee{}).Where("event_id = ?", current.ID).
+			Pluck("user_id", &attendeeIDs)
+		s.Publish(event, append(attendeeIDs, current.CreatorID)...)
+	}
+}
+
+func (s *RealtimeService) publishRecord(tx *gorm.DB, action string, value reflect.Value) {
+	if value.Kind() != reflect.Struct || !value.CanInterface() {
+		return
+	}
+	switch record := value.Interface().(type) {
+	case models.Task:
+		if record.ID == "" {
+			return
+		}
+		s.Publish(RealtimeEvent{Type: "task." + action, EntityType: "task", EntityID: record.ID, TeamID: record.TeamID})
+	case models.Event:
+		if record.ID == "" {
+			return
+		}
+		event := RealtimeEvent{Type: "event." + action, EntityType: "event", EntityID: record.ID}
+		// 非公開のイベントはチームに知らせず、作成者と参加者にのみ知らせる
+		if record.TeamID != nil && record.Visibility != models.EventVisibilityPrivate {
+			event.TeamID = *record.TeamID
+		}
+		var attendeeIDs []string
+		tx.Session(&gorm.Session{NewDB: true}).Model(&models.EventAttendee{}).Where("event_id = ?", record.ID).
+			Pluck("user_id", &attendeeIDs)
+		s.Publish(event, append(attendeeIDs, record.CreatorID)...)
+	case models.Comment:
+		if record.ID == "" || record.TaskID == "" {
+			return
+		}
+		var teamIDs []string
+		if err := tx.Session(&gorm.Session{NewDB: true}).Model(&models.Task{}).Where("id = ?", record.TaskID).
+			Pluck("team_id", &teamIDs).Error; err != nil || len(teamIDs) == 0 {
+			return
+		}
+		s.Publish(RealtimeEvent{
+			Type:       "comment." + action,
+			EntityType: "comment",
+			EntityID:   record.ID,
+			TeamID:     teamIDs[0],
+			TaskID:     record.TaskID,
+		})
+	}
+}
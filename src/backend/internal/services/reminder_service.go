@@ -0,0 +1,201 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// 設定できるリマインダーの最大リードタイム（4週間）
+	maxReminderMinutes = 4 * 7 * 24 * 60
+	// サーバー停止中などで送れなかったリマインダーを後から送る猶予
+	reminderCatchUp = 6 * time.Hour
+	// 前回の実行から時間が空いた場合に遡って送る上限（reminderCatchUp を超える分は終わっていない回のみ送る）
+	reminderMaxCatchUp = 24 * time.Hour
+)
+
+// 通知種別
+const (
+	NotificationTypeEventReminder = "EVENT_REMINDER"
+)
+
+type ReminderService struct {
+	db           *gorm.DB
+	eventService *EventService
+	notifier     Notifier
+}
+
+func NewReminderService(db *gorm.DB, eventService *EventService, notifier Notifier) *ReminderService {
+	return &ReminderService{db: db, eventService: eventService, notifier: notifier}
+}
+
+// ListReminders イベントに設定した自分のリマインダー一覧
+func (s *ReminderService) ListReminders(eventID, userID string, sort SortOptions) ([]models.EventReminder, error) {
+	if _, err := findEventForUser(s.db, eventID, userID); err != nil {
+		return nil, err
+	}
+
+	var reminders []models.EventReminder
+	if err := orderBy(s.db.Where("event_id = ? AND user_id = ?", eventID, userID), sort, "minutes_before DESC").
+		Find(&reminders).Error; err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// AddReminder イベント開始のminutesBefore分前に通知するリマインダーを追加
+func (s *ReminderService) AddReminder(eventID, userID string, minutesBefore int) (*models.EventReminder, error) {
+	if _, err := findEventForUser(s.db, eventID, userID); err != nil {
+		return nil, err
+	}
+	if minutesBefore < 0 || minutesBefore > maxReminderMinutes {
+		return nil, fmt.Errorf("%w: minutesBeforeは0〜%dの範囲で指定してください", ErrInvalidInput, maxReminderMinutes)
+	}
+
+	var existing models.EventReminder
+	err := s.db.First(&existing, "event_id = ? AND user_id = ? AND minutes_before = ?", eventID, userID, minutesBefore).Error
+	if err == nil {
+		return &existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	reminder := models.EventReminder{EventID: eventID, UserID: userID, MinutesBefore: minutesBefore}
+	if err := s.db.Create(&reminder).Error; err != nil {
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+// DeleteReminder 自分のリマインダーを削除
+func (s *ReminderService) DeleteReminder(eventID, reminderID, userID string) error {
+	result := s.db.Where("id = ? AND event_id = ? AND user_id = ?", reminderID, eventID, userID).
+		Delete(&models.EventReminder{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeliverDueReminders 送信時刻を迎えたリマインダーを通知する（Cronジョブ用）
+//
+// 送信済みの回はReminderDeliveryに記録するため、再起動しても二重に送らず、
+// 停止中に迎えたリマインダーもreminderCatchUpの範囲内であれば起動後に送る。
+// since（前回成功した実行の日時）がそれより前の場合は reminderMaxCatchUp まで遡り、まだ終わっていない回のリマインダーを送る。
+func (s *ReminderService) DeliverDueReminders(since time.Time) error {
+	now := time.Now()
+	catchUpStart := now.Add(-reminderCatchUp)
+	windowStart := catchUpStart
+	if !since.IsZero() && since.Before(windowStart) {
+		windowStart = since
+		if limit := now.Add(-reminderMaxCatchUp); windowStart.Before(limit) {
+			windowStart = limit
+		}
+	}
+
+	var events []models.Event
+	if err := s.db.Where("id IN (?)", s.db.Model(&models.EventReminder{}).Select("event_id")).
+		Where("is_recurring = ? OR (start_date >= ? AND start_date <= ?)",
+			true, windowStart, now.Add(maxReminderMinutes*time.Minute)).
+		Where("status <> ?", models.EventStatusCancelled).
+		Preload("Reminders").Preload("Attendees").Find(&events).Error; err != nil {
+		return err
+	}
+	// 回の索引が使える繰り返しイベントは、最大のリードタイムまでの回を索引から取得する
+	indexed, rest, err := indexedOccurrences(s.db, events, windowStart, now.Add(maxReminderMinutes*time.Minute).Add(time.Second))
+	if err != nil {
+		return err
+	}
+	exceptions, err := s.eventService.loadExceptions(rest)
+	if err != nil {
+		return err
+	}
+
+	for i := range events {
+		event := &events[i]
+		declined := make(map[string]bool)
+		for _, a := range event.Attendees {
+			if a.Status == models.AttendeeStatusDeclined {
+				declined[a.UserID] = true
+			}
+		}
+
+		for j := range event.Reminders {
+			reminder := &event.Reminders[j]
+			if declined[reminder.UserID] {
+				continue
+			}
+			lead := time.Duration(reminder.MinutesBefore) * time.Minute
+			occurrences, ok := indexed[event.ID]
+			if !ok {
+				if occurrences, err = expandEvent(event, exceptions[event.ID], windowStart.Add(lead), now.Add(lead).Add(time.Second)); err != nil {
+					continue
+				}
+			}
+			for _, occ := range occurrences {
+				fireAt := occ.StartDate.Add(-lead)
+				// 作成前に送信時刻を過ぎていた回や、猶予を超えて遅れた回は送らない
+				if fireAt.After(now) || fireAt.Before(windowStart) || fireAt.Before(reminder.CreatedAt) {
+					continue
+				}
+				if fireAt.Before(catchUpStart) && !occ.EndDate.After(now) {
+					continue
+				}
+				if err := s.deliver(event, reminder, occ, fireAt); err != nil {
+					log.Printf("リマインダー %s の送信に失敗しました: %v", reminder.ID, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// deliver 送信記録を作成できた場合のみ通知する（記録済みなら送信済み）
+func (s *ReminderService) deliver(event *models.Event, reminder *models.EventReminder, occ EventOccurrence, fireAt time.Time) error {
+	delivery := models.ReminderDelivery{
+		ReminderID:   reminder.ID,
+		RecurrenceID: occ.RecurrenceID,
+		FireAt:       fireAt,
+		SentAt:       time.Now(),
+	}
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&delivery)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	return s.notifier.Notify(NotificationMessage{
+		UserID:     reminder.UserID,
+		Type:       NotificationTypeEventReminder,
+		Title:      "リマインダー: " + occ.Title,
+		Body:       fmt.Sprintf("%s に開始します（%s）", occ.StartDate.Format("2006-01-02 15:04"), formatReminderLead(reminder.MinutesBefore)),
+		EntityType: "event",
+		EntityID:   event.ID,
+	})
+}
+
+// formatReminderLead リードタイムを「1日前」「10分前」のように表す
+func formatReminderLead(minutes int) string {
+	switch {
+	case minutes == 0:
+		return "開始時刻"
+	case minutes%(24*60) == 0:
+		return fmt.Sprintf("%d日前", minutes/(24*60))
+	case minutes%60 == 0:
+		return fmt.Sprintf("%d時間前", minutes/60)
+	}
+	return fmt.Sprintf("%d分前", minutes)
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"time"
+
+	"task-calendar-backend/internal/database"
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// TeamSummary 期間内のチームのタスクの状況（週次ダイジェストなどの集計に使う）
+type TeamSummary struct {
+	TeamID   string    `json:"teamId"`
+	TeamName string    `json:"teamName"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	// 期間内に作成されたタスクの数
+	CreatedCount int64 `json:"createdCount"`
+	// 期間内に完了したタスク（完了日時は記録していないため、期間内に更新された完了済みのタスク）
+	CompletedCount int64         `json:"completedCount"`
+	CompletedTasks []models.Task `json:"completedTasks"`
+	// 期間の終わりの時点で期限を過ぎている未完了のタスク（期限の古い順）
+	OverdueCount int64         `json:"overdueCount"`
+	OverdueTasks []models.Task `json:"overdueTasks"`
+}
+
+// ReportService チームのタスクの集計
+type ReportService struct {
+	db *gorm.DB
+}
+
+func NewReportService(db *gorm.DB) *ReportService {
+	return &ReportService{db: db}
+}
+
+// TeamSummary [from, to) のチームのタスクの状況を集計する（タスクの一覧は limit 件まで）
+//
+// 権限は確認しないため、呼び出し元でチームのメンバーであることを確認する。集計はレプリカがあればレプリカで行う。
+func (s *ReportService) TeamSummary(teamID string, from, to time.Time, limit int) (*TeamSummary, error) {
+	db := database.Read(s.db)
+	var team models.Team
+	if err := db.Select("id", "name").First(&team, "id = ?", teamID).Error; err != nil {
+		return nil, err
+	}
+	summary := &TeamSummary{TeamID: team.ID, TeamName: team.Name, From: from, To: to}
+
+	if err := db.Model(&models.Task{}).
+		Where("team_id = ? AND created_at >= ? AND created_at < ?", teamID, from, to).
+		Count(&summary.CreatedCount).Error; err != nil {
+		return nil, err
+	}
+
+	const completedCond = "team_id = ? AND status = ? AND updated_at >= ? AND updated_at < ?"
+	if err := db.Model(&models.Task{}).Where(completedCond, teamID, models.TaskStatusDone, from, to).
+		Count(&summary.CompletedCount).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Preload("Assignee").Where(completedCond, teamID, models.TaskStatusDone, from, to).
+		Order("updated_at DESC").Limit(limit).Find(&summary.CompletedTasks).Error; err != nil {
+		return nil, err
+	}
+
+	const overdueCond = "team_id = ? AND due_date < ? AND status NOT IN ?"
+	closedStatuses := []models.TaskStatus{models.TaskStatusDone, models.TaskStatusCancelled}
+	if err := db.Model(&models.Task{}).Where(overdueCond, teamID, to, closedStatuses).
+		Count(&summary.OverdueCount).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Preload("Assignee").Where(overdueCond, teamID, to, closedStatuses).
+		Order("due_date ASC").Limit(limit).Find(&summary.OverdueTasks).Error; err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// RetentionPolicy 保存期間のルールの設定（日数。0のルールは実行しない）
+type RetentionPolicy struct {
+	NotificationDays   int // 作成から日数が過ぎた通知を削除する
+	DoneTaskDays       int // 完了してから（最後の更新から）日数が過ぎたタスクをアーカイブする
+	TeamInvitationDays int // 日数が過ぎても承諾されていないチームへの招待を取り消す
+	ExpiredTokenDays   int // 期限から日数が過ぎたメールの返信・出欠回答・パスワード再設定のトークンを削除する
+	DryRun             bool
+}
+
+// RetentionAction 保存期間のルールで行う操作
+type RetentionAction string
+
+const (
+	RetentionActionDelete  RetentionAction = "delete"
+	RetentionActionArchive RetentionAction = "archive"
+)
+
+// RetentionRuleResult 保存期間のルール1つの結果
+type RetentionRuleResult struct {
+	Rule        string          `json:"rule"`
+	Description string          `json:"description"`
+	Action      RetentionAction `json:"action"`
+	Days        int             `json:"days"`
+	Enabled     bool            `json:"enabled"`
+	Before      *time.Time      `json:"before,omitempty"` // この日時より前のものが対象
+	Affected    int64           `json:"affected"`         // 削除・アーカイブした件数（dryRun の場合は対象の件数）
+}
+
+// RetentionReport 保存期間のルールの実行結果
+type RetentionReport struct {
+	DryRun bool                  `json:"dryRun"`
+	RanAt  time.Time             `json:"ranAt"`
+	Rules  []RetentionRuleResult `json:"rules"`
+}
+
+// retentionRule 保存期間のルール
+type retentionRule struct {
+	name        string
+	description string
+	action      RetentionAction
+	days        int
+	targets     []retentionTarget
+}
+
+// retentionTarget ルールの対象のテーブルと、before より前のものに絞り込む条件
+type retentionTarget struct {
+	model interface{}
+	scope func(before time.Time) func(*gorm.DB) *gorm.DB
+}
+
+// RetentionService 保存期間を過ぎたデータの削除・アーカイブ（通知・完了したタスク・チームへの招待・期限切れのトークン）
+//
+// 定期実行のほか、管理者は対象の件数の確認（dryRun）と手動での実行ができる。
+// 設定の DryRun が有効な間は、定期実行では対象の件数をログに出すだけにする（ルールを導入する前の確認に使う）。
+type RetentionService struct {
+	db     *gorm.DB
+	policy RetentionPolicy
+}
+
+func NewRetentionService(db *gorm.DB, policy RetentionPolicy) *RetentionService {
+	return &RetentionService{db: db, policy: policy}
+}
+
+func (s *RetentionService) rules() []retentionRule {
+	olderThan := func(column string) func(before time.Time) func(*gorm.DB) *gorm.DB {
+		return func(before time.Time) func(*gorm.DB) *gorm.DB {
+			return func(db *gorm.DB) *gorm.DB {
+				return db.Where(column+" < ?", before)
+			}
+		}
+	}
+	return []retentionRule{
+		{
+			name:        "notifications",
+			description: "作成から保存期間が過ぎた通知を削除する",
+			action:      RetentionActionDelete,
+			days:        s.policy.NotificationDays,
+			targets:     []retentionTarget{{model: &models.Notification{}, scope: olderThan("created_at")}},
+		},
+		{
+			name:        "done_tasks",
+			description: "完了してから保存期間が過ぎたタスクをアーカイブする",
+			action:      RetentionActionArchive,
+			days:        s.policy.DoneTaskDays,
+			targets: []retentionTarget{{model: &models.Task{}, scope: func(before time.Time) func(*gorm.DB) *gorm.DB {
+				return func(db *gorm.DB) *gorm.DB {
+					return db.Where("status = ? AND archived_at IS NULL AND updated_at < ?", models.TaskStatusDone, before)
+				}
+			}}},
+		},
+		{
+			name:        "team_invitations",
+			description: "承諾されないまま期限が過ぎたチームへの招待を取り消す",
+			action:      RetentionActionDelete,
+			days:        s.policy.TeamInvitationDays,
+			targets: []retentionTarget{{model: &models.TeamMember{}, scope: func(before time.Time) func(*gorm.DB) *gorm.DB {
+				return func(db *gorm.DB) *gorm.DB {
+					return db.Where("status = ? AND joined_at < ?", models.TeamMemberStatusPending, before)
+				}
+			}}},
+		},
+		{
+			name:        "expired_tokens",
+			description: "期限が過ぎたメールの返信・出欠回答・パスワード再設定のトークンを削除する",
+			action:      RetentionActionDelete,
+			days:        s.policy.ExpiredTokenDays,
+			targets: []retentionTarget{
+				{model: &models.EmailReplyToken{}, scope: olderThan("expires_at")},
+				{model: &models.EventReplyToken{}, scope: olderThan("expires_at")},
+				{model: &models.PasswordResetToken{}, scope: olderThan("expires_at")},
+			},
+		},
+	}
+}
+
+// Run 保存期間のルールを実行する（dryRun の場合は対象の件数を数えるだけにする）
+func (s *RetentionService) Run(dryRun bool) (*RetentionReport, error) {
+	now := time.Now()
+	report := &RetentionReport{DryRun: dryRun, RanAt: now, Rules: []RetentionRuleResult{}}
+	for _, rule := range s.rules() {
+		result := RetentionRuleResult{
+			Rule:        rule.name,
+			Description: rule.description,
+			Action:      rule.action,
+			Days:        rule.days,
+			Enabled:     rule.days > 0,
+		}
+		if result.Enabled {
+			before := now.AddDate(0, 0, -rule.days)
+			result.Before = &before
+			for _, target := range rule.targets {
+				affected, err := s.apply(rule.action, target, before, now, dryRun)
+				if err != nil {
+					return nil, err
+				}
+				result.Affected += affected
+			}
+		}
+		report.Rules = append(report.Rules, result)
+	}
+	return report, nil
+}
+
+func (s *RetentionService) apply(action RetentionAction, target retentionTarget, before, now time.Time, dryRun bool) (int64, error) {
+	query := s.db.Model(target.model).Scopes(target.scope(before))
+	if dryRun {
+		var count int64
+		err := query.Count(&count).Error
+		return count, err
+	}
+	var result *gorm.DB
+	switch action {
+	case RetentionActionArchive:
+		result = query.Updates(map[string]interface{}{"archived_at": now})
+	default:
+		result = s.db.Scopes(target.scope(before)).Delete(target.model)
+	}
+	return result.RowsAffected, result.Error
+}
+
+// RunScheduled 保存期間のルールを実行し、結果をログに出す（定期実行。設定の DryRun が有効な間は対象の件数を数えるだけにする）
+func (s *RetentionService) RunScheduled() error {
+	report, err := s.Run(s.policy.DryRun)
+	if err != nil {
+		return err
+	}
+	for _, rule := range report.Rules {
+		if !rule.Enabled {
+			continue
+		}
+		if report.DryRun {
+			log.Printf("保存期間のルール %s（dryRun）: 対象 %d 件", rule.Rule, rule.Affected)
+		} else if rule.Affected > 0 {
+			log.Printf("保存期間のルール %s: %d 件を処理しました", rule.Rule, rule.Affected)
+		}
+	}
+	return nil
+}
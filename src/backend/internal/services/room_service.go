@@ -0,0 +1,305 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 繰り返しイベントで会議室の重複予約を確認する期間（開始日時から）
+const roomBookingHorizon = 366 * 24 * time.Hour
+
+// RoomInput 会議室の作成・更新
+type RoomInput struct {
+	Name        string `json:"name" binding:"required,notblank,max=100"`
+	Description string `json:"description"`
+	Location    string `json:"location"`
+	Capacity    int    `json:"capacity" binding:"min=0"`
+	TimeZone    string `json:"timeZone" binding:"omitempty,timezone"`
+}
+
+// EventLocationInput イベントの場所の設定（roomIdを空にすると会議室の予約を解除する）
+type EventLocationInput struct {
+	Location string  `json:"location"`
+	RoomID   *string `json:"roomId"`
+}
+
+type RoomService struct {
+	db *gorm.DB
+}
+
+func NewRoomService(db *gorm.DB) *RoomService {
+	return &RoomService{db: db}
+}
+
+// ListRooms チームの会議室一覧（minCapacity以上の定員のもの）
+func (s *RoomService) ListRooms(teamID, userID string, minCapacity int, sort SortOptions) ([]models.Room, error) {
+	if err := ensureTeamMember(s.db, teamID, userID); err != nil {
+		return nil, err
+	}
+
+	query := s.db.Where("team_id = ?", teamID)
+	if minCapacity > 0 {
+		query = query.Where("capacity = 0 OR capacity >= ?", minCapacity)
+	}
+	var rooms []models.Room
+	if err := orderBy(query, sort, "name ASC").Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+// CreateRoom 会議室を登録する（チーム管理者のみ）
+func (s *RoomService) CreateRoom(teamID, userID string, input RoomInput) (*models.Room, error) {
+	if err := s.ensureRoomAdmin(teamID, userID); err != nil {
+		return nil, err
+	}
+	room := models.Room{TeamID: teamID}
+	if err := applyRoomInput(&room, input); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&room).Error; err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+// UpdateRoom 会議室の情報を更新する（チーム管理者のみ）
+func (s *RoomService) UpdateRoom(roomID, userID string, input RoomInput) (*models.Room, error) {
+	room, err := s.findRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureRoomAdmin(room.TeamID, userID); err != nil {
+		return nil, err
+	}
+	if err := applyRoomInput(room, input); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(room).Error; err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// DeleteRoom 会議室を削除する（予約していたイベントの会議室は解除される）
+func (s *RoomService) DeleteRoom(roomID, userID string) error {
+	room, err := s.findRoom(roomID)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureRoomAdmin(room.TeamID, userID); err != nil {
+		return err
+	}
+	return s.db.Delete(room).Error
+}
+
+// GetRoomBookings 会議室の [from, to) の予約（イベントの発生）一覧（閲覧できない非公開の予定は内容を伏せる）
+func (s *RoomService) GetRoomBookings(roomID, userID string, from, to time.Time) ([]EventOccurrence, error) {
+	if err := validateExpansionRange(from, to); err != nil {
+		return nil, err
+	}
+	room, err := s.findRoom(roomID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureTeamMember(s.db, room.TeamID, userID); err != nil {
+		return nil, err
+	}
+	bookings, err := roomBookings(s.db, room, "", from, to)
+	if err != nil {
+		return nil, err
+	}
+	return maskPrivateOccurrences(s.db, bookings, userID)
+}
+
+// SetEventLocation イベントの場所と会議室を設定する
+//
+// 会議室は同じチームのメンバーのみ予約でき、他のイベントと時間が重なる場合は ErrConflict を返す。
+func (s *RoomService) SetEventLocation(eventID, userID string, input EventLocationInput) (*models.Event, error) {
+	event, err := findEditableEvent(s.db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roomID := input.RoomID
+	if roomID != nil && *roomID == "" {
+		roomID = nil
+	}
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if roomID != nil {
+			// 同じ会議室への同時の予約を直列化する
+			var room models.Room
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&room, "id = ?", *roomID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("%w: 会議室が見つかりません", ErrInvalidInput)
+				}
+				return err
+			}
+			if err := ensureTeamMember(tx, room.TeamID, userID); err != nil {
+				return err
+			}
+			if room.Capacity > 0 {
+				var attendees int64
+				if err := tx.Model(&models.EventAttendee{}).
+					Where("event_id = ? AND user_id <> ? AND status NOT IN ?", event.ID, event.CreatorID, models.InactiveAttendeeStatuses).
+					Count(&attendees).Error; err != nil {
+					return err
+				}
+				if int(attendees)+1 > room.Capacity {
+					return fmt.Errorf("%w: 参加者数が会議室の定員（%d人）を超えています", ErrInvalidInput, room.Capacity)
+				}
+			}
+
+			var exceptions []models.EventException
+			if event.IsRecurring {
+				if err := tx.Where("event_id = ?", event.ID).Find(&exceptions).Error; err != nil {
+					return err
+				}
+			}
+			from := event.StartDate
+			occurrences, err := expandEvent(event, exceptions, from, from.Add(roomBookingHorizon))
+			if err != nil {
+				return err
+			}
+			if err := ensureRoomAvailable(tx, &room, event.ID, occurrences); err != nil {
+				return err
+			}
+		}
+		return tx.Model(event).Updates(map[string]interface{}{
+			"location": strings.TrimSpace(input.Location),
+			"room_id":  roomID,
+		}).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Preload("Room").First(event, "id = ?", event.ID).Error; err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (s *RoomService) findRoom(roomID string) (*models.Room, error) {
+	var room models.Room
+	if err := s.db.First(&room, "id = ?", roomID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &room, nil
+}
+
+func (s *RoomService) ensureRoomAdmin(teamID, userID string) error {
+	admin, err := isTeamAdmin(s.db, teamID, userID)
+	if err != nil {
+		return err
+	}
+	if !admin {
+		return ErrForbidden
+	}
+	return nil
+}
+
+func applyRoomInput(room *models.Room, input RoomInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return fmt.Errorf("%w: nameは必須です", ErrInvalidInput)
+	}
+	if input.Capacity < 0 {
+		return fmt.Errorf("%w: capacityは0以上で指定してください", ErrInvalidInput)
+	}
+	if _, err := models.LoadLocation(input.TimeZone); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	room.Name = name
+	room.Description = input.Description
+	room.Location = input.Location
+	room.Capacity = input.Capacity
+	room.TimeZone = input.TimeZone
+	return nil
+}
+
+// roomBookings 会議室を予約したイベントを [from, to) の発生に展開する（終日の予約は会議室のタイムゾーンの1日として扱う）
+func roomBookings(db *gorm.DB, room *models.Room, excludeEventID string, from, to time.Time) ([]EventOccurrence, error) {
+	query := db.Where("room_id = ? AND start_date < ? AND (is_recurring = ? OR end_date > ?)",
+		room.ID, to.Add(allDaySlack), true, from.Add(-allDaySlack)).
+		Where("status <> ?", models.EventStatusCancelled)
+	if excludeEventID != "" {
+		query = query.Where("id <> ?", excludeEventID)
+	}
+	var events []models.Event
+	if err := query.Find(&events).Error; err != nil {
+		return nil, err
+	}
+	exceptions, err := loadEventExceptions(db, events)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, _ := models.LoadLocation(room.TimeZone)
+	bookings := []EventOccurrence{}
+	for i := range events {
+		expanded, err := expandEvent(&events[i], exceptions[events[i].ID], from.In(loc), to.In(loc))
+		if err != nil {
+			continue
+		}
+		for _, occ := range expanded {
+			occ.StartDate = models.LocalTime(occ.StartDate, occ.AllDay, loc)
+			occ.EndDate = models.LocalTime(occ.EndDate, occ.AllDay, loc)
+			if occ.StartDate.Before(to) && occ.EndDate.After(from) {
+				bookings = append(bookings, occ)
+			}
+		}
+	}
+	sort.SliceStable(bookings, func(i, j int) bool {
+		return bookings[i].StartDate.Before(bookings[j].StartDate)
+	})
+	return bookings, nil
+}
+
+// ensureRoomAvailable イベントの発生が会議室の他の予約と重ならないことを確認する
+func ensureRoomAvailable(db *gorm.DB, room *models.Room, eventID string, occurrences []EventOccurrence) error {
+	if len(occurrences) == 0 {
+		return nil
+	}
+	loc, _ := models.LoadLocation(room.TimeZone)
+	intervals := make([]timeInterval, len(occurrences))
+	for i, occ := range occurrences {
+		intervals[i] = timeInterval{
+			start: models.LocalTime(occ.StartDate, occ.AllDay, loc),
+			end:   models.LocalTime(occ.EndDate, occ.AllDay, loc),
+		}
+	}
+	from, to := intervals[0].start, intervals[0].end
+	for _, interval := range intervals {
+		if interval.start.Before(from) {
+			from = interval.start
+		}
+		if interval.end.After(to) {
+			to = interval.end
+		}
+	}
+
+	bookings, err := roomBookings(db, room, eventID, from, to)
+	if err != nil {
+		return err
+	}
+	for _, booking := range bookings {
+		for _, interval := range intervals {
+			if booking.StartDate.Before(interval.end) && booking.EndDate.After(interval.start) {
+				return fmt.Errorf("%w: 会議室「%s」は%sに予約されています", ErrConflict, room.Name,
+					booking.StartDate.In(loc).Format("2006-01-02 15:04"))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// デモデータのユーザーに共通のパスワード（フロントエンドの開発・e2eテストでのログイン用）
+const SeedPassword = "password123"
+
+// デモデータのタイムゾーン（繰り返しイベントの時刻の基準）
+const seedTimeZone = "Asia/Tokyo"
+
+// ErrAlreadySeeded デモデータを投入済み
+var ErrAlreadySeeded = errors.New("デモデータは投入済みです")
+
+// SeedUser 投入したデモユーザー（ログインに使う）
+type SeedUser struct {
+	Email    string          `json:"email"`
+	Password string          `json:"password"`
+	Name     string          `json:"name"`
+	Role     models.UserRole `json:"role"`
+}
+
+// SeedResult 投入したデモデータの件数
+type SeedResult struct {
+	Users     []SeedUser `json:"users"`
+	Teams     int        `json:"teams"`
+	Tasks     int        `json:"tasks"`
+	Events    int        `json:"events"`
+	Attendees int        `json:"attendees"`
+	Comments  int        `json:"comments"`
+}
+
+// seedUserSpec デモユーザーの定義
+type seedUserSpec struct {
+	key       string
+	email     string
+	username  string
+	firstName string
+	lastName  string
+	role      models.UserRole
+}
+
+var seedUsers = []seedUserSpec{
+	{key: "admin", email: "admin@example.com", username: "admin", firstName: "太郎", lastName: "管理", role: models.UserRoleAdmin},
+	{key: "sato", email: "sato@example.com", username: "sato", firstName: "花子", lastName: "佐藤", role: models.UserRoleManager},
+	{key: "suzuki", email: "suzuki@example.com", username: "suzuki", firstName: "一郎", lastName: "鈴木", role: models.UserRoleMember},
+	{key: "takahashi", email: "takahashi@example.com", username: "takahashi", firstName: "美咲", lastName: "高橋", role: models.UserRoleMember},
+	{key: "tanaka", email: "tanaka@example.com", username: "tanaka", firstName: "健太", lastName: "田中", role: models.UserRoleMember},
+	{key: "ito", email: "ito@example.com", username: "ito", firstName: "さくら", lastName: "伊藤", role: models.UserRoleMember},
+}
+
+// SeedService 開発・e2eテスト用のデモデータ（ユーザー・チーム・タスク・繰り返しイベント）の投入
+//
+// 本番環境では使わない（呼び出す側で ENVIRONMENT を確認する）。日付は投入した日を基準にするため、いつ投入しても直近の予定が並ぶ。
+type SeedService struct {
+	db *gorm.DB
+}
+
+func NewSeedService(db *gorm.DB) *SeedService {
+	return &SeedService{db: db}
+}
+
+// Seed デモデータを投入する
+//
+// デモユーザーのメールアドレスがすでに登録されている場合は何もせず ErrAlreadySeeded を返す
+// （やり直す場合は migrate down で空にしてから migrate up・seed を実行する）。すべて1つのトランザクションで投入する。
+func (s *SeedService) Seed() (*SeedResult, error) {
+	emails := make([]string, len(seedUsers))
+	for i, spec := range seedUsers {
+		emails[i] = spec.email
+	}
+	var existing int64
+	if err := s.db.Model(&models.User{}).Where("email IN ?", emails).Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, ErrAlreadySeeded
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(SeedPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := models.LoadLocation(seedTimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SeedResult{}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().In(loc)
+		seeder := &seeder{tx: tx, result: result, users: map[string]*models.User{}, today: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)}
+		for _, spec := range seedUsers {
+			if err := seeder.createUser(spec, string(hashed)); err != nil {
+				return err
+			}
+		}
+		return seeder.createTeams()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// seeder デモデータの投入中の状態
+type seeder struct {
+	tx     *gorm.DB
+	result *SeedResult
+	users  map[string]*models.User
+	today  time.Time // 投入した日の0時（seedTimeZone）
+}
+
+func (s *seeder) createUser(spec seedUserSpec, hashedPassword string) error {
+	user := &models.User{
+		Email:          spec.email,
+		Username:       spec.username,
+		Password:       hashedPassword,
+		FirstName:      spec.firstName,
+		LastName:       spec.lastName,
+		Role:           spec.role,
+		TimeZone:       seedTimeZone,
+		HolidayCountry: "JP",
+	}
+	if err := s.tx.Create(user).Error; err != nil {
+		return err
+	}
+	s.users[spec.key] = user
+	s.result.Users = append(s.result.Users, SeedUser{
+		Email:    spec.email,
+		Password: SeedPassword,
+		Name:     spec.lastName + " " + spec.firstName,
+		Role:     spec.role,
+	})
+	return nil
+}
+
+func (s *seeder) createTeams() error {
+	product, err := s.createTeam("プロダクト開発", "Webアプリとモバイルアプリの開発チーム", "sato",
+		map[string]models.TeamMemberRole{"admin": models.TeamMemberRoleAdmin, "suzuki": models.TeamMemberRoleMember, "takahashi": models.TeamMemberRoleMember, "tanaka": models.TeamMemberRoleMember})
+	if err != nil {
+		return err
+	}
+	marketing, err := s.createTeam("マーケティング", "キャンペーンとイベントの企画・運営", "takahashi",
+		map[string]models.TeamMemberRole{"ito": models.TeamMemberRoleAdmin, "sato": models.TeamMemberRoleMember})
+	if err != nil {
+		return err
+	}
+
+	// タスク（期限は今日の前後に散らし、期限切れ・今日・今週・来月が混ざるようにする）
+	tasks := []struct {
+		team        *models.Team
+		title       string
+		description string
+		status      models.TaskStatus
+		priority    models.Priority
+		dueInDays   *int
+		creator     string
+		assignee    string
+		recurrence  models.TaskRecurrence
+		comments    []seedComment
+	}{
+		{team: product, title: "ログイン画面のデザインを確定する", description: "Figmaの案Bをもとに、エラー表示のパターンを追加する。", status: models.TaskStatusInReview, priority: models.PriorityHigh, dueInDays: intPtr(-2), creator: "sato", assignee: "takahashi",
+			comments: []seedComment{{author: "takahashi", content: "案Bにエラー表示を追加しました。確認をお願いします。"}, {author: "sato", content: "ありがとうございます。パスワードの表示切り替えも入れてください。"}}},
+		{team: product, title: "カレンダーの週表示を実装する", description: "ドラッグで予定の時間を変更できるようにする。", status: models.TaskStatusInProgress, priority: models.PriorityHigh, dueInDays: intPtr(3), creator: "sato", assignee: "suzuki",
+			comments: []seedComment{{author: "suzuki", content: "ドラッグ中のスクロールの挙動を調整中です。"}}},
+		{team: product, title: "通知設定のAPIを追加する", status: models.TaskStatusTodo, priority: models.PriorityMedium, dueInDays: intPtr(7), creator: "suzuki", assignee: "tanaka"},
+		{team: product, title: "本番環境の監視ダッシュボードを作る", description: "応答時間とエラー率、ジョブの滞留を表示する。", status: models.TaskStatusTodo, priority: models.PriorityUrgent, dueInDays: intPtr(0), creator: "admin", assignee: "admin"},
+		{team: product, title: "依存ライブラリを更新する", status: models.TaskStatusTodo, priority: models.PriorityLow, dueInDays: intPtr(14), creator: "admin", recurrence: models.TaskRecurrenceMonthly},
+		{team: product, title: "スプリントの振り返りをまとめる", status: models.TaskStatusTodo, priority: models.PriorityMedium, dueInDays: intPtr(4), creator: "sato", assignee: "sato", recurrence: models.TaskRecurrenceWeekly},
+		{team: product, title: "リリースノートを書く", status: models.TaskStatusDone, priority: models.PriorityMedium, dueInDays: intPtr(-7), creator: "sato", assignee: "tanaka"},
+		{team: product, title: "古いエクスポート機能を削除する", status: models.TaskStatusCancelled, priority: models.PriorityLow, creator: "suzuki"},
+		{team: marketing, title: "新機能の紹介記事を公開する", description: "週表示とドラッグ操作の紹介。スクリーンショットは開発チームから受け取る。", status: models.TaskStatusInProgress, priority: models.PriorityHigh, dueInDays: intPtr(5), creator: "takahashi", assignee: "ito",
+			comments: []seedComment{{author: "ito", content: "下書きができました。スクリーンショットの差し替え待ちです。"}}},
+		{team: marketing, title: "展示会のブースを申し込む", status: models.TaskStatusTodo, priority: models.PriorityUrgent, dueInDays: intPtr(1), creator: "takahashi", assignee: "takahashi"},
+		{team: marketing, title: "SNSの投稿を予約する", status: models.TaskStatusTodo, priority: models.PriorityMedium, dueInDays: intPtr(2), creator: "ito", assignee: "ito", recurrence: models.TaskRecurrenceWeekly},
+		{team: marketing, title: "四半期のアクセス解析レポート", status: models.TaskStatusTodo, priority: models.PriorityMedium, dueInDays: intPtr(30), creator: "takahashi", assignee: "sato"},
+	}
+	for _, spec := range tasks {
+		task := &models.Task{
+			Title:       spec.title,
+			Description: spec.description,
+			Status:      spec.status,
+			Priority:    spec.priority,
+			IsRecurring: spec.recurrence != "",
+			Recurrence:  spec.recurrence,
+			TeamID:      spec.team.ID,
+			CreatorID:   s.users[spec.creator].ID,
+		}
+		if spec.dueInDays != nil {
+			due := s.at(*spec.dueInDays, 18, 0)
+			task.DueDate = &due
+		}
+		if spec.assignee != "" {
+			task.AssigneeID = &s.users[spec.assignee].ID
+		}
+		if err := s.tx.Create(task).Error; err != nil {
+			return err
+		}
+		s.result.Tasks++
+		if err := s.createComments(task, spec.comments); err != nil {
+			return err
+		}
+	}
+
+	// イベント（繰り返しの定例・単発の会議・終日の予定・個人の予定）
+	weekStart := s.today.AddDate(0, 0, -((int(s.today.Weekday()) + 6) % 7)) // 今週の月曜日
+	monthStart := s.today.AddDate(0, 0, 1-s.today.Day())                    // 今月の1日
+	events := []struct {
+		team        *models.Team
+		title       string
+		description string
+		eventType   models.EventType
+		start       time.Time
+		duration    time.Duration
+		allDay      bool
+		recurrence  string
+		location    string
+		creator     string
+		attendees   map[string]models.AttendeeStatus
+	}{
+		{team: product, title: "朝会", description: "昨日やったこと・今日やること・困っていること", eventType: models.EventTypeMeeting, start: weekStart.Add(9*time.Hour + 30*time.Minute), duration: 15 * time.Minute,
+			recurrence: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR", location: "オンライン", creator: "sato",
+			attendees: map[string]models.AttendeeStatus{"suzuki": models.AttendeeStatusAccepted, "takahashi": models.AttendeeStatusAccepted, "tanaka": models.AttendeeStatusAccepted, "admin": models.AttendeeStatusTentative}},
+		{team: product, title: "スプリント計画", eventType: models.EventTypeMeeting, start: weekStart.Add(14 * time.Hour), duration: time.Hour,
+			recurrence: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", location: "会議室A", creator: "sato",
+			attendees: map[string]models.AttendeeStatus{"suzuki": models.AttendeeStatusAccepted, "takahashi": models.AttendeeStatusPending, "tanaka": models.AttendeeStatusAccepted}},
+		{team: product, title: "月次の全体報告", eventType: models.EventTypeMeeting, start: monthStart.Add(16 * time.Hour), duration: time.Hour,
+			recurrence: "FREQ=MONTHLY;BYMONTHDAY=1", location: "大会議室", creator: "admin",
+			attendees: map[string]models.AttendeeStatus{"sato": models.AttendeeStatusAccepted, "suzuki": models.AttendeeStatusPending, "takahashi": models.AttendeeStatusPending, "tanaka": models.AttendeeStatusDeclined}},
+		{team: product, title: "デザインレビュー", description: "ログイン画面と週表示のデザインを確認する", eventType: models.EventTypeMeeting, start: s.at(1, 13, 0), duration: 45 * time.Minute,
+			location: "会議室B", creator: "takahashi",
+			attendees: map[string]models.AttendeeStatus{"sato": models.AttendeeStatusAccepted, "suzuki": models.AttendeeStatusTentative}},
+		{team: product, title: "v2.0 リリース", eventType: models.EventTypeDeadline, start: s.at(10, 0, 0), duration: 24 * time.Hour, allDay: true, creator: "sato"},
+		{team: marketing, title: "キャンペーン定例", eventType: models.EventTypeMeeting, start: weekStart.AddDate(0, 0, 2).Add(11 * time.Hour), duration: 30 * time.Minute,
+			recurrence: "FREQ=WEEKLY;BYDAY=WE", location: "オンライン", creator: "takahashi",
+			attendees: map[string]models.AttendeeStatus{"ito": models.AttendeeStatusAccepted, "sato": models.AttendeeStatusTentative}},
+		{team: marketing, title: "展示会", eventType: models.EventTypeMeeting, start: s.at(21, 0, 0), duration: 48 * time.Hour, allDay: true, location: "東京ビッグサイト", creator: "takahashi",
+			attendees: map[string]models.AttendeeStatus{"ito": models.AttendeeStatusAccepted}},
+		{title: "歯医者", eventType: models.EventTypePersonal, start: s.at(2, 17, 30), duration: time.Hour, creator: "suzuki"},
+		{title: "夏季休暇", eventType: models.EventTypeOutOfOffice, start: s.at(14, 0, 0), duration: 72 * time.Hour, allDay: true, creator: "tanaka"},
+	}
+	for _, spec := range events {
+		event := &models.Event{
+			Title:       spec.title,
+			Description: spec.description,
+			StartDate:   spec.start,
+			EndDate:     spec.start.Add(spec.duration),
+			AllDay:      spec.allDay,
+			TimeZone:    seedTimeZone,
+			IsRecurring: spec.recurrence != "",
+			Recurrence:  spec.recurrence,
+			Type:        spec.eventType,
+			Location:    spec.location,
+			CreatorID:   s.users[spec.creator].ID,
+		}
+		if spec.team != nil {
+			event.TeamID = &spec.team.ID
+		}
+		if err := s.tx.Create(event).Error; err != nil {
+			return err
+		}
+		s.result.Events++
+		for key, status := range spec.attendees {
+			attendee := &models.EventAttendee{EventID: event.ID, UserID: s.users[key].ID, InvitedByID: event.CreatorID, Status: status}
+			if status != models.AttendeeStatusPending {
+				respondedAt := time.Now()
+				attendee.RespondedAt = &respondedAt
+			}
+			if err := s.tx.Create(attendee).Error; err != nil {
+				return err
+			}
+			s.result.Attendees++
+		}
+	}
+	return nil
+}
+
+func (s *seeder) createTeam(name, description, owner string, members map[string]models.TeamMemberRole) (*models.Team, error) {
+	team := &models.Team{Name: name, Description: description, CreatorID: s.users[owner].ID, HolidayCountry: "JP"}
+	if err := s.tx.Create(team).Error; err != nil {
+		return nil, err
+	}
+	s.result.Teams++
+	memberships := []models.TeamMember{{UserID: team.CreatorID, TeamID: team.ID, Role: models.TeamMemberRoleOwner, Status: models.TeamMemberStatusActive, JoinedAt: time.Now()}}
+	for key, role := range members {
+		memberships = append(memberships, models.TeamMember{UserID: s.users[key].ID, TeamID: team.ID, Role: role, Status: models.TeamMemberStatusActive, JoinedAt: time.Now()})
+	}
+	if err := s.tx.Create(&memberships).Error; err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// seedComment タスクのデモのコメント
+type seedComment struct {
+	author  string
+	content string
+}
+
+func (s *seeder) createComments(task *models.Task, comments []seedComment) error {
+	for _, spec := range comments {
+		html, err := renderMarkdown(spec.content)
+		if err != nil {
+			return err
+		}
+		comment := &models.Comment{Content: spec.content, ContentHTML: html, TaskID: task.ID, AuthorID: s.users[spec.author].ID}
+		if err := s.tx.Create(comment).Error; err != nil {
+			return err
+		}
+		s.result.Comments++
+	}
+	return nil
+}
+
+// at 投入した日から days 日後の hour 時 minute 分（seedTimeZone）
+func (s *seeder) at(days, hour, minute int) time.Time {
+	return s.today.AddDate(0, 0, days).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
+
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/ical"
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// CreateSharedLinkInput 共有リンクの作成（teamId を指定するとチームのカレンダーを公開する）
+type CreateSharedLinkInput struct {
+	Name      string     `json:"name" binding:"max=100"`
+	TeamID    *string    `json:"teamId"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// SharedOccurrence 共有リンクで公開するイベントの回（作成者・参加者などの情報は含めない）
+type SharedOccurrence struct {
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	Type        models.EventType   `json:"type"`
+	StartDate   time.Time          `json:"startDate"`
+	EndDate     time.Time          `json:"endDate"`
+	AllDay      bool               `json:"allDay"`
+	IsRecurring bool               `json:"isRecurring"`
+	Status      models.EventStatus `json:"status"`
+}
+
+// SharedCalendar 共有リンクで公開するカレンダー
+type SharedCalendar struct {
+	Name        string             `json:"name"`
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	Occurrences []SharedOccurrence `json:"occurrences"`
+}
+
+type SharedCalendarService struct {
+	db *gorm.DB
+}
+
+func NewSharedCalendarService(db *gorm.DB) *SharedCalendarService {
+	return &SharedCalendarService{db: db}
+}
+
+// ListLinks 自分が作成した共有リンクの一覧
+func (s *SharedCalendarService) ListLinks(userID string, sort SortOptions, include IncludeOptions) ([]models.SharedCalendarLink, error) {
+	var links []models.SharedCalendarLink
+	if err := orderBy(preload(s.db, include).Where("creator_id = ?", userID), sort, "created_at DESC").
+		Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+// CreateLink 共有リンクを作成する（チームのカレンダーはチームの管理者のみ）
+func (s *SharedCalendarService) CreateLink(userID string, input CreateSharedLinkInput) (*models.SharedCalendarLink, error) {
+	if input.TeamID != nil {
+		admin, err := isTeamAdmin(s.db, *input.TeamID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !admin {
+			return nil, fmt.Errorf("%w: チームのカレンダーの共有リンクはチームの管理者のみ作成できます", ErrForbidden)
+		}
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: expiresAtは現在より後の日時を指定してください", ErrInvalidInput)
+	}
+
+	token, err := newReplyToken()
+	if err != nil {
+		return nil, err
+	}
+	link := models.SharedCalendarLink{
+		Token:     token,
+		Name:      strings.TrimSpace(input.Name),
+		ExpiresAt: input.ExpiresAt,
+		CreatorID: userID,
+		TeamID:    input.TeamID,
+	}
+	if err := s.db.Create(&link).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Preload("Team").First(&link, "id = ?", link.ID).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// RevokeLink 共有リンクを削除してURLを無効にする（作成者、またはチームのカレンダーの場合はチームの管理者）
+func (s *SharedCalendarService) RevokeLink(linkID, userID string) error {
+	var link models.SharedCalendarLink
+	if err := s.db.First(&link, "id = ?", linkID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return err
+	}
+	if link.CreatorID != userID {
+		if link.TeamID == nil {
+			return ErrNotFound
+		}
+		admin, err := isTeamAdmin(s.db, *link.TeamID, userID)
+		if err != nil {
+			return err
+		}
+		if !admin {
+			return ErrForbidden
+		}
+	}
+	return s.db.Delete(&link).Error
+}
+
+// GetSharedCalendar 共有リンクで公開している [from, to) のイベントの回
+func (s *SharedCalendarService) GetSharedCalendar(token string, from, to time.Time) (*SharedCalendar, error) {
+	if err := validateExpansionRange(from, to); err != nil {
+		return nil, err
+	}
+	link, err := s.resolve(token)
+	if err != nil {
+		return nil, err
+	}
+	name, err := s.calendarName(link)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []models.Event
+	if err := s.sharedEvents(link).
+		Where("(all_day = ? AND start_date < ? AND (is_recurring = ? OR end_date > ?)) OR "+
+			"(all_day = ? AND start_date < ? AND (is_recurring = ? OR end_date > ?))",
+			false, to, true, from,
+			true, to.Add(allDaySlack), true, from.Add(-allDaySlack)).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	exceptions, err := loadEventExceptions(s.db, events)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &SharedCalendar{Name: name, From: from, To: to, Occurrences: []SharedOccurrence{}}
+	for i := range events {
+		occurrences, err := expandEvent(&events[i], exceptions[events[i].ID], from, to)
+		if err != nil {
+			continue
+		}
+		for _, occ := range occurrences {
+			calendar.Occurrences = append(calendar.Occurrences, SharedOccurrence{
+				Title:       occ.Title,
+				Description: occ.Description,
+				Type:        occ.Type,
+				StartDate:   occ.StartDate,
+				EndDate:     occ.EndDate,
+				AllDay:      occ.AllDay,
+				IsRecurring: occ.IsRecurring,
+				Status:      occ.Status,
+			})
+		}
+	}
+	sort.SliceStable(calendar.Occurrences, func(i, j int) bool {
+		return calendar.Occurrences[i].StartDate.Before(calendar.Occurrences[j].StartDate)
+	})
+	return calendar, nil
+}
+
+// LocalizeSharedCalendar 公開するイベントの回の日時を閲覧者のタイムゾーンで表す
+func LocalizeSharedCalendar(calendar *SharedCalendar, loc *time.Location) *SharedCalendar {
+	for i := range calendar.Occurrences {
+		occ := &calendar.Occurrences[i]
+		occ.StartDate = models.LocalTime(occ.StartDate, occ.AllDay, loc)
+		occ.EndDate = models.LocalTime(occ.EndDate, occ.AllDay, loc)
+	}
+	return calendar
+}
+
+// ExportSharedICS 共有リンクで公開しているイベントをiCalendar形式で出力する（カレンダーアプリからの購読用）
+func (s *SharedCalendarService) ExportSharedICS(token string, loc *time.Location) ([]byte, error) {
+	link, err := s.resolve(token)
+	if err != nil {
+		return nil, err
+	}
+	name, err := s.calendarName(link)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []models.Event
+	if err := s.sharedEvents(link).Preload("Room").Order("start_date ASC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	exceptions, err := loadEventExceptions(s.db, events)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, buildCalendar(name, events, exceptions, loc)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// resolve 有効な共有リンクを取得し、最終アクセス日時を記録する（無効・期限切れは ErrNotFound）
+func (s *SharedCalendarService) resolve(token string) (*models.SharedCalendarLink, error) {
+	var link models.SharedCalendarLink
+	if err := s.db.First(&link, "token = ?", token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if link.ExpiresAt != nil && !link.ExpiresAt.After(now) {
+		return nil, ErrNotFound
+	}
+	s.db.Model(&link).UpdateColumn("last_accessed_at", now)
+	return &link, nil
+}
+
+// sharedEvents 共有リンクで公開するイベント（非公開のイベントを除く）
+func (s *SharedCalendarService) sharedEvents(link *models.SharedCalendarLink) *gorm.DB {
+	query := s.db.Model(&models.Event{}).Where("events.visibility <> ?", models.EventVisibilityPrivate)
+	if link.TeamID != nil {
+		return query.Where("events.team_id = ?", *link.TeamID)
+	}
+	attending := s.db.Session(&gorm.Session{NewDB: true}).Model(&models.EventAttendee{}).
+		Select("event_id").Where("user_id = ? AND status NOT IN ?", link.CreatorID, models.InactiveAttendeeStatuses)
+	return query.Where("events.creator_id = ? OR events.id IN (?)", link.CreatorID, attending)
+}
+
+// calendarName 公開するカレンダーの名前（リンクの名前、未設定の場合はチーム名または作成者の名前）
+func (s *SharedCalendarService) calendarName(link *models.SharedCalendarLink) (string, error) {
+	if link.Name != "" {
+		return link.Name, nil
+	}
+	if link.TeamID != nil {
+		var team models.Team
+		if err := s.db.Select("id", "name").First(&team, "id = ?", *link.TeamID).Error; err != nil {
+			return "", err
+		}
+		return team.Name, nil
+	}
+	var user models.User
+	if err := s.db.Select("id", "first_name", "last_name", "username").First(&user, "id = ?", link.CreatorID).Error; err != nil {
+		return "", err
+	}
+	return userDisplayName(&user), nil
+}
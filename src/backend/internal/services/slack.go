@@ -0,0 +1,253 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Slackからのリクエストのタイムスタンプの許容範囲（リプレイ攻撃を防ぐ）
+const slackRequestTolerance = 5 * time.Minute
+
+// SlackClient Slack Web API（ボットトークン）のクライアント
+type SlackClient struct {
+	token   string
+	baseURL string
+	client  *http.Client
+}
+
+func NewSlackClient(token string) *SlackClient {
+	return &SlackClient{token: token, baseURL: "https://slack.com/api", client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+func (c *SlackClient) Provider() models.ChatProvider {
+	return models.ChatProviderSlack
+}
+
+func (c *SlackClient) Validate(channel *models.TeamChatChannel) error {
+	if channel.ChannelID == "" {
+		return fmt.Errorf("%w: channelIdは必須です", ErrInvalidInput)
+	}
+	return nil
+}
+
+// Post チャンネルにメッセージを投稿する（ボットをチャンネルに招待しておく必要がある）
+func (c *SlackClient) Post(ctx context.Context, channel *models.TeamChatChannel, msg ChatMessage) error {
+	text := slackEscape(msg.Text)
+	if msg.LinkURL != "" {
+		text += fmt.Sprintf("\n<%s|%s>", msg.LinkURL, slackEscape(msg.LinkTitle))
+	} else if msg.LinkTitle != "" {
+		text += "\n" + slackEscape(msg.LinkTitle)
+	}
+	for _, fact := range msg.Facts {
+		text += fmt.Sprintf("\n*%s*: %s", slackEscape(fact.Title), slackEscape(fact.Value))
+	}
+	return c.call(ctx, "chat.postMessage", map[string]interface{}{
+		"channel":      channel.ChannelID,
+		"text":         text,
+		"unfurl_links": false,
+	}, nil)
+}
+
+// UserEmail Slackのユーザーのメールアドレス（users:read.email スコープが必要）
+func (c *SlackClient) UserEmail(ctx context.Context, slackUserID string) (string, error) {
+	var body struct {
+		User struct {
+			Profile struct {
+				Email string `json:"email"`
+			} `json:"profile"`
+		} `json:"user"`
+	}
+	if err := c.call(ctx, "users.info?user="+url.QueryEscape(slackUserID), nil, &body); err != nil {
+		return "", err
+	}
+	return body.User.Profile.Email, nil
+}
+
+// call Web APIのメソッドを呼び出す（payloadがnilの場合はGET）
+func (c *SlackClient) call(ctx context.Context, method string, payload interface{}, out interface{}) error {
+	httpMethod := http.MethodGet
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		httpMethod = http.MethodPost
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, httpMethod, c.baseURL+"/"+method, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Slack APIがステータス %d を返しました", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	if !result.OK {
+		return fmt.Errorf("Slack APIの%sが失敗しました: %s", strings.SplitN(method, "?", 2)[0], result.Error)
+	}
+	if out != nil {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
+
+// slackEscape Slackのメッセージで特別な意味を持つ文字をエスケープする
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// SlackCommand Slackのスラッシュコマンドで送られてくる内容
+type SlackCommand struct {
+	Command   string
+	Text      string
+	UserID    string
+	ChannelID string
+}
+
+// SlackCommandResponse スラッシュコマンドへの応答（response_type が ephemeral の場合は実行したユーザーにのみ表示される）
+type SlackCommandResponse struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+// SlackCommandService Slackのスラッシュコマンド（/task タイトル）で、チャンネルに対応するチームにタスクを作成する
+//
+// Slackのユーザーはメールアドレスが一致するユーザーとして扱う。作成したタスクはチャット連携でチャンネルにも投稿される。
+type SlackCommandService struct {
+	db            *gorm.DB
+	client        *SlackClient
+	signingSecret []byte
+	appURL        string
+}
+
+func NewSlackCommandService(db *gorm.DB, client *SlackClient, signingSecret, appURL string) *SlackCommandService {
+	return &SlackCommandService{db: db, client: client, signingSecret: []byte(signingSecret), appURL: strings.TrimRight(appURL, "/")}
+}
+
+// VerifyRequest Slackの署名（X-Slack-Signature、X-Slack-Request-Timestamp）を検証する
+func (s *SlackCommandService) VerifyRequest(timestamp, signature string, body []byte) error {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: リクエストのタイムスタンプが不正です", ErrForbidden)
+	}
+	if d := time.Since(time.Unix(sec, 0)); d > slackRequestTolerance || d < -slackRequestTolerance {
+		return fmt.Errorf("%w: リクエストの有効期限が切れています", ErrForbidden)
+	}
+	mac := hmac.New(sha256.New, s.signingSecret)
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return fmt.Errorf("%w: 署名が一致しません", ErrForbidden)
+	}
+	return nil
+}
+
+// HandleCommand スラッシュコマンドを実行する（利用者に伝えるエラーは応答の本文で返す）
+func (s *SlackCommandService) HandleCommand(ctx context.Context, cmd SlackCommand) (*SlackCommandResponse, error) {
+	title := strings.TrimSpace(cmd.Text)
+	if title == "" || title == "help" {
+		return slackEphemeral(fmt.Sprintf("使い方: `%s タスクのタイトル` でこのチャンネルに連携したチームにタスクを作成します", cmd.Command)), nil
+	}
+
+	var channels []models.TeamChatChannel
+	if err := s.db.Where("provider = ? AND channel_id = ?", models.ChatProviderSlack, cmd.ChannelID).
+		Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	switch len(channels) {
+	case 0:
+		return slackEphemeral("このチャンネルはどのチームにも連携されていません"), nil
+	case 1:
+	default:
+		return slackEphemeral("このチャンネルは複数のチームに連携されているため、タスクを作成するチームを決められません"), nil
+	}
+	teamID := channels[0].TeamID
+
+	user, err := s.findUser(ctx, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return slackEphemeral("Slackのメールアドレスと一致するアカウントが見つかりません"), nil
+	}
+	if err := ensureTeamMember(s.db, teamID, user.ID); err != nil {
+		if errors.Is(err, ErrForbidden) {
+			return slackEphemeral("このチャンネルに連携したチームのメンバーではありません"), nil
+		}
+		return nil, err
+	}
+
+	task := models.Task{
+		Title:     title,
+		TeamID:    teamID,
+		CreatorID: user.ID,
+		Status:    models.TaskStatusTodo,
+		Priority:  models.PriorityMedium,
+	}
+	if err := s.db.Create(&task).Error; err != nil {
+		return nil, err
+	}
+	return slackEphemeral(fmt.Sprintf("タスクを作成しました: <%s/tasks/%s|%s>", s.appURL, task.ID, slackEscape(task.Title))), nil
+}
+
+// findUser Slackのユーザーとメールアドレスが一致するユーザー（見つからない場合はnil）
+func (s *SlackCommandService) findUser(ctx context.Context, slackUserID string) (*models.User, error) {
+	email, err := s.client.UserEmail(ctx, slackUserID)
+	if err != nil {
+		return nil, err
+	}
+	if email == "" {
+		return nil, nil
+	}
+	var user models.User
+	err = s.db.First(&user, "LOWER(email) = ?", strings.ToLower(email)).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func slackEphemeral(text string) *SlackCommandResponse {
+	return &SlackCommandResponse{ResponseType: "ephemeral", Text: text}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SortField 並び順の項目（Column は列名、Desc は降順）
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// SortOptions sort クエリで指定した並び順（先の項目を優先する）。空の場合は一覧ごとの既定の順
+type SortOptions []SortField
+
+// SortableFields 一覧で並べ替えに使える項目（sort で指定する名前 → 列名）
+type SortableFields map[string]string
+
+// 一覧ごとに sort で指定できる項目
+var (
+	// CursorSortFields カーソルページングの一覧（コメント・通知・Webhookの配信）。カーソルが作成日時によるため作成日時のみ
+	CursorSortFields         = SortableFields{"createdAt": "created_at"}
+	DeviceSortFields         = SortableFields{"createdAt": "created_at", "lastSeenAt": "last_seen_at", "platform": "platform", "deviceName": "device_name"}
+	SubscriptionSortFields   = SortableFields{"createdAt": "created_at", "name": "name", "lastFetchedAt": "last_fetched_at"}
+	WebhookSortFields        = SortableFields{"createdAt": "created_at", "url": "url"}
+	PollSortFields           = SortableFields{"createdAt": "created_at", "title": "title", "status": "status"}
+	AttendeeSortFields       = SortableFields{"createdAt": "created_at", "status": "status", "respondedAt": "responded_at"}
+	SharedLinkSortFields     = SortableFields{"createdAt": "created_at", "name": "name", "expiresAt": "expires_at"}
+	ReminderSortFields       = SortableFields{"createdAt": "created_at", "minutesBefore": "minutes_before"}
+	CategorySortFields       = SortableFields{"createdAt": "created_at", "name": "name"}
+	LabelSortFields          = SortableFields{"createdAt": "created_at", "name": "name"}
+	RoomSortFields           = SortableFields{"createdAt": "created_at", "name": "name", "capacity": "capacity"}
+	ReportSortFields         = SortableFields{"createdAt": "created_at", "resolvedAt": "resolved_at"}
+	ConnectionSortFields     = SortableFields{"createdAt": "created_at", "provider": "provider", "lastSyncedAt": "last_synced_at"}
+	WatcherSortFields        = SortableFields{"createdAt": "created_at"}
+	TaskActivitySortFields   = SortableFields{"createdAt": "created_at", "action": "action"}
+	ChecklistItemSortFields  = SortableFields{"position": "position", "createdAt": "created_at", "content": "content", "isCompleted": "is_completed"}
+	AutomationRuleSortFields = SortableFields{"createdAt": "created_at", "name": "name", "lastTriggeredAt": "last_triggered_at"}
+)
+
+// orderBy 並び順をクエリに適用する（指定がなければ defaultOrder）
+//
+// 同じ値の行の順が毎回変わらないよう、指定した項目の後にIDの順を加える。
+func orderBy(query *gorm.DB, sort SortOptions, defaultOrder string) *gorm.DB {
+	if len(sort) == 0 {
+		return query.Order(defaultOrder)
+	}
+	for _, field := range sort {
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: field.Column}, Desc: field.Desc})
+	}
+	return query.Order("id ASC")
+}
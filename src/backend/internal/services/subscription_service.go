@@ -0,0 +1,386 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"task-calendar-backend/internal/ical"
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// 取得するICSフィードの最大サイズ
+const maxFeedSize = 10 << 20
+
+var errPrivateAddress = errors.New("内部ネットワークのアドレスには接続できません")
+
+// SubscriptionInput 外部カレンダー購読の登録内容
+type SubscriptionInput struct {
+	Name   string  `json:"name" binding:"required,notblank,max=100"`
+	URL    string  `json:"url" binding:"required,url"`
+	TeamID *string `json:"teamId"`
+}
+
+type SubscriptionService struct {
+	db              *gorm.DB
+	client          *http.Client
+	refreshInterval time.Duration
+}
+
+func NewSubscriptionService(db *gorm.DB, refreshInterval time.Duration) *SubscriptionService {
+	return &SubscriptionService{db: db, client: newFeedClient(), refreshInterval: refreshInterval}
+}
+
+// newFeedClient 利用者が指定したURLを取得するため、内部ネットワークへの接続を禁止したHTTPクライアント
+func newFeedClient() *http.Client {
+	return newExternalClient(30 * time.Second)
+}
+
+// newExternalClient 内部ネットワークへの接続を禁止したHTTPクライアント（購読のフィードやWebhookなど、利用者が指定したURLに使う）
+//
+// 接続するたびに解決したアドレスを確かめるため、登録した後にDNSの向き先を内部のアドレスに変えられても接続しない。
+func newExternalClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || isInternalIP(ip) {
+				return errPrivateAddress
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: 10 * time.Second},
+	}
+}
+
+// isInternalIP ループバック・プライベート・リンクローカル（クラウドのメタデータを含む）・未指定・マルチキャストのアドレスか
+func isInternalIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast()
+}
+
+// checkExternalHost URLのホストが内部ネットワークを指していないか（登録時の確認）
+//
+// IPアドレスと localhost のみ確かめる。名前で指定した場合は、接続時に newExternalClient が解決したアドレスを確かめる。
+func checkExternalHost(u *url.URL) error {
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errPrivateAddress
+	}
+	if ip := net.ParseIP(host); ip != nil && isInternalIP(ip) {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+// ListSubscriptions 自分の購読と所属チームの購読を取得
+func (s *SubscriptionService) ListSubscriptions(userID string, sort SortOptions) ([]models.CalendarSubscription, error) {
+	teamIDs := s.db.Model(&models.TeamMember{}).Select("team_id").
+		Where("user_id = ? AND status = ?", userID, models.TeamMemberStatusActive)
+
+	var subscriptions []models.CalendarSubscription
+	query := s.db.Where("(team_id IS NULL AND user_id = ?) OR team_id IN (?)", userID, teamIDs)
+	if err := orderBy(query, sort, "created_at ASC").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// CreateSubscription 外部カレンダーを購読し、初回の取り込みを行う
+//
+// 取り込みに失敗しても購読は作成し、エラーはlastErrorに記録する。
+func (s *SubscriptionService) CreateSubscription(userID string, input SubscriptionInput) (*models.CalendarSubscription, error) {
+	feedURL, err := normalizeFeedURL(input.URL)
+	if err != nil {
+		return nil, err
+	}
+	if input.TeamID != nil {
+		admin, err := isTeamAdmin(s.db, *input.TeamID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !admin {
+			return nil, ErrForbidden
+		}
+	}
+
+	subscription := models.CalendarSubscription{
+		Name:   strings.TrimSpace(input.Name),
+		URL:    feedURL,
+		UserID: userID,
+		TeamID: input.TeamID,
+	}
+	if err := s.db.Create(&subscription).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.sync(&subscription); err != nil {
+		log.Printf("外部カレンダー %s の取り込みに失敗しました: %v", subscription.ID, err)
+	}
+	return s.reload(subscription.ID)
+}
+
+// RefreshSubscription 購読を今すぐ再取得する
+func (s *SubscriptionService) RefreshSubscription(subscriptionID, userID string) (*models.CalendarSubscription, error) {
+	subscription, err := s.findManageableSubscription(subscriptionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sync(subscription); err != nil {
+		log.Printf("外部カレンダー %s の取り込みに失敗しました: %v", subscription.ID, err)
+	}
+	return s.reload(subscription.ID)
+}
+
+// DeleteSubscription 購読と取り込んだイベントを削除する
+func (s *SubscriptionService) DeleteSubscription(subscriptionID, userID string) error {
+	subscription, err := s.findManageableSubscription(subscriptionID, userID)
+	if err != nil {
+		return err
+	}
+	return models.ExternalSync(s.db).Transaction(func(tx *gorm.DB) error {
+		events := tx.Model(&models.Event{}).Select("id").Where("subscription_id = ?", subscription.ID)
+		if err := tx.Where("event_id IN (?)", events).Delete(&models.EventException{}).Error; err != nil {
+			return err
+		}
+		// 取り込んだイベントは外部のカレンダーの写しのため、論理削除せずに完全に削除する
+		if err := tx.Unscoped().Where("subscription_id = ?", subscription.ID).Delete(&models.Event{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(subscription).Error
+	})
+}
+
+// SyncDueSubscriptions 再取得間隔を過ぎた購読をすべて同期する（Cronジョブ用）
+func (s *SubscriptionService) SyncDueSubscriptions() error {
+	var subscriptions []models.CalendarSubscription
+	if err := s.db.Where("last_fetched_at IS NULL OR last_fetched_at <= ?", time.Now().Add(-s.refreshInterval)).
+		Find(&subscriptions).Error; err != nil {
+		return err
+	}
+	for i := range subscriptions {
+		if err := s.sync(&subscriptions[i]); err != nil {
+			log.Printf("外部カレンダー %s の取り込みに失敗しました: %v", subscriptions[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// sync フィードを取得してイベントを反映し、取得結果を購読に記録する
+func (s *SubscriptionService) sync(subscription *models.CalendarSubscription) error {
+	updates := map[string]interface{}{"last_fetched_at": time.Now(), "last_error": ""}
+
+	cal, etag, lastModified, err := s.fetch(subscription)
+	if err == nil && cal != nil {
+		var count int
+		count, err = s.mirror(subscription, cal)
+		updates["etag"] = etag
+		updates["last_modified"] = lastModified
+		updates["event_count"] = count
+	}
+	if err != nil {
+		updates["last_error"] = err.Error()
+		// 取り込みに失敗した場合は次回に全体を取り直す
+		updates["etag"] = ""
+		updates["last_modified"] = ""
+	}
+
+	if updateErr := s.db.Model(subscription).Updates(updates).Error; updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+// fetch フィードを取得する（変更がない場合はcalがnil）
+func (s *SubscriptionService) fetch(subscription *models.CalendarSubscription) (*ical.Component, string, string, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, subscription.URL, nil)
+	if err != nil {
+		return nil, "", "", err
+	}
+	req.Header.Set("Accept", "text/calendar")
+	if subscription.ETag != "" {
+		req.Header.Set("If-None-Match", subscription.ETag)
+	}
+	if subscription.LastModified != "" {
+		req.Header.Set("If-Modified-Since", subscription.LastModified)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", "", err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return nil, "", "", nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, "", "", fmt.Errorf("フィードの取得に失敗しました（HTTP %d）", resp.StatusCode)
+	}
+
+	body := io.LimitReader(resp.Body, maxFeedSize+1)
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if len(data) > maxFeedSize {
+		return nil, "", "", errors.New("フィードのサイズが上限を超えています")
+	}
+
+	cal, err := ical.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", "", err
+	}
+	if cal.Name != "VCALENDAR" {
+		return nil, "", "", errors.New("VCALENDARではありません")
+	}
+	return cal, resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), nil
+}
+
+// mirror フィードの内容で購読のイベントを置き換える（UIDで対応付けて更新、消えたものは削除）
+func (s *SubscriptionService) mirror(subscription *models.CalendarSubscription, cal *ical.Component) (int, error) {
+	uids, masters, overrides := groupVEvents(cal)
+	loc := calendarLocation(cal, time.UTC)
+
+	count := 0
+	err := models.ExternalSync(s.db).Transaction(func(tx *gorm.DB) error {
+		var existing []models.Event
+		if err := tx.Where("subscription_id = ?", subscription.ID).Find(&existing).Error; err != nil {
+			return err
+		}
+		byUID := make(map[string]*models.Event, len(existing))
+		for i := range existing {
+			byUID[existing[i].ICalUID] = &existing[i]
+		}
+
+		seen := make(map[string]bool, len(uids))
+		for _, uid := range uids {
+			imported, err := convertVEvent(masters[uid], overrides[uid], loc)
+			if err != nil {
+				// 壊れたVEVENTはスキップして残りを取り込む
+				continue
+			}
+			seen[uid] = true
+			count++
+
+			eventID := ""
+			if event, ok := byUID[uid]; ok {
+				eventID = event.ID
+				if err := tx.Model(event).Updates(map[string]interface{}{
+					"title":        imported.event.Title,
+					"description":  imported.event.Description,
+					"start_date":   imported.event.StartDate,
+					"end_date":     imported.event.EndDate,
+					"all_day":      imported.event.AllDay,
+					"time_zone":    imported.event.TimeZone,
+					"location":     imported.event.Location,
+					"is_recurring": imported.event.IsRecurring,
+					"recurrence":   imported.event.Recurrence,
+					"type":         imported.event.Type,
+					"visibility":   imported.event.Visibility,
+					"status":       imported.event.Status,
+				}).Error; err != nil {
+					return err
+				}
+				if err := tx.Where("event_id = ?", eventID).Delete(&models.EventException{}).Error; err != nil {
+					return err
+				}
+			} else {
+				event := imported.event
+				event.ICalUID = uid
+				event.CreatorID = subscription.UserID
+				event.TeamID = subscription.TeamID
+				event.SubscriptionID = &subscription.ID
+				if err := tx.Create(&event).Error; err != nil {
+					return err
+				}
+				eventID = event.ID
+			}
+
+			for i := range imported.exceptions {
+				imported.exceptions[i].EventID = eventID
+				if err := tx.Create(&imported.exceptions[i]).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		var removed []string
+		for uid, event := range byUID {
+			if !seen[uid] {
+				removed = append(removed, event.ID)
+			}
+		}
+		if len(removed) == 0 {
+			return nil
+		}
+		if err := tx.Where("event_id IN ?", removed).Delete(&models.EventException{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", removed).Delete(&models.Event{}).Error
+	})
+	return count, err
+}
+
+// findManageableSubscription 購読の登録者またはチーム管理者のみ操作できる
+func (s *SubscriptionService) findManageableSubscription(subscriptionID, userID string) (*models.CalendarSubscription, error) {
+	subscription, err := s.reload(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.UserID == userID && subscription.TeamID == nil {
+		return subscription, nil
+	}
+	if subscription.TeamID != nil {
+		admin, err := isTeamAdmin(s.db, *subscription.TeamID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if admin || subscription.UserID == userID {
+			return subscription, nil
+		}
+	}
+	return nil, ErrForbidden
+}
+
+func (s *SubscriptionService) reload(subscriptionID string) (*models.CalendarSubscription, error) {
+	var subscription models.CalendarSubscription
+	if err := s.db.First(&subscription, "id = ?", subscriptionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// normalizeFeedURL webcal:// を https:// に読み替え、http(s)のURLのみ受け付ける
+func normalizeFeedURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(strings.ToLower(raw), "webcal://") {
+		raw = "https://" + raw[len("webcal://"):]
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%w: urlはhttp(s)またはwebcalのURLを指定してください", ErrInvalidInput)
+	}
+	return u.String(), nil
+}
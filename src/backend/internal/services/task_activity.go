@@ -0,0 +1,31 @@
+package services
+
+import (
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// recordTaskActivity タスクの操作履歴を記録する
+func recordTaskActivity(tx *gorm.DB, taskID, actorID string, action models.TaskActivityAction, details string) error {
+	return tx.Create(&models.TaskActivity{
+		TaskID:  taskID,
+		ActorID: actorID,
+		Action:  action,
+		Details: details,
+	}).Error
+}
+
+// GetActivity タスクの操作履歴を取得
+func (s *TaskService) GetActivity(taskID, userID string, sort SortOptions, include IncludeOptions) ([]models.TaskActivity, error) {
+	if _, err := findTaskForMember(s.db, taskID, userID); err != nil {
+		return nil, err
+	}
+
+	var activities []models.TaskActivity
+	if err := orderBy(preload(s.db, include).Where("task_id = ?", taskID), sort, "created_at DESC").
+		Find(&activities).Error; err != nil {
+		return nil, err
+	}
+	return activities, nil
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ChecklistItemInput チェックリスト項目の作成・更新内容
+type ChecklistItemInput struct {
+	Content     *string `json:"content" binding:"omitempty,notblank,max=500"`
+	Position    *int    `json:"position" binding:"omitempty,min=0"`
+	IsCompleted *bool   `json:"isCompleted"`
+}
+
+// OccurrenceStats 繰り返しタスクの完了統計のサマリー
+type OccurrenceStats struct {
+	Occurrences           []models.TaskOccurrence `json:"occurrences"`
+	TotalOccurrences      int                     `json:"totalOccurrences"`
+	FullyCompleted        int                     `json:"fullyCompleted"`
+	AverageCompletionRate float64                 `json:"averageCompletionRate"` // 項目のあった回の完了率の平均（該当する回がなければ0）
+}
+
+// GetChecklist タスクのチェックリストを取得
+func (s *TaskService) GetChecklist(taskID, userID string, sort SortOptions) ([]models.ChecklistItem, error) {
+	if _, err := findTaskForMember(s.db, taskID, userID); err != nil {
+		return nil, err
+	}
+
+	var items []models.ChecklistItem
+	if err := orderBy(s.db.Where("task_id = ?", taskID), sort, "position ASC, created_at ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// AddChecklistItem チェックリスト項目を追加
+func (s *TaskService) AddChecklistItem(taskID, userID string, input ChecklistItemInput) (*models.ChecklistItem, error) {
+	if _, err := findTaskForMember(s.db, taskID, userID); err != nil {
+		return nil, err
+	}
+	if input.Content == nil || *input.Content == "" {
+		return nil, fmt.Errorf("%w: contentは必須です", ErrInvalidInput)
+	}
+
+	item := models.ChecklistItem{
+		TaskID:  taskID,
+		Content: *input.Content,
+	}
+	if input.Position != nil {
+		item.Position = *input.Position
+	} else {
+		var count int64
+		s.db.Model(&models.ChecklistItem{}).Where("task_id = ?", taskID).Count(&count)
+		item.Position = int(count)
+	}
+
+	if err := s.db.Create(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpdateChecklistItem チェックリスト項目を更新（完了状態の切り替えを含む）
+func (s *TaskService) UpdateChecklistItem(taskID, itemID, userID string, input ChecklistItemInput) (*models.ChecklistItem, error) {
+	item, err := s.findChecklistItem(taskID, itemID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if input.Content != nil {
+		if *input.Content == "" {
+			return nil, fmt.Errorf("%w: contentは空にできません", ErrInvalidInput)
+		}
+		updates["content"] = *input.Content
+	}
+	if input.Position != nil {
+		updates["position"] = *input.Position
+	}
+	if input.IsCompleted != nil && *input.IsCompleted != item.IsCompleted {
+		updates["is_completed"] = *input.IsCompleted
+		if *input.IsCompleted {
+			now := time.Now()
+			updates["completed_at"] = &now
+			updates["completed_by_id"] = &userID
+		} else {
+			updates["completed_at"] = nil
+			updates["completed_by_id"] = nil
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := s.db.Model(item).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+	return s.findChecklistItem(taskID, itemID, userID)
+}
+
+// DeleteChecklistItem チェックリスト項目を削除（保存期間のあいだはゴミ箱から復元できる）
+func (s *TaskService) DeleteChecklistItem(taskID, itemID, userID string) error {
+	item, err := s.findChecklistItem(taskID, itemID, userID)
+	if err != nil {
+		return err
+	}
+	return s.db.Delete(item).Error
+}
+
+// SetRecurrence タスクの繰り返し設定を変更
+func (s *TaskService) SetRecurrence(taskID, userID string, isRecurring bool, recurrence models.TaskRecurrence) (*models.Task, error) {
+	task, err := findTaskForMember(s.db, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if isRecurring {
+		if _, ok := recurrence.Next(time.Now()); !ok {
+			return nil, fmt.Errorf("%w: recurrenceが不正です", ErrInvalidInput)
+		}
+		if task.DueDate == nil {
+			return nil, fmt.Errorf("%w: 繰り返しタスクには期限日が必要です", ErrInvalidInput)
+		}
+	} else {
+		recurrence = ""
+	}
+
+	if err := s.db.Model(task).Updates(map[string]interface{}{
+		"is_recurring": isRecurring,
+		"recurrence":   recurrence,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// GetOccurrenceStats 繰り返しタスクの各回の完了統計を取得
+func (s *TaskService) GetOccurrenceStats(taskID, userID string) (*OccurrenceStats, error) {
+	if _, err := findTaskForMember(s.db, taskID, userID); err != nil {
+		return nil, err
+	}
+
+	var occurrences []models.TaskOccurrence
+	if err := s.db.Where("task_id = ?", taskID).Order("due_date DESC").Find(&occurrences).Error; err != nil {
+		return nil, err
+	}
+
+	stats := &OccurrenceStats{
+		Occurrences:      occurrences,
+		TotalOccurrences: len(occurrences),
+	}
+	// 平均の完了率は、チェックリストの項目があった回のみで求める（項目のない回は完了率を持たない）
+	var rateSum float64
+	var withItems int
+	for _, o := range occurrences {
+		if o.TotalItems == 0 {
+			continue
+		}
+		if o.CompletedItems == o.TotalItems {
+			stats.FullyCompleted++
+		}
+		rateSum += float64(o.CompletedItems) / float64(o.TotalItems)
+		withItems++
+	}
+	if withItems > 0 {
+		stats.AverageCompletionRate = rateSum / float64(withItems)
+	}
+	return stats, nil
+}
+
+// ResetRecurringChecklists 期限を過ぎた繰り返しタスクを次回に進め、チェックリストをリセットする
+func (s *TaskService) ResetRecurringChecklists() error {
+	now := time.Now()
+
+	var tasks []models.Task
+	if err := s.db.Where("is_recurring = ? AND due_date IS NOT NULL AND due_date <= ?", true, now).
+		Find(&tasks).Error; err != nil {
+		return err
+	}
+
+	for i := range tasks {
+		if err := s.advanceOccurrence(&tasks[i], now); err != nil {
+			log.Printf("繰り返しタスク %s のリセットに失敗しました: %v", tasks[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// advanceOccurrence 現在の回の統計を記録し、チェックリストをリセットして次回の期限を設定
+func (s *TaskService) advanceOccurrence(task *models.Task, now time.Time) error {
+	next, ok := task.Recurrence.Next(*task.DueDate)
+	if !ok {
+		return fmt.Errorf("不明な繰り返し周期です: %s", task.Recurrence)
+	}
+	for !next.After(now) {
+		next, _ = task.Recurrence.Next(next)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var total, completed int64
+		if err := tx.Model(&models.ChecklistItem{}).Where("task_id = ?", task.ID).Count(&total).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ChecklistItem{}).Where("task_id = ? AND is_completed = ?", task.ID, true).
+			Count(&completed).Error; err != nil {
+			return err
+		}
+
+		occurrence := models.TaskOccurrence{
+			TaskID:         task.ID,
+			DueDate:        *task.DueDate,
+			Status:         task.Status,
+			TotalItems:     int(total),
+			CompletedItems: int(completed),
+			ResetAt:        now,
+		}
+		if err := tx.Create(&occurrence).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.ChecklistItem{}).Where("task_id = ?", task.ID).Updates(map[string]interface{}{
+			"is_completed":    false,
+			"completed_at":    nil,
+			"completed_by_id": nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(task).Updates(map[string]interface{}{
+			"due_date": next,
+			"status":   models.TaskStatusTodo,
+		}).Error
+	})
+}
+
+func (s *TaskService) findChecklistItem(taskID, itemID, userID string) (*models.ChecklistItem, error) {
+	if _, err := findTaskForMember(s.db, taskID, userID); err != nil {
+		return nil, err
+	}
+
+	var item models.ChecklistItem
+	if err := s.db.First(&item, "id = ? AND task_id = ?", itemID, taskID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &item, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"fmt"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MergeTask 重複タスク(source)を対象タスク(target)に統合する
+// コメント・添付ファイル・ウォッチャー・チェックリストを移動し、統合元は重複としてクローズする
+func (s *TaskService) MergeTask(targetID, sourceID, userID string) (*models.Task, error) {
+	if targetID == sourceID {
+		return nil, fmt.Errorf("%w: 同じタスク同士は統合できません", ErrInvalidInput)
+	}
+
+	target, err := findTaskForMember(s.db, targetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	source, err := findTaskForMember(s.db, sourceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if source.TeamID != target.TeamID {
+		return nil, fmt.Errorf("%w: 別チームのタスクは統合できません", ErrInvalidInput)
+	}
+	if source.DuplicateOfID != nil {
+		return nil, fmt.Errorf("%w: 統合元のタスクは既に統合済みです", ErrInvalidInput)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Comment{}).Where("task_id = ?", source.ID).
+			Update("task_id", target.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Attachment{}).Where("task_id = ?", source.ID).
+			Update("task_id", target.ID).Error; err != nil {
+			return err
+		}
+
+		// ウォッチャーは重複を除いて統合先に移す
+		var watchers []models.TaskWatcher
+		if err := tx.Where("task_id = ?", source.ID).Find(&watchers).Error; err != nil {
+			return err
+		}
+		for _, w := range watchers {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&models.TaskWatcher{TaskID: target.ID, UserID: w.UserID}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("task_id = ?", source.ID).Delete(&models.TaskWatcher{}).Error; err != nil {
+			return err
+		}
+
+		// チェックリストは統合先の末尾に追加する
+		var offset int64
+		if err := tx.Model(&models.ChecklistItem{}).Where("task_id = ?", target.ID).Count(&offset).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ChecklistItem{}).Where("task_id = ?", source.ID).Updates(map[string]interface{}{
+			"task_id":  target.ID,
+			"position": gorm.Expr("position + ?", offset),
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(source).Updates(map[string]interface{}{
+			"status":          models.TaskStatusCancelled,
+			"duplicate_of_id": target.ID,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := recordTaskActivity(tx, target.ID, userID, models.TaskActivityMergedFrom,
+			fmt.Sprintf("タスク「%s」(%s) を統合しました", source.Title, source.ID)); err != nil {
+			return err
+		}
+		return recordTaskActivity(tx, source.ID, userID, models.TaskActivityMergedInto,
+			fmt.Sprintf("タスク「%s」(%s) に統合されました", target.Title, target.ID))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var merged models.Task
+	if err := s.db.Preload("ChecklistItems").Scopes(PreloadPinnedComments).First(&merged, "id = ?", target.ID).Error; err != nil {
+		return nil, err
+	}
+	return &merged, nil
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// WatchTask タスクをウォッチする
+func (s *TaskService) WatchTask(taskID, userID string) error {
+	if _, err := findTaskForMember(s.db, taskID, userID); err != nil {
+		return err
+	}
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.TaskWatcher{TaskID: taskID, UserID: userID}).Error
+}
+
+// UnwatchTask タスクのウォッチを解除する
+func (s *TaskService) UnwatchTask(taskID, userID string) error {
+	if _, err := findTaskForMember(s.db, taskID, userID); err != nil {
+		return err
+	}
+	return s.db.Where("task_id = ? AND user_id = ?", taskID, userID).Delete(&models.TaskWatcher{}).Error
+}
+
+// GetWatchers タスクのウォッチャー一覧を取得
+func (s *TaskService) GetWatchers(taskID, userID string, sort SortOptions, include IncludeOptions) ([]models.TaskWatcher, error) {
+	if _, err := findTaskForMember(s.db, taskID, userID); err != nil {
+		return nil, err
+	}
+
+	var watchers []models.TaskWatcher
+	if err := orderBy(preload(s.db, include).Where("task_id = ?", taskID), sort, "created_at ASC").Find(&watchers).Error; err != nil {
+		return nil, err
+	}
+	return watchers, nil
+}
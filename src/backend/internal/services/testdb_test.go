@@ -0,0 +1,50 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+
+	"task-calendar-backend/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// openTestDB マイグレーションを適用した空のSQLiteのデータベース
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Connect("sqlite:"+filepath.Join(t.TempDir(), "test.db"), database.Options{})
+	if err != nil {
+		t.Fatalf("接続に失敗しました: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if _, err := database.MigrateUp(db); err != nil {
+		t.Fatalf("マイグレーションに失敗しました: %v", err)
+	}
+	return db
+}
+
+// execAll SQLを順に実行する（IDを指定してデータを用意するため、モデルを使わずに登録する）
+func execAll(t *testing.T, db *gorm.DB, statements ...string) {
+	t.Helper()
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("%s: %v", statement, err)
+		}
+	}
+}
+
+// seedTeam ユーザー u1（チーム t1 のオーナー）と u2（メンバー）を登録する
+func seedTeam(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	execAll(t, db,
+		`INSERT INTO users (id, email, username, password, first_name, last_name) VALUES ('u1', 'u1@example.com', 'u1', 'x', 'U', '1')`,
+		`INSERT INTO users (id, email, username, password, first_name, last_name) VALUES ('u2', 'u2@example.com', 'u2', 'x', 'U', '2')`,
+		`INSERT INTO teams (id, name, creator_id) VALUES ('t1', 'Team', 'u1')`,
+		`INSERT INTO team_members (id, user_id, team_id, role, status) VALUES ('m1', 'u1', 't1', 'OWNER', 'ACTIVE')`,
+		`INSERT INTO team_members (id, user_id, team_id, role, status) VALUES ('m2', 'u2', 't1', 'MEMBER', 'ACTIVE')`,
+	)
+}
@@ -0,0 +1,393 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/storage"
+
+	"gorm.io/gorm"
+)
+
+// 完全な削除を一度に行う件数
+const trashPurgeBatchSize = 100
+
+// TrashRetention 削除したものを復元できる期間（種類ごと。過ぎたものは PurgeExpired で完全に削除する）
+type TrashRetention struct {
+	Tasks          time.Duration
+	Events         time.Duration
+	Comments       time.Duration
+	Attachments    time.Duration
+	ChecklistItems time.Duration
+}
+
+// Trash 復元できる削除したタスク・イベント・コメント・添付ファイル・チェックリスト項目（削除した新しい順）
+type Trash struct {
+	Tasks          []models.Task          `json:"tasks"`
+	Events         []models.Event         `json:"events"`
+	Comments       []models.Comment       `json:"comments"`
+	Attachments    []models.Attachment    `json:"attachments"`
+	ChecklistItems []models.ChecklistItem `json:"checklistItems"`
+}
+
+// TrashService 削除した（論理削除した）タスク・イベント・コメント・添付ファイル・チェックリスト項目の一覧・復元と、保存期間を過ぎたものの完全な削除
+//
+// これらの削除は deleted_at を記録するだけにし、保存期間のあいだは復元できる。
+// 関連するデータ（コメントの履歴・リアクション、イベントの参加者、添付ファイルの内容など）は完全に削除するまで残す。
+//
+// チーム・ラベル・イベントカテゴリーは対象にせず、これまでどおり削除した時点で完全に削除する。
+// ラベル・カテゴリーはチーム内で名前が一意のため、残すと同じ名前で作り直せなくなる（削除するとタスク・イベントからも外れる）。
+// チームの削除は所属するデータをまとめて削除する管理者の操作で、ゴミ箱から個別に戻す単位ではない。
+type TrashService struct {
+	db        *gorm.DB
+	storage   storage.Storage
+	retention TrashRetention
+}
+
+func NewTrashService(db *gorm.DB, storage storage.Storage, retention TrashRetention) *TrashService {
+	return &TrashService{db: db, storage: storage, retention: retention}
+}
+
+// ListTrash 自分が復元できる削除したものの一覧
+//
+// タスクは所属するチームのもの、イベントは自分が作成した、または管理するチームの非公開でないもの。
+// コメント・添付ファイル・チェックリスト項目は削除されていないタスクのもので、
+// コメントと添付ファイルは自分が投稿した、または管理するチームのもの（モデレーションで削除したコメントは除く）。
+func (s *TrashService) ListTrash(userID string) (*Trash, error) {
+	teamIDs := s.db.Session(&gorm.Session{NewDB: true}).Model(&models.TeamMember{}).
+		Select("team_id").Where("user_id = ? AND status = ?", userID, models.TeamMemberStatusActive)
+	adminTeamIDs := s.db.Session(&gorm.Session{NewDB: true}).Model(&models.TeamMember{}).
+		Select("team_id").Where("user_id = ? AND status = ? AND role IN ?", userID, models.TeamMemberStatusActive,
+		[]models.TeamMemberRole{models.TeamMemberRoleOwner, models.TeamMemberRoleAdmin})
+
+	trash := &Trash{Tasks: []models.Task{}, Events: []models.Event{}, Comments: []models.Comment{},
+		Attachments: []models.Attachment{}, ChecklistItems: []models.ChecklistItem{}}
+	if err := s.db.Unscoped().Scopes(inWorkspaceOf(userID)).
+		Where("tasks.deleted_at IS NOT NULL AND tasks.team_id IN (?)", teamIDs).
+		Order("tasks.deleted_at DESC").Find(&trash.Tasks).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Unscoped().Scopes(inWorkspaceOf(userID)).
+		Where("events.deleted_at IS NOT NULL AND events.subscription_id IS NULL AND events.connection_id IS NULL").
+		Where("events.creator_id = ? OR (events.team_id IN (?) AND events.visibility <> ?)",
+			userID, adminTeamIDs, models.EventVisibilityPrivate).
+		Order("events.deleted_at DESC").Find(&trash.Events).Error; err != nil {
+		return nil, err
+	}
+
+	taskIDs := s.db.Session(&gorm.Session{NewDB: true}).Model(&models.Task{}).Scopes(inWorkspaceOf(userID)).
+		Select("id").Where("team_id IN (?)", teamIDs)
+	adminTaskIDs := s.db.Session(&gorm.Session{NewDB: true}).Model(&models.Task{}).Scopes(inWorkspaceOf(userID)).
+		Select("id").Where("team_id IN (?)", adminTeamIDs)
+	moderatedIDs := s.db.Session(&gorm.Session{NewDB: true}).Model(&models.CommentReport{}).
+		Select("comment_id").Where("action = ?", models.ModerationActionDelete)
+	if err := s.db.Unscoped().
+		Where("comments.deleted_at IS NOT NULL AND comments.task_id IN (?)", taskIDs).
+		Where("comments.author_id = ? OR comments.task_id IN (?)", userID, adminTaskIDs).
+		Where("comments.id NOT IN (?)", moderatedIDs).
+		Order("comments.deleted_at DESC").Find(&trash.Comments).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Unscoped().
+		Where("attachments.deleted_at IS NOT NULL AND attachments.task_id IN (?)", taskIDs).
+		Where("attachments.uploader_id = ? OR attachments.task_id IN (?)", userID, adminTaskIDs).
+		Order("attachments.deleted_at DESC").Find(&trash.Attachments).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Unscoped().
+		Where("checklist_items.deleted_at IS NOT NULL AND checklist_items.task_id IN (?)", taskIDs).
+		Order("checklist_items.deleted_at DESC").Find(&trash.ChecklistItems).Error; err != nil {
+		return nil, err
+	}
+	return trash, nil
+}
+
+// RestoreTask 削除したタスクを復元する（チームのメンバー。削除されていない場合はそのまま返す）
+func (s *TrashService) RestoreTask(taskID, userID string) (*models.Task, error) {
+	task, err := findTaskForMember(s.db.Unscoped(), taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !task.DeletedAt.Valid {
+		return task, nil
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(task).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return recordTaskActivity(tx, task.ID, userID, models.TaskActivityRestored, "タスクを復元しました")
+	})
+	if err != nil {
+		return nil, err
+	}
+	task.DeletedAt = gorm.DeletedAt{}
+	return task, nil
+}
+
+// RestoreEvent 削除したイベントを復元する（作成者またはチーム管理者。削除されていない場合はそのまま返す）
+//
+// 会議室を予約していた場合、削除した後に同じ時間帯が予約されていると復元できない。
+func (s *TrashService) RestoreEvent(eventID, userID string) (*models.Event, error) {
+	event, err := findEditableEvent(s.db.Unscoped(), eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !event.DeletedAt.Valid {
+		return event, nil
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(event).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return ensureSeriesRoomAvailable(tx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+	event.DeletedAt = gorm.DeletedAt{}
+	return event, nil
+}
+
+// RestoreComment 削除したコメントを復元する（投稿者本人またはチーム管理者。モデレーションで削除したものは復元できない）
+func (s *TrashService) RestoreComment(taskID, commentID, userID string) (*models.Comment, error) {
+	task, err := findTaskForMember(s.db, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var comment models.Comment
+	if err := s.db.Unscoped().First(&comment, "id = ? AND task_id = ?", commentID, taskID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if comment.AuthorID != userID {
+		admin, err := isTeamAdmin(s.db, task.TeamID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !admin {
+			return nil, ErrForbidden
+		}
+	}
+	if !comment.DeletedAt.Valid {
+		return &comment, nil
+	}
+	var moderated int64
+	if err := s.db.Model(&models.CommentReport{}).
+		Where("comment_id = ? AND action = ?", comment.ID, models.ModerationActionDelete).
+		Count(&moderated).Error; err != nil {
+		return nil, err
+	}
+	if moderated > 0 {
+		return nil, fmt.Errorf("%w: モデレーションで削除したコメントは復元できません", ErrForbidden)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&comment).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return recordTaskActivity(tx, taskID, userID, models.TaskActivityCommentRestored,
+			fmt.Sprintf("コメント %s を復元しました", comment.ID))
+	})
+	if err != nil {
+		return nil, err
+	}
+	comment.DeletedAt = gorm.DeletedAt{}
+	return &comment, nil
+}
+
+// RestoreAttachment 削除した添付ファイルを復元する（アップロードした本人またはチーム管理者。削除されていない場合はそのまま返す）
+func (s *TrashService) RestoreAttachment(taskID, attachmentID, userID string) (*models.Attachment, error) {
+	task, err := findTaskForMember(s.db, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var attachment models.Attachment
+	if err := s.db.Unscoped().First(&attachment, "id = ? AND task_id = ?", attachmentID, taskID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if attachment.UploaderID != userID {
+		admin, err := isTeamAdmin(s.db, task.TeamID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !admin {
+			return nil, ErrForbidden
+		}
+	}
+	if !attachment.DeletedAt.Valid {
+		return &attachment, nil
+	}
+
+	if err := s.db.Unscoped().Model(&attachment).Update("deleted_at", nil).Error; err != nil {
+		return nil, err
+	}
+	attachment.DeletedAt = gorm.DeletedAt{}
+	return &attachment, nil
+}
+
+// RestoreChecklistItem 削除したチェックリスト項目を復元する（チームのメンバー。削除されていない場合はそのまま返す）
+func (s *TrashService) RestoreChecklistItem(taskID, itemID, userID string) (*models.ChecklistItem, error) {
+	if _, err := findTaskForMember(s.db, taskID, userID); err != nil {
+		return nil, err
+	}
+	var item models.ChecklistItem
+	if err := s.db.Unscoped().First(&item, "id = ? AND task_id = ?", itemID, taskID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if !item.DeletedAt.Valid {
+		return &item, nil
+	}
+
+	if err := s.db.Unscoped().Model(&item).Update("deleted_at", nil).Error; err != nil {
+		return nil, err
+	}
+	item.DeletedAt = gorm.DeletedAt{}
+	return &item, nil
+}
+
+// PurgeExpired 保存期間を過ぎた削除したものを完全に削除する（定期実行）
+//
+// IDを指定せずに削除するため、削除したときと違い差分同期・Webhookには伝えない（削除は論理削除したときに伝えている）。
+func (s *TrashService) PurgeExpired() error {
+	now := time.Now()
+	if err := s.purgeBatches(&models.Attachment{}, now.Add(-s.retention.Attachments), s.purgeAttachments); err != nil {
+		return err
+	}
+	if err := s.purgeBatches(&models.ChecklistItem{}, now.Add(-s.retention.ChecklistItems), s.purgeChecklistItems); err != nil {
+		return err
+	}
+	if err := s.purgeBatches(&models.Comment{}, now.Add(-s.retention.Comments), s.purgeComments); err != nil {
+		return err
+	}
+	if err := s.purgeBatches(&models.Task{}, now.Add(-s.retention.Tasks), s.purgeTasks); err != nil {
+		return err
+	}
+	return s.purgeBatches(&models.Event{}, now.Add(-s.retention.Events), s.purgeEvents)
+}
+
+// purgeBatches before より前に削除した model の行を、trashPurgeBatchSize 件ずつ purge で完全に削除する
+func (s *TrashService) purgeBatches(model interface{}, before time.Time, purge func(ids []string) error) error {
+	for {
+		var ids []string
+		if err := s.db.Unscoped().Model(model).Where("deleted_at < ?", before).
+			Order("deleted_at ASC").Limit(trashPurgeBatchSize).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := purge(ids); err != nil {
+			return err
+		}
+		if len(ids) < trashPurgeBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *TrashService) purgeComments(ids []string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return purgeCommentsTx(tx, ids)
+	})
+}
+
+// purgeCommentsTx コメントと履歴・リアクション・メンションを完全に削除する（添付ファイルはタスクの添付として残す）
+func purgeCommentsTx(tx *gorm.DB, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := tx.Where("comment_id IN ?", ids).Delete(&models.CommentRevision{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("comment_id IN ?", ids).Delete(&models.CommentReaction{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("comment_id IN ?", ids).Delete(&models.CommentMention{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Model(&models.Attachment{}).Where("comment_id IN ?", ids).
+		Update("comment_id", nil).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN ?", ids).Delete(&models.Comment{}).Error
+}
+
+// purgeTasks タスクと、コメント・添付ファイル・操作履歴などタスクだけに属するデータを完全に削除する
+func (s *TrashService) purgeTasks(ids []string) error {
+	var attachments []models.Attachment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var commentIDs []string
+		if err := tx.Unscoped().Model(&models.Comment{}).Where("task_id IN ?", ids).
+			Pluck("id", &commentIDs).Error; err != nil {
+			return err
+		}
+		if err := purgeCommentsTx(tx, commentIDs); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Select("id", "storage_key").Where("task_id IN ?", ids).Find(&attachments).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{
+			&models.Attachment{}, &models.ChecklistItem{}, &models.TaskActivity{}, &models.TaskOccurrence{}, &models.EmailReplyToken{},
+		} {
+			if err := tx.Unscoped().Where("task_id IN ?", ids).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&models.Task{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	s.deleteFiles(attachments)
+	return nil
+}
+
+// purgeAttachments 添付ファイルを完全に削除する（ストレージのファイルは行を削除した後に消す）
+func (s *TrashService) purgeAttachments(ids []string) error {
+	var attachments []models.Attachment
+	if err := s.db.Unscoped().Select("id", "storage_key").Where("id IN ?", ids).Find(&attachments).Error; err != nil {
+		return err
+	}
+	if err := s.db.Unscoped().Where("id IN ?", ids).Delete(&models.Attachment{}).Error; err != nil {
+		return err
+	}
+	s.deleteFiles(attachments)
+	return nil
+}
+
+func (s *TrashService) purgeChecklistItems(ids []string) error {
+	return s.db.Unscoped().Where("id IN ?", ids).Delete(&models.ChecklistItem{}).Error
+}
+
+// deleteFiles 完全に削除した添付ファイルの内容をストレージから消す（失敗しても行の削除は戻さずにログに残す）
+func (s *TrashService) deleteFiles(attachments []models.Attachment) {
+	for _, attachment := range attachments {
+		if err := s.storage.Delete(attachment.StorageKey); err != nil {
+			log.Printf("添付ファイルの削除に失敗しました（%s）: %v", attachment.ID, err)
+		}
+	}
+}
+
+// purgeEvents イベントを完全に削除する（参加者・回ごとの変更・リマインダーなどは外部キーで削除される）
+func (s *TrashService) purgeEvents(ids []string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("event_id IN ?", ids).Delete(&models.EventReplyToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&models.Event{}).Error
+	})
+}
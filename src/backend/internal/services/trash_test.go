@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/storage"
+)
+
+// seedTrash タスク k1 に、削除したコメント・添付ファイル・チェックリスト項目を登録する
+//
+// c1 は u2、c2 は u1 が投稿したコメントで、c3 はモデレーションで削除したコメント。
+func seedTrash(t *testing.T, s *TrashService) {
+	t.Helper()
+	seedTeam(t, s.db)
+	execAll(t, s.db,
+		`INSERT INTO tasks (id, title, team_id, creator_id) VALUES ('k1', 'Task', 't1', 'u1')`,
+		`INSERT INTO comments (id, content, task_id, author_id, deleted_at) VALUES ('c1', 'A', 'k1', 'u2', '2026-01-01 00:00:00')`,
+		`INSERT INTO comments (id, content, task_id, author_id, deleted_at) VALUES ('c2', 'B', 'k1', 'u1', '2026-01-02 00:00:00')`,
+		`INSERT INTO comments (id, content, task_id, author_id, deleted_at) VALUES ('c3', 'C', 'k1', 'u2', '2026-01-03 00:00:00')`,
+		`INSERT INTO comment_reports (id, reason, status, action, task_id, comment_id, author_id, reporter_id) `+
+			`VALUES ('r1', 'spam', 'RESOLVED', 'DELETE', 'k1', 'c3', 'u2', 'u1')`,
+		`INSERT INTO attachments (id, file_name, storage_key, task_id, uploader_id, deleted_at) `+
+			`VALUES ('a1', 'a.txt', 'attachments/k1/a1', 'k1', 'u1', '2026-01-01 00:00:00')`,
+		`INSERT INTO checklist_items (id, content, task_id, deleted_at) VALUES ('i1', 'item', 'k1', '2026-01-01 00:00:00')`,
+	)
+}
+
+func newTestTrashService(t *testing.T) *TrashService {
+	t.Helper()
+	store, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := 24 * time.Hour
+	return NewTrashService(openTestDB(t), store, TrashRetention{
+		Tasks: day, Events: day, Comments: day, Attachments: day, ChecklistItems: day,
+	})
+}
+
+func TestListTrash(t *testing.T) {
+	s := newTestTrashService(t)
+	seedTrash(t, s)
+
+	tests := []struct {
+		userID          string
+		wantComments    string
+		wantAttachments string
+	}{
+		// 管理者はチームのコメントをすべて戻せる（モデレーションで削除したものを除く）
+		{userID: "u1", wantComments: "c2,c1", wantAttachments: "a1"},
+		// メンバーは自分のコメント・添付ファイルのみ
+		{userID: "u2", wantComments: "c1", wantAttachments: ""},
+	}
+	for _, tt := range tests {
+		trash, err := s.ListTrash(tt.userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var comments, attachments []string
+		for _, c := range trash.Comments {
+			comments = append(comments, c.ID)
+		}
+		for _, a := range trash.Attachments {
+			attachments = append(attachments, a.ID)
+		}
+		if got := strings.Join(comments, ","); got != tt.wantComments {
+			t.Errorf("%s: コメントが %q です（%q のはず）", tt.userID, got, tt.wantComments)
+		}
+		if got := strings.Join(attachments, ","); got != tt.wantAttachments {
+			t.Errorf("%s: 添付ファイルが %q です（%q のはず）", tt.userID, got, tt.wantAttachments)
+		}
+		if len(trash.ChecklistItems) != 1 || trash.ChecklistItems[0].ID != "i1" {
+			t.Errorf("%s: チェックリスト項目が %+v です（i1 のみのはず）", tt.userID, trash.ChecklistItems)
+		}
+	}
+
+	// 削除したタスクのものは、タスクを戻すまで一覧に出さない
+	execAll(t, s.db, `UPDATE tasks SET deleted_at = '2026-01-04 00:00:00' WHERE id = 'k1'`)
+	trash, err := s.ListTrash("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash.Tasks) != 1 || len(trash.Comments)+len(trash.Attachments)+len(trash.ChecklistItems) != 0 {
+		t.Errorf("削除したタスクのゴミ箱が %+v です", trash)
+	}
+}
+
+func TestRestoreAttachmentAndChecklistItem(t *testing.T) {
+	s := newTestTrashService(t)
+	seedTrash(t, s)
+
+	if _, err := s.RestoreAttachment("k1", "a1", "u2"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("他のメンバーの添付ファイルを戻したときのエラーが %v です（ErrForbidden のはず）", err)
+	}
+	if _, err := s.RestoreAttachment("k1", "a1", "u1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RestoreChecklistItem("k1", "i1", "u2"); err != nil {
+		t.Fatal(err)
+	}
+
+	var attachment models.Attachment
+	if err := s.db.First(&attachment, "id = ?", "a1").Error; err != nil {
+		t.Errorf("戻した添付ファイルが見つかりません: %v", err)
+	}
+	var item models.ChecklistItem
+	if err := s.db.First(&item, "id = ?", "i1").Error; err != nil {
+		t.Errorf("戻したチェックリスト項目が見つかりません: %v", err)
+	}
+}
+
+// 保存期間を過ぎた添付ファイルは行とファイルを完全に削除し、期間内のものは残す
+func TestPurgeExpiredAttachments(t *testing.T) {
+	s := newTestTrashService(t)
+	seedTrash(t, s)
+	execAll(t, s.db, `INSERT INTO attachments (id, file_name, storage_key, task_id, uploader_id) `+
+		`VALUES ('a2', 'b.txt', 'attachments/k1/a2', 'k1', 'u1')`)
+	for _, key := range []string{"attachments/k1/a1", "attachments/k1/a2"} {
+		if _, err := s.storage.Save(key, strings.NewReader("data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.db.Delete(&models.Attachment{ID: "a2"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.PurgeExpired(); err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	if err := s.db.Unscoped().Model(&models.Attachment{}).Order("id").Pluck("id", &ids).Error; err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ids, ",") != "a2" {
+		t.Errorf("残った添付ファイルが %v です（a2 のみのはず）", ids)
+	}
+	if _, err := s.storage.Open("attachments/k1/a1"); !os.IsNotExist(err) {
+		t.Errorf("保存期間を過ぎたファイルを開いたときのエラーが %v です（存在しないはず）", err)
+	}
+	rc, err := s.storage.Open("attachments/k1/a2")
+	if err != nil {
+		t.Fatalf("保存期間内のファイルが削除されました: %v", err)
+	}
+	rc.Close()
+
+	var items int64
+	if err := s.db.Unscoped().Model(&models.ChecklistItem{}).Count(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+	if items != 0 {
+		t.Errorf("保存期間を過ぎたチェックリスト項目が %d 件残っています", items)
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import "time"
+
+// 入力中の通知を同じ接続から送る最短の間隔（クライアントはキー入力ごとに送ってよい）
+const typingThrottle = 3 * time.Second
+
+// 入力中の通知（内容は保存せず、タスクを閲覧中のユーザーにのみ届ける）
+const (
+	RealtimeEventCommentTyping        = "comment.typing"
+	RealtimeEventCommentTypingStopped = "comment.typing_stopped"
+)
+
+// Typing タスクのコメントを入力中であること（typing が false の場合は入力をやめたこと）を、タスクを閲覧中の他のユーザーに知らせる
+//
+// クライアントは comment.typing を受け取ってから数秒間、続報がなければ表示を消す。
+func (s *RealtimeService) Typing(sub *RealtimeSubscriber, taskID string, typing bool) error {
+	s.mu.Lock()
+	viewing := sub.viewing
+	if typing && sub.typingTaskID == taskID && time.Since(sub.typingAt) < typingThrottle {
+		s.mu.Unlock()
+		return nil
+	}
+	if !typing && sub.typingTaskID != taskID {
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
+
+	// 閲覧中のタスクはメンバーであることを確認済み
+	teamID := ""
+	if viewing != nil && viewing.kind == PresenceTask && viewing.id == taskID {
+		teamID = viewing.teamID
+	} else {
+		task, err := findTaskForMember(s.db, taskID, sub.UserID)
+		if err != nil {
+			return err
+		}
+		teamID = task.TeamID
+	}
+
+	s.mu.Lock()
+	if typing {
+		sub.typingTaskID, sub.typingAt = taskID, time.Now()
+	} else {
+		sub.typingTaskID = ""
+	}
+	s.mu.Unlock()
+
+	eventType := RealtimeEventCommentTyping
+	if !typing {
+		eventType = RealtimeEventCommentTypingStopped
+	}
+	s.publishToTaskViewers(RealtimeEvent{
+		Type:       eventType,
+		EntityType: "task",
+		EntityID:   taskID,
+		TeamID:     teamID,
+		TaskID:     taskID,
+		UserID:     sub.UserID,
+	})
+	return nil
+}
+
+// publishToTaskViewers タスクを閲覧中の購読者（通知したユーザー本人を除く）にのみ通知する
+func (s *RealtimeService) publishToTaskViewers(event RealtimeEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subscribers {
+		if sub.UserID == event.UserID || sub.viewing == nil ||
+			sub.viewing.kind != PresenceTask || sub.viewing.id != event.TaskID {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			s.closeLocked(sub)
+		}
+	}
+}
@@ -0,0 +1,597 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// 失敗した配信を再試行する回数の上限（超えたらデッドレターにする）
+	webhookMaxAttempts = 8
+	// 再試行の間隔（失敗するたびに倍にする）
+	webhookRetryBase = 30 * time.Second
+	webhookRetryMax  = time.Hour
+	webhookTimeout   = 10 * time.Second
+	// 1回のジョブで配信する件数と、同時に配信する数
+	webhookBatchSize   = 100
+	webhookConcurrency = 8
+	// 配信中の記録を他のサーバーに取られないよう、次の試行日時を先に延ばしておく時間
+	webhookClaimLease = 2 * time.Minute
+)
+
+// WebhookEventNotification 作成者宛ての通知（チームのないWebhookの宛先に送る）
+const WebhookEventNotification = "notification"
+
+// webhookTeamEventTypes チームのWebhookの宛先に送るイベントの種類
+var webhookTeamEventTypes = []string{
+	"task.created", "task.updated", "task.deleted",
+	"event.created", "event.updated", "event.deleted",
+	"comment.created", "comment.updated", "comment.deleted",
+}
+
+// WebhookEndpointInput Webhookの宛先の登録（teamId を指定した場合はチームの変更、省略した場合は自分宛ての通知を送る）
+type WebhookEndpointInput struct {
+	URL        string   `json:"url" binding:"required,http_url"`
+	TeamID     *string  `json:"teamId"`
+	EventTypes []string `json:"eventTypes"` // 空はすべて
+	Active     *bool    `json:"active"`
+}
+
+// WebhookEndpointUpdateInput Webhookの宛先の変更（省略した項目は変更しない）
+type WebhookEndpointUpdateInput struct {
+	URL        *string  `json:"url" binding:"omitempty,http_url"`
+	EventTypes []string `json:"eventTypes"`
+	Active     *bool    `json:"active"`
+}
+
+// CreatedWebhookEndpoint 登録したWebhookの宛先（署名の鍵はこのときのみ返す）
+type CreatedWebhookEndpoint struct {
+	models.WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// webhookPayload 宛先に送る本文
+type webhookPayload struct {
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+type webhookTaskData struct {
+	ID          string            `json:"id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description,omitempty"`
+	Status      models.TaskStatus `json:"status,omitempty"`
+	Priority    models.Priority   `json:"priority,omitempty"`
+	DueDate     *time.Time        `json:"dueDate,omitempty"`
+	TeamID      string            `json:"teamId"`
+	CreatorID   string            `json:"creatorId,omitempty"`
+	AssigneeID  *string           `json:"assigneeId,omitempty"`
+	UpdatedAt   *time.Time        `json:"updatedAt,omitempty"`
+}
+
+type webhookEventData struct {
+	ID        string             `json:"id"`
+	Title     string             `json:"title"`
+	StartDate *time.Time         `json:"startDate,omitempty"`
+	EndDate   *time.Time         `json:"endDate,omitempty"`
+	AllDay    bool               `json:"allDay"`
+	Location  string             `json:"location,omitempty"`
+	Status    models.EventStatus `json:"status,omitempty"`
+	TeamID    string             `json:"teamId"`
+	CreatorID string             `json:"creatorId,omitempty"`
+	UpdatedAt *time.Time         `json:"updatedAt,omitempty"`
+}
+
+type webhookCommentData struct {
+	ID        string     `json:"id"`
+	TaskID    string     `json:"taskId"`
+	TeamID    string     `json:"teamId"`
+	AuthorID  string     `json:"authorId,omitempty"`
+	Content   string     `json:"content,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+}
+
+type webhookNotificationData struct {
+	UserID     string `json:"userId"`
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	EntityType string `json:"entityType,omitempty"`
+	EntityID   string `json:"entityId,omitempty"`
+}
+
+// WebhookService 登録された宛先に、チームの変更と通知をHMAC署名付きで送る
+//
+// 配信は記録してから Cron ジョブで送り、失敗した場合は間隔を倍にしながら webhookMaxAttempts 回まで再試行する。
+// 宛先は X-TaskCalendar-Signature（t=タイムスタンプ,v1=HMAC-SHA256("タイムスタンプ.本文") の16進）で送信元を検証できる。
+// 内部ネットワークのアドレス（ループバック・プライベート・メタデータなど）には登録も送信もしない。
+type WebhookService struct {
+	db     *gorm.DB
+	client *http.Client
+}
+
+func NewWebhookService(db *gorm.DB) *WebhookService {
+	return &WebhookService{db: db, client: newExternalClient(webhookTimeout)}
+}
+
+// ListEndpoints Webhookの宛先の一覧（teamID が空の場合は自分宛ての通知の宛先、指定した場合はチームの宛先で管理者のみ）
+func (s *WebhookService) ListEndpoints(userID, teamID string, sort SortOptions) ([]models.WebhookEndpoint, error) {
+	query := orderBy(s.db, sort, "created_at ASC")
+	if teamID == "" {
+		query = query.Where("creator_id = ? AND team_id IS NULL", userID)
+	} else {
+		if err := s.ensureTeamAdmin(teamID, userID); err != nil {
+			return nil, err
+		}
+		query = query.Where("team_id = ?", teamID)
+	}
+	var endpoints []models.WebhookEndpoint
+	if err := query.Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// CreateEndpoint Webhookの宛先を登録する（チームの宛先は管理者のみ）
+func (s *WebhookService) CreateEndpoint(userID string, input WebhookEndpointInput) (*CreatedWebhookEndpoint, error) {
+	endpoint := models.WebhookEndpoint{CreatorID: userID, Active: true}
+	if input.TeamID != nil && *input.TeamID != "" {
+		if err := s.ensureTeamAdmin(*input.TeamID, userID); err != nil {
+			return nil, err
+		}
+		endpoint.TeamID = input.TeamID
+	}
+	if err := s.applyInput(&endpoint, &input.URL, input.EventTypes, input.Active); err != nil {
+		return nil, err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = secret
+	if err := s.db.Create(&endpoint).Error; err != nil {
+		return nil, err
+	}
+	return &CreatedWebhookEndpoint{WebhookEndpoint: endpoint, Secret: secret}, nil
+}
+
+// UpdateEndpoint Webhookの宛先を変更する
+func (s *WebhookService) UpdateEndpoint(endpointID, userID string, input WebhookEndpointUpdateInput) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.findEditableEndpoint(endpointID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyInput(endpoint, input.URL, input.EventTypes, input.Active); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(endpoint).Error; err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// DeleteEndpoint Webhookの宛先を削除する（配信の記録も削除する）
+func (s *WebhookService) DeleteEndpoint(endpointID, userID string) error {
+	endpoint, err := s.findEditableEndpoint(endpointID, userID)
+	if err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		deliveryIDs := tx.Model(&models.WebhookDelivery{}).Select("id").Where("endpoint_id = ?", endpoint.ID)
+		if err := tx.Where("delivery_id IN (?)", deliveryIDs).Delete(&models.WebhookDeliveryAttempt{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("endpoint_id = ?", endpoint.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(endpoint).Error
+	})
+}
+
+// findEditableEndpoint 変更できるWebhookの宛先（自分宛ての宛先は作成者、チームの宛先は管理者のみ）
+func (s *WebhookService) findEditableEndpoint(endpointID, userID string) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	if err := s.db.First(&endpoint, "id = ?", endpointID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if endpoint.TeamID == nil {
+		if endpoint.CreatorID != userID {
+			return nil, ErrNotFound
+		}
+		return &endpoint, nil
+	}
+	if err := s.ensureTeamAdmin(*endpoint.TeamID, userID); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (s *WebhookService) ensureTeamAdmin(teamID, userID string) error {
+	admin, err := isTeamAdmin(s.db, teamID, userID)
+	if err != nil {
+		return err
+	}
+	if !admin {
+		return fmt.Errorf("%w: チームのWebhookはチームの管理者のみ設定できます", ErrForbidden)
+	}
+	return nil
+}
+
+func (s *WebhookService) applyInput(endpoint *models.WebhookEndpoint, rawURL *string, eventTypes []string, active *bool) error {
+	if rawURL != nil {
+		u, err := url.Parse(strings.TrimSpace(*rawURL))
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("%w: urlはhttpまたはhttpsのURLで指定してください", ErrInvalidInput)
+		}
+		if err := checkExternalHost(u); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		endpoint.URL = u.String()
+	}
+	if eventTypes != nil {
+		allowed := []string{WebhookEventNotification}
+		if endpoint.TeamID != nil {
+			allowed = webhookTeamEventTypes
+		}
+		types := make([]string, 0, len(eventTypes))
+		for _, t := range eventTypes {
+			if !containsString(allowed, t) {
+				return fmt.Errorf("%w: eventTypesに %s は指定できません（%s）", ErrInvalidInput, t, strings.Join(allowed, ", "))
+			}
+			if !containsString(types, t) {
+				types = append(types, t)
+			}
+		}
+		endpoint.EventTypes = types
+	}
+	if active != nil {
+		endpoint.Active = *active
+	}
+	return nil
+}
+
+// Notify 通知を、ユーザーが登録した自分宛ての宛先に送る（通知の配信先のWebhookチャネル）
+func (s *WebhookService) Notify(msg NotificationMessage) error {
+	var endpoints []models.WebhookEndpoint
+	if err := s.db.Where("creator_id = ? AND team_id IS NULL AND active = ?", msg.UserID, true).
+		Find(&endpoints).Error; err != nil {
+		return err
+	}
+	return s.enqueue(s.db, endpoints, WebhookEventNotification, webhookNotificationData{
+		UserID:     msg.UserID,
+		Type:       msg.Type,
+		Title:      msg.Title,
+		Body:       msg.Body,
+		EntityType: msg.EntityType,
+		EntityID:   msg.EntityID,
+	})
+}
+
+// enqueue 宛先のうちイベントの種類を受け取るものに、配信を記録する
+func (s *WebhookService) enqueue(db *gorm.DB, endpoints []models.WebhookEndpoint, eventType string, data interface{}) error {
+	now := time.Now()
+	payload, err := json.Marshal(webhookPayload{Type: eventType, CreatedAt: now, Data: data})
+	if err != nil {
+		return err
+	}
+	var deliveries []models.WebhookDelivery
+	for _, endpoint := range endpoints {
+		if len(endpoint.EventTypes) > 0 && !containsString(endpoint.EventTypes, eventType) {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			EndpointID:    endpoint.ID,
+			EventType:     eventType,
+			Payload:       string(payload),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return db.Create(&deliveries).Error
+}
+
+// RegisterCallbacks タスク・イベント・コメントの作成・更新・削除を、チームの宛先への配信として記録するgormのコールバックを登録する
+//
+// 配信は変更と同じトランザクションで記録するため、ロールバックされた変更は送らない。
+// IDの分からない一括更新・削除と、非公開のイベントは送らない。
+func (s *WebhookService) RegisterCallbacks() error {
+	callbacks := s.db.Callback()
+	if err := callbacks.Create().After("gorm:create").Register("webhook:after_create", s.recordChanges("created")); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("webhook:after_update", s.recordChanges("updated")); err != nil {
+		return err
+	}
+	return callbacks.Delete().After("gorm:delete").Register("webhook:after_delete", s.recordChanges("deleted"))
+}
+
+func (s *WebhookService) recordChanges(action string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx.Error != nil || tx.RowsAffected == 0 || !tx.Statement.ReflectValue.IsValid() {
+			return
+		}
+		db := tx.Session(&gorm.Session{NewDB: true})
+		value := reflect.Indirect(tx.Statement.ReflectValue)
+		switch value.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < value.Len(); i++ {
+				s.recordChange(db, action, reflect.Indirect(value.Index(i)))
+			}
+		case reflect.Struct:
+			s.recordChange(db, action, value)
+		}
+	}
+}
+
+func (s *WebhookService) recordChange(db *gorm.DB, action string, value reflect.Value) {
+	if value.Kind() != reflect.Struct || !value.CanInterface() {
+		return
+	}
+	var (
+		teamID, eventType string
+		data              interface{}
+	)
+	switch record := value.Interface().(type) {
+	case models.Task:
+		if record.ID == "" {
+			return
+		}
+		// 更新は変更した列しか持たない場合があるため、保存後の値を読み直す
+		if action != "deleted" {
+			if err := db.First(&record, "id = ?", record.ID).Error; err != nil {
+				return
+			}
+		}
+		teamID, eventType = record.TeamID, "task."+action
+		data = webhookTask(&record, action)
+	case models.Event:
+		if record.ID == "" {
+			return
+		}
+		if action != "deleted" {
+			if err := db.First(&record, "id = ?", record.ID).Error; err != nil {
+				return
+			}
+		}
+		if record.TeamID == nil || record.Visibility == models.EventVisibilityPrivate {
+			return
+		}
+		teamID, eventType = *record.TeamID, "event."+action
+		data = webhookEvent(&record, action)
+	case models.Comment:
+		if record.ID == "" {
+			return
+		}
+		if action != "deleted" {
+			if err := db.First(&record, "id = ?", record.ID).Error; err != nil || record.IsHidden {
+				return
+			}
+		}
+		var teamIDs []string
+		if record.TaskID == "" || db.Model(&models.Task{}).Where("id = ?", record.TaskID).
+			Pluck("team_id", &teamIDs).Error != nil || len(teamIDs) == 0 {
+			return
+		}
+		teamID, eventType = teamIDs[0], "comment."+action
+		data = webhookComment(&record, teamID, action)
+	default:
+		return
+	}
+	if teamID == "" {
+		return
+	}
+
+	var endpoints []models.WebhookEndpoint
+	if err := db.Where("team_id = ? AND active = ?", teamID, true).Find(&endpoints).Error; err != nil {
+		log.Printf("Webhookの宛先の取得に失敗しました: %v", err)
+		return
+	}
+	if err := s.enqueue(db, endpoints, eventType, data); err != nil {
+		log.Printf("Webhookの配信の記録に失敗しました（%s）: %v", eventType, err)
+	}
+}
+
+func webhookTask(task *models.Task, action string) webhookTaskData {
+	data := webhookTaskData{ID: task.ID, Title: task.Title, TeamID: task.TeamID}
+	if action != "deleted" {
+		data.Description = task.Description
+		data.Status = task.Status
+		data.Priority = task.Priority
+		data.DueDate = task.DueDate
+		data.CreatorID = task.CreatorID
+		data.AssigneeID = task.AssigneeID
+		data.UpdatedAt = &task.UpdatedAt
+	}
+	return data
+}
+
+func webhookEvent(event *models.Event, action string) webhookEventData {
+	data := webhookEventData{ID: event.ID, Title: event.Title, TeamID: *event.TeamID}
+	if action != "deleted" {
+		data.StartDate = &event.StartDate
+		data.EndDate = &event.EndDate
+		data.AllDay = event.AllDay
+		data.Location = event.Location
+		data.Status = event.Status
+		data.CreatorID = event.CreatorID
+		data.UpdatedAt = &event.UpdatedAt
+	}
+	return data
+}
+
+func webhookComment(comment *models.Comment, teamID, action string) webhookCommentData {
+	data := webhookCommentData{ID: comment.ID, TaskID: comment.TaskID, TeamID: teamID}
+	if action != "deleted" {
+		data.AuthorID = comment.AuthorID
+		data.Content = comment.Content
+		data.CreatedAt = &comment.CreatedAt
+	}
+	return data
+}
+
+// DeliverPending 送る時刻を迎えた配信を送る（Cronジョブ用）
+//
+// 複数のサーバーで実行しても二重に送らないよう、次の試行日時を先に延ばせた配信のみ送る。
+func (s *WebhookService) DeliverPending() error {
+	now := time.Now()
+	var deliveries []models.WebhookDelivery
+	if err := s.db.Preload("Endpoint").
+		Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+		Where("endpoint_id IN (?)", s.db.Model(&models.WebhookEndpoint{}).Select("id").Where("active = ?", true)).
+		Order("next_attempt_at ASC").Limit(webhookBatchSize).Find(&deliveries).Error; err != nil {
+		return err
+	}
+
+	sem := make(chan struct{}, webhookConcurrency)
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		result := s.db.Model(&models.WebhookDelivery{}).
+			Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, models.WebhookDeliveryPending, delivery.NextAttemptAt).
+			UpdateColumn("next_attempt_at", now.Add(webhookClaimLease))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			if err := s.attempt(delivery); err != nil {
+				log.Printf("Webhookの配信結果の記録に失敗しました（%s）: %v", delivery.ID, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// attempt 配信を1回試み、試行の結果と配信の状態を記録する（失敗した場合は再試行の日時を決め、上限に達したらデッドレターにする）
+func (s *WebhookService) attempt(delivery *models.WebhookDelivery) error {
+	started := time.Now()
+	statusCode, err := s.send(&delivery.Endpoint, delivery)
+	now := time.Now()
+	record := models.WebhookDeliveryAttempt{
+		DeliveryID: delivery.ID,
+		StatusCode: statusCode,
+		LatencyMs:  now.Sub(started).Milliseconds(),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if err := s.db.Create(&record).Error; err != nil {
+		return err
+	}
+
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = ""
+	switch {
+	case err == nil:
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Status = models.WebhookDeliveryDead
+		delivery.LastError = err.Error()
+	default:
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = now.Add(webhookRetryDelay(delivery.Attempts))
+	}
+	return s.db.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(map[string]interface{}{
+		"status":           delivery.Status,
+		"attempts":         delivery.Attempts,
+		"last_status_code": delivery.LastStatusCode,
+		"last_error":       delivery.LastError,
+		"delivered_at":     delivery.DeliveredAt,
+		"next_attempt_at":  delivery.NextAttemptAt,
+	}).Error
+}
+
+// send 署名を付けて送り、応答のステータスを返す（2xx以外の応答はエラー）
+//
+// 応答の本文は記録しない（宛先が返した内容を配信の記録として利用者に見せないため）。
+func (s *WebhookService) send(endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequest(http.MethodPost, endpoint.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TaskCalendar-Webhook/1.0")
+	req.Header.Set("X-TaskCalendar-Event", delivery.EventType)
+	req.Header.Set("X-TaskCalendar-Delivery", delivery.ID)
+	req.Header.Set("X-TaskCalendar-Signature", "t="+timestamp+",v1="+signWebhookPayload(endpoint.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("ステータス %d が返りました", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// signWebhookPayload "タイムスタンプ.本文" のHMAC-SHA256（16進）
+func signWebhookPayload(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookRetryDelay attempts 回失敗した後、次に試すまでの間隔
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := webhookRetryBase
+	for i := 1; i < attempts && delay < webhookRetryMax; i++ {
+		delay *= 2
+	}
+	if delay > webhookRetryMax {
+		delay = webhookRetryMax
+	}
+	return delay
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// WebhookDeliveryListOptions 配信の一覧の取得条件
+type WebhookDeliveryListOptions struct {
+	PageOptions
+	Status models.WebhookDeliveryStatus // 空はすべて
+}
+
+// WebhookDeliveryPage 配信の一覧の1ページ分（新しい順）
+type WebhookDeliveryPage struct {
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+	NextCursor *string                  `json:"nextCursor"`
+	Total      *int64                   `json:"total,omitempty"` // includeTotal を指定した場合のみ
+}
+
+// ListDeliveries Webhookの宛先への配信の一覧（送った内容と最後の試行の結果を含む）
+func (s *WebhookService) ListDeliveries(endpointID, userID string, opts WebhookDeliveryListOptions) (*WebhookDeliveryPage, error) {
+	endpoint, err := s.findEditableEndpoint(endpointID, userID)
+	if err != nil {
+		return nil, err
+	}
+	query := s.db.Model(&models.WebhookDelivery{}).Where("endpoint_id = ?", endpoint.ID)
+	switch opts.Status {
+	case "":
+	case models.WebhookDeliveryPending, models.WebhookDeliverySucceeded, models.WebhookDeliveryDead:
+		query = query.Where("status = ?", opts.Status)
+	default:
+		return nil, fmt.Errorf("%w: statusはPENDING・SUCCEEDED・DEADのいずれかで指定してください", ErrInvalidInput)
+	}
+	total, err := countTotal(query, opts.PageOptions)
+	if err != nil {
+		return nil, err
+	}
+	query, limit, err := paginate(query, opts.PageOptions, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	page := &WebhookDeliveryPage{Total: total}
+	page.Deliveries, page.NextCursor = pageOf(deliveries, limit, func(d *models.WebhookDelivery) (time.Time, string) {
+		return d.CreatedAt, d.ID
+	})
+	return page, nil
+}
+
+// GetDelivery 配信と、試行ごとの結果（ステータスコード・所要時間・応答）
+func (s *WebhookService) GetDelivery(endpointID, deliveryID, userID string) (*models.WebhookDelivery, error) {
+	endpoint, err := s.findEditableEndpoint(endpointID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var delivery models.WebhookDelivery
+	if err := s.db.Preload("History", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Where("id = ? AND endpoint_id = ?", deliveryID, endpoint.ID).First(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ReplayDelivery 配信と同じ内容を、新しい配信としてすぐに送り直す（元の配信の記録はそのまま残す）
+func (s *WebhookService) ReplayDelivery(endpointID, deliveryID, userID string) (*models.WebhookDelivery, error) {
+	original, err := s.GetDelivery(endpointID, deliveryID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var endpoint models.WebhookEndpoint
+	if err := s.db.Select("id", "active").First(&endpoint, "id = ?", original.EndpointID).Error; err != nil {
+		return nil, err
+	}
+	if !endpoint.Active {
+		return nil, fmt.Errorf("%w: 無効にしたWebhookには再送できません", ErrInvalidInput)
+	}
+
+	replay := models.WebhookDelivery{
+		EndpointID:    original.EndpointID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+		ReplayOfID:    &original.ID,
+	}
+	if err := s.db.Create(&replay).Error; err != nil {
+		return nil, err
+	}
+	return &replay, nil
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"task-calendar-backend/internal/models"
+)
+
+// 内部ネットワークのアドレスを宛先に登録できない
+func TestWebhookRejectsInternalEndpoints(t *testing.T) {
+	s := &WebhookService{}
+	for _, rawURL := range []string{
+		"http://127.0.0.1/hook",
+		"http://localhost:8080/hook",
+		"http://api.localhost/hook",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		endpoint := &models.WebhookEndpoint{}
+		if err := s.applyInput(endpoint, &rawURL, nil, nil); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: エラーが %v です（ErrInvalidInput のはず）", rawURL, err)
+		}
+	}
+
+	rawURL := "https://hooks.example.com/taskcalendar"
+	endpoint := &models.WebhookEndpoint{}
+	if err := s.applyInput(endpoint, &rawURL, nil, nil); err != nil {
+		t.Fatalf("外部のURLを登録できません: %v", err)
+	}
+	if endpoint.URL != rawURL {
+		t.Errorf("URLが %s です", endpoint.URL)
+	}
+}
+
+// 登録済みの宛先が内部のアドレスを指している場合（DNSの向き先の変更など）も送信しない
+func TestWebhookSendRefusesLoopback(t *testing.T) {
+	received := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	defer server.Close()
+
+	s := NewWebhookService(nil)
+	endpoint := &models.WebhookEndpoint{URL: server.URL, Secret: "secret"}
+	delivery := &models.WebhookDelivery{ID: "d1", EventType: WebhookEventNotification, Payload: "{}"}
+	statusCode, err := s.send(endpoint, delivery)
+	if !errors.Is(err, errPrivateAddress) {
+		t.Errorf("エラーが %v です（errPrivateAddress のはず）", err)
+	}
+	if statusCode != 0 || received {
+		t.Error("ループバックのアドレスに送信しました")
+	}
+}
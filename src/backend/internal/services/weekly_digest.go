@@ -0,0 +1,231 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"task-calendar-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// 週次ダイジェストを送る曜日と時刻（User.TimeZone の月曜 8:00）
+	weeklyDigestWeekday = time.Monday
+	weeklyDigestMinute  = 8 * 60
+	// サーバー停止中などで送れなかった週次ダイジェストを後から送る猶予
+	weeklyDigestCatchUp = 12 * time.Hour
+	// チームごとに一覧に載せるタスクの数
+	weeklyDigestTaskLimit = 10
+)
+
+// WeeklyDigestInput 週次ダイジェストの設定
+type WeeklyDigestInput struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// WeeklyDigestSettings 週次ダイジェストの設定
+type WeeklyDigestSettings struct {
+	Enabled  bool   `json:"enabled"`
+	TimeZone string `json:"timeZone"` // 送信する曜日・時刻の基準
+}
+
+// WeeklyDigestService 毎週月曜に、先週の所属チームのタスクの状況と今週の予定をメールで送る（配信の停止はユーザーごと）
+type WeeklyDigestService struct {
+	db            *gorm.DB
+	reportService *ReportService
+	agendaService *AgendaService
+	mailer        Mailer
+}
+
+func NewWeeklyDigestService(db *gorm.DB, reportService *ReportService, agendaService *AgendaService, mailer Mailer) *WeeklyDigestService {
+	return &WeeklyDigestService{db: db, reportService: reportService, agendaService: agendaService, mailer: mailer}
+}
+
+// GetSettings 週次ダイジェストの設定を取得（未設定の場合は有効）
+func (s *WeeklyDigestService) GetSettings(userID string) (*WeeklyDigestSettings, error) {
+	var user models.User
+	if err := s.db.Select("id", "time_zone").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	var setting models.WeeklyDigestSetting
+	if err := s.db.Where("user_id = ?", userID).Limit(1).Find(&setting).Error; err != nil {
+		return nil, err
+	}
+	return &WeeklyDigestSettings{Enabled: !setting.OptedOut, TimeZone: user.TimeZone}, nil
+}
+
+// UpdateSettings 週次ダイジェストの配信を停止・再開する
+func (s *WeeklyDigestService) UpdateSettings(userID string, input WeeklyDigestInput) (*WeeklyDigestSettings, error) {
+	setting := models.WeeklyDigestSetting{UserID: userID, OptedOut: !*input.Enabled}
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"opted_out", "updated_at"}),
+	}).Create(&setting).Error; err != nil {
+		return nil, err
+	}
+	return s.GetSettings(userID)
+}
+
+// SendWeeklyDigests 送信時刻を迎えたユーザーに週次ダイジェストを送る（Cronジョブ用）
+//
+// 週はユーザーのタイムゾーンのISO週で判断し、送った週を記録して1週1通にする。
+// 送信時刻から weeklyDigestCatchUp を過ぎた場合と、載せる内容がない週は送らない。
+// ただし since（前回成功した実行の日時）が送信時刻より前の場合は、停止中に送信時刻を迎えたとみなしてその週のうちは送る。
+func (s *WeeklyDigestService) SendWeeklyDigests(since time.Time) error {
+	var users []models.User
+	if err := s.db.Where("email <> '' AND id NOT IN (?)",
+		s.db.Model(&models.WeeklyDigestSetting{}).Select("user_id").Where("opted_out = ?", true)).
+		Find(&users).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range users {
+		user := &users[i]
+		loc, err := models.LoadLocation(user.TimeZone)
+		if err != nil {
+			loc = time.UTC
+		}
+		local := now.In(loc)
+		daysSince := (int(local.Weekday()) - int(weeklyDigestWeekday) + 7) % 7
+		weekStart := time.Date(local.Year(), local.Month(), local.Day()-daysSince, 0, 0, 0, 0, loc)
+		sendAt := weekStart.Add(weeklyDigestMinute * time.Minute)
+		missed := !since.IsZero() && since.Before(sendAt)
+		if now.Before(sendAt) || (now.After(sendAt.Add(weeklyDigestCatchUp)) && !missed) {
+			continue
+		}
+		year, week := weekStart.ISOWeek()
+		weekKey := fmt.Sprintf("%04d-W%02d", year, week)
+
+		// 送った週を記録できた場合のみ送る（複数のサーバーで実行しても二重に送らない）
+		if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.WeeklyDigestSetting{UserID: user.ID}).Error; err != nil {
+			return err
+		}
+		result := s.db.Model(&models.WeeklyDigestSetting{}).
+			Where("user_id = ? AND opted_out = ? AND (last_sent_week IS NULL OR last_sent_week <> ?)", user.ID, false, weekKey).
+			UpdateColumn("last_sent_week", weekKey)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		if err := s.send(user, loc, weekStart); err != nil {
+			log.Printf("週次ダイジェストの送信に失敗しました（%s）: %v", user.Email, err)
+		}
+	}
+	return nil
+}
+
+// weeklyDigestView 週次ダイジェストのテンプレートに渡す内容
+type weeklyDigestView struct {
+	Name     string
+	LastWeek string
+	ThisWeek string
+	Teams    []weeklyDigestTeam
+	Events   []dailyAgendaLine
+}
+
+type weeklyDigestTeam struct {
+	Name           string
+	Created        int64
+	Completed      int64
+	CompletedTasks []dailyAgendaLine
+	MoreCompleted  int64
+	Overdue        int64
+	OverdueTasks   []dailyAgendaLine
+	MoreOverdue    int64
+}
+
+func (s *WeeklyDigestService) send(user *models.User, loc *time.Location, weekStart time.Time) error {
+	lastWeek := weekStart.AddDate(0, 0, -7)
+	nextWeek := weekStart.AddDate(0, 0, 7)
+	view := weeklyDigestView{
+		Name:     userDisplayName(user),
+		LastWeek: formatDigestPeriod(lastWeek, weekStart),
+		ThisWeek: formatDigestPeriod(weekStart, nextWeek),
+	}
+
+	var teamIDs []string
+	if err := s.db.Model(&models.TeamMember{}).
+		Where("user_id = ? AND status = ?", user.ID, models.TeamMemberStatusActive).
+		Order("joined_at ASC").Pluck("team_id", &teamIDs).Error; err != nil {
+		return err
+	}
+	hasContent := false
+	for _, teamID := range teamIDs {
+		summary, err := s.reportService.TeamSummary(teamID, lastWeek, weekStart, weeklyDigestTaskLimit)
+		if err != nil {
+			return err
+		}
+		team := weeklyDigestTeam{
+			Name:          summary.TeamName,
+			Created:       summary.CreatedCount,
+			Completed:     summary.CompletedCount,
+			MoreCompleted: summary.CompletedCount - int64(len(summary.CompletedTasks)),
+			Overdue:       summary.OverdueCount,
+			MoreOverdue:   summary.OverdueCount - int64(len(summary.OverdueTasks)),
+		}
+		for i := range summary.CompletedTasks {
+			task := &summary.CompletedTasks[i]
+			team.CompletedTasks = append(team.CompletedTasks, dailyAgendaLine{Title: task.Title, Detail: digestAssignee(task)})
+		}
+		for i := range summary.OverdueTasks {
+			task := &summary.OverdueTasks[i]
+			team.OverdueTasks = append(team.OverdueTasks, dailyAgendaLine{
+				When:   task.DueDate.In(loc).Format("01/02") + "まで",
+				Title:  task.Title,
+				Detail: digestAssignee(task),
+			})
+		}
+		if team.Created+team.Completed+team.Overdue > 0 {
+			hasContent = true
+		}
+		view.Teams = append(view.Teams, team)
+	}
+
+	items, err := s.agendaService.GetAgenda(user.ID, weekStart, nextWeek, OccurrenceFilter{})
+	if err != nil {
+		return err
+	}
+	for _, item := range LocalizeAgenda(items, loc) {
+		if item.Kind != AgendaItemEvent {
+			continue
+		}
+		when := item.StartDate.Format("01/02") + "（" + weekdayLabels[item.StartDate.Weekday()] + "）"
+		if item.AllDay {
+			when += " 終日"
+		} else {
+			when += " " + item.StartDate.Format("15:04")
+		}
+		view.Events = append(view.Events, dailyAgendaLine{When: when, Title: item.Title})
+	}
+	if !hasContent && len(view.Events) == 0 {
+		return nil
+	}
+
+	msg, err := renderEmail(EmailTemplateWeeklyDigest, view)
+	if err != nil {
+		return err
+	}
+	msg.To = user.Email
+	return s.mailer.Send(msg)
+}
+
+// formatDigestPeriod [from, to) の期間の表示（to は含まないため前日までにする）
+func formatDigestPeriod(from, to time.Time) string {
+	last := to.AddDate(0, 0, -1)
+	return from.Format("01/02") + "（" + weekdayLabels[from.Weekday()] + "）〜" +
+		last.Format("01/02") + "（" + weekdayLabels[last.Weekday()] + "）"
+}
+
+func digestAssignee(task *models.Task) string {
+	if task.Assignee == nil {
+		return ""
+	}
+	return "担当: " + userDisplayName(task.Assignee)
+}
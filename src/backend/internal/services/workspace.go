@@ -0,0 +1,136 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"task-calendar-backend/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var workspaceSlugPattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{0,62}[a-z0-9])?$`)
+
+// inWorkspaceOf userID のユーザーと同じワークスペースの行に絞り込む（ワークスペースを持つユーザー・チーム・タスク・イベントのクエリに使う）
+//
+// 認証したユーザーのIDから決めるため、別の組織のデータをIDで指定されても見つからないものとして扱える。
+func inWorkspaceOf(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		workspace := db.Session(&gorm.Session{NewDB: true}).Model(&models.User{}).
+			Select("workspace_id").Where("id = ?", userID)
+		return db.Where(clause.Expr{SQL: "? = (?)", Vars: []interface{}{
+			clause.Column{Table: clause.CurrentTable, Name: "workspace_id"}, workspace,
+		}})
+	}
+}
+
+// WorkspaceInput ワークスペースの作成（最初の管理者のユーザーもあわせて作成する）
+type WorkspaceInput struct {
+	Name  string
+	Slug  string
+	Owner WorkspaceOwnerInput
+}
+
+// WorkspaceOwnerInput ワークスペースの最初の管理者（ADMIN）
+type WorkspaceOwnerInput struct {
+	Email     string
+	Username  string
+	Password  string
+	FirstName string
+	LastName  string
+}
+
+// ProvisionedWorkspace 作成したワークスペースと管理者
+type ProvisionedWorkspace struct {
+	Workspace models.Workspace `json:"workspace"`
+	Owner     models.User      `json:"owner"`
+}
+
+// WorkspaceService ワークスペース（テナント）の作成・一覧（ホスティングする運営者向け）
+type WorkspaceService struct {
+	db *gorm.DB
+}
+
+func NewWorkspaceService(db *gorm.DB) *WorkspaceService {
+	return &WorkspaceService{db: db}
+}
+
+// CreateWorkspace ワークスペースと、その管理者のユーザーを作成する
+func (s *WorkspaceService) CreateWorkspace(input WorkspaceInput) (*ProvisionedWorkspace, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: nameは必須です", ErrInvalidInput)
+	}
+	slug := strings.ToLower(strings.TrimSpace(input.Slug))
+	if !workspaceSlugPattern.MatchString(slug) {
+		return nil, fmt.Errorf("%w: slugは英小文字・数字・ハイフンの64文字以内で指定してください", ErrInvalidInput)
+	}
+	if len(input.Owner.Password) < minPasswordLength {
+		return nil, fmt.Errorf("%w: パスワードは%d文字以上で指定してください", ErrInvalidInput, minPasswordLength)
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(input.Owner.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProvisionedWorkspace{Workspace: models.Workspace{Name: name, Slug: slug}}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.Workspace{}).Where("slug = ?", slug).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: slug %s は使われています", ErrInvalidInput, slug)
+		}
+		if err := tx.Model(&models.User{}).Where("email = ? OR username = ?", input.Owner.Email, input.Owner.Username).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: メールアドレスまたはユーザー名は使われています", ErrInvalidInput)
+		}
+
+		if err := tx.Create(&result.Workspace).Error; err != nil {
+			return err
+		}
+		result.Owner = models.User{
+			WorkspaceID: result.Workspace.ID,
+			Email:       input.Owner.Email,
+			Username:    input.Owner.Username,
+			Password:    string(hashed),
+			FirstName:   input.Owner.FirstName,
+			LastName:    input.Owner.LastName,
+			Role:        models.UserRoleAdmin,
+		}
+		return tx.Create(&result.Owner).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListWorkspaces すべてのワークスペース（作成した順）
+func (s *WorkspaceService) ListWorkspaces() ([]models.Workspace, error) {
+	var workspaces []models.Workspace
+	if err := s.db.Order("created_at ASC").Find(&workspaces).Error; err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+// GetWorkspace ワークスペース
+func (s *WorkspaceService) GetWorkspace(id string) (*models.Workspace, error) {
+	var workspace models.Workspace
+	if err := s.db.First(&workspace, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &workspace, nil
+}
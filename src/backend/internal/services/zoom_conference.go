@@ -0,0 +1,177 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"task-calendar-backend/internal/models"
+)
+
+const (
+	zoomAPIURL   = "https://api.zoom.us/v2"
+	zoomTokenURL = "https://zoom.us/oauth/token"
+)
+
+// ZoomConferenceProvider Server-to-Server OAuth アプリによる Zoom ミーティングの作成
+type ZoomConferenceProvider struct {
+	accountID    string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+func NewZoomConferenceProvider(accountID, clientID, clientSecret string) *ZoomConferenceProvider {
+	return &ZoomConferenceProvider{
+		accountID:    accountID,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		client:       &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *ZoomConferenceProvider) Provider() models.ConferenceProvider {
+	return models.ConferenceProviderZoom
+}
+
+// zoomMeeting ミーティングの作成・更新内容（typeは2が日時指定、3が日時を固定しない繰り返し）
+type zoomMeeting struct {
+	Topic     string `json:"topic"`
+	Type      int    `json:"type"`
+	StartTime string `json:"start_time,omitempty"`
+	Duration  int    `json:"duration,omitempty"`
+	Timezone  string `json:"timezone"`
+}
+
+func newZoomMeeting(req ConferenceRequest) zoomMeeting {
+	m := zoomMeeting{Topic: req.Title, Type: 2, Timezone: req.TimeZone}
+	if req.Recurring {
+		// 繰り返しイベントは全ての回で同じリンクを使う
+		m.Type = 3
+		return m
+	}
+	m.StartTime = req.StartDate.UTC().Format("2006-01-02T15:04:05Z")
+	m.Duration = int(req.EndDate.Sub(req.StartDate).Minutes())
+	if m.Duration < 1 {
+		m.Duration = 1
+	}
+	return m
+}
+
+func (p *ZoomConferenceProvider) CreateMeeting(ctx context.Context, req ConferenceRequest) (*Conference, error) {
+	var created struct {
+		ID      int64  `json:"id"`
+		JoinURL string `json:"join_url"`
+	}
+	if err := p.do(ctx, http.MethodPost, "/users/me/meetings", newZoomMeeting(req), &created); err != nil {
+		return nil, err
+	}
+	return &Conference{ID: strconv.FormatInt(created.ID, 10), JoinURL: created.JoinURL}, nil
+}
+
+func (p *ZoomConferenceProvider) UpdateMeeting(ctx context.Context, meetingID string, req ConferenceRequest) error {
+	return p.do(ctx, http.MethodPatch, "/meetings/"+url.PathEscape(meetingID), newZoomMeeting(req), nil)
+}
+
+func (p *ZoomConferenceProvider) DeleteMeeting(ctx context.Context, meetingID string) error {
+	err := p.do(ctx, http.MethodDelete, "/meetings/"+url.PathEscape(meetingID), nil, nil)
+	if errors.Is(err, errZoomNotFound) {
+		// 既にZoom側で削除されている
+		return nil
+	}
+	return err
+}
+
+var errZoomNotFound = errors.New("Zoomのミーティングが見つかりません")
+
+func (p *ZoomConferenceProvider) do(ctx context.Context, method, path string, body, out interface{}) error {
+	token, err := p.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, zoomAPIURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errZoomNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&apiErr)
+		return fmt.Errorf("Zoom APIがエラーを返しました（HTTP %d）: %s", resp.StatusCode, apiErr.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+// token アカウントの認証情報でアクセストークンを取得する（有効期限まで使い回す）
+func (p *ZoomConferenceProvider) token(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.accessToken != "" && time.Now().Before(p.expiresAt.Add(-time.Minute)) {
+		return p.accessToken, nil
+	}
+
+	query := url.Values{"grant_type": {"account_credentials"}, "account_id": {p.accountID}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, zoomTokenURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(p.clientID, p.clientSecret)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Reason      string `json:"reason"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("Zoomのトークンの応答を解析できません（HTTP %d）", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return "", fmt.Errorf("Zoomのトークンを取得できませんでした: %s", body.Reason)
+	}
+	p.accessToken = body.AccessToken
+	p.expiresAt = time.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	return p.accessToken, nil
+}
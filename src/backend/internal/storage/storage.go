@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidKey 保存先キーが不正な場合のエラー
+var ErrInvalidKey = errors.New("不正なストレージキーです")
+
+// Storage 添付ファイル等のバイナリ保存先
+type Storage interface {
+	Save(key string, r io.Reader) (int64, error)
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// LocalStorage ローカルディスクに保存するStorage実装
+type LocalStorage struct {
+	baseDir string
+}
+
+func NewLocalStorage(baseDir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{baseDir: baseDir}, nil
+}
+
+func (s *LocalStorage) Save(key string, r io.Reader) (int64, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return 0, err
+	}
+
+	f, err := os.Create(p)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(p)
+		return 0, err
+	}
+	return n, nil
+}
+
+func (s *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+func (s *LocalStorage) Delete(key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// path キーをベースディレクトリ配下のパスに変換する（ディレクトリトラバーサル対策）
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "..") {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.baseDir, filepath.FromSlash(clean)), nil
+}
+
+// NewKey プレフィックス付きのランダムなキーを生成する
+func NewKey(prefix string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return path.Join(prefix, hex.EncodeToString(b)), nil
+}
@@ -0,0 +1,146 @@
+// Package upload ファイルのアップロード（multipart/form-data をメモリにためずに読み出し、サイズと形式を確かめる）
+//
+// 読み出したファイルは、ストレージに書き込みながら上限を確かめる。上限を超えた時点で読み出しが ErrTooLarge になるため、
+// ストレージは書きかけのファイルを削除する。
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// multipartOverhead ファイル以外の部分（境界・ヘッダー・他の項目）に許す本文のサイズ
+const multipartOverhead = 1 << 20
+
+var (
+	ErrTooLarge        = errors.New("ファイルサイズが上限を超えています")
+	ErrUnsupportedType = errors.New("このファイル形式はアップロードできません")
+	ErrNoFile          = errors.New("fileが必要です")
+	ErrInvalidForm     = errors.New("multipart/form-dataの形式が不正です")
+)
+
+// Limits アップロードの制限
+type Limits struct {
+	MaxSize int64 // ファイルの最大サイズ（バイト）
+	// AllowedTypes 許可するファイル形式（内容から判定したMIMEタイプ。image/ のように / で終わるものは前方一致）。空はすべて
+	AllowedTypes []string
+}
+
+// File アップロードされたファイル（読み進めるとリクエストの本文から直接読み出す）
+type File struct {
+	Name        string // クライアントが指定したファイル名（ディレクトリを除く。本文をそのまま受け取った場合は空）
+	ContentType string // 先頭の内容から判定したMIMEタイプ
+	r           io.Reader
+}
+
+func (f *File) Read(p []byte) (int, error) {
+	return f.r.Read(p)
+}
+
+// FromRequest multipart/form-data の field のファイルを開く
+//
+// 本文は先頭から順に読み、field より前の項目は読み飛ばす（field より後の項目は読まない）。
+func FromRequest(w http.ResponseWriter, r *http.Request, field string, limits Limits) (*File, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, limits.MaxSize+multipartOverhead)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, ErrInvalidForm
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, ErrNoFile
+		}
+		if err != nil {
+			return nil, readError(err, ErrInvalidForm)
+		}
+		if part.FormName() != field || part.FileName() == "" {
+			continue
+		}
+		return Sniff(filepath.Base(part.FileName()), part, limits)
+	}
+}
+
+// FromBody 本文をそのままファイルとして開く（multipart/form-data 以外で送られたファイル向け）
+func FromBody(w http.ResponseWriter, r *http.Request, limits Limits) (*File, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, limits.MaxSize+1)
+	return Sniff("", r.Body, limits)
+}
+
+// Sniff 先頭の内容から形式を判定し、サイズの上限つきで読み出すファイルにする
+func Sniff(name string, r io.Reader, limits Limits) (*File, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, readError(err, err)
+	}
+	head = head[:n]
+	contentType := http.DetectContentType(head)
+	if !allowed(contentType, limits.AllowedTypes) {
+		return nil, ErrUnsupportedType
+	}
+	return &File{
+		Name:        name,
+		ContentType: contentType,
+		r:           &limitedReader{r: io.MultiReader(bytes.NewReader(head), r), remaining: limits.MaxSize},
+	}, nil
+}
+
+// IsTooLarge サイズの上限を超えたエラーか（本文全体の上限を超えた場合を含む）
+func IsTooLarge(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.Is(err, ErrTooLarge) || errors.As(err, &maxErr)
+}
+
+func readError(err, fallback error) error {
+	if IsTooLarge(err) {
+		return ErrTooLarge
+	}
+	return fallback
+}
+
+func allowed(contentType string, allowedTypes []string) bool {
+	if len(allowedTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range allowedTypes {
+		if mediaType == t || (strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t)) {
+			return true
+		}
+	}
+	return false
+}
+
+// limitedReader remaining バイトを超えて読み出そうとすると ErrTooLarge を返す
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrTooLarge
+	}
+	// 上限ちょうどのファイルと超えるファイルを見分けるため、1バイト多く読む
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, ErrTooLarge
+	}
+	if err != nil {
+		return n, readError(err, err)
+	}
+	return n, nil
+}
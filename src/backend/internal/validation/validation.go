@@ -0,0 +1,179 @@
+// Package validation リクエストの本文の検証（binding タグ）の設定と、検証エラーのメッセージ
+//
+// binding タグには validator の標準のルール（required・max・oneof・gtfield など）のほか、
+// notblank（空白のみの文字列を許さない）と color（#rgb・#rrggbb）を使える。
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"task-calendar-backend/internal/i18n"
+	"task-calendar-backend/internal/models"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Register gin のバリデーターに、JSONの項目名と独自のルールを設定する（ルーターの作成前に呼ぶ）
+func Register() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return fmt.Errorf("バリデーターを設定できません")
+	}
+	// 検証エラーの項目名を、Goのフィールド名ではなくJSONの項目名にする
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	rules := map[string]validator.Func{
+		"notblank": notBlank,
+		"color":    color,
+	}
+	for tag, fn := range rules {
+		if err := v.RegisterValidation(tag, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func notBlank(fl validator.FieldLevel) bool {
+	field := fl.Field()
+	if field.Kind() != reflect.String {
+		return true
+	}
+	return strings.TrimSpace(field.String()) != ""
+}
+
+func color(fl validator.FieldLevel) bool {
+	_, err := models.NormalizeColor(fl.Field().String())
+	return err == nil
+}
+
+// Message 検証エラーの項目ごとのメッセージ（lang の言語。英語以外は日本語）
+func Message(fe validator.FieldError, lang i18n.Lang) string {
+	if lang == i18n.English {
+		return englishMessage(fe)
+	}
+	field, param := fe.Field(), fe.Param()
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%sは必須です", field)
+	case "notblank":
+		return fmt.Sprintf("%sは空にできません", field)
+	case "max", "lte":
+		switch fe.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("%sは%s文字以内で指定してください", field, param)
+		case reflect.Slice, reflect.Map:
+			return fmt.Sprintf("%sは%s件以内で指定してください", field, param)
+		}
+		return fmt.Sprintf("%sは%s以下で指定してください", field, param)
+	case "min", "gte":
+		switch fe.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("%sは%s文字以上で指定してください", field, param)
+		case reflect.Slice, reflect.Map:
+			return fmt.Sprintf("%sは%s件以上で指定してください", field, param)
+		}
+		return fmt.Sprintf("%sは%s以上で指定してください", field, param)
+	case "gt":
+		return fmt.Sprintf("%sは%sより大きい値で指定してください", field, param)
+	case "gtfield":
+		return fmt.Sprintf("%sは%sより後にしてください", field, jsonFieldName(param))
+	case "gtefield":
+		return fmt.Sprintf("%sは%s以降にしてください", field, jsonFieldName(param))
+	case "nefield":
+		return fmt.Sprintf("%sは%sと異なる値にしてください", field, jsonFieldName(param))
+	case "oneof":
+		return fmt.Sprintf("%sは%sのいずれかで指定してください", field, strings.Join(strings.Fields(param), "・"))
+	case "email":
+		return fmt.Sprintf("%sはメールアドレスの形式で指定してください", field)
+	case "url", "http_url":
+		return fmt.Sprintf("%sはURLの形式で指定してください", field)
+	case "timezone":
+		return fmt.Sprintf("%sはIANAタイムゾーン（Asia/Tokyo など）で指定してください", field)
+	case "color":
+		return fmt.Sprintf("%sは#rrggbbの形式で指定してください", field)
+	case "datetime":
+		return fmt.Sprintf("%sは%sの形式で指定してください", field, datetimeLayout(param))
+	case "unique":
+		return fmt.Sprintf("%sに重複があります", field)
+	}
+	return fmt.Sprintf("%sが不正です", field)
+}
+
+func englishMessage(fe validator.FieldError) string {
+	field, param := fe.Field(), fe.Param()
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "notblank":
+		return fmt.Sprintf("%s must not be blank", field)
+	case "max", "lte":
+		switch fe.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("%s must be at most %s characters", field, param)
+		case reflect.Slice, reflect.Map:
+			return fmt.Sprintf("%s must contain at most %s items", field, param)
+		}
+		return fmt.Sprintf("%s must be less than or equal to %s", field, param)
+	case "min", "gte":
+		switch fe.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("%s must be at least %s characters", field, param)
+		case reflect.Slice, reflect.Map:
+			return fmt.Sprintf("%s must contain at least %s items", field, param)
+		}
+		return fmt.Sprintf("%s must be greater than or equal to %s", field, param)
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, param)
+	case "gtfield":
+		return fmt.Sprintf("%s must be after %s", field, jsonFieldName(param))
+	case "gtefield":
+		return fmt.Sprintf("%s must not be before %s", field, jsonFieldName(param))
+	case "nefield":
+		return fmt.Sprintf("%s must differ from %s", field, jsonFieldName(param))
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", field, strings.Join(strings.Fields(param), ", "))
+	case "email":
+		return fmt.Sprintf("%s must be an email address", field)
+	case "url", "http_url":
+		return fmt.Sprintf("%s must be a URL", field)
+	case "timezone":
+		return fmt.Sprintf("%s must be an IANA time zone (e.g. Asia/Tokyo)", field)
+	case "color":
+		return fmt.Sprintf("%s must be in the #rrggbb format", field)
+	case "datetime":
+		return fmt.Sprintf("%s must be in the %s format", field, datetimeLayout(param))
+	case "unique":
+		return fmt.Sprintf("%s contains duplicates", field)
+	}
+	return fmt.Sprintf("%s is invalid", field)
+}
+
+// jsonFieldName gtfield などのパラメーター（Goのフィールド名）をJSONの項目名にする
+func jsonFieldName(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
+
+func datetimeLayout(layout string) string {
+	switch layout {
+	case "15:04":
+		return "HH:MM"
+	case "2006-01-02":
+		return "YYYY-MM-DD"
+	}
+	return layout
+}
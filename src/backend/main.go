@@ -1,31 +1,84 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"task-calendar-backend/internal/apidocs"
+	"task-calendar-backend/internal/apierror"
 	"task-calendar-backend/internal/config"
 	"task-calendar-backend/internal/database"
 	"task-calendar-backend/internal/handlers"
+	"task-calendar-backend/internal/logging"
 	"task-calendar-backend/internal/middleware"
+	"task-calendar-backend/internal/models"
+	"task-calendar-backend/internal/ratelimit"
 	"task-calendar-backend/internal/services"
+	"task-calendar-backend/internal/storage"
+	"task-calendar-backend/internal/validation"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
 	// 設定読み込み
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("設定の読み込みに失敗しました:", err)
+	}
+
+	// ログ（JSON。標準の log パッケージの出力も同じ形式にする）
+	if err := logging.Setup(cfg.LogLevel, cfg.LogFormat); err != nil {
+		log.Fatal("ログの設定に失敗しました:", err)
+	}
 
 	// データベース接続
-	db, err := database.Connect(cfg.DatabaseURL)
+	db, err := database.Connect(cfg.DatabaseURL, database.Options{
+		MaxOpenConns:    int(cfg.DBMaxOpenConns),
+		MaxIdleConns:    int(cfg.DBMaxIdleConns),
+		ConnMaxLifetime: time.Duration(cfg.DBConnMaxLifetimeSeconds) * time.Second,
+		ConnMaxIdleTime: time.Duration(cfg.DBConnMaxIdleTimeSeconds) * time.Second,
+		ReplicaURLs:     cfg.ReplicaURLs(),
+	})
 	if err != nil {
 		log.Fatal("データベース接続に失敗しました:", err)
 	}
 
-	// マイグレーション実行
-	if err := database.Migrate(db); err != nil {
-		log.Fatal("マイグレーションに失敗しました:", err)
+	// マイグレーションのサブコマンド（migrate up・down・status）はサーバーを起動せずに終わる
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(db, os.Args[2:]); err != nil {
+			log.Fatal("マイグレーションに失敗しました:", err)
+		}
+		return
+	}
+
+	// スキーマが最新か確認する（MIGRATE_ON_START の場合は先に適用する）
+	if cfg.MigrateOnStart {
+		applied, err := database.MigrateUp(db)
+		if err != nil {
+			log.Fatal("マイグレーションに失敗しました:", err)
+		}
+		for _, m := range applied {
+			log.Printf("マイグレーションを適用しました: %d_%s", m.Version, m.Name)
+		}
+	}
+	if err := database.CheckSchema(db); err != nil {
+		log.Fatal("スキーマが最新ではありません（migrate up を実行してください）:", err)
+	}
+
+	// デモデータを投入するサブコマンド（開発・e2eテスト用）
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		if err := runSeedCommand(db, cfg.Environment); err != nil {
+			log.Fatal("デモデータの投入に失敗しました:", err)
+		}
+		return
 	}
 
 	// サービス初期化
@@ -34,60 +87,481 @@ func main() {
 	teamService := services.NewTeamService(db)
 	taskService := services.NewTaskService(db)
 	eventService := services.NewEventService(db)
+	changeWatermarkService := services.NewChangeWatermarkService(db)
+	if err := changeWatermarkService.RegisterCallbacks(); err != nil {
+		log.Fatal("変更日時の記録の初期化に失敗しました:", err)
+	}
+	deltaSyncService := services.NewDeltaSyncService(db)
+	if err := deltaSyncService.RegisterCallbacks(); err != nil {
+		log.Fatal("削除の記録の初期化に失敗しました:", err)
+	}
+
+	// 添付ファイルストレージ
+	fileStorage, err := storage.NewLocalStorage(cfg.StorageDir)
+	if err != nil {
+		log.Fatal("ストレージの初期化に失敗しました:", err)
+	}
+	attachmentService := services.NewAttachmentService(db, fileStorage, cfg.MaxUploadSize)
+
+	// 非同期ジョブ（結果のファイルは添付ファイルと同じストレージに保存し、署名付きURLで返す）
+	jobService := services.NewJobService(db, fileStorage, cfg.JWTSecret, "/api/v1/jobs")
+	avatarService := services.NewAvatarService(db, fileStorage, cfg.MaxAvatarSize, "/api/v1/users")
+	trashService := services.NewTrashService(db, fileStorage, services.TrashRetention{
+		Tasks:          time.Duration(cfg.TaskTrashDays) * 24 * time.Hour,
+		Events:         time.Duration(cfg.EventTrashDays) * 24 * time.Hour,
+		Comments:       time.Duration(cfg.CommentTrashDays) * 24 * time.Hour,
+		Attachments:    time.Duration(cfg.AttachmentTrashDays) * 24 * time.Hour,
+		ChecklistItems: time.Duration(cfg.ChecklistTrashDays) * 24 * time.Hour,
+	})
+	jobService.Register(models.JobTypeEventExport, services.EventExportJob(eventService))
+	jobService.Register(models.JobTypeEventImport, services.EventImportJob(eventService))
+	jobService.Register(models.JobTypeTaskMerge, services.TaskMergeJob(taskService))
+	// バックアップ・復元（アーカイブに添付ファイルとアバター画像も含める）
+	backupService := services.NewBackupService(db, fileStorage)
+	jobService.Register(models.JobTypeBackupExport, services.BackupExportJob(backupService))
+	jobService.Register(models.JobTypeBackupRestore, services.BackupRestoreJob(backupService))
+
+	// 通知
+	notificationService := services.NewNotificationService(db)
+	realtimeService := services.NewRealtimeService(db)
+	if err := realtimeService.RegisterCallbacks(); err != nil {
+		log.Fatal("リアルタイム配信の初期化に失敗しました:", err)
+	}
+	notificationRouter := services.NewNotificationRouter(db, jobService)
+	notificationRouter.Register(models.NotificationChannelInApp, notificationService)
+	webhookService := services.NewWebhookService(db)
+	if err := webhookService.RegisterCallbacks(); err != nil {
+		log.Fatal("Webhookの初期化に失敗しました:", err)
+	}
+	notificationRouter.Register(models.NotificationChannelWebhook, webhookService)
+	var pushSenders []services.PushSender
+	if cfg.FCMCredentialsFile != "" {
+		fcmSender, err := services.NewFCMSender(cfg.FCMCredentialsFile)
+		if err != nil {
+			log.Fatal("FCMの初期化に失敗しました:", err)
+		}
+		pushSenders = append(pushSenders, fcmSender)
+	}
+	if cfg.APNsKeyFile != "" {
+		apnsSender, err := services.NewAPNsSender(cfg.APNsKeyFile, cfg.APNsKeyID, cfg.APNsTeamID, cfg.APNsTopic, cfg.APNsProduction)
+		if err != nil {
+			log.Fatal("APNsの初期化に失敗しました:", err)
+		}
+		pushSenders = append(pushSenders, apnsSender)
+	}
+	pushService := services.NewPushService(db, pushSenders...)
+	if pushService.Enabled() {
+		notificationRouter.Register(models.NotificationChannelPush, pushService)
+	}
+	if err := notificationRouter.RegisterCallbacks(); err != nil {
+		log.Fatal("通知の初期化に失敗しました:", err)
+	}
+	mentionBroadcastService := services.NewMentionBroadcastService(db, notificationRouter)
+	if err := mentionBroadcastService.RegisterCallbacks(); err != nil {
+		log.Fatal("メンションの初期化に失敗しました:", err)
+	}
+	notifier := services.MultiNotifier{services.LogNotifier{}, notificationRouter}
+	var mailer services.Mailer = services.LogMailer{}
+	if cfg.SMTPHost != "" {
+		smtpMailer := services.NewSMTPMailer(cfg.SMTPHost, int(cfg.SMTPPort), cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom)
+		mailer = services.NewQueuedMailer(jobService, smtpMailer, int(cfg.SMTPMaxAttempts))
+		notificationRouter.Register(models.NotificationChannelEmail, services.NewEmailNotifier(db, mailer))
+	}
+	passwordResetService := services.NewPasswordResetService(db, mailer, strings.TrimRight(cfg.AppURL, "/")+"/reset-password")
+	replyAddressService := services.NewReplyAddressService(db, cfg.ReplyEmailDomain)
+	invitationService := services.NewInvitationService(db, mailer, replyAddressService)
+	commentService := services.NewCommentService(db, notifier, replyAddressService)
+	moderationService := services.NewModerationService(db, notifier)
+	workspaceService := services.NewWorkspaceService(db)
+	retentionService := services.NewRetentionService(db, services.RetentionPolicy{
+		NotificationDays:   int(cfg.RetainNotificationDays),
+		DoneTaskDays:       int(cfg.ArchiveDoneTaskDays),
+		TeamInvitationDays: int(cfg.TeamInvitationExpiryDays),
+		ExpiredTokenDays:   int(cfg.RetainExpiredTokenDays),
+		DryRun:             cfg.RetentionDryRun,
+	})
+	caldavService := services.NewCalDAVService(db, eventService)
+	attendeeService := services.NewAttendeeService(db, notifier, invitationService)
+	inboundEmailService := services.NewInboundEmailService(db, commentService, attendeeService)
+	duplicateService := services.NewDuplicateService(db, attendeeService)
+	pollService := services.NewPollService(db, notifier, attendeeService)
+	attendanceService := services.NewAttendanceService(db)
+	reminderService := services.NewReminderService(db, eventService, notifier)
+	occurrenceIndexService := services.NewOccurrenceIndexService(db)
+	var holidayProvider services.HolidayProvider = services.BuiltinHolidayProvider{}
+	if cfg.HolidayAPIURL != "" {
+		holidayProvider = services.NewNagerHolidayProvider(cfg.HolidayAPIURL, holidayProvider)
+	}
+	holidayService := services.NewHolidayService(db, holidayProvider)
+	freeBusyService := services.NewFreeBusyService(db, holidayService)
+	agendaService := services.NewAgendaService(db, eventService, holidayService)
+	dailyAgendaService := services.NewDailyAgendaService(db, agendaService, mailer)
+	reportService := services.NewReportService(db)
+	weeklyDigestService := services.NewWeeklyDigestService(db, reportService, agendaService, mailer)
+	sharedCalendarService := services.NewSharedCalendarService(db)
+	roomService := services.NewRoomService(db)
+	categoryService := services.NewCategoryService(db)
+	labelService := services.NewLabelService(db)
+	patchService := services.NewPatchService(db)
+	idempotencyService := services.NewIdempotencyService(db)
+	apiUsageService := services.NewAPIUsageService(db)
+	healthService := services.NewHealthService(db)
+	rateLimiter := ratelimit.New(int(cfg.RateLimitRequests), time.Duration(cfg.RateLimitWindowSeconds)*time.Second)
+	subscriptionService := services.NewSubscriptionService(db, time.Duration(cfg.FeedRefreshMinutes)*time.Minute)
+
+	// 外部カレンダー連携（クライアントIDが設定されたプロバイダーのみ有効）
+	var calendarProviders []services.CalendarProvider
+	if cfg.MicrosoftClientID != "" {
+		calendarProviders = append(calendarProviders, services.NewMicrosoftCalendarProvider(
+			cfg.MicrosoftClientID, cfg.MicrosoftClientSecret, cfg.MicrosoftRedirectURL, cfg.MicrosoftTenant))
+	}
+	calendarSyncService := services.NewCalendarSyncService(db, cfg.JWTSecret, calendarProviders...)
+
+	// ビデオ会議連携（認証情報が設定されたサービスのみ有効）
+	var conferenceProviders []services.ConferenceProvider
+	if cfg.ZoomClientID != "" {
+		conferenceProviders = append(conferenceProviders, services.NewZoomConferenceProvider(
+			cfg.ZoomAccountID, cfg.ZoomClientID, cfg.ZoomClientSecret))
+	}
+	if cfg.GoogleMeetCredentialsFile != "" {
+		meet, err := services.NewGoogleMeetConferenceProvider(cfg.GoogleMeetCredentialsFile)
+		if err != nil {
+			log.Fatal("Google Meetの認証情報の読み込みに失敗しました:", err)
+		}
+		conferenceProviders = append(conferenceProviders, meet)
+	}
+	conferenceService := services.NewConferenceService(db, conferenceProviders...)
+
+	// チャット連携（Microsoft Teams・Discord はチャンネルごとのWebhook、その他はトークンが設定されたサービスのみ有効）
+	chatPosters := []services.ChatPoster{services.NewTeamsWebhookPoster(), services.NewDiscordWebhookPoster()}
+	var slackCommandService *services.SlackCommandService
+	if cfg.SlackBotToken != "" {
+		slackClient := services.NewSlackClient(cfg.SlackBotToken)
+		chatPosters = append(chatPosters, slackClient)
+		if cfg.SlackSigningSecret != "" {
+			slackCommandService = services.NewSlackCommandService(db, slackClient, cfg.SlackSigningSecret, cfg.AppURL)
+		}
+	}
+	chatIntegrationService := services.NewChatIntegrationService(db, jobService, cfg.AppURL, chatPosters...)
+	if err := chatIntegrationService.RegisterCallbacks(); err != nil {
+		log.Fatal("チャット連携の初期化に失敗しました:", err)
+	}
+	automationService := services.NewAutomationService(db, jobService, chatIntegrationService, notificationRouter)
+	if err := automationService.RegisterCallbacks(); err != nil {
+		log.Fatal("自動化のルールの初期化に失敗しました:", err)
+	}
 
 	// Cronサービス開始
 	cronService := services.NewCronService(eventService)
+	// 稼働確認の記録以外のジョブは実行の記録を残し、停止中に実行されなかった分を起動時に実行する
+	cronStateService := services.NewCronStateService(db)
+	addCronJob := func(name, spec string, job func() error) error {
+		return cronService.AddJob(name, spec, cronStateService.Track(name, spec, job))
+	}
+	addCronJobSince := func(name, spec string, job func(since time.Time) error) error {
+		return cronService.AddJob(name, spec, cronStateService.TrackSince(name, spec, job))
+	}
+	if err := addCronJob("繰り返しタスクのリセット", "@every 15m", taskService.ResetRecurringChecklists); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("外部カレンダーの同期", "@every 15m", subscriptionService.SyncDueSubscriptions); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("外部カレンダー連携の同期", "@every 15m", calendarSyncService.SyncAllConnections); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("繰り返しイベントの回の索引の更新", "@every 10m", occurrenceIndexService.RefreshOccurrenceIndex); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJobSince("イベントリマインダーの送信", "@every 1m", reminderService.DeliverDueReminders); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("ビデオ会議リンクの更新", "@every 5m", conferenceService.SyncConferences); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJobSince("アジェンダメールの送信", "@every 5m", dailyAgendaService.SendDailyAgendas); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJobSince("週次ダイジェストの送信", "@every 15m", weeklyDigestService.SendWeeklyDigests); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("Webhookの配信", "@every 10s", webhookService.DeliverPending); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("開始前のイベントのチャットへの投稿", "@every 1m", chatIntegrationService.PostUpcomingEvents); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("期限が近いタスクのチャットへの投稿", "@every 15m", chatIntegrationService.PostDueSoonTasks); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("期限の切れたIdempotency-Keyの削除", "@every 1h", idempotencyService.PurgeExpired); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("期限切れのタスクの自動化のルールの実行", "@every 5m", automationService.RunOverdueRules); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("非同期ジョブの実行", "@every 5s", jobService.RunPending); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("保存期間を過ぎた非同期ジョブの削除", "@every 1h", jobService.PurgeExpired); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("APIの利用状況の集計の書き込み", "@every 1m", apiUsageService.Flush); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("保存期間を過ぎたAPIの利用状況の削除", "@every 24h", apiUsageService.PurgeExpired); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("保存期間を過ぎた削除の記録の削除", "@every 24h", deltaSyncService.PurgeTombstones); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("保存期間を過ぎた削除したデータの完全な削除", "@every 1h", trashService.PurgeExpired); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := addCronJob("保存期間のルールの実行", "@every 24h", retentionService.RunScheduled); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
+	if err := cronService.AddJob("稼働確認の記録", "@every "+services.CronHeartbeatInterval.String(), healthService.CronHeartbeat); err != nil {
+		log.Fatal("Cronジョブの登録に失敗しました:", err)
+	}
 	cronService.Start()
-	defer cronService.Stop()
+	cronStateService.RunMissed()
 
 	// Ginルーター設定
 	if os.Getenv("GIN_MODE") == "release" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	r := gin.Default()
+	// リクエストの本文の検証（binding タグ）の設定
+	if err := validation.Register(); err != nil {
+		log.Fatal("バリデーターの設定に失敗しました:", err)
+	}
+
+	r := gin.New()
+
+	// リクエストID（エラー応答の requestId・X-Request-ID）と、リクエストごとのログ
+	r.Use(middleware.RequestID(), middleware.RequestLogger(), middleware.Recovery())
 
 	// CORS設定
 	r.Use(middleware.CORS())
 
+	// 存在しないルートもエラー応答の形式で返す
+	r.NoRoute(func(c *gin.Context) {
+		apierror.Respond(c, http.StatusNotFound, "指定されたAPIは存在しません")
+	})
+
 	// ハンドラー初期化
 	authHandler := handlers.NewAuthHandler(authService)
+	passwordResetHandler := handlers.NewPasswordResetHandler(passwordResetService)
 	userHandler := handlers.NewUserHandler(userService)
 	teamHandler := handlers.NewTeamHandler(teamService)
 	taskHandler := handlers.NewTaskHandler(taskService)
 	eventHandler := handlers.NewEventHandler(eventService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService)
+	commentHandler := handlers.NewCommentHandler(commentService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService, changeWatermarkService)
+	notificationPreferenceHandler := handlers.NewNotificationPreferenceHandler(notificationRouter)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	deviceHandler := handlers.NewDeviceHandler(pushService)
+	mentionHandler := handlers.NewMentionHandler(mentionBroadcastService)
+	realtimeHandler := handlers.NewRealtimeHandler(realtimeService)
+	chatIntegrationHandler := handlers.NewChatIntegrationHandler(chatIntegrationService)
+	automationHandler := handlers.NewAutomationHandler(automationService)
+	slackHandler := handlers.NewSlackHandler(slackCommandService)
+	moderationHandler := handlers.NewModerationHandler(moderationService)
+	workspaceHandler := handlers.NewWorkspaceHandler(workspaceService)
+	trashHandler := handlers.NewTrashHandler(trashService)
+	retentionHandler := handlers.NewRetentionHandler(retentionService)
+	inboundEmailHandler := handlers.NewInboundEmailHandler(inboundEmailService, cfg.InboundEmailSecret)
+	caldavHandler := handlers.NewCalDAVHandler(caldavService)
+	subscriptionHandler := handlers.NewSubscriptionHandler(subscriptionService)
+	calendarSyncHandler := handlers.NewCalendarSyncHandler(calendarSyncService)
+	attendeeHandler := handlers.NewAttendeeHandler(attendeeService)
+	duplicateHandler := handlers.NewDuplicateHandler(duplicateService)
+	pollHandler := handlers.NewPollHandler(pollService)
+	attendanceHandler := handlers.NewAttendanceHandler(attendanceService)
+	reminderHandler := handlers.NewReminderHandler(reminderService)
+	freeBusyHandler := handlers.NewFreeBusyHandler(freeBusyService)
+	agendaHandler := handlers.NewAgendaHandler(agendaService, changeWatermarkService)
+	holidayHandler := handlers.NewHolidayHandler(holidayService)
+	dailyAgendaHandler := handlers.NewDailyAgendaHandler(dailyAgendaService)
+	weeklyDigestHandler := handlers.NewWeeklyDigestHandler(weeklyDigestService)
+	sharedCalendarHandler := handlers.NewSharedCalendarHandler(sharedCalendarService)
+	roomHandler := handlers.NewRoomHandler(roomService)
+	categoryHandler := handlers.NewCategoryHandler(categoryService)
+	labelHandler := handlers.NewLabelHandler(labelService)
+	conferenceHandler := handlers.NewConferenceHandler(conferenceService)
+	apiDocsHandler := handlers.NewAPIDocsHandler()
+	patchHandler := handlers.NewPatchHandler(patchService)
+	batchHandler := handlers.NewBatchHandler(r)
+	limitsHandler := handlers.NewLimitsHandler(rateLimiter)
+	jobHandler := handlers.NewJobHandler(jobService)
+	cronJobHandler := handlers.NewCronJobHandler(cronStateService)
+	avatarHandler := handlers.NewAvatarHandler(avatarService)
+	apiUsageHandler := handlers.NewAPIUsageHandler(apiUsageService)
+	deltaSyncHandler := handlers.NewDeltaSyncHandler(deltaSyncService)
+	healthHandler := handlers.NewHealthHandler(healthService)
+	seedHandler := handlers.NewSeedHandler(services.NewSeedService(db))
 
-	// ルート設定
-	api := r.Group("/api")
-	{
-		// 認証不要ルート
-		auth := api.Group("/auth")
+	// CalDAV（カレンダーアプリからの同期用、Basic認証）
+	dav := r.Group("/caldav")
+	dav.Use(middleware.BasicAuth(db, "TaskCalendar"))
+	for _, method := range handlers.CalDAVMethods {
+		r.Handle(method, "/.well-known/caldav", caldavHandler.WellKnown)
+		dav.Handle(method, "/*path", caldavHandler.ServeDAV)
+	}
+
+	// APIドキュメント（OpenAPIの仕様とSwagger UI。バージョンによらない）
+	r.GET("/api/docs", apiDocsHandler.GetUI)
+	r.GET("/api/docs/openapi.json", apiDocsHandler.GetSpec)
+
+	// ルート設定（APIのバージョンごとに登録する関数を分ける。互換性のない変更は registerV2 を追加して /api/v2 に登録する）
+	//
+	// v2 は一覧の応答の形（data・meta）のみ v1 と異なるため、同じルートを登録してハンドラーが apiVersion で形を切り替える。
+	registerV1 := func(api *gin.RouterGroup) {
+		// リクエスト数・エラー率・応答時間を利用者・ルートごとに集計する（管理者の利用状況の分析用）
+		api.Use(middleware.APIUsage(apiUsageService))
+		// fields クエリで、GETの応答を必要な項目のみにする（ETag より先に登録し、ETag は絞り込む前の応答全体から求める）
+		api.Use(middleware.Fields())
+		// GETの応答にETagをつけ、更新・削除では If-Match を確認する（古い内容をもとにした更新を412で拒否する）
+		api.Use(middleware.ETag(), middleware.IfMatch(r))
+
+		// 認証不要ルート（リクエスト数はIPアドレスごとに制限する）
+		auth := api.Group("/auth", middleware.RateLimit(rateLimiter))
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/password-reset", passwordResetHandler.RequestReset)
+			auth.POST("/password-reset/confirm", passwordResetHandler.ResetPassword)
 		}
 
+		// メール受信Webhook（共有シークレットで認証）
+		api.POST("/inbound/email", inboundEmailHandler.ReceiveEmail)
+
+		// Slackのスラッシュコマンド（Slackの署名で認証）
+		api.POST("/integrations/slack/commands", slackHandler.HandleCommand)
+
+		// 外部カレンダー連携のOAuthコールバック（stateで認証）
+		api.GET("/integrations/:provider/callback", calendarSyncHandler.Callback)
+
+		// 共有リンクで公開しているカレンダー（トークンで認可）
+		api.GET("/shared/calendars/:token", sharedCalendarHandler.GetSharedCalendar)
+		api.GET("/shared/calendars/:token/calendar.ics", sharedCalendarHandler.ExportSharedICS)
+
+		// 非同期ジョブの結果のファイル（署名付きURLで認可）
+		api.GET("/jobs/:id/download", jobHandler.DownloadResult)
+
+		// アバター画像（img 要素から読み込むため認証不要）
+		api.GET("/users/:id/avatar", avatarHandler.GetAvatar)
+
+		// リクエスト数の制限の状況（確認のためのリクエストは数えない）
+		api.GET("/limits", middleware.AuthMiddleware(cfg.JWTSecret), limitsHandler.GetLimits)
+
 		// 認証必要ルート
 		protected := api.Group("/")
 		protected.Use(middleware.AuthMiddleware(cfg.JWTSecret))
+		// リクエスト数をユーザーごとに制限し、X-RateLimit-* ヘッダーで残りを知らせる
+		protected.Use(middleware.RateLimit(rateLimiter))
+		// Idempotency-Key をつけたPOSTの再送には、最初の応答を返す（通信の失敗で二重に作成しないため）
+		protected.Use(middleware.Idempotency(idempotencyService))
 		{
+			// 複数のリクエストをまとめて実行する
+			protected.POST("/batch", batchHandler.ExecuteBatch)
+
+			// 時間のかかる処理を非同期ジョブとして登録する（202で返したジョブの状態を GET /jobs/:id で確認する）
+			jobs := protected.Group("/jobs")
+			{
+				jobs.POST("/event-exports", jobHandler.CreateEventExportJob)
+				jobs.POST("/event-imports", jobHandler.CreateEventImportJob)
+				jobs.POST("/task-merges", jobHandler.CreateTaskMergeJob)
+				jobs.GET("/:id", jobHandler.GetJob)
+			}
+
 			// ユーザー管理
 			users := protected.Group("/users")
 			{
 				users.GET("/me", userHandler.GetProfile)
 				users.PUT("/me", userHandler.UpdateProfile)
+				users.PATCH("/me", patchHandler.PatchProfile)
+				users.PUT("/me/avatar", avatarHandler.UploadAvatar)
+				users.DELETE("/me/avatar", avatarHandler.DeleteAvatar)
+				users.GET("/me/working-hours", freeBusyHandler.GetWorkingHours)
+				users.PUT("/me/working-hours", freeBusyHandler.UpdateWorkingHours)
+				users.GET("/me/daily-agenda", dailyAgendaHandler.GetSettings)
+				users.PUT("/me/daily-agenda", dailyAgendaHandler.UpdateSettings)
+				users.GET("/me/weekly-digest", weeklyDigestHandler.GetSettings)
+				users.PUT("/me/weekly-digest", weeklyDigestHandler.UpdateSettings)
+				users.GET("/me/notification-preferences", notificationPreferenceHandler.GetMyPreferences)
+				users.PUT("/me/notification-preferences", notificationPreferenceHandler.UpdateMyPreferences)
+				users.GET("/me/devices", deviceHandler.List)
+				users.POST("/me/devices", deviceHandler.Register)
+				users.DELETE("/me/devices/:id", deviceHandler.Delete)
+				users.GET("/:id/freebusy", freeBusyHandler.GetUserFreeBusy)
 			}
 
+			// 通知センター
+			notifications := protected.Group("/notifications")
+			{
+				notifications.GET("", notificationHandler.GetNotifications)
+				notifications.GET("/groups", notificationHandler.GetNotificationGroups)
+				notifications.POST("/read-all", notificationHandler.MarkAllRead)
+				notifications.POST("/:id/read", notificationHandler.MarkRead)
+			}
+
+			// Webhook（チームの変更・自分宛ての通知の送信先）
+			webhooks := protected.Group("/webhooks")
+			{
+				webhooks.GET("", webhookHandler.GetEndpoints)
+				webhooks.POST("", webhookHandler.CreateEndpoint)
+				webhooks.PUT("/:id", webhookHandler.UpdateEndpoint)
+				webhooks.DELETE("/:id", webhookHandler.DeleteEndpoint)
+				webhooks.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+				webhooks.GET("/:id/deliveries/:deliveryId", webhookHandler.GetDelivery)
+				webhooks.POST("/:id/deliveries/:deliveryId/replay", webhookHandler.ReplayDelivery)
+			}
+
+			// 空き状況
+			protected.GET("/freebusy", freeBusyHandler.GetFreeBusy)
+
+			// アジェンダ（イベントとタスクの期限）
+			protected.GET("/calendar", agendaHandler.GetAgenda)
+
+			// 差分同期（オフラインで使うクライアント向け）
+			protected.GET("/changes", deltaSyncHandler.ListChanges)
+
+			// 削除したタスク・イベントの一覧（復元は各リソースの /restore）
+			protected.GET("/trash", trashHandler.GetTrash)
+
 			// チーム管理
 			teams := protected.Group("/teams")
 			{
 				teams.GET("", teamHandler.GetTeams)
 				teams.POST("", teamHandler.CreateTeam)
 				teams.GET("/:id", teamHandler.GetTeam)
+				teams.GET("/:id/presence", realtimeHandler.GetTeamPresence)
 				teams.PUT("/:id", teamHandler.UpdateTeam)
+				teams.PATCH("/:id", patchHandler.PatchTeam)
 				teams.DELETE("/:id", teamHandler.DeleteTeam)
 				teams.POST("/:id/members", teamHandler.AddMember)
 				teams.DELETE("/:id/members/:userId", teamHandler.RemoveMember)
+				teams.GET("/:id/events/export.ics", eventHandler.ExportTeamICS)
+				teams.PUT("/:id/event-settings", eventHandler.UpdateTeamEventSettings)
+				teams.GET("/:id/holidays", holidayHandler.GetTeamHolidays)
+				teams.GET("/:id/rooms", roomHandler.GetRooms)
+				teams.POST("/:id/rooms", roomHandler.CreateRoom)
+				teams.GET("/:id/event-categories", categoryHandler.GetCategories)
+				teams.POST("/:id/event-categories", categoryHandler.CreateCategory)
+				teams.GET("/:id/labels", labelHandler.GetLabels)
+				teams.POST("/:id/labels", labelHandler.CreateLabel)
+				teams.GET("/:id/automation-rules", automationHandler.GetRules)
+				teams.POST("/:id/automation-rules", automationHandler.CreateRule)
+				teams.GET("/:id/chat-channels", chatIntegrationHandler.GetTeamChannels)
+				teams.PUT("/:id/chat-channels/:provider", chatIntegrationHandler.SetTeamChannel)
+				teams.DELETE("/:id/chat-channels/:provider", chatIntegrationHandler.DeleteTeamChannel)
+				teams.GET("/:id/notification-preferences", notificationPreferenceHandler.GetTeamPreferences)
+				teams.PUT("/:id/notification-preferences", notificationPreferenceHandler.UpdateTeamPreferences)
+				teams.GET("/:id/mention-settings", mentionHandler.GetTeamSettings)
+				teams.PUT("/:id/mention-settings", mentionHandler.UpdateTeamSettings)
 			}
 
 			// タスク管理
@@ -97,27 +571,266 @@ func main() {
 				tasks.POST("", taskHandler.CreateTask)
 				tasks.GET("/:id", taskHandler.GetTask)
 				tasks.PUT("/:id", taskHandler.UpdateTask)
+				tasks.PATCH("/:id", patchHandler.PatchTask)
 				tasks.DELETE("/:id", taskHandler.DeleteTask)
-				tasks.POST("/:id/comments", taskHandler.AddComment)
+				tasks.POST("/:id/restore", trashHandler.RestoreTask)
+				tasks.GET("/:id/comments", commentHandler.GetComments)
+				tasks.POST("/:id/comments", commentHandler.CreateComment)
+				tasks.PUT("/:id/comments/:commentId", commentHandler.UpdateComment)
+				tasks.DELETE("/:id/comments/:commentId", commentHandler.DeleteComment)
+				tasks.POST("/:id/comments/:commentId/restore", trashHandler.RestoreComment)
+				tasks.GET("/:id/comments/:commentId/history", commentHandler.GetCommentHistory)
+				tasks.POST("/:id/comments/:commentId/reactions", commentHandler.AddReaction)
+				tasks.DELETE("/:id/comments/:commentId/reactions/:emoji", commentHandler.RemoveReaction)
+				tasks.POST("/:id/comments/:commentId/pin", commentHandler.PinComment)
+				tasks.DELETE("/:id/comments/:commentId/pin", commentHandler.UnpinComment)
+				tasks.POST("/:id/comments/:commentId/report", moderationHandler.ReportComment)
+				tasks.POST("/:id/comments/:commentId/attachments", attachmentHandler.UploadCommentAttachment)
+				tasks.GET("/:id/attachments/:attachmentId", attachmentHandler.DownloadAttachment)
+				tasks.DELETE("/:id/attachments/:attachmentId", attachmentHandler.DeleteAttachment)
+				tasks.POST("/:id/attachments/:attachmentId/restore", trashHandler.RestoreAttachment)
+				tasks.POST("/:id/merge", taskHandler.MergeTask)
+				tasks.GET("/:id/activity", taskHandler.GetActivity)
+				tasks.GET("/:id/watchers", taskHandler.GetWatchers)
+				tasks.GET("/:id/viewers", realtimeHandler.GetTaskViewers)
+				tasks.POST("/:id/watch", taskHandler.WatchTask)
+				tasks.DELETE("/:id/watch", taskHandler.UnwatchTask)
+				tasks.PUT("/:id/recurrence", taskHandler.SetRecurrence)
+				tasks.PUT("/:id/labels", labelHandler.SetTaskLabels)
+				tasks.GET("/:id/occurrences", taskHandler.GetOccurrences)
+				tasks.GET("/:id/checklist", taskHandler.GetChecklist)
+				tasks.POST("/:id/checklist", taskHandler.AddChecklistItem)
+				tasks.PUT("/:id/checklist/:itemId", taskHandler.UpdateChecklistItem)
+				tasks.DELETE("/:id/checklist/:itemId", taskHandler.DeleteChecklistItem)
+				tasks.POST("/:id/checklist/:itemId/restore", trashHandler.RestoreChecklistItem)
 			}
 
 			// イベント管理
 			events := protected.Group("/events")
 			{
-				events.GET("", eventHandler.GetEvents)
+				events.GET("", eventHandler.ListEvents)
 				events.POST("", eventHandler.CreateEvent)
+				events.POST("/quick", eventHandler.QuickEvent)
+				events.GET("/occurrences", eventHandler.GetOccurrences)
+				events.GET("/export.ics", eventHandler.ExportICS)
+				events.POST("/import", eventHandler.ImportICS)
+				events.POST("/check-conflicts", eventHandler.CheckConflicts)
+				events.POST("/find-slots", freeBusyHandler.FindSlots)
 				events.GET("/:id", eventHandler.GetEvent)
 				events.PUT("/:id", eventHandler.UpdateEvent)
+				events.PATCH("/:id", patchHandler.PatchEvent)
 				events.DELETE("/:id", eventHandler.DeleteEvent)
+				events.POST("/:id/restore", trashHandler.RestoreEvent)
+				events.POST("/:id/duplicate", duplicateHandler.DuplicateEvent)
+				events.GET("/:id/occurrences", eventHandler.GetEventOccurrences)
+				events.PUT("/:id/occurrences/:recurrenceId", eventHandler.OverrideOccurrence)
+				events.DELETE("/:id/occurrences/:recurrenceId", eventHandler.CancelOccurrence)
+				events.GET("/:id/conflicts", eventHandler.GetEventConflicts)
+				events.PUT("/:id/location", roomHandler.SetEventLocation)
+				events.PUT("/:id/appearance", categoryHandler.SetEventAppearance)
+				events.PUT("/:id/labels", labelHandler.SetEventLabels)
+				events.PUT("/:id/visibility", eventHandler.SetEventVisibility)
+				events.PUT("/:id/buffers", eventHandler.SetEventBuffers)
+				events.PATCH("/:id/time", eventHandler.MoveEvent)
+				events.POST("/:id/conference", conferenceHandler.AddConference)
+				events.DELETE("/:id/conference", conferenceHandler.RemoveConference)
+				events.GET("/:id/exceptions", eventHandler.GetExceptions)
+				events.DELETE("/:id/exceptions/:exceptionId", eventHandler.DeleteException)
+				events.GET("/:id/attendees", attendeeHandler.GetAttendees)
+				events.POST("/:id/attendees", attendeeHandler.InviteAttendees)
+				events.DELETE("/:id/attendees/:userId", attendeeHandler.RemoveAttendee)
+				events.PUT("/:id/rsvp", attendeeHandler.RespondRSVP)
+				events.PUT("/:id/capacity", attendeeHandler.SetCapacity)
+				events.POST("/:id/cancel", attendeeHandler.CancelEvent)
+				events.POST("/:id/transfer", attendeeHandler.TransferOwnership)
+				events.POST("/:id/check-in", attendanceHandler.CheckIn)
+				events.GET("/:id/attendance", attendanceHandler.GetAttendance)
+				events.GET("/:id/reminders", reminderHandler.GetReminders)
+				events.POST("/:id/reminders", reminderHandler.AddReminder)
+				events.DELETE("/:id/reminders/:reminderId", reminderHandler.DeleteReminder)
+			}
+
+			// 日程調整
+			polls := protected.Group("/polls")
+			{
+				polls.GET("", pollHandler.GetPolls)
+				polls.POST("", pollHandler.CreatePoll)
+				polls.GET("/:id", pollHandler.GetPoll)
+				polls.DELETE("/:id", pollHandler.DeletePoll)
+				polls.PUT("/:id/votes", pollHandler.Vote)
+				polls.POST("/:id/confirm", pollHandler.ConfirmPoll)
+			}
+
+			// 会議室
+			rooms := protected.Group("/rooms")
+			{
+				rooms.PUT("/:id", roomHandler.UpdateRoom)
+				rooms.DELETE("/:id", roomHandler.DeleteRoom)
+				rooms.GET("/:id/bookings", roomHandler.GetRoomBookings)
+			}
+
+			// イベントカテゴリー
+			categories := protected.Group("/event-categories")
+			{
+				categories.PUT("/:id", categoryHandler.UpdateCategory)
+				categories.DELETE("/:id", categoryHandler.DeleteCategory)
+			}
+
+			// カレンダーの共有リンク
+			calendarLinks := protected.Group("/calendar-links")
+			{
+				calendarLinks.GET("", sharedCalendarHandler.GetLinks)
+				calendarLinks.POST("", sharedCalendarHandler.CreateLink)
+				calendarLinks.DELETE("/:id", sharedCalendarHandler.RevokeLink)
+			}
+
+			// ラベル
+			labels := protected.Group("/labels")
+			{
+				labels.PUT("/:id", labelHandler.UpdateLabel)
+				labels.DELETE("/:id", labelHandler.DeleteLabel)
+			}
+
+			// タスクの自動化のルール
+			automationRules := protected.Group("/automation-rules")
+			{
+				automationRules.PUT("/:id", automationHandler.UpdateRule)
+				automationRules.DELETE("/:id", automationHandler.DeleteRule)
+			}
+
+			// 外部カレンダー購読
+			subscriptions := protected.Group("/calendar-subscriptions")
+			{
+				subscriptions.GET("", subscriptionHandler.GetSubscriptions)
+				subscriptions.POST("", subscriptionHandler.CreateSubscription)
+				subscriptions.POST("/:id/refresh", subscriptionHandler.RefreshSubscription)
+				subscriptions.DELETE("/:id", subscriptionHandler.DeleteSubscription)
+			}
+
+			// 外部カレンダー連携
+			integrations := protected.Group("/integrations")
+			{
+				integrations.GET("/connections", calendarSyncHandler.GetConnections)
+				integrations.POST("/connections/:id/sync", calendarSyncHandler.SyncConnection)
+				integrations.DELETE("/connections/:id", calendarSyncHandler.DeleteConnection)
+				integrations.GET("/:provider/authorize", calendarSyncHandler.Authorize)
+			}
+
+			// 管理者機能（ワークスペースの管理者）
+			admin := protected.Group("/admin")
+			admin.Use(middleware.RequireAdmin(db))
+			{
+				admin.GET("/moderation/reports", moderationHandler.GetReports)
+				admin.POST("/moderation/reports/:id/resolve", moderationHandler.ResolveReport)
+			}
+
+			// 運営者の機能（すべてのワークスペースに関わるもの。既定のワークスペースの管理者のみ）
+			platform := protected.Group("/admin")
+			platform.Use(middleware.RequirePlatformAdmin(db))
+			{
+				platform.GET("/workspaces", workspaceHandler.GetWorkspaces)
+				platform.POST("/workspaces", workspaceHandler.CreateWorkspace)
+				platform.GET("/workspaces/:id", workspaceHandler.GetWorkspace)
+				platform.GET("/analytics/api-usage", apiUsageHandler.GetUsage)
+				// 定期実行のジョブ（名前は一覧の name。URLエンコードして指定する）
+				platform.GET("/jobs", cronJobHandler.GetCronJobs)
+				platform.POST("/jobs/:name/run", cronJobHandler.RunCronJob)
+				platform.POST("/jobs/:name/pause", cronJobHandler.PauseCronJob)
+				platform.POST("/jobs/:name/resume", cronJobHandler.ResumeCronJob)
+				// 非同期ジョブ
+				platform.GET("/job-queue", jobHandler.GetJobs)
+				platform.POST("/job-queue/:id/retry", jobHandler.RetryJob)
+				// 保存期間のルール（GET は今実行した場合の対象の件数）
+				platform.GET("/retention", retentionHandler.GetRetention)
+				platform.POST("/retention/run", retentionHandler.RunRetention)
+				// バックアップ・復元（非同期ジョブとして登録し、進み具合と結果は GET /jobs/:id で確認する）
+				platform.POST("/backups", jobHandler.CreateBackupJob)
+				platform.POST("/backups/restore", jobHandler.CreateRestoreJob)
 			}
 		}
 	}
 
-	// ヘルスチェック
+	registerV1(r.Group("/api/v1", middleware.APIVersion("v1")))
+	registerV1(r.Group("/api/v2", middleware.APIVersion("v2")))
+	// バージョンを指定しない /api は、既存のクライアントのため v1 の別名として残す
+	registerV1(r.Group("/api", middleware.APIVersion("v1")))
+
+	// リアルタイム更新（ブラウザはヘッダーを指定できないため token クエリでも認証する）
+	r.GET("/ws", middleware.TokenFromQuery("token"), middleware.AuthMiddleware(cfg.JWTSecret), realtimeHandler.Connect)
+	// WebSocketを使えないクライアント・プロキシ向けのServer-Sent Events（EventSourceもヘッダーを指定できない）
+	r.GET("/sse", middleware.TokenFromQuery("token"), middleware.AuthMiddleware(cfg.JWTSecret), realtimeHandler.Stream)
+
+	// ヘルスチェック（/healthz はプロセスの稼働、/readyz はデータベースなどを含めた受け付けの可否。/health は以前からの互換用）
+	r.GET("/healthz", healthHandler.Live)
+	r.GET("/readyz", healthHandler.Ready)
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "OK"})
 	})
 
-	log.Printf("🚀 サーバーがポート %s で開始されました", cfg.Port)
-	log.Fatal(r.Run(":" + cfg.Port))
+	// デモデータの投入（開発環境のみ。フロントエンドの開発・e2eテストを空のデータベースから始めないため）
+	if cfg.Environment == "development" {
+		r.POST("/dev/seed", seedHandler.Seed)
+	}
+
+	// 仕様を作り直し忘れたルートを知らせる（go generate ./internal/apidocs で更新する）
+	for _, drift := range apidocs.Drift(r.Routes()) {
+		log.Printf("⚠️ APIドキュメントがルートと一致しません: %s", drift)
+	}
+
+	srv := &http.Server{Addr: ":" + cfg.Port, Handler: r}
+	// WebSocket・Server-Sent Eventsの接続は終わるまで待てないため、停止を知らせて切断させる
+	srv.RegisterOnShutdown(realtimeService.Shutdown)
+	go func() {
+		log.Printf("🚀 サーバーがポート %s で開始されました", cfg.Port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("サーバーの起動に失敗しました:", err)
+		}
+	}()
+
+	// SIGHUP で設定を読み込み直し、再起動せずに変えられる設定（ログレベル・レート制限）を反映する
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			next, err := config.Load()
+			if err != nil {
+				log.Printf("設定の再読み込みに失敗しました（以前の設定のまま動作します）: %v", err)
+				continue
+			}
+			if err := logging.SetLevel(next.LogLevel); err != nil {
+				log.Printf("ログレベルを変更できません: %v", err)
+			}
+			rateLimiter.SetLimits(int(next.RateLimitRequests), time.Duration(next.RateLimitWindowSeconds)*time.Second)
+			if names := cfg.RestartRequired(next); len(names) > 0 {
+				log.Printf("⚠️ 再起動するまで反映されない設定が変更されています: %s", strings.Join(names, ", "))
+			}
+			log.Println("設定を再読み込みしました")
+		}
+	}()
+
+	// SIGTERM（デプロイ時の停止）で新しい接続の受け付けを止め、実行中のリクエストと定期実行ジョブの終了を待ってから終わる
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("サーバーを停止しています...")
+	healthService.ShuttingDown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.ShutdownTimeoutSeconds)*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("実行中のリクエストの終了を待てませんでした: %v", err)
+	}
+	if err := cronService.Shutdown(ctx); err != nil {
+		log.Printf("実行中の定期実行ジョブの終了を待てませんでした: %v", err)
+	}
+	// 停止までの利用状況を失わないよう、メモリ上の集計を書き込む
+	if err := apiUsageService.Flush(); err != nil {
+		log.Printf("APIの利用状況の集計の書き込みに失敗しました: %v", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			log.Printf("データベース接続の切断に失敗しました: %v", err)
+		}
+	}
+	log.Println("サーバーを停止しました")
 }
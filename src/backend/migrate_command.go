@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"task-calendar-backend/internal/database"
+
+	"gorm.io/gorm"
+)
+
+const migrateUsage = "使い方: migrate up | migrate down [取り消す件数（既定1）] | migrate status"
+
+// runMigrateCommand マイグレーションのサブコマンド（サーバーは起動しない）
+//
+//	migrate up          適用していないマイグレーションをすべて適用する
+//	migrate down [N]    適用したマイグレーションを新しいものから N 件（既定1件）取り消す
+//	migrate status      マイグレーションごとの適用の状況を表示する
+func runMigrateCommand(db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	switch args[0] {
+	case "up":
+		applied, err := database.MigrateUp(db)
+		for _, m := range applied {
+			fmt.Printf("適用しました: %d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("適用していないマイグレーションはありません")
+		}
+		return nil
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("取り消す件数は正の整数で指定してください: %s", args[1])
+			}
+			steps = n
+		}
+		reverted, err := database.MigrateDown(db, steps)
+		for _, m := range reverted {
+			fmt.Printf("取り消しました: %d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		statuses, err := database.MigrationStatuses(db)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "未適用"
+			if status.AppliedAt != nil {
+				applied = status.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%6d  %-40s  %s\n", status.Version, status.Name, applied)
+		}
+		return nil
+	}
+	return errors.New(migrateUsage)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"task-calendar-backend/internal/services"
+
+	"gorm.io/gorm"
+)
+
+// runSeedCommand デモデータを投入するサブコマンド（サーバーは起動しない。本番環境では実行できない）
+//
+//	seed    デモのユーザー・チーム・タスク・繰り返しイベントを投入する（投入済みの場合は何もしない）
+func runSeedCommand(db *gorm.DB, environment string) error {
+	if environment == "production" {
+		return errors.New("本番環境ではデモデータを投入できません")
+	}
+	result, err := services.NewSeedService(db).Seed()
+	if errors.Is(err, services.ErrAlreadySeeded) {
+		fmt.Println(err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("デモデータを投入しました（チーム %d・タスク %d・イベント %d・参加者 %d・コメント %d）\n",
+		result.Teams, result.Tasks, result.Events, result.Attendees, result.Comments)
+	for _, user := range result.Users {
+		fmt.Printf("  %-24s %-12s %s（%s）\n", user.Email, user.Password, user.Name, user.Role)
+	}
+	return nil
+}